@@ -0,0 +1,240 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/version"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// aboutJSON is the shape of the --json output of `pulumi about`. While we can add fields to this structure in the
+// future, we should not change existing fields.
+type aboutJSON struct {
+	// CLI describes the Pulumi CLI itself.
+	CLI aboutCLI `json:"cli"`
+	// Platform describes the operating system and architecture that the CLI is running on.
+	Platform aboutPlatform `json:"platform"`
+	// Runtimes lists the language runtimes that Pulumi programs may be written for, and their installed versions.
+	Runtimes []aboutRuntime `json:"runtimes"`
+	// Plugins lists the installed plugins.
+	Plugins []aboutPlugin `json:"plugins"`
+	// Backend is the URL of the backend that commands would use, if there is one.
+	Backend string `json:"backend,omitempty"`
+	// Project describes the project in the current directory, if there is one.
+	Project *aboutProject `json:"project,omitempty"`
+	// Stack is the name of the current stack, if there is one.
+	Stack string `json:"stack,omitempty"`
+}
+
+type aboutCLI struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"goVersion"`
+}
+
+type aboutPlatform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+type aboutRuntime struct {
+	Name string `json:"name"`
+	// Version is the version of the runtime's executable, or empty if the executable could not be found or run.
+	Version string `json:"version,omitempty"`
+}
+
+type aboutPlugin struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Version string `json:"version,omitempty"`
+}
+
+type aboutProject struct {
+	Name    string `json:"name"`
+	Runtime string `json:"runtime"`
+}
+
+// aboutRuntimeCommands are the commands that print the versions of the language runtimes that Pulumi supports.
+var aboutRuntimeCommands = []struct {
+	Name    string
+	Command []string
+}{
+	{Name: "nodejs", Command: []string{"node", "--version"}},
+	{Name: "python", Command: []string{"python", "--version"}},
+	{Name: "go", Command: []string{"go", "version"}},
+}
+
+func newAboutCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "about",
+		Short: "Print information about the Pulumi environment",
+		Long: "Print information about the Pulumi environment.\n" +
+			"\n" +
+			"Prints the version of the CLI, the operating system and architecture it is running on, the\n" +
+			"versions of the language runtimes that Pulumi supports, the installed plugins, the current\n" +
+			"backend, and the current project and stack. This is useful to attach to bug reports, and to\n" +
+			"check what a CI image provides. Pass --json to print the same information as JSON.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			about, err := gatherAbout(runVersionCommand)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(about)
+			}
+			printAbout(about)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// gatherAbout collects information about the Pulumi environment, using the given function to run the commands that
+// print the versions of language runtimes. Nothing that it gathers requires logging into a backend.
+func gatherAbout(run func(name string, args ...string) (string, error)) (aboutJSON, error) {
+	about := aboutJSON{
+		CLI:      aboutCLI{Version: version.Version, GoVersion: runtime.Version()},
+		Platform: aboutPlatform{OS: runtime.GOOS, Arch: runtime.GOARCH},
+		Plugins:  []aboutPlugin{},
+	}
+
+	for _, rt := range aboutRuntimeCommands {
+		var v string
+		if out, err := run(rt.Command[0], rt.Command[1:]...); err == nil {
+			v = parseRuntimeVersion(out)
+		}
+		about.Runtimes = append(about.Runtimes, aboutRuntime{Name: rt.Name, Version: v})
+	}
+
+	plugins, err := workspace.GetPlugins()
+	if err != nil {
+		return aboutJSON{}, errors.Wrap(err, "loading plugins")
+	}
+	for _, plugin := range plugins {
+		p := aboutPlugin{Name: plugin.Name, Kind: string(plugin.Kind)}
+		if plugin.Version != nil {
+			p.Version = plugin.Version.String()
+		}
+		about.Plugins = append(about.Plugins, p)
+	}
+
+	// Report the backend that commands would use without logging into it, which might prompt for credentials.
+	about.Backend = defaultBackendURL()
+	if about.Backend == "" {
+		creds, err := workspace.GetStoredCredentials()
+		if err != nil {
+			return aboutJSON{}, err
+		}
+		about.Backend = creds.Current
+	}
+
+	if proj, err := workspace.DetectProject(); err == nil {
+		about.Project = &aboutProject{Name: string(proj.Name), Runtime: proj.Runtime.Name()}
+		if w, err := workspace.New(); err == nil {
+			about.Stack = w.Settings().Stack
+		}
+	}
+
+	return about, nil
+}
+
+// runVersionCommand runs the given command and returns its output, which some runtimes print to stderr.
+func runVersionCommand(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	return string(out), err
+}
+
+// parseRuntimeVersion extracts the version from the output of a runtime's version command, such as "v10.15.0",
+// "Python 3.7.2" or "go version go1.12 linux/amd64".
+func parseRuntimeVersion(out string) string {
+	fields := strings.Fields(out)
+	for _, f := range fields {
+		v := strings.TrimPrefix(strings.TrimPrefix(f, "go"), "v")
+		if v != "" && v[0] >= '0' && v[0] <= '9' {
+			return v
+		}
+	}
+	return strings.TrimSpace(out)
+}
+
+func printAbout(about aboutJSON) {
+	first := true
+	printSection := func(title string, table cmdutil.Table) {
+		if !first {
+			fmt.Println()
+		}
+		first = false
+		fmt.Println(title)
+		cmdutil.PrintTable(table)
+	}
+
+	cliVersion := about.CLI.Version
+	if cliVersion == "" {
+		cliVersion = "unknown"
+	}
+	printSection("CLI", cmdutil.Table{
+		Headers: []string{"VERSION", "GO VERSION", "OS", "ARCH"},
+		Rows: []cmdutil.TableRow{{Columns: []string{
+			cliVersion, about.CLI.GoVersion, about.Platform.OS, about.Platform.Arch,
+		}}},
+	})
+
+	var runtimes []cmdutil.TableRow
+	for _, rt := range about.Runtimes {
+		v := rt.Version
+		if v == "" {
+			v = "not found"
+		}
+		runtimes = append(runtimes, cmdutil.TableRow{Columns: []string{rt.Name, v}})
+	}
+	printSection("Language runtimes", cmdutil.Table{Headers: []string{"NAME", "VERSION"}, Rows: runtimes})
+
+	var plugins []cmdutil.TableRow
+	for _, p := range about.Plugins {
+		plugins = append(plugins, cmdutil.TableRow{Columns: []string{p.Name, p.Kind, p.Version}})
+	}
+	printSection("Plugins", cmdutil.Table{Headers: []string{"NAME", "KIND", "VERSION"}, Rows: plugins})
+
+	backend := about.Backend
+	if backend == "" {
+		backend = "not logged in"
+	}
+	project, runtimeName, stack := "none", "", about.Stack
+	if about.Project != nil {
+		project, runtimeName = about.Project.Name, about.Project.Runtime
+	}
+	if stack == "" {
+		stack = "none"
+	}
+	printSection("Current", cmdutil.Table{
+		Headers: []string{"BACKEND", "PROJECT", "RUNTIME", "STACK"},
+		Rows:    []cmdutil.TableRow{{Columns: []string{backend, project, runtimeName, stack}}},
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRuntimeVersion(t *testing.T) {
+	assert.Equal(t, "10.15.0", parseRuntimeVersion("v10.15.0\n"))
+	assert.Equal(t, "3.7.2", parseRuntimeVersion("Python 3.7.2\n"))
+	assert.Equal(t, "2.7.15", parseRuntimeVersion("Python 2.7.15\n"))
+	assert.Equal(t, "1.12", parseRuntimeVersion("go version go1.12 linux/amd64\n"))
+	assert.Equal(t, "unknown", parseRuntimeVersion(" unknown \n"))
+}
+
+func TestGatherAbout(t *testing.T) {
+	outputs := map[string]string{
+		"node": "v10.15.0\n",
+		"go":   "go version go1.12 linux/amd64\n",
+	}
+	about, err := gatherAbout(func(name string, args ...string) (string, error) {
+		out, has := outputs[name]
+		if !has {
+			return "", errors.New("executable file not found in $PATH")
+		}
+		return out, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, runtime.GOOS, about.Platform.OS)
+	assert.Equal(t, runtime.GOARCH, about.Platform.Arch)
+	assert.Equal(t, []aboutRuntime{
+		{Name: "nodejs", Version: "10.15.0"},
+		{Name: "python"},
+		{Name: "go", Version: "1.12"},
+	}, about.Runtimes)
+}
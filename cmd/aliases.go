@@ -0,0 +1,228 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// aliasChainEnvVar holds the comma-separated names of the aliases that are running, so that an alias that runs
+// itself, directly or through other aliases, fails instead of running forever.
+const aliasChainEnvVar = "PULUMI_ALIAS_CHAIN"
+
+// addAliasCommands adds a command to root for each of the user's command aliases and each of the aliases of the
+// project in the current directory. An alias that has the same name as a built-in command is ignored.
+func addAliasCommands(root *cobra.Command) {
+	aliases, err := workspace.GetCommandAliases()
+	if err != nil {
+		cmdutil.Diag().Warningf(diag.Message("" /*urn*/, "ignoring command aliases: %v"), err)
+		aliases = workspace.CommandAliases{}
+	}
+	// Errors loading the project are reported by the commands that need it.
+	if proj, err := workspace.DetectProject(); err == nil {
+		for name, steps := range proj.Aliases {
+			aliases[name] = steps
+		}
+	}
+
+	var names []string
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c, _, err := root.Find([]string{name}); err == nil && c != root {
+			// Only warn once, rather than again in each step of a running alias.
+			if os.Getenv(aliasChainEnvVar) == "" {
+				cmdutil.Diag().Warningf(diag.Message("", /*urn*/
+					"ignoring alias '%s', which has the same name as a pulumi command"), name)
+			}
+			continue
+		}
+		root.AddCommand(newAliasCmd(root, name, aliases[name]))
+	}
+}
+
+// newAliasCmd creates the command that runs an alias's steps.
+func newAliasCmd(root *cobra.Command, name string, steps []string) *cobra.Command {
+	return &cobra.Command{
+		Use:   name,
+		Short: "Alias for: " + strings.Join(steps, "; "),
+		Long: "Alias for:\n" +
+			"\n" +
+			"    " + strings.Join(steps, "\n    ") + "\n" +
+			"\n" +
+			"Runs each step in order, stopping at the first that fails. Global flags, such as --cwd and --backend,\n" +
+			"are passed to every pulumi command; any other arguments are passed to the last pulumi command.",
+		// Flags are passed through to the alias's steps, which parse them.
+		DisableFlagParsing: true,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			return runAlias(root, name, steps, args)
+		}),
+	}
+}
+
+// runAlias runs the steps of the named alias with the given arguments. Steps that start with "!" are run as shell
+// commands; the others are run as pulumi commands, each in its own process.
+func runAlias(root *cobra.Command, name string, steps []string, args []string) error {
+	chain := os.Getenv(aliasChainEnvVar)
+	if chain != "" {
+		for _, running := range strings.Split(chain, ",") {
+			if running == name {
+				return errors.Errorf("alias '%s' runs itself", name)
+			}
+		}
+		chain += ","
+	}
+	env := append(os.Environ(), aliasChainEnvVar+"="+chain+name)
+
+	global, rest := splitAliasArgs(root.PersistentFlags(), args)
+	last := -1
+	for i, step := range steps {
+		if !strings.HasPrefix(step, "!") {
+			last = i
+		}
+	}
+	if last == -1 && len(rest) > 0 {
+		return errors.Errorf("alias '%s' only runs shell commands, so does not take arguments", name)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "finding the pulumi executable")
+	}
+
+	label := "alias " + name
+	for i, step := range steps {
+		if strings.HasPrefix(step, "!") {
+			if err = runShellCommands(label, []string{step[1:]}, "", env, os.Stdout); err != nil {
+				return err
+			}
+			continue
+		}
+
+		stepArgs, err := splitCommandLine(step)
+		if err != nil {
+			return errors.Wrapf(err, "invalid step '%s' of alias '%s'", step, name)
+		}
+		stepArgs = append(stepArgs, global...)
+		if i == last {
+			stepArgs = append(stepArgs, rest...)
+		}
+
+		fmt.Printf("Running %s: pulumi %s\n", label, strings.Join(stepArgs, " "))
+		c := exec.Command(exe, stepArgs...)
+		c.Env = env
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err = c.Run(); err != nil {
+			return errors.Wrapf(err, "%s 'pulumi %s' failed", label, step)
+		}
+	}
+	return nil
+}
+
+// splitAliasArgs separates the arguments given to an alias into the global flags in flags, along with their values,
+// and the remaining arguments.
+func splitAliasArgs(flags *pflag.FlagSet, args []string) ([]string, []string) {
+	var global, rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		var flag *pflag.Flag
+		inline := strings.Contains(arg, "=")
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			flag = flags.Lookup(strings.SplitN(arg[2:], "=", 2)[0])
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			flag = flags.ShorthandLookup(arg[1:2])
+			inline = inline || len(arg) > 2
+		}
+		if flag == nil {
+			rest = append(rest, arg)
+			continue
+		}
+
+		global = append(global, arg)
+		if !inline && flag.NoOptDefVal == "" && i+1 < len(args) {
+			i++
+			global = append(global, args[i])
+		}
+	}
+	return global, rest
+}
+
+// splitCommandLine splits a command line into its arguments as a POSIX shell would, honoring single quotes, double
+// quotes, and backslash escapes, but without expanding variables or globs.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	args, err := splitCommandLine(`up --yes -m "Deploy to production"  --config 'name=it''s'`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"up", "--yes", "-m", "Deploy to production", "--config", "name=its"}, args)
+
+	args, err = splitCommandLine(`config set greeting hello\ world ""`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config", "set", "greeting", "hello world", ""}, args)
+
+	_, err = splitCommandLine(`up -m "unterminated`)
+	assert.Error(t, err)
+}
+
+func TestSplitAliasArgs(t *testing.T) {
+	var cwd, backend string
+	var emoji bool
+	var verbose verbosityFlag
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVarP(&cwd, "cwd", "C", "", "")
+	flags.StringVar(&backend, "backend", "", "")
+	flags.BoolVarP(&emoji, "emoji", "e", false, "")
+	flags.VarP(&verbose, "verbose", "v", "")
+	flags.Lookup("verbose").NoOptDefVal = "+1"
+
+	global, rest := splitAliasArgs(flags, []string{
+		"-C", "infra", "--yes", "--backend=file://", "-vv", "-m", "msg", "-e", "--cwd", "x", "-Cy", "--", "-C",
+	})
+	assert.Equal(t, []string{"-C", "infra", "--backend=file://", "-vv", "-e", "--cwd", "x", "-Cy"}, global)
+	assert.Equal(t, []string{"--yes", "-m", "msg", "--", "-C"}, rest)
+}
@@ -15,12 +15,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
@@ -36,6 +39,8 @@ func newCancelCmd() *cobra.Command {
 		Long: "Cancel a stack's currently running update, if any.\n" +
 			"\n" +
 			"This command cancels the update currently being applied to a stack if any exists.\n" +
+			"For stacks stored locally, this removes the lock left behind by an update whose process\n" +
+			"was killed before the update completed; it does not stop a process that is still running.\n" +
 			"Note that this operation is _very dangerous_, and may leave the stack in an\n" +
 			"inconsistent state if a resource operation was pending when the update was canceled.\n" +
 			"\n" +
@@ -60,21 +65,27 @@ func newCancelCmd() *cobra.Command {
 				return err
 			}
 
-			// Ensure that we are targeting the Pulumi cloud.
-			backend, ok := s.Backend().(httpstate.Backend)
-			if !ok {
-				return errors.New("the `cancel` command is not supported for local stacks")
+			// Local stacks are locked while they are being updated; canceling an update of a local stack removes
+			// the lock left behind by a process that was killed before its update completed.
+			var cancelCurrentUpdate func(context.Context, backend.StackReference) error
+			switch b := s.Backend().(type) {
+			case httpstate.Backend:
+				cancelCurrentUpdate = b.CancelCurrentUpdate
+			case filestate.Backend:
+				cancelCurrentUpdate = b.CancelCurrentUpdate
+			default:
+				return errors.Errorf("the `cancel` command is not supported by the %s backend", b.Name())
 			}
 
 			// Ensure the user really wants to do this.
 			stackName := string(s.Ref().Name())
 			prompt := fmt.Sprintf("This will irreversibly cancel the currently running update for '%s'!", stackName)
 			if !yes && !confirmPrompt(prompt, stackName, opts) {
-				return errors.New("confirmation declined")
+				return confirmationDeclined()
 			}
 
 			// Cancel the update.
-			if err := backend.CancelCurrentUpdate(commandContext(), s.Ref()); err != nil {
+			if err := cancelCurrentUpdate(commandContext(), s.Ref()); err != nil {
 				return err
 			}
 
@@ -15,7 +15,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -33,6 +32,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -40,6 +40,7 @@ func newConfigCmd() *cobra.Command {
 	var stack string
 	var showSecrets bool
 	var jsonOut bool
+	var full bool
 
 	cmd := &cobra.Command{
 		Use:   "config",
@@ -58,7 +59,7 @@ func newConfigCmd() *cobra.Command {
 				return err
 			}
 
-			return listConfig(stack, showSecrets, jsonOut)
+			return listConfig(stack, showSecrets, jsonOut, full)
 		}),
 	}
 
@@ -68,6 +69,9 @@ func newConfigCmd() *cobra.Command {
 	cmd.Flags().BoolVarP(
 		&jsonOut, "json", "j", false,
 		"Emit output as JSON")
+	cmd.Flags().BoolVar(
+		&full, "full", false,
+		"Show long values in full, wrapped onto more lines, instead of truncating them to fit the terminal")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
@@ -333,6 +337,10 @@ func loadProjectStack(stack backend.Stack) (*workspace.ProjectStack, error) {
 }
 
 func saveProjectStack(stack backend.Stack, ps *workspace.ProjectStack) error {
+	err := backend.CheckWritable(stack.Backend(), "change the configuration of stack '%s'", stack.Ref())
+	if err != nil {
+		return err
+	}
 	if stackConfigFile == "" {
 		return workspace.SaveProjectStack(stack.Ref().Name(), ps)
 	}
@@ -379,7 +387,7 @@ type configValueJSON struct {
 	Secret bool    `json:"secret"`
 }
 
-func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
+func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool, full bool) error {
 	ps, err := loadProjectStack(stack)
 	if err != nil {
 		return err
@@ -428,11 +436,9 @@ func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
 
 			configValues[key.String()] = entry
 		}
-		out, err := json.MarshalIndent(configValues, "", "  ")
-		if err != nil {
+		if err := printJSON(configValues); err != nil {
 			return err
 		}
-		fmt.Println(string(out))
 	} else {
 		rows := []cmdutil.TableRow{}
 		for _, key := range keys {
@@ -447,6 +453,7 @@ func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
 		cmdutil.PrintTable(cmdutil.Table{
 			Headers: []string{"KEY", "VALUE"},
 			Rows:    rows,
+			Full:    full,
 		})
 	}
 
@@ -482,11 +489,9 @@ func getConfig(stack backend.Stack, key config.Key, jsonOut bool) error {
 				Secret: v.Secure(),
 			}
 
-			out, err := json.MarshalIndent(value, "", "  ")
-			if err != nil {
+			if err := printJSON(value); err != nil {
 				return err
 			}
-			fmt.Println(string(out))
 		} else {
 			fmt.Printf("%v\n", raw)
 		}
@@ -494,8 +499,16 @@ func getConfig(stack backend.Stack, key config.Key, jsonOut bool) error {
 		return nil
 	}
 
-	return errors.Errorf(
-		"configuration key '%s' not found for stack '%s'", prettyKey(key), stack.Ref())
+	var keys []string
+	for k := range cfg {
+		keys = append(keys, prettyKey(k))
+	}
+	hint := errutil.DidYouMean(prettyKey(key), keys)
+	if hint == "" {
+		hint = "run `pulumi config` to list the stack's configuration"
+	}
+	return errutil.WithCode(errutil.ErrConfigKeyNotFound, hint, errors.Errorf(
+		"configuration key '%s' not found for stack '%s'", prettyKey(key), stack.Ref()))
 }
 
 var (
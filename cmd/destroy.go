@@ -29,6 +29,7 @@ import (
 
 func newDestroyCmd() *cobra.Command {
 	var debug bool
+	var eventLog string
 	var stack string
 
 	var message string
@@ -36,13 +37,17 @@ func newDestroyCmd() *cobra.Command {
 	// Flags for engine.UpdateOptions.
 	var analyzers []string
 	var diffDisplay bool
+	var jsonDisplay bool
+	var progressFormat string
 	var parallel int
 	var refresh bool
 	var showConfig bool
 	var showReplacementSteps bool
 	var showSames bool
+	var showTimings bool
 	var skipPreview bool
 	var suppressOutputs bool
+	var targets []string
 	var yes bool
 
 	var cmd = &cobra.Command{
@@ -55,12 +60,19 @@ func newDestroyCmd() *cobra.Command {
 			"loaded from the associated state file in the workspace.  After running to completion,\n" +
 			"all of this stack's resources and associated state will be gone.\n" +
 			"\n" +
+			"Use --target to destroy only specific resources, along with any resources that depend on them\n" +
+			"and their children. All other resources in the stack are left intact.\n" +
+			"\n" +
+			"Before proceeding, the full ordered list of resources to be deleted is shown, and you are asked to\n" +
+			"confirm. When running non-interactively, --yes must be passed to proceed.\n" +
+			"\n" +
 			"Warning: this command is generally irreversible and should be used with great care.",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) *result.Result {
+			// Unlike other updates, a destroy requires --yes in non-interactive mode even if its preview is skipped.
 			interactive := cmdutil.Interactive()
-			if !interactive {
-				yes = true // auto-approve changes, since we cannot prompt.
+			if !interactive && !yes {
+				return result.FromError(errNonInteractiveConfirmation)
 			}
 
 			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
@@ -73,10 +85,22 @@ func newDestroyCmd() *cobra.Command {
 				ShowConfig:           showConfig,
 				ShowReplacementSteps: showReplacementSteps,
 				ShowSameResources:    showSames,
+				ShowTimings:          showTimings,
 				SuppressOutputs:      suppressOutputs,
 				IsInteractive:        interactive,
 				DiffDisplay:          diffDisplay,
 				Debug:                debug,
+				EventLogPath:         eventLog,
+			}
+			if err = jsonFlagsToOptions(&opts, jsonDisplay); err != nil {
+				return result.FromError(err)
+			}
+			if err = progressFormatToOptions(&opts, progressFormat, false /*isPreview*/); err != nil {
+				return result.FromError(err)
+			}
+
+			if err := resetEventLog(eventLog); err != nil {
+				return result.FromError(err)
 			}
 
 			s, err := requireStack(stack, false, opts.Display, true /*setCurrent*/)
@@ -98,6 +122,8 @@ func newDestroyCmd() *cobra.Command {
 				Parallel:  parallel,
 				Debug:     debug,
 				Refresh:   refresh,
+
+				DestroyTargets: targetURNs(targets),
 			}
 
 			_, err = s.Destroy(commandContext(), backend.UpdateOperation{
@@ -117,6 +143,9 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLog, "event-log", "",
+		"Log every engine event to the given file as newline-delimited JSON")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
@@ -134,6 +163,13 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonDisplay, "json", "j", false,
+		"Serialize the destroy's operations and overall output as JSON. Requires --yes, and skips the preview")
+	cmd.PersistentFlags().StringVar(
+		&progressFormat, "progress-format", "text",
+		"Display progress as 'text', or as 'json': newline-delimited JSON events on stdout, "+
+			"for other programs to render")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
@@ -149,12 +185,20 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that don't need to be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&showTimings, "show-timings", false,
+		"Show the slowest resources, the critical path of resources that determined how long the destroy took, and "+
+			"the number and latency of the calls made to each provider")
 	cmd.PersistentFlags().BoolVar(
 		&skipPreview, "skip-preview", false,
 		"Do not perform a preview before performing the destroy")
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
+	cmd.PersistentFlags().StringSliceVarP(
+		&targets, "target", "t", []string{},
+		"Specify a single resource URN to destroy, along with its dependents and children. "+
+			"Multiple resources can be specified using --target urn1 --target urn2")
 	cmd.PersistentFlags().BoolVarP(
 		&yes, "yes", "y", false,
 		"Automatically approve and perform the destroy after previewing it")
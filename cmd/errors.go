@@ -47,8 +47,8 @@ func printPendingOperationsError(e deploy.PlanPendingOperationsError) {
 	}
 
 	fprintf(writer, `
-These resources are in an unknown state because the Pulumi CLI was interrupted while
-waiting for changes to these resources to complete. You should confirm whether or not the
+These resources are in an unknown state because the Pulumi CLI was interrupted, or timed out,
+while waiting for changes to these resources to complete. You should confirm whether or not the
 operations listed completed successfully by checking the state of the appropriate provider.
 For example, if you are using AWS, you can confirm using the AWS Console.
 
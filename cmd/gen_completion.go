@@ -15,14 +15,20 @@
 package cmd
 
 import (
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
 // newCompletionCmd returns a new command that, when run, generates a bash or zsh completion script for the CLI.
@@ -34,6 +40,8 @@ func newGenCompletionCmd(root *cobra.Command) *cobra.Command {
 		Short:  "Generate completion scripts for the Pulumi CLI",
 		Hidden: true,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			addDynamicCompletions(root)
+
 			switch {
 			case args[0] == "bash":
 				return root.GenBashCompletion(os.Stdout)
@@ -46,6 +54,140 @@ func newGenCompletionCmd(root *cobra.Command) *cobra.Command {
 	}
 }
 
+// bashCompletionFunc defines the shell functions that complete stack names and configuration keys. They ask the CLI
+// itself, by way of the hidden complete-values command, so that the values come from the current project and backend.
+const bashCompletionFunc = `__pulumi_complete_values()
+{
+    local pulumi_out
+    if pulumi_out=$(pulumi complete-values "$@" 2>/dev/null); then
+        COMPREPLY=( $(compgen -W "${pulumi_out[*]}" -- "$cur") )
+    fi
+}
+
+__pulumi_complete_stacks()
+{
+    __pulumi_complete_values stacks
+}
+
+__pulumi_complete_config_keys()
+{
+    local stack=${flaghash[--stack]:-${flaghash[--stack=]:-${flaghash[-s]}}}
+    if [[ -n ${stack} ]]; then
+        __pulumi_complete_values config-keys --stack "${stack}"
+    else
+        __pulumi_complete_values config-keys
+    fi
+}
+
+__custom_func()
+{
+    case ${last_command} in
+        pulumi_config_get | pulumi_config_rm)
+            __pulumi_complete_config_keys
+            ;;
+        pulumi_stack_rm | pulumi_stack_select)
+            __pulumi_complete_stacks
+            ;;
+    esac
+}
+`
+
+// addDynamicCompletions hooks the functions in bashCompletionFunc into the completion script generated for root: the
+// values of every --stack flag complete to the known stacks, and arguments fall back to __custom_func.
+func addDynamicCompletions(root *cobra.Command) {
+	root.BashCompletionFunction = bashCompletionFunc
+
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		if cmd.LocalFlags().Lookup("stack") != nil {
+			err := cmd.LocalFlags().SetAnnotation("stack", cobra.BashCompCustom, []string{"__pulumi_complete_stacks"})
+			contract.IgnoreError(err)
+		}
+		for _, child := range cmd.Commands() {
+			visit(child)
+		}
+	}
+	visit(root)
+}
+
+// newCompleteValuesCmd returns a new command that prints, one per line, the values that the completion scripts offer
+// for stack names or configuration keys. Like gen-completion, it is hidden, as it is only meant to be run by them.
+func newCompleteValuesCmd() *cobra.Command {
+	var stack string
+
+	cmd := &cobra.Command{
+		Use:    "complete-values <KIND>",
+		Args:   cmdutil.ExactArgs(1),
+		Short:  "Print the stack names or configuration keys that shell completion offers",
+		Hidden: true,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			// Completion runs behind the user's back, so it must never stop to prompt.
+			cmdutil.DisableInteractive = true
+			opts := display.Options{Color: colors.Never}
+
+			var values []string
+			var err error
+			switch args[0] {
+			case "stacks":
+				values, err = completeStackNames(opts)
+			case "config-keys":
+				values, err = completeConfigKeys(stack, opts)
+			default:
+				return errors.Errorf("%q is not a supported kind of value; expected stacks or config-keys", args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			for _, v := range values {
+				fmt.Println(v)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(
+		&stack, "stack", "s", "",
+		"The stack whose configuration keys to print; defaults to the current stack")
+
+	return cmd
+}
+
+// completeStackNames returns the names of the stacks in the current backend, sorted. Inside of a project, only that
+// project's stacks are returned.
+func completeStackNames(opts display.Options) ([]string, error) {
+	b, err := currentBackend(opts)
+	if err != nil {
+		return nil, err
+	}
+	return listStackNames(b)
+}
+
+// completeConfigKeys returns the keys of the given stack's configuration, or the current stack's if stackName is
+// empty, sorted and in the form that `pulumi config` prints them.
+func completeConfigKeys(stackName string, opts display.Options) ([]string, error) {
+	s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys config.KeyArray
+	for key := range ps.Config {
+		keys = append(keys, key)
+	}
+	sort.Sort(keys)
+
+	var names []string
+	for _, key := range keys {
+		names = append(names, prettyKey(key))
+	}
+	return names, nil
+}
+
 const (
 	// Inspired by https://github.com/kubernetes/kubernetes/blob/master/pkg/kubectl/cmd/completion.go
 	zshHead = `#compdef pulumi
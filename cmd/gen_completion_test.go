@@ -0,0 +1,50 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamicCompletions(t *testing.T) {
+	root := NewPulumiCmd()
+	addDynamicCompletions(root)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, root.GenBashCompletion(buf))
+	script := buf.String()
+
+	// The completion functions are included in the script.
+	assert.True(t, strings.Contains(script, "__pulumi_complete_config_keys()"))
+	assert.True(t, strings.Contains(script, "__custom_func()"))
+
+	// --stack and -s complete to stack names, both where they are declared and where they are inherited.
+	for _, command := range []string{"_pulumi_up()", "_pulumi_config_rm()", "_pulumi_stack_output()"} {
+		start := strings.Index(script, command)
+		if !assert.True(t, start >= 0, command) {
+			continue
+		}
+		body := script[start:]
+		body = body[:strings.Index(body, "\n}\n")]
+		assert.True(t, strings.Contains(body,
+			"flags_with_completion+=(\"--stack\")\n    flags_completion+=(\"__pulumi_complete_stacks\")"), command)
+		assert.True(t, strings.Contains(body,
+			"flags_with_completion+=(\"-s\")\n    flags_completion+=(\"__pulumi_complete_stacks\")"), command)
+	}
+}
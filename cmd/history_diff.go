@@ -0,0 +1,152 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newHistoryDiffCmd(stackName *string) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "diff <version1> <version2>",
+		Short: "Show the differences between two recorded deployments of a stack",
+		Long: "Show the differences between two recorded deployments of a stack.\n" +
+			"\n" +
+			"This command compares the resources recorded by two versions of a stack's update history,\n" +
+			"as listed by `pulumi history`, and prints the resources that were added, removed, or changed\n" +
+			"in between, along with their property-level differences.",
+		Args: cmdutil.ExactArgs(2),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			v1, err := parseHistoryVersion(args[0])
+			if err != nil {
+				return err
+			}
+			v2, err := parseHistoryVersion(args[1])
+			if err != nil {
+				return err
+			}
+
+			s, err := requireStack(*stackName, false /*offerNew */, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			from, err := getHistoricalSnapshot(s, v1)
+			if err != nil {
+				return err
+			}
+			to, err := getHistoricalSnapshot(s, v2)
+			if err != nil {
+				return err
+			}
+
+			displaySnapshotDiff(deploy.DiffSnapshots(from, to), opts)
+			return nil
+		}),
+	}
+
+	return cmd
+}
+
+// parseHistoryVersion parses a stack version number as given on the command line.
+func parseHistoryVersion(arg string) (int, error) {
+	version, err := strconv.Atoi(arg)
+	if err != nil || version < 1 {
+		return 0, errors.Errorf("invalid version '%s': versions must be positive integers", arg)
+	}
+	return version, nil
+}
+
+// getHistoricalSnapshot loads the snapshot recorded by the given version of the stack's update history.
+func getHistoricalSnapshot(s backend.Stack, version int) (*deploy.Snapshot, error) {
+	deployment, err := s.Backend().ExportHistoricalDeployment(commandContext(), s.Ref(), version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting deployment for version %d", version)
+	}
+	snap, err := stack.DeserializeUntypedDeployment(deployment)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading deployment for version %d", version)
+	}
+	return snap, nil
+}
+
+func displaySnapshotDiff(diff *deploy.SnapshotDiff, opts display.Options) {
+	if !diff.AnyChanges() {
+		fmt.Println("No differences")
+		return
+	}
+
+	printResource := func(op deploy.StepOp, res *resource.State) {
+		fmt.Print(opts.Color.Colorize(fmt.Sprintf("%s %s %s%s\n", op.Prefix(), res.Type, res.URN.Name(), colors.Reset)))
+	}
+
+	for _, res := range diff.Adds {
+		printResource(deploy.OpCreate, res)
+	}
+	for _, res := range diff.Deletes {
+		printResource(deploy.OpDelete, res)
+	}
+	for _, update := range diff.Updates {
+		op := deploy.OpUpdate
+		if update.Replaced {
+			op = deploy.OpReplace
+		}
+		printResource(op, update.New)
+
+		details := engine.GetResourcePropertiesDetails(engine.StepEventMetadata{
+			Op:   op,
+			URN:  update.New.URN,
+			Type: update.New.Type,
+			Old:  historyStateMetadata(update.Old),
+			New:  historyStateMetadata(update.New),
+		}, 1 /*indent*/, false /*planning*/, false /*summary*/, false /*debug*/)
+		fmt.Print(opts.Color.Colorize(details))
+	}
+
+	fmt.Println("")
+	fmt.Printf("%d added, %d removed, %d changed\n", len(diff.Adds), len(diff.Deletes), len(diff.Updates))
+}
+
+func historyStateMetadata(state *resource.State) *engine.StepEventStateMetadata {
+	return &engine.StepEventStateMetadata{
+		Type:     state.Type,
+		URN:      state.URN,
+		Custom:   state.Custom,
+		Delete:   state.Delete,
+		ID:       state.ID,
+		Parent:   state.Parent,
+		Protect:  state.Protect,
+		Inputs:   state.Inputs,
+		Outputs:  state.Outputs,
+		Provider: state.Provider,
+	}
+}
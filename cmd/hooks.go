@@ -0,0 +1,143 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// Names of the project hooks, as they appear in Pulumi.yaml.
+const (
+	prePreviewHook = "prePreview"
+	preUpdateHook  = "preUpdate"
+	postUpdateHook = "postUpdate"
+	onFailureHook  = "onFailure"
+)
+
+// projectHookCommands returns the commands that a project declares for the named hook.
+func projectHookCommands(proj *workspace.Project, hook string) []string {
+	if proj.Hooks == nil {
+		return nil
+	}
+	switch hook {
+	case prePreviewHook:
+		return proj.Hooks.PrePreview
+	case preUpdateHook:
+		return proj.Hooks.PreUpdate
+	case postUpdateHook:
+		return proj.Hooks.PostUpdate
+	case onFailureHook:
+		return proj.Hooks.OnFailure
+	default:
+		return nil
+	}
+}
+
+// projectHookEnv returns the environment in which a hook's commands run: the CLI's own environment plus variables
+// that describe the project and stack. If the hook is running because an update failed, updateErr is that failure.
+func projectHookEnv(proj *workspace.Project, stackName tokens.QName, hook string, updateErr error) []string {
+	env := append(os.Environ(),
+		"PULUMI_HOOK="+hook,
+		"PULUMI_PROJECT="+string(proj.Name),
+		"PULUMI_STACK="+string(stackName))
+	if updateErr != nil {
+		env = append(env, "PULUMI_ERROR="+updateErr.Error())
+	}
+	return env
+}
+
+// runProjectHook runs each of the commands that a project declares for the named hook, in order, from the project's
+// root directory. Commands write to out and to stderr. The first command to fail stops the hook and its error is
+// returned.
+func runProjectHook(proj *workspace.Project, root string, s backend.Stack, hook string, updateErr error,
+	out io.Writer) error {
+
+	commands := projectHookCommands(proj, hook)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	env := projectHookEnv(proj, s.Ref().Name(), hook, updateErr)
+	return runShellCommands(hook+" hook", commands, root, env, out)
+}
+
+// runShellCommands runs each of the given commands in order, from the given directory and with the given environment,
+// describing each with label. Commands write to out and to stderr. The first command to fail stops the remaining
+// commands and its error is returned.
+func runShellCommands(label string, commands []string, root string, env []string, out io.Writer) error {
+	for _, command := range commands {
+		fmt.Fprintf(out, "Running %s: %s\n", label, command)
+
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			c = exec.Command("cmd", "/C", command)
+		} else {
+			c = exec.Command("sh", "-c", command)
+		}
+		c.Dir, c.Env = root, env
+		c.Stdout, c.Stderr = out, os.Stderr
+		if err := c.Run(); err != nil {
+			return errors.Wrapf(err, "%s '%s' failed", label, command)
+		}
+	}
+	return nil
+}
+
+// hookOutput returns the writer to which the output of hooks run around an operation with the given options is written.
+// This is stdout, unless the operation's result or progress is emitted on stdout as JSON.
+func hookOutput(opts backend.UpdateOptions) io.Writer {
+	if opts.Display.IsJSON() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// updateWithHooks runs an update between the project's preUpdate hook and its postUpdate or onFailure hook. If the
+// preUpdate hook fails, the update does not run. If the update is declined when confirming it, neither of the other
+// hooks runs, as nothing has failed. A failing onFailure hook is reported as a warning so that it does not mask the
+// update's own error. The hooks' output is written to out.
+func updateWithHooks(proj *workspace.Project, root string, s backend.Stack, out io.Writer,
+	update func() (engine.ResourceChanges, error)) (engine.ResourceChanges, error) {
+
+	if err := runProjectHook(proj, root, s, preUpdateHook, nil, out); err != nil {
+		return nil, err
+	}
+
+	changes, err := update()
+	if _, declined := errors.Cause(err).(backend.ConfirmationDeclinedError); declined {
+		return changes, err
+	}
+	if err != nil {
+		if hookErr := runProjectHook(proj, root, s, onFailureHook, err, out); hookErr != nil {
+			cmdutil.Diag().Warningf(diag.RawMessage("" /*urn*/, hookErr.Error()))
+		}
+		return changes, err
+	}
+
+	return changes, runProjectHook(proj, root, s, postUpdateHook, nil, out)
+}
@@ -0,0 +1,96 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestProjectHookCommands(t *testing.T) {
+	proj := &workspace.Project{Name: "proj"}
+	assert.Nil(t, projectHookCommands(proj, preUpdateHook))
+
+	proj.Hooks = &workspace.ProjectHooks{
+		PrePreview: []string{"make check"},
+		PreUpdate:  []string{"make build", "make migrate"},
+		OnFailure:  []string{"./notify.sh"},
+	}
+	assert.Equal(t, []string{"make check"}, projectHookCommands(proj, prePreviewHook))
+	assert.Equal(t, []string{"make build", "make migrate"}, projectHookCommands(proj, preUpdateHook))
+	assert.Nil(t, projectHookCommands(proj, postUpdateHook))
+	assert.Equal(t, []string{"./notify.sh"}, projectHookCommands(proj, onFailureHook))
+}
+
+func TestProjectHookEnv(t *testing.T) {
+	proj := &workspace.Project{Name: "proj"}
+	env := projectHookEnv(proj, "dev", postUpdateHook, nil)
+	assert.Contains(t, env, "PULUMI_HOOK=postUpdate")
+	assert.Contains(t, env, "PULUMI_PROJECT=proj")
+	assert.Contains(t, env, "PULUMI_STACK=dev")
+	for _, v := range env {
+		assert.NotContains(t, v, "PULUMI_ERROR=")
+	}
+
+	env = projectHookEnv(proj, "dev", onFailureHook, errors.New("boom"))
+	assert.Contains(t, env, "PULUMI_ERROR=boom")
+}
+
+// hookTestStack is a stack that is only good for naming in the environment of hooks.
+type hookTestStack struct {
+	backend.Stack
+}
+
+func (hookTestStack) Ref() backend.StackReference { return hookTestStackRef{} }
+
+type hookTestStackRef struct{}
+
+func (hookTestStackRef) String() string     { return "dev" }
+func (hookTestStackRef) Name() tokens.QName { return "dev" }
+
+func TestUpdateWithHooksOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook is a POSIX shell command")
+	}
+
+	proj := &workspace.Project{
+		Name:  "proj",
+		Hooks: &workspace.ProjectHooks{OnFailure: []string{"echo failed"}},
+	}
+	run := func(updateErr error) string {
+		var out bytes.Buffer
+		_, err := updateWithHooks(proj, "", hookTestStack{}, &out, func() (engine.ResourceChanges, error) {
+			return nil, updateErr
+		})
+		assert.Equal(t, updateErr, err)
+		return out.String()
+	}
+
+	// A failed update runs the onFailure hook...
+	assert.Contains(t, run(errors.New("boom")), "failed")
+
+	// ...but a declined one does not, as nothing has failed.
+	assert.Equal(t, "", run(backend.ConfirmationDeclinedError{Kind: apitype.UpdateUpdate}))
+}
@@ -16,6 +16,9 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -25,11 +28,15 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 func newLoginCmd() *cobra.Command {
 	var cloudURL string
 	var localMode bool
+	var tokenFile string
+	var ssoIssuer string
+	var ssoClientID string
 
 	cmd := &cobra.Command{
 		Use:   "login [<url>]",
@@ -41,7 +48,8 @@ func newLoginCmd() *cobra.Command {
 			"    $ pulumi login\n" +
 			"\n" +
 			"and this command will prompt you for an access token, including a way to launch your web browser to\n" +
-			"easily obtain one. You can script by using PULUMI_ACCESS_TOKEN environment variable.\n" +
+			"easily obtain one. You can script by using PULUMI_ACCESS_TOKEN environment variable, or by passing\n" +
+			"--token-file with the path of a file that contains the access token.\n" +
 			"\n" +
 			"By default, this will log into app.pulumi.com. If you prefer to log into a separate instance\n" +
 			"of the Pulumi service, such as Pulumi Enterprise, specify a <url>. For example, run\n" +
@@ -59,35 +67,101 @@ func newLoginCmd() *cobra.Command {
 			"will store your state information on your computer underneath ~/.pulumi. It is then up to you to\n" +
 			"manage this state, including backing it up, using it in a team environment, and so on.\n" +
 			"\n" +
+			"State may also be stored in an Azure Blob Storage container by passing azblob://<container>/<prefix>.\n" +
+			"The storage account and its credentials are read from AZURE_STORAGE_CONNECTION_STRING or, failing\n" +
+			"that, from AZURE_STORAGE_ACCOUNT along with one of AZURE_STORAGE_KEY, AZURE_STORAGE_SAS_TOKEN, or\n" +
+			"the Azure AD service principal named by AZURE_TENANT_ID, AZURE_CLIENT_ID, and AZURE_CLIENT_SECRET.\n" +
+			"For instance,\n" +
+			"\n" +
+			"    $ pulumi login azblob://pulumi-state/my-team\n" +
+			"\n" +
+			"Likewise, state may be stored in a Google Cloud Storage bucket by passing gs://<bucket>/<prefix>, in\n" +
+			"which case the application default credentials are used to access the bucket.\n" +
+			"\n" +
+			"Finally, state may be stored in a service of your own that speaks Pulumi's REST state protocol, by\n" +
+			"passing rest+https://<host>/<path>. If PULUMI_REST_BACKEND_TOKEN is set, it is sent to the service as\n" +
+			"a bearer token. For instance,\n" +
+			"\n" +
+			"    $ pulumi login rest+https://state.acmecorp.com/pulumi\n" +
+			"\n" +
 			"As a shortcut, you may pass --local to use your home directory (this is an alias for file://~):\n" +
 			"\n" +
-			"    $ pulumi login --local\n",
+			"    $ pulumi login --local\n" +
+			"\n" +
+			"You may be logged into several backends at once; each login is remembered in\n" +
+			"~/.pulumi/credentials.json, and the most recent one becomes the current backend. To run a single\n" +
+			"command against another backend that you have logged into, without changing the current one, pass\n" +
+			"its URL to that command's --backend:\n" +
+			"\n" +
+			"    $ pulumi stack ls --backend file://~\n" +
+			"\n" +
+			"To inspect stacks without any risk of changing them, as auditors and dashboards do, pass --read-only.\n" +
+			"Commands then refuse every operation that would change a stack, such as updates, destroys, imports,\n" +
+			"and configuration changes, until you log in again without it. Pair it with credentials that only grant\n" +
+			"read access to have the backend enforce the same restriction.\n" +
+			"\n" +
+			"Organizations whose Pulumi Enterprise server accepts tokens from their OpenID Connect identity\n" +
+			"provider may log in through single sign-on instead of with a personal access token, by passing the\n" +
+			"provider's issuer URL (or setting PULUMI_SSO_ISSUER):\n" +
+			"\n" +
+			"    $ pulumi login https://pulumi.acmecorp.com --sso-issuer https://login.acmecorp.com\n" +
+			"\n" +
+			"This prints a code to enter at the provider's verification page, which may be opened on any device,\n" +
+			"and waits for the login to be approved. The short-lived access tokens that the provider issues are\n" +
+			"refreshed as they expire, and `pulumi login` logs in through the same provider again once they can\n" +
+			"no longer be.\n" +
+			"The CLI identifies itself to the provider as the client pulumi-cli, unless --sso-client-id (or\n" +
+			"PULUMI_SSO_CLIENT_ID) names another.\n" +
+			"\n" +
+			"Access tokens are kept in your operating system's keychain (the macOS keychain, or the Secret Service\n" +
+			"via secret-tool on Linux) when one is available, and otherwise in ~/.pulumi/credentials.json. Pass\n" +
+			"--insecure-storage to keep the access token in ~/.pulumi/credentials.json even if there is a keychain.\n" +
+			"\n" +
+			"A project may also name the backend in which its stacks are stored, in its Pulumi.yaml:\n" +
+			"\n" +
+			"    backend:\n" +
+			"      url: https://pulumi.acmecorp.com\n" +
+			"\n" +
+			"Commands run in the project then use that backend, and `pulumi login` without a <url> logs into it.\n",
 		Args: cmdutil.MaximumNArgs(1),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			displayOptions := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
-			// If a <cloud> was specified as an argument, use it.
-			if len(args) > 0 {
-				if cloudURL != "" {
-					return errors.New("only one of --cloud-url or argument URL may be specified, not both")
-				}
-				cloudURL = args[0]
+			var err error
+			if cloudURL, err = loginURL(args, cloudURL, localMode); err != nil {
+				return err
+			}
+			if err = checkOfflineBackend(cloudURL); err != nil {
+				return err
 			}
 
-			// For local mode, store state by default in the user's home directory.
-			if localMode {
-				if cloudURL != "" {
-					return errors.New("a URL may not be specified when --local mode is enabled")
-				}
-				cloudURL = "file://~"
+			if ssoIssuer == "" {
+				ssoIssuer = os.Getenv(httpstate.SSOIssuerEnvVar)
+			}
+			if ssoClientID == "" {
+				ssoClientID = os.Getenv(httpstate.SSOClientIDEnvVar)
 			}
 
 			var be backend.Backend
-			var err error
 			if filestate.IsLocalBackendURL(cloudURL) {
+				if tokenFile != "" {
+					return errors.New("--token-file may only be used to log into the Pulumi service")
+				}
 				be, err = filestate.Login(cmdutil.Diag(), cloudURL, "")
+			} else if ssoIssuer != "" {
+				if tokenFile != "" {
+					return errors.New("only one of --token-file or --sso-issuer may be specified, not both")
+				}
+				be, err = httpstate.LoginWithSSO(commandContext(), cmdutil.Diag(), cloudURL, "", ssoIssuer, ssoClientID)
+			} else if tokenFile != "" {
+				token, readErr := ioutil.ReadFile(tokenFile)
+				if readErr != nil {
+					return errors.Wrap(readErr, "reading access token")
+				}
+				be, err = httpstate.LoginWithToken(
+					commandContext(), cmdutil.Diag(), cloudURL, "", strings.TrimSpace(string(token)))
 			} else {
 				be, err = httpstate.Login(commandContext(), cmdutil.Diag(), cloudURL, "", displayOptions)
 			}
@@ -95,6 +169,16 @@ func newLoginCmd() *cobra.Command {
 				return errors.Wrapf(err, "problem logging in")
 			}
 
+			// Remember whether the backend was logged into with --read-only; logging in again without it makes the
+			// backend writable again.
+			key := cloudURL
+			if cloudBe, isCloud := be.(httpstate.Backend); isCloud {
+				key = cloudBe.CloudURL()
+			}
+			if err = workspace.SetBackendReadOnly(key, backend.ReadOnly); err != nil {
+				return errors.Wrap(err, "saving credentials")
+			}
+
 			if currentUser, err := be.CurrentUser(); err == nil {
 				fmt.Printf("Logged into %s as %s (%s)\n", be.Name(), currentUser, be.URL())
 			} else {
@@ -107,6 +191,40 @@ func newLoginCmd() *cobra.Command {
 
 	cmd.PersistentFlags().StringVarP(&cloudURL, "cloud-url", "c", "", "A cloud URL to log into")
 	cmd.PersistentFlags().BoolVarP(&localMode, "local", "l", false, "Use Pulumi in local-only mode")
+	cmd.PersistentFlags().StringVar(&tokenFile, "token-file", "",
+		"Read the access token to log in with from a file, instead of prompting for it")
+	cmd.PersistentFlags().StringVar(&ssoIssuer, "sso-issuer", "",
+		"Log in through single sign-on with the OpenID Connect identity provider at this issuer URL")
+	cmd.PersistentFlags().StringVar(&ssoClientID, "sso-client-id", "",
+		"The OAuth client ID with which to identify the CLI to the single sign-on identity provider")
+	cmd.PersistentFlags().BoolVar(&workspace.InsecureCredentialStorage, "insecure-storage", false,
+		"Store the access token in ~/.pulumi/credentials.json rather than in the operating system's keychain")
 
 	return cmd
 }
+
+// loginURL returns the URL of the backend to log into, given the arguments of `pulumi login` and the values of its
+// --cloud-url and --local flags. If none of them names a backend, it is the backend named by --backend or by the
+// current project, if any.
+func loginURL(args []string, cloudURL string, localMode bool) (string, error) {
+	// If a <cloud> was specified as an argument, use it.
+	if len(args) > 0 {
+		if cloudURL != "" {
+			return "", errors.New("only one of --cloud-url or argument URL may be specified, not both")
+		}
+		cloudURL = args[0]
+	}
+
+	// For local mode, store state by default in the user's home directory.
+	if localMode {
+		if cloudURL != "" {
+			return "", errors.New("a URL may not be specified when --local mode is enabled")
+		}
+		cloudURL = "file://~"
+	}
+
+	if cloudURL == "" {
+		cloudURL = defaultBackendURL()
+	}
+	return cloudURL, nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestLoginURL(t *testing.T) {
+	url, err := loginURL([]string{"file:///tmp/state"}, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "file:///tmp/state", url)
+
+	url, err = loginURL(nil, "https://api.acmecorp.com", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.acmecorp.com", url)
+
+	url, err = loginURL(nil, "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "file://~", url)
+
+	_, err = loginURL([]string{"file:///tmp/state"}, "https://api.acmecorp.com", false)
+	assert.Error(t, err)
+	_, err = loginURL([]string{"file:///tmp/state"}, "", true)
+	assert.Error(t, err)
+
+	// Without a URL, the backend named by --backend is used.
+	backendURL = "file:///tmp/other"
+	defer func() { backendURL = "" }()
+	url, err = loginURL(nil, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "file:///tmp/other", url)
+}
+
+func TestLoginToMultipleBackends(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-login-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	os.Setenv(workspace.PulumiCredentialsPathEnvVar, dir)
+	defer os.Unsetenv(workspace.PulumiCredentialsPathEnvVar)
+
+	first, second := "file://"+dir+"/first", "file://"+dir+"/second"
+	for _, url := range []string{first, second} {
+		assert.NoError(t, os.MkdirAll(url[len("file://"):], 0700))
+		login := newLoginCmd()
+		login.SetArgs([]string{url})
+		assert.NoError(t, login.Execute())
+	}
+
+	// Each login is remembered, and the most recent one is current.
+	creds, err := workspace.GetStoredCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, second, creds.Current)
+	assert.Contains(t, creds.AccessTokens, first)
+	assert.Contains(t, creds.AccessTokens, second)
+
+	// --backend selects another backend for a single command, without changing the current one.
+	backendURL = first
+	be, err := currentBackend(display.Options{})
+	backendURL = ""
+	assert.NoError(t, err)
+	assert.Equal(t, first, be.URL())
+	creds, err = workspace.GetStoredCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, second, creds.Current)
+
+	be, err = currentBackend(display.Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, second, be.URL())
+
+	// A service backend that has never been logged into cannot be selected with --backend, as that would prompt.
+	_, err = overrideBackend("https://api.acmecorp.com")
+	assert.Error(t, err)
+
+	// Logging out of everything forgets every backend.
+	logout := newLogoutCmd()
+	logout.SetArgs([]string{"--all"})
+	assert.NoError(t, logout.Execute())
+	creds, err = workspace.GetStoredCredentials()
+	assert.NoError(t, err)
+	assert.Empty(t, creds.Current)
+	assert.Empty(t, creds.AccessTokens)
+}
+
+func TestLoginWithTokenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-login-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	os.Setenv(workspace.PulumiCredentialsPathEnvVar, dir)
+	defer os.Unsetenv(workspace.PulumiCredentialsPathEnvVar)
+	defer func() { workspace.InsecureCredentialStorage = false }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/user", req.URL.Path)
+		assert.Equal(t, "token secret", req.Header.Get("Authorization"))
+		assert.NoError(t, json.NewEncoder(w).Encode(apitype.User{GitHubLogin: "alice"}))
+	}))
+	defer server.Close()
+
+	// The token is read from the file without its trailing newline, and stored like any other.
+	tokenFile := filepath.Join(dir, "token")
+	assert.NoError(t, ioutil.WriteFile(tokenFile, []byte("secret\n"), 0600))
+	login := newLoginCmd()
+	login.SetArgs([]string{server.URL, "--token-file", tokenFile, "--insecure-storage"})
+	assert.NoError(t, login.Execute())
+
+	creds, err := workspace.GetStoredCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL, creds.Current)
+	assert.Equal(t, "secret", creds.AccessTokens[server.URL])
+}
+
+func TestOverrideBackendWithAccessTokenEnvVar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-login-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	os.Setenv(workspace.PulumiCredentialsPathEnvVar, dir)
+	defer os.Unsetenv(workspace.PulumiCredentialsPathEnvVar)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "token secret", req.Header.Get("Authorization"))
+		assert.NoError(t, json.NewEncoder(w).Encode(apitype.User{GitHubLogin: "alice"}))
+	}))
+	defer server.Close()
+
+	// A service backend that has never been logged into may be selected with --backend if PULUMI_ACCESS_TOKEN is set.
+	_, err = overrideBackend(server.URL)
+	assert.Error(t, err)
+	os.Setenv(httpstate.AccessTokenEnvVar, "secret")
+	defer os.Unsetenv(httpstate.AccessTokenEnvVar)
+	be, err := overrideBackend(server.URL)
+	assert.NoError(t, err)
+	name, err := be.CurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", name)
+}
@@ -28,6 +28,7 @@ import (
 func newLogoutCmd() *cobra.Command {
 	var cloudURL string
 	var localMode bool
+	var all bool
 
 	cmd := &cobra.Command{
 		Use:   "logout <url>",
@@ -38,9 +39,17 @@ func newLogoutCmd() *cobra.Command {
 			"\n" +
 			"Because you may be logged into multiple backends simultaneously, you can optionally pass\n" +
 			"a specific URL argument, formatted just as you logged in, to log out of a specific one.\n" +
-			"If none is supplied, you will be logged out of the default cloud.",
+			"If none is supplied, you will be logged out of the backend named by --backend or, failing that, of\n" +
+			"the current one. Pass --all to log out of every backend at once.",
 		Args: cmdutil.MaximumNArgs(1),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if all {
+				if len(args) > 0 || cloudURL != "" || localMode {
+					return errors.New("a URL may not be specified when --all is enabled")
+				}
+				return workspace.DeleteAllAccessTokens()
+			}
+
 			// If a <cloud> was specified as an argument, use it.
 			if len(args) > 0 {
 				if cloudURL != "" {
@@ -57,6 +66,9 @@ func newLogoutCmd() *cobra.Command {
 				cloudURL = "file://~"
 			}
 
+			if cloudURL == "" {
+				cloudURL = backendURL
+			}
 			if cloudURL == "" {
 				creds, err := workspace.GetStoredCredentials()
 				if err != nil {
@@ -84,6 +96,8 @@ func newLogoutCmd() *cobra.Command {
 		"A cloud URL to log out of (defaults to current cloud)")
 	cmd.PersistentFlags().BoolVarP(&localMode, "local", "l", false,
 		"Log out of using local mode")
+	cmd.PersistentFlags().BoolVar(&all, "all", false,
+		"Log out of all backends")
 
 	return cmd
 }
@@ -22,10 +22,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/operations"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
 // We use RFC 5424 timestamps with millisecond precision for displaying time stamps on log entries. Go does not
@@ -80,8 +82,13 @@ func newLogsCmd() *cobra.Command {
 			// displayed before previously rendered log entries, but weren't available at the time, so still need to be
 			// rendered now even though they are technically out of order.
 			shown := map[operations.LogEntry]bool{}
+
+			// Keep the provider plugins that serve logs loaded from one poll to the next.
+			session := backend.NewLogSession()
+			defer contract.IgnoreClose(session)
+			ctx := backend.ContextWithLogSession(commandContext(), session)
 			for {
-				logs, err := s.GetLogs(commandContext(), operations.LogQuery{
+				logs, err := s.GetLogs(ctx, operations.LogQuery{
 					StartTime:      startTime,
 					ResourceFilter: resourceFilter,
 				})
@@ -52,8 +52,8 @@ func newNewCmd() *cobra.Command {
 	var dir string
 	var force bool
 	var generateOnly bool
+	var listTemplates bool
 	var name string
-	var offline bool
 	var stack string
 	var yes bool
 
@@ -61,7 +61,20 @@ func newNewCmd() *cobra.Command {
 		Use:        "new [template|url]",
 		SuggestFor: []string{"init", "create"},
 		Short:      "Create a new Pulumi project",
-		Args:       cmdutil.MaximumNArgs(1),
+		Long: "Create a new Pulumi project from a template.\n" +
+			"\n" +
+			"The template may be named, in which case it is taken from the template gallery, given as the\n" +
+			"https:// URL of a Git repository, or given as the path of a local directory. If no template is\n" +
+			"given, you will be prompted to choose one from the gallery.\n" +
+			"\n" +
+			"The gallery is Pulumi's own repository of templates unless PULUMI_TEMPLATE_GALLERY is set to the\n" +
+			"https:// URL of another Git repository, or the path of a local directory, whose subdirectories are\n" +
+			"templates. Git galleries are cached locally, so that they can be used with --offline once they have\n" +
+			"been retrieved.\n" +
+			"\n" +
+			"A template may define configuration that should be prompted for in the template section of its\n" +
+			"Pulumi.yaml.",
+		Args: cmdutil.MaximumNArgs(1),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			interactive := cmdutil.Interactive()
 			if !interactive {
@@ -74,6 +87,15 @@ func newNewCmd() *cobra.Command {
 				IsInteractive: interactive,
 			}
 
+			// If asked to, list the templates rather than creating a project.
+			if listTemplates {
+				var templateNameOrURL string
+				if len(args) > 0 {
+					templateNameOrURL = args[0]
+				}
+				return printTemplates(templateNameOrURL)
+			}
+
 			// Validate name (if specified) before further prompts/operations.
 			if name != "" && workspace.ValidateProjectName(name) != nil {
 				return errors.Errorf("'%s' is not a valid project name. %s.", name, workspace.ValidateProjectName(name))
@@ -126,7 +148,7 @@ func newNewCmd() *cobra.Command {
 			}
 
 			// Retrieve the template repo.
-			repo, err := workspace.RetrieveTemplates(templateNameOrURL, offline)
+			repo, err := workspace.RetrieveTemplates(templateNameOrURL, cmdutil.Offline)
 			if err != nil {
 				return err
 			}
@@ -283,7 +305,7 @@ func newNewCmd() *cobra.Command {
 		defaultHelp(cmd, args)
 
 		// Attempt to retrieve available templates.
-		repo, err := workspace.RetrieveTemplates("", false /*offline*/)
+		repo, err := workspace.RetrieveTemplates("", cmdutil.Offline)
 		if err != nil {
 			logging.Warningf("could not retrieve templates: %v", err)
 			return
@@ -322,11 +344,15 @@ func newNewCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&generateOnly, "generate-only", "g", false,
 		"Generate the project only; do not create a stack, save config, or install dependencies")
+	cmd.PersistentFlags().BoolVar(
+		&listTemplates, "list-templates", false,
+		"List the templates in the template gallery, or at the given URL or path, instead of creating a project")
 	cmd.PersistentFlags().StringVarP(
 		&name, "name", "n", "",
 		"The project name; if not specified, a prompt will request it")
+	// --offline is a global flag, but pulumi new keeps the -o shorthand it had before --offline applied everywhere.
 	cmd.PersistentFlags().BoolVarP(
-		&offline, "offline", "o", false,
+		&cmdutil.Offline, "offline", "o", cmdutil.IsTruthy(os.Getenv("PULUMI_OFFLINE")),
 		"Use locally cached templates without making any network requests")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
@@ -338,6 +364,38 @@ func newNewCmd() *cobra.Command {
 	return cmd
 }
 
+// printTemplates prints the names and descriptions of the templates at the given name, URL, or path, or in the
+// template gallery if it is empty.
+func printTemplates(templateNameOrURL string) error {
+	repo, err := workspace.RetrieveTemplates(templateNameOrURL, cmdutil.Offline)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		contract.IgnoreError(repo.Delete())
+	}()
+
+	templates, err := repo.Templates()
+	if err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		fmt.Println("No templates found")
+		return nil
+	}
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	rows := make([]cmdutil.TableRow, len(templates))
+	for i, template := range templates {
+		rows[i] = cmdutil.TableRow{Columns: []string{template.Name, template.Description}}
+	}
+	cmdutil.PrintTable(cmdutil.Table{
+		Headers: []string{"NAME", "DESCRIPTION"},
+		Rows:    rows,
+	})
+	return nil
+}
+
 // errorIfNotEmptyDirectory returns an error if path is not empty.
 func errorIfNotEmptyDirectory(path string) error {
 	infos, err := ioutil.ReadDir(path)
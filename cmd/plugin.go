@@ -32,8 +32,11 @@ func newPluginCmd() *cobra.Command {
 			"\n" +
 			"Pulumi uses dynamically loaded plugins as an extensibility mechanism for\n" +
 			"supporting any number of languages and resource providers.  These plugins are\n" +
-			"distributed out of band and must be installed manually.  Attempting to use a\n" +
-			"package that provisions resources without the corresponding plugin will fail.\n" +
+			"distributed out of band, and are downloaded when a project that requires them is\n" +
+			"previewed or updated.  A project may pin the versions of its resource plugins in\n" +
+			"a Pulumi.lock.yaml file, written by `pulumi plugin lock`, and a plugin may be\n" +
+			"linked to a local build while it is being developed with `pulumi plugin link`,\n" +
+			"and plugins may be required to be signed by trusted keys with `pulumi plugin trust`.\n" +
 			"\n" +
 			"You may write your own plugins, for example to implement custom languages or\n" +
 			"resources, although most people will never need to do this.  To understand how to\n" +
@@ -44,8 +47,14 @@ func newPluginCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(newPluginInstallCmd())
+	cmd.AddCommand(newPluginLinkCmd())
+	cmd.AddCommand(newPluginLockCmd())
 	cmd.AddCommand(newPluginLsCmd())
+	cmd.AddCommand(newPluginPruneCmd())
 	cmd.AddCommand(newPluginRmCmd())
+	cmd.AddCommand(newPluginSchemaCmd())
+	cmd.AddCommand(newPluginTrustCmd())
+	cmd.AddCommand(newPluginUnlinkCmd())
 
 	return cmd
 }
@@ -75,6 +84,20 @@ func getProjectPlugins() ([]workspace.PluginInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Add the plugins that the project lists, other than those the language host already reported.
+	projectPlugins, err := proj.RequiredPlugins()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, plugin := range plugins {
+		seen[plugin.String()] = true
+	}
+	for _, plugin := range projectPlugins {
+		if !seen[plugin.String()] {
+			plugins = append(plugins, plugin)
+		}
+	}
 	for _, plugin := range plugins {
 		if _, path, _ := workspace.GetPluginPath(plugin.Kind, plugin.Name, plugin.Version); path != "" {
 			err = plugin.SetFileMetadata(path)
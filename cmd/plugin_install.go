@@ -15,18 +15,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"os"
+	"sync"
 
 	"github.com/blang/semver"
+	"github.com/cheggaaa/pb"
+	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate"
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -49,7 +54,13 @@ func newPluginInstallCmd() *cobra.Command {
 			"project.  VERSION cannot be a range: it must be a specific number.\n" +
 			"\n" +
 			"If you let Pulumi compute the set to download, it is conservative and may end up\n" +
-			"downloading more plugins than is strictly necessary.",
+			"downloading more plugins than is strictly necessary.  Plugins are downloaded\n" +
+			"several at a time, and each is verified against its published SHA-256 checksum\n" +
+			"before it is installed.  If the plugin trust policy requires plugins to be signed\n" +
+			"(see `pulumi plugin trust`), each plugin must also be signed by a trusted key.\n" +
+			"\n" +
+			"When installing a plugin from a tarball with --file, its signature, if any, is\n" +
+			"read from the file of the same name plus " + workspace.PluginSignatureSuffix + ".",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			displayOpts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -103,9 +114,10 @@ func newPluginInstallCmd() *cobra.Command {
 				releases = r
 			}
 
-			// Now for each kind, name, version pair, download it from the release website, and install it.
+			// Now for each kind, name, version pair, decide whether to install it, and if so, where from.
+			var downloads []workspace.PluginInfo
 			for _, install := range installs {
-				label := fmt.Sprintf("[%s plugin %s]", install.Kind, install)
+				label := pluginInstallLabel(install)
 				cmdutil.Diag().Infoerrf(
 					diag.Message("", "%s installing"), label)
 
@@ -131,39 +143,38 @@ func newPluginInstallCmd() *cobra.Command {
 					}
 				}
 
-				// If we got here, actually try to do the download.
-				var source string
-				var tarball io.ReadCloser
-				var err error
-				if file == "" {
-					source = releases.CloudURL()
+				if file != "" {
 					if verbose {
 						cmdutil.Diag().Infoerrf(
-							diag.Message("", "%s downloading from %s"), label, source)
-					}
-					if tarball, err = releases.DownloadPlugin(commandContext(), install, true, displayOpts); err != nil {
-						return errors.Wrapf(err, "%s downloading from %s", label, source)
+							diag.Message("", "%s opening tarball from %s"), label, file)
 					}
-				} else {
-					source = file
 					if verbose {
 						cmdutil.Diag().Infoerrf(
-							diag.Message("", "%s opening tarball from %s"), label, file)
+							diag.Message("", "%s installing tarball ..."), label)
 					}
-					if tarball, err = os.Open(file); err != nil {
-						return errors.Wrapf(err, "opening file %s", source)
+					if err := install.InstallFile(file); err != nil {
+						return errors.Wrapf(err, "installing %s from %s", label, file)
 					}
+					continue
+				}
+
+				if cmdutil.Offline {
+					return errors.Errorf("%s; pass --file to install it from a tarball instead",
+						cmdutil.OfflineError("%s downloading", label))
 				}
 				if verbose {
+					source, err := pluginInstallSource(releases, install)
+					if err != nil {
+						return errors.Wrapf(err, "%s downloading", label)
+					}
 					cmdutil.Diag().Infoerrf(
-						diag.Message("", "%s installing tarball ..."), label)
-				}
-				if err = install.Install(tarball); err != nil {
-					return errors.Wrapf(err, "installing %s from %s", label, source)
+						diag.Message("", "%s downloading from %s"), label, source)
 				}
+				downloads = append(downloads, install)
 			}
 
-			return nil
+			// Finally, download and install the plugins that need it, several at a time.
+			return downloadAndInstallPlugins(commandContext(), releases, downloads, displayOpts)
 		}),
 	}
 
@@ -180,3 +191,93 @@ func newPluginInstallCmd() *cobra.Command {
 
 	return cmd
 }
+
+// maxConcurrentPluginDownloads is the number of plugins that are downloaded at once.
+const maxConcurrentPluginDownloads = 4
+
+// pluginInstallLabel returns the label that prefixes the messages about installing the given plugin.
+func pluginInstallLabel(plugin workspace.PluginInfo) string {
+	return fmt.Sprintf("[%s plugin %s]", plugin.Kind, plugin)
+}
+
+// pluginInstallSource returns the location from which the given plugin is downloaded.
+func pluginInstallSource(releases httpstate.Backend, plugin workspace.PluginInfo) (string, error) {
+	if workspace.HasPluginMirror() {
+		return plugin.DownloadURL()
+	}
+	return releases.CloudURL(), nil
+}
+
+// downloadAndInstallPlugins downloads the given plugins concurrently, verifying each against its published checksum,
+// and installs them.  If the CLI is interactive, the progress of each download is shown, along with the number of
+// plugins that have been installed so far.  A plugin that fails to install does not stop the others from installing.
+func downloadAndInstallPlugins(ctx context.Context, releases httpstate.Backend, plugins []workspace.PluginInfo,
+	opts display.Options) error {
+
+	if len(plugins) == 0 {
+		return nil
+	}
+
+	// The pool of progress bars stops once all of its bars are finished, so it starts with a bar that counts the
+	// installed plugins, and the bar for each download is added once the download starts.
+	installed := pb.New(len(plugins))
+	installed.Prefix(opts.Color.Colorize(colors.SpecUnimportant + "Installing plugins: "))
+	installed.Postfix(opts.Color.Colorize(colors.Reset))
+	installed.SetMaxWidth(100)
+	var progress *pb.Pool
+	if cmdutil.Interactive() {
+		pool, err := pb.StartPool(installed)
+		if err != nil {
+			logging.V(7).Infof("not showing the progress of plugin downloads: %v", err)
+		} else {
+			progress = pool
+		}
+	}
+
+	errs := make([]error, len(plugins))
+	slots := make(chan struct{}, maxConcurrentPluginDownloads)
+	var wg sync.WaitGroup
+	for i, plugin := range plugins {
+		wg.Add(1)
+		go func(i int, plugin workspace.PluginInfo) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			errs[i] = downloadAndInstallPlugin(ctx, releases, plugin, progress, opts)
+			installed.Increment()
+		}(i, plugin)
+	}
+	wg.Wait()
+	if progress != nil {
+		installed.Finish()
+		contract.IgnoreError(progress.Stop())
+	}
+
+	var result error
+	for _, err := range errs {
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
+}
+
+// downloadAndInstallPlugin downloads the given plugin, verifying it against its published checksum, and installs it.
+func downloadAndInstallPlugin(ctx context.Context, releases httpstate.Backend, plugin workspace.PluginInfo,
+	progress *pb.Pool, opts display.Options) error {
+
+	label := pluginInstallLabel(plugin)
+	source, err := pluginInstallSource(releases, plugin)
+	if err != nil {
+		return errors.Wrapf(err, "%s downloading", label)
+	}
+	tarball, err := releases.DownloadPlugin(ctx, plugin, progress, opts)
+	if err != nil {
+		return errors.Wrapf(err, "%s downloading from %s", label, source)
+	}
+	if err = plugin.Install(tarball); err != nil {
+		return errors.Wrapf(err, "installing %s from %s", label, source)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newPluginLinkCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "link [KIND NAME PATH]",
+		Args:  cmdutil.RangeArgs(0, 3),
+		Short: "Use a local build of a plugin in place of installed versions",
+		Long: "Use a local build of a plugin in place of installed versions.\n" +
+			"\n" +
+			"Links the plugin with the given KIND and NAME to the executable at PATH, or to\n" +
+			"the plugin's executable (e.g. pulumi-resource-aws) in the directory at PATH.\n" +
+			"The linked executable is then used whichever version of the plugin is required,\n" +
+			"and is never downloaded over, so that each rebuild of a plugin under development\n" +
+			"is picked up without being installed into the plugin cache.\n" +
+			"\n" +
+			"With no arguments, lists the linked plugins.  Use `pulumi plugin unlink` to go\n" +
+			"back to using installed versions.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				links, err := workspace.GetPluginLinks()
+				if err != nil {
+					return errors.Wrap(err, "loading plugin links")
+				}
+				rows := []cmdutil.TableRow{}
+				for _, link := range links {
+					rows = append(rows, cmdutil.TableRow{
+						Columns: []string{link.Name, string(link.Kind), link.Executable()},
+					})
+				}
+				cmdutil.PrintTable(cmdutil.Table{Headers: []string{"NAME", "KIND", "PATH"}, Rows: rows})
+				return nil
+			}
+			if len(args) != 3 {
+				return errors.New("please specify the KIND, NAME, and PATH of the plugin to link")
+			}
+
+			if !workspace.IsPluginKind(args[0]) {
+				return errors.Errorf("unrecognized plugin kind: %s", args[0])
+			}
+			link, err := workspace.LinkPlugin(workspace.PluginKind(args[0]), args[1], args[2])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Linked %s plugin %s to %s\n", link.Kind, link.Name, link.Executable())
+			return nil
+		}),
+	}
+
+	return cmd
+}
+
+func newPluginUnlinkCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "unlink KIND NAME",
+		Args:  cmdutil.ExactArgs(2),
+		Short: "Go back to using installed versions of a linked plugin",
+		Long: "Go back to using installed versions of a linked plugin.\n" +
+			"\n" +
+			"Removes the link created by `pulumi plugin link` for the plugin with the given\n" +
+			"KIND and NAME.  The local build itself is left alone.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if !workspace.IsPluginKind(args[0]) {
+				return errors.Errorf("unrecognized plugin kind: %s", args[0])
+			}
+			unlinked, err := workspace.UnlinkPlugin(workspace.PluginKind(args[0]), args[1])
+			if err != nil {
+				return err
+			} else if !unlinked {
+				return errors.Errorf("the %s plugin %s is not linked", args[0], args[1])
+			}
+			fmt.Printf("Unlinked %s plugin %s\n", args[0], args[1])
+			return nil
+		}),
+	}
+
+	return cmd
+}
@@ -0,0 +1,101 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newPluginLockCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "lock",
+		Args:  cmdutil.NoArgs,
+		Short: "Pin the versions of the current project's resource plugins",
+		Long: "Pin the versions of the current project's resource plugins.\n" +
+			"\n" +
+			"This command writes the exact versions of the resource plugins that the current\n" +
+			"project requires to a Pulumi.lock.yaml file next to its Pulumi.yaml file.  When\n" +
+			"the project is previewed or updated, the plugins it requires must match those\n" +
+			"pinned in the lock file, so that every machine that deploys the same commit of\n" +
+			"the project uses the same providers.  Commit the lock file along with the project,\n" +
+			"and run this command again after upgrading the project's packages.\n" +
+			"\n" +
+			"Plugins that the project requires without naming a version are pinned at the\n" +
+			"newest version that is installed.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			_, root, err := readProject()
+			if err != nil {
+				return err
+			}
+			plugins, err := getProjectPlugins()
+			if err != nil {
+				return err
+			}
+			installed, err := workspace.GetPlugins()
+			if err != nil {
+				return errors.Wrap(err, "loading plugins")
+			}
+
+			lock := &workspace.PluginLock{}
+			for _, plugin := range plugins {
+				if plugin.Kind != workspace.ResourcePlugin {
+					continue
+				}
+				version := plugin.Version
+				if version == nil {
+					if version = newestPluginVersion(installed, plugin.Kind, plugin.Name); version == nil {
+						return errors.Errorf("cannot pin the resource plugin %s, which is required without a version "+
+							"and is not installed; install it with `pulumi plugin install`", plugin.Name)
+					}
+				}
+				if pinned := lock.Version(plugin.Kind, plugin.Name); pinned != nil && !pinned.EQ(*version) {
+					return errors.Errorf("cannot pin the resource plugin %s, which is required at both %s and %s",
+						plugin.Name, pinned, version)
+				}
+				lock.Pin(plugin.Kind, plugin.Name, *version)
+			}
+			if err = lock.Save(root); err != nil {
+				return errors.Wrapf(err, "writing %s", workspace.PluginLockFile)
+			}
+
+			fmt.Printf("Pinned %d resource plugin(s) in %s\n", len(lock.Plugins), workspace.PluginLockFile)
+			for _, plugin := range lock.Plugins {
+				fmt.Printf("    %s %s\n", plugin.Name, plugin.Version)
+			}
+			return nil
+		}),
+	}
+
+	return cmd
+}
+
+// newestPluginVersion returns the newest version of the given plugin among the given plugins, or nil if there is none.
+func newestPluginVersion(plugins []workspace.PluginInfo, kind workspace.PluginKind, name string) *semver.Version {
+	var newest *semver.Version
+	for _, plugin := range plugins {
+		if plugin.Kind == kind && plugin.Name == name && plugin.Version != nil &&
+			(newest == nil || plugin.Version.GT(*newest)) {
+			newest = plugin.Version
+		}
+	}
+	return newest
+}
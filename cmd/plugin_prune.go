@@ -0,0 +1,171 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newPluginPruneCmd() *cobra.Command {
+	var keep int
+	var unusedDays int
+	var dryRun bool
+	var yes bool
+	var cmd = &cobra.Command{
+		Use:   "prune",
+		Args:  cmdutil.NoArgs,
+		Short: "Remove old and unused plugins from the download cache",
+		Long: "Remove old and unused plugins from the download cache.\n" +
+			"\n" +
+			"A plugin is removed if it is older than the newest --keep versions of the\n" +
+			"plugin that are installed, or if it has not been used for --unused-days days.\n" +
+			"Pass 0 for either to disable that policy.  The plugins that would be removed,\n" +
+			"and the space that removing them would reclaim, are listed before anything is\n" +
+			"removed; pass --dry-run to list them without removing them.\n" +
+			"\n" +
+			"This removal cannot be undone.  If a deleted plugin is subsequently required\n" +
+			"in order to execute a Pulumi program, it must be re-downloaded and installed\n" +
+			"using the plugin install command.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			if keep < 0 || unusedDays < 0 {
+				return errors.New("--keep and --unused-days must not be negative")
+			}
+
+			plugins, err := workspace.GetPlugins()
+			if err != nil {
+				return errors.Wrap(err, "loading plugins")
+			}
+			var total int64
+			for _, plugin := range plugins {
+				total += plugin.Size
+			}
+			unusedFor := time.Duration(unusedDays) * 24 * time.Hour
+			deletes := selectPluginsToPrune(plugins, keep, unusedFor, time.Now())
+			if len(deletes) == 0 {
+				fmt.Printf("No plugins need to be removed; the plugin cache holds %d plugins totalling %s.\n",
+					len(plugins), humanize.Bytes(uint64(total)))
+				return nil
+			}
+
+			// List what would be removed, along with how much space doing so would reclaim.
+			var reclaimed int64
+			var rows []cmdutil.TableRow
+			for _, del := range deletes {
+				var version string
+				if del.Version != nil {
+					version = del.Version.String()
+				}
+				lastUsedTime := humanNeverTime
+				if !del.LastUsedTime.IsZero() {
+					lastUsedTime = humanize.Time(del.LastUsedTime)
+				}
+				size := humanize.Bytes(uint64(del.Size))
+				rows = append(rows, cmdutil.TableRow{
+					Columns: []string{del.Name, string(del.Kind), version, size, lastUsedTime},
+				})
+				reclaimed += del.Size
+			}
+			var suffix string
+			if len(deletes) != 1 {
+				suffix = "s"
+			}
+			fmt.Print(
+				opts.Color.Colorize(
+					fmt.Sprintf("%sThis will remove %d plugin%s from the cache, reclaiming %s of %s:%s\n",
+						colors.SpecAttention, len(deletes), suffix, humanize.Bytes(uint64(reclaimed)),
+						humanize.Bytes(uint64(total)), colors.Reset)))
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"NAME", "KIND", "VERSION", "SIZE", "LAST USED"},
+				Rows:    rows,
+			})
+			if dryRun {
+				return nil
+			}
+
+			// Confirm that the user wants to do this (unless --yes was passed), and do the deletes.
+			if yes || confirmPrompt("", "yes", opts) {
+				var result error
+				for _, plugin := range deletes {
+					if err := plugin.Delete(); err != nil {
+						result = multierror.Append(
+							result, errors.Wrapf(err, "failed to delete %s plugin %s", plugin.Kind, plugin))
+					}
+				}
+				if result != nil {
+					return result
+				}
+				fmt.Printf("Removed %d plugin%s, reclaiming %s.\n", len(deletes), suffix,
+					humanize.Bytes(uint64(reclaimed)))
+			} else if !cmdutil.Interactive() {
+				return errNonInteractiveConfirmation
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().IntVar(
+		&keep, "keep", 3,
+		"The number of versions of each plugin to keep, newest first")
+	cmd.PersistentFlags().IntVar(
+		&unusedDays, "unused-days", 90,
+		"Remove plugins that have not been used for this many days")
+	cmd.PersistentFlags().BoolVar(
+		&dryRun, "dry-run", false,
+		"List the plugins that would be removed, without removing them")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with removal anyway")
+
+	return cmd
+}
+
+// selectPluginsToPrune returns the given plugins that are older than the newest keep versions of each kind and name,
+// or that were last used longer than unusedFor before now. A keep or unusedFor of zero disables the respective policy.
+// Plugins whose last use is unknown are never considered unused.
+func selectPluginsToPrune(plugins []workspace.PluginInfo, keep int, unusedFor time.Duration,
+	now time.Time) []workspace.PluginInfo {
+
+	old := make(map[string]bool)
+	if keep > 0 {
+		for _, plugin := range withoutLatestPluginVersions(plugins, keep) {
+			old[plugin.Dir()] = true
+		}
+	}
+
+	var result []workspace.PluginInfo
+	for _, plugin := range plugins {
+		unused := unusedFor > 0 && !plugin.LastUsedTime.IsZero() && now.Sub(plugin.LastUsedTime) > unusedFor
+		if old[plugin.Dir()] || unused {
+			result = append(result, plugin)
+		}
+	}
+	return result
+}
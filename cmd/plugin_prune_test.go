@@ -0,0 +1,60 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestSelectPluginsToPrune(t *testing.T) {
+	now := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	plugin := func(name, version string, unusedDays int) workspace.PluginInfo {
+		v := semver.MustParse(version)
+		info := workspace.PluginInfo{Kind: workspace.ResourcePlugin, Name: name, Version: &v}
+		if unusedDays >= 0 {
+			info.LastUsedTime = now.Add(-time.Duration(unusedDays) * 24 * time.Hour)
+		}
+		return info
+	}
+
+	plugins := []workspace.PluginInfo{
+		plugin("aws", "0.17.1", 1),
+		plugin("aws", "0.17.10", 1),
+		plugin("aws", "0.17.2", 1),
+		plugin("aws", "0.16.0", 1),
+		plugin("gcp", "0.16.0", 120),
+		plugin("azure", "0.16.0", -1),
+	}
+
+	// Old versions and unused plugins are pruned; plugins whose last use is unknown are not considered unused.
+	assert.Equal(t, []workspace.PluginInfo{
+		plugins[0],
+		plugins[3],
+		plugins[4],
+	}, selectPluginsToPrune(plugins, 2, 90*24*time.Hour, now))
+
+	// Either policy may be disabled.
+	assert.Equal(t, []workspace.PluginInfo{plugins[3]}, selectPluginsToPrune(plugins, 3, 0, now))
+	assert.Equal(t, []workspace.PluginInfo{plugins[4]}, selectPluginsToPrune(plugins, 0, 90*24*time.Hour, now))
+	assert.Empty(t, selectPluginsToPrune(plugins, 0, 0, now))
+
+	assert.Empty(t, selectPluginsToPrune(nil, 1, time.Hour, now))
+}
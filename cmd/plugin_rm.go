@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/go-multierror"
@@ -30,6 +31,7 @@ import (
 
 func newPluginRmCmd() *cobra.Command {
 	var all bool
+	var keepLatest bool
 	var yes bool
 	var cmd = &cobra.Command{
 		Use:   "rm [KIND [NAME [VERSION]]]",
@@ -42,6 +44,9 @@ func newPluginRmCmd() *cobra.Command {
 			"NAME are specified, but not VERSION, all versions of the plugin with the\n" +
 			"given KIND and NAME will be removed.  VERSION may be a range.\n" +
 			"\n" +
+			"Pass --keep-latest to remove only old versions, keeping the newest version of\n" +
+			"each plugin; e.g., `pulumi plugin rm --keep-latest` prunes the entire cache.\n" +
+			"\n" +
 			"This removal cannot be undone.  If a deleted plugin is subsequently required\n" +
 			"in order to execute a Pulumi program, it must be re-downloaded and installed\n" +
 			"using the plugin install command.",
@@ -59,7 +64,7 @@ func newPluginRmCmd() *cobra.Command {
 					return errors.Errorf("unrecognized plugin kind: %s", kind)
 				}
 				kind = workspace.PluginKind(args[0])
-			} else if !all {
+			} else if !all && !keepLatest {
 				return errors.Errorf("please pass --all if you'd like to remove all plugins")
 			}
 			if len(args) > 1 {
@@ -79,6 +84,9 @@ func newPluginRmCmd() *cobra.Command {
 			if err != nil {
 				return errors.Wrap(err, "loading plugins")
 			}
+			if keepLatest {
+				plugins = withoutLatestPlugins(plugins)
+			}
 			for _, plugin := range plugins {
 				if (kind == "" || plugin.Kind == kind) &&
 					(name == "" || plugin.Name == name) &&
@@ -114,6 +122,8 @@ func newPluginRmCmd() *cobra.Command {
 				if result != nil {
 					return result
 				}
+			} else if !cmdutil.Interactive() {
+				return errNonInteractiveConfirmation
 			}
 
 			return nil
@@ -123,9 +133,52 @@ func newPluginRmCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&all, "all", "a", false,
 		"Remove all plugins")
+	cmd.PersistentFlags().BoolVar(
+		&keepLatest, "keep-latest", false,
+		"Keep the newest version of each plugin, removing only older versions")
 	cmd.PersistentFlags().BoolVarP(
 		&yes, "yes", "y", false,
 		"Skip confirmation prompts, and proceed with removal anyway")
 
 	return cmd
 }
+
+// withoutLatestPlugins returns the given plugins other than the newest version of each kind and name. Plugins without
+// versions are considered older than any versioned plugin, and so are only kept if they are the only ones of their
+// kind and name.
+func withoutLatestPlugins(plugins []workspace.PluginInfo) []workspace.PluginInfo {
+	return withoutLatestPluginVersions(plugins, 1)
+}
+
+// withoutLatestPluginVersions returns the given plugins other than the newest n versions of each kind and name, in
+// their original order. Plugins without versions are considered older than any versioned plugin.
+func withoutLatestPluginVersions(plugins []workspace.PluginInfo, n int) []workspace.PluginInfo {
+	type pluginKey struct {
+		kind workspace.PluginKind
+		name string
+	}
+	versions := make(map[pluginKey][]int)
+	for i, plugin := range plugins {
+		key := pluginKey{kind: plugin.Kind, name: plugin.Name}
+		versions[key] = append(versions[key], i)
+	}
+
+	kept := make(map[int]bool)
+	for _, indices := range versions {
+		sort.SliceStable(indices, func(i, j int) bool {
+			vi, vj := plugins[indices[i]].Version, plugins[indices[j]].Version
+			return vi != nil && (vj == nil || vi.GT(*vj))
+		})
+		for i := 0; i < n && i < len(indices); i++ {
+			kept[indices[i]] = true
+		}
+	}
+
+	var result []workspace.PluginInfo
+	for i, plugin := range plugins {
+		if !kept[i] {
+			result = append(result, plugin)
+		}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestWithoutLatestPlugins(t *testing.T) {
+	plugin := func(kind workspace.PluginKind, name, version string) workspace.PluginInfo {
+		info := workspace.PluginInfo{Kind: kind, Name: name}
+		if version != "" {
+			v := semver.MustParse(version)
+			info.Version = &v
+		}
+		return info
+	}
+
+	plugins := []workspace.PluginInfo{
+		plugin(workspace.ResourcePlugin, "aws", "0.17.1"),
+		plugin(workspace.ResourcePlugin, "aws", "0.17.10"),
+		plugin(workspace.ResourcePlugin, "aws", "0.17.2"),
+		plugin(workspace.ResourcePlugin, "aws", ""),
+		plugin(workspace.ResourcePlugin, "gcp", "0.16.0"),
+		plugin(workspace.ResourcePlugin, "kubernetes", ""),
+		plugin(workspace.LanguagePlugin, "aws", "0.1.0"),
+	}
+	assert.Equal(t, []workspace.PluginInfo{
+		plugins[0],
+		plugins[2],
+		plugins[3],
+	}, withoutLatestPlugins(plugins))
+
+	assert.Empty(t, withoutLatestPlugins(nil))
+}
@@ -0,0 +1,80 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+func newPluginSchemaCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "schema <provider> [version]",
+		Args:  cmdutil.RangeArgs(1, 2),
+		Short: "Print the schema of a resource provider",
+		Long: "Print the schema of a resource provider.\n" +
+			"\n" +
+			"This command loads the given resource plugin and prints, as JSON, the schema that\n" +
+			"describes the provider's configuration, resources, and functions.  Editors,\n" +
+			"configuration validators, and documentation generators can build on the schema.\n" +
+			"\n" +
+			"If no version is given, the newest installed version of the plugin is used.  The\n" +
+			"plugin must already be installed, e.g. with `pulumi plugin install`.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			pkg := tokens.Package(args[0])
+			var version *semver.Version
+			if len(args) == 2 {
+				v, err := semver.ParseTolerant(args[1])
+				if err != nil {
+					return errors.Wrap(err, "invalid plugin semver")
+				}
+				version = &v
+			}
+
+			ctx, err := newStatePluginContext()
+			if err != nil {
+				return err
+			}
+			defer contract.IgnoreClose(ctx)
+
+			provider, err := ctx.Host.Provider(pkg, version)
+			if err != nil {
+				return errors.Wrapf(err, "loading the %s provider", pkg)
+			}
+			schema, err := provider.GetSchema(0)
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			if err = json.Indent(&out, schema, "", "  "); err != nil {
+				return errors.Wrapf(err, "the %s provider's schema is not valid JSON", pkg)
+			}
+			fmt.Println(out.String())
+			return nil
+		}),
+	}
+
+	return cmd
+}
@@ -0,0 +1,166 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func newPluginTrustCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "trust",
+		Short: "Manage the keys trusted to sign plugins",
+		Long: "Manage the keys trusted to sign plugins.\n" +
+			"\n" +
+			"In environments that must only run approved code, Pulumi can be configured to\n" +
+			"refuse to install or execute any plugin that isn't signed by a trusted key.  A\n" +
+			"plugin's signature is the base64-encoded Ed25519 signature of the SHA-256 hash\n" +
+			"of its tarball, published next to the tarball with a .sig extension.  Each\n" +
+			"time a plugin is executed, the signature of the tarball it was installed from\n" +
+			"is checked again.  Plugins that are installed alongside the pulumi executable\n" +
+			"are part of the installation, and are always trusted.\n" +
+			"\n" +
+			"The policy applies to every user of the installation, and is stored in\n" +
+			workspace.GetPluginTrustFilePath() + ", so changing it usually requires\n" +
+			"administrator privileges.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newPluginTrustAddCmd())
+	cmd.AddCommand(newPluginTrustLsCmd())
+	cmd.AddCommand(newPluginTrustRequireCmd())
+	cmd.AddCommand(newPluginTrustRmCmd())
+
+	return cmd
+}
+
+func newPluginTrustAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add NAME KEY",
+		Args:  cmdutil.ExactArgs(2),
+		Short: "Trust a key to sign plugins",
+		Long: "Trust a key to sign plugins.\n" +
+			"\n" +
+			"KEY is a base64-encoded Ed25519 public key.  If a key is already trusted under\n" +
+			"NAME, it is replaced.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			policy, err := workspace.GetPluginTrustPolicy()
+			if err != nil {
+				return errors.Wrap(err, "loading plugin trust policy")
+			}
+			if err = policy.AddKey(args[0], args[1]); err != nil {
+				return err
+			}
+			if err = workspace.SavePluginTrustPolicy(policy); err != nil {
+				return errors.Wrap(err, "saving plugin trust policy")
+			}
+			fmt.Printf("Trusted key %s to sign plugins\n", args[0])
+			return nil
+		}),
+	}
+}
+
+func newPluginTrustLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Args:  cmdutil.NoArgs,
+		Short: "List the keys trusted to sign plugins",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			policy, err := workspace.GetPluginTrustPolicy()
+			if err != nil {
+				return errors.Wrap(err, "loading plugin trust policy")
+			}
+			rows := []cmdutil.TableRow{}
+			for _, k := range policy.Keys {
+				rows = append(rows, cmdutil.TableRow{Columns: []string{k.Name, k.Key}})
+			}
+			cmdutil.PrintTable(cmdutil.Table{Headers: []string{"NAME", "KEY"}, Rows: rows})
+			if policy.RequireSignatures {
+				fmt.Println("\nPlugins must be signed by one of these keys before they are executed.")
+			} else {
+				fmt.Println("\nPlugins are not required to be signed; use `pulumi plugin trust require` to require it.")
+			}
+			return nil
+		}),
+	}
+}
+
+func newPluginTrustRequireCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "require [true|false]",
+		Args:  cmdutil.MaximumNArgs(1),
+		Short: "Require plugins to be signed by a trusted key before they are executed",
+		Long: "Require plugins to be signed by a trusted key before they are executed.\n" +
+			"\n" +
+			"Pass false to allow unsigned plugins to be executed again.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			require := true
+			if len(args) > 0 {
+				b, err := strconv.ParseBool(args[0])
+				if err != nil {
+					return errors.Errorf("expected true or false, got '%s'", args[0])
+				}
+				require = b
+			}
+
+			policy, err := workspace.GetPluginTrustPolicy()
+			if err != nil {
+				return errors.Wrap(err, "loading plugin trust policy")
+			}
+			if require && len(policy.Keys) == 0 {
+				return errors.New("no keys are trusted to sign plugins; use `pulumi plugin trust add` first")
+			}
+			policy.RequireSignatures = require
+			if err = workspace.SavePluginTrustPolicy(policy); err != nil {
+				return errors.Wrap(err, "saving plugin trust policy")
+			}
+			if require {
+				fmt.Println("Plugins must now be signed by a trusted key before they are executed")
+			} else {
+				fmt.Println("Plugins are no longer required to be signed")
+			}
+			return nil
+		}),
+	}
+}
+
+func newPluginTrustRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm NAME",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Stop trusting a key to sign plugins",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			policy, err := workspace.GetPluginTrustPolicy()
+			if err != nil {
+				return errors.Wrap(err, "loading plugin trust policy")
+			}
+			if !policy.RemoveKey(args[0]) {
+				return errors.Errorf("no key named %s is trusted", args[0])
+			}
+			if err = workspace.SavePluginTrustPolicy(policy); err != nil {
+				return errors.Wrap(err, "saving plugin trust policy")
+			}
+			fmt.Printf("Stopped trusting key %s\n", args[0])
+			return nil
+		}),
+	}
+}
@@ -21,12 +21,16 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/result"
 )
 
 func newPreviewCmd() *cobra.Command {
 	var debug bool
+	var diffExitCode bool
+	var eventLog string
 	var expectNop bool
 	var message string
 	var stack string
@@ -34,7 +38,14 @@ func newPreviewCmd() *cobra.Command {
 	// Flags for engine.UpdateOptions.
 	var analyzers []string
 	var diffDisplay bool
+	var excludes []string
+	var jsonDisplay bool
+	var progressFormat string
+	var mockProviders string
 	var parallel int
+	var refresh bool
+	var replaces []string
+	var savePlan string
 	var showConfig bool
 	var showReplacementSteps bool
 	var showSames bool
@@ -54,15 +65,34 @@ func newPreviewCmd() *cobra.Command {
 			"operations must take place to achieve the desired state. No changes to the stack will\n" +
 			"actually take place.\n" +
 			"\n" +
+			"To check that a stack is fully converged, e.g. in a scheduled drift-detection job or a pre-merge\n" +
+			"check, use `--expect-no-changes`, optionally with `--refresh` to also catch resources whose live\n" +
+			"state has drifted. The command then fails if any changes would be made. Changes to properties\n" +
+			"listed under `suppressDiffs` in Pulumi.yaml or in the stack's settings file are not reported as drift.\n" +
+			"\n" +
+			"Alternatively, with `--diff-exit-code`, the exit code tells whether any changes would be made:\n" +
+			"0 if none would be, 2 if some would be, and 1 if the preview failed.\n" +
+			"\n" +
 			"The program to run is loaded from the project in the current directory. Use the `-C` or\n" +
-			"`--cwd` flag to use a different directory.",
+			"`--cwd` flag to use a different directory.\n" +
+			"\n" +
+			"Commands listed under `hooks.prePreview` in Pulumi.yaml run from the project directory before the\n" +
+			"preview, with PULUMI_PROJECT, PULUMI_STACK and PULUMI_HOOK set in their environment.",
 		Args: cmdutil.NoArgs,
-		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) *result.Result {
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) (res *result.Result) {
+			var changes engine.ResourceChanges
+			if diffExitCode {
+				defer func() { res = diffExitCodeResult(res, changes) }()
+			}
+
 			opts := backend.UpdateOptions{
 				Engine: engine.UpdateOptions{
-					Analyzers: analyzers,
-					Parallel:  parallel,
-					Debug:     debug,
+					Analyzers:      analyzers,
+					Parallel:       parallel,
+					Debug:          debug,
+					Refresh:        refresh,
+					ReplaceTargets: targetURNs(replaces),
+					ExcludeTargets: targetURNs(excludes),
 				},
 				Display: display.Options{
 					Color:                cmdutil.GetGlobalColorization(),
@@ -72,10 +102,30 @@ func newPreviewCmd() *cobra.Command {
 					SuppressOutputs:      suppressOutputs,
 					IsInteractive:        cmdutil.Interactive(),
 					DiffDisplay:          diffDisplay,
+					JSONDisplay:          jsonDisplay,
 					Debug:                debug,
+					EventLogPath:         eventLog,
 				},
 			}
 
+			if err := progressFormatToOptions(&opts, progressFormat, true /*isPreview*/); err != nil {
+				return result.FromError(err)
+			}
+
+			if err := resetEventLog(eventLog); err != nil {
+				return result.FromError(err)
+			}
+
+			if savePlan != "" {
+				opts.Engine.SavePlan = deploy.NewSavedPlan()
+			}
+			if mockProviders != "" {
+				var err error
+				if opts.Engine.MockProviders, err = plugin.LoadMockFixtures(mockProviders); err != nil {
+					return result.FromError(err)
+				}
+			}
+
 			s, err := requireStack(stack, true, opts.Display, true /*setCurrent*/)
 			if err != nil {
 				return result.FromError(err)
@@ -86,12 +136,20 @@ func newPreviewCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
+			if opts.Engine.SuppressDiffs, err = stackDiffSuppressions(s); err != nil {
+				return result.FromError(err)
+			}
+
+			if err = runProjectHook(proj, root, s, prePreviewHook, nil, hookOutput(opts)); err != nil {
+				return result.FromError(err)
+			}
+
 			m, err := getUpdateMetadata("", root)
 			if err != nil {
 				return result.FromError(errors.Wrap(err, "gathering environment metadata"))
 			}
 
-			changes, err := s.Preview(commandContext(), backend.UpdateOperation{
+			changes, err = s.Preview(commandContext(), backend.UpdateOperation{
 				Proj:   proj,
 				Root:   root,
 				M:      m,
@@ -103,6 +161,11 @@ func newPreviewCmd() *cobra.Command {
 				return PrintEngineError(err)
 			case expectNop && changes != nil && changes.HasChanges():
 				return result.FromError(errors.New("error: no changes were expected but changes were proposed"))
+			case savePlan != "":
+				if err = writePlan(savePlan, opts.Engine.SavePlan); err != nil {
+					return result.FromError(err)
+				}
+				return nil
 			default:
 				return nil
 			}
@@ -112,9 +175,16 @@ func newPreviewCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().BoolVar(
+		&diffExitCode, "diff-exit-code", false,
+		"Exit with code 0 if no changes are proposed, 2 if changes are proposed, and 1 if the preview fails")
+	cmd.PersistentFlags().StringVar(
+		&eventLog, "event-log", "",
+		"Log every engine event to the given file as newline-delimited JSON")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
-		"Return an error if any changes are proposed by this preview")
+		"Return an error if any changes are proposed by this preview. Combine with --refresh to also fail if any "+
+			"resources have drifted from their recorded state")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
@@ -133,9 +203,34 @@ func newPreviewCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().StringSliceVar(
+		&excludes, "exclude", []string{},
+		"Specify a single resource URN to leave unchanged, along with any resources that depend on it. "+
+			"Multiple resources can be specified using --exclude urn1 --exclude urn2")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonDisplay, "json", "j", false,
+		"Serialize the preview diffs, operations, and overall output as JSON")
+	cmd.PersistentFlags().StringVar(
+		&progressFormat, "progress-format", "text",
+		"Display progress as 'text', or as 'json': newline-delimited JSON events on stdout, "+
+			"for other programs to render")
+	cmd.PersistentFlags().StringVar(
+		&mockProviders, "mock-providers", "",
+		"Replace every resource provider with a mock that responds with the IDs and outputs in the given JSON "+
+			"fixtures file, so that no cloud credentials are needed")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
+	cmd.PersistentFlags().BoolVarP(
+		&refresh, "refresh", "r", false,
+		"Refresh the state of the stack's resources before this preview")
+	cmd.PersistentFlags().StringArrayVar(
+		&replaces, "replace", []string{},
+		"Specify a single resource URN to replace, even if its diff does not require it. "+
+			"Multiple resources can be specified using --replace urn1 --replace urn2")
+	cmd.PersistentFlags().StringVar(
+		&savePlan, "save-plan", "",
+		"Save the plan computed by this preview to the given file, so that it can be applied with `pulumi up --plan`")
 	cmd.PersistentFlags().BoolVar(
 		&showConfig, "show-config", false,
 		"Show configuration keys and variables")
@@ -151,3 +246,16 @@ func newPreviewCmd() *cobra.Command {
 
 	return cmd
 }
+
+// diffExitCodeResult maps the outcome of a preview run with --diff-exit-code onto the command's exit code: 0 if the
+// preview succeeded without proposing any changes, 2 if it proposed changes, and 1 if it failed.
+func diffExitCodeResult(res *result.Result, changes engine.ResourceChanges) *result.Result {
+	switch {
+	case res != nil:
+		return result.FromError(&cmdutil.ExitCodeError{Code: 1, Err: res.Error()})
+	case changes != nil && changes.HasChanges():
+		return result.FromError(&cmdutil.ExitCodeError{Code: 2})
+	default:
+		return nil
+	}
+}
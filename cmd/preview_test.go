@@ -0,0 +1,53 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func TestDiffExitCodeResult(t *testing.T) {
+	exitCode := func(res *result.Result) int {
+		if res == nil {
+			return 0
+		}
+		exitErr, ok := res.Error().(*cmdutil.ExitCodeError)
+		assert.True(t, ok)
+		return exitErr.Code
+	}
+
+	assert.Equal(t, 0, exitCode(diffExitCodeResult(nil, nil)))
+	assert.Equal(t, 0, exitCode(diffExitCodeResult(nil, engine.ResourceChanges{deploy.OpSame: 3})))
+	assert.Equal(t, 2, exitCode(diffExitCodeResult(nil, engine.ResourceChanges{deploy.OpUpdate: 1})))
+
+	// Failures win over changes, and keep their error so that it is still reported.
+	err := errors.New("boom")
+	res := diffExitCodeResult(result.FromError(err), engine.ResourceChanges{deploy.OpUpdate: 1})
+	assert.Equal(t, 1, exitCode(res))
+	assert.Equal(t, err, res.Error().(*cmdutil.ExitCodeError).Err)
+
+	// Bails have already been reported, so they exit silently.
+	res = diffExitCodeResult(result.Bail(), nil)
+	assert.Equal(t, 1, exitCode(res))
+	assert.Nil(t, res.Error().(*cmdutil.ExitCodeError).Err)
+}
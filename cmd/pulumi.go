@@ -24,16 +24,17 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/blang/semver"
 	"github.com/djherbis/times"
 	"github.com/docker/docker/pkg/term"
-	"github.com/golang/glog"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate"
@@ -42,7 +43,9 @@ import (
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/httputil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
 	"github.com/pulumi/pulumi/pkg/version"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
@@ -55,7 +58,9 @@ func NewPulumiCmd() *cobra.Command {
 	var tracing string
 	var tracingHeaderFlag string
 	var profiling string
-	var verbose int
+	var rpcInterceptors string
+	var verbose verbosityFlag
+	var logFile string
 	var color string
 
 	cmd := &cobra.Command{
@@ -93,13 +98,28 @@ func NewPulumiCmd() *cobra.Command {
 				}
 			}
 
+			// Plain output leaves out emoji unless they were explicitly asked for.
+			if emojiFlag := cmd.Flag("emoji"); cmdutil.Plain && emojiFlag != nil && !emojiFlag.Changed {
+				cmdutil.Emoji = false
+			}
+
 			if cwd != "" {
 				if err := os.Chdir(cwd); err != nil {
 					return err
 				}
 			}
 
-			logging.InitLogging(logToStderr, verbose, logFlow)
+			// Logging settings always flow to plugins, so that they log at the same level as the CLI and, if the CLI's
+			// log goes to stderr or a file, to the same place.
+			if logFile != "" {
+				if err := logging.InitLogFile(logFile); err != nil {
+					return errors.Wrap(err, "opening log file")
+				}
+				if verbose.level == 0 {
+					verbose.level = logging.EngineVerbosity
+				}
+			}
+			logging.InitLogging(logToStderr, verbose.level, true /*logFlow*/)
 			cmdutil.InitTracing("pulumi-cli", "pulumi", tracing)
 			if tracingHeaderFlag != "" {
 				tracingHeader = tracingHeaderFlag
@@ -111,11 +131,22 @@ func NewPulumiCmd() *cobra.Command {
 				}
 			}
 
-			checkForUpdate()
+			if err := rpcutil.ConfigureClientInterceptors(rpcInterceptors); err != nil {
+				return err
+			}
+
+			if err := httputil.ConfigureDefaultTransport(); err != nil {
+				return err
+			}
+
+			if !cmdutil.Offline {
+				checkForUpdate()
+			}
 
 			return nil
 		}),
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			rpcutil.FlushClientInterceptors()
 			logging.Flush()
 			cmdutil.CloseTracing()
 
@@ -128,6 +159,8 @@ func NewPulumiCmd() *cobra.Command {
 		},
 	}
 
+	cmd.PersistentFlags().StringVar(&backendURL, "backend", "",
+		"Use the backend at the given URL for this command, instead of the one most recently logged into")
 	cmd.PersistentFlags().StringVarP(&cwd, "cwd", "C", "",
 		"Run pulumi as if it had been started in another directory")
 	cmd.PersistentFlags().BoolVarP(&cmdutil.Emoji, "emoji", "e", runtime.GOOS == "darwin",
@@ -136,18 +169,43 @@ func NewPulumiCmd() *cobra.Command {
 		"Disable integrity checking of checkpoint files")
 	cmd.PersistentFlags().BoolVar(&logFlow, "logflow", false,
 		"Flow log settings to child processes (like plugins)")
+	contract.AssertNoError(cmd.PersistentFlags().MarkDeprecated("logflow", "log settings now always flow to plugins"))
+	cmd.PersistentFlags().StringVar(&logFile, "log-file", "",
+		"Write the logs of the CLI, the engine, and plugins to the given file. Logs at the level of -vv unless a "+
+			"verbosity is given")
 	cmd.PersistentFlags().BoolVar(&logToStderr, "logtostderr", false,
 		"Log to stderr instead of to files")
 	cmd.PersistentFlags().BoolVar(&cmdutil.DisableInteractive, "non-interactive", false,
-		"Disable interactive mode for all commands")
+		"Disable interactive mode for all commands. This is the default when stdin or stdout is not a terminal, "+
+			"or when running in CI; commands that need confirmation then require --yes")
+	cmd.PersistentFlags().BoolVar(&cmdutil.Offline, "offline", cmdutil.IsTruthy(os.Getenv("PULUMI_OFFLINE")),
+		"Fail instead of making any network requests, as in air-gapped environments. Only backends on the local "+
+			"filesystem may be used, and plugins and templates must already be installed")
+	cmd.PersistentFlags().BoolVar(&cmdutil.Plain, "plain", cmdutil.Plain,
+		"Produce stable, line-oriented output without colors, emoji, or progress that is redrawn in place, "+
+			"e.g. for CI logs. This is the default when running in CI; use --plain=false to turn it off")
+	cmd.PersistentFlags().BoolVar(&backend.ReadOnly, "read-only", cmdutil.IsTruthy(os.Getenv("PULUMI_READ_ONLY")),
+		"Refuse every operation that would change a stack, such as updates, destroys, imports, and configuration "+
+			"changes, so that stacks may be inspected safely")
 	cmd.PersistentFlags().StringVar(&tracing, "tracing", "",
 		"Emit tracing to a Zipkin-compatible tracing endpoint")
 	cmd.PersistentFlags().StringVar(&profiling, "profiling", "",
 		"Emit CPU and memory profiles and an execution trace to '[filename].[pid].{cpu,mem,trace}', respectively")
-	cmd.PersistentFlags().IntVarP(&verbose, "verbose", "v", 0,
-		"Enable verbose logging (e.g., v=3); anything >3 is very verbose")
+	cmd.PersistentFlags().StringVar(&rpcInterceptors, "rpc-interceptors", os.Getenv(rpcutil.ClientInterceptorsEnvVar),
+		"Wrap the RPCs made to plugins in the given comma-separated interceptors, e.g. log=rpc.log,chaos=0.05. "+
+			"Built-in interceptors are log[=FILE], metrics[=FILE], header=KEY:VALUE, and chaos[=RATE]")
+	cmd.PersistentFlags().VarP(&verbose, "verbose", "v",
+		"Enable verbose logging of the CLI, the engine, and plugins: -v logs the CLI's decisions, -vv also the "+
+			"engine's steps, and -vvv everything, including plugin calls. -v=N, -vN, or -v N sets the glog level "+
+			"N directly")
+	cmd.PersistentFlags().Lookup("verbose").NoOptDefVal = "+1"
+
+	// Because -v may be given without a value, the flag parser would read the level in -v9 as another shorthand
+	// flag, and the level in -v 9 as an argument, so rewrite both as -v=9 before the arguments are parsed.
+	cmd.SetArgs(normalizeVerbosityArgs(os.Args[1:]))
 	cmd.PersistentFlags().StringVar(
-		&color, "color", "auto", "Colorize output. Choices are: always, never, raw, auto")
+		&color, "color", "auto", "Colorize output. Choices are: always, never, raw, auto. "+
+			"Auto colorizes only when writing to a terminal, and never with --plain or when NO_COLOR is set")
 
 	// Common commands:
 	//     - Getting Started Commands
@@ -156,6 +214,7 @@ func NewPulumiCmd() *cobra.Command {
 	cmd.AddCommand(newUpCmd())
 	cmd.AddCommand(newPreviewCmd())
 	cmd.AddCommand(newDestroyCmd())
+	cmd.AddCommand(newWatchCmd())
 	//     - Stack Management Commands:
 	cmd.AddCommand(newStackCmd())
 	cmd.AddCommand(newConfigCmd())
@@ -171,12 +230,17 @@ func NewPulumiCmd() *cobra.Command {
 	cmd.AddCommand(newLogsCmd())
 	cmd.AddCommand(newPluginCmd())
 	cmd.AddCommand(newVersionCmd())
+	cmd.AddCommand(newAboutCmd())
 	cmd.AddCommand(newHistoryCmd())
 
 	// Less common, and thus hidden, commands:
 	cmd.AddCommand(newGenCompletionCmd(cmd))
+	cmd.AddCommand(newCompleteValuesCmd())
 	cmd.AddCommand(newGenMarkdownCmd(cmd))
 
+	// User-defined commands, which run sequences of the commands above:
+	addAliasCommands(cmd)
+
 	// We have a set of options that are useful for developers of pulumi that we add when PULUMI_DEBUG_COMMANDS is
 	// set to true.
 	if hasDebugCommands() {
@@ -187,12 +251,73 @@ func NewPulumiCmd() *cobra.Command {
 	return cmd
 }
 
+// verbosityFlag is the value of the --verbose flag. Each -v raises the verbosity by one step, as listed in
+// verbosityLevels, while -v=N sets the glog level N directly.
+type verbosityFlag struct {
+	level int // the glog level to log at.
+	steps int // the number of times -v was passed without a level.
+}
+
+// verbosityLevels are the glog levels of each step of verbosity: -v, -vv, and -vvv.
+var verbosityLevels = []int{logging.CLIVerbosity, logging.EngineVerbosity, logging.PluginVerbosity}
+
+func (v *verbosityFlag) String() string {
+	return strconv.Itoa(v.level)
+}
+
+func (v *verbosityFlag) Set(value string) error {
+	if value == "+1" {
+		v.steps++
+		if v.steps > len(verbosityLevels) {
+			v.steps = len(verbosityLevels)
+		}
+		v.level = verbosityLevels[v.steps-1]
+		return nil
+	}
+
+	level, err := strconv.Atoi(value)
+	if err != nil || level < 0 {
+		return errors.Errorf("verbosity must be a non-negative integer, not '%s'", value)
+	}
+	v.level = level
+	return nil
+}
+
+func (v *verbosityFlag) Type() string {
+	return "int"
+}
+
+// normalizeVerbosityArgs rewrites the verbosity levels given as -vN, -v N, or --verbose N in args as -v=N, which is
+// the only form the flag parser reads as a level rather than as another flag or argument.
+func normalizeVerbosityArgs(args []string) []string {
+	isLevel := func(s string) bool {
+		_, err := strconv.Atoi(s)
+		return err == nil && !strings.HasPrefix(s, "-") && !strings.HasPrefix(s, "+")
+	}
+
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(result, args[i:]...)
+		case strings.HasPrefix(arg, "-v") && isLevel(arg[2:]):
+			arg = "-v=" + arg[2:]
+		case (arg == "-v" || arg == "--verbose") && i+1 < len(args) && isLevel(args[i+1]):
+			arg = arg + "=" + args[i+1]
+			i++
+		}
+		result = append(result, arg)
+	}
+	return result
+}
+
 // checkForUpdate checks to see if the CLI needs to be updated, and if so emits a warning, as well as information
 // as to how it can be upgraded.
 func checkForUpdate() {
 	curVer, err := semver.ParseTolerant(version.Version)
 	if err != nil {
-		glog.V(3).Infof("error parsing current version: %s", err)
+		logging.V(3).Infof("error parsing current version: %s", err)
 	}
 
 	// We don't care about warning for you to update if you have installed a developer version
@@ -202,7 +327,7 @@ func checkForUpdate() {
 
 	latestVer, oldestAllowedVer, err := getCLIVersionInfo()
 	if err != nil {
-		glog.V(3).Infof("error fetching latest version information: %s", err)
+		logging.V(3).Infof("error fetching latest version information: %s", err)
 	}
 
 	if oldestAllowedVer.GT(curVer) {
@@ -226,7 +351,7 @@ func getCLIVersionInfo() (semver.Version, semver.Version, error) {
 
 	err = cacheVersionInfo(latest, oldest)
 	if err != nil {
-		glog.V(3).Infof("failed to cache version info: %s", err)
+		logging.V(3).Infof("failed to cache version info: %s", err)
 	}
 
 	return latest, oldest, err
@@ -327,7 +452,7 @@ func getUpgradeCommand() string {
 
 	isBrew, err := isBrewInstall(exe)
 	if err != nil {
-		glog.V(3).Infof("error determining if the running executable was installed with brew: %s", err)
+		logging.V(3).Infof("error determining if the running executable was installed with brew: %s", err)
 	}
 	if isBrew {
 		return "$ brew upgrade pulumi"
@@ -401,7 +526,13 @@ func isDevVersion(s semver.Version) bool {
 	return !s.Pre[0].IsNum && strings.HasPrefix("dev", s.Pre[0].VersionStr)
 }
 
+// confirmPrompt asks the user to confirm an action by typing the given name, and returns true if they did. It never
+// confirms an action when running non-interactively, since there is no one to answer.
 func confirmPrompt(prompt string, name string, opts display.Options) bool {
+	if !cmdutil.Interactive() {
+		return false
+	}
+
 	if prompt != "" {
 		fmt.Print(
 			opts.Color.Colorize(
@@ -0,0 +1,109 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func TestVerbosityFlag(t *testing.T) {
+	parse := func(args ...string) (int, error) {
+		var verbose verbosityFlag
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.VarP(&verbose, "verbose", "v", "")
+		flags.Lookup("verbose").NoOptDefVal = "+1"
+		err := flags.Parse(normalizeVerbosityArgs(args))
+		return verbose.level, err
+	}
+
+	for _, test := range []struct {
+		args  []string
+		level int
+	}{
+		{nil, 0},
+		{[]string{"-v"}, 3},
+		{[]string{"-vv"}, 7},
+		{[]string{"-vvv"}, 9},
+		{[]string{"-vvvv"}, 9},
+		{[]string{"-v", "-v"}, 7},
+		{[]string{"-v=5"}, 5},
+		{[]string{"--verbose=11"}, 11},
+		{[]string{"--verbose"}, 3},
+		{[]string{"-v9"}, 9},
+		{[]string{"-v", "9"}, 9},
+		{[]string{"--verbose", "11"}, 11},
+		{[]string{"-vv", "-v", "4"}, 4},
+	} {
+		level, err := parse(test.args...)
+		assert.NoError(t, err, "%v", test.args)
+		assert.Equal(t, test.level, level, "%v", test.args)
+	}
+
+	_, err := parse("-v=lots")
+	assert.Error(t, err)
+
+	// Only the argument right after -v is read as a level, and none after --.
+	assert.Equal(t, []string{"-v=9", "up", "3", "--", "-v", "9"},
+		normalizeVerbosityArgs([]string{"-v", "9", "up", "3", "--", "-v", "9"}))
+	assert.Equal(t, []string{"-v", "dev", "-vv"}, normalizeVerbosityArgs([]string{"-v", "dev", "-vv"}))
+}
+
+func TestNewOfflineShorthand(t *testing.T) {
+	defer func(offline bool) { cmdutil.Offline = offline }(cmdutil.Offline)
+
+	for _, args := range [][]string{{"-o"}, {"--offline"}} {
+		cmdutil.Offline = false
+		newCmd, _, err := NewPulumiCmd().Find([]string{"new"})
+		assert.NoError(t, err)
+		assert.NoError(t, newCmd.ParseFlags(args))
+		assert.True(t, cmdutil.Offline, "%v", args)
+	}
+}
+
+func TestPlainFlag(t *testing.T) {
+	defer func(plain, emoji, offline bool) {
+		cmdutil.Plain, cmdutil.Emoji, cmdutil.Offline = plain, emoji, offline
+		_ = cmdutil.SetGlobalColorization("auto")
+	}(cmdutil.Plain, cmdutil.Emoji, cmdutil.Offline)
+
+	run := func(args ...string) {
+		root := NewPulumiCmd()
+		root.AddCommand(&cobra.Command{Use: "noop", Run: func(*cobra.Command, []string) {}})
+		root.SetArgs(append([]string{"noop", "--offline"}, args...))
+		assert.NoError(t, root.Execute())
+	}
+
+	// Plain output leaves out colors and emoji...
+	run("--plain")
+	assert.True(t, cmdutil.Plain)
+	assert.False(t, cmdutil.Emoji)
+	assert.Equal(t, colors.Never, cmdutil.GetGlobalColorization())
+
+	// ...unless emoji are explicitly asked for.
+	run("--plain", "--emoji")
+	assert.True(t, cmdutil.Plain)
+	assert.True(t, cmdutil.Emoji)
+
+	run("--plain=false", "--emoji")
+	assert.False(t, cmdutil.Plain)
+	assert.True(t, cmdutil.Emoji)
+}
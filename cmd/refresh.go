@@ -29,6 +29,7 @@ import (
 
 func newRefreshCmd() *cobra.Command {
 	var debug bool
+	var eventLog string
 	var expectNop bool
 	var message string
 	var stack string
@@ -36,10 +37,13 @@ func newRefreshCmd() *cobra.Command {
 	// Flags for engine.UpdateOptions.
 	var analyzers []string
 	var diffDisplay bool
+	var jsonDisplay bool
+	var progressFormat string
 	var parallel int
 	var showConfig bool
 	var showReplacementSteps bool
 	var showSames bool
+	var showTimings bool
 	var skipPreview bool
 	var suppressOutputs bool
 	var yes bool
@@ -59,9 +63,6 @@ func newRefreshCmd() *cobra.Command {
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) *result.Result {
 			interactive := cmdutil.Interactive()
-			if !interactive {
-				yes = true // auto-approve changes, since we cannot prompt.
-			}
 
 			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
 			if err != nil {
@@ -73,10 +74,22 @@ func newRefreshCmd() *cobra.Command {
 				ShowConfig:           showConfig,
 				ShowReplacementSteps: showReplacementSteps,
 				ShowSameResources:    showSames,
+				ShowTimings:          showTimings,
 				SuppressOutputs:      suppressOutputs,
 				IsInteractive:        interactive,
 				DiffDisplay:          diffDisplay,
 				Debug:                debug,
+				EventLogPath:         eventLog,
+			}
+			if err = jsonFlagsToOptions(&opts, jsonDisplay); err != nil {
+				return result.FromError(err)
+			}
+			if err = progressFormatToOptions(&opts, progressFormat, false /*isPreview*/); err != nil {
+				return result.FromError(err)
+			}
+
+			if err := resetEventLog(eventLog); err != nil {
+				return result.FromError(err)
 			}
 
 			s, err := requireStack(stack, true, opts.Display, true /*setCurrent*/)
@@ -94,10 +107,16 @@ func newRefreshCmd() *cobra.Command {
 				return result.FromError(errors.Wrap(err, "gathering environment metadata"))
 			}
 
+			suppressDiffs, err := stackDiffSuppressions(s)
+			if err != nil {
+				return result.FromError(err)
+			}
+
 			opts.Engine = engine.UpdateOptions{
-				Analyzers: analyzers,
-				Parallel:  parallel,
-				Debug:     debug,
+				Analyzers:     analyzers,
+				Parallel:      parallel,
+				Debug:         debug,
+				SuppressDiffs: suppressDiffs,
 			}
 
 			changes, err := s.Refresh(commandContext(), backend.UpdateOperation{
@@ -123,6 +142,9 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLog, "event-log", "",
+		"Log every engine event to the given file as newline-delimited JSON")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
 		"Return an error if any changes occur during this update")
@@ -144,6 +166,13 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonDisplay, "json", "j", false,
+		"Serialize the refresh's operations and overall output as JSON. Requires --yes, and skips the preview")
+	cmd.PersistentFlags().StringVar(
+		&progressFormat, "progress-format", "text",
+		"Display progress as 'text', or as 'json': newline-delimited JSON events on stdout, "+
+			"for other programs to render")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
@@ -153,6 +182,10 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that needn't be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&showTimings, "show-timings", false,
+		"Show the slowest resources, the critical path of resources that determined how long the refresh took, and "+
+			"the number and latency of the calls made to each provider")
 	cmd.PersistentFlags().BoolVar(
 		&skipPreview, "skip-preview", false,
 		"Do not perform a preview before performing the refresh")
@@ -0,0 +1,85 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// rolloutPhaseName returns the name by which the CLI reports the given phase of a project's staged rollout.
+func rolloutPhaseName(i int, phase workspace.ProjectRolloutPhase) string {
+	if phase.Name != "" {
+		return phase.Name
+	}
+	return fmt.Sprintf("phase %d", i+1)
+}
+
+// updateInPhases runs update once for each phase of the project's staged rollout, in order, or just once if the
+// project does not declare a rollout. After each phase but the last, the phase's verify commands run and the rollout
+// pauses for the phase's configured duration; a failing update or verify command, or canceling the given context,
+// stops the rollout. The returned changes are the sum of the changes made by each phase.
+func updateInPhases(ctx context.Context, proj *workspace.Project, root string, stackName tokens.QName,
+	opts backend.UpdateOptions,
+	update func(opts backend.UpdateOptions) (engine.ResourceChanges, error)) (engine.ResourceChanges, error) {
+
+	if len(proj.Rollout) == 0 {
+		return update(opts)
+	}
+
+	total := engine.ResourceChanges{}
+	for i, phase := range proj.Rollout {
+		name := rolloutPhaseName(i, phase)
+		cmdutil.Diag().Infof(diag.Message("", "Rolling out %s (%d of %d)"), name, i+1, len(proj.Rollout))
+
+		opts.Engine.RolloutPhase = i + 1
+		changes, err := update(opts)
+		for op, count := range changes {
+			total[op] += count
+		}
+		if err != nil || i == len(proj.Rollout)-1 {
+			return total, err
+		}
+
+		env := append(projectHookEnv(proj, stackName, "verify", nil), "PULUMI_ROLLOUT_PHASE="+name)
+		if err = runShellCommands(name+" verification", phase.Verify, root, env, hookOutput(opts)); err != nil {
+			return total, err
+		}
+		if phase.Pause != "" {
+			pause, err := time.ParseDuration(phase.Pause)
+			if err != nil {
+				return total, err
+			}
+			cmdutil.Diag().Infof(diag.Message("", "Pausing for %v before the next phase"), pause)
+
+			timer := time.NewTimer(pause)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return total, ctx.Err()
+			}
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestUpdateInPhases(t *testing.T) {
+	var phases []int
+	update := func(opts backend.UpdateOptions) (engine.ResourceChanges, error) {
+		phases = append(phases, opts.Engine.RolloutPhase)
+		return engine.ResourceChanges{deploy.OpUpdate: 1}, nil
+	}
+
+	// Without a rollout, the update runs once, for every phase.
+	proj := &workspace.Project{Name: "proj"}
+	changes, err := updateInPhases(context.Background(), proj, ".", "dev", backend.UpdateOptions{}, update)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, phases)
+	assert.Equal(t, 1, changes[deploy.OpUpdate])
+
+	// With a rollout, the update runs once per phase, and the changes of each phase are summed.
+	phases = nil
+	proj.Rollout = []workspace.ProjectRolloutPhase{
+		{Name: "canary", Resources: []string{"web-0"}, Verify: []string{"exit 0"}},
+		{},
+	}
+	changes, err = updateInPhases(context.Background(), proj, ".", "dev", backend.UpdateOptions{}, update)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, phases)
+	assert.Equal(t, 2, changes[deploy.OpUpdate])
+
+	// A failing verify command stops the rollout.
+	phases = nil
+	proj.Rollout[0].Verify = []string{"exit 1"}
+	_, err = updateInPhases(context.Background(), proj, ".", "dev", backend.UpdateOptions{}, update)
+	assert.Error(t, err)
+	assert.Equal(t, []int{1}, phases)
+
+	// Canceling the update stops the rollout while it is paused between phases.
+	phases = nil
+	proj.Rollout[0].Verify = nil
+	proj.Rollout[0].Pause = "1h"
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = updateInPhases(ctx, proj, ".", "dev", backend.UpdateOptions{}, update)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []int{1}, phases)
+}
@@ -22,15 +22,20 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
 
 func newStackCmd() *cobra.Command {
+	var jsonOut bool
 	var showIDs bool
 	var showURNs bool
+	var showURLs bool
+	var full bool
 	var stackName string
 
 	cmd := &cobra.Command{
@@ -56,6 +61,10 @@ func newStackCmd() *cobra.Command {
 				return err
 			}
 
+			if jsonOut {
+				return printJSON(makeStackJSON(s, snap))
+			}
+
 			// First print general info about the current stack.
 			fmt.Printf("Current stack is %s:\n", s.Ref())
 
@@ -125,6 +134,11 @@ func newStackCmd() *cobra.Command {
 					if showIDs && res.ID != "" {
 						additionalInfo += fmt.Sprintf("        ID: %s\n", res.ID)
 					}
+					if cs, ok := s.(httpstate.Stack); ok && showURLs {
+						if resourceURL, err := cs.ResourceConsoleURL(res.URN); err == nil {
+							additionalInfo += fmt.Sprintf("        URL: %s\n", resourceURL)
+						}
+					}
 
 					rows = append(rows, cmdutil.TableRow{Columns: columns, AdditionalInfo: additionalInfo})
 				}
@@ -133,12 +147,13 @@ func newStackCmd() *cobra.Command {
 					Headers: []string{"TYPE", "NAME"},
 					Rows:    rows,
 					Prefix:  "    ",
+					Full:    full,
 				})
 
 				// Print out the output properties for the stack, if present.
 				if res, outputs := stack.GetRootStackResource(snap); res != nil {
 					fmt.Printf("\n")
-					printStackOutputs(outputs)
+					printStackOutputs(outputs, full)
 				}
 			}
 
@@ -160,25 +175,118 @@ func newStackCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(
 		&stackName, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
 	cmd.PersistentFlags().BoolVarP(
 		&showIDs, "show-ids", "i", false, "Display each resource's provider-assigned unique ID")
 	cmd.PersistentFlags().BoolVarP(
 		&showURNs, "show-urns", "u", false, "Display each resource's Pulumi-assigned globally unique URN")
+	cmd.Flags().BoolVar(
+		&full, "full", false,
+		"Show long values in full, wrapped onto more lines, instead of truncating them to fit the terminal")
+	cmd.PersistentFlags().BoolVar(
+		&showURLs, "show-urls", false, "Display a link to each resource's page in the Pulumi console, if there is one")
 
 	cmd.AddCommand(newStackExportCmd())
+	cmd.AddCommand(newStackGCResourcesCmd())
 	cmd.AddCommand(newStackGraphCmd())
+	cmd.AddCommand(newHistoryCmd())
 	cmd.AddCommand(newStackImportCmd())
 	cmd.AddCommand(newStackInitCmd())
 	cmd.AddCommand(newStackLsCmd())
 	cmd.AddCommand(newStackOutputCmd())
+	cmd.AddCommand(newStackQueryCmd())
 	cmd.AddCommand(newStackRmCmd())
+	cmd.AddCommand(newStackRollbackCmd())
 	cmd.AddCommand(newStackSelectCmd())
+	cmd.AddCommand(newStackStatsCmd())
 	cmd.AddCommand(newStackTagCmd())
 
 	return cmd
 }
 
-func printStackOutputs(outputs map[string]interface{}) {
+// stackJSON is the shape of the --json output of `pulumi stack`. While we can add fields to this structure in the
+// future, we should not change the existing fields.
+type stackJSON struct {
+	Name          string                 `json:"name"`
+	Backend       string                 `json:"backend"`
+	Owner         string                 `json:"owner,omitempty"`
+	LastUpdate    string                 `json:"lastUpdate,omitempty"`
+	PulumiVersion string                 `json:"pulumiVersion,omitempty"`
+	Plugins       []stackPluginJSON      `json:"plugins"`
+	Resources     []stackResourceJSON    `json:"resources"`
+	Outputs       map[string]interface{} `json:"outputs"`
+	URL           string                 `json:"url,omitempty"`
+}
+
+// stackPluginJSON describes a plugin used by the last update of a stack in the --json output of `pulumi stack`.
+type stackPluginJSON struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Version string `json:"version,omitempty"`
+}
+
+// stackResourceJSON describes a resource in the --json output of `pulumi stack`.
+type stackResourceJSON struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	URN  string `json:"urn"`
+	ID   string `json:"id,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// makeStackJSON describes a stack, and the given snapshot of its state, for the --json output of `pulumi stack`.
+func makeStackJSON(s backend.Stack, snap *deploy.Snapshot) stackJSON {
+	result := stackJSON{
+		Name:      string(s.Ref().Name()),
+		Backend:   s.Backend().Name(),
+		Plugins:   []stackPluginJSON{},
+		Resources: []stackResourceJSON{},
+		Outputs:   make(map[string]interface{}),
+	}
+	cs, isCloud := s.(httpstate.Stack)
+	if isCloud {
+		result.Owner = cs.OrgName()
+		if consoleURL, err := cs.ConsoleURL(); err == nil {
+			result.URL = consoleURL
+		}
+	}
+	if snap == nil {
+		return result
+	}
+
+	if t := snap.Manifest.Time; !t.IsZero() {
+		result.LastUpdate = t.UTC().Format(timeFormat)
+	}
+	result.PulumiVersion = snap.Manifest.Version
+	for _, plugin := range snap.Manifest.Plugins {
+		p := stackPluginJSON{Name: plugin.Name, Kind: string(plugin.Kind)}
+		if plugin.Version != nil {
+			p.Version = plugin.Version.String()
+		}
+		result.Plugins = append(result.Plugins, p)
+	}
+	for _, res := range snap.Resources {
+		r := stackResourceJSON{
+			Type: string(res.Type),
+			Name: string(res.URN.Name()),
+			URN:  string(res.URN),
+			ID:   string(res.ID),
+		}
+		if isCloud {
+			if resourceURL, err := cs.ResourceConsoleURL(res.URN); err == nil {
+				r.URL = resourceURL
+			}
+		}
+		result.Resources = append(result.Resources, r)
+	}
+	if _, outputs := stack.GetRootStackResource(snap); outputs != nil {
+		result.Outputs = outputs
+	}
+	return result
+}
+
+func printStackOutputs(outputs map[string]interface{}, full bool) {
 	fmt.Printf("Current stack outputs (%d):\n", len(outputs))
 	if len(outputs) == 0 {
 		fmt.Printf("    No output values currently in this stack\n")
@@ -199,6 +307,7 @@ func printStackOutputs(outputs map[string]interface{}) {
 			Headers: []string{"OUTPUT", "VALUE"},
 			Rows:    rows,
 			Prefix:  "    ",
+			Full:    full,
 		})
 	}
 }
@@ -22,11 +22,13 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
 
 func newStackExportCmd() *cobra.Command {
 	var file string
+	var format string
 	var stackName string
 
 	cmd := &cobra.Command{
@@ -38,7 +40,12 @@ func newStackExportCmd() *cobra.Command {
 			"The deployment can then be hand-edited and used to update the stack via\n" +
 			"`pulumi stack import`. This process may be used to correct inconsistencies\n" +
 			"in a stack's state due to failed deployments, manual changes to cloud\n" +
-			"resources, etc.",
+			"resources, etc.\n" +
+			"\n" +
+			"Pass `--format tfstate` to instead export a best-effort rendering of the stack's resources in the\n" +
+			"Terraform state file format, for use with tools that read Terraform state. Resource types and\n" +
+			"properties are mapped using the conventions of providers bridged from Terraform; the result\n" +
+			"cannot be imported back into Pulumi.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -55,6 +62,20 @@ func newStackExportCmd() *cobra.Command {
 				return err
 			}
 
+			var exported interface{}
+			switch format {
+			case "json":
+				exported = deployment
+			case "tfstate":
+				snap, err := stack.DeserializeUntypedDeployment(deployment)
+				if err != nil {
+					return errors.Wrap(err, "could not read deployment")
+				}
+				exported = stack.SerializeTerraformState(snap)
+			default:
+				return errors.Errorf("unsupported export format '%s': must be 'json' or 'tfstate'", format)
+			}
+
 			// Read from stdin or a specified file.
 			writer := os.Stdout
 			if file != "" {
@@ -67,7 +88,7 @@ func newStackExportCmd() *cobra.Command {
 			// Write the deployment.
 			enc := json.NewEncoder(writer)
 			enc.SetIndent("", "    ")
-			if err = enc.Encode(deployment); err != nil {
+			if err = enc.Encode(exported); err != nil {
 				return errors.Wrap(err, "could not export deployment")
 			}
 			return nil
@@ -77,5 +98,7 @@ func newStackExportCmd() *cobra.Command {
 		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
 	cmd.PersistentFlags().StringVarP(
 		&file, "file", "", "", "A filename to write stack output to")
+	cmd.PersistentFlags().StringVar(
+		&format, "format", "json", "The format to export the deployment in: 'json' or 'tfstate'")
 	return cmd
 }
@@ -0,0 +1,183 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/edit"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// orphanedResource is a resource that `pulumi stack gc-resources` has identified as a candidate for removal.
+type orphanedResource struct {
+	State  *resource.State
+	Reason string
+}
+
+func newStackGCResourcesCmd() *cobra.Command {
+	var stackName string
+	var prune bool
+	var skipProviderCheck bool
+
+	cmd := &cobra.Command{
+		Use:   "gc-resources",
+		Args:  cmdutil.NoArgs,
+		Short: "Find (and optionally remove) orphaned resources in a stack's state",
+		Long: "Find (and optionally remove) orphaned resources in a stack's state.\n" +
+			"\n" +
+			"A resource is orphaned if its provider reports that it no longer exists, or if it cannot be\n" +
+			"reached from the stack's root resource by following parent links. Orphaned resources are\n" +
+			"listed; pass `--prune` to also remove them from the stack's state. Pruning does not delete\n" +
+			"anything from the cloud; resources that are protected or that other resources depend upon\n" +
+			"are left in place.\n" +
+			"\n" +
+			"Checking whether resources still exist requires the stack's resource provider plugins. Pass\n" +
+			"`--skip-provider-check` to only look for unreachable resources.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+			if snap == nil {
+				fmt.Println("No orphaned resources found")
+				return nil
+			}
+
+			orphans, err := findOrphanedResources(snap, skipProviderCheck)
+			if err != nil {
+				return err
+			}
+			if len(orphans) == 0 {
+				fmt.Println("No orphaned resources found")
+				return nil
+			}
+
+			var rows []cmdutil.TableRow
+			for _, orphan := range orphans {
+				rows = append(rows, cmdutil.TableRow{
+					Columns: []string{string(orphan.State.Type), string(orphan.State.URN.Name()),
+						string(orphan.State.ID), orphan.Reason},
+				})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"TYPE", "NAME", "ID", "REASON"},
+				Rows:    rows,
+			})
+
+			if !prune {
+				fmt.Println()
+				fmt.Println("Rerun with --prune to remove these resources from the stack's state")
+				return nil
+			}
+
+			// Resources are identified by URN and ID, since the snapshot is reloaded in order to edit it.
+			type resourceKey struct {
+				URN resource.URN
+				ID  resource.ID
+			}
+			isOrphan := make(map[resourceKey]bool)
+			for _, orphan := range orphans {
+				isOrphan[resourceKey{orphan.State.URN, orphan.State.ID}] = true
+			}
+
+			removed := 0
+			err = runTotalStateEdit(stackName, func(opts display.Options, snap *deploy.Snapshot) error {
+				var condemned []*resource.State
+				for _, res := range snap.Resources {
+					if !res.Delete && isOrphan[resourceKey{res.URN, res.ID}] {
+						condemned = append(condemned, res)
+					}
+				}
+
+				failures := edit.DeleteResources(snap, condemned)
+				for _, res := range condemned {
+					failure, failed := failures[res]
+					if !failed {
+						continue
+					}
+					fmt.Println(opts.Color.Colorize(fmt.Sprintf("%swarning%s: not removing %s: %v",
+						colors.SpecWarning, colors.Reset, res.URN, failure)))
+				}
+				removed = len(condemned) - len(failures)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Removed %d resource(s) from the stack's state\n", removed)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&prune, "prune", false, "Remove the orphaned resources from the stack's state, after confirmation")
+	cmd.PersistentFlags().BoolVar(
+		&skipProviderCheck, "skip-provider-check", false,
+		"Do not ask resource providers whether resources still exist; only look for unreachable resources")
+
+	return cmd
+}
+
+// findOrphanedResources returns the orphaned resources in the given snapshot, in snapshot order.
+func findOrphanedResources(snap *deploy.Snapshot, skipProviderCheck bool) ([]orphanedResource, error) {
+	reasons := make(map[*resource.State]string)
+	for _, res := range edit.FindUnreachableResources(snap) {
+		reasons[res] = "unreachable from the stack"
+	}
+
+	if !skipProviderCheck {
+		plugctx, err := newStatePluginContext()
+		if err != nil {
+			return nil, err
+		}
+		defer contract.IgnoreClose(plugctx)
+
+		missing, err := edit.FindMissingResources(plugctx.Host, snap)
+		if err != nil {
+			return nil, errors.Wrap(err, "checking whether resources still exist")
+		}
+		for _, res := range missing {
+			reasons[res] = "deleted outside of Pulumi"
+		}
+	}
+
+	var orphans []orphanedResource
+	for _, res := range snap.Resources {
+		if reason, has := reasons[res]; has {
+			orphans = append(orphans, orphanedResource{State: res, Reason: reason})
+		}
+	}
+	return orphans, nil
+}
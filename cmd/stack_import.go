@@ -26,11 +26,13 @@ import (
 	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
 
 func newStackImportCmd() *cobra.Command {
+	var dryRun bool
 	var force bool
 	var file string
 	var stackName string
@@ -43,7 +45,11 @@ func newStackImportCmd() *cobra.Command {
 			"A deployment that was exported from a stack using `pulumi stack export` and\n" +
 			"hand-edited to correct inconsistencies due to failed updates, manual changes\n" +
 			"to cloud resources, etc. can be reimported to the stack using this command.\n" +
-			"The updated deployment will be read from standard in.",
+			"The updated deployment will be read from standard in.\n" +
+			"\n" +
+			"Deployments written by older versions of the Pulumi CLI are migrated to the\n" +
+			"current format as they are imported. Pass --dry-run to validate a deployment\n" +
+			"and report the migrations that would be applied without importing it.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -75,7 +81,12 @@ func newStackImportCmd() *cobra.Command {
 			// We do, however, now want to unmarshal the json.RawMessage into a real, typed deployment.  We do this so
 			// we can check that the deployment doesn't contain resources from a stack other than the selected one. This
 			// catches errors wherein someone imports the wrong stack's deployment (which can seriously hork things).
-			snapshot, err := stack.DeserializeUntypedDeployment(&deployment)
+			// Older deployments are migrated to the current schema version as they are deserialized.
+			v3deployment, report, err := stack.MigrateUntypedDeployment(&deployment)
+			var snapshot *deploy.Snapshot
+			if err == nil {
+				snapshot, err = stack.DeserializeDeploymentV3(*v3deployment)
+			}
 			if err != nil {
 				switch err {
 				case stack.ErrDeploymentSchemaVersionTooOld:
@@ -111,6 +122,12 @@ func newStackImportCmd() *cobra.Command {
 					errors.New("importing this file could be dangerous; rerun with --force to proceed anyway"))
 			}
 
+			// If this is a dry run, report what would be migrated and stop short of importing anything.
+			if dryRun {
+				printMigrationReport(report)
+				return nil
+			}
+
 			// Explicitly clear-out any pending operations.
 			if snapshot.PendingOperations != nil {
 				for _, op := range snapshot.PendingOperations {
@@ -142,6 +159,9 @@ func newStackImportCmd() *cobra.Command {
 
 	cmd.PersistentFlags().StringVarP(
 		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&dryRun, "dry-run", false,
+		"Validate the deployment and report the migrations that would be applied, but do not import it")
 	cmd.PersistentFlags().BoolVarP(
 		&force, "force", "f", false,
 		"Force the import to occur, even if apparent errors are discovered beforehand (not recommended)")
@@ -150,3 +170,17 @@ func newStackImportCmd() *cobra.Command {
 
 	return cmd
 }
+
+// printMigrationReport prints a summary of the schema migrations that were, or would be, applied to a deployment.
+func printMigrationReport(report *stack.MigrationReport) {
+	if !report.Migrated() {
+		fmt.Printf("The deployment is already at the current schema version (%d); no migration is needed.\n",
+			report.ToVersion)
+		return
+	}
+
+	fmt.Printf("The deployment would be migrated from schema version %d to %d:\n", report.FromVersion, report.ToVersion)
+	for _, step := range report.Steps {
+		fmt.Printf("    %d -> %d: %s\n", step.From, step.To, step.Description)
+	}
+}
@@ -34,6 +34,7 @@ import (
 func newStackLsCmd() *cobra.Command {
 	var allStacks bool
 	var jsonOut bool
+	var full bool
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List all known stacks",
@@ -83,13 +84,16 @@ func newStackLsCmd() *cobra.Command {
 				return formatStackSummariesJSON(b, current, stackSummaries)
 			}
 
-			return formatStackSummariesConsole(b, current, stackSummaries)
+			return formatStackSummariesConsole(b, current, stackSummaries, full)
 		}),
 	}
 	cmd.PersistentFlags().BoolVarP(
 		&jsonOut, "json", "j", false, "Emit output as JSON")
 	cmd.PersistentFlags().BoolVarP(
 		&allStacks, "all", "a", false, "List all stacks instead of just stacks for the current project")
+	cmd.PersistentFlags().BoolVar(
+		&full, "full", false,
+		"Show long values in full, wrapped onto more lines, instead of truncating them to fit the terminal")
 
 	return cmd
 }
@@ -135,7 +139,8 @@ func formatStackSummariesJSON(b backend.Backend, currentStack string, stackSumma
 	return printJSON(output)
 }
 
-func formatStackSummariesConsole(b backend.Backend, currentStack string, stackSummaries []backend.StackSummary) error {
+func formatStackSummariesConsole(b backend.Backend, currentStack string, stackSummaries []backend.StackSummary,
+	full bool) error {
 	_, showURLColumn := b.(httpstate.Backend)
 
 	// Header string and formatting options to align columns.
@@ -190,6 +195,7 @@ func formatStackSummariesConsole(b backend.Backend, currentStack string, stackSu
 	cmdutil.PrintTable(cmdutil.Table{
 		Headers: headers,
 		Rows:    rows,
+		Full:    full,
 	})
 
 	return nil
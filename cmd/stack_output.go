@@ -27,6 +27,7 @@ import (
 
 func newStackOutputCmd() *cobra.Command {
 	var jsonOut bool
+	var full bool
 	var stackName string
 
 	cmd := &cobra.Command{
@@ -77,7 +78,7 @@ func newStackOutputCmd() *cobra.Command {
 					return err
 				}
 			} else {
-				printStackOutputs(outputs)
+				printStackOutputs(outputs, full)
 			}
 			return nil
 		}),
@@ -85,6 +86,9 @@ func newStackOutputCmd() *cobra.Command {
 
 	cmd.PersistentFlags().BoolVarP(
 		&jsonOut, "json", "j", false, "Emit output as JSON")
+	cmd.PersistentFlags().BoolVar(
+		&full, "full", false,
+		"Show long values in full, wrapped onto more lines, instead of truncating them to fit the terminal")
 	cmd.PersistentFlags().StringVarP(
 		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
 
@@ -0,0 +1,123 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackQueryCmd() *cobra.Command {
+	var stackName string
+
+	cmd := &cobra.Command{
+		Use:   "query <expression>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Query a stack's deployment with a JMESPath expression",
+		Long: "Query a stack's deployment with a JMESPath expression.\n" +
+			"\n" +
+			"The expression is evaluated against a JSON document with the following properties, and\n" +
+			"the result is printed as JSON:\n" +
+			"\n" +
+			"    manifest   the manifest of the stack's latest deployment\n" +
+			"    resources  the stack's resources, in the same form as `pulumi stack export`\n" +
+			"    outputs    the stack's output properties\n" +
+			"\n" +
+			"For example, to list the URNs of all security groups that allow inbound traffic on port 22:\n" +
+			"\n" +
+			"    pulumi stack query \"resources[?type=='aws:ec2/securityGroup:SecurityGroup' &&\n" +
+			"        outputs.ingress[?fromPort<=\\`22\\` && toPort>=\\`22\\`]].urn\"\n" +
+			"\n" +
+			"See http://jmespath.org for a description of the expression language.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			query, err := jmespath.Compile(args[0])
+			if err != nil {
+				return errors.Wrap(err, "invalid query expression")
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+
+			doc, err := stackQueryDocument(snap)
+			if err != nil {
+				return err
+			}
+			result, err := query.Search(doc)
+			if err != nil {
+				return errors.Wrap(err, "evaluating query expression")
+			}
+
+			return printJSON(result)
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// stackQueryDocument returns the document that `pulumi stack query` expressions are evaluated against. The document
+// is built from the serialized form of the snapshot, so that queries may be written against the familiar shape of
+// `pulumi stack export`.
+func stackQueryDocument(snap *deploy.Snapshot) (interface{}, error) {
+	if snap == nil {
+		snap = deploy.NewSnapshot(deploy.Manifest{}, nil, nil)
+	}
+	_, outputs := stack.GetRootStackResource(snap)
+
+	deployment := stack.SerializeDeployment(snap)
+	resources := deployment.Resources
+	if resources == nil {
+		resources = []apitype.ResourceV3{}
+	}
+	if outputs == nil {
+		outputs = make(map[string]interface{})
+	}
+
+	// JMESPath only understands the generic representation of JSON values, so round trip the document through JSON.
+	b, err := json.Marshal(map[string]interface{}{
+		"manifest":  deployment.Manifest,
+		"resources": resources,
+		"outputs":   outputs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestStackQueryDocument(t *testing.T) {
+	newState := func(t tokens.Type, name string, outputs map[string]interface{}) *resource.State {
+		return &resource.State{
+			Type:    t,
+			URN:     resource.NewURN("stack", "proj", "", t, tokens.QName(name)),
+			Custom:  t != resource.RootStackType,
+			Inputs:  resource.PropertyMap{},
+			Outputs: resource.NewPropertyMapFromMap(outputs),
+		}
+	}
+	sgType := tokens.Type("aws:ec2/securityGroup:SecurityGroup")
+	snap := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{
+		newState(resource.RootStackType, "proj-stack", map[string]interface{}{"url": "http://example.com"}),
+		newState(sgType, "ssh", map[string]interface{}{
+			"ingress": []interface{}{
+				map[string]interface{}{"fromPort": 22, "toPort": 22},
+			},
+		}),
+		newState(sgType, "web", map[string]interface{}{
+			"ingress": []interface{}{
+				map[string]interface{}{"fromPort": 80, "toPort": 80},
+				map[string]interface{}{"fromPort": 443, "toPort": 443},
+			},
+		}),
+	}, nil)
+
+	doc, err := stackQueryDocument(snap)
+	assert.NoError(t, err)
+
+	result, err := jmespath.Search("outputs.url", doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com", result)
+
+	result, err = jmespath.Search(
+		"resources[?type=='aws:ec2/securityGroup:SecurityGroup' && "+
+			"outputs.ingress[?fromPort<=`22` && toPort>=`22`]].urn", doc)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{string(snap.Resources[1].URN)}, result)
+
+	doc, err = stackQueryDocument(nil)
+	assert.NoError(t, err)
+	result, err = jmespath.Search("length(resources)", doc)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), result)
+}
@@ -65,7 +65,7 @@ func newStackRmCmd() *cobra.Command {
 			// Ensure the user really wants to do this.
 			prompt := fmt.Sprintf("This will permanently remove the '%s' stack!", s.Ref())
 			if !yes && !confirmPrompt(prompt, s.Ref().String(), opts) {
-				return errors.New("confirmation declined")
+				return confirmationDeclined()
 			}
 
 			hasResources, err := s.Remove(commandContext(), force)
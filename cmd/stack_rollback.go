@@ -0,0 +1,190 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+func newStackRollbackCmd() *cobra.Command {
+	var debug bool
+	var message string
+	var stack string
+	var to int
+
+	// Flags for engine.UpdateOptions.
+	var diffDisplay bool
+	var parallel int
+	var showReplacementSteps bool
+	var showSames bool
+	var skipPreview bool
+	var suppressOutputs bool
+	var yes bool
+
+	var cmd = &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll a stack's resources back to an earlier version",
+		Long: "Roll a stack's resources back to an earlier version.\n" +
+			"\n" +
+			"This command restores the resources recorded by an earlier version of the stack, as listed by\n" +
+			"`pulumi history`. Rather than running the program, it registers each resource recorded by that\n" +
+			"version with the inputs and options it had at the time, and performs an update that brings the\n" +
+			"stack's current resources in line with them: resources that have since changed are updated back,\n" +
+			"resources that have since been deleted are recreated, and resources that have since been added are\n" +
+			"deleted. The rollback is recorded as a new update, so it can itself be rolled back.\n" +
+			"\n" +
+			"By default, the stack is rolled back to the most recent successful update before the latest one.\n" +
+			"Use --to to choose a different version.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) *result.Result {
+			interactive := cmdutil.Interactive()
+
+			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			opts.Display = display.Options{
+				Color:                cmdutil.GetGlobalColorization(),
+				ShowReplacementSteps: showReplacementSteps,
+				ShowSameResources:    showSames,
+				SuppressOutputs:      suppressOutputs,
+				IsInteractive:        interactive,
+				DiffDisplay:          diffDisplay,
+				Debug:                debug,
+			}
+
+			s, err := requireStack(stack, false /*offerNew*/, opts.Display, true /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			if to == 0 {
+				updates, err := s.Backend().GetHistory(commandContext(), s.Ref())
+				if err != nil {
+					return result.FromError(errors.Wrap(err, "getting history"))
+				}
+				if to, err = previousSuccessfulVersion(updates); err != nil {
+					return result.FromError(err)
+				}
+			} else if to < 0 {
+				return result.Errorf("invalid version %d: versions must be positive integers", to)
+			}
+
+			snap, err := getHistoricalSnapshot(s, to)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			proj, root, err := readProject()
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			if message == "" {
+				message = fmt.Sprintf("Rollback to version %d", to)
+			}
+			m, err := getUpdateMetadata(message, root)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "gathering environment metadata"))
+			}
+
+			opts.Engine = engine.UpdateOptions{
+				Parallel: parallel,
+				Debug:    debug,
+
+				RollbackSnapshot: snap,
+			}
+
+			_, err = s.Update(commandContext(), backend.UpdateOperation{
+				Proj:   proj,
+				Root:   root,
+				M:      m,
+				Opts:   opts,
+				Scopes: cancellationScopes,
+			})
+			switch {
+			case err == context.Canceled:
+				return result.FromError(errors.New("rollback cancelled"))
+			case err != nil:
+				return PrintEngineError(err)
+			default:
+				return nil
+			}
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&debug, "debug", "d", false,
+		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVarP(
+		&message, "message", "m", "",
+		"Optional message to associate with the rollback operation")
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().IntVar(
+		&to, "to", 0,
+		"The version of the stack to roll back to. Defaults to the last successful update before the latest one")
+
+	// Flags for engine.UpdateOptions.
+	cmd.PersistentFlags().BoolVar(
+		&diffDisplay, "diff", false,
+		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().IntVarP(
+		&parallel, "parallel", "p", defaultParallel,
+		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
+	cmd.PersistentFlags().BoolVar(
+		&showReplacementSteps, "show-replacement-steps", false,
+		"Show detailed resource replacement creates and deletes instead of a single step")
+	cmd.PersistentFlags().BoolVar(
+		&showSames, "show-sames", false,
+		"Show resources that don't need be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&skipPreview, "skip-preview", false,
+		"Do not perform a preview before performing the rollback")
+	cmd.PersistentFlags().BoolVar(
+		&suppressOutputs, "suppress-outputs", false,
+		"Suppress display of stack outputs (in case they contain sensitive values)")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Automatically approve and perform the rollback after previewing it")
+
+	return cmd
+}
+
+// previousSuccessfulVersion returns the version produced by the most recent successful update before the latest one,
+// given a stack's history in descending order.
+func previousSuccessfulVersion(updates []backend.UpdateInfo) (int, error) {
+	if len(updates) > 0 {
+		latest := updates[0].Version
+		for _, update := range updates[1:] {
+			if update.Version > 0 && update.Version < latest && update.Result == backend.SucceededResult {
+				return update.Version, nil
+			}
+		}
+	}
+	return 0, errors.New("the stack has no earlier successful update to roll back to; use --to to pick a version")
+}
@@ -0,0 +1,44 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+)
+
+func TestPreviousSuccessfulVersion(t *testing.T) {
+	update := func(version int, result backend.UpdateResult) backend.UpdateInfo {
+		return backend.UpdateInfo{Version: version, Result: result}
+	}
+
+	// The latest update is skipped, as are failed updates before it.
+	v, err := previousSuccessfulVersion([]backend.UpdateInfo{
+		update(4, backend.SucceededResult),
+		update(3, backend.FailedResult),
+		update(2, backend.SucceededResult),
+		update(1, backend.SucceededResult),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	_, err = previousSuccessfulVersion([]backend.UpdateInfo{update(1, backend.SucceededResult)})
+	assert.Error(t, err)
+	_, err = previousSuccessfulVersion(nil)
+	assert.Error(t, err)
+}
@@ -68,7 +68,7 @@ func newStackSelectCmd() *cobra.Command {
 					return state.SetCurrentStack(stackRef.String())
 				}
 
-				return errors.Errorf("no stack named '%s' found", stackRef)
+				return stackNotFoundError(b, stackRef.String())
 			}
 
 			// If no stack was given, prompt the user to select a name from the available ones.
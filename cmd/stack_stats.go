@@ -0,0 +1,284 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+func newStackStatsCmd() *cobra.Command {
+	var stackName string
+	var jsonOut bool
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Args:  cmdutil.NoArgs,
+		Short: "Show statistics about a stack's resources and updates",
+		Long: "Show statistics about a stack's resources and updates.\n" +
+			"\n" +
+			"This command reports the number of resources in the stack by type and by provider, the\n" +
+			"size of the stack's checkpoint, the resource properties that contribute the most to that\n" +
+			"size, and the durations of the stack's most recent updates. This information can help\n" +
+			"to find out what makes a stack slow to update.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			if top < 1 {
+				return errors.New("--top must be at least 1")
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+			updates, err := s.Backend().GetHistory(commandContext(), s.Ref())
+			if err != nil {
+				return errors.Wrap(err, "getting history")
+			}
+
+			stats, err := computeStackStats(snap, updates, top)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(stats)
+			}
+			printStackStats(stats)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+	cmd.PersistentFlags().IntVar(
+		&top, "top", 10, "The number of entries to show in each ranking, and the number of recent updates to show")
+
+	return cmd
+}
+
+// stackStats is the shape of the --json output of `pulumi stack stats`. While we can add fields to this structure in
+// the future, we should not change existing fields.
+type stackStats struct {
+	// Resources is the total number of resources in the stack.
+	Resources int `json:"resources"`
+	// CheckpointSize is the size, in bytes, of the stack's serialized deployment.
+	CheckpointSize int `json:"checkpointSize"`
+	// ResourcesByType counts the stack's resources by type, most common first.
+	ResourcesByType []stackStatsCount `json:"resourcesByType"`
+	// ResourcesByProvider counts the stack's custom resources by the provider that manages them, most common first.
+	ResourcesByProvider []stackStatsCount `json:"resourcesByProvider"`
+	// LargestProperties lists the largest output properties in the stack, largest first.
+	LargestProperties []stackStatsProperty `json:"largestProperties"`
+	// Updates lists the stack's most recent updates, most recent first.
+	Updates []stackStatsUpdate `json:"updates"`
+	// AverageUpdateDuration is the average duration, in seconds, of the updates in Updates that have finished.
+	AverageUpdateDuration int64 `json:"averageUpdateDuration"`
+}
+
+type stackStatsCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type stackStatsProperty struct {
+	URN      resource.URN `json:"urn"`
+	Property string       `json:"property"`
+	Size     int          `json:"size"`
+}
+
+type stackStatsUpdate struct {
+	Version   int    `json:"version,omitempty"`
+	Kind      string `json:"kind"`
+	Result    string `json:"result"`
+	StartTime string `json:"startTime"`
+	Duration  *int64 `json:"duration,omitempty"` // only present once the update finishes.
+}
+
+// computeStackStats computes the statistics reported by `pulumi stack stats` for the given snapshot and update
+// history. Rankings are limited to the top entries, as are the updates, which are expected most recent first.
+func computeStackStats(snap *deploy.Snapshot, updates []backend.UpdateInfo, top int) (*stackStats, error) {
+	if snap == nil {
+		snap = deploy.NewSnapshot(deploy.Manifest{}, nil, nil)
+	}
+
+	stats := &stackStats{
+		Resources:           len(snap.Resources),
+		ResourcesByType:     []stackStatsCount{},
+		ResourcesByProvider: []stackStatsCount{},
+		LargestProperties:   []stackStatsProperty{},
+		Updates:             []stackStatsUpdate{},
+	}
+
+	b, err := json.Marshal(stack.SerializeDeployment(snap))
+	if err != nil {
+		return nil, err
+	}
+	stats.CheckpointSize = len(b)
+
+	byType, byProvider := make(map[string]int), make(map[string]int)
+	for _, res := range snap.Resources {
+		byType[string(res.Type)]++
+
+		if res.Custom && res.Provider != "" {
+			ref, err := providers.ParseReference(res.Provider)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing provider reference for %s", res.URN)
+			}
+			byProvider[fmt.Sprintf("%s (%s)", ref.URN().Type().Name(), ref.URN().Name())]++
+		}
+
+		for k, v := range res.Outputs {
+			pb, err := json.Marshal(stack.SerializePropertyValue(v))
+			if err != nil {
+				return nil, err
+			}
+			stats.LargestProperties = append(stats.LargestProperties,
+				stackStatsProperty{URN: res.URN, Property: string(k), Size: len(pb)})
+		}
+	}
+	stats.ResourcesByType = rankStackStatsCounts(byType, top)
+	stats.ResourcesByProvider = rankStackStatsCounts(byProvider, top)
+
+	sort.SliceStable(stats.LargestProperties, func(i, j int) bool {
+		pi, pj := stats.LargestProperties[i], stats.LargestProperties[j]
+		if pi.Size != pj.Size {
+			return pi.Size > pj.Size
+		}
+		if pi.URN != pj.URN {
+			return pi.URN < pj.URN
+		}
+		return pi.Property < pj.Property
+	})
+	if len(stats.LargestProperties) > top {
+		stats.LargestProperties = stats.LargestProperties[:top]
+	}
+
+	var total, finished int64
+	for i, update := range updates {
+		if i == top {
+			break
+		}
+		info := stackStatsUpdate{
+			Version:   update.Version,
+			Kind:      string(update.Kind),
+			Result:    string(update.Result),
+			StartTime: time.Unix(update.StartTime, 0).UTC().Format(timeFormat),
+		}
+		if update.Result != backend.InProgressResult {
+			duration := update.EndTime - update.StartTime
+			info.Duration = &duration
+			total += duration
+			finished++
+		}
+		stats.Updates = append(stats.Updates, info)
+	}
+	if finished > 0 {
+		stats.AverageUpdateDuration = total / finished
+	}
+
+	return stats, nil
+}
+
+// rankStackStatsCounts returns the top entries of the given counts, largest first.
+func rankStackStatsCounts(counts map[string]int, top int) []stackStatsCount {
+	ranked := []stackStatsCount{}
+	for name, count := range counts {
+		ranked = append(ranked, stackStatsCount{Name: name, Count: count})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+	return ranked
+}
+
+func printStackStats(stats *stackStats) {
+	fmt.Printf("Resources: %d\n", stats.Resources)
+	fmt.Printf("Checkpoint size: %s\n", humanize.Bytes(uint64(stats.CheckpointSize)))
+
+	printCounts := func(title, heading string, counts []stackStatsCount) {
+		if len(counts) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		var rows []cmdutil.TableRow
+		for _, c := range counts {
+			rows = append(rows, cmdutil.TableRow{Columns: []string{c.Name, strconv.Itoa(c.Count)}})
+		}
+		cmdutil.PrintTable(cmdutil.Table{Headers: []string{heading, "COUNT"}, Rows: rows})
+	}
+	printCounts("Resources by type", "TYPE", stats.ResourcesByType)
+	printCounts("Resources by provider", "PROVIDER", stats.ResourcesByProvider)
+
+	if len(stats.LargestProperties) > 0 {
+		fmt.Printf("\nLargest properties:\n")
+		var rows []cmdutil.TableRow
+		for _, p := range stats.LargestProperties {
+			rows = append(rows, cmdutil.TableRow{
+				Columns: []string{string(p.URN), p.Property, humanize.Bytes(uint64(p.Size))},
+			})
+		}
+		cmdutil.PrintTable(cmdutil.Table{Headers: []string{"URN", "PROPERTY", "SIZE"}, Rows: rows})
+	}
+
+	if len(stats.Updates) > 0 {
+		fmt.Printf("\nRecent updates:\n")
+		var rows []cmdutil.TableRow
+		for _, u := range stats.Updates {
+			version, duration := "n/a", "n/a"
+			if u.Version > 0 {
+				version = strconv.Itoa(u.Version)
+			}
+			if u.Duration != nil {
+				duration = (time.Duration(*u.Duration) * time.Second).String()
+			}
+			rows = append(rows, cmdutil.TableRow{Columns: []string{version, u.Kind, u.Result, u.StartTime, duration}})
+		}
+		cmdutil.PrintTable(cmdutil.Table{
+			Headers: []string{"VERSION", "KIND", "RESULT", "STARTED", "DURATION"},
+			Rows:    rows,
+		})
+		fmt.Printf("Average duration: %s\n", time.Duration(stats.AverageUpdateDuration)*time.Second)
+	}
+}
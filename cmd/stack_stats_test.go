@@ -0,0 +1,87 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestComputeStackStats(t *testing.T) {
+	newState := func(t tokens.Type, name string, provider string, outputs map[string]interface{}) *resource.State {
+		return &resource.State{
+			Type:     t,
+			URN:      resource.NewURN("stack", "proj", "", t, tokens.QName(name)),
+			Custom:   t != resource.RootStackType,
+			ID:       resource.ID(name),
+			Provider: provider,
+			Inputs:   resource.PropertyMap{},
+			Outputs:  resource.NewPropertyMapFromMap(outputs),
+		}
+	}
+
+	prov := newState("pulumi:providers:aws", "default", "", nil)
+	ref, err := providers.NewReference(prov.URN, prov.ID)
+	assert.NoError(t, err)
+
+	bucketType := tokens.Type("aws:s3/bucket:Bucket")
+	snap := deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{
+		newState(resource.RootStackType, "proj-stack", "", nil),
+		prov,
+		newState(bucketType, "a", ref.String(), map[string]interface{}{"policy": strings.Repeat("x", 1000)}),
+		newState(bucketType, "b", ref.String(), map[string]interface{}{"policy": "small"}),
+		newState("aws:sqs/queue:Queue", "c", ref.String(), nil),
+	}, nil)
+
+	updates := []backend.UpdateInfo{
+		{Version: 3, Kind: "update", Result: backend.InProgressResult, StartTime: 100},
+		{Version: 2, Kind: "update", Result: backend.SucceededResult, StartTime: 50, EndTime: 80},
+		{Version: 1, Kind: "update", Result: backend.FailedResult, StartTime: 0, EndTime: 10},
+	}
+
+	stats, err := computeStackStats(snap, updates, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, stats.Resources)
+	assert.True(t, stats.CheckpointSize > 1000)
+	assert.Equal(t, []stackStatsCount{
+		{Name: string(bucketType), Count: 2},
+		{Name: "aws:sqs/queue:Queue", Count: 1},
+	}, stats.ResourcesByType)
+	assert.Equal(t, []stackStatsCount{{Name: "aws (default)", Count: 3}}, stats.ResourcesByProvider)
+	if assert.Len(t, stats.LargestProperties, 2) {
+		assert.Equal(t, snap.Resources[2].URN, stats.LargestProperties[0].URN)
+		assert.Equal(t, "policy", stats.LargestProperties[0].Property)
+		assert.Equal(t, 1002, stats.LargestProperties[0].Size)
+	}
+	if assert.Len(t, stats.Updates, 2) {
+		assert.Nil(t, stats.Updates[0].Duration)
+		assert.Equal(t, int64(30), *stats.Updates[1].Duration)
+	}
+	assert.Equal(t, int64(30), stats.AverageUpdateDuration)
+
+	stats, err = computeStackStats(nil, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Resources)
+	assert.Empty(t, stats.ResourcesByType)
+	assert.Empty(t, stats.Updates)
+}
@@ -17,6 +17,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/pulumi/pulumi/pkg/util/contract"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/edit"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/spf13/cobra"
@@ -46,6 +48,7 @@ troubleshooting a stack or when performing specific edits that otherwise would r
 	}
 
 	cmd.AddCommand(newStateDeleteCommand())
+	cmd.AddCommand(newStatePendingCommand())
 	cmd.AddCommand(newStateUnprotectCommand())
 	return cmd
 }
@@ -173,3 +176,13 @@ func runTotalStateEdit(stackName string, operation func(opts display.Options, sn
 	}
 	return s.ImportDeployment(commandContext(), &dep)
 }
+
+// newStatePluginContext creates a plugin context for commands that need to talk to the providers of the resources in
+// a stack's state. The caller is responsible for closing it.
+func newStatePluginContext() (*plugin.Context, error) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), nil, nil, nil, pwd, nil, nil)
+}
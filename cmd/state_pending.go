@@ -0,0 +1,225 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/edit"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+func newStatePendingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pending",
+		Short: "Manage resources that are pending deletion",
+		Long: `Manage resources that are pending deletion
+
+When a resource is replaced, the old resource is deleted after its replacement has been created. If that deletion
+fails, the old resource remains in the stack's state, marked as pending deletion, and Pulumi will try to delete it
+again during the next update. Subcommands of this command can be used to list such resources, to retry their deletion
+immediately, or to forget about them without deleting them.`,
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newStatePendingLsCommand())
+	cmd.AddCommand(newStatePendingRetryCommand())
+	cmd.AddCommand(newStatePendingForgetCommand())
+	return cmd
+}
+
+func newStatePendingLsCommand() *cobra.Command {
+	var stack string
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the resources in a stack's state that are pending deletion",
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+
+			var pending []*resource.State
+			if snap != nil {
+				pending = edit.PendingDeletions(snap)
+			}
+			if len(pending) == 0 {
+				fmt.Println("No resources are pending deletion")
+				return nil
+			}
+
+			var rows []cmdutil.TableRow
+			for _, res := range pending {
+				rows = append(rows, cmdutil.TableRow{
+					Columns: []string{string(res.URN), string(res.ID)},
+				})
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"URN", "ID"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	return cmd
+}
+
+func newStatePendingRetryCommand() *cobra.Command {
+	var id string
+	var stack string
+
+	cmd := &cobra.Command{
+		Use:   "retry <resource URN>",
+		Short: "Retry the deletion of resources that are pending deletion",
+		Long: `Retry the deletion of resources that are pending deletion
+
+This command asks the provider of each resource with the given URN that is pending deletion to delete it, and
+removes the resources that were deleted successfully from the stack's state. Use --id to select a single resource if
+several resources with the same URN are pending deletion.`,
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			urn := resource.URN(args[0])
+			var deleted int
+			err := runTotalStateEdit(stack, func(opts display.Options, snap *deploy.Snapshot) error {
+				condemned, err := locatePendingDeletions(snap, urn, resource.ID(id))
+				if err != nil {
+					return err
+				}
+
+				plugctx, err := newStatePluginContext()
+				if err != nil {
+					return err
+				}
+				defer contract.IgnoreClose(plugctx)
+
+				failures, err := edit.RetryPendingDeletions(plugctx.Host, snap, condemned)
+				if err != nil {
+					return err
+				}
+				for _, res := range condemned {
+					if failure, failed := failures[res]; failed {
+						fmt.Println(opts.Color.Colorize(fmt.Sprintf("%serror%s: deleting %s (%s): %v",
+							colors.SpecError, colors.Reset, res.URN, res.ID, failure)))
+					}
+				}
+				deleted = len(condemned) - len(failures)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Deleted %d resource(s)\n", deleted)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&id, "id", "", "The ID of the resource to delete, if several resources with the URN are pending deletion")
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	return cmd
+}
+
+func newStatePendingForgetCommand() *cobra.Command {
+	var id string
+	var stack string
+
+	cmd := &cobra.Command{
+		Use:   "forget <resource URN>",
+		Short: "Remove resources that are pending deletion from a stack's state without deleting them",
+		Long: `Remove resources that are pending deletion from a stack's state without deleting them
+
+This command removes each resource with the given URN that is pending deletion from the stack's state. The resources
+themselves are not deleted; use this command when they have already been deleted by other means, or when they should
+be cleaned up by hand. Use --id to select a single resource if several resources with the same URN are pending
+deletion.`,
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			urn := resource.URN(args[0])
+			var forgotten int
+			err := runTotalStateEdit(stack, func(opts display.Options, snap *deploy.Snapshot) error {
+				condemned, err := locatePendingDeletions(snap, urn, resource.ID(id))
+				if err != nil {
+					return err
+				}
+				for _, res := range condemned {
+					if err = edit.ForgetPendingDeletion(snap, res); err != nil {
+						return err
+					}
+				}
+				forgotten = len(condemned)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Removed %d resource(s) from the stack's state\n", forgotten)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&id, "id", "", "The ID of the resource to remove, if several resources with the URN are pending deletion")
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	return cmd
+}
+
+// locatePendingDeletions returns the resources in the snapshot that are pending deletion and have the given URN and,
+// if it is non-empty, the given ID.
+func locatePendingDeletions(snap *deploy.Snapshot, urn resource.URN, id resource.ID) ([]*resource.State, error) {
+	if snap == nil {
+		return nil, errors.Errorf("No resource %q is pending deletion", urn)
+	}
+
+	var pending []*resource.State
+	for _, res := range edit.PendingDeletions(snap) {
+		if res.URN == urn && (id == "" || res.ID == id) {
+			pending = append(pending, res)
+		}
+	}
+	if len(pending) == 0 {
+		if id != "" {
+			return nil, errors.Errorf("No resource %q with ID %q is pending deletion", urn, id)
+		}
+		return nil, errors.Errorf("No resource %q is pending deletion", urn)
+	}
+	return pending, nil
+}
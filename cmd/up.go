@@ -27,11 +27,13 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/result"
@@ -45,6 +47,7 @@ const (
 // nolint: vetshadow, intentionally disabling here for cleaner err declaration/assignment.
 func newUpCmd() *cobra.Command {
 	var debug bool
+	var eventLog string
 	var expectNop bool
 	var message string
 	var stack string
@@ -52,12 +55,21 @@ func newUpCmd() *cobra.Command {
 
 	// Flags for engine.UpdateOptions.
 	var analyzers []string
+	var continueOnError bool
 	var diffDisplay bool
+	var jsonDisplay bool
+	var progressFormat string
+	var excludes []string
+	var mockProviders string
 	var parallel int
+	var planFile string
 	var refresh bool
+	var replaces []string
+	var resume bool
 	var showConfig bool
 	var showReplacementSteps bool
 	var showSames bool
+	var showTimings bool
 	var skipPreview bool
 	var suppressOutputs bool
 	var yes bool
@@ -69,6 +81,16 @@ func newUpCmd() *cobra.Command {
 			return result.FromError(err)
 		}
 
+		if resume {
+			updates, err := s.Backend().GetHistory(commandContext(), s.Ref())
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "getting history"))
+			}
+			if err = checkResumable(updates); err != nil {
+				return result.FromError(err)
+			}
+		}
+
 		// Save any config values passed via flags.
 		if len(configArray) > 0 {
 			commandLineConfig, err := parseConfig(configArray)
@@ -91,19 +113,51 @@ func newUpCmd() *cobra.Command {
 			return result.FromError(errors.Wrap(err, "gathering environment metadata"))
 		}
 
+		suppressDiffs, err := stackDiffSuppressions(s)
+		if err != nil {
+			return result.FromError(err)
+		}
+
 		opts.Engine = engine.UpdateOptions{
-			Analyzers: analyzers,
-			Parallel:  parallel,
-			Debug:     debug,
-			Refresh:   refresh,
-		}
-
-		changes, err := s.Update(commandContext(), backend.UpdateOperation{
-			Proj:   proj,
-			Root:   root,
-			M:      m,
-			Opts:   opts,
-			Scopes: cancellationScopes,
+			Analyzers:       analyzers,
+			Parallel:        parallel,
+			Debug:           debug,
+			Refresh:         refresh,
+			ReplaceTargets:  targetURNs(replaces),
+			ExcludeTargets:  targetURNs(excludes),
+			ContinueOnError: continueOnError,
+			Resume:          resume,
+			SuppressDiffs:   suppressDiffs,
+		}
+		if len(proj.Rollout) > 0 && opts.Display.IsJSON() {
+			return result.Errorf("--json and --progress-format=json may not be used with a project that declares a " +
+				"staged rollout")
+		}
+		if planFile != "" {
+			if len(proj.Rollout) > 0 {
+				return result.Errorf("--plan may not be used with a project that declares a staged rollout")
+			}
+			if opts.Engine.FollowPlan, err = readPlan(planFile); err != nil {
+				return result.FromError(err)
+			}
+		}
+		if mockProviders != "" {
+			if opts.Engine.MockProviders, err = plugin.LoadMockFixtures(mockProviders); err != nil {
+				return result.FromError(err)
+			}
+		}
+
+		changes, err := updateWithHooks(proj, root, s, hookOutput(opts), func() (engine.ResourceChanges, error) {
+			return updateInPhases(commandContext(), proj, root, s.Ref().Name(), opts,
+				func(opts backend.UpdateOptions) (engine.ResourceChanges, error) {
+					return s.Update(commandContext(), backend.UpdateOperation{
+						Proj:   proj,
+						Root:   root,
+						M:      m,
+						Opts:   opts,
+						Scopes: cancellationScopes,
+					})
+				})
 		})
 		switch {
 		case err == context.Canceled:
@@ -120,7 +174,7 @@ func newUpCmd() *cobra.Command {
 	// up implementation used when the source of the Pulumi program is a template name or a URL to a template.
 	upTemplateNameOrURL := func(templateNameOrURL string, opts backend.UpdateOptions) *result.Result {
 		// Retrieve the template repo.
-		repo, err := workspace.RetrieveTemplates(templateNameOrURL, false)
+		repo, err := workspace.RetrieveTemplates(templateNameOrURL, cmdutil.Offline)
 		if err != nil {
 			return result.FromError(err)
 		}
@@ -231,10 +285,12 @@ func newUpCmd() *cobra.Command {
 		}
 
 		opts.Engine = engine.UpdateOptions{
-			Analyzers: analyzers,
-			Parallel:  parallel,
-			Debug:     debug,
-			Refresh:   refresh,
+			Analyzers:       analyzers,
+			Parallel:        parallel,
+			Debug:           debug,
+			Refresh:         refresh,
+			ReplaceTargets:  targetURNs(replaces),
+			ContinueOnError: continueOnError,
 		}
 
 		// TODO for the URL case:
@@ -242,12 +298,14 @@ func newUpCmd() *cobra.Command {
 		// - attempt `destroy` on any update errors.
 		// - show template.Quickstart?
 
-		changes, err := s.Update(commandContext(), backend.UpdateOperation{
-			Proj:   proj,
-			Root:   root,
-			M:      m,
-			Opts:   opts,
-			Scopes: cancellationScopes,
+		changes, err := updateWithHooks(proj, root, s, hookOutput(opts), func() (engine.ResourceChanges, error) {
+			return s.Update(commandContext(), backend.UpdateOperation{
+				Proj:   proj,
+				Root:   root,
+				M:      m,
+				Opts:   opts,
+				Scopes: cancellationScopes,
+			})
 		})
 		switch {
 		case err == context.Canceled:
@@ -276,13 +334,25 @@ func newUpCmd() *cobra.Command {
 			"afterwards so that the stack may be updated incrementally again later on.\n" +
 			"\n" +
 			"The program to run is loaded from the project in the current directory by default. Use the `-C` or\n" +
-			"`--cwd` flag to use a different directory.",
+			"`--cwd` flag to use a different directory.\n" +
+			"\n" +
+			"The update is previewed first, and you are asked to confirm it. When running non-interactively,\n" +
+			"--yes must be passed to approve the update, unless --skip-preview is passed.\n" +
+			"\n" +
+			"Commands listed under `hooks` in Pulumi.yaml run around the update: `preUpdate` commands before it,\n" +
+			"and `postUpdate` or `onFailure` commands after it, depending on its outcome. They run from the\n" +
+			"project directory with PULUMI_PROJECT, PULUMI_STACK and PULUMI_HOOK set in their environment.\n" +
+			"\n" +
+			"If the stack's last update failed part way through, use `--resume` to pick up where it left off:\n" +
+			"resources that the failed update already brought up to date are not checked or diffed again, so\n" +
+			"any changes made to them in the program since then are not applied until the next update.\n" +
+			"\n" +
+			"If Pulumi.yaml declares a staged `rollout`, the update runs once per phase, in order. Each phase\n" +
+			"changes only the existing resources whose names match its patterns (new resources are created in\n" +
+			"the first phase), then runs its `verify` commands and waits for its `pause` before the next begins.",
 		Args: cmdutil.MaximumNArgs(1),
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) *result.Result {
 			interactive := cmdutil.Interactive()
-			if !interactive {
-				yes = true // auto-approve changes, since we cannot prompt.
-			}
 
 			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
 			if err != nil {
@@ -294,13 +364,35 @@ func newUpCmd() *cobra.Command {
 				ShowConfig:           showConfig,
 				ShowReplacementSteps: showReplacementSteps,
 				ShowSameResources:    showSames,
+				ShowTimings:          showTimings,
 				SuppressOutputs:      suppressOutputs,
 				IsInteractive:        interactive,
 				DiffDisplay:          diffDisplay,
 				Debug:                debug,
+				EventLogPath:         eventLog,
+			}
+			if err = jsonFlagsToOptions(&opts, jsonDisplay); err != nil {
+				return result.FromError(err)
+			}
+			if err = progressFormatToOptions(&opts, progressFormat, false /*isPreview*/); err != nil {
+				return result.FromError(err)
+			}
+
+			if err := resetEventLog(eventLog); err != nil {
+				return result.FromError(err)
+			}
+
+			if resume && refresh {
+				return result.Error("--resume cannot be combined with --refresh")
 			}
 
 			if len(args) > 0 {
+				if planFile != "" {
+					return result.Error("--plan cannot be used when updating from a template")
+				}
+				if resume {
+					return result.Error("--resume cannot be used when updating from a template")
+				}
 				return upTemplateNameOrURL(args[0], opts)
 			}
 
@@ -311,6 +403,9 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVar(
+		&eventLog, "event-log", "",
+		"Log every engine event to the given file as newline-delimited JSON")
 	cmd.PersistentFlags().BoolVar(
 		&expectNop, "expect-no-changes", false,
 		"Return an error if any changes occur during this update")
@@ -332,15 +427,45 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().StringSliceVar(
 		&analyzers, "analyzer", []string{},
 		"Run one or more analyzers as part of this update")
+	cmd.PersistentFlags().BoolVar(
+		&continueOnError, "continue-on-error", false,
+		"Continue to update resources that do not depend on a resource that failed to update, rather than stopping "+
+			"at the first failure")
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().StringSliceVar(
+		&excludes, "exclude", []string{},
+		"Specify a single resource URN to leave unchanged, along with any resources that depend on it. "+
+			"Multiple resources can be specified using --exclude urn1 --exclude urn2")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonDisplay, "json", "j", false,
+		"Serialize the update's operations and overall output as JSON. Requires --yes, and skips the preview")
+	cmd.PersistentFlags().StringVar(
+		&progressFormat, "progress-format", "text",
+		"Display progress as 'text', or as 'json': newline-delimited JSON events on stdout, "+
+			"for other programs to render")
+	cmd.PersistentFlags().StringVar(
+		&mockProviders, "mock-providers", "",
+		"Replace every resource provider with a mock that manages no real resources, and that responds with the IDs "+
+			"and outputs in the given JSON fixtures file. Only use this with a stack that exists for testing")
 	cmd.PersistentFlags().IntVarP(
 		&parallel, "parallel", "p", defaultParallel,
 		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
+	cmd.PersistentFlags().StringVar(
+		&planFile, "plan", "",
+		"Apply exactly the plan saved to the given file by `pulumi preview --save-plan`, failing if the program or "+
+			"the stack's resources have since changed such that different steps would be taken")
 	cmd.PersistentFlags().BoolVarP(
 		&refresh, "refresh", "r", false,
 		"Refresh the state of the stack's resources before this update")
+	cmd.PersistentFlags().StringArrayVar(
+		&replaces, "replace", []string{},
+		"Specify a single resource URN to replace, even if its diff does not require it. "+
+			"Multiple resources can be specified using --replace urn1 --replace urn2")
+	cmd.PersistentFlags().BoolVar(
+		&resume, "resume", false,
+		"Resume the stack's last update, which must have failed, skipping the resources it already completed")
 	cmd.PersistentFlags().BoolVar(
 		&showConfig, "show-config", false,
 		"Show configuration keys and variables")
@@ -350,6 +475,10 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&showSames, "show-sames", false,
 		"Show resources that don't need be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&showTimings, "show-timings", false,
+		"Show the slowest resources, the critical path of resources that determined how long the update took, and "+
+			"the number and latency of the calls made to each provider")
 	cmd.PersistentFlags().BoolVar(
 		&skipPreview, "skip-preview", false,
 		"Do not perform a preview before performing the update")
@@ -363,6 +492,19 @@ func newUpCmd() *cobra.Command {
 	return cmd
 }
 
+// checkResumable returns an error unless the most recent update in a stack's history, given in descending order, is
+// a failed update that `pulumi up --resume` can pick up from.
+func checkResumable(updates []backend.UpdateInfo) error {
+	if len(updates) == 0 {
+		return errors.New("the stack has no updates to resume")
+	}
+	if last := updates[0]; last.Kind != apitype.UpdateUpdate || last.Result != backend.FailedResult {
+		return errors.Errorf("the stack's last operation was a %s that %s; only failed updates can be resumed",
+			last.Kind, last.Result)
+	}
+	return nil
+}
+
 // handleConfig handles prompting for config values (as needed) and saving config.
 func handleConfig(
 	s backend.Stack,
@@ -0,0 +1,47 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+)
+
+func TestCheckResumable(t *testing.T) {
+	update := func(kind apitype.UpdateKind, result backend.UpdateResult) backend.UpdateInfo {
+		return backend.UpdateInfo{Kind: kind, Result: result}
+	}
+
+	assert.NoError(t, checkResumable([]backend.UpdateInfo{
+		update(apitype.UpdateUpdate, backend.FailedResult),
+		update(apitype.UpdateUpdate, backend.SucceededResult),
+	}))
+
+	// Only the most recent update is considered.
+	assert.Error(t, checkResumable([]backend.UpdateInfo{
+		update(apitype.UpdateUpdate, backend.SucceededResult),
+		update(apitype.UpdateUpdate, backend.FailedResult),
+	}))
+
+	// Failed refreshes and destroys cannot be resumed.
+	assert.Error(t, checkResumable([]backend.UpdateInfo{update(apitype.RefreshUpdate, backend.FailedResult)}))
+	assert.Error(t, checkResumable([]backend.UpdateInfo{update(apitype.DestroyUpdate, backend.FailedResult)}))
+
+	assert.Error(t, checkResumable(nil))
+}
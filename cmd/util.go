@@ -19,15 +19,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
+	"os/user"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/golang/glog"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
@@ -35,6 +36,7 @@ import (
 	surveycore "gopkg.in/AlecAivazis/survey.v1/core"
 	git "gopkg.in/src-d/go-git.v4"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
 	"github.com/pulumi/pulumi/pkg/backend/filestate"
@@ -42,11 +44,17 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend/state"
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cancel"
 	"github.com/pulumi/pulumi/pkg/util/ciutil"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/util/gitutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -54,17 +62,77 @@ func hasDebugCommands() bool {
 	return cmdutil.IsTruthy(os.Getenv("PULUMI_DEBUG_COMMANDS"))
 }
 
+// backendURL is the URL of the backend to use in place of the current one, set by the --backend flag.
+var backendURL string
+
+// currentBackend returns the backend to use: the one named by --backend if given, else the one named by the current
+// project, if any, or else the current login.
 func currentBackend(opts display.Options) (backend.Backend, error) {
+	// If --backend was passed or the project names a backend, use that backend, leaving the current login untouched.
+	if url := defaultBackendURL(); url != "" {
+		if err := checkOfflineBackend(url); err != nil {
+			return nil, err
+		}
+		return overrideBackend(url)
+	}
+
 	creds, err := workspace.GetStoredCredentials()
 	if err != nil {
 		return nil, err
 	}
+	if err = checkOfflineBackend(creds.Current); err != nil {
+		return nil, err
+	}
 	if filestate.IsLocalBackendURL(creds.Current) {
 		return filestate.New(cmdutil.Diag(), creds.Current, stackConfigFile)
 	}
 	return httpstate.Login(commandContext(), cmdutil.Diag(), creds.Current, stackConfigFile, opts)
 }
 
+// defaultBackendURL returns the URL of the backend named by --backend or, failing that, by the current project's
+// Pulumi.yaml. If neither names a backend, the result is empty.
+func defaultBackendURL() string {
+	if backendURL != "" {
+		return backendURL
+	}
+	if proj, err := workspace.DetectProject(); err == nil && proj.Backend != nil {
+		return proj.Backend.URL
+	}
+	return ""
+}
+
+// overrideBackend returns the backend for the given URL without changing which backend is current. Because doing so
+// must not prompt for credentials, a Pulumi service backend may only be used if it has been logged into before or if
+// PULUMI_ACCESS_TOKEN is set.
+func overrideBackend(url string) (backend.Backend, error) {
+	if filestate.IsLocalBackendURL(url) {
+		return filestate.New(cmdutil.Diag(), url, stackConfigFile)
+	}
+
+	url = httpstate.ValueOrDefaultURL(url)
+	token, err := workspace.GetAccessToken(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting stored credentials")
+	}
+	if token == "" && os.Getenv(httpstate.AccessTokenEnvVar) == "" {
+		return nil, errutil.WithCode(errutil.ErrAuthNotLoggedIn, "",
+			errors.Errorf("not logged into %s; run `pulumi login %s` first", url, url))
+	}
+	return httpstate.New(cmdutil.Diag(), url, stackConfigFile)
+}
+
+// checkOfflineBackend returns an error if --offline is set and the backend at the given URL is not on the local
+// filesystem, rather than letting the backend's first request hang or time out.
+func checkOfflineBackend(url string) error {
+	if cmdutil.Offline && !filestate.IsFileBackendURL(url) {
+		if !filestate.IsLocalBackendURL(url) {
+			url = httpstate.ValueOrDefaultURL(url)
+		}
+		return cmdutil.OfflineError("using the backend at %s", url)
+	}
+	return nil
+}
+
 // This is used to control the contents of the tracing header.
 var tracingHeader = os.Getenv("PULUMI_TRACING_HEADER")
 
@@ -145,7 +213,40 @@ func requireStack(
 		return createStack(b, stackRef, nil, setCurrent)
 	}
 
-	return nil, errors.Errorf("no stack named '%s' found", stackName)
+	return nil, stackNotFoundError(b, stackName)
+}
+
+// listStackNames returns the sorted names of the given backend's stacks, limited to those of the current project if
+// there is one.
+func listStackNames(b backend.Backend) ([]string, error) {
+	var packageFilter *tokens.PackageName
+	if proj, err := workspace.DetectProject(); err == nil {
+		packageFilter = &proj.Name
+	}
+
+	summaries, err := b.ListStacks(commandContext(), packageFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, summary := range summaries {
+		names = append(names, summary.Name().String())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// stackNotFoundError returns the error reported when the given backend has no stack with the given name, suggesting a
+// similarly named stack if there is one.
+func stackNotFoundError(b backend.Backend, stackName string) error {
+	hint := fmt.Sprintf("run `pulumi stack ls` to list the stacks, or `pulumi stack init %s` to create it", stackName)
+	if names, err := listStackNames(b); err == nil {
+		if suggestion := errutil.DidYouMean(stackName, names); suggestion != "" {
+			hint = suggestion
+		}
+	}
+	return errutil.WithCode(errutil.ErrStackNotFound, hint, errors.Errorf("no stack named '%s' found", stackName))
 }
 
 func requireCurrentStack(offerNew bool, opts display.Options, setCurrent bool) (backend.Stack, error) {
@@ -343,14 +444,27 @@ func getUpdateMetadata(msg, root string) (*backend.UpdateMetadata, error) {
 	}
 
 	if err := addGitMetadata(root, m); err != nil {
-		glog.V(3).Infof("errors detecting git metadata: %s", err)
+		logging.V(3).Infof("errors detecting git metadata: %s", err)
 	}
 
 	addCIMetadataToEnvironment(m.Environment)
+	addExecMetadataToEnvironment(m.Environment)
 
 	return m, nil
 }
 
+// addExecMetadataToEnvironment populates the environment metadata bag with values that describe this process, so that
+// anyone who finds the update in progress can tell who is running it and where.
+func addExecMetadataToEnvironment(env map[string]string) {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		env[backend.ExecUser] = u.Username
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		env[backend.ExecHostname] = hostname
+	}
+	env[backend.ExecPID] = strconv.Itoa(os.Getpid())
+}
+
 // addGitMetadata populate's the environment metadata bag with Git-related values.
 func addGitMetadata(repoRoot string, m *backend.UpdateMetadata) error {
 	var allErrors *multierror.Error
@@ -588,22 +702,51 @@ func (cancellationScopeSource) NewScope(events chan<- engine.Event, isPreview bo
 	return c
 }
 
+// confirmationDeclined returns the error for an action that the user did not confirm. When running non-interactively,
+// there was no one to ask, so the error explains how to confirm the action up front instead.
+func confirmationDeclined() error {
+	if !cmdutil.Interactive() {
+		return errNonInteractiveConfirmation
+	}
+	return errors.New("confirmation declined")
+}
+
+// stackDiffSuppressions returns the diff suppressions declared in the given stack's settings file.
+func stackDiffSuppressions(s backend.Stack) ([]workspace.ProjectDiffSuppression, error) {
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading stack settings")
+	}
+	return ps.SuppressDiffs, nil
+}
+
 // printJSON simply prints out some object, formatted as JSON, using standard indentation.
 func printJSON(v interface{}) error {
+	return fprintJSON(os.Stdout, v)
+}
+
+// fprintJSON writes some object to w, formatted as JSON, using standard indentation.
+func fprintJSON(w io.Writer, v interface{}) error {
 	out, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(out))
+	fmt.Fprintln(w, string(out))
 	return nil
 }
 
+// errNonInteractiveConfirmation is returned when a command needs the user to confirm an action but cannot prompt for
+// it, because it is running non-interactively.
+var errNonInteractiveConfirmation = errors.New(
+	"confirmation is required but cannot be prompted for in non-interactive mode; pass --yes to proceed")
+
 // updateFlagsToOptions ensures that the given update flags represent a valid combination.  If so, an UpdateOptions
 // is returned with a nil-error; otherwise, the non-nil error contains information about why the combination is invalid.
 func updateFlagsToOptions(interactive, skipPreview, yes bool) (backend.UpdateOptions, error) {
-	if !interactive && !yes {
-		return backend.UpdateOptions{},
-			errors.New("--yes must be passed in non-interactive mode")
+	// Changes are confirmed after their preview. Without a terminal to prompt on, they must be approved up front,
+	// unless the preview, and so its confirmation, is being skipped.
+	if !interactive && !yes && !skipPreview {
+		return backend.UpdateOptions{}, errNonInteractiveConfirmation
 	}
 
 	return backend.UpdateOptions{
@@ -611,3 +754,99 @@ func updateFlagsToOptions(interactive, skipPreview, yes bool) (backend.UpdateOpt
 		SkipPreview: skipPreview,
 	}, nil
 }
+
+// errJSONConfirmation is returned when an update, refresh, or destroy is asked for JSON output without being approved
+// up front, since there is no way to prompt for confirmation without corrupting the JSON document.
+var errJSONConfirmation = errors.New("--yes must be passed to proceed when --json is passed")
+
+// jsonFlagsToOptions adjusts the options of an update, refresh, or destroy whose result is to be emitted as JSON. The
+// result is emitted as a single JSON document, so the operation must be approved up front, and its preview, which
+// would otherwise be displayed first, is skipped.
+func jsonFlagsToOptions(opts *backend.UpdateOptions, jsonDisplay bool) error {
+	if !jsonDisplay {
+		return nil
+	}
+	if !opts.AutoApprove {
+		return errJSONConfirmation
+	}
+	opts.SkipPreview = true
+	opts.Display.JSONDisplay = true
+	return nil
+}
+
+var errProgressConfirmation = errors.New("--yes must be passed to proceed when --progress-format=json is passed")
+
+// progressFormatToOptions applies the --progress-format flag to the display options of an operation. JSON progress is
+// written to stdout as the operation runs, so it cannot be combined with --json, and an update, refresh, or destroy
+// that shows it must be approved up front rather than by answering a prompt.
+func progressFormatToOptions(opts *backend.UpdateOptions, format string, isPreview bool) error {
+	switch format {
+	case "", "text":
+		return nil
+	case "json":
+		if opts.Display.JSONDisplay {
+			return errors.New("--progress-format=json cannot be combined with --json")
+		}
+		if !isPreview && !opts.AutoApprove && !opts.SkipPreview {
+			return errProgressConfirmation
+		}
+		opts.Display.JSONProgress, opts.Display.IsInteractive = true, false
+		return nil
+	default:
+		return errors.Errorf("unsupported progress format '%s'; expected 'text' or 'json'", format)
+	}
+}
+
+// resetEventLog creates or empties the event log at the given path, if any, so that it records only the events of the
+// current command. The display appends each operation's events to it.
+func resetEventLog(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "creating event log")
+	}
+	return f.Close()
+}
+
+// targetURNs converts the values passed to a --replace, --target, or --exclude flag into the URNs of the targeted
+// resources.
+func targetURNs(values []string) []resource.URN {
+	var urns []resource.URN
+	for _, r := range values {
+		urns = append(urns, resource.URN(r))
+	}
+	return urns
+}
+
+// readPlan reads a plan saved by `pulumi preview --save-plan` from the given file.
+func readPlan(path string) (*deploy.SavedPlan, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open plan file")
+	}
+	defer contract.IgnoreClose(f)
+
+	var plan apitype.PlanV1
+	if err = json.NewDecoder(f).Decode(&plan); err != nil {
+		return nil, errors.Wrap(err, "could not read plan file")
+	}
+	return stack.DeserializePlan(plan)
+}
+
+// writePlan writes the given plan to the given file, so that it can later be applied with `pulumi up --plan`.
+func writePlan(path string, plan *deploy.SavedPlan) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "could not create plan file")
+	}
+	defer contract.IgnoreClose(f)
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "    ")
+	if err = enc.Encode(stack.SerializePlan(plan)); err != nil {
+		return errors.Wrap(err, "could not write plan file")
+	}
+	return nil
+}
@@ -18,7 +18,10 @@ import (
 	"testing"
 
 	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
 	pul_testing "github.com/pulumi/pulumi/pkg/testing"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/gitutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -226,3 +229,83 @@ func TestReadingGitLabMetadata(t *testing.T) {
 		assertEnvValue(t, test, backend.VCSRepoKind, gitutil.GitLabHostName)
 	}
 }
+
+func TestUpdateFlagsToOptions(t *testing.T) {
+	// Interactive sessions can always prompt for confirmation.
+	opts, err := updateFlagsToOptions(true /*interactive*/, false /*skipPreview*/, false /*yes*/)
+	assert.NoError(t, err)
+	assert.False(t, opts.AutoApprove)
+
+	// Non-interactive sessions must approve previewed changes up front...
+	_, err = updateFlagsToOptions(false /*interactive*/, false /*skipPreview*/, false /*yes*/)
+	assert.Equal(t, errNonInteractiveConfirmation, err)
+
+	opts, err = updateFlagsToOptions(false /*interactive*/, false /*skipPreview*/, true /*yes*/)
+	assert.NoError(t, err)
+	assert.True(t, opts.AutoApprove)
+
+	// ...unless there is no preview to confirm.
+	opts, err = updateFlagsToOptions(false /*interactive*/, true /*skipPreview*/, false /*yes*/)
+	assert.NoError(t, err)
+	assert.True(t, opts.SkipPreview)
+}
+
+func TestJSONFlagsToOptions(t *testing.T) {
+	// Without --json, the options are left alone.
+	opts := backend.UpdateOptions{}
+	assert.NoError(t, jsonFlagsToOptions(&opts, false))
+	assert.Equal(t, backend.UpdateOptions{}, opts)
+
+	// With --json, the operation must be approved up front, and its preview is skipped.
+	assert.Equal(t, errJSONConfirmation, jsonFlagsToOptions(&opts, true))
+	opts.AutoApprove = true
+	assert.NoError(t, jsonFlagsToOptions(&opts, true))
+	assert.True(t, opts.SkipPreview)
+	assert.True(t, opts.Display.JSONDisplay)
+}
+
+func TestProgressFormatToOptions(t *testing.T) {
+	// Text progress leaves the options alone.
+	opts := backend.UpdateOptions{Display: display.Options{IsInteractive: true}}
+	assert.NoError(t, progressFormatToOptions(&opts, "text", false /*isPreview*/))
+	assert.Equal(t, backend.UpdateOptions{Display: display.Options{IsInteractive: true}}, opts)
+	assert.Error(t, progressFormatToOptions(&opts, "xml", false /*isPreview*/))
+
+	// JSON progress is never interactive, so updates must be approved up front; previews need no approval.
+	assert.Equal(t, errProgressConfirmation, progressFormatToOptions(&opts, "json", false /*isPreview*/))
+	assert.NoError(t, progressFormatToOptions(&opts, "json", true /*isPreview*/))
+	assert.True(t, opts.Display.JSONProgress)
+	assert.False(t, opts.Display.IsInteractive)
+	opts = backend.UpdateOptions{AutoApprove: true}
+	assert.NoError(t, progressFormatToOptions(&opts, "json", false /*isPreview*/))
+	assert.True(t, opts.Display.JSONProgress)
+
+	// It cannot be combined with --json.
+	opts = backend.UpdateOptions{AutoApprove: true, Display: display.Options{JSONDisplay: true}}
+	assert.Error(t, progressFormatToOptions(&opts, "json", false /*isPreview*/))
+}
+
+func TestCheckOfflineBackend(t *testing.T) {
+	defer func() { cmdutil.Offline = false }()
+
+	// Any backend may be used when online.
+	assert.NoError(t, checkOfflineBackend(""))
+	assert.NoError(t, checkOfflineBackend("gs://state"))
+
+	// Offline, only backends on the local filesystem may be.
+	cmdutil.Offline = true
+	assert.NoError(t, checkOfflineBackend("file://~"))
+	assert.NoError(t, checkOfflineBackend("file:///tmp/state"))
+	for _, url := range []string{"https://api.acmecorp.com", "azblob://state", "gs://state", "rest+https://state"} {
+		err := checkOfflineBackend(url)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), url)
+		}
+	}
+
+	// The service is reported by its URL, even when it is the default.
+	err := checkOfflineBackend("")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), httpstate.DefaultURL())
+	}
+}
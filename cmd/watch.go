@@ -0,0 +1,215 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/fsutil"
+	"github.com/pulumi/pulumi/pkg/util/result"
+)
+
+const (
+	// watchPollInterval is how often the watched files are checked for changes.
+	watchPollInterval = 500 * time.Millisecond
+	// defaultWatchDebounce is how long the watched files must go unchanged before an update is started.
+	defaultWatchDebounce = time.Second
+)
+
+// watchSkipDirs are directories that are never watched, as they are either not part of the program's source or are
+// routinely written to by tools.
+var watchSkipDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	".pulumi":      true,
+	"node_modules": true,
+	"__pycache__":  true,
+	"venv":         true,
+}
+
+// nolint: vetshadow, intentionally disabling here for cleaner err declaration/assignment.
+func newWatchCmd() *cobra.Command {
+	var debounce time.Duration
+	var debug bool
+	var message string
+	var paths []string
+	var stack string
+
+	// Flags for engine.UpdateOptions.
+	var analyzers []string
+	var parallel int
+	var refresh bool
+	var showConfig bool
+	var showReplacementSteps bool
+	var showSames bool
+	var suppressOutputs bool
+
+	var cmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Continuously update the resources in a stack as the program changes",
+		Long: "Continuously update the resources in a stack as the program changes.\n" +
+			"\n" +
+			"This command performs an update and then watches the program's source files. Whenever they change, the\n" +
+			"program is run again and any resulting changes are deployed automatically, without a preview or a\n" +
+			"confirmation prompt. Edits made in quick succession are batched into a single update. Failed updates\n" +
+			"are reported, and the next change will trigger another attempt. Press ^C to stop watching.\n" +
+			"\n" +
+			"By default the project directory is watched, skipping version control and dependency directories.\n" +
+			"Use --path to watch other files or directories instead.\n" +
+			"\n" +
+			"This command is intended for fast iteration during development and should not be used against\n" +
+			"production stacks.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) *result.Result {
+			opts := backend.UpdateOptions{
+				AutoApprove: true,
+				SkipPreview: true,
+			}
+			opts.Display = display.Options{
+				Color:                cmdutil.GetGlobalColorization(),
+				ShowConfig:           showConfig,
+				ShowReplacementSteps: showReplacementSteps,
+				ShowSameResources:    showSames,
+				SuppressOutputs:      suppressOutputs,
+				IsInteractive:        cmdutil.Interactive(),
+				Debug:                debug,
+			}
+			opts.Engine = engine.UpdateOptions{
+				Analyzers: analyzers,
+				Parallel:  parallel,
+				Debug:     debug,
+				Refresh:   refresh,
+			}
+
+			s, err := requireStack(stack, true, opts.Display, true /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+			_, root, err := readProject()
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			if len(paths) == 0 {
+				paths = []string{root}
+			}
+			skipDir := func(path string) bool { return watchSkipDirs[filepath.Base(path)] }
+
+			files, err := fsutil.SnapshotFiles(paths, skipDir)
+			if err != nil {
+				return result.FromError(errors.Wrap(err, "reading watched files"))
+			}
+
+			ctx := commandContext()
+			for {
+				// The project may itself have been edited, so re-read it before every update.
+				proj, root, err := readProject()
+				if err != nil {
+					return result.FromError(err)
+				}
+				m, err := getUpdateMetadata(message, root)
+				if err != nil {
+					return result.FromError(errors.Wrap(err, "gathering environment metadata"))
+				}
+
+				_, err = s.Update(ctx, backend.UpdateOperation{
+					Proj:   proj,
+					Root:   root,
+					M:      m,
+					Opts:   opts,
+					Scopes: cancellationScopes,
+				})
+				switch {
+				case err == context.Canceled:
+					return result.FromError(errors.New("update cancelled"))
+				case err != nil:
+					// Report the failure, but keep watching: the next edit will likely fix it.
+					if res := PrintEngineError(err); res != nil && res.Error() != nil {
+						cmdutil.Diag().Errorf(diag.RawMessage("" /*urn*/, res.Error().Error()))
+					}
+				}
+
+				fmt.Println(opts.Display.Color.Colorize(colors.SpecInfo +
+					fmt.Sprintf("Watching for changes in %s...", strings.Join(paths, ", ")) + colors.Reset))
+
+				var changed []string
+				files, changed, err = fsutil.WaitForChanges(ctx, files, paths, skipDir, watchPollInterval, debounce)
+				if err != nil {
+					return result.FromError(errors.Wrap(err, "watching files"))
+				}
+				fmt.Println(opts.Display.Color.Colorize(colors.SpecInfo +
+					fmt.Sprintf("Detected changes in %d file(s); updating...", len(changed)) + colors.Reset))
+			}
+		}),
+	}
+
+	cmd.PersistentFlags().DurationVar(
+		&debounce, "debounce", defaultWatchDebounce,
+		"How long to wait for files to stop changing before starting an update")
+	cmd.PersistentFlags().BoolVarP(
+		&debug, "debug", "d", false,
+		"Print detailed debugging output during resource operations")
+	cmd.PersistentFlags().StringVarP(
+		&message, "message", "m", "",
+		"Optional message to associate with each update operation")
+	cmd.PersistentFlags().StringSliceVar(
+		&paths, "path", []string{},
+		"A file or directory to watch for changes. May be specified multiple times. Defaults to the project directory")
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().StringVar(
+		&stackConfigFile, "config-file", "",
+		"Use the configuration values in the specified file rather than detecting the file name")
+
+	// Flags for engine.UpdateOptions.
+	cmd.PersistentFlags().StringSliceVar(
+		&analyzers, "analyzer", []string{},
+		"Run one or more analyzers as part of each update")
+	cmd.PersistentFlags().IntVarP(
+		&parallel, "parallel", "p", defaultParallel,
+		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
+	cmd.PersistentFlags().BoolVarP(
+		&refresh, "refresh", "r", false,
+		"Refresh the state of the stack's resources before each update")
+	cmd.PersistentFlags().BoolVar(
+		&showConfig, "show-config", false,
+		"Show configuration keys and variables")
+	cmd.PersistentFlags().BoolVar(
+		&showReplacementSteps, "show-replacement-steps", false,
+		"Show detailed resource replacement creates and deletes instead of a single step")
+	cmd.PersistentFlags().BoolVar(
+		&showSames, "show-sames", false,
+		"Show resources that don't need be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&suppressOutputs, "suppress-outputs", false,
+		"Suppress display of stack outputs (in case they contain sensitive values)")
+
+	return cmd
+}
@@ -15,21 +15,40 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 )
 
+// whoAmIJSON is the shape of the --json output of `pulumi whoami`.
+type whoAmIJSON struct {
+	User          string   `json:"user"`
+	URL           string   `json:"url"`
+	Organizations []string `json:"organizations,omitempty"`
+}
+
 func newWhoAmICmd() *cobra.Command {
+	var jsonOut bool
+	var details bool
+
 	cmd := &cobra.Command{
 		Use:   "whoami",
 		Short: "Display the current logged-in user",
 		Long: "Display the current logged-in user\n" +
 			"\n" +
-			"Displays the username of the currently logged in user.",
+			"Displays the username of the currently logged in user. Pass --details to also display the URL of\n" +
+			"the backend and, for the Pulumi service, the organizations that the user is a member of, or --json\n" +
+			"to display all of these as JSON. This is useful for checking which account commands will operate\n" +
+			"on, such as in CI logs before running destructive commands.",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
@@ -41,15 +60,47 @@ func newWhoAmICmd() *cobra.Command {
 				return err
 			}
 
-			name, err := b.CurrentUser()
-			if err != nil {
-				return err
-			}
-
-			fmt.Println(name)
-			return nil
+			return printWhoAmI(commandContext(), os.Stdout, b, details, jsonOut)
 		}),
 	}
 
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+	cmd.PersistentFlags().BoolVar(
+		&details, "details", false, "Display the backend URL and organizations along with the user")
+
 	return cmd
 }
+
+// printWhoAmI writes the current user of the given backend to w. If details is true, the backend's URL and, for the
+// Pulumi service, the user's organizations are written as well; if jsonOut is true, all of these are written as JSON.
+func printWhoAmI(ctx context.Context, w io.Writer, b backend.Backend, details, jsonOut bool) error {
+	name, err := b.CurrentUser()
+	if err != nil {
+		return err
+	}
+
+	if !details && !jsonOut {
+		fmt.Fprintln(w, name)
+		return nil
+	}
+
+	result := whoAmIJSON{User: name, URL: b.URL()}
+	if httpBackend, ok := b.(httpstate.Backend); ok {
+		result.URL = httpBackend.CloudURL()
+		if result.Organizations, err = httpBackend.CurrentUserOrganizations(ctx); err != nil {
+			return err
+		}
+	}
+
+	if jsonOut {
+		return fprintJSON(w, result)
+	}
+
+	fmt.Fprintf(w, "User: %s\n", result.User)
+	if len(result.Organizations) > 0 {
+		fmt.Fprintf(w, "Organizations: %s\n", strings.Join(result.Organizations, ", "))
+	}
+	fmt.Fprintf(w, "Backend URL: %s\n", result.URL)
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/user"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// whoAmI returns what printWhoAmI writes for the given backend.
+func whoAmI(t *testing.T, b backend.Backend, details, jsonOut bool) string {
+	var out bytes.Buffer
+	assert.NoError(t, printWhoAmI(context.Background(), &out, b, details, jsonOut))
+	return out.String()
+}
+
+func TestWhoAmIService(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-whoami-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	os.Setenv(workspace.PulumiCredentialsPathEnvVar, dir)
+	defer os.Unsetenv(workspace.PulumiCredentialsPathEnvVar)
+	workspace.InsecureCredentialStorage = true
+	defer func() { workspace.InsecureCredentialStorage = false }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/user", req.URL.Path)
+		assert.NoError(t, json.NewEncoder(w).Encode(apitype.User{
+			GitHubLogin:   "alice",
+			Organizations: []apitype.OrganizationSummary{{GitHubLogin: "acme"}, {GitHubLogin: "initech"}},
+		}))
+	}))
+	defer server.Close()
+
+	b, err := httpstate.LoginWithToken(context.Background(), cmdutil.Diag(), server.URL, "", "secret")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "alice\n", whoAmI(t, b, false, false))
+	assert.Equal(t, "User: alice\nOrganizations: acme, initech\nBackend URL: "+server.URL+"\n",
+		whoAmI(t, b, true, false))
+
+	var result whoAmIJSON
+	assert.NoError(t, json.Unmarshal([]byte(whoAmI(t, b, false, true)), &result))
+	assert.Equal(t, whoAmIJSON{User: "alice", URL: server.URL, Organizations: []string{"acme", "initech"}}, result)
+}
+
+func TestWhoAmIFileBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-whoami-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	b, err := filestate.New(cmdutil.Diag(), "file://"+dir, "")
+	assert.NoError(t, err)
+	u, err := user.Current()
+	assert.NoError(t, err)
+
+	// Backends other than the service have no organizations.
+	assert.Equal(t, "User: "+u.Username+"\nBackend URL: file://"+dir+"\n", whoAmI(t, b, true, false))
+	assert.Equal(t, "{\n  \"user\": \""+u.Username+"\",\n  \"url\": \"file://"+dir+"\"\n}\n", whoAmI(t, b, false, true))
+}
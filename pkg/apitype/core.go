@@ -42,12 +42,18 @@ const (
 	// DeploymentSchemaVersionCurrent is the current version of the `Deployment` schema.
 	// Any deployments newer than this version will be rejected.
 	DeploymentSchemaVersionCurrent = 3
+
+	// PlanSchemaVersionCurrent is the current version of the `Plan` schema.
+	// Any plans newer than this version will be rejected.
+	PlanSchemaVersionCurrent = 1
 )
 
 // VersionedCheckpoint is a version number plus a json document. The version number describes what
-// version of the Checkpoint structure the Checkpoint member's json document can decode into.
+// version of the Checkpoint structure the Checkpoint member's json document can decode into. The serial
+// number is incremented each time the checkpoint is written, and is used to detect concurrent writers.
 type VersionedCheckpoint struct {
 	Version    int             `json:"version"`
+	Serial     int64           `json:"serial,omitempty"`
 	Checkpoint json.RawMessage `json:"checkpoint"`
 }
 
@@ -114,6 +120,9 @@ type DeploymentV3 struct {
 	Resources []ResourceV3 `json:"resources,omitempty" yaml:"resources,omitempty"`
 	// PendingOperations are all operations that were known by the engine to be currently executing.
 	PendingOperations []OperationV2 `json:"pending_operations,omitempty" yaml:"pending_operations,omitempty"`
+	// CompletedResources are the URNs of the resources whose operations completed successfully during the update that
+	// produced this deployment. If that update failed, they are the resources that `pulumi up --resume` may skip.
+	CompletedResources []resource.URN `json:"completed_resources,omitempty" yaml:"completed_resources,omitempty"`
 }
 
 // OperationType is the type of an operation initiated by the engine. Its value indicates the type of operation
@@ -276,6 +285,9 @@ type ResourceV3 struct {
 	// PendingReplacement is used to track delete-before-replace resources that have been deleted but not yet
 	// recreated.
 	PendingReplacement bool `json:"pendingReplacement,omitempty" yaml:"pendingReplacement,omitempty"`
+	// CustomTimeouts records the user-specified timeouts, in seconds, for the resource's create, update and delete
+	// operations, if any.
+	CustomTimeouts *resource.CustomTimeouts `json:"customTimeouts,omitempty" yaml:"customTimeouts,omitempty"`
 }
 
 // ManifestV1 captures meta-information about this checkpoint file, such as versions of binaries, etc.
@@ -290,6 +302,24 @@ type ManifestV1 struct {
 	Plugins []PluginInfoV1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
+// PlanV1 is a plan computed by a preview, which records the steps planned for each resource so that a later update
+// can be constrained to perform exactly those steps.
+type PlanV1 struct {
+	// Version indicates the schema of the encoded plan.
+	Version int `json:"version" yaml:"version"`
+	// Resources contains the steps planned for each resource, keyed by URN.
+	Resources map[resource.URN]PlannedResourceV1 `json:"resources" yaml:"resources"`
+}
+
+// PlannedResourceV1 captures the steps planned for a single resource.
+type PlannedResourceV1 struct {
+	// Ops are the operations planned for the resource, in the order they were planned.
+	Ops []string `json:"ops" yaml:"ops"`
+	// Inputs are the planned input properties of the resource. Values that were unknown at the time of the plan are
+	// represented by the unknown value sentinel used by the resource provider protocol.
+	Inputs map[string]interface{} `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+}
+
 // PluginInfoV1 captures the version and information about a plugin.
 type PluginInfoV1 struct {
 	Name    string               `json:"name" yaml:"name"`
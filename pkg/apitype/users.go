@@ -0,0 +1,33 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apitype
+
+// OrganizationSummary describes an organization that a user of the Pulumi service is a member of.
+type OrganizationSummary struct {
+	// GitHubLogin is the organization's name, as used in stack names.
+	GitHubLogin string `json:"githubLogin"`
+	// Name is the organization's display name.
+	Name string `json:"name"`
+}
+
+// User describes the user that an access token belongs to.
+type User struct {
+	// GitHubLogin is the user's name, as used in stack names.
+	GitHubLogin string `json:"githubLogin"`
+	// Name is the user's display name.
+	Name string `json:"name"`
+	// Organizations are the organizations that the user is a member of.
+	Organizations []OrganizationSummary `json:"organizations,omitempty"`
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/diag/colors"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
@@ -84,21 +85,13 @@ func PreviewThenPrompt(ctx context.Context, kind apitype.UpdateKind, stack Stack
 	// Note that eventsChannel is not closed in a `defer`. It is generally unsafe to do so, since defers run during
 	// panics and we can't know whether or not we were in the middle of writing to this channel when the panic occurred.
 	//
-	// Instead of using a `defer`, we manually close `eventsChannel` on every exit of this function.
+	// Instead of using a `defer`, we manually close `eventsChannel` as soon as the preview has finished.
 	eventsChannel := make(chan engine.Event)
 
-	var events []engine.Event
-	go func() {
-		// pull the events from the channel and store them locally
-		for e := range eventsChannel {
-			if e.Type == engine.ResourcePreEvent ||
-				e.Type == engine.ResourceOutputsEvent ||
-				e.Type == engine.SummaryEvent {
-
-				events = append(events, e)
-			}
-		}
-	}()
+	// The preview's events are only needed if we are going to prompt, so skip holding on to them otherwise; for
+	// large programs there are a great many of them.
+	prompting := !op.Opts.AutoApprove && kind != apitype.PreviewUpdate
+	collected := collectPreviewEvents(eventsChannel, prompting)
 
 	// Perform the update operations, passing true for dryRun, so that we get a preview.
 	// We perform the preview (DryRun), but don't display the cloud link since the
@@ -110,23 +103,50 @@ func PreviewThenPrompt(ctx context.Context, kind apitype.UpdateKind, stack Stack
 	}
 
 	changes, err := apply(ctx, kind, stack, op, opts, eventsChannel)
+
+	// The preview has finished sending events, so wait for the collector to see them all before using them.
+	close(eventsChannel)
+	events := <-collected
 	if err != nil {
-		close(eventsChannel)
 		return changes, err
 	}
 
 	// If there are no changes, or we're auto-approving or just previewing, we can skip the confirmation prompt.
-	if op.Opts.AutoApprove || kind == apitype.PreviewUpdate {
-		close(eventsChannel)
+	if !prompting {
 		return changes, nil
 	}
 
+	// For destroys, spell out exactly what is going to be deleted, and in what order, before asking.
+	if kind == apitype.DestroyUpdate {
+		_, err = os.Stdout.WriteString(createDeleteList(events, op.Opts.Display) + "\n")
+		contract.IgnoreError(err)
+	}
+
 	// Otherwise, ensure the user wants to proceed.
 	err = confirmBeforeUpdating(kind, stack, events, op.Opts)
-	close(eventsChannel)
 	return changes, err
 }
 
+// collectPreviewEvents pulls events from the given channel until it is closed, and then sends those that are needed
+// to prompt for confirmation, if any, to the returned channel. The events must not be used until they have been
+// received from the returned channel, as until then the collector may still be appending to them.
+func collectPreviewEvents(events <-chan engine.Event, prompting bool) <-chan []engine.Event {
+	collected := make(chan []engine.Event, 1)
+	go func() {
+		var kept []engine.Event
+		for e := range events {
+			if prompting && (e.Type == engine.ResourcePreEvent ||
+				e.Type == engine.ResourceOutputsEvent ||
+				e.Type == engine.SummaryEvent) {
+
+				kept = append(kept, e)
+			}
+		}
+		collected <- kept
+	}()
+	return collected
+}
+
 // confirmBeforeUpdating asks the user whether to proceed. A nil error means yes.
 func confirmBeforeUpdating(kind apitype.UpdateKind, stack Stack,
 	events []engine.Event, opts UpdateOptions) error {
@@ -170,7 +190,7 @@ func confirmBeforeUpdating(kind apitype.UpdateKind, stack Stack,
 		}
 
 		if response == string(no) {
-			return errors.Errorf("confirmation declined, not proceeding with the %s", kind)
+			return ConfirmationDeclinedError{Kind: kind}
 		}
 
 		if response == string(yes) {
@@ -188,6 +208,12 @@ func confirmBeforeUpdating(kind apitype.UpdateKind, stack Stack,
 
 func PreviewThenPromptThenExecute(ctx context.Context, kind apitype.UpdateKind, stack Stack,
 	op UpdateOperation, apply Applier) (engine.ResourceChanges, error) {
+	if kind != apitype.PreviewUpdate {
+		if err := CheckWritable(stack.Backend(), "%s stack '%s'", kind, stack.Ref()); err != nil {
+			return nil, err
+		}
+	}
+
 	// Preview the operation to the user and ask them if they want to proceed.
 
 	if !op.Opts.SkipPreview {
@@ -195,17 +221,57 @@ func PreviewThenPromptThenExecute(ctx context.Context, kind apitype.UpdateKind,
 		if err != nil || kind == apitype.PreviewUpdate {
 			return changes, err
 		}
+
+		// The operation is expected to take the steps that its preview did, which lets its progress be estimated.
+		op.Opts.Display.ExpectedSteps = 0
+		for stepOp, count := range changes {
+			if stepOp != deploy.OpSame {
+				op.Opts.Display.ExpectedSteps += count
+			}
+		}
 	}
 
 	// Perform the change (!DryRun) and show the cloud link to the result.
 	// We don't care about the events it issues, so just pass a nil channel along.
 	opts := ApplierOptions{
 		DryRun:   false,
-		ShowLink: true,
+		ShowLink: !op.Opts.Display.IsJSON(),
 	}
 	return apply(ctx, kind, stack, op, opts, nil /*events*/)
 }
 
+// createDeleteList renders the ordered list of resources that the previewed operation will delete, flagging any
+// resources that are protected.
+func createDeleteList(events []engine.Event, displayOpts display.Options) string {
+	var deletes []engine.StepEventMetadata
+	for _, e := range events {
+		if e.Type != engine.ResourcePreEvent {
+			continue
+		}
+		m := e.Payload.(engine.ResourcePreEventPayload).Metadata
+		if m.Op == deploy.OpDelete || m.Op == deploy.OpDeleteReplaced {
+			deletes = append(deletes, m)
+		}
+	}
+
+	if len(deletes) == 0 {
+		return displayOpts.Color.Colorize(colors.SpecInfo + "No resources will be deleted." + colors.Reset)
+	}
+
+	buff := &bytes.Buffer{}
+	fmt.Fprintln(buff, displayOpts.Color.Colorize(colors.SpecHeadline+
+		fmt.Sprintf("The following %d resource(s) will be deleted, in this order:", len(deletes))+colors.Reset))
+	for i, m := range deletes {
+		var protected string
+		if m.Old != nil && m.Old.Protect {
+			protected = displayOpts.Color.Colorize(colors.SpecWarning + " [protected]" + colors.Reset)
+		}
+		fmt.Fprintf(buff, "    %d. %s%s\n", i+1, m.URN, protected)
+	}
+
+	return strings.TrimSpace(buff.String())
+}
+
 func createDiff(updateKind apitype.UpdateKind, events []engine.Event, displayOpts display.Options) string {
 	buff := &bytes.Buffer{}
 
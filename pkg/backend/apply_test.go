@@ -0,0 +1,48 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/engine"
+)
+
+func TestCollectPreviewEvents(t *testing.T) {
+	// Send events the way an Applier does, and read them back the way PreviewThenPrompt does as soon as the
+	// Applier returns. Every event must be seen, however far the collector lags behind; run with -race to check
+	// that the events are not read while the collector is still appending to them.
+	preview := func(prompting bool) []engine.Event {
+		events := make(chan engine.Event)
+		collected := collectPreviewEvents(events, prompting)
+		for i := 0; i < 1000; i++ {
+			events <- engine.Event{Type: engine.ResourcePreEvent}
+			events <- engine.Event{Type: engine.DiagEvent}
+		}
+		events <- engine.Event{Type: engine.SummaryEvent}
+		close(events)
+		return <-collected
+	}
+
+	// Only the events needed to prompt are kept, and only if there will be a prompt.
+	kept := preview(true)
+	if assert.Len(t, kept, 1001) {
+		assert.Equal(t, engine.ResourcePreEvent, kept[999].Type)
+		assert.Equal(t, engine.SummaryEvent, kept[1000].Type)
+	}
+	assert.Empty(t, preview(false))
+}
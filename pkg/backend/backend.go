@@ -16,6 +16,7 @@
 package backend
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -33,6 +34,8 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/stack"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cancel"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -41,6 +44,30 @@ var (
 	ErrNoPreviousDeployment = errors.New("no previous deployment")
 )
 
+// ReadOnly may be set to true to make every backend refuse operations that would change its stacks, so that stacks can
+// be inspected without any risk of changing them.
+var ReadOnly bool
+
+// ReadOnlyError is returned when an operation that would change a stack is attempted with a backend that is in
+// read-only mode.
+type ReadOnlyError struct {
+	Operation string // the refused operation, e.g. "update stack 'dev'".
+}
+
+func (e ReadOnlyError) Error() string {
+	return fmt.Sprintf("cannot %s: the backend is in read-only mode, either because --read-only was passed or "+
+		"because it was logged into with `pulumi login --read-only`", e.Operation)
+}
+
+// CheckWritable returns a ReadOnlyError for the operation described by the given format and arguments if the given
+// backend is in read-only mode.
+func CheckWritable(b Backend, format string, args ...interface{}) error {
+	if b.ReadOnly() {
+		return ReadOnlyError{Operation: fmt.Sprintf(format, args...)}
+	}
+	return nil
+}
+
 // StackAlreadyExistsError is returned from CreateStack when the stack already exists in the backend.
 type StackAlreadyExistsError struct {
 	StackName string
@@ -50,6 +77,65 @@ func (e StackAlreadyExistsError) Error() string {
 	return fmt.Sprintf("stack '%v' already exists", e.StackName)
 }
 
+func (e StackAlreadyExistsError) Code() errutil.ErrorCode {
+	return errutil.ErrStackAlreadyExists
+}
+
+func (e StackAlreadyExistsError) Hint() string {
+	return fmt.Sprintf("run `pulumi stack select %v` to use it", e.StackName)
+}
+
+// UpdateConflictError is returned when an update of a stack cannot start because another update of the stack is in
+// progress. It describes the update in progress as well as the backend is able to; fields that are unknown are empty.
+type UpdateConflictError struct {
+	StackName string
+	Kind      apitype.UpdateKind // the kind of the update in progress.
+	StartTime time.Time          // the time at which the update in progress started.
+	User      string             // the user who started the update in progress.
+	Hostname  string             // the machine on which the update in progress is running.
+	PID       int                // the ID of the process that is running the update in progress.
+	URL       string             // a URL at which the update in progress may be investigated.
+}
+
+func (e UpdateConflictError) Error() string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "the stack '%v' is currently being updated by another update", e.StackName)
+	if e.Kind != "" {
+		fmt.Fprintf(&buffer, " (%s)", e.Kind)
+	}
+	if !e.StartTime.IsZero() {
+		fmt.Fprintf(&buffer, " started at %s", e.StartTime.Format(time.RFC1123))
+	}
+	if e.User != "" {
+		fmt.Fprintf(&buffer, " by %s", e.User)
+	}
+	if e.Hostname != "" {
+		fmt.Fprintf(&buffer, " on %s", e.Hostname)
+	}
+	if e.PID != 0 {
+		fmt.Fprintf(&buffer, " (process %d)", e.PID)
+	}
+	if e.URL != "" {
+		fmt.Fprintf(&buffer, "; see %s", e.URL)
+	}
+	buffer.WriteString("; if it is no longer running, run `pulumi cancel` to cancel it")
+	return buffer.String()
+}
+
+func (e UpdateConflictError) Code() errutil.ErrorCode {
+	return errutil.ErrStackUpdateConflict
+}
+
+// ConfirmationDeclinedError is returned when the user declines to proceed with an update when asked to confirm it, in
+// which case the update made no changes.
+type ConfirmationDeclinedError struct {
+	Kind apitype.UpdateKind // the kind of the update that was declined.
+}
+
+func (e ConfirmationDeclinedError) Error() string {
+	return fmt.Sprintf("confirmation declined, not proceeding with the %s", e.Kind)
+}
+
 // StackReference is an opaque type that refers to a stack managed by a backend.  The CLI uses the ParseStackReference
 // method to turn a string like "my-great-stack" or "pulumi/my-great-stack" into a stack reference that can be used to
 // interact with the stack via the backend. Stack references are specific to a given backend and different back ends
@@ -80,6 +166,9 @@ type Backend interface {
 	Name() string
 	// URL returns a URL at which information about this backend may be seen.
 	URL() string
+	// ReadOnly returns true if the backend refuses operations that would change its stacks, such as updates, because
+	// ReadOnly is set or because it was logged into with `pulumi login --read-only`.
+	ReadOnly() bool
 
 	// ParseStackReference takes a string representation and parses it to a reference which may be used for other
 	// methods in this backend.
@@ -123,6 +212,10 @@ type Backend interface {
 
 	// ExportDeployment exports the deployment for the given stack as an opaque JSON message.
 	ExportDeployment(ctx context.Context, stackRef StackReference) (*apitype.UntypedDeployment, error)
+	// ExportHistoricalDeployment exports the deployment that was recorded by the given version of the stack's update
+	// history as an opaque JSON message.
+	ExportHistoricalDeployment(ctx context.Context, stackRef StackReference,
+		version int) (*apitype.UntypedDeployment, error)
 	// ImportDeployment imports the given deployment into the indicated stack.
 	ImportDeployment(ctx context.Context, stackRef StackReference, deployment *apitype.UntypedDeployment) error
 	// Logout logs you out of the backend and removes any stored credentials.
@@ -224,6 +317,16 @@ func (c *backendClient) GetStackOutputs(ctx context.Context, name string) (resou
 	return res.Outputs, nil
 }
 
+// DownloadPlugin downloads the given plugin from the plugin release server, since backends other than the Pulumi
+// service do not serve plugins themselves.
 func (c *backendClient) DownloadPlugin(ctx context.Context, plug workspace.PluginInfo) (io.ReadCloser, error) {
-	return nil, errors.New("downloading plugins at runtime not available when using local backend")
+	if cmdutil.Offline {
+		return nil, cmdutil.OfflineError("downloading the %s plugin %s", plug.Kind, plug.String())
+	}
+	tarball, _, err := plug.Download()
+	if err != nil {
+		return nil, errors.Wrapf(err, "the %s plugin %s is not installed, and could not be downloaded; install it "+
+			"with `pulumi plugin install --file`", plug.Kind, plug.String())
+	}
+	return tarball, nil
 }
@@ -0,0 +1,51 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
+)
+
+func TestUpdateConflictError(t *testing.T) {
+	assert.Equal(t,
+		"the stack 'dev' is currently being updated by another update; "+
+			"if it is no longer running, run `pulumi cancel` to cancel it",
+		UpdateConflictError{StackName: "dev"}.Error())
+
+	assert.Equal(t,
+		"the stack 'acme/dev' is currently being updated by another update (refresh) "+
+			"started at Mon, 01 Jan 2018 00:00:00 UTC by alice on build-7 (process 42); "+
+			"see https://app.pulumi.com/acme/web/dev/updates/3; "+
+			"if it is no longer running, run `pulumi cancel` to cancel it",
+		UpdateConflictError{
+			StackName: "acme/dev",
+			Kind:      apitype.RefreshUpdate,
+			StartTime: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+			User:      "alice",
+			Hostname:  "build-7",
+			PID:       42,
+			URL:       "https://app.pulumi.com/acme/web/dev/updates/3",
+		}.Error())
+
+	code, _ := errutil.CodeOf(errors.Wrap(UpdateConflictError{StackName: "dev"}, "updating"))
+	assert.Equal(t, errutil.ErrStackUpdateConflict, code)
+}
@@ -43,7 +43,20 @@ func ShowEvents(
 	op string, action apitype.UpdateKind, stack tokens.QName, proj tokens.PackageName,
 	events <-chan engine.Event, done chan<- bool, opts Options, isPreview bool) {
 
-	if opts.DiffDisplay {
+	// Plain output is never redrawn in place, so render it as we would for a non-interactive session.
+	if cmdutil.Plain {
+		opts.IsInteractive = false
+	}
+
+	if opts.EventLogPath != "" {
+		events = logEvents(opts.EventLogPath, events)
+	}
+
+	if opts.JSONDisplay {
+		ShowJSONEvents(op, events, done, opts, isPreview)
+	} else if opts.JSONProgress {
+		ShowJSONProgressEvents(op, events, done, opts, isPreview)
+	} else if opts.DiffDisplay {
 		ShowDiffEvents(op, action, events, done, opts)
 	} else {
 		ShowProgressEvents(op, action, stack, proj, events, done, opts, isPreview)
@@ -221,11 +234,67 @@ func renderSummaryEvent(action apitype.UpdateKind, event engine.SummaryEventPayl
 
 		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("\n%sDuration:%s %s\n",
 			colors.SpecHeadline, colors.Reset, roundedDuration)))
+
+		if opts.ShowTimings && event.Timings != nil {
+			renderTimingReport(out, event.Timings, opts)
+		}
 	}
 
 	return out.String()
 }
 
+// maxSlowestResources is the number of resources listed as the slowest in a timing report.
+const maxSlowestResources = 5
+
+// renderTimingReport writes the slowest resources of an update, its critical path, and the calls it made to each
+// package's resource providers to out.
+func renderTimingReport(out *bytes.Buffer, report *engine.TimingReport, opts Options) {
+	writeTiming := func(timing engine.ResourceTiming) {
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("    %s%-8s %s %s%s\n", timing.Op.Prefix(),
+			formatTiming(timing.Duration), timing.URN.Type(), timing.URN.Name(), colors.Reset)))
+	}
+
+	if len(report.Resources) > 0 {
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("\n%sSlowest resources:%s\n",
+			colors.SpecHeadline, colors.Reset)))
+		for i, timing := range report.Resources {
+			if i == maxSlowestResources {
+				break
+			}
+			writeTiming(timing)
+		}
+	}
+
+	if len(report.CriticalPath) > 0 {
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("\n%sCritical path:%s %s\n",
+			colors.SpecHeadline, colors.Reset, formatTiming(report.CriticalPathDuration))))
+		for _, timing := range report.CriticalPath {
+			writeTiming(timing)
+		}
+	}
+
+	if len(report.Providers) > 0 {
+		fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("\n%sProvider calls:%s\n",
+			colors.SpecHeadline, colors.Reset)))
+		for _, provider := range report.Providers {
+			fprintIgnoreError(out, fmt.Sprintf("    %s %s\n", provider.Package, formatTiming(provider.Duration)))
+			for _, call := range provider.Calls {
+				fprintIgnoreError(out, fmt.Sprintf("        %-12s %5d calls, p95 %s\n",
+					call.Method, call.Count, formatTiming(call.P95)))
+			}
+		}
+	}
+}
+
+// formatTiming rounds a duration to a precision suitable for display: whole seconds for durations of a second or
+// more, and milliseconds otherwise.
+func formatTiming(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
 func renderPreludeEvent(event engine.PreludeEventPayload, opts Options) string {
 	// Only if we have been instructed to show configuration values will we print anything during the prelude.
 	if !opts.ShowConfig {
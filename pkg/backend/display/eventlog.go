@@ -0,0 +1,164 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// eventLogEntry is a single line of an event log: one engine event, serialized as JSON.
+type eventLogEntry struct {
+	// Timestamp is the time at which the event was received.
+	Timestamp time.Time `json:"timestamp"`
+	// Type is the kind of engine event.
+	Type engine.EventType `json:"type"`
+	// Preview is true if the event was issued by a preview rather than an update.
+	Preview bool `json:"preview,omitempty"`
+
+	// Config contains the stack's configuration, for prelude events. Secret values are blinded.
+	Config map[string]string `json:"config,omitempty"`
+	// Step describes the step a resource event applies to.
+	Step *previewStep `json:"step,omitempty"`
+	// Status is the state of the resource after a failed operation: "ok", "partial-failure", or "unknown".
+	Status string `json:"status,omitempty"`
+	// Diagnostic is the message reported by a diagnostic event.
+	Diagnostic *previewDiagnostic `json:"diagnostic,omitempty"`
+	// Stdout is the text written by a stdout event.
+	Stdout string `json:"stdout,omitempty"`
+	// Summary contains the outcome of the operation, for summary events.
+	Summary *eventLogSummary `json:"summary,omitempty"`
+}
+
+// eventLogSummary is the outcome of an operation, as recorded by a summary event.
+type eventLogSummary struct {
+	Duration      time.Duration          `json:"duration,omitempty"`
+	ChangeSummary engine.ResourceChanges `json:"changeSummary,omitempty"`
+	MaybeCorrupt  bool                   `json:"maybeCorrupt,omitempty"`
+	Timings       *engine.TimingReport   `json:"timings,omitempty"`
+}
+
+// makeEventLogEntry translates an engine event into an event log entry.
+func makeEventLogEntry(e engine.Event, timestamp time.Time) eventLogEntry {
+	entry := eventLogEntry{Timestamp: timestamp, Type: e.Type}
+
+	switch e.Type {
+	case engine.PreludeEvent:
+		p := e.Payload.(engine.PreludeEventPayload)
+		entry.Preview, entry.Config = p.IsPreview, p.Config
+	case engine.SummaryEvent:
+		p := e.Payload.(engine.SummaryEventPayload)
+		entry.Preview = p.IsPreview
+		entry.Summary = &eventLogSummary{
+			Duration:      p.Duration,
+			ChangeSummary: p.ResourceChanges,
+			MaybeCorrupt:  p.MaybeCorrupt,
+			Timings:       p.Timings,
+		}
+	case engine.StdoutColorEvent:
+		entry.Stdout = colors.Never.Colorize(e.Payload.(engine.StdoutEventPayload).Message)
+	case engine.DiagEvent:
+		p := e.Payload.(engine.DiagEventPayload)
+		entry.Diagnostic = &previewDiagnostic{
+			URN:      p.URN,
+			Message:  colors.Never.Colorize(p.Prefix + p.Message),
+			Severity: p.Severity,
+		}
+	case engine.ResourcePreEvent:
+		p := e.Payload.(engine.ResourcePreEventPayload)
+		step := makePreviewStep(p.Metadata)
+		entry.Preview, entry.Step = p.Planning, &step
+	case engine.ResourceOutputsEvent:
+		p := e.Payload.(engine.ResourceOutputsEventPayload)
+		step := makePreviewStep(p.Metadata)
+		entry.Preview, entry.Step = p.Planning, &step
+	case engine.ResourceOperationFailed:
+		p := e.Payload.(engine.ResourceOperationFailedPayload)
+		step := makePreviewStep(p.Metadata)
+		entry.Step, entry.Status = &step, statusName(p.Status)
+	}
+	return entry
+}
+
+// statusName returns the name used for a resource status in event logs.
+func statusName(status resource.Status) string {
+	switch status {
+	case resource.StatusOK:
+		return "ok"
+	case resource.StatusPartialFailure:
+		return "partial-failure"
+	default:
+		return "unknown"
+	}
+}
+
+// writeEventLogEntry writes a single event to an event log as a line of JSON.
+func writeEventLogEntry(w io.Writer, e engine.Event) error {
+	b, err := json.Marshal(makeEventLogEntry(e, time.Now()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// logEvents appends every event read from `events` to the event log at the given path, as newline-delimited JSON,
+// and forwards it to the returned channel. Each event is written before it is forwarded, so the log is complete by
+// the time the consumer has seen the final event. If the log cannot be written, a warning is printed and events
+// continue to be forwarded.
+func logEvents(path string, events <-chan engine.Event) <-chan engine.Event {
+	forward := make(chan engine.Event)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		fprintIgnoreError(os.Stderr, fmt.Sprintf("warning: could not open event log: %v\n", err))
+	}
+
+	go func() {
+		defer func() {
+			if f != nil {
+				contract.IgnoreClose(f)
+			}
+			close(forward)
+		}()
+
+		// Displays stop reading once they see a cancel event, so stop forwarding then; keep logging until the
+		// channel is closed, however.
+		forwarding := true
+		for e := range events {
+			if f != nil {
+				if err := writeEventLogEntry(f, e); err != nil {
+					fprintIgnoreError(os.Stderr, fmt.Sprintf("warning: could not write to event log: %v\n", err))
+					contract.IgnoreClose(f)
+					f = nil
+				}
+			}
+			if forwarding {
+				forward <- e
+				forwarding = e.Type != engine.CancelEvent
+			}
+		}
+	}()
+
+	return forward
+}
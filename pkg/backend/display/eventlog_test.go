@@ -0,0 +1,118 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestMakeEventLogEntry(t *testing.T) {
+	now := time.Now()
+	urn := resource.URN("urn:pulumi:test::proj::pkg:m:typ::a")
+	create := engine.StepEventMetadata{Op: deploy.OpCreate, URN: urn, Type: urn.Type()}
+
+	entry := makeEventLogEntry(engine.Event{Type: engine.PreludeEvent,
+		Payload: engine.PreludeEventPayload{IsPreview: true, Config: map[string]string{"proj:key": "value"}}}, now)
+	assert.Equal(t, eventLogEntry{Timestamp: now, Type: engine.PreludeEvent, Preview: true,
+		Config: map[string]string{"proj:key": "value"}}, entry)
+
+	entry = makeEventLogEntry(engine.Event{Type: engine.ResourcePreEvent,
+		Payload: engine.ResourcePreEventPayload{Metadata: create, Planning: true}}, now)
+	assert.True(t, entry.Preview)
+	if assert.NotNil(t, entry.Step) {
+		assert.Equal(t, deploy.OpCreate, entry.Step.Op)
+		assert.Equal(t, urn, entry.Step.URN)
+	}
+
+	entry = makeEventLogEntry(engine.Event{Type: engine.ResourceOperationFailed,
+		Payload: engine.ResourceOperationFailedPayload{Metadata: create, Status: resource.StatusPartialFailure}}, now)
+	assert.Equal(t, "partial-failure", entry.Status)
+	assert.NotNil(t, entry.Step)
+
+	// Colorization is stripped from the text of diagnostics and stdout.
+	entry = makeEventLogEntry(engine.Event{Type: engine.DiagEvent, Payload: engine.DiagEventPayload{URN: urn,
+		Prefix: colors.SpecError + "error: " + colors.Reset, Message: "boom", Severity: diag.Error}}, now)
+	assert.Equal(t, &previewDiagnostic{URN: urn, Message: "error: boom", Severity: diag.Error}, entry.Diagnostic)
+	entry = makeEventLogEntry(engine.Event{Type: engine.StdoutColorEvent,
+		Payload: engine.StdoutEventPayload{Message: colors.Bold + "hello" + colors.Reset}}, now)
+	assert.Equal(t, "hello", entry.Stdout)
+
+	entry = makeEventLogEntry(engine.Event{Type: engine.SummaryEvent, Payload: engine.SummaryEventPayload{
+		Duration: time.Minute, ResourceChanges: engine.ResourceChanges{deploy.OpCreate: 1}}}, now)
+	assert.Equal(t, &eventLogSummary{Duration: time.Minute, ChangeSummary: engine.ResourceChanges{deploy.OpCreate: 1}},
+		entry.Summary)
+}
+
+func TestLogEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-event-log")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.json")
+
+	// Each event is forwarded, and appended to the log as a line of JSON.
+	urn := resource.URN("urn:pulumi:test::proj::pkg:m:typ::a")
+	var forwarded []engine.EventType
+	for e := range logEvents(path, sendEvents(
+		engine.Event{Type: engine.PreludeEvent, Payload: engine.PreludeEventPayload{}},
+		engine.Event{Type: engine.DiagEvent, Payload: engine.DiagEventPayload{URN: urn, Message: "hi"}},
+	)) {
+		forwarded = append(forwarded, e.Type)
+	}
+	expected := []engine.EventType{engine.PreludeEvent, engine.DiagEvent, engine.CancelEvent}
+	assert.Equal(t, expected, forwarded)
+
+	readLog := func() []engine.EventType {
+		f, err := os.Open(path)
+		assert.NoError(t, err)
+		defer f.Close()
+		var types []engine.EventType
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var entry eventLogEntry
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			types = append(types, entry.Type)
+		}
+		return types
+	}
+	assert.Equal(t, expected, readLog())
+
+	// Later operations append to the same log.
+	for range logEvents(path, sendEvents()) {
+	}
+	assert.Equal(t, append(expected, engine.CancelEvent), readLog())
+
+	// Events are still forwarded if the log cannot be written.
+	forwarded = nil
+	for e := range logEvents(filepath.Join(dir, "missing", "events.json"), sendEvents(
+		engine.Event{Type: engine.PreludeEvent, Payload: engine.PreludeEventPayload{}},
+	)) {
+		forwarded = append(forwarded, e.Type)
+	}
+	assert.Equal(t, []engine.EventType{engine.PreludeEvent, engine.CancelEvent}, forwarded)
+}
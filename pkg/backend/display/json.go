@@ -0,0 +1,202 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/stack"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// previewDigest is a summary of the planned steps of a preview, or of the steps performed by an update, refresh, or
+// destroy, suitable for consumption by other programs.
+type previewDigest struct {
+	// Permalink links to the preview in the backend's web console, if it has one.
+	Permalink string `json:"permalink,omitempty"`
+	// Config contains the stack's configuration. Secret values are blinded.
+	Config map[string]string `json:"config,omitempty"`
+	// Steps contains the planned steps, in the order in which they were planned, or, if this is not a preview, the
+	// steps that were performed, in the order in which they completed.
+	Steps []previewStep `json:"steps"`
+	// Diagnostics contains the warnings and errors reported by the preview.
+	Diagnostics []previewDiagnostic `json:"diagnostics,omitempty"`
+	// Duration is the length of time the preview took.
+	Duration time.Duration `json:"duration,omitempty"`
+	// ChangeSummary contains the number of resources affected by each kind of operation.
+	ChangeSummary engine.ResourceChanges `json:"changeSummary,omitempty"`
+	// MaybeCorrupt is true if one or more resources may be corrupt.
+	MaybeCorrupt bool `json:"maybeCorrupt,omitempty"`
+}
+
+// previewStep describes a single planned step.
+type previewStep struct {
+	// Op is the kind of operation the step performs.
+	Op deploy.StepOp `json:"op"`
+	// URN is the URN of the resource affected by the step.
+	URN resource.URN `json:"urn"`
+	// URL links to the resource in the backend's web console, if it has one.
+	URL string `json:"url,omitempty"`
+	// Type is the type of the resource affected by the step.
+	Type string `json:"type"`
+	// Provider is the provider that will perform the step.
+	Provider string `json:"provider,omitempty"`
+	// OldState is the state of the resource before the step, if any.
+	OldState *apitype.ResourceV3 `json:"oldState,omitempty"`
+	// NewState is the planned state of the resource after the step, if any.
+	NewState *apitype.ResourceV3 `json:"newState,omitempty"`
+	// DiffReasons contains the properties that differ between the old and new states.
+	DiffReasons []resource.PropertyKey `json:"diffReasons,omitempty"`
+	// ReplaceReasons contains the properties whose changes require the resource to be replaced.
+	ReplaceReasons []resource.PropertyKey `json:"replaceReasons,omitempty"`
+	// ReplaceReasonDetails explains, for some or all of ReplaceReasons, why a change to that property requires the
+	// resource to be replaced.
+	ReplaceReasonDetails map[resource.PropertyKey]string `json:"replaceReasonDetails,omitempty"`
+	// Failed is true if the step was attempted but did not succeed.
+	Failed bool `json:"failed,omitempty"`
+}
+
+// previewDiagnostic is a warning or error reported during a preview.
+type previewDiagnostic struct {
+	URN      resource.URN  `json:"urn,omitempty"`
+	Message  string        `json:"message"`
+	Severity diag.Severity `json:"severity"`
+}
+
+// ShowJSONEvents reads events from the `events` channel until the operation is complete, accumulating a summary of the
+// planned steps of a preview, or of the steps performed by any other operation. Once the operation is complete, the
+// summary is written to stdout as a single JSON document.
+func ShowJSONEvents(op string, events <-chan engine.Event, done chan<- bool, opts Options, isPreview bool) {
+	// Ensure we close the done channel before exiting.
+	defer func() { close(done) }()
+
+	out, err := json.MarshalIndent(makePreviewDigest(events, opts, isPreview), "", "    ")
+	if err != nil {
+		fprintIgnoreError(os.Stderr, fmt.Sprintf("error: could not render the %s as JSON: %v\n", op, err))
+		return
+	}
+	fprintIgnoreError(os.Stdout, string(out)+"\n")
+}
+
+// makePreviewDigest reads events from the `events` channel until it is closed or a cancel event is read, and returns
+// the summary of the operation that they describe.
+func makePreviewDigest(events <-chan engine.Event, opts Options, isPreview bool) previewDigest {
+	digest := previewDigest{Permalink: opts.Permalink, Steps: []previewStep{}}
+	for e := range events {
+		if e.Type == engine.CancelEvent {
+			break
+		}
+
+		switch e.Type {
+		case engine.PreludeEvent:
+			digest.Config = e.Payload.(engine.PreludeEventPayload).Config
+		case engine.SummaryEvent:
+			p := e.Payload.(engine.SummaryEventPayload)
+			digest.Duration = p.Duration
+			digest.ChangeSummary = p.ResourceChanges
+			digest.MaybeCorrupt = p.MaybeCorrupt
+		case engine.DiagEvent:
+			p := e.Payload.(engine.DiagEventPayload)
+			if p.Ephemeral || (p.Severity != diag.Error && p.Severity != diag.Warning) {
+				continue
+			}
+			digest.Diagnostics = append(digest.Diagnostics, previewDiagnostic{
+				URN:      p.URN,
+				Message:  colors.Never.Colorize(p.Prefix + p.Message),
+				Severity: p.Severity,
+			})
+		case engine.ResourcePreEvent:
+			if isPreview {
+				digest.Steps = append(digest.Steps,
+					makeDigestStep(e.Payload.(engine.ResourcePreEventPayload).Metadata, opts))
+			}
+		case engine.ResourceOutputsEvent:
+			if !isPreview {
+				digest.Steps = append(digest.Steps,
+					makeDigestStep(e.Payload.(engine.ResourceOutputsEventPayload).Metadata, opts))
+			}
+		case engine.ResourceOperationFailed:
+			if !isPreview {
+				step := makeDigestStep(e.Payload.(engine.ResourceOperationFailedPayload).Metadata, opts)
+				step.Failed = true
+				digest.Steps = append(digest.Steps, step)
+			}
+		}
+	}
+	return digest
+}
+
+// makeDigestStep translates the metadata of a step into a previewStep, linking it to the backend's web console if
+// possible.
+func makeDigestStep(m engine.StepEventMetadata, opts Options) previewStep {
+	step := makePreviewStep(m)
+	if opts.ResourceURL != nil {
+		step.URL = opts.ResourceURL(step.URN)
+	}
+	return step
+}
+
+// makePreviewStep translates the metadata of a planned step into a previewStep.
+func makePreviewStep(m engine.StepEventMetadata) previewStep {
+	step := previewStep{
+		Op:             m.Op,
+		URN:            m.URN,
+		Type:           string(m.Type),
+		Provider:       m.Provider,
+		OldState:       makePreviewState(m.URN, m.Old),
+		NewState:       makePreviewState(m.URN, m.New),
+		DiffReasons:    m.Diffs,
+		ReplaceReasons: m.Keys,
+
+		ReplaceReasonDetails: m.ReplaceReasons,
+	}
+
+	// If the provider did not say which properties differ, report the properties whose inputs changed.
+	if step.DiffReasons == nil && m.Old != nil && m.New != nil {
+		if diff := m.Old.Inputs.Diff(m.New.Inputs, engine.IsInternalPropertyKey); diff != nil {
+			for _, k := range diff.Keys() {
+				if diff.Changed(k) {
+					step.DiffReasons = append(step.DiffReasons, k)
+				}
+			}
+		}
+	}
+	return step
+}
+
+// makePreviewState serializes the state described by the given step metadata, if any.
+func makePreviewState(urn resource.URN, m *engine.StepEventStateMetadata) *apitype.ResourceV3 {
+	if m == nil {
+		return nil
+	}
+	contract.Assert(urn != "")
+
+	state := resource.NewState(m.Type, m.URN, m.Custom, m.Delete, m.ID, m.Inputs, m.Outputs, m.Parent, m.Protect,
+		false, nil, m.InitErrors, m.Provider, nil, false, resource.CustomTimeouts{})
+	if state.URN == "" {
+		state.URN = urn
+	}
+	res := stack.SerializeResource(state)
+	return &res
+}
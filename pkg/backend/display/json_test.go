@@ -0,0 +1,127 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// sendEvents returns a channel from which the given events, followed by a cancel event, may be read.
+func sendEvents(events ...engine.Event) <-chan engine.Event {
+	ch := make(chan engine.Event)
+	go func() {
+		for _, e := range events {
+			ch <- e
+		}
+		ch <- engine.Event{Type: engine.CancelEvent}
+		close(ch)
+	}()
+	return ch
+}
+
+func TestPreviewDigest(t *testing.T) {
+	urnA := resource.URN("urn:pulumi:test::proj::pkg:m:typ::a")
+	urnB := resource.URN("urn:pulumi:test::proj::pkg:m:typ::b")
+	state := func(urn resource.URN, foo string) *engine.StepEventStateMetadata {
+		return &engine.StepEventStateMetadata{URN: urn, Type: urn.Type(), Custom: true,
+			Inputs: resource.PropertyMap{"foo": resource.NewStringProperty(foo)}}
+	}
+	create := engine.StepEventMetadata{Op: deploy.OpCreate, URN: urnA, Type: urnA.Type(), New: state(urnA, "x")}
+	update := engine.StepEventMetadata{Op: deploy.OpUpdate, URN: urnB, Type: urnB.Type(),
+		Old: state(urnB, "x"), New: state(urnB, "y")}
+
+	digest := makePreviewDigest(sendEvents(
+		engine.Event{Type: engine.PreludeEvent,
+			Payload: engine.PreludeEventPayload{Config: map[string]string{"proj:key": "value"}}},
+		engine.Event{Type: engine.ResourcePreEvent, Payload: engine.ResourcePreEventPayload{Metadata: create}},
+		engine.Event{Type: engine.ResourcePreEvent, Payload: engine.ResourcePreEventPayload{Metadata: update}},
+		// Only warnings and errors that are not ephemeral are reported.
+		engine.Event{Type: engine.DiagEvent,
+			Payload: engine.DiagEventPayload{URN: urnA, Message: "status", Severity: diag.Info}},
+		engine.Event{Type: engine.DiagEvent,
+			Payload: engine.DiagEventPayload{URN: urnA, Message: "working", Severity: diag.Warning, Ephemeral: true}},
+		engine.Event{Type: engine.DiagEvent,
+			Payload: engine.DiagEventPayload{URN: urnB, Prefix: "warning: ", Message: "careful", Severity: diag.Warning}},
+		// A preview reports planned steps, not completed ones.
+		engine.Event{Type: engine.ResourceOutputsEvent, Payload: engine.ResourceOutputsEventPayload{Metadata: create}},
+		engine.Event{Type: engine.SummaryEvent,
+			Payload: engine.SummaryEventPayload{ResourceChanges: engine.ResourceChanges{deploy.OpCreate: 1}}},
+	), Options{ResourceURL: func(urn resource.URN) string { return "https://app/" + string(urn.Name()) }}, true)
+
+	assert.Equal(t, map[string]string{"proj:key": "value"}, digest.Config)
+	if assert.Len(t, digest.Steps, 2) {
+		assert.Equal(t, deploy.OpCreate, digest.Steps[0].Op)
+		assert.Equal(t, "https://app/a", digest.Steps[0].URL)
+		assert.Nil(t, digest.Steps[0].OldState)
+		assert.Equal(t, "x", digest.Steps[0].NewState.Inputs["foo"])
+
+		// Without diffs from the provider, the properties whose inputs changed are reported.
+		assert.Equal(t, deploy.OpUpdate, digest.Steps[1].Op)
+		assert.Equal(t, []resource.PropertyKey{"foo"}, digest.Steps[1].DiffReasons)
+	}
+	assert.Equal(t, []previewDiagnostic{{URN: urnB, Message: "warning: careful", Severity: diag.Warning}},
+		digest.Diagnostics)
+	assert.Equal(t, engine.ResourceChanges{deploy.OpCreate: 1}, digest.ChangeSummary)
+}
+
+func TestUpdateDigest(t *testing.T) {
+	urnA := resource.URN("urn:pulumi:test::proj::pkg:m:typ::a")
+	urnB := resource.URN("urn:pulumi:test::proj::pkg:m:typ::b")
+	create := engine.StepEventMetadata{Op: deploy.OpCreate, URN: urnA, Type: urnA.Type()}
+	update := engine.StepEventMetadata{Op: deploy.OpUpdate, URN: urnB, Type: urnB.Type(),
+		Diffs: []resource.PropertyKey{"bar"}}
+
+	// Other operations report the steps that were performed, in the order in which they completed.
+	digest := makePreviewDigest(sendEvents(
+		engine.Event{Type: engine.ResourcePreEvent, Payload: engine.ResourcePreEventPayload{Metadata: create}},
+		engine.Event{Type: engine.ResourcePreEvent, Payload: engine.ResourcePreEventPayload{Metadata: update}},
+		engine.Event{Type: engine.ResourceOperationFailed,
+			Payload: engine.ResourceOperationFailedPayload{Metadata: update}},
+		engine.Event{Type: engine.ResourceOutputsEvent, Payload: engine.ResourceOutputsEventPayload{Metadata: create}},
+	), Options{}, false)
+
+	assert.Equal(t, []previewStep{
+		{Op: deploy.OpUpdate, URN: urnB, Type: string(urnB.Type()), DiffReasons: []resource.PropertyKey{"bar"},
+			Failed: true},
+		{Op: deploy.OpCreate, URN: urnA, Type: string(urnA.Type())},
+	}, digest.Steps)
+}
+
+func TestPreviewDigestJSON(t *testing.T) {
+	// The digest is consumed by other programs, so its format must not change.
+	digest := makePreviewDigest(sendEvents(), Options{}, true)
+	byts, err := json.Marshal(digest)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"steps":[]}`, string(byts))
+
+	urn := resource.URN("urn:pulumi:test::proj::pkg:m:typ::a")
+	digest.Steps = append(digest.Steps, previewStep{Op: deploy.OpReplace, URN: urn, Type: string(urn.Type()),
+		ReplaceReasons: []resource.PropertyKey{"foo"}, Failed: true})
+	digest.Diagnostics = []previewDiagnostic{{URN: urn, Message: "boom", Severity: diag.Error}}
+	byts, err = json.Marshal(digest)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"steps":[{"op":"replace","urn":"urn:pulumi:test::proj::pkg:m:typ::a","type":"pkg:m:typ",`+
+		`"replaceReasons":["foo"],"failed":true}],`+
+		`"diagnostics":[{"urn":"urn:pulumi:test::proj::pkg:m:typ::a","message":"boom","severity":"error"}]}`,
+		string(byts))
+}
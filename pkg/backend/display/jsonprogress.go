@@ -0,0 +1,169 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// The types of JSON progress events.
+const (
+	progressStarted      = "started"       // the operation has started.
+	progressStepStarted  = "step-started"  // a step has started.
+	progressStepFinished = "step-finished" // a step has finished.
+	progressStepFailed   = "step-failed"   // a step has failed.
+	progressDiagnostic   = "diagnostic"    // a message has been reported.
+	progressStdout       = "stdout"        // the program has written to stdout.
+	progressFinished     = "finished"      // the operation has finished.
+)
+
+// progressEvent is a single line of JSON progress. While we can add fields to this structure in the future, we should
+// not change the existing fields.
+type progressEvent struct {
+	// Timestamp is the time at which the event was received.
+	Timestamp time.Time `json:"timestamp"`
+	// Type is the kind of progress event.
+	Type string `json:"type"`
+	// Operation is the operation in progress, e.g. "update".
+	Operation string `json:"operation"`
+	// Preview is true if the operation is a preview.
+	Preview bool `json:"preview,omitempty"`
+
+	// Op, URN, and ResourceType describe the step that a step event applies to.
+	Op           deploy.StepOp `json:"op,omitempty"`
+	URN          resource.URN  `json:"urn,omitempty"`
+	ResourceType tokens.Type   `json:"resourceType,omitempty"`
+
+	// Done is the number of resources whose steps have finished or failed so far.
+	Done int `json:"done"`
+	// Total is the number of steps that the operation is expected to take, if known.
+	Total int `json:"total,omitempty"`
+	// Percent estimates how much of the operation is complete, if its total is known.
+	Percent *int `json:"percent,omitempty"`
+
+	// Severity and Message are the contents of a diagnostic or stdout event.
+	Severity diag.Severity `json:"severity,omitempty"`
+	Message  string        `json:"message,omitempty"`
+
+	// Summary contains the outcome of the operation, for finished events.
+	Summary *eventLogSummary `json:"summary,omitempty"`
+}
+
+// ShowJSONProgressEvents writes progress events to stdout as newline-delimited JSON, so that other programs may
+// render their own progress as an operation runs.
+func ShowJSONProgressEvents(op string, events <-chan engine.Event, done chan<- bool, opts Options, isPreview bool) {
+	// Ensure we close the done channel before exiting.
+	defer func() { close(done) }()
+
+	if err := writeJSONProgress(os.Stdout, op, events, opts, isPreview); err != nil {
+		fprintIgnoreError(os.Stderr, fmt.Sprintf("error: could not write the progress of the %s: %v\n", op, err))
+	}
+}
+
+// writeJSONProgress writes a line of JSON to w for each event read from events that marks the progress of the
+// operation, until events is closed or a cancel event is read. If w cannot be written, events are still read, so that
+// the operation is not blocked, and the error is returned at the end.
+func writeJSONProgress(w io.Writer, op string, events <-chan engine.Event, opts Options, isPreview bool) error {
+	enc := json.NewEncoder(w)
+	done := make(map[resource.URN]bool)
+	var err error
+	for e := range events {
+		if e.Type == engine.CancelEvent {
+			break
+		}
+
+		var metadata *engine.StepEventMetadata
+		event := progressEvent{Operation: op, Preview: isPreview}
+		switch e.Type {
+		case engine.PreludeEvent:
+			event.Type = progressStarted
+		case engine.SummaryEvent:
+			p := e.Payload.(engine.SummaryEventPayload)
+			event.Type = progressFinished
+			event.Summary = &eventLogSummary{
+				Duration:      p.Duration,
+				ChangeSummary: p.ResourceChanges,
+				MaybeCorrupt:  p.MaybeCorrupt,
+				Timings:       p.Timings,
+			}
+		case engine.StdoutColorEvent:
+			event.Type = progressStdout
+			event.Message = colors.Never.Colorize(e.Payload.(engine.StdoutEventPayload).Message)
+		case engine.DiagEvent:
+			p := e.Payload.(engine.DiagEventPayload)
+			if p.Ephemeral || (p.Severity == diag.Debug && !opts.Debug) {
+				continue
+			}
+			event.Type = progressDiagnostic
+			event.URN, event.Severity = p.URN, p.Severity
+			event.Message = colors.Never.Colorize(p.Prefix + p.Message)
+		case engine.ResourcePreEvent:
+			p := e.Payload.(engine.ResourcePreEventPayload)
+			event.Type, metadata = progressStepStarted, &p.Metadata
+		case engine.ResourceOutputsEvent:
+			p := e.Payload.(engine.ResourceOutputsEventPayload)
+			event.Type, metadata = progressStepFinished, &p.Metadata
+		case engine.ResourceOperationFailed:
+			p := e.Payload.(engine.ResourceOperationFailedPayload)
+			event.Type, metadata = progressStepFailed, &p.Metadata
+		default:
+			continue
+		}
+
+		if metadata != nil {
+			if metadata.Op == deploy.OpSame && !opts.ShowSameResources {
+				continue
+			}
+			event.Op, event.URN, event.ResourceType = metadata.Op, metadata.URN, metadata.Type
+
+			// Only logical steps are counted, as they are by the preview that estimates the total. Component resources
+			// have nothing to do, and may never report outputs, so they are done as soon as they start.
+			component := metadata.Res != nil && !metadata.Res.Custom
+			if metadata.Logical && (event.Type != progressStepStarted || component) {
+				done[metadata.URN] = true
+			}
+		}
+
+		event.Done = len(done)
+		if opts.ExpectedSteps > 0 {
+			// Estimates may be off, so only report that the operation is complete once it is.
+			percent := 100
+			if event.Type != progressFinished {
+				percent = event.Done * 100 / opts.ExpectedSteps
+				if percent > 99 {
+					percent = 99
+				}
+			}
+			event.Total, event.Percent = opts.ExpectedSteps, &percent
+		}
+		event.Timestamp = time.Now()
+
+		if err == nil {
+			err = enc.Encode(event)
+		}
+	}
+	return err
+}
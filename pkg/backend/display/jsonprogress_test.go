@@ -0,0 +1,105 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestWriteJSONProgress(t *testing.T) {
+	urnA := resource.URN("urn:pulumi:test::proj::pkg:m:typ::a")
+	urnB := resource.URN("urn:pulumi:test::proj::pkg:m:typ::b")
+	urnC := resource.URN("urn:pulumi:test::proj::pkg:m:typ::c")
+	urnD := resource.URN("urn:pulumi:test::proj::pkg:m:comp::d")
+	step := func(op deploy.StepOp, urn resource.URN) engine.StepEventMetadata {
+		return engine.StepEventMetadata{Op: op, URN: urn, Type: urn.Type(), Logical: true}
+	}
+
+	events := make(chan engine.Event)
+	go func() {
+		events <- engine.Event{Type: engine.PreludeEvent, Payload: engine.PreludeEventPayload{}}
+		events <- engine.Event{Type: engine.ResourcePreEvent,
+			Payload: engine.ResourcePreEventPayload{Metadata: step(deploy.OpSame, urnC)}}
+		events <- engine.Event{Type: engine.ResourcePreEvent,
+			Payload: engine.ResourcePreEventPayload{Metadata: step(deploy.OpCreate, urnA)}}
+		events <- engine.Event{Type: engine.DiagEvent,
+			Payload: engine.DiagEventPayload{URN: urnA, Message: "creating", Severity: diag.Info, Ephemeral: true}}
+		events <- engine.Event{Type: engine.ResourceOutputsEvent,
+			Payload: engine.ResourceOutputsEventPayload{Metadata: step(deploy.OpCreate, urnA)}}
+		events <- engine.Event{Type: engine.ResourceOutputsEvent,
+			Payload: engine.ResourceOutputsEventPayload{Metadata: step(deploy.OpCreate, urnA)}}
+		component := step(deploy.OpCreate, urnD)
+		component.Res = &engine.StepEventStateMetadata{URN: urnD, Type: urnD.Type()}
+		events <- engine.Event{Type: engine.ResourcePreEvent,
+			Payload: engine.ResourcePreEventPayload{Metadata: component}}
+		events <- engine.Event{Type: engine.DiagEvent,
+			Payload: engine.DiagEventPayload{URN: urnB, Message: "boom", Severity: diag.Error}}
+		events <- engine.Event{Type: engine.ResourceOperationFailed,
+			Payload: engine.ResourceOperationFailedPayload{Metadata: step(deploy.OpUpdate, urnB)}}
+		events <- engine.Event{Type: engine.SummaryEvent, Payload: engine.SummaryEventPayload{
+			ResourceChanges: engine.ResourceChanges{deploy.OpCreate: 1}}}
+		events <- engine.Event{Type: engine.CancelEvent}
+		close(events)
+	}()
+
+	var buf bytes.Buffer
+	err := writeJSONProgress(&buf, "update", events, Options{ExpectedSteps: 4}, false /*isPreview*/)
+	assert.NoError(t, err)
+
+	var progress []progressEvent
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event progressEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		assert.Equal(t, "update", event.Operation)
+		assert.Equal(t, 4, event.Total)
+		progress = append(progress, event)
+	}
+
+	type summary struct {
+		Type    string
+		URN     resource.URN
+		Done    int
+		Percent int
+	}
+	var summaries []summary
+	for _, event := range progress {
+		summaries = append(summaries, summary{event.Type, event.URN, event.Done, *event.Percent})
+	}
+	assert.Equal(t, []summary{
+		{progressStarted, "", 0, 0},
+		{progressStepStarted, urnA, 0, 0},
+		{progressStepFinished, urnA, 1, 25},
+		{progressStepFinished, urnA, 1, 25},
+		// Component resources are done as soon as they start.
+		{progressStepStarted, urnD, 2, 50},
+		{progressDiagnostic, urnB, 2, 50},
+		{progressStepFailed, urnB, 3, 75},
+		{progressFinished, "", 3, 100},
+	}, summaries)
+	assert.Equal(t, diag.Error, progress[5].Severity)
+	assert.Equal(t, "boom", progress[5].Message)
+	assert.Equal(t, engine.ResourceChanges{deploy.OpCreate: 1}, progress[7].Summary.ChangeSummary)
+}
@@ -14,7 +14,10 @@
 
 package display
 
-import "github.com/pulumi/pulumi/pkg/diag/colors"
+import (
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+)
 
 // Options controls how the output of events are rendered
 type Options struct {
@@ -22,9 +25,23 @@ type Options struct {
 	ShowConfig           bool                // true if we should show configuration information.
 	ShowReplacementSteps bool                // true to show the replacement steps in the plan.
 	ShowSameResources    bool                // true to show the resources that aren't updated in addition to updates.
+	ShowTimings          bool                // true to show where the time went after an update.
 	SuppressOutputs      bool                // true to suppress output summarization, e.g. if contains sensitive info.
 	SummaryDiff          bool                // If the diff display should be summarized
 	IsInteractive        bool                // If we should display things interactively
 	DiffDisplay          bool                // true if we should display things as a rich diff
+	JSONDisplay          bool                // true if we should emit the entire plan as JSON
+	JSONProgress         bool                // true if we should emit progress as newline-delimited JSON events
+	ExpectedSteps        int                 // the number of steps the operation is expected to take, if known.
 	Debug                bool                // true to enable debug output.
+	EventLogPath         string              // an optional file to which to append every event as a line of JSON.
+
+	// Permalink and ResourceURL link to the update and to its resources in the backend's web console, if it has one.
+	Permalink   string
+	ResourceURL func(urn resource.URN) string
+}
+
+// IsJSON returns true if the display is written to stdout as JSON, in which case nothing else may be written there.
+func (opts Options) IsJSON() bool {
+	return opts.JSONDisplay || opts.JSONProgress
 }
@@ -114,6 +114,15 @@ type ProgressDisplay struct {
 	// Any system events we've received.  They will be printed at the bottom of all the status rows
 	systemEventPayloads []engine.StdoutEventPayload
 
+	// The last few lines of diagnostic output we've received.  While the update is running, these are
+	// shown in the tree-view below the status rows, so that messages aren't lost when a resource's
+	// info column moves on to its next message.
+	recentDiagnostics []string
+
+	// The ID one past the last line of the recent diagnostics pane we've printed, so that we can clear
+	// the pane out when we're done.
+	recentDiagnosticsEndID int
+
 	// Used to record the order that rows are created in.  That way, when we present in a tree, we
 	// can keep things ordered so they will not jump around.
 	displayOrderCounter int
@@ -161,6 +170,9 @@ type ProgressDisplay struct {
 	printedProgressCache map[string]Progress
 }
 
+// maxRecentDiagnostics is the number of lines of diagnostic output shown below the status rows in the tree-view.
+const maxRecentDiagnostics = 5
+
 var (
 	// simple regex to take our names like "aws:function:Function" and convert to
 	// "aws:Function"
@@ -623,6 +635,65 @@ func (display *ProgressDisplay) refreshAllRowsIfInTerminal() {
 				systemID++
 			}
 		}
+
+		display.refreshRecentDiagnostics(systemID)
+	}
+}
+
+// refreshRecentDiagnostics prints the recent diagnostics pane, starting at the given line ID.  Once the display is
+// done, the pane is blanked out instead, as all diagnostics are then printed in full below the status rows.
+func (display *ProgressDisplay) refreshRecentDiagnostics(id int) {
+	if display.done {
+		for ; id < display.recentDiagnosticsEndID; id++ {
+			display.colorizeAndWriteProgress(makeActionProgress(fmt.Sprintf("%v", id), " "))
+		}
+		return
+	}
+
+	if len(display.recentDiagnostics) == 0 {
+		return
+	}
+
+	display.colorizeAndWriteProgress(makeActionProgress(fmt.Sprintf("%v", id), " "))
+	id++
+	display.colorizeAndWriteProgress(makeActionProgress(
+		fmt.Sprintf("%v", id), colors.Yellow+"Recent Diagnostics"+colors.Reset))
+	id++
+
+	maxMsgLength := display.terminalWidth - 1
+	if maxMsgLength < 0 {
+		maxMsgLength = 0
+	}
+	for _, line := range display.recentDiagnostics {
+		msg := colors.TrimColorizedString("  "+line, maxMsgLength)
+		display.colorizeAndWriteProgress(makeActionProgress(fmt.Sprintf("%v", id), msg))
+		id++
+	}
+
+	if id > display.recentDiagnosticsEndID {
+		display.recentDiagnosticsEndID = id
+	}
+}
+
+// recordRecentDiagnostic adds a diagnostic message to the recent diagnostics pane, dropping the oldest lines once
+// the pane is full.
+func (display *ProgressDisplay) recordRecentDiagnostic(payload engine.DiagEventPayload, msg string) {
+	name := display.stack.String()
+	if payload.URN != "" {
+		name = string(payload.URN.Name())
+	}
+
+	for _, line := range splitIntoDisplayableLines(msg) {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			continue
+		}
+		display.recentDiagnostics = append(display.recentDiagnostics,
+			colors.BrightBlue+name+":"+colors.Reset+" "+line)
+	}
+
+	if extra := len(display.recentDiagnostics) - maxRecentDiagnostics; extra > 0 {
+		display.recentDiagnostics = display.recentDiagnostics[extra:]
 	}
 }
 
@@ -867,10 +938,14 @@ func (display *ProgressDisplay) processNormalEvent(event engine.Event) {
 		display.summaryEventPayload = &payload
 		return
 	case engine.DiagEvent:
-		msg := display.renderProgressDiagEvent(event.Payload.(engine.DiagEventPayload), true /*includePrefix:*/)
+		payload := event.Payload.(engine.DiagEventPayload)
+		msg := display.renderProgressDiagEvent(payload, true /*includePrefix:*/)
 		if msg == "" {
 			return
 		}
+		if display.isTerminal {
+			display.recordRecentDiagnostic(payload, msg)
+		}
 	case engine.StdoutColorEvent:
 		display.handleSystemEvent(event.Payload.(engine.StdoutEventPayload))
 		return
@@ -1045,6 +1120,8 @@ func (display *ProgressDisplay) getStepDoneDescription(step engine.StepEventMeta
 				return "refreshing failed"
 			case deploy.OpReadDiscard, deploy.OpDiscardReplaced:
 				return "discarding failed"
+			case deploy.OpImport:
+				return "importing failed"
 			}
 		} else {
 			switch op {
@@ -1073,6 +1150,8 @@ func (display *ProgressDisplay) getStepDoneDescription(step engine.StepEventMeta
 				return "discarded"
 			case deploy.OpDiscardReplaced:
 				return "discarded original"
+			case deploy.OpImport:
+				return "imported"
 			}
 		}
 
@@ -1114,6 +1193,8 @@ func (display *ProgressDisplay) getPreviewText(step engine.StepEventMetadata) st
 		return "discard"
 	case deploy.OpDiscardReplaced:
 		return "discard origina;"
+	case deploy.OpImport:
+		return "import"
 	}
 
 	contract.Failf("Unrecognized resource step op: %v", step.Op)
@@ -1142,6 +1223,8 @@ func (display *ProgressDisplay) getPreviewDoneText(step engine.StepEventMetadata
 		return "refresh"
 	case deploy.OpReadDiscard:
 		return "discard"
+	case deploy.OpImport:
+		return "import"
 	}
 
 	contract.Failf("Unrecognized resource step op: %v", step.Op)
@@ -1216,6 +1299,8 @@ func (display *ProgressDisplay) getStepInProgressDescription(step engine.StepEve
 			return "discarding"
 		case deploy.OpDiscardReplaced:
 			return "discarding original"
+		case deploy.OpImport:
+			return "importing"
 		}
 
 		contract.Failf("Unrecognized resource step op: %v", op)
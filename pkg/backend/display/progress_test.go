@@ -0,0 +1,120 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+// newTestProgressDisplay returns a display whose output may be read from the returned channel.
+func newTestProgressDisplay(isTerminal bool) (*ProgressDisplay, chan Progress) {
+	output := make(chan Progress, 100)
+	return &ProgressDisplay{
+		opts:                 Options{Color: colors.Never},
+		stack:                "dev",
+		isTerminal:           isTerminal,
+		terminalWidth:        80,
+		progressOutput:       output,
+		printedProgressCache: make(map[string]Progress),
+	}, output
+}
+
+// drainProgress returns the messages written to a display's output so far, keyed by line ID.
+func drainProgress(output chan Progress) map[string]string {
+	lines := make(map[string]string)
+	for {
+		select {
+		case p := <-output:
+			lines[p.ID] = p.Action
+		default:
+			return lines
+		}
+	}
+}
+
+func TestRecentDiagnostics(t *testing.T) {
+	display, output := newTestProgressDisplay(true)
+	urn := resource.URN("urn:pulumi:test::proj::pkg:m:typ::a")
+
+	// Each non-blank line is attributed to its resource, or to the stack if it has none.
+	display.recordRecentDiagnostic(engine.DiagEventPayload{URN: urn}, "first\n\nsecond  \n")
+	display.recordRecentDiagnostic(engine.DiagEventPayload{}, "third")
+	assert.Equal(t, []string{
+		colors.BrightBlue + "a:" + colors.Reset + " first",
+		colors.BrightBlue + "a:" + colors.Reset + " second",
+		colors.BrightBlue + "dev:" + colors.Reset + " third",
+	}, display.recentDiagnostics)
+
+	// Only the most recent lines are kept.
+	for i := 0; i < maxRecentDiagnostics; i++ {
+		display.recordRecentDiagnostic(engine.DiagEventPayload{URN: urn}, fmt.Sprintf("line %d", i))
+	}
+	assert.Len(t, display.recentDiagnostics, maxRecentDiagnostics)
+	assert.Equal(t, colors.BrightBlue+"a:"+colors.Reset+" line 0", display.recentDiagnostics[0])
+
+	// The pane is printed below the given line, under a heading.
+	display.refreshRecentDiagnostics(10)
+	lines := drainProgress(output)
+	assert.Len(t, lines, maxRecentDiagnostics+2)
+	assert.Equal(t, "Recent Diagnostics", lines["11"])
+	assert.Equal(t, "  a: line 0", lines["12"])
+	assert.Equal(t, 10+maxRecentDiagnostics+2, display.recentDiagnosticsEndID)
+
+	// Once the display is done, the pane is blanked out. The blank line above the heading is left as it is.
+	display.done = true
+	display.refreshRecentDiagnostics(10)
+	lines = drainProgress(output)
+	assert.Len(t, lines, maxRecentDiagnostics+1)
+	for _, line := range lines {
+		assert.Equal(t, " ", line)
+	}
+}
+
+func TestElapsedTime(t *testing.T) {
+	urn := resource.URN("urn:pulumi:test::proj::pkg:m:typ::a")
+	newRow := func(display *ProgressDisplay, op deploy.StepOp) *resourceRowData {
+		row := &resourceRowData{display: display}
+		row.SetStep(engine.StepEventMetadata{Op: op, URN: urn, Type: urn.Type()})
+		return row
+	}
+
+	// Steps that do something are timed, from when they start until they are done.
+	display, _ := newTestProgressDisplay(true)
+	row := newRow(display, deploy.OpCreate)
+	assert.False(t, row.start.IsZero())
+	row.start = row.start.Add(-90 * time.Second)
+	assert.Equal(t, "1m30s", row.getElapsedTime())
+	row.SetFailed()
+	assert.Equal(t, "1m30s", row.getElapsedTime())
+	assert.False(t, row.end.IsZero())
+	row.start = row.start.Add(-time.Hour)
+	assert.Equal(t, "1h1m30s", row.getElapsedTime())
+
+	// Steps that do nothing, previews, and the non-interactive display show no times.
+	assert.Equal(t, "", newRow(display, deploy.OpSame).getElapsedTime())
+	display.isPreview = true
+	assert.Equal(t, "", newRow(display, deploy.OpCreate).getElapsedTime())
+	nonInteractive, _ := newTestProgressDisplay(false)
+	assert.Equal(t, "", newRow(nonInteractive, deploy.OpCreate).getElapsedTime())
+}
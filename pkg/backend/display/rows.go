@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize/english"
 
@@ -127,6 +128,11 @@ type resourceRowData struct {
 	// If we failed this operation for any reason.
 	failed bool
 
+	// When the engine started and finished applying this row's step.  Only tracked for steps that
+	// actually do something, so that we can show how long each one took.
+	start time.Time
+	end   time.Time
+
 	diagInfo *DiagInfo
 
 	// If this row should be hidden by default.  We will hide unless we have any child nodes
@@ -163,6 +169,9 @@ func (data *resourceRowData) SetStep(step engine.StepEventMetadata) {
 	if step.Op == deploy.OpRefresh {
 		data.diffOutputs = true
 	}
+	if data.start.IsZero() && !data.display.isPreview && step.Op != deploy.OpSame {
+		data.start = time.Now()
+	}
 }
 
 func (data *resourceRowData) AddOutputStep(step engine.StepEventMetadata) {
@@ -301,11 +310,32 @@ func (data *resourceRowData) ColorizedColumns() []string {
 	} else {
 		columns[statusColumn] = data.display.getStepInProgressDescription(step)
 	}
+	if elapsed := data.getElapsedTime(); elapsed != "" && columns[statusColumn] != "" {
+		columns[statusColumn] += " (" + elapsed + ")"
+	}
 
 	columns[infoColumn] = data.getInfoColumn()
 	return columns
 }
 
+// getElapsedTime returns how long this row's step has been running, or how long it took if it is done.  Times are
+// only shown in the tree-view, where they are updated as the display ticks; the non-interactive display prints each
+// row as it changes, so it has nothing to show there.
+func (data *resourceRowData) getElapsedTime() string {
+	if !data.display.isTerminal || data.start.IsZero() || isRootURN(data.step.URN) {
+		return ""
+	}
+
+	end := data.end
+	if end.IsZero() {
+		end = time.Now()
+		if data.IsDone() {
+			data.end = end
+		}
+	}
+	return end.Sub(data.start).Truncate(time.Second).String()
+}
+
 func (data *resourceRowData) getInfoColumn() string {
 	step := data.step
 	if step.Op == deploy.OpCreateReplacement || step.Op == deploy.OpDeleteReplaced {
@@ -331,6 +361,9 @@ func (data *resourceRowData) getInfoColumn() string {
 	if colors.Never.Colorize(changes) != "" {
 		appendDiagMessage("[" + changes + "]")
 	}
+	if reasons := engine.GetReplaceReasons(step); reasons != "" {
+		appendDiagMessage("[" + deploy.OpReplace.Color() + "replace: " + reasons + colors.Reset + "]")
+	}
 
 	diagInfo := data.diagInfo
 	if data.display.done {
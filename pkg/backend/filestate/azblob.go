@@ -0,0 +1,474 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// azureBackendURLPrefix is the URL scheme of backends that store state in an Azure Blob Storage container, in the form
+// azblob://<container>/<prefix>.
+const azureBackendURLPrefix = "azblob://"
+
+const (
+	// AzureStorageConnectionStringEnvVar may be set to the connection string of the storage account in which an
+	// azblob:// backend stores state. It takes precedence over the other Azure environment variables.
+	AzureStorageConnectionStringEnvVar = "AZURE_STORAGE_CONNECTION_STRING"
+	// AzureStorageAccountEnvVar names the storage account in which an azblob:// backend stores state.
+	AzureStorageAccountEnvVar = "AZURE_STORAGE_ACCOUNT"
+	// AzureStorageKeyEnvVar may be set to an access key for the storage account.
+	AzureStorageKeyEnvVar = "AZURE_STORAGE_KEY"
+	// AzureStorageSASTokenEnvVar may be set to a shared access signature that grants access to the container.
+	AzureStorageSASTokenEnvVar = "AZURE_STORAGE_SAS_TOKEN"
+
+	// AzureTenantIDEnvVar may be set to the Azure AD tenant of a service principal with access to the container. Azure
+	// AD credentials are only used if neither a key nor a shared access signature is available.
+	AzureTenantIDEnvVar = "AZURE_TENANT_ID"
+	// AzureClientIDEnvVar may be set to the application ID of the service principal.
+	AzureClientIDEnvVar = "AZURE_CLIENT_ID"
+	// AzureClientSecretEnvVar may be set to a client secret of the service principal.
+	AzureClientSecretEnvVar = "AZURE_CLIENT_SECRET"
+	// AzureAuthorityHostEnvVar optionally overrides the Azure AD endpoint from which tokens are requested.
+	AzureAuthorityHostEnvVar = "AZURE_AUTHORITY_HOST"
+)
+
+const (
+	azureDefaultEndpointSuffix = "core.windows.net"
+	azureDefaultAuthorityHost  = "https://login.microsoftonline.com"
+	azureStorageScope          = "https://storage.azure.com/.default"
+
+	// The well-known account and key of the local storage emulator, used by "UseDevelopmentStorage=true".
+	azureDevelopmentAccount  = "devstoreaccount1"
+	azureDevelopmentEndpoint = "http://127.0.0.1:10000/devstoreaccount1"
+	azureDevelopmentKey      = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/" +
+		"K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+// azureCredentials describes how to reach and authorize requests to the blob service of a storage account.
+type azureCredentials struct {
+	endpoint   string             // the blob service endpoint, e.g. https://account.blob.core.windows.net.
+	credential azblob.Credential  // the credential with which requests are signed.
+	sas        string             // a shared access signature that is added to each request's URL, if any.
+	tokens     oauth2.TokenSource // the source of the credential's Azure AD tokens, if it is a token credential.
+}
+
+// azureBucket is a bucket that stores objects as block blobs in an Azure Blob Storage container.
+type azureBucket struct {
+	container azblob.ContainerURL
+	name      string                 // the name of the container.
+	prefix    string                 // the prefix of the names of the bucket's blobs within the container, if any.
+	token     azblob.TokenCredential // the credential whose token is refreshed before each request, if any.
+	tokens    oauth2.TokenSource
+}
+
+// newAzureBucket creates a bucket for the given azblob:// URL, using credentials found in the environment.
+func newAzureBucket(bucketURL string) (*azureBucket, error) {
+	contract.Require(strings.HasPrefix(bucketURL, azureBackendURLPrefix), "bucketURL")
+
+	rest := strings.Trim(bucketURL[len(azureBackendURLPrefix):], "/")
+	container, prefix := rest, ""
+	if slash := strings.Index(rest, "/"); slash != -1 {
+		container, prefix = rest[:slash], rest[slash+1:]
+	}
+	if container == "" {
+		return nil, errors.Errorf("%s is missing a container name; expected %s<container>[/<prefix>]",
+			bucketURL, azureBackendURLPrefix)
+	}
+
+	creds, err := azureCredentialsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return newAzureBucketWithCredentials(creds, container, prefix)
+}
+
+// newAzureBucketWithCredentials creates a bucket for the given container and prefix, using the given credentials.
+func newAzureBucketWithCredentials(creds azureCredentials, container, prefix string) (*azureBucket, error) {
+	containerURL, err := url.Parse(creds.endpoint + "/" + url.PathEscape(container))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the endpoint of Azure storage container '%s'", container)
+	}
+	containerURL.RawQuery = strings.TrimPrefix(creds.sas, "?")
+
+	p := azblob.NewPipeline(creds.credential, azblob.PipelineOptions{
+		Retry: azblob.RetryOptions{
+			Policy:        azblob.RetryPolicyExponential,
+			MaxTries:      int32(bucketRetryOpts.MaxRetryCount),
+			RetryDelay:    bucketRetryOpts.Delay,
+			MaxRetryDelay: bucketRetryOpts.MaxDelay,
+		},
+		HTTPSender: azureHTTPSender,
+	})
+	b := &azureBucket{
+		container: azblob.NewContainerURL(*containerURL, p),
+		name:      container,
+		prefix:    prefix,
+		tokens:    creds.tokens,
+	}
+	if creds.tokens != nil {
+		b.token = creds.credential.(azblob.TokenCredential)
+	}
+	return b, nil
+}
+
+// azureHTTPSender sends the requests of Azure storage pipelines with http.DefaultClient, rather than with the client of
+// the pipeline package, so that they use the transport set up by httputil.ConfigureDefaultTransport, which trusts any
+// CA certificates given by PULUMI_EXTRA_CA_CERTS.
+var azureHTTPSender = pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+	return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+		resp, err := http.DefaultClient.Do(request.WithContext(ctx))
+		if err != nil {
+			err = pipeline.NewError(err, "HTTP request failed")
+		}
+		return pipeline.NewHTTPResponse(resp), err
+	}
+})
+
+// azureCredentialsFromEnv returns the credentials for the storage account configured in the environment.
+func azureCredentialsFromEnv() (azureCredentials, error) {
+	if connectionString := os.Getenv(AzureStorageConnectionStringEnvVar); connectionString != "" {
+		return parseAzureConnectionString(connectionString)
+	}
+
+	account := os.Getenv(AzureStorageAccountEnvVar)
+	if account == "" {
+		return azureCredentials{}, errors.Errorf(
+			"storing state in Azure Blob Storage requires either %s or %s to be set",
+			AzureStorageConnectionStringEnvVar, AzureStorageAccountEnvVar)
+	}
+	endpoint := fmt.Sprintf("https://%s.blob.%s", account, azureDefaultEndpointSuffix)
+
+	if key := os.Getenv(AzureStorageKeyEnvVar); key != "" {
+		return newAzureSharedKeyCredentials(endpoint, account, key)
+	}
+	if token := os.Getenv(AzureStorageSASTokenEnvVar); token != "" {
+		return azureCredentials{endpoint: endpoint, credential: azblob.NewAnonymousCredential(), sas: token}, nil
+	}
+
+	tenant, client, secret :=
+		os.Getenv(AzureTenantIDEnvVar), os.Getenv(AzureClientIDEnvVar), os.Getenv(AzureClientSecretEnvVar)
+	if tenant != "" && client != "" && secret != "" {
+		authority := os.Getenv(AzureAuthorityHostEnvVar)
+		if authority == "" {
+			authority = azureDefaultAuthorityHost
+		}
+		config := clientcredentials.Config{
+			ClientID:     client,
+			ClientSecret: secret,
+			TokenURL: fmt.Sprintf("%s/%s/oauth2/v2.0/token",
+				strings.TrimSuffix(authority, "/"), url.PathEscape(tenant)),
+			Scopes: []string{azureStorageScope},
+		}
+		return azureCredentials{
+			endpoint:   endpoint,
+			credential: azblob.NewTokenCredential("", nil),
+			tokens:     config.TokenSource(context.Background()),
+		}, nil
+	}
+
+	return azureCredentials{}, errors.Errorf(
+		"no credentials found for Azure storage account '%s'; set %s, %s, or %s, %s, and %s",
+		account, AzureStorageKeyEnvVar, AzureStorageSASTokenEnvVar,
+		AzureTenantIDEnvVar, AzureClientIDEnvVar, AzureClientSecretEnvVar)
+}
+
+// parseAzureConnectionString returns the credentials described by an Azure Storage connection string, which is a
+// semicolon-separated list of key=value settings.
+func parseAzureConnectionString(connectionString string) (azureCredentials, error) {
+	settings := make(map[string]string)
+	for _, setting := range strings.Split(connectionString, ";") {
+		if setting = strings.TrimSpace(setting); setting == "" {
+			continue
+		}
+		eq := strings.Index(setting, "=")
+		if eq == -1 {
+			return azureCredentials{}, errors.New(
+				"the Azure Storage connection string is malformed; expected key=value settings")
+		}
+		settings[strings.ToLower(setting[:eq])] = setting[eq+1:]
+	}
+
+	if strings.EqualFold(settings["usedevelopmentstorage"], "true") {
+		return newAzureSharedKeyCredentials(azureDevelopmentEndpoint, azureDevelopmentAccount, azureDevelopmentKey)
+	}
+
+	account := settings["accountname"]
+	endpoint := strings.TrimSuffix(settings["blobendpoint"], "/")
+	if endpoint == "" {
+		if account == "" {
+			return azureCredentials{}, errors.New(
+				"the Azure Storage connection string must specify AccountName or BlobEndpoint")
+		}
+		protocol, suffix := settings["defaultendpointsprotocol"], settings["endpointsuffix"]
+		if protocol == "" {
+			protocol = "https"
+		}
+		if suffix == "" {
+			suffix = azureDefaultEndpointSuffix
+		}
+		endpoint = fmt.Sprintf("%s://%s.blob.%s", protocol, account, suffix)
+	}
+
+	switch {
+	case settings["accountkey"] != "":
+		if account == "" {
+			return azureCredentials{}, errors.New(
+				"the Azure Storage connection string must specify the AccountName of its AccountKey")
+		}
+		return newAzureSharedKeyCredentials(endpoint, account, settings["accountkey"])
+	case settings["sharedaccesssignature"] != "":
+		return azureCredentials{
+			endpoint:   endpoint,
+			credential: azblob.NewAnonymousCredential(),
+			sas:        settings["sharedaccesssignature"],
+		}, nil
+	default:
+		return azureCredentials{}, errors.New(
+			"the Azure Storage connection string must specify either AccountKey or SharedAccessSignature")
+	}
+}
+
+// newAzureSharedKeyCredentials returns credentials that sign requests with a storage account access key.
+func newAzureSharedKeyCredentials(endpoint, account, key string) (azureCredentials, error) {
+	credential, err := azblob.NewSharedKeyCredential(account, key)
+	if err != nil {
+		return azureCredentials{}, errors.Wrapf(err, "decoding the access key of Azure storage account '%s'", account)
+	}
+	return azureCredentials{endpoint: endpoint, credential: credential}, nil
+}
+
+// blobName returns the name of the blob that holds the object with the given key.
+func (b *azureBucket) blobName(key string) string {
+	return path.Join(b.prefix, key)
+}
+
+// blob returns the URL of the blob that holds the object with the given key.
+func (b *azureBucket) blob(key string) azblob.BlockBlobURL {
+	return b.container.NewBlockBlobURL(b.blobName(key))
+}
+
+func (b *azureBucket) URL(key string) string {
+	return azureBackendURLPrefix + path.Join(b.name, b.blobName(key))
+}
+
+func (b *azureBucket) Location(key string) string {
+	return b.URL(key)
+}
+
+// authorize refreshes the bucket's Azure AD token, if it uses one, before a request is made.
+func (b *azureBucket) authorize() error {
+	if b.tokens == nil {
+		return nil
+	}
+	token, err := b.tokens.Token()
+	if err != nil {
+		return errors.Wrap(err, "requesting a token from Azure AD")
+	}
+	b.token.SetToken(token.AccessToken)
+	return nil
+}
+
+// translateError converts an error returned by the Blob Storage service for a request about the object with the given
+// key. Errors that indicate that the blob does not exist satisfy os.IsNotExist, and those that indicate that it
+// already exists satisfy os.IsExist.
+func (b *azureBucket) translateError(op, key string, err error) error {
+	serr, ok := err.(azblob.StorageError)
+	if !ok {
+		return errors.Wrapf(err, "%s %s", op, b.URL(key))
+	}
+
+	switch serr.ServiceCode() {
+	case azblob.ServiceCodeBlobNotFound:
+		return &os.PathError{Op: op, Path: b.URL(key), Err: os.ErrNotExist}
+	case azblob.ServiceCodeBlobAlreadyExists:
+		return &os.PathError{Op: op, Path: b.URL(key), Err: os.ErrExist}
+	default:
+		// The service's own description of the error includes the request, whose URL may be signed.
+		status := "failed"
+		if resp := serr.Response(); resp != nil {
+			status = resp.Status
+		}
+		return errors.Errorf("%s %s failed: %s (%s)", op, b.URL(key), status, serr.ServiceCode())
+	}
+}
+
+func (b *azureBucket) NewReader(key string) (io.ReadCloser, error) {
+	if err := b.authorize(); err != nil {
+		return nil, err
+	}
+	resp, err := b.blob(key).Download(context.Background(), 0, azblob.CountToEnd, azblob.BlobAccessConditions{},
+		false)
+	if err != nil {
+		return nil, b.translateError("get", key, err)
+	}
+	return resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: bucketRetryOpts.MaxRetryCount}), nil
+}
+
+// put uploads the contents of the blob that holds the object with the given key, subject to the given conditions.
+func (b *azureBucket) put(key string, data []byte, conditions azblob.BlobAccessConditions) error {
+	if err := b.authorize(); err != nil {
+		return err
+	}
+	_, err := azblob.UploadBufferToBlockBlob(context.Background(), data, b.blob(key),
+		azblob.UploadToBlockBlobOptions{AccessConditions: conditions})
+	if err != nil {
+		return b.translateError("put", key, err)
+	}
+	return nil
+}
+
+// azureBlobWriter buffers the contents of a blob, and uploads them when it is closed.
+type azureBlobWriter struct {
+	bytes.Buffer
+	bucket *azureBucket
+	key    string
+}
+
+func (w *azureBlobWriter) Close() error {
+	return w.bucket.put(w.key, w.Bytes(), azblob.BlobAccessConditions{})
+}
+
+func (b *azureBucket) NewWriter(key string) (io.WriteCloser, error) {
+	return &azureBlobWriter{bucket: b, key: key}, nil
+}
+
+func (b *azureBucket) Create(key string, data []byte) error {
+	// The service only writes the blob if it does not already exist.
+	err := b.put(key, data, azblob.BlobAccessConditions{
+		ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfNoneMatch: azblob.ETagAny},
+	})
+	if os.IsExist(err) {
+		// If the request was retried after the service wrote the blob but before we heard back, the retry fails even
+		// though the blob holds what we wrote.
+		if existing, readErr := readObject(b, key); readErr == nil && bytes.Equal(existing, data) {
+			return nil
+		}
+	}
+	return err
+}
+
+// Rename copies the blob to its new name and then deletes the original, as Blob Storage cannot rename blobs. This is
+// not atomic: if the delete fails, both blobs are left behind. The original is only deleted if it has not been
+// written since it was copied, so that a concurrent write is never lost.
+func (b *azureBucket) Rename(from, to string) error {
+	if err := b.authorize(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	resp, err := b.blob(from).Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
+	if err != nil {
+		return b.translateError("get", from, err)
+	}
+	var data bytes.Buffer
+	body := resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: bucketRetryOpts.MaxRetryCount})
+	_, err = data.ReadFrom(body)
+	contract.IgnoreClose(body)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", b.URL(from))
+	}
+
+	if err = b.put(to, data.Bytes(), azblob.BlobAccessConditions{}); err != nil {
+		return err
+	}
+	_, err = b.blob(from).Delete(ctx, azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{
+		ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfMatch: resp.ETag()},
+	})
+	if err != nil {
+		return b.translateError("delete", from, err)
+	}
+	return nil
+}
+
+func (b *azureBucket) Delete(key string) error {
+	if err := b.authorize(); err != nil {
+		return err
+	}
+	_, err := b.blob(key).Delete(context.Background(), azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{})
+	if err != nil {
+		return b.translateError("delete", key, err)
+	}
+	return nil
+}
+
+func (b *azureBucket) DeleteAll(dir string) error {
+	blobs, err := b.list(dir, false)
+	if err != nil {
+		return err
+	}
+	for _, blob := range blobs {
+		if err = b.Delete(path.Join(dir, blob)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *azureBucket) List(dir string) ([]string, error) {
+	return b.list(dir, true)
+}
+
+// list returns the names, relative to the given directory, of the blobs beneath it. If shallow is true, blobs in
+// subdirectories are not included.
+func (b *azureBucket) list(dir string, shallow bool) ([]string, error) {
+	prefix := b.blobName(dir) + "/"
+	if prefix == "/" {
+		prefix = ""
+	}
+	if err := b.authorize(); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	ctx, options := context.Background(), azblob.ListBlobsSegmentOptions{Prefix: prefix}
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		var blobs []azblob.BlobItem
+		if shallow {
+			resp, err := b.container.ListBlobsHierarchySegment(ctx, marker, "/", options)
+			if err != nil {
+				return nil, b.translateError("list", dir, err)
+			}
+			blobs, marker = resp.Segment.BlobItems, resp.NextMarker
+		} else {
+			resp, err := b.container.ListBlobsFlatSegment(ctx, marker, options)
+			if err != nil {
+				return nil, b.translateError("list", dir, err)
+			}
+			blobs, marker = resp.Segment.BlobItems, resp.NextMarker
+		}
+
+		for _, blob := range blobs {
+			names = append(names, strings.TrimPrefix(blob.Name, prefix))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,232 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAzureConnectionString(t *testing.T) {
+	creds, err := parseAzureConnectionString(
+		"DefaultEndpointsProtocol=https;AccountName=acct;AccountKey=a2V5;EndpointSuffix=core.chinacloudapi.cn")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acct.blob.core.chinacloudapi.cn", creds.endpoint)
+	if assert.IsType(t, &azblob.SharedKeyCredential{}, creds.credential) {
+		assert.Equal(t, "acct", creds.credential.(*azblob.SharedKeyCredential).AccountName())
+	}
+
+	creds, err = parseAzureConnectionString(
+		"BlobEndpoint=https://acct.blob.core.windows.net/;SharedAccessSignature=sv=2018-11-09&sig=abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acct.blob.core.windows.net", creds.endpoint)
+	assert.Equal(t, "sv=2018-11-09&sig=abc", creds.sas)
+
+	creds, err = parseAzureConnectionString("UseDevelopmentStorage=true")
+	assert.NoError(t, err)
+	assert.Equal(t, azureDevelopmentEndpoint, creds.endpoint)
+
+	_, err = parseAzureConnectionString("AccountName=acct")
+	assert.Error(t, err)
+	_, err = parseAzureConnectionString("AccountName=acct;AccountKey")
+	assert.Error(t, err)
+	_, err = parseAzureConnectionString("AccountName=acct;AccountKey=not base64")
+	assert.Error(t, err)
+}
+
+// fakeBlobService is a minimal in-memory implementation of the Blob Storage service for a single container.
+type fakeBlobService struct {
+	t         *testing.T
+	account   string
+	container string
+
+	lock     sync.Mutex
+	blobs    map[string][]byte
+	etags    map[string]string
+	versions int
+
+	afterGet func(name string) // called, with the lock held, after each blob is read.
+}
+
+func newFakeBlobService(t *testing.T, account, container string) *fakeBlobService {
+	return &fakeBlobService{t: t, account: account, container: container,
+		blobs: make(map[string][]byte), etags: make(map[string]string)}
+}
+
+// fail responds to a request with the given status and Blob Storage error code.
+func (s *fakeBlobService) fail(w http.ResponseWriter, status int, code azblob.ServiceCodeType) {
+	w.Header().Set("x-ms-error-code", string(code))
+	w.WriteHeader(status)
+}
+
+func (s *fakeBlobService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The SDK signs requests itself, so only check that they are signed with the account's key.
+	if !assert.True(s.t, strings.HasPrefix(req.Header.Get("Authorization"), "SharedKey "+s.account+":")) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	prefix := "/" + s.account + "/" + s.container
+	assert.True(s.t, strings.HasPrefix(req.URL.Path, prefix))
+	name := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, prefix), "/")
+
+	query := req.URL.Query()
+	switch {
+	case req.Method == "GET" && query.Get("comp") == "list":
+		type blob struct {
+			Name string `xml:"Name"`
+		}
+		var result struct {
+			XMLName    xml.Name `xml:"EnumerationResults"`
+			Blobs      []blob   `xml:"Blobs>Blob"`
+			NextMarker string   `xml:"NextMarker"`
+		}
+		var names []string
+		for name := range s.blobs {
+			rest := strings.TrimPrefix(name, query.Get("prefix"))
+			delimiter := query.Get("delimiter")
+			if strings.HasPrefix(name, query.Get("prefix")) && (delimiter == "" || !strings.Contains(rest, delimiter)) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result.Blobs = append(result.Blobs, blob{Name: name})
+		}
+		byts, err := xml.Marshal(result)
+		assert.NoError(s.t, err)
+		_, err = w.Write(byts)
+		assert.NoError(s.t, err)
+	case req.Method == "GET":
+		data, has := s.blobs[name]
+		if !has {
+			s.fail(w, http.StatusNotFound, azblob.ServiceCodeBlobNotFound)
+			return
+		}
+		w.Header().Set("ETag", s.etags[name])
+		_, err := w.Write(data)
+		assert.NoError(s.t, err)
+		if s.afterGet != nil {
+			s.afterGet(name)
+		}
+	case req.Method == "PUT":
+		if _, has := s.blobs[name]; has && req.Header.Get("If-None-Match") == "*" {
+			s.fail(w, http.StatusConflict, azblob.ServiceCodeBlobAlreadyExists)
+			return
+		}
+		assert.Equal(s.t, "BlockBlob", req.Header.Get("x-ms-blob-type"))
+		data, err := ioutil.ReadAll(req.Body)
+		assert.NoError(s.t, err)
+		s.versions++
+		s.blobs[name], s.etags[name] = data, fmt.Sprintf(`"%d"`, s.versions)
+		w.Header().Set("ETag", s.etags[name])
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == "DELETE":
+		if _, has := s.blobs[name]; !has {
+			s.fail(w, http.StatusNotFound, azblob.ServiceCodeBlobNotFound)
+			return
+		}
+		if etag := req.Header.Get("If-Match"); etag != "" && etag != s.etags[name] {
+			s.fail(w, http.StatusPreconditionFailed, azblob.ServiceCodeConditionNotMet)
+			return
+		}
+		delete(s.blobs, name)
+		delete(s.etags, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// newFakeAzureBucket returns a bucket that stores blobs beneath the given prefix in the given fake service.
+func newFakeAzureBucket(t *testing.T, server *httptest.Server, prefix string) *azureBucket {
+	creds, err := newAzureSharedKeyCredentials(server.URL+"/acct", "acct", "a2V5")
+	assert.NoError(t, err)
+	b, err := newAzureBucketWithCredentials(creds, "state", prefix)
+	assert.NoError(t, err)
+	return b
+}
+
+func TestAzureBucket(t *testing.T) {
+	service := newFakeBlobService(t, "acct", "state")
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	b := newFakeAzureBucket(t, server, "team/prod")
+	assert.Equal(t, "azblob://state/team/prod/.pulumi/stacks/dev.json", b.URL(".pulumi/stacks/dev.json"))
+	testBucket(t, b)
+	testBucketBackend(t, b)
+
+	// Blobs are stored beneath the bucket's prefix, if any.
+	service.blobs = make(map[string][]byte)
+	assert.NoError(t, writeObject(b, "a b/c.json", []byte("c")))
+	assert.NoError(t, writeObject(newFakeAzureBucket(t, server, ""), "d.json", []byte("d")))
+	assert.Equal(t, map[string][]byte{"team/prod/a b/c.json": []byte("c"), "d.json": []byte("d")}, service.blobs)
+}
+
+func TestAzureBucketRename(t *testing.T) {
+	service := newFakeBlobService(t, "acct", "state")
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	b := newFakeAzureBucket(t, server, "")
+	assert.NoError(t, writeObject(b, "a.json", []byte("a")))
+	assert.NoError(t, b.Rename("a.json", "b.json"))
+	assert.Equal(t, map[string][]byte{"b.json": []byte("a")}, service.blobs)
+
+	// If the original is written while it is being copied, it is left in place rather than deleted.
+	service.afterGet = func(name string) {
+		service.blobs[name], service.etags[name] = []byte("b"), `"concurrent"`
+	}
+	assert.Error(t, b.Rename("b.json", "c.json"))
+	assert.Equal(t, map[string][]byte{"b.json": []byte("b"), "c.json": []byte("a")}, service.blobs)
+}
+
+func TestAzureBucketDefaultTransport(t *testing.T) {
+	defer useTestUploads()()
+	service := newFakeBlobService(t, "acct", "state")
+	server := httptest.NewTLSServer(service)
+	defer server.Close()
+
+	// Requests are made with http.DefaultTransport, which here is the only transport that trusts the server.
+	defer func(transport http.RoundTripper) { http.DefaultTransport = transport }(http.DefaultTransport)
+	http.DefaultTransport = server.Client().Transport
+
+	b := newFakeAzureBucket(t, server, "")
+	assert.NoError(t, writeObject(b, "a.json", []byte("a")))
+	assert.Equal(t, map[string][]byte{"a.json": []byte("a")}, service.blobs)
+}
+
+func TestAzureBucketRetries(t *testing.T) {
+	defer useTestUploads()()
+	service := newFakeBlobService(t, "acct", "state")
+	server := httptest.NewServer(newFlakyHandler(service))
+	defer server.Close()
+
+	testFlakyBucket(t, func() bucket { return newFakeAzureBucket(t, server, "") })
+}
@@ -18,11 +18,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"os/user"
-	"path/filepath"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -50,13 +50,25 @@ const localBackendURLPrefix = "file://"
 // Backend extends the base backend interface with specific information about local backends.
 type Backend interface {
 	backend.Backend
-	local() // at the moment, no local specific info, so just use a marker function.
+	local() // a marker function, to distinguish local backends from other backends.
+
+	// CancelCurrentUpdate removes the lock left behind by an update of the given stack that did not complete, e.g.
+	// because the process performing it was killed.
+	CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference) error
 }
 
 type localBackend struct {
 	d               diag.Sink
 	url             string
+	bucket          bucket // the storage in which the backend keeps its state.
 	stackConfigFile string
+	readOnly        bool // true if the backend was logged into in read-only mode.
+
+	crypters    map[tokens.QName]config.Crypter // cached checkpoint crypters, keyed by stack name.
+	crypterLock sync.Mutex                      // a lock protecting the crypters map.
+
+	serials    map[tokens.QName]int64 // the checkpoint serial numbers we expect to find, keyed by stack name.
+	serialLock sync.Mutex             // a lock protecting the serials map.
 }
 
 type localBackendReference struct {
@@ -71,18 +83,56 @@ func (r localBackendReference) Name() tokens.QName {
 	return r.name
 }
 
+// IsLocalBackendURL returns true if the given URL refers to a backend whose state is managed by the CLI itself, rather
+// than by a service: a directory on the local filesystem (file://), an Azure Blob Storage container (azblob://), a
+// Google Cloud Storage bucket (gs://), or a service that speaks the REST state protocol (rest+https://).
 func IsLocalBackendURL(url string) bool {
+	return strings.HasPrefix(url, localBackendURLPrefix) || strings.HasPrefix(url, azureBackendURLPrefix) ||
+		strings.HasPrefix(url, gcsBackendURLPrefix) || strings.HasPrefix(url, restBackendURLPrefix)
+}
+
+// IsFileBackendURL returns true if the given URL refers to a backend whose state is stored on the local filesystem, and
+// which may therefore be used without network access.
+func IsFileBackendURL(url string) bool {
 	return strings.HasPrefix(url, localBackendURLPrefix)
 }
 
 func New(d diag.Sink, url, stackConfigFile string) (Backend, error) {
-	if !IsLocalBackendURL(url) {
-		return nil, errors.Errorf("local URL %s has an illegal prefix; expected %s", url, localBackendURLPrefix)
+	var bucket bucket
+	switch {
+	case strings.HasPrefix(url, localBackendURLPrefix):
+		bucket = newLocalBucket(localBackendDir(url))
+	case strings.HasPrefix(url, azureBackendURLPrefix):
+		azureBucket, err := newAzureBucket(url)
+		if err != nil {
+			return nil, err
+		}
+		bucket = azureBucket
+	case strings.HasPrefix(url, gcsBackendURLPrefix):
+		gcsBucket, err := newGCSBucket(url)
+		if err != nil {
+			return nil, err
+		}
+		bucket = gcsBucket
+	case strings.HasPrefix(url, restBackendURLPrefix):
+		restBucket, err := newRESTBucket(url)
+		if err != nil {
+			return nil, err
+		}
+		bucket = restBucket
+	default:
+		return nil, errors.Errorf("local URL %s has an illegal prefix; expected %s, %s, %s, or %s",
+			url, localBackendURLPrefix, azureBackendURLPrefix, gcsBackendURLPrefix, restBackendURLPrefix+"https://")
 	}
+
 	return &localBackend{
 		d:               d,
 		url:             url,
+		bucket:          bucket,
 		stackConfigFile: stackConfigFile,
+		readOnly:        workspace.IsReadOnlyBackend(url),
+		crypters:        make(map[tokens.QName]config.Crypter),
+		serials:         make(map[tokens.QName]int64),
 	}, nil
 }
 
@@ -91,6 +141,12 @@ func Login(d diag.Sink, url, stackConfigFile string) (Backend, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// Make sure that the backend's state can be read before remembering it, so that mistakes in the URL or in the
+	// credentials used to access it are reported right away.
+	if _, err = be.(*localBackend).bucket.List(stacksDirectory()); err != nil {
+		return nil, errors.Wrapf(err, "reading the state stored at %s", url)
+	}
 	return be, workspace.StoreAccessToken(url, "", true)
 }
 
@@ -109,8 +165,13 @@ func (b *localBackend) URL() string {
 	return b.url
 }
 
-func (b *localBackend) Dir() string {
-	path := b.url[len(localBackendURLPrefix):]
+func (b *localBackend) ReadOnly() bool {
+	return b.readOnly || backend.ReadOnly
+}
+
+// localBackendDir returns the directory on the local filesystem beneath which a file:// backend stores its state.
+func localBackendDir(url string) string {
+	path := url[len(localBackendURLPrefix):]
 	if path == "~" {
 		user, err := user.Current()
 		contract.AssertNoErrorf(err, "could not determine current user")
@@ -123,11 +184,6 @@ func (b *localBackend) Dir() string {
 	return path
 }
 
-func (b *localBackend) StateDir() string {
-	dir := b.Dir()
-	return filepath.Join(dir, workspace.BookkeepingDir)
-}
-
 func (b *localBackend) ParseStackReference(stackRefName string) (backend.StackReference, error) {
 	return localBackendReference{name: tokens.QName(stackRefName)}, nil
 }
@@ -137,6 +193,10 @@ func (b *localBackend) CreateStack(ctx context.Context, stackRef backend.StackRe
 
 	contract.Requiref(opts == nil, "opts", "local stacks do not support any options")
 
+	if err := backend.CheckWritable(b, "create stack '%s'", stackRef); err != nil {
+		return nil, err
+	}
+
 	stackName := stackRef.Name()
 	if stackName == "" {
 		return nil, errors.New("invalid empty stack name")
@@ -200,6 +260,10 @@ func (b *localBackend) ListStacks(
 }
 
 func (b *localBackend) RemoveStack(ctx context.Context, stackRef backend.StackReference, force bool) (bool, error) {
+	if err := backend.CheckWritable(b, "remove stack '%s'", stackRef); err != nil {
+		return false, err
+	}
+
 	stackName := stackRef.Name()
 	_, snapshot, _, err := b.getStack(stackName)
 	if err != nil {
@@ -243,7 +307,7 @@ func (b *localBackend) Preview(ctx context.Context, stackRef backend.StackRefere
 	// We can skip PreviewThenPromptThenExecute and just go straight to Execute.
 	opts := backend.ApplierOptions{
 		DryRun:   true,
-		ShowLink: true,
+		ShowLink: !op.Opts.Display.IsJSON(),
 	}
 	return b.apply(ctx, apitype.PreviewUpdate, stack, op, opts, nil /*events*/)
 }
@@ -283,8 +347,20 @@ func (b *localBackend) apply(ctx context.Context, kind apitype.UpdateKind, stack
 
 	// Print a banner so it's clear this is a local deployment.
 	actionLabel := backend.ActionLabel(kind, opts.DryRun)
-	fmt.Printf(op.Opts.Display.Color.Colorize(
-		colors.SpecHeadline+"%s (%s):"+colors.Reset+"\n"), actionLabel, stackRef)
+	if !op.Opts.Display.IsJSON() {
+		fmt.Printf(op.Opts.Display.Color.Colorize(
+			colors.SpecHeadline+"%s (%s):"+colors.Reset+"\n"), actionLabel, stackRef)
+	}
+
+	// Lock the stack for the duration of the update, so that concurrent updates cannot clobber one another.
+	if !opts.DryRun {
+		if err := b.lockStack(stackName, kind); err != nil {
+			return nil, err
+		}
+		defer func() {
+			contract.IgnoreError(b.unlockStack(stackName))
+		}()
+	}
 
 	// Start the update.
 	update, err := b.newUpdate(stackName, op.Proj, op.Root)
@@ -292,6 +368,19 @@ func (b *localBackend) apply(ctx context.Context, kind apitype.UpdateKind, stack
 		return nil, err
 	}
 
+	// Since there is no service to do so, tell the project's webhooks, if any, that the update is starting.
+	webhookEvent := backend.WebhookEvent{
+		Event:     workspace.WebhookUpdateStarted,
+		Project:   op.Proj.Name,
+		Stack:     string(stackName),
+		Kind:      kind,
+		StartTime: time.Now().Unix(),
+		Message:   op.M.Message,
+	}
+	if !opts.DryRun {
+		backend.SendWebhooks(ctx, b.d, op.Proj.Webhooks, webhookEvent)
+	}
+
 	// Spawn a display loop to show events on the CLI.
 	displayEvents := make(chan engine.Event)
 	displayDone := make(chan bool)
@@ -304,11 +393,17 @@ func (b *localBackend) apply(ctx context.Context, kind apitype.UpdateKind, stack
 
 	scope := op.Scopes.NewScope(engineEvents, opts.DryRun)
 	eventsDone := make(chan bool)
+	var timings *engine.TimingReport
 	go func() {
 		// Pull in all events from the engine and send them to the two listeners.
 		for e := range engineEvents {
 			displayEvents <- e
 
+			// Keep the update's timing report so that it can be saved in the stack's history.
+			if e.Type == engine.SummaryEvent {
+				timings = e.Payload.(engine.SummaryEventPayload).Timings
+			}
+
 			// If the caller also wants to see the events, stream them there also.
 			if events != nil {
 				events <- e
@@ -373,6 +468,7 @@ func (b *localBackend) apply(ctx context.Context, kind apitype.UpdateKind, stack
 		//     rudely assume it knows where the checkpoint file is on disk as it makes a copy of it.  This isn't
 		//     trivial to achieve today given the event driven nature of plan-walking, however.
 		ResourceChanges: changes,
+		Timings:         timings,
 	}
 
 	var saveErr error
@@ -380,6 +476,13 @@ func (b *localBackend) apply(ctx context.Context, kind apitype.UpdateKind, stack
 	if !opts.DryRun {
 		saveErr = b.addToHistory(stackName, info)
 		backupErr = b.backupStack(stackName)
+
+		webhookEvent.Event, webhookEvent.EndTime = workspace.WebhookUpdateSucceeded, end
+		webhookEvent.ResourceChanges = changes
+		if updateErr != nil {
+			webhookEvent.Event, webhookEvent.Error = workspace.WebhookUpdateFailed, updateErr.Error()
+		}
+		backend.SendWebhooks(ctx, b.d, op.Proj.Webhooks, webhookEvent)
 	}
 
 	if updateErr != nil {
@@ -401,7 +504,8 @@ func (b *localBackend) apply(ctx context.Context, kind apitype.UpdateKind, stack
 		fmt.Printf(
 			op.Opts.Display.Color.Colorize(
 				colors.SpecHeadline+"Permalink: "+
-					colors.Underline+colors.BrightBlue+"file://%s"+colors.Reset+"\n"), stack.(*localStack).Path())
+					colors.Underline+colors.BrightBlue+"%s"+colors.Reset+"\n"),
+			b.bucket.URL(stack.(*localStack).Path()))
 	}
 
 	return changes, nil
@@ -425,11 +529,14 @@ func (b *localBackend) GetLogs(ctx context.Context, stackRef backend.StackRefere
 		return nil, err
 	}
 
-	return GetLogsForTarget(target, query)
+	return GetLogsForTarget(ctx, target, query)
 }
 
-// GetLogsForTarget fetches stack logs using the config, decrypter, and checkpoint in the given target.
-func GetLogsForTarget(target *deploy.Target, query operations.LogQuery) ([]operations.LogEntry, error) {
+// GetLogsForTarget fetches stack logs using the config, decrypter, and checkpoint in the given target. Provider plugins
+// that serve logs are kept loaded by the log session carried by the given context, if there is one.
+func GetLogsForTarget(ctx context.Context, target *deploy.Target,
+	query operations.LogQuery) ([]operations.LogEntry, error) {
+
 	contract.Assert(target != nil)
 	contract.Assert(target.Snapshot != nil)
 
@@ -438,8 +545,20 @@ func GetLogsForTarget(target *deploy.Target, query operations.LogQuery) ([]opera
 		return nil, err
 	}
 
+	// Resources that the CLI has no built-in support for may get their logs from their provider plugins. Without a
+	// session to keep those plugins loaded for later calls, they are only needed for this one.
+	session := backend.LogSessionFromContext(ctx)
+	if session == nil {
+		session = backend.NewLogSession()
+		defer contract.IgnoreClose(session)
+	}
+	providers, err := session.Providers()
+	if err != nil {
+		return nil, err
+	}
+
 	components := operations.NewResourceTree(target.Snapshot.Resources)
-	ops := components.OperationsProvider(config)
+	ops := components.PluginOperationsProvider(config, providers)
 	logs, err := ops.GetLogs(query)
 	if logs == nil {
 		return nil, err
@@ -471,9 +590,36 @@ func (b *localBackend) ExportDeployment(ctx context.Context,
 	}, nil
 }
 
+func (b *localBackend) ExportHistoricalDeployment(ctx context.Context,
+	stackRef backend.StackReference, version int) (*apitype.UntypedDeployment, error) {
+
+	snap, err := b.getHistoricalCheckpoint(stackRef.Name(), version)
+	if err != nil {
+		return nil, err
+	}
+
+	if snap == nil {
+		snap = deploy.NewSnapshot(deploy.Manifest{}, nil, nil)
+	}
+
+	data, err := json.Marshal(stack.SerializeDeployment(snap))
+	if err != nil {
+		return nil, err
+	}
+
+	return &apitype.UntypedDeployment{
+		Version:    3,
+		Deployment: json.RawMessage(data),
+	}, nil
+}
+
 func (b *localBackend) ImportDeployment(ctx context.Context, stackRef backend.StackReference,
 	deployment *apitype.UntypedDeployment) error {
 
+	if err := backend.CheckWritable(b, "import a deployment into stack '%s'", stackRef); err != nil {
+		return err
+	}
+
 	stackName := stackRef.Name()
 	config, _, _, err := b.getStack(stackName)
 	if err != nil {
@@ -505,22 +651,14 @@ func (b *localBackend) getLocalStacks() ([]tokens.QName, error) {
 	var stacks []tokens.QName
 
 	// Read the stack directory.
-	path := b.stackPath("")
-
-	files, err := ioutil.ReadDir(path)
-	if err != nil && !os.IsNotExist(err) {
+	files, err := b.bucket.List(stacksDirectory())
+	if err != nil {
 		return nil, errors.Errorf("could not read stacks: %v", err)
 	}
 
-	for _, file := range files {
-		// Ignore directories.
-		if file.IsDir() {
-			continue
-		}
-
+	for _, stackfn := range files {
 		// Skip files without valid extensions (e.g., *.bak files).
-		stackfn := file.Name()
-		ext := filepath.Ext(stackfn)
+		ext := path.Ext(stackfn)
 		if _, has := encoding.Marshalers[ext]; !has {
 			continue
 		}
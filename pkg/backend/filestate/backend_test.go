@@ -0,0 +1,90 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestReadOnlyBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-read-only")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	be := &localBackend{
+		url:      "file://" + dir,
+		bucket:   newLocalBucket(dir),
+		crypters: make(map[tokens.QName]config.Crypter),
+		serials:  make(map[tokens.QName]int64),
+	}
+	ctx := context.Background()
+	ref := localBackendReference{name: "dev"}
+	_, err = be.CreateStack(ctx, ref, nil)
+	assert.NoError(t, err)
+
+	// Stacks can still be read, but not changed.
+	be.readOnly = true
+	_, err = be.GetStack(ctx, ref)
+	assert.NoError(t, err)
+	deployment, err := be.ExportDeployment(ctx, ref)
+	assert.NoError(t, err)
+
+	_, err = be.CreateStack(ctx, localBackendReference{name: "prod"}, nil)
+	assert.IsType(t, backend.ReadOnlyError{}, err)
+	assert.IsType(t, backend.ReadOnlyError{}, be.ImportDeployment(ctx, ref, deployment))
+	assert.IsType(t, backend.ReadOnlyError{}, be.CancelCurrentUpdate(ctx, ref))
+	_, err = be.RemoveStack(ctx, ref, true)
+	assert.IsType(t, backend.ReadOnlyError{}, err)
+	stack, err := be.GetStack(ctx, ref)
+	assert.NoError(t, err)
+	_, err = backend.PreviewThenPromptThenExecute(
+		ctx, apitype.DestroyUpdate, stack, backend.UpdateOperation{}, be.apply)
+	assert.IsType(t, backend.ReadOnlyError{}, err)
+
+	// The same goes for every backend while ReadOnly is set.
+	be.readOnly = false
+	backend.ReadOnly = true
+	defer func() { backend.ReadOnly = false }()
+	_, err = be.RemoveStack(ctx, ref, true)
+	assert.IsType(t, backend.ReadOnlyError{}, err)
+}
+
+func TestBackendURLs(t *testing.T) {
+	for _, url := range []string{"file://~", "file:///tmp/state"} {
+		assert.True(t, IsLocalBackendURL(url))
+		assert.True(t, IsFileBackendURL(url))
+	}
+
+	// Backends in cloud object stores are managed by the CLI, but need the network.
+	for _, url := range []string{"azblob://state/prefix", "gs://state", "rest+https://state.acmecorp.com"} {
+		assert.True(t, IsLocalBackendURL(url))
+		assert.False(t, IsFileBackendURL(url))
+	}
+
+	for _, url := range []string{"", "https://api.pulumi.com", "/tmp/state"} {
+		assert.False(t, IsLocalBackendURL(url))
+		assert.False(t, IsFileBackendURL(url))
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/httputil"
+)
+
+// bucketRetryOpts controls how buckets that store objects in cloud services retry requests that fail transiently.
+// Checkpoints are written throughout an update, so the buckets ride out network failures of a minute or two rather
+// than abandoning the update and losing track of the resources that it has created.
+var bucketRetryOpts = httputil.PersistentRetryOpts
+
+// uploadChunkSize is the size of the chunks in which buckets that store objects in cloud services upload large objects,
+// such as the checkpoints of big stacks. A failure partway through such an upload only requires the chunk that failed
+// to be sent again, rather than the whole object.
+var uploadChunkSize = 8 << 20
+
+// bucket is the storage in which a backend keeps the checkpoints, update history, backups, and locks of its stacks:
+// either a directory on the local filesystem or a container in a cloud object store. Objects are identified by
+// slash-separated keys relative to the root of the bucket.
+type bucket interface {
+	// URL returns a URL at which the object with the given key may be found.
+	URL(key string) string
+	// Location returns a human-readable description of where the object with the given key is stored, for use in
+	// messages.
+	Location(key string) string
+
+	// NewReader opens the object with the given key for reading. If the object does not exist, the error satisfies
+	// os.IsNotExist.
+	NewReader(key string) (io.ReadCloser, error)
+	// NewWriter returns a writer that replaces the contents of the object with the given key. The object is not
+	// guaranteed to have been written until the writer has been closed successfully.
+	NewWriter(key string) (io.WriteCloser, error)
+	// Create writes an object with the given key and contents, failing with an error that satisfies os.IsExist if an
+	// object with that key already exists.
+	Create(key string, data []byte) error
+	// Rename moves the object with the given key to another key, replacing any object that is already there. This
+	// need not be atomic; if it fails, the object may be left at either key or at both.
+	Rename(from, to string) error
+	// Delete removes the object with the given key. If the object does not exist, the error satisfies os.IsNotExist.
+	Delete(key string) error
+	// DeleteAll removes every object beneath the given directory.
+	DeleteAll(dir string) error
+	// List returns the names of the objects directly beneath the given directory, in lexical order. Subdirectories are
+	// not included. If the directory does not exist, the result is empty.
+	List(dir string) ([]string, error)
+}
+
+// readObject reads the entire contents of the object with the given key.
+func readObject(b bucket, key string) ([]byte, error) {
+	r, err := b.NewReader(key)
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(r)
+	return ioutil.ReadAll(r)
+}
+
+// writeObject replaces the contents of the object with the given key.
+func writeObject(b bucket, key string, data []byte) error {
+	w, err := b.NewWriter(key)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// objectExists returns true if an object with the given key exists.
+func objectExists(b bucket, key string) (bool, error) {
+	r, err := b.NewReader(key)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	contract.IgnoreClose(r)
+	return true, nil
+}
+
+// localBucket is a bucket that stores objects as files underneath a directory on the local filesystem.
+type localBucket struct {
+	root string
+}
+
+func newLocalBucket(root string) *localBucket {
+	return &localBucket{root: root}
+}
+
+func (b *localBucket) path(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(key))
+}
+
+func (b *localBucket) URL(key string) string {
+	return localBackendURLPrefix + filepath.ToSlash(b.path(key))
+}
+
+func (b *localBucket) Location(key string) string {
+	return b.path(key)
+}
+
+func (b *localBucket) NewReader(key string) (io.ReadCloser, error) {
+	return os.Open(b.path(key))
+}
+
+func (b *localBucket) NewWriter(key string) (io.WriteCloser, error) {
+	path := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+}
+
+func (b *localBucket) Create(key string, data []byte) error {
+	path := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Creating the file exclusively ensures that only one process can create it.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		contract.IgnoreError(os.Remove(path))
+	}
+	return err
+}
+
+func (b *localBucket) Rename(from, to string) error {
+	return os.Rename(b.path(from), b.path(to))
+}
+
+func (b *localBucket) Delete(key string) error {
+	return os.Remove(b.path(key))
+}
+
+func (b *localBucket) DeleteAll(dir string) error {
+	return os.RemoveAll(b.path(dir))
+}
+
+func (b *localBucket) List(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(b.path(dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
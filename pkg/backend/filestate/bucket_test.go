@@ -0,0 +1,183 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/httputil"
+)
+
+// flakyHandler wraps a fake cloud storage service, failing a quarter of requests with 503 Service Unavailable before
+// they reach the service. A further quarter of writes reach the service, but their responses are lost and replaced with
+// 503 Service Unavailable.
+type flakyHandler struct {
+	handler http.Handler
+
+	lock   sync.Mutex
+	random *rand.Rand
+}
+
+func newFlakyHandler(handler http.Handler) *flakyHandler {
+	return &flakyHandler{handler: handler, random: rand.New(rand.NewSource(1))}
+}
+
+func (h *flakyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.lock.Lock()
+	n := h.random.Intn(4)
+	h.lock.Unlock()
+
+	switch {
+	case n == 0:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case n == 1 && (req.Method == "PUT" || req.Method == "POST"):
+		h.handler.ServeHTTP(httptest.NewRecorder(), req)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		h.handler.ServeHTTP(w, req)
+	}
+}
+
+// useTestUploads makes buckets upload objects in tiny chunks, and retry failed requests many times without delay, until
+// the returned function is called.
+func useTestUploads() func() {
+	oldChunkSize, oldRetryOpts := uploadChunkSize, bucketRetryOpts
+	uploadChunkSize = 16
+	bucketRetryOpts = httputil.RetryOpts{
+		MaxRetryCount: 20,
+		Delay:         time.Millisecond,
+		Backoff:       1,
+		MaxDelay:      time.Millisecond,
+	}
+	return func() {
+		uploadChunkSize, bucketRetryOpts = oldChunkSize, oldRetryOpts
+	}
+}
+
+// testFlakyBucket checks that the given empty bucket, whose service fails intermittently, still behaves as the bucket
+// interface requires, including for objects that it uploads in chunks.
+func testFlakyBucket(t *testing.T, newBucket func() bucket) {
+	testBucket(t, newBucket())
+	testBucketBackend(t, newBucket())
+
+	b := newBucket()
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	assert.NoError(t, writeObject(b, "big.json", data))
+	assert.NoError(t, writeObject(b, "big.json", data[1:]))
+	byts, err := readObject(newBucket(), "big.json")
+	assert.NoError(t, err)
+	assert.Equal(t, data[1:], byts)
+}
+
+// testBucket checks that the given empty bucket behaves as the bucket interface requires.
+func testBucket(t *testing.T, b bucket) {
+	// Objects that have not been written do not exist.
+	_, err := b.NewReader("a/b.json")
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, os.IsNotExist(b.Delete("a/b.json")))
+	names, err := b.List("a")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	// Written objects can be read back, and listed alongside one another but not alongside their subdirectories.
+	assert.NoError(t, writeObject(b, "a/b.json", []byte("b")))
+	assert.NoError(t, writeObject(b, "a/c.json", []byte("c")))
+	assert.NoError(t, writeObject(b, "a/d/e.json", []byte("e")))
+	byts, err := readObject(b, "a/b.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(byts))
+	names, err = b.List("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b.json", "c.json"}, names)
+
+	// Creating an object only succeeds if it does not exist yet.
+	assert.NoError(t, b.Create("a/lock.json", []byte("first")))
+	assert.True(t, os.IsExist(b.Create("a/lock.json", []byte("second"))))
+	byts, err = readObject(b, "a/lock.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", string(byts))
+	assert.NoError(t, b.Delete("a/lock.json"))
+
+	// Renaming an object replaces the object at its new key.
+	assert.NoError(t, b.Rename("a/b.json", "a/c.json"))
+	exists, err := objectExists(b, "a/b.json")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	byts, err = readObject(b, "a/c.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(byts))
+
+	// Deleting a directory deletes everything beneath it.
+	assert.NoError(t, b.DeleteAll("a"))
+	for _, key := range []string{"a/c.json", "a/d/e.json"} {
+		exists, err = objectExists(b, key)
+		assert.NoError(t, err)
+		assert.False(t, exists, key)
+	}
+}
+
+// testBucketBackend checks that a backend that stores its state in the given empty bucket can save and lock stacks.
+func testBucketBackend(t *testing.T, b bucket) {
+	be := &localBackend{
+		bucket:   b,
+		crypters: make(map[tokens.QName]config.Crypter),
+		serials:  make(map[tokens.QName]int64),
+	}
+
+	_, err := be.saveStack("dev", nil, nil)
+	assert.NoError(t, err)
+	stacks, err := be.getLocalStacks()
+	assert.NoError(t, err)
+	assert.Equal(t, []tokens.QName{"dev"}, stacks)
+
+	assert.NoError(t, be.lockStack("dev", apitype.UpdateUpdate))
+	err = be.lockStack("dev", apitype.UpdateUpdate)
+	if assert.IsType(t, backend.UpdateConflictError{}, err) {
+		conflict := err.(backend.UpdateConflictError)
+		assert.Equal(t, apitype.UpdateUpdate, conflict.Kind)
+		assert.Equal(t, os.Getpid(), conflict.PID)
+	}
+	assert.NoError(t, be.unlockStack("dev"))
+	assert.NoError(t, be.lockStack("dev", apitype.UpdateUpdate))
+	assert.NoError(t, be.unlockStack("dev"))
+
+	assert.NoError(t, be.removeStack("dev"))
+	stacks, err = be.getLocalStacks()
+	assert.NoError(t, err)
+	assert.Empty(t, stacks)
+}
+
+func TestLocalBucket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-bucket")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	testBucket(t, newLocalBucket(dir))
+	testBucketBackend(t, newLocalBucket(dir))
+}
@@ -0,0 +1,176 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// The checkpoints recorded in a stack's update history are stored as content-addressed chunks, so that the parts of a
+// checkpoint that have not changed since an earlier update are neither uploaded nor stored again. Each historical
+// checkpoint is described by a manifest that lists its chunks in order; the chunks themselves are stored beneath the
+// stack's history directory, named by the SHA-256 hashes of their contents.
+//
+// Chunk boundaries are chosen by the contents of the checkpoint rather than by offset, using a rolling "gear" hash,
+// so that inserting or removing a resource only changes the chunks around it rather than shifting every boundary
+// after it.
+const (
+	minChunkSize = 2 << 10  // chunks are at least this large, except for the last one.
+	maxChunkSize = 64 << 10 // chunks are at most this large.
+	// chunkMask selects the bits of the hash that must be clear after a byte for a chunk boundary to follow it, which
+	// places boundaries 8KiB apart on average.
+	chunkMask = 1<<13 - 1
+
+	// historyChunksDir is the name of the directory beneath a stack's history directory that holds the chunks.
+	historyChunksDir = "chunks"
+)
+
+// gearTable holds the pseudo-random value that each byte contributes to the rolling hash. The values are fixed, so
+// that every version of the CLI splits a checkpoint in the same way.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x50756c756d690000) // a splitmix64 generator with a fixed seed.
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// splitChunks splits data into content-defined chunks.
+func splitChunks(data []byte) [][]byte {
+	var chunks [][]byte
+	for len(data) > 0 {
+		end := len(data)
+		if end > maxChunkSize {
+			end = maxChunkSize
+		}
+		var hash uint64
+		for i := minChunkSize; i < end; i++ {
+			hash = (hash << 1) + gearTable[data[i]]
+			if hash&chunkMask == 0 {
+				end = i + 1
+				break
+			}
+		}
+		chunks = append(chunks, data[:end])
+		data = data[end:]
+	}
+	return chunks
+}
+
+// chunkManifest describes a checkpoint that is stored as chunks.
+type chunkManifest struct {
+	Size   int      `json:"size"`   // the size of the checkpoint, in bytes.
+	SHA256 string   `json:"sha256"` // the hex-encoded SHA-256 hash of the checkpoint.
+	Chunks []string `json:"chunks"` // the hashes of the checkpoint's chunks, in order.
+}
+
+// hashBytes returns the hex-encoded SHA-256 hash of the given data.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// historyChunksDirectory returns the directory in which the chunks of the given stack's historical checkpoints are
+// stored. Keeping them beneath the history directory means that they are removed along with the stack's history.
+func (b *localBackend) historyChunksDirectory(name tokens.QName) string {
+	return path.Join(b.historyDirectory(name), historyChunksDir)
+}
+
+// writeChunkedCheckpoint stores the given checkpoint as chunks of the given stack's history, uploading only the chunks
+// that are not stored already, and then writes a manifest describing it to the given path.
+func (b *localBackend) writeChunkedCheckpoint(name tokens.QName, manifestPath string, data []byte) error {
+	dir := b.historyChunksDirectory(name)
+	existing, err := b.bucket.List(dir)
+	if err != nil {
+		return err
+	}
+	stored := make(map[string]bool, len(existing))
+	for _, chunk := range existing {
+		stored[chunk] = true
+	}
+
+	manifest := chunkManifest{Size: len(data), SHA256: hashBytes(data)}
+	uploaded := 0
+	for _, chunk := range splitChunks(data) {
+		hash := hashBytes(chunk)
+		manifest.Chunks = append(manifest.Chunks, hash)
+		if stored[hash] {
+			continue
+		}
+		// Chunks are immutable, so one that another process has just stored is as good as one that we store.
+		if err = b.bucket.Create(path.Join(dir, hash), chunk); err != nil && !os.IsExist(err) {
+			return err
+		}
+		stored[hash] = true
+		uploaded++
+	}
+	logging.V(7).Infof("stored checkpoint %s as %d chunks, %d of which were new",
+		b.bucket.Location(manifestPath), len(manifest.Chunks), uploaded)
+
+	byts, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err = writeObject(b.bucket, manifestPath, byts); err != nil {
+		return err
+	}
+	// The manifest holds the hash of the whole checkpoint, so signing it signs the checkpoint.
+	return b.signCheckpoint(manifestPath, byts)
+}
+
+// readChunkedCheckpoint reassembles the checkpoint described by the manifest at the given path from the given stack's
+// history chunks, after checking the manifest's signature. If there is no manifest, the error satisfies os.IsNotExist.
+func (b *localBackend) readChunkedCheckpoint(name tokens.QName, manifestPath string) ([]byte, error) {
+	byts, err := b.readSignedCheckpoint(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var manifest chunkManifest
+	if err = json.Unmarshal(byts, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", b.bucket.Location(manifestPath))
+	}
+
+	dir := b.historyChunksDirectory(name)
+	data := bytes.NewBuffer(make([]byte, 0, manifest.Size))
+	for _, hash := range manifest.Chunks {
+		chunk, err := readObject(b.bucket, path.Join(dir, hash))
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("checkpoint %s is missing its chunk %s", b.bucket.Location(manifestPath), hash)
+		} else if err != nil {
+			return nil, err
+		}
+		data.Write(chunk)
+	}
+	if data.Len() != manifest.Size || hashBytes(data.Bytes()) != manifest.SHA256 {
+		return nil, errors.Errorf("checkpoint %s is corrupt: its chunks do not match its manifest",
+			b.bucket.Location(manifestPath))
+	}
+	return data.Bytes(), nil
+}
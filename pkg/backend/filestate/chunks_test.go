@@ -0,0 +1,140 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// chunkHashes returns the set of the hashes of the chunks into which the given data is split.
+func chunkHashes(data []byte) map[string]bool {
+	hashes := make(map[string]bool)
+	for _, chunk := range splitChunks(data) {
+		hashes[hashBytes(chunk)] = true
+	}
+	return hashes
+}
+
+func TestSplitChunks(t *testing.T) {
+	data := make([]byte, 1<<20)
+	_, err := rand.New(rand.NewSource(42)).Read(data)
+	assert.NoError(t, err)
+
+	// Chunks cover the data exactly, and are neither too small nor too large.
+	chunks := splitChunks(data)
+	assert.Equal(t, data, bytes.Join(chunks, nil))
+	for i, chunk := range chunks {
+		assert.True(t, len(chunk) <= maxChunkSize)
+		if i < len(chunks)-1 {
+			assert.True(t, len(chunk) >= minChunkSize)
+		}
+	}
+	assert.True(t, len(chunks) > 32)
+
+	// Inserting data in the middle only changes the chunks around it.
+	edited := append(append(append([]byte{}, data[:500000]...), "inserted"...), data[500000:]...)
+	original, changed := chunkHashes(data), 0
+	for hash := range chunkHashes(edited) {
+		if !original[hash] {
+			changed++
+		}
+	}
+	assert.True(t, changed <= 2, "%d chunks changed", changed)
+
+	assert.Empty(t, splitChunks(nil))
+}
+
+func TestChunkedHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-chunks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	b := &localBackend{
+		bucket:   newLocalBucket(dir),
+		crypters: make(map[tokens.QName]config.Crypter),
+		serials:  make(map[tokens.QName]int64),
+	}
+
+	// A checkpoint that is large enough to be split into several chunks.
+	cfg := make(config.Map)
+	for i := 0; i < 2000; i++ {
+		cfg[config.MustMakeKey("proj", fmt.Sprintf("key%d", i))] = config.NewValue(fmt.Sprintf("value-%x", i*7919))
+	}
+	save := func() {
+		_, err := b.saveStack("dev", cfg, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, b.addToHistory("dev", backend.UpdateInfo{Kind: "update", Result: backend.SucceededResult}))
+	}
+	countChunks := func() int {
+		chunks, err := b.bucket.List(b.historyChunksDirectory("dev"))
+		assert.NoError(t, err)
+		return len(chunks)
+	}
+
+	save()
+	first := countChunks()
+	assert.True(t, first > 4, "%d chunks", first)
+
+	// Recording an update that changes little of the checkpoint only stores the chunks that changed.
+	cfg[config.MustMakeKey("proj", "key1000")] = config.NewValue("changed")
+	save()
+	assert.True(t, countChunks()-first <= 3, "%d new chunks", countChunks()-first)
+
+	history, err := b.getHistory("dev")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	for version := 1; version <= 2; version++ {
+		_, err = b.getHistoricalCheckpoint("dev", version)
+		assert.NoError(t, err)
+	}
+
+	// Historical checkpoints that were copied whole by older versions of the CLI can still be read.
+	prefixes, err := b.historyFiles("dev")
+	assert.NoError(t, err)
+	byts, err := b.readChunkedCheckpoint("dev", prefixes[0]+".checkpoint.chunks.json")
+	assert.NoError(t, err)
+	assert.NoError(t, b.bucket.Delete(prefixes[0]+".checkpoint.chunks.json"))
+	assert.NoError(t, writeObject(b.bucket, prefixes[0]+".checkpoint.json", byts))
+	_, err = b.getHistoricalCheckpoint("dev", 1)
+	assert.NoError(t, err)
+
+	// Checkpoints with missing chunks are reported as such.
+	chunks, err := b.bucket.List(b.historyChunksDirectory("dev"))
+	assert.NoError(t, err)
+	for _, chunk := range chunks {
+		assert.NoError(t, b.bucket.Delete(path.Join(b.historyChunksDirectory("dev"), chunk)))
+	}
+	_, err = b.getHistoricalCheckpoint("dev", 2)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is missing its chunk")
+
+	// Removing the stack removes its chunks.
+	save()
+	assert.NotZero(t, countChunks())
+	assert.NoError(t, b.removeStack("dev"))
+	assert.Zero(t, countChunks())
+}
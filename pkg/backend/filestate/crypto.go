@@ -17,16 +17,20 @@ package filestate
 import (
 	cryptorand "crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/pkg/errors"
 
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/encoding"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
@@ -135,7 +139,9 @@ func symmetricCrypterFromPhraseAndState(phrase string, state string) (config.Cry
 	decrypter := config.NewSymmetricCrypterFromPassphrase(phrase, salt)
 	decrypted, err := decrypter.DecryptValue(state[indexN(state, ":", 2)+1:])
 	if err != nil || decrypted != "pulumi" {
-		return nil, errors.New("incorrect passphrase")
+		return nil, errutil.WithCode(errutil.ErrConfigIncorrectPassphrase,
+			"set PULUMI_CONFIG_PASSPHRASE to the passphrase that the stack was created with",
+			errors.New("incorrect passphrase"))
 	}
 
 	return decrypter, nil
@@ -156,3 +162,77 @@ func indexN(s string, substr string, n int) int {
 
 	return len(s) - (len(scratch) + len(substr))
 }
+
+// encryptedCheckpoint is the envelope written to disk in place of a checkpoint when checkpoints are encrypted at rest.
+// It deliberately lacks a `checkpoint` member so that CLIs which predate checkpoint encryption fail to load it, rather
+// than mistaking it for an empty stack.
+type encryptedCheckpoint struct {
+	Version             int    `json:"version" yaml:"version"`
+	Serial              int64  `json:"serial,omitempty" yaml:"serial,omitempty"`
+	EncryptedCheckpoint string `json:"encryptedCheckpoint" yaml:"encryptedCheckpoint"`
+}
+
+// isEncryptedCheckpoint returns the ciphertext stored in the given checkpoint file contents and true if the contents
+// are an encrypted checkpoint envelope, and false otherwise.
+func isEncryptedCheckpoint(byts []byte) (string, bool) {
+	var envelope encryptedCheckpoint
+	if err := json.Unmarshal(byts, &envelope); err != nil || envelope.EncryptedCheckpoint == "" {
+		return "", false
+	}
+	return envelope.EncryptedCheckpoint, true
+}
+
+// checkpointCrypter returns the crypter used to encrypt and decrypt the given stack's checkpoint at rest. Deriving
+// a key from a passphrase is deliberately slow, so crypters are cached for the lifetime of the backend.
+func (b *localBackend) checkpointCrypter(stackName tokens.QName) (config.Crypter, error) {
+	b.crypterLock.Lock()
+	defer b.crypterLock.Unlock()
+
+	if crypter, has := b.crypters[stackName]; has {
+		return crypter, nil
+	}
+
+	crypter, err := symmetricCrypter(stackName, b.stackConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	b.crypters[stackName] = crypter
+	return crypter, nil
+}
+
+// encryptCheckpoint encrypts the marshaled checkpoint for the given stack and returns the marshaled envelope that
+// should be written to disk in its place. The envelope carries the checkpoint's serial number in the clear, so that
+// it can be checked without decrypting the checkpoint.
+func (b *localBackend) encryptCheckpoint(stackName tokens.QName, m encoding.Marshaler, byts []byte,
+	serial int64) ([]byte, error) {
+	crypter, err := b.checkpointCrypter(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := crypter.EncryptValue(string(byts))
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Marshal(encryptedCheckpoint{
+		Version:             apitype.DeploymentSchemaVersionCurrent,
+		Serial:              serial,
+		EncryptedCheckpoint: ciphertext,
+	})
+}
+
+// decryptCheckpoint decrypts the ciphertext from an encrypted checkpoint envelope for the given stack, returning
+// the plaintext checkpoint.
+func (b *localBackend) decryptCheckpoint(stackName tokens.QName, ciphertext string) ([]byte, error) {
+	crypter, err := b.checkpointCrypter(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := crypter.DecryptValue(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(plaintext), nil
+}
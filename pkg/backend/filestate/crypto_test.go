@@ -0,0 +1,97 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestIsEncryptedCheckpoint(t *testing.T) {
+	ciphertext, encrypted := isEncryptedCheckpoint([]byte(`{"version":3,"encryptedCheckpoint":"v1:abc"}`))
+	assert.True(t, encrypted)
+	assert.Equal(t, "v1:abc", ciphertext)
+
+	_, encrypted = isEncryptedCheckpoint([]byte(`{"version":3,"checkpoint":{"stack":"dev"}}`))
+	assert.False(t, encrypted)
+	_, encrypted = isEncryptedCheckpoint([]byte(`not json`))
+	assert.False(t, encrypted)
+}
+
+func TestEncryptedCheckpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-encrypted-checkpoints")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Seed the crypter cache, so that no passphrase is prompted for.
+	b := &localBackend{
+		bucket:   newLocalBucket(dir),
+		crypters: map[tokens.QName]config.Crypter{"dev": config.NewSymmetricCrypter(make([]byte, 32))},
+		serials:  make(map[tokens.QName]int64),
+	}
+	cfg := config.Map{config.MustMakeKey("proj", "password"): config.NewValue("hunter2")}
+
+	os.Setenv(EncryptCheckpointsEnvVar, "true")
+	_, err = b.saveStack("dev", cfg, nil)
+	os.Unsetenv(EncryptCheckpointsEnvVar)
+	assert.NoError(t, err)
+
+	// The checkpoint on disk does not contain the plaintext, but can still be read.
+	byts, err := readObject(b.bucket, b.stackPath("dev"))
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(byts), "hunter2"))
+	_, encrypted := isEncryptedCheckpoint(byts)
+	assert.True(t, encrypted)
+
+	actual, _, err := b.getCheckpoint("dev")
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, actual)
+
+	// A checkpoint cannot be read with the wrong key.
+	b.crypters["dev"] = config.NewSymmetricCrypter(append(make([]byte, 31), 1))
+	_, _, err = b.getCheckpoint("dev")
+	assert.Error(t, err)
+}
+
+func TestSymmetricCrypterFromPhraseAndState(t *testing.T) {
+	salt := []byte("saltsalt")
+	msg, err := config.NewSymmetricCrypterFromPassphrase("password", salt).EncryptValue("pulumi")
+	assert.NoError(t, err)
+	state := "v1:" + base64.StdEncoding.EncodeToString(salt) + ":" + msg
+
+	crypter, err := symmetricCrypterFromPhraseAndState("password", state)
+	assert.NoError(t, err)
+	ciphertext, err := crypter.EncryptValue("secret")
+	assert.NoError(t, err)
+	plaintext, err := crypter.DecryptValue(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+
+	_, err = symmetricCrypterFromPhraseAndState("wrong", state)
+	assert.Error(t, err)
+
+	_, err = symmetricCrypterFromPhraseAndState("password", "v2:abc:def")
+	assert.Error(t, err)
+	_, err = symmetricCrypterFromPhraseAndState("password", "v1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,509 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/httputil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// gcsBackendURLPrefix is the URL scheme of backends that store state in a Google Cloud Storage bucket, in the form
+// gs://<bucket>/<prefix>.
+const gcsBackendURLPrefix = "gs://"
+
+const (
+	// GoogleApplicationCredentialsEnvVar may be set to the path of a service account key file, or of an authorized
+	// user's credentials file, with which a gs:// backend accesses its bucket. If it is not set, the application
+	// default credentials of the Cloud SDK are used, or else those of the Compute Engine service account.
+	GoogleApplicationCredentialsEnvVar = "GOOGLE_APPLICATION_CREDENTIALS"
+	// GCSEmulatorHostEnvVar may be set to the host of a Cloud Storage emulator, which is then used without credentials
+	// in place of the Cloud Storage service.
+	GCSEmulatorHostEnvVar = "STORAGE_EMULATOR_HOST"
+)
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsScope    = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+// gcsBucket is a bucket that stores objects in a Google Cloud Storage bucket.
+//
+// The bucket remembers the generation of each object when it first reads the object, and makes subsequent writes and
+// deletes of the object conditional upon its generation being unchanged. This prevents the bucket from overwriting
+// changes that were made by other processes after it read an object, such as a concurrent update of a stack's
+// checkpoint.
+type gcsBucket struct {
+	endpoint string             // the endpoint of the Cloud Storage JSON API.
+	bucket   string             // the bucket in which objects are stored.
+	prefix   string             // the prefix of the names of the objects within the bucket, if any.
+	tokens   oauth2.TokenSource // the source of the tokens with which requests are authorized, if they need them.
+	client   *http.Client
+
+	generationLock sync.Mutex
+	generations    map[string]int64 // the expected generation of each object, keyed by name; zero if it is absent.
+}
+
+// newGCSBucket creates a bucket for the given gs:// URL, using the application default credentials.
+func newGCSBucket(bucketURL string) (*gcsBucket, error) {
+	contract.Require(strings.HasPrefix(bucketURL, gcsBackendURLPrefix), "bucketURL")
+
+	rest := strings.Trim(bucketURL[len(gcsBackendURLPrefix):], "/")
+	bucket, prefix := rest, ""
+	if slash := strings.Index(rest, "/"); slash != -1 {
+		bucket, prefix = rest[:slash], rest[slash+1:]
+	}
+	if bucket == "" {
+		return nil, errors.Errorf("%s is missing a bucket name; expected %s<bucket>[/<prefix>]",
+			bucketURL, gcsBackendURLPrefix)
+	}
+
+	// The application default credentials are those in the file named by GOOGLE_APPLICATION_CREDENTIALS, else those
+	// saved by `gcloud auth application-default login`, else those of the Compute Engine service account.
+	endpoint, tokens := gcsEndpoint, oauth2.TokenSource(nil)
+	if emulator := os.Getenv(GCSEmulatorHostEnvVar); emulator != "" {
+		endpoint = "http://" + emulator
+	} else {
+		creds, err := google.FindDefaultCredentials(context.Background(), gcsScope)
+		if err != nil {
+			return nil, errors.Wrap(err, "finding Google application default credentials")
+		}
+		tokens = creds.TokenSource
+	}
+
+	return &gcsBucket{
+		endpoint:    endpoint,
+		bucket:      bucket,
+		prefix:      prefix,
+		tokens:      tokens,
+		client:      http.DefaultClient,
+		generations: make(map[string]int64),
+	}, nil
+}
+
+// objectName returns the name of the Cloud Storage object that holds the object with the given key.
+func (b *gcsBucket) objectName(key string) string {
+	return path.Join(b.prefix, key)
+}
+
+func (b *gcsBucket) URL(key string) string {
+	return gcsBackendURLPrefix + path.Join(b.bucket, b.objectName(key))
+}
+
+func (b *gcsBucket) Location(key string) string {
+	return b.URL(key)
+}
+
+// observe records the generation of the object with the given name, if it is not known already.
+func (b *gcsBucket) observe(name string, generation int64) {
+	b.generationLock.Lock()
+	defer b.generationLock.Unlock()
+	if _, has := b.generations[name]; !has {
+		b.generations[name] = generation
+	}
+}
+
+// wrote records the generation of the object with the given name after it was written or deleted by this bucket.
+func (b *gcsBucket) wrote(name string, generation int64) {
+	b.generationLock.Lock()
+	defer b.generationLock.Unlock()
+	b.generations[name] = generation
+}
+
+// precondition returns the generation that the object with the given name is expected to have, if any.
+func (b *gcsBucket) precondition(name string) (int64, bool) {
+	b.generationLock.Lock()
+	defer b.generationLock.Unlock()
+	generation, has := b.generations[name]
+	return generation, has
+}
+
+// errGCSPreconditionFailed is returned by result when a request's generation precondition does not hold.
+var errGCSPreconditionFailed = errors.New("generation precondition failed")
+
+// newRequest creates an authorized request to the Cloud Storage JSON API.
+func (b *gcsBucket) newRequest(method, target string, header http.Header, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if b.tokens != nil {
+		token, tokenErr := b.tokens.Token()
+		if tokenErr != nil {
+			return nil, errors.Wrap(tokenErr, "requesting a Google access token")
+		}
+		token.SetAuthHeader(req)
+	}
+	return req, nil
+}
+
+// do sends a request to the Cloud Storage JSON API, retrying it if it fails transiently, and interprets the result as
+// described by result.
+func (b *gcsBucket) do(method, target string, header http.Header, body []byte, name string) (*http.Response, error) {
+	req, err := b.newRequest(method, target, header, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httputil.DoWithRetryOpts(req, b.client, bucketRetryOpts)
+	return b.result(method, name, resp, err)
+}
+
+// result interprets the response to, or the error from, a request concerning the object with the given name. Responses
+// that indicate that an object does not exist are returned as errors that satisfy os.IsNotExist, and responses that
+// indicate that a precondition failed as errGCSPreconditionFailed. Successful responses are returned as they are, as
+// are the 308 Resume Incomplete responses with which the service reports the progress of resumable uploads.
+func (b *gcsBucket) result(method, name string, resp *http.Response, err error) (*http.Response, error) {
+	location := gcsBackendURLPrefix + path.Join(b.bucket, name)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return nil, errors.Wrapf(err, "%s %s", method, location)
+	}
+	if (resp.StatusCode >= 200 && resp.StatusCode < 300) || resp.StatusCode == http.StatusPermanentRedirect {
+		return resp, nil
+	}
+	defer contract.IgnoreClose(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		if name != "" {
+			return nil, &os.PathError{Op: strings.ToLower(method), Path: location, Err: os.ErrNotExist}
+		}
+	case http.StatusPreconditionFailed:
+		return nil, errGCSPreconditionFailed
+	}
+
+	var failure struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if byts, readErr := ioutil.ReadAll(resp.Body); readErr == nil {
+		contract.IgnoreError(json.Unmarshal(byts, &failure))
+	}
+	return nil, errors.Errorf("%s %s failed: %s: %s", method, location, resp.Status, failure.Error.Message)
+}
+
+// objectURL returns the URL of the Cloud Storage object with the given name, with the given query parameters.
+func (b *gcsBucket) objectURL(name string, query url.Values) string {
+	target := b.endpoint + "/storage/v1/b/" + url.PathEscape(b.bucket) + "/o/" + url.PathEscape(name)
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	return target
+}
+
+// changedError returns the error reported when an object's generation precondition fails.
+func (b *gcsBucket) changedError(name string) error {
+	return errors.Errorf("%s%s was changed by another process after it was read; re-run the command",
+		gcsBackendURLPrefix, path.Join(b.bucket, name))
+}
+
+func (b *gcsBucket) NewReader(key string) (io.ReadCloser, error) {
+	name := b.objectName(key)
+	resp, err := b.do("GET", b.objectURL(name, url.Values{"alt": {"media"}}), nil, nil, name)
+	if os.IsNotExist(err) {
+		b.observe(name, 0)
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	generation, err := strconv.ParseInt(resp.Header.Get("X-Goog-Generation"), 10, 64)
+	if err == nil {
+		b.observe(name, generation)
+	}
+	return resp.Body, nil
+}
+
+// put uploads the contents of the object with the given key. If create is true, the object must not exist yet;
+// otherwise, if the generation of the object is known, it must not have changed. Objects larger than uploadChunkSize
+// are uploaded in chunks, using a resumable upload.
+func (b *gcsBucket) put(key string, data []byte, create bool) error {
+	name := b.objectName(key)
+	query := url.Values{"name": {name}}
+	if create {
+		query.Set("ifGenerationMatch", "0")
+	} else if generation, has := b.precondition(name); has {
+		query.Set("ifGenerationMatch", strconv.FormatInt(generation, 10))
+	}
+
+	var resp *http.Response
+	var err error
+	if len(data) > uploadChunkSize {
+		resp, err = b.putResumable(name, query, data)
+	} else {
+		query.Set("uploadType", "media")
+		target := b.endpoint + "/upload/storage/v1/b/" + url.PathEscape(b.bucket) + "/o?" + query.Encode()
+		resp, err = b.do("POST", target, nil, data, name)
+	}
+	if err == errGCSPreconditionFailed {
+		// If the request was retried after the service wrote the object but before we heard back, the retry fails
+		// its precondition even though the object holds what we wrote.
+		if b.holds(name, data) {
+			return nil
+		}
+		if create {
+			return &os.PathError{Op: "create", Path: b.URL(key), Err: os.ErrExist}
+		}
+		return b.changedError(name)
+	} else if err != nil {
+		return err
+	}
+	defer contract.IgnoreClose(resp.Body)
+
+	var object struct {
+		Generation json.Number `json:"generation"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return errors.Wrapf(err, "reading the result of writing %s", b.URL(key))
+	}
+	generation, err := object.Generation.Int64()
+	if err != nil {
+		return errors.Wrapf(err, "reading the generation of %s", b.URL(key))
+	}
+	b.wrote(name, generation)
+	return nil
+}
+
+// holds returns true, after recording the object's generation, if the object with the given name holds exactly the
+// given data.
+func (b *gcsBucket) holds(name string, data []byte) bool {
+	resp, err := b.do("GET", b.objectURL(name, url.Values{"alt": {"media"}}), nil, nil, name)
+	if err != nil {
+		return false
+	}
+	defer contract.IgnoreClose(resp.Body)
+	byts, err := ioutil.ReadAll(resp.Body)
+	if err != nil || !bytes.Equal(byts, data) {
+		return false
+	}
+	generation, err := strconv.ParseInt(resp.Header.Get("X-Goog-Generation"), 10, 64)
+	if err != nil {
+		return false
+	}
+	b.wrote(name, generation)
+	return true
+}
+
+// putResumable uploads the contents of the object with the given name in chunks of uploadChunkSize bytes, using a
+// resumable upload. If sending a chunk fails, the bucket asks the service how much of the object it has received and
+// resumes the upload from there, retrying as directed by bucketRetryOpts.
+func (b *gcsBucket) putResumable(name string, query url.Values, data []byte) (*http.Response, error) {
+	query.Set("uploadType", "resumable")
+	target := b.endpoint + "/upload/storage/v1/b/" + url.PathEscape(b.bucket) + "/o?" + query.Encode()
+	header := http.Header{"X-Upload-Content-Length": {strconv.Itoa(len(data))}}
+	resp, err := b.do("POST", target, header, nil, name)
+	if err != nil {
+		return nil, err
+	}
+	contract.IgnoreClose(resp.Body)
+	session := resp.Header.Get("Location")
+	if session == "" {
+		return nil, errors.Errorf("starting an upload of %s%s: the service did not return a session URI",
+			gcsBackendURLPrefix, path.Join(b.bucket, name))
+	}
+
+	// The chunks are sent with the client's transport, rather than with the client itself, because older versions of
+	// the client report the 308 responses with which the service acknowledges each chunk as missing a Location header.
+	transport := b.client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	offset, known, failures, delay := 0, true, 0, bucketRetryOpts.Delay
+	for {
+		var chunk []byte
+		if known {
+			end := offset + uploadChunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+			chunk = data[offset:end]
+			header = http.Header{"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", offset, end-1, len(data))}}
+		} else {
+			// Ask the service how much of the object it has received, so that we can resume from there.
+			header = http.Header{"Content-Range": {fmt.Sprintf("bytes */%d", len(data))}}
+		}
+
+		req, err := b.newRequest("PUT", session, header, chunk)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := transport.RoundTrip(req)
+		if err == nil && resp.StatusCode == http.StatusPermanentRedirect {
+			// The service has received the object up to the end of the range that it reports, if any.
+			offset, known, failures, delay = 0, true, 0, bucketRetryOpts.Delay
+			if received := resp.Header.Get("Range"); received != "" {
+				var first, last int
+				if _, scanErr := fmt.Sscanf(received, "bytes=%d-%d", &first, &last); scanErr == nil {
+					offset = last + 1
+				}
+			}
+			contract.IgnoreClose(resp.Body)
+			continue
+		}
+
+		if failures++; failures < bucketRetryOpts.MaxRetryCount && httputil.IsRetryable(resp, err) {
+			if err == nil {
+				contract.IgnoreClose(resp.Body)
+			}
+			logging.V(7).Infof("resuming the upload of %s in %v after a failure", name, delay)
+			time.Sleep(delay)
+			if delay = time.Duration(float64(delay) * bucketRetryOpts.Backoff); delay > bucketRetryOpts.MaxDelay {
+				delay = bucketRetryOpts.MaxDelay
+			}
+			known = false
+			continue
+		}
+		return b.result("PUT", name, resp, err)
+	}
+}
+
+// gcsObjectWriter buffers the contents of an object, and uploads them when it is closed.
+type gcsObjectWriter struct {
+	bytes.Buffer
+	bucket *gcsBucket
+	key    string
+}
+
+func (w *gcsObjectWriter) Close() error {
+	return w.bucket.put(w.key, w.Bytes(), false)
+}
+
+func (b *gcsBucket) NewWriter(key string) (io.WriteCloser, error) {
+	return &gcsObjectWriter{bucket: b, key: key}, nil
+}
+
+func (b *gcsBucket) Create(key string, data []byte) error {
+	return b.put(key, data, true)
+}
+
+func (b *gcsBucket) Rename(from, to string) error {
+	data, err := readObject(b, from)
+	if err != nil {
+		return err
+	}
+	if err = b.put(to, data, false); err != nil {
+		return err
+	}
+	return b.Delete(from)
+}
+
+func (b *gcsBucket) Delete(key string) error {
+	name := b.objectName(key)
+	var query url.Values
+	if generation, has := b.precondition(name); has && generation != 0 {
+		query = url.Values{"ifGenerationMatch": {strconv.FormatInt(generation, 10)}}
+	}
+
+	resp, err := b.do("DELETE", b.objectURL(name, query), nil, nil, name)
+	if err == errGCSPreconditionFailed {
+		return b.changedError(name)
+	} else if err != nil {
+		return err
+	}
+	b.wrote(name, 0)
+	return resp.Body.Close()
+}
+
+func (b *gcsBucket) DeleteAll(dir string) error {
+	objects, err := b.list(dir, "")
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if err = b.Delete(path.Join(dir, object)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *gcsBucket) List(dir string) ([]string, error) {
+	return b.list(dir, "/")
+}
+
+// list returns the names, relative to the given directory, of the objects beneath it. If a delimiter is given,
+// objects in subdirectories are not included.
+func (b *gcsBucket) list(dir, delimiter string) ([]string, error) {
+	prefix := b.objectName(dir) + "/"
+	if prefix == "/" {
+		prefix = ""
+	}
+
+	var names []string
+	pageToken := ""
+	for {
+		query := url.Values{"prefix": {prefix}, "fields": {"items(name),nextPageToken"}}
+		if delimiter != "" {
+			query.Set("delimiter", delimiter)
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		target := b.endpoint + "/storage/v1/b/" + url.PathEscape(b.bucket) + "/o?" + query.Encode()
+		resp, err := b.do("GET", target, nil, nil, "")
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		contract.IgnoreClose(resp.Body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing %s", b.URL(dir))
+		}
+
+		for _, item := range result.Items {
+			names = append(names, strings.TrimPrefix(item.Name, prefix))
+		}
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+// fakeGCSObject is an object stored by fakeGCSService.
+type fakeGCSObject struct {
+	data       []byte
+	generation int64
+}
+
+// fakeGCSUpload is a resumable upload in progress in fakeGCSService.
+type fakeGCSUpload struct {
+	query    url.Values                 // the query parameters with which the upload was started.
+	data     []byte                     // the data that has been received so far.
+	response *httptest.ResponseRecorder // the final response to the upload, once it has completed.
+}
+
+// fakeGCSService is a minimal in-memory implementation of the Cloud Storage JSON API for a single bucket.
+type fakeGCSService struct {
+	t      *testing.T
+	bucket string
+
+	lock       sync.Mutex
+	objects    map[string]fakeGCSObject
+	uploads    []*fakeGCSUpload
+	generation int64
+}
+
+// checkGeneration returns false, after failing the request, if the generation precondition in the given query does
+// not hold for the object with the given name.
+func (s *fakeGCSService) checkGeneration(w http.ResponseWriter, query url.Values, name string) bool {
+	match := query.Get("ifGenerationMatch")
+	if match == "" {
+		return true
+	}
+	expected, err := strconv.ParseInt(match, 10, 64)
+	assert.NoError(s.t, err)
+	if s.objects[name].generation != expected {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+func (s *fakeGCSService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !assert.Equal(s.t, "Bearer token", req.Header.Get("Authorization")) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	objectsPath := "/storage/v1/b/" + s.bucket + "/o"
+	query := req.URL.Query()
+	switch {
+	case req.Method == "GET" && req.URL.Path == objectsPath:
+		type item struct {
+			Name string `json:"name"`
+		}
+		var result struct {
+			Items []item `json:"items,omitempty"`
+		}
+		var names []string
+		for name := range s.objects {
+			rest := strings.TrimPrefix(name, query.Get("prefix"))
+			delimiter := query.Get("delimiter")
+			if strings.HasPrefix(name, query.Get("prefix")) && (delimiter == "" || !strings.Contains(rest, delimiter)) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result.Items = append(result.Items, item{Name: name})
+		}
+		assert.NoError(s.t, json.NewEncoder(w).Encode(result))
+	case req.Method == "GET" && strings.HasPrefix(req.URL.Path, objectsPath+"/"):
+		assert.Equal(s.t, "media", query.Get("alt"))
+		object, has := s.objects[strings.TrimPrefix(req.URL.Path, objectsPath+"/")]
+		if !has {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Goog-Generation", strconv.FormatInt(object.generation, 10))
+		_, err := w.Write(object.data)
+		assert.NoError(s.t, err)
+	case req.Method == "POST" && req.URL.Path == "/upload"+objectsPath && query.Get("uploadType") == "resumable":
+		s.uploads = append(s.uploads, &fakeGCSUpload{query: query})
+		w.Header().Set("Location", fmt.Sprintf("http://%s/upload-session/%d", req.Host, len(s.uploads)-1))
+	case req.Method == "POST" && req.URL.Path == "/upload"+objectsPath:
+		assert.Equal(s.t, "media", query.Get("uploadType"))
+		data, err := ioutil.ReadAll(req.Body)
+		assert.NoError(s.t, err)
+		s.write(w, query, data)
+	case req.Method == "PUT" && strings.HasPrefix(req.URL.Path, "/upload-session/"):
+		session, err := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/upload-session/"))
+		assert.NoError(s.t, err)
+		upload := s.uploads[session]
+		data, err := ioutil.ReadAll(req.Body)
+		assert.NoError(s.t, err)
+
+		// Either a chunk of the object, or a query of how much of it has been received.
+		var first, last, total int
+		if _, err = fmt.Sscanf(req.Header.Get("Content-Range"), "bytes */%d", &total); err != nil {
+			_, err = fmt.Sscanf(req.Header.Get("Content-Range"), "bytes %d-%d/%d", &first, &last, &total)
+			assert.NoError(s.t, err)
+			assert.Equal(s.t, last-first+1, len(data))
+			if upload.response == nil && first <= len(upload.data) {
+				upload.data = append(upload.data[:first], data...)
+			}
+		}
+		if upload.response == nil && len(upload.data) < total {
+			if len(upload.data) > 0 {
+				w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(upload.data)-1))
+			}
+			w.WriteHeader(http.StatusPermanentRedirect)
+			return
+		}
+		if upload.response == nil {
+			upload.response = httptest.NewRecorder()
+			s.write(upload.response, upload.query, upload.data)
+		}
+		w.WriteHeader(upload.response.Code)
+		_, err = w.Write(upload.response.Body.Bytes())
+		assert.NoError(s.t, err)
+	case req.Method == "DELETE" && strings.HasPrefix(req.URL.Path, objectsPath+"/"):
+		name := strings.TrimPrefix(req.URL.Path, objectsPath+"/")
+		if _, has := s.objects[name]; !has {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !s.checkGeneration(w, query, name) {
+			return
+		}
+		delete(s.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// write writes the object named by the given query parameters, if their generation precondition holds.
+func (s *fakeGCSService) write(w http.ResponseWriter, query url.Values, data []byte) {
+	name := query.Get("name")
+	if !s.checkGeneration(w, query, name) {
+		return
+	}
+	s.generation++
+	s.objects[name] = fakeGCSObject{data: data, generation: s.generation}
+	fmt.Fprintf(w, `{"name": %q, "generation": "%d"}`, name, s.generation)
+}
+
+func newTestGCSBucket(t *testing.T, flaky bool) (*fakeGCSService, func(prefix string) *gcsBucket, func()) {
+	service := &fakeGCSService{t: t, bucket: "state", objects: make(map[string]fakeGCSObject)}
+	var handler http.Handler = service
+	if flaky {
+		handler = newFlakyHandler(service)
+	}
+	server := httptest.NewServer(handler)
+	newBucket := func(prefix string) *gcsBucket {
+		return &gcsBucket{
+			endpoint:    server.URL,
+			bucket:      "state",
+			prefix:      prefix,
+			tokens:      oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}),
+			client:      server.Client(),
+			generations: make(map[string]int64),
+		}
+	}
+	return service, newBucket, server.Close
+}
+
+func TestGCSBucket(t *testing.T) {
+	service, newBucket, closeServer := newTestGCSBucket(t, false)
+	defer closeServer()
+
+	b := newBucket("team/prod")
+	assert.Equal(t, "gs://state/team/prod/.pulumi/stacks/dev.json", b.URL(".pulumi/stacks/dev.json"))
+	testBucket(t, newBucket("team/prod"))
+	testBucketBackend(t, newBucket("team/prod"))
+
+	// Objects are stored beneath the bucket's prefix, if any.
+	service.objects = make(map[string]fakeGCSObject)
+	assert.NoError(t, writeObject(b, "a b/c.json", []byte("c")))
+	assert.NoError(t, writeObject(newBucket(""), "d.json", []byte("d")))
+	var names []string
+	for name := range service.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"d.json", "team/prod/a b/c.json"}, names)
+}
+
+func TestGCSGenerationPreconditions(t *testing.T) {
+	_, newBucket, closeServer := newTestGCSBucket(t, false)
+	defer closeServer()
+
+	first, second := newBucket(""), newBucket("")
+
+	// Once the first bucket has read an object, it refuses to overwrite changes that another process makes to it.
+	assert.NoError(t, writeObject(first, "chk.json", []byte("1")))
+	_, err := readObject(second, "chk.json")
+	assert.NoError(t, err)
+	assert.NoError(t, writeObject(first, "chk.json", []byte("2")))
+	assert.Error(t, writeObject(second, "chk.json", []byte("3")))
+	assert.Error(t, second.Delete("chk.json"))
+	byts, err := readObject(first, "chk.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", string(byts))
+
+	// The same goes for objects that it found not to exist.
+	exists, err := objectExists(second, "new.json")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	assert.NoError(t, writeObject(first, "new.json", []byte("1")))
+	assert.Error(t, writeObject(second, "new.json", []byte("2")))
+
+	// Objects that have not been read are written unconditionally.
+	assert.NoError(t, writeObject(newBucket(""), "chk.json", []byte("4")))
+
+	// Creating an object that already exists fails, regardless of what the bucket knows about it.
+	assert.True(t, os.IsExist(newBucket("").Create("chk.json", nil)))
+}
+
+func TestGCSBucketRetries(t *testing.T) {
+	defer useTestUploads()()
+	_, newBucket, closeServer := newTestGCSBucket(t, true)
+	defer closeServer()
+
+	testFlakyBucket(t, func() bucket { return newBucket("team/prod") })
+}
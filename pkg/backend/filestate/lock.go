@@ -0,0 +1,110 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// updateLock is the content of a stack's lock file, which exists for as long as the stack is being updated. If the
+// process performing the update is killed, the lock file is left behind and must be removed with `pulumi cancel`.
+type updateLock struct {
+	Kind      apitype.UpdateKind `json:"kind"`
+	Pid       int                `json:"pid"`
+	Hostname  string             `json:"hostname"`
+	User      string             `json:"user,omitempty"`
+	StartTime time.Time          `json:"startTime"`
+}
+
+func (b *localBackend) lockPath(stack tokens.QName) string {
+	contract.Require(stack != "", "stack")
+	return path.Join(workspace.BookkeepingDir, workspace.LockDir, string(stack)+".json")
+}
+
+// lockStack acquires the lock for the given stack, failing if another update of the stack is in progress.
+func (b *localBackend) lockStack(name tokens.QName, kind apitype.UpdateKind) error {
+	hostname, err := os.Hostname()
+	contract.IgnoreError(err)
+	var username string
+	if u, userErr := user.Current(); userErr == nil {
+		username = u.Username
+	}
+	lock, err := json.Marshal(updateLock{
+		Kind:      kind,
+		Pid:       os.Getpid(),
+		Hostname:  hostname,
+		User:      username,
+		StartTime: time.Now(),
+	})
+	contract.AssertNoError(err)
+
+	// Creating the lock file exclusively ensures that only one process can hold the lock at a time.
+	path := b.lockPath(name)
+	if err = b.bucket.Create(path, lock); os.IsExist(err) {
+		return b.lockedError(name, path)
+	} else if err != nil {
+		return errors.Wrap(err, "creating lock file")
+	}
+	return nil
+}
+
+// lockedError returns an error describing the update that holds the lock for the given stack.
+func (b *localBackend) lockedError(name tokens.QName, path string) error {
+	var lock updateLock
+	if byts, err := readObject(b.bucket, path); err == nil {
+		contract.IgnoreError(json.Unmarshal(byts, &lock))
+	}
+	return backend.UpdateConflictError{
+		StackName: string(name),
+		Kind:      lock.Kind,
+		StartTime: lock.StartTime,
+		User:      lock.User,
+		Hostname:  lock.Hostname,
+		PID:       lock.Pid,
+		URL:       b.bucket.URL(path),
+	}
+}
+
+// unlockStack releases the lock for the given stack.
+func (b *localBackend) unlockStack(name tokens.QName) error {
+	return b.bucket.Delete(b.lockPath(name))
+}
+
+// CancelCurrentUpdate removes the lock left behind by an update of the given stack. It does not stop the process
+// performing the update, if it is still running.
+func (b *localBackend) CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference) error {
+	if err := backend.CheckWritable(b, "cancel the update of stack '%s'", stackRef); err != nil {
+		return err
+	}
+
+	err := b.unlockStack(stackRef.Name())
+	if os.IsNotExist(err) {
+		return errors.Errorf("stack %v has no update in progress", stackRef)
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func newTestLockBackend(t *testing.T) (*localBackend, func()) {
+	dir, err := ioutil.TempDir("", "pulumi-lock")
+	assert.NoError(t, err)
+	return &localBackend{
+		url:      "file://" + dir,
+		bucket:   newLocalBucket(dir),
+		crypters: make(map[tokens.QName]config.Crypter),
+		serials:  make(map[tokens.QName]int64),
+	}, func() { os.RemoveAll(dir) }
+}
+
+func TestLockStack(t *testing.T) {
+	b, cleanup := newTestLockBackend(t)
+	defer cleanup()
+
+	// Acquiring the lock records who holds it.
+	before := time.Now().Add(-time.Second)
+	assert.NoError(t, b.lockStack("dev", apitype.RefreshUpdate))
+	byts, err := readObject(b.bucket, b.lockPath("dev"))
+	assert.NoError(t, err)
+	var lock updateLock
+	assert.NoError(t, json.Unmarshal(byts, &lock))
+	assert.Equal(t, apitype.RefreshUpdate, lock.Kind)
+	assert.Equal(t, os.Getpid(), lock.Pid)
+	assert.True(t, lock.StartTime.After(before))
+
+	// Locks are per stack.
+	assert.NoError(t, b.lockStack("prod", apitype.UpdateUpdate))
+
+	// A second update of the same stack conflicts with the first, and is told who holds the lock.
+	err = b.lockStack("dev", apitype.UpdateUpdate)
+	if assert.IsType(t, backend.UpdateConflictError{}, err) {
+		conflict := err.(backend.UpdateConflictError)
+		assert.Equal(t, "dev", conflict.StackName)
+		assert.Equal(t, apitype.RefreshUpdate, conflict.Kind)
+		assert.Equal(t, os.Getpid(), conflict.PID)
+		assert.Equal(t, lock.Hostname, conflict.Hostname)
+		assert.Equal(t, b.bucket.URL(b.lockPath("dev")), conflict.URL)
+	}
+
+	// Once the lock is released, it may be acquired again.
+	assert.NoError(t, b.unlockStack("dev"))
+	assert.NoError(t, b.lockStack("dev", apitype.UpdateUpdate))
+	assert.NoError(t, b.unlockStack("dev"))
+	assert.NoError(t, b.unlockStack("prod"))
+}
+
+func TestStaleLock(t *testing.T) {
+	b, cleanup := newTestLockBackend(t)
+	defer cleanup()
+	ctx := context.Background()
+	ref := localBackendReference{name: "dev"}
+
+	// A lock left behind by a process that was killed blocks further updates.
+	start := time.Now().Add(-time.Hour).UTC().Round(time.Second)
+	byts, err := json.Marshal(updateLock{Kind: apitype.UpdateUpdate, Pid: 1 << 30, Hostname: "elsewhere",
+		StartTime: start})
+	assert.NoError(t, err)
+	assert.NoError(t, writeObject(b.bucket, b.lockPath("dev"), byts))
+	err = b.lockStack("dev", apitype.UpdateUpdate)
+	if assert.IsType(t, backend.UpdateConflictError{}, err) {
+		conflict := err.(backend.UpdateConflictError)
+		assert.Equal(t, 1<<30, conflict.PID)
+		assert.Equal(t, "elsewhere", conflict.Hostname)
+		assert.True(t, start.Equal(conflict.StartTime))
+	}
+
+	// Even a lock whose contents cannot be read still holds the stack.
+	assert.NoError(t, writeObject(b.bucket, b.lockPath("dev"), []byte("garbage")))
+	assert.IsType(t, backend.UpdateConflictError{}, b.lockStack("dev", apitype.UpdateUpdate))
+
+	// `pulumi cancel` removes it, after which the stack may be updated again.
+	assert.NoError(t, b.CancelCurrentUpdate(ctx, ref))
+	assert.NoError(t, b.lockStack("dev", apitype.UpdateUpdate))
+	assert.NoError(t, b.unlockStack("dev"))
+
+	// There is nothing to cancel if no update is in progress.
+	err = b.CancelCurrentUpdate(ctx, ref)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "has no update in progress")
+	}
+}
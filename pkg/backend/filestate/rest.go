@@ -0,0 +1,337 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/httputil"
+)
+
+// restBackendURLPrefix is the URL scheme prefix of backends that store state in a service that speaks the REST state
+// protocol, in the form rest+https://<host>/<path> (or rest+http://). The prefix distinguishes such services from the
+// Pulumi service, whose URLs are plain https:// URLs.
+const restBackendURLPrefix = "rest+"
+
+// RESTBackendTokenEnvVar may be set to a bearer token with which requests to a rest+https:// backend are authorized.
+const RESTBackendTokenEnvVar = "PULUMI_REST_BACKEND_TOKEN"
+
+// restBucket is a bucket that stores objects in a service that speaks the REST state protocol, which lets
+// organizations host their own state service. The protocol is deliberately small: the service stores opaque objects,
+// and the CLI layers checkpoints, update history, backups and locks on top of them exactly as it does for the other
+// buckets. Every object is addressed by appending its slash-separated key to the base URL, e.g.
+// <base>/.pulumi/stacks/dev.json, and the service must support the following requests:
+//
+//	GET <base>/<key>
+//	    Returns 200 and the contents of the object, or 404 if it does not exist. The response should include an
+//	    ETag header identifying the object's current contents.
+//	PUT <base>/<key>
+//	    Replaces the contents of the object with the request body, returning any 2xx status. If the request has an
+//	    "If-None-Match: *" header, the service must atomically fail with 412 if the object already exists; this is
+//	    how locks are taken. If the request has an "If-Match: <etag>" header, the service must atomically fail with
+//	    412 unless the object exists and its ETag matches. The response should include the object's new ETag.
+//	DELETE <base>/<key>
+//	    Removes the object, returning any 2xx status, or 404 if it does not exist. "If-Match" is honored as for PUT.
+//	GET <base>/<dir>/ (or <base>/ for the root)
+//	    Returns 200 and a JSON object of the form {"objects": ["<name>", ...]} listing the names, relative to the
+//	    directory, of the objects directly beneath it. With "?recursive=true", objects in subdirectories are listed
+//	    too, by their slash-separated paths relative to the directory. A directory that does not exist is empty.
+//
+// The CLI stores a stack's checkpoint at .pulumi/stacks/<stack>.json, its update history beneath
+// .pulumi/history/<stack>/, the backups of its checkpoint beneath .pulumi/backups/<stack>/, and the lock that is held
+// while it is being updated at .pulumi/locks/<stack>.json. Any other response status is reported as an error, along
+// with the body of the response if it is JSON of the form {"message": "<message>"}.
+//
+// Like gcsBucket, the bucket remembers the ETag of each object when it first reads the object, and makes subsequent
+// writes and deletes of the object conditional upon it, so that it does not overwrite changes made by other processes.
+// Services that do not return ETags forgo this protection.
+type restBucket struct {
+	base   string // the base URL of the service, without the rest+ prefix or a trailing slash.
+	token  string // the bearer token with which requests are authorized, if any.
+	client *http.Client
+
+	etagLock sync.Mutex
+	etags    map[string]string // the expected ETag of each object, keyed by key; empty if it is absent.
+}
+
+// newRESTBucket creates a bucket for the given rest+https:// or rest+http:// URL.
+func newRESTBucket(bucketURL string) (*restBucket, error) {
+	contract.Require(strings.HasPrefix(bucketURL, restBackendURLPrefix), "bucketURL")
+
+	base := strings.TrimRight(bucketURL[len(restBackendURLPrefix):], "/")
+	if u, err := url.Parse(base); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, errors.Errorf("%s is not a valid URL; expected %shttps://<host>[/<path>]",
+			bucketURL, restBackendURLPrefix)
+	}
+
+	return &restBucket{
+		base:   base,
+		token:  os.Getenv(RESTBackendTokenEnvVar),
+		client: http.DefaultClient,
+		etags:  make(map[string]string),
+	}, nil
+}
+
+// objectURL returns the URL of the object with the given key.
+func (b *restBucket) objectURL(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return b.base + "/" + strings.Join(segments, "/")
+}
+
+func (b *restBucket) URL(key string) string {
+	return restBackendURLPrefix + b.objectURL(key)
+}
+
+func (b *restBucket) Location(key string) string {
+	return b.objectURL(key)
+}
+
+// observe records the ETag of the object with the given key, if it is not known already.
+func (b *restBucket) observe(key, etag string) {
+	b.etagLock.Lock()
+	defer b.etagLock.Unlock()
+	if _, has := b.etags[key]; !has {
+		b.etags[key] = etag
+	}
+}
+
+// wrote records the ETag of the object with the given key after it was written or deleted by this bucket.
+func (b *restBucket) wrote(key, etag string) {
+	b.etagLock.Lock()
+	defer b.etagLock.Unlock()
+	b.etags[key] = etag
+}
+
+// precondition returns the ETag that the object with the given key is expected to have, if any.
+func (b *restBucket) precondition(key string) string {
+	b.etagLock.Lock()
+	defer b.etagLock.Unlock()
+	return b.etags[key]
+}
+
+// errRESTPreconditionFailed is returned by do when a request's precondition does not hold.
+var errRESTPreconditionFailed = errors.New("precondition failed")
+
+// do sends a request to the service, retrying it if it fails transiently. Responses that indicate that an object does
+// not exist are returned as errors that satisfy os.IsNotExist, and responses that indicate that a precondition failed
+// as errRESTPreconditionFailed.
+func (b *restBucket) do(method, target string, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	resp, err := httputil.DoWithRetryOpts(req, b.client, bucketRetryOpts)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return nil, errors.Wrapf(err, "%s %s", method, target)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer contract.IgnoreClose(resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, &os.PathError{Op: strings.ToLower(method), Path: target, Err: os.ErrNotExist}
+	case http.StatusPreconditionFailed:
+		return nil, errRESTPreconditionFailed
+	}
+
+	var failure struct {
+		Message string `json:"message"`
+	}
+	if byts, readErr := ioutil.ReadAll(resp.Body); readErr == nil {
+		contract.IgnoreError(json.Unmarshal(byts, &failure))
+	}
+	return nil, errors.Errorf("%s %s failed: %s: %s", method, target, resp.Status, failure.Message)
+}
+
+// changedError returns the error reported when an object's ETag precondition fails.
+func (b *restBucket) changedError(key string) error {
+	return errors.Errorf("%s was changed by another process after it was read; re-run the command", b.objectURL(key))
+}
+
+func (b *restBucket) NewReader(key string) (io.ReadCloser, error) {
+	resp, err := b.do("GET", b.objectURL(key), nil, nil)
+	if os.IsNotExist(err) {
+		b.observe(key, "")
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	b.observe(key, resp.Header.Get("ETag"))
+	return resp.Body, nil
+}
+
+// put uploads the contents of the object with the given key. If create is true, the object must not exist yet;
+// otherwise, if the ETag of the object is known, it must not have changed.
+func (b *restBucket) put(key string, data []byte, create bool) error {
+	header := http.Header{}
+	if create {
+		header.Set("If-None-Match", "*")
+	} else if etag := b.precondition(key); etag != "" {
+		header.Set("If-Match", etag)
+	}
+
+	resp, err := b.do("PUT", b.objectURL(key), data, header)
+	if err == errRESTPreconditionFailed {
+		// If the request was retried after the service wrote the object but before we heard back, the retry fails
+		// its precondition even though the object holds what we wrote.
+		if b.holds(key, data) {
+			return nil
+		}
+		if create {
+			return &os.PathError{Op: "create", Path: b.objectURL(key), Err: os.ErrExist}
+		}
+		return b.changedError(key)
+	} else if err != nil {
+		return err
+	}
+	b.wrote(key, resp.Header.Get("ETag"))
+	return resp.Body.Close()
+}
+
+// holds returns true, after recording the object's ETag, if the object with the given key holds exactly the given data.
+func (b *restBucket) holds(key string, data []byte) bool {
+	resp, err := b.do("GET", b.objectURL(key), nil, nil)
+	if err != nil {
+		return false
+	}
+	defer contract.IgnoreClose(resp.Body)
+	byts, err := ioutil.ReadAll(resp.Body)
+	if err != nil || !bytes.Equal(byts, data) {
+		return false
+	}
+	b.wrote(key, resp.Header.Get("ETag"))
+	return true
+}
+
+// restObjectWriter buffers the contents of an object, and uploads them when it is closed.
+type restObjectWriter struct {
+	bytes.Buffer
+	bucket *restBucket
+	key    string
+}
+
+func (w *restObjectWriter) Close() error {
+	return w.bucket.put(w.key, w.Bytes(), false)
+}
+
+func (b *restBucket) NewWriter(key string) (io.WriteCloser, error) {
+	return &restObjectWriter{bucket: b, key: key}, nil
+}
+
+func (b *restBucket) Create(key string, data []byte) error {
+	return b.put(key, data, true)
+}
+
+func (b *restBucket) Rename(from, to string) error {
+	data, err := readObject(b, from)
+	if err != nil {
+		return err
+	}
+	if err = b.put(to, data, false); err != nil {
+		return err
+	}
+	return b.Delete(from)
+}
+
+func (b *restBucket) Delete(key string) error {
+	header := http.Header{}
+	if etag := b.precondition(key); etag != "" {
+		header.Set("If-Match", etag)
+	}
+
+	resp, err := b.do("DELETE", b.objectURL(key), nil, header)
+	if err == errRESTPreconditionFailed {
+		return b.changedError(key)
+	} else if err != nil {
+		return err
+	}
+	b.wrote(key, "")
+	return resp.Body.Close()
+}
+
+func (b *restBucket) DeleteAll(dir string) error {
+	objects, err := b.list(dir, true)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if err = b.Delete(path.Join(dir, object)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *restBucket) List(dir string) ([]string, error) {
+	return b.list(dir, false)
+}
+
+// list returns the names, relative to the given directory, of the objects beneath it. Unless recursive is true,
+// objects in subdirectories are not included.
+func (b *restBucket) list(dir string, recursive bool) ([]string, error) {
+	target := b.base + "/"
+	if dir != "" {
+		target = b.objectURL(dir) + "/"
+	}
+	if recursive {
+		target += "?recursive=true"
+	}
+
+	resp, err := b.do("GET", target, nil, nil)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(resp.Body)
+
+	var result struct {
+		Objects []string `json:"objects"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrapf(err, "listing %s", target)
+	}
+	sort.Strings(result.Objects)
+	return result.Objects, nil
+}
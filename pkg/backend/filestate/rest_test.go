@@ -0,0 +1,172 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRESTObject is an object stored by fakeRESTService.
+type fakeRESTObject struct {
+	data []byte
+	etag string
+}
+
+// fakeRESTService is a minimal in-memory implementation of the REST state protocol, served beneath /state.
+type fakeRESTService struct {
+	t     *testing.T
+	token string
+
+	lock    sync.Mutex
+	objects map[string]fakeRESTObject
+	version int
+}
+
+// checkPreconditions returns false, after failing the request, if the request's preconditions do not hold for the
+// object with the given key.
+func (s *fakeRESTService) checkPreconditions(w http.ResponseWriter, req *http.Request, key string) bool {
+	object, has := s.objects[key]
+	if (req.Header.Get("If-None-Match") == "*" && has) ||
+		(req.Header.Get("If-Match") != "" && req.Header.Get("If-Match") != object.etag) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+func (s *fakeRESTService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if req.Header.Get("Authorization") != "Bearer "+s.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	assert.True(s.t, strings.HasPrefix(req.URL.Path, "/state/"))
+	key := strings.TrimPrefix(req.URL.Path, "/state/")
+	switch {
+	case req.Method == "GET" && (key == "" || strings.HasSuffix(key, "/")):
+		recursive := req.URL.Query().Get("recursive") == "true"
+		result := struct {
+			Objects []string `json:"objects"`
+		}{Objects: []string{}}
+		for name := range s.objects {
+			rest := strings.TrimPrefix(name, key)
+			if strings.HasPrefix(name, key) && (recursive || !strings.Contains(rest, "/")) {
+				result.Objects = append(result.Objects, rest)
+			}
+		}
+		assert.NoError(s.t, json.NewEncoder(w).Encode(result))
+	case req.Method == "GET":
+		object, has := s.objects[key]
+		if !has {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", object.etag)
+		_, err := w.Write(object.data)
+		assert.NoError(s.t, err)
+	case req.Method == "PUT":
+		if !s.checkPreconditions(w, req, key) {
+			return
+		}
+		data, err := ioutil.ReadAll(req.Body)
+		assert.NoError(s.t, err)
+		s.version++
+		etag := strconv.Quote(strconv.Itoa(s.version))
+		s.objects[key] = fakeRESTObject{data: data, etag: etag}
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNoContent)
+	case req.Method == "DELETE":
+		if _, has := s.objects[key]; !has {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !s.checkPreconditions(w, req, key) {
+			return
+		}
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRESTBucket(t *testing.T) {
+	service := &fakeRESTService{t: t, token: "secret", objects: make(map[string]fakeRESTObject)}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	os.Setenv(RESTBackendTokenEnvVar, "secret")
+	defer os.Unsetenv(RESTBackendTokenEnvVar)
+	newBucket := func() *restBucket {
+		b, err := newRESTBucket(restBackendURLPrefix + server.URL + "/state/")
+		assert.NoError(t, err)
+		return b
+	}
+
+	b := newBucket()
+	assert.Equal(t, restBackendURLPrefix+server.URL+"/state/a%20b/c.json", b.URL("a b/c.json"))
+	testBucket(t, b)
+	testBucketBackend(t, newBucket())
+
+	// Once a bucket has read an object, it refuses to overwrite changes that another process makes to it.
+	first, second := newBucket(), newBucket()
+	assert.NoError(t, writeObject(first, "chk.json", []byte("1")))
+	_, err := readObject(second, "chk.json")
+	assert.NoError(t, err)
+	assert.NoError(t, writeObject(first, "chk.json", []byte("2")))
+	assert.Error(t, writeObject(second, "chk.json", []byte("3")))
+	assert.Error(t, second.Delete("chk.json"))
+	byts, err := readObject(newBucket(), "chk.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", string(byts))
+
+	// Requests are rejected without the right token.
+	b = newBucket()
+	b.token = "wrong"
+	_, err = b.List("")
+	assert.Error(t, err)
+
+	_, err = newRESTBucket(restBackendURLPrefix + "ftp://example.com")
+	assert.Error(t, err)
+}
+
+func TestRESTBucketRetries(t *testing.T) {
+	defer useTestUploads()()
+	service := &fakeRESTService{t: t, token: "secret", objects: make(map[string]fakeRESTObject)}
+	server := httptest.NewServer(newFlakyHandler(service))
+	defer server.Close()
+
+	os.Setenv(RESTBackendTokenEnvVar, "secret")
+	defer os.Unsetenv(RESTBackendTokenEnvVar)
+
+	testFlakyBucket(t, func() bucket {
+		b, err := newRESTBucket(restBackendURLPrefix + server.URL + "/state")
+		assert.NoError(t, err)
+		return b
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// CheckpointSigningKeyEnvVar may be set to a secret key with which to sign checkpoints. When it is set, a signature
+// is written alongside each checkpoint, and checkpoints that are unsigned or whose contents do not match their
+// signature are refused, so that tampered or truncated state is detected before it is used to plan an update.
+const CheckpointSigningKeyEnvVar = "PULUMI_CHECKPOINT_SIGNING_KEY"
+
+// checkpointSignaturePrefix identifies the algorithm used to produce a checkpoint signature.
+const checkpointSignaturePrefix = "hmac-sha256:"
+
+// checkpointSigningKey returns the key with which checkpoints should be signed, or nil if signing is disabled.
+func checkpointSigningKey() []byte {
+	if key := os.Getenv(CheckpointSigningKeyEnvVar); key != "" {
+		return []byte(key)
+	}
+	return nil
+}
+
+// checkpointSignaturePath returns the path of the signature for the checkpoint file at the given path.
+func checkpointSignaturePath(chkpath string) string {
+	return chkpath + ".sig"
+}
+
+// computeCheckpointSignature computes the signature of the given checkpoint contents.
+func computeCheckpointSignature(byts []byte, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, err := mac.Write(byts)
+	contract.IgnoreError(err) // writes to a hash never fail.
+	return checkpointSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signCheckpoint writes the signature for the checkpoint file at the given path, whose contents are byts, if signing
+// is enabled. The signature is computed from the contents that were written rather than read back from the file, so
+// that changes made to the file in the meantime are not signed.
+func (b *localBackend) signCheckpoint(chkpath string, byts []byte) error {
+	key := checkpointSigningKey()
+	if key == nil {
+		return nil
+	}
+	return writeObject(b.bucket, checkpointSignaturePath(chkpath), []byte(computeCheckpointSignature(byts, key)+"\n"))
+}
+
+// readSignedCheckpoint reads the checkpoint file at the given path and, if signing is enabled, checks its contents
+// against its signature. The contents that were checked are returned, so that the checkpoint cannot change between
+// being checked and being used. If the file does not exist, the error satisfies os.IsNotExist.
+//
+// A checkpoint that has not been signed, e.g. because it was written before signing was enabled, is refused unless
+// integrity checking has been disabled; it is signed the next time it is saved.
+func (b *localBackend) readSignedCheckpoint(chkpath string) ([]byte, error) {
+	byts, err := readObject(b.bucket, chkpath)
+	if err != nil {
+		return nil, err
+	}
+	key := checkpointSigningKey()
+	if key == nil {
+		return byts, nil
+	}
+
+	expected, err := readObject(b.bucket, checkpointSignaturePath(chkpath))
+	if os.IsNotExist(err) {
+		if DisableIntegrityChecking {
+			return byts, nil
+		}
+		return nil, errors.Errorf("checkpoint %s is not signed. Refusing to use it (if it was written before %s was "+
+			"set, pass --disable-integrity-checking once to use it; it will be signed the next time it is saved)",
+			b.bucket.Location(chkpath), CheckpointSigningKeyEnvVar)
+	} else if err != nil {
+		return nil, err
+	}
+
+	actual := computeCheckpointSignature(byts, key)
+	if !hmac.Equal([]byte(strings.TrimSpace(string(expected))), []byte(actual)) {
+		return nil, errors.Errorf("checkpoint %s does not match its signature; it may have been modified or "+
+			"truncated. Refusing to use it (check that %s is set to the key that signed it)",
+			b.bucket.Location(chkpath), CheckpointSigningKeyEnvVar)
+	}
+	return byts, nil
+}
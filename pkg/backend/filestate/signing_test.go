@@ -0,0 +1,137 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestSignedCheckpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-signed-checkpoints")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	b := &localBackend{
+		bucket:   newLocalBucket(dir),
+		crypters: make(map[tokens.QName]config.Crypter),
+		serials:  make(map[tokens.QName]int64),
+	}
+	cfg := config.Map{config.MustMakeKey("proj", "key"): config.NewValue("value")}
+	chkpath := b.stackPath("dev")
+
+	// A checkpoint written before signing was enabled is refused once it is enabled, unless integrity checking is
+	// disabled.
+	_, err = b.saveStack("dev", cfg, nil)
+	assert.NoError(t, err)
+	os.Setenv(CheckpointSigningKeyEnvVar, "secret")
+	defer os.Unsetenv(CheckpointSigningKeyEnvVar)
+	_, _, err = b.getCheckpoint("dev")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is not signed")
+	}
+	DisableIntegrityChecking = true
+	_, _, err = b.getCheckpoint("dev")
+	DisableIntegrityChecking = false
+	assert.NoError(t, err)
+
+	// Saving the checkpoint signs it, after which it can be read.
+	_, err = b.saveStack("dev", cfg, nil)
+	assert.NoError(t, err)
+	actual, _, err := b.getCheckpoint("dev")
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, actual)
+
+	// A checkpoint that is modified after it was signed is refused.
+	byts, err := readObject(b.bucket, chkpath)
+	assert.NoError(t, err)
+	assert.NoError(t, writeObject(b.bucket, chkpath, []byte(strings.Replace(string(byts), "value", "other", 1))))
+	_, _, err = b.getCheckpoint("dev")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not match its signature")
+	}
+
+	// As is one whose signature has been removed.
+	assert.NoError(t, writeObject(b.bucket, chkpath, byts))
+	_, _, err = b.getCheckpoint("dev")
+	assert.NoError(t, err)
+	assert.NoError(t, b.bucket.Delete(checkpointSignaturePath(chkpath)))
+	_, _, err = b.getCheckpoint("dev")
+	assert.Error(t, err)
+
+	// Or one that was signed with another key.
+	_, err = b.saveStack("dev", cfg, nil)
+	assert.NoError(t, err)
+	os.Setenv(CheckpointSigningKeyEnvVar, "another secret")
+	_, _, err = b.getCheckpoint("dev")
+	assert.Error(t, err)
+}
+
+func TestSignedHistoricalCheckpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-signed-history")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	b := &localBackend{
+		bucket:   newLocalBucket(dir),
+		crypters: make(map[tokens.QName]config.Crypter),
+		serials:  make(map[tokens.QName]int64),
+	}
+	os.Setenv(CheckpointSigningKeyEnvVar, "secret")
+	defer os.Unsetenv(CheckpointSigningKeyEnvVar)
+
+	cfg := config.Map{config.MustMakeKey("proj", "key"): config.NewValue("value")}
+	_, err = b.saveStack("dev", cfg, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, b.addToHistory("dev", backend.UpdateInfo{Kind: "update", Result: backend.SucceededResult}))
+	_, err = b.getHistoricalCheckpoint("dev", 1)
+	assert.NoError(t, err)
+
+	// Replacing a historical checkpoint's chunks, along with its manifest, is detected.
+	prefixes, err := b.historyFiles("dev")
+	assert.NoError(t, err)
+	manifestPath := prefixes[0] + ".checkpoint.chunks.json"
+	forged := []byte(`{"version":3,"checkpoint":{"stack":"dev"}}`)
+	manifest := chunkManifest{Size: len(forged), SHA256: hashBytes(forged), Chunks: []string{hashBytes(forged)}}
+	assert.NoError(t, b.bucket.Create(b.historyChunksDirectory("dev")+"/"+hashBytes(forged), forged))
+	byts, err := json.Marshal(manifest)
+	assert.NoError(t, err)
+	assert.NoError(t, writeObject(b.bucket, manifestPath, byts))
+	_, err = b.getHistoricalCheckpoint("dev", 1)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not match its signature")
+	}
+
+	// Historical checkpoints copied whole by older versions of the CLI are unsigned, so are refused too.
+	assert.NoError(t, b.bucket.Delete(manifestPath))
+	assert.NoError(t, writeObject(b.bucket, prefixes[0]+".checkpoint.json", forged))
+	_, err = b.getHistoricalCheckpoint("dev", 1)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is not signed")
+	}
+
+	// A current checkpoint that does not match its signature is not recorded in the history.
+	assert.NoError(t, writeObject(b.bucket, b.stackPath("dev"), forged))
+	assert.Error(t, b.addToHistory("dev", backend.UpdateInfo{Kind: "update", Result: backend.SucceededResult}))
+}
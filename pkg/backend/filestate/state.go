@@ -15,9 +15,10 @@
 package filestate
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -26,7 +27,6 @@ import (
 
 	"github.com/pkg/errors"
 
-	"github.com/pulumi/pulumi/pkg/apitype"
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/encoding"
 	"github.com/pulumi/pulumi/pkg/resource/config"
@@ -35,13 +35,17 @@ import (
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
-	"github.com/pulumi/pulumi/pkg/util/fsutil"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 const DisableCheckpointBackupsEnvVar = "PULUMI_DISABLE_CHECKPOINT_BACKUPS"
 
+// EncryptCheckpointsEnvVar may be set to a truthy value to encrypt checkpoints at rest using the stack's secrets
+// crypter.  Checkpoints contain resource outputs, which frequently include sensitive values in plaintext.
+const EncryptCheckpointsEnvVar = "PULUMI_ENCRYPT_CHECKPOINTS"
+
 // DisableIntegrityChecking can be set to true to disable checkpoint state integrity verification.  This is not
 // recommended, because it could mean proceeding even in the face of a corrupted checkpoint state file, but can
 // be used as a last resort when a command absolutely must be run.
@@ -99,6 +103,10 @@ func (b *localBackend) getTarget(stackName tokens.QName) (*deploy.Target, error)
 	if err != nil {
 		return nil, err
 	}
+	credentials, err := stk.ResolveCredentials(filepath.Dir(stackConfigFile))
+	if err != nil {
+		return nil, err
+	}
 	decrypter, err := defaultCrypter(stackName, stk.Config, stackConfigFile)
 	if err != nil {
 		return nil, err
@@ -108,10 +116,11 @@ func (b *localBackend) getTarget(stackName tokens.QName) (*deploy.Target, error)
 		return nil, err
 	}
 	return &deploy.Target{
-		Name:      stackName,
-		Config:    stk.Config,
-		Decrypter: decrypter,
-		Snapshot:  snapshot,
+		Name:        stackName,
+		Config:      stk.Config,
+		Decrypter:   decrypter,
+		Snapshot:    snapshot,
+		Credentials: credentials,
 	}, nil
 }
 
@@ -122,37 +131,151 @@ func (b *localBackend) getStack(name tokens.QName) (config.Map, *deploy.Snapshot
 
 	file := b.stackPath(name)
 
-	chk, err := b.getCheckpoint(name)
+	cfg, snapshot, err := b.getCheckpoint(name)
 	if err != nil {
 		return nil, nil, file, errors.Wrap(err, "failed to load checkpoint")
 	}
 
-	// Materialize an actual snapshot object.
-	snapshot, err := stack.DeserializeCheckpoint(chk)
-	if err != nil {
-		return nil, nil, "", err
-	}
-
 	// Ensure the snapshot passes verification before returning it, to catch bugs early.
 	if !DisableIntegrityChecking {
 		if verifyerr := snapshot.VerifyIntegrity(); verifyerr != nil {
 			return nil, nil, file,
-				errors.Wrapf(verifyerr, "%s: snapshot integrity failure; refusing to use it", file)
+				errors.Wrapf(verifyerr, "%s: snapshot integrity failure; refusing to use it", b.bucket.Location(file))
 		}
 	}
 
-	return chk.Config, snapshot, file, nil
+	return cfg, snapshot, file, nil
 }
 
 // GetCheckpoint loads a checkpoint file for the given stack in this project, from the current project workspace.
-func (b *localBackend) getCheckpoint(stackName tokens.QName) (*apitype.CheckpointV3, error) {
+func (b *localBackend) getCheckpoint(stackName tokens.QName) (config.Map, *deploy.Snapshot, error) {
 	chkpath := b.stackPath(stackName)
-	bytes, err := ioutil.ReadFile(chkpath)
+
+	// Remember the serial number of the checkpoint the first time we read it, so that saveStack can detect writes by
+	// other processes. The serial number is read first so that a concurrent write causes a spurious conflict rather
+	// than a missed one.
+	serial, err := b.readCheckpointSerial(chkpath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	b.serialLock.Lock()
+	if _, has := b.serials[stackName]; !has {
+		b.serials[stackName] = serial
+	}
+	b.serialLock.Unlock()
+
+	return b.readCheckpoint(stackName, chkpath)
+}
+
+// readCheckpointSerial returns the serial number of the checkpoint file at the given path, or zero if the file does
+// not exist or has no serial number.
+func (b *localBackend) readCheckpointSerial(chkpath string) (int64, error) {
+	f, err := b.bucket.NewReader(chkpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer contract.IgnoreClose(f)
+
+	return stack.ReadCheckpointSerial(bufio.NewReader(f))
+}
+
+// nextCheckpointSerial returns the serial number with which the next checkpoint for the given stack should be written.
+// It fails if the checkpoint on disk has been written by someone else since this backend last read or wrote it.
+//
+// Note that this check is optimistic: a write by another process that races with this one may go undetected, unless
+// the backend's bucket makes its writes conditional (as Google Cloud Storage buckets do).
+func (b *localBackend) nextCheckpointSerial(name tokens.QName, file string) (int64, error) {
+	serial, err := b.readCheckpointSerial(file)
+	if err != nil {
+		return 0, err
+	}
+
+	b.serialLock.Lock()
+	defer b.serialLock.Unlock()
+	if expected, has := b.serials[name]; has && serial != expected {
+		return 0, errutil.WithCode(errutil.ErrStackChanged, "", errors.Errorf(
+			"the state of stack '%s' changed underneath you, most likely because another update is running "+
+				"(expected checkpoint serial %d, found %d); re-run the command", name, expected, serial))
+	}
+	return serial + 1, nil
+}
+
+// readCheckpoint loads the checkpoint file at the given path, verifying its signature and decrypting it if necessary,
+// and returns the stack's configuration and its snapshot (which is nil if the stack has never been deployed).
+func (b *localBackend) readCheckpoint(stackName tokens.QName,
+	chkpath string) (config.Map, *deploy.Snapshot, error) {
+
+	// Signed checkpoints are read in their entirety, so that the contents whose signature is checked are the contents
+	// that are used.
+	if checkpointSigningKey() != nil {
+		byts, err := b.readSignedCheckpoint(chkpath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return b.decodeCheckpoint(stackName, b.bucket.Location(chkpath), byts)
+	}
+
+	// Otherwise, most checkpoints can be decoded a resource at a time, without reading the whole file into memory.
+	f, err := b.bucket.NewReader(chkpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, snapshot, err := stack.DecodeCheckpoint(bufio.NewReader(f))
+	contract.IgnoreClose(f)
+	if err != stack.ErrCheckpointNotStreamable {
+		return cfg, snapshot, err
+	}
+
+	// Otherwise, the checkpoint is either encrypted or uses an older schema, so read it in its entirety.
+	bytes, err := readObject(b.bucket, chkpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b.parseCheckpoint(stackName, b.bucket.Location(chkpath), bytes)
+}
+
+// decodeCheckpoint decodes the given checkpoint, which was read from the given location, in the same way as
+// readCheckpoint.
+func (b *localBackend) decodeCheckpoint(stackName tokens.QName, location string,
+	byts []byte) (config.Map, *deploy.Snapshot, error) {
+
+	cfg, snapshot, err := stack.DecodeCheckpoint(bufio.NewReader(bytes.NewReader(byts)))
+	if err != stack.ErrCheckpointNotStreamable {
+		return cfg, snapshot, err
+	}
+	return b.parseCheckpoint(stackName, location, byts)
+}
+
+// parseCheckpoint deserializes a checkpoint that could not be streamed, either because it is encrypted or because it
+// uses an older schema.
+func (b *localBackend) parseCheckpoint(stackName tokens.QName, location string,
+	bytes []byte) (config.Map, *deploy.Snapshot, error) {
+
+	// If the checkpoint was encrypted at rest, decrypt it before handing it off to the usual deserialization logic.
+	if ciphertext, encrypted := isEncryptedCheckpoint(bytes); encrypted {
+		var err error
+		if bytes, err = b.decryptCheckpoint(stackName, ciphertext); err != nil {
+			return nil, nil, errors.Wrapf(err, "decrypting checkpoint %s", location)
+		}
 	}
 
-	return stack.UnmarshalVersionedCheckpointToLatestCheckpoint(bytes)
+	chk, report, err := stack.MigrateCheckpoint(bytes)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "reading checkpoint %s", location)
+	}
+	if report.Migrated() {
+		// The checkpoint will be written using the current schema version the next time the stack is saved.
+		logging.V(5).Infof("migrated checkpoint %s from schema version %d to %d",
+			location, report.FromVersion, report.ToVersion)
+	}
+	snapshot, err := stack.DeserializeCheckpoint(chk)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chk.Config, snapshot, nil
 }
 
 func (b *localBackend) saveStack(name tokens.QName,
@@ -163,33 +286,60 @@ func (b *localBackend) saveStack(name tokens.QName,
 	if m == nil {
 		return "", errors.Errorf("resource serialization failed; illegal markup extension: '%v'", ext)
 	}
-	if filepath.Ext(file) == "" {
+	if path.Ext(file) == "" {
 		file = file + ext
 	}
-	chk := stack.SerializeCheckpoint(name, config, snap)
-	byts, err := m.Marshal(chk)
+
+	// Refuse to overwrite a checkpoint that someone else has written since we last saw it.
+	serial, err := b.nextCheckpointSerial(name, file)
 	if err != nil {
-		return "", errors.Wrap(err, "An IO error occurred during the current operation")
+		return "", err
 	}
 
-	// Back up the existing file if it already exists.
-	bck := backupTarget(file)
+	// Unless the checkpoint needs to be transformed or signed before it is written, it is streamed straight to disk
+	// below, which avoids holding the serialized form of the entire checkpoint in memory at once.
+	var byts []byte
+	encrypt := cmdutil.IsTruthy(os.Getenv(EncryptCheckpointsEnvVar))
+	if !m.IsJSONLike() || encrypt || checkpointSigningKey() != nil {
+		chk := stack.SerializeCheckpoint(name, config, snap)
+		chk.Serial = serial
+		if byts, err = m.Marshal(chk); err != nil {
+			return "", errors.Wrap(err, "An IO error occurred during the current operation")
+		}
 
-	// Ensure the directory exists.
-	if err = os.MkdirAll(filepath.Dir(file), 0700); err != nil {
-		return "", errors.Wrap(err, "An IO error occurred during the current operation")
+		// If requested, encrypt the checkpoint before it ever touches the disk.
+		if encrypt {
+			if byts, err = b.encryptCheckpoint(name, m, byts, serial); err != nil {
+				return "", errors.Wrap(err, "encrypting checkpoint")
+			}
+		}
+	}
+	writeCheckpoint := func(path string) error {
+		if byts != nil {
+			return writeObject(b.bucket, path, byts)
+		}
+		return b.writeCheckpointFile(path, name, config, snap, serial)
 	}
 
+	// Back up the existing file if it already exists.
+	bck := b.backupTarget(file)
+
 	// And now write out the new snapshot file, overwriting that location.
-	if err = ioutil.WriteFile(file, byts, 0600); err != nil {
+	if err := writeCheckpoint(file); err != nil {
 		return "", errors.Wrap(err, "An IO error occurred during the current operation")
 	}
+	b.serialLock.Lock()
+	b.serials[name] = serial
+	b.serialLock.Unlock()
+	if err := b.signCheckpoint(file, byts); err != nil {
+		return "", errors.Wrap(err, "signing checkpoint")
+	}
 
-	logging.V(7).Infof("Saved stack %s checkpoint to: %s (backup=%s)", name, file, bck)
+	logging.V(7).Infof("Saved stack %s checkpoint to: %s (backup=%s)", name, b.bucket.Location(file), bck)
 
 	// And if we are retaining historical checkpoint information, write it out again
 	if cmdutil.IsTruthy(os.Getenv("PULUMI_RETAIN_CHECKPOINTS")) {
-		if err = ioutil.WriteFile(fmt.Sprintf("%v.%v", file, time.Now().UnixNano()), byts, 0600); err != nil {
+		if err := writeCheckpoint(fmt.Sprintf("%v.%v", file, time.Now().UnixNano())); err != nil {
 			return "", errors.Wrap(err, "An IO error occurred during the current operation")
 		}
 	}
@@ -201,31 +351,54 @@ func (b *localBackend) saveStack(name tokens.QName,
 		if verifyerr := snap.VerifyIntegrity(); verifyerr != nil {
 			return "", errors.Wrapf(verifyerr,
 				"%s: snapshot integrity failure; it was already written, but is invalid (backup available at %s)",
-				file, bck)
+				b.bucket.Location(file), b.bucket.Location(bck))
 		}
 	}
 
 	return file, nil
 }
 
+// writeCheckpointFile streams the checkpoint for the given stack to the file at the given path.
+func (b *localBackend) writeCheckpointFile(path string, name tokens.QName, config config.Map, snap *deploy.Snapshot,
+	serial int64) error {
+	f, err := b.bucket.NewWriter(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if err = stack.WriteCheckpoint(w, name, config, snap, serial); err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 // removeStack removes information about a stack from the current workspace.
 func (b *localBackend) removeStack(name tokens.QName) error {
 	contract.Require(name != "", "name")
 
 	// Just make a backup of the file and don't write out anything new.
 	file := b.stackPath(name)
-	backupTarget(file)
+	b.backupTarget(file)
+	contract.IgnoreError(b.bucket.Delete(checkpointSignaturePath(file)))
+
+	b.serialLock.Lock()
+	delete(b.serials, name)
+	b.serialLock.Unlock()
 
 	historyDir := b.historyDirectory(name)
-	return os.RemoveAll(historyDir)
+	return b.bucket.DeleteAll(historyDir)
 }
 
 // backupTarget makes a backup of an existing file, in preparation for writing a new one.  Instead of a copy, it
 // simply renames the file, which is simpler, more efficient, etc.
-func backupTarget(file string) string {
+func (b *localBackend) backupTarget(file string) string {
 	contract.Require(file != "", "file")
 	bck := file + ".bak"
-	err := os.Rename(file, bck)
+	err := b.bucket.Rename(file, bck)
 	contract.IgnoreError(err) // ignore errors.
 	// IDEA: consider multiple backups (.bak.bak.bak...etc).
 	return bck
@@ -242,7 +415,7 @@ func (b *localBackend) backupStack(name tokens.QName) error {
 
 	// Read the current checkpoint file. (Assuming it aleady exists.)
 	stackPath := b.stackPath(name)
-	byts, err := ioutil.ReadFile(stackPath)
+	byts, err := readObject(b.bucket, stackPath)
 	if err != nil {
 		return err
 	}
@@ -250,111 +423,141 @@ func (b *localBackend) backupStack(name tokens.QName) error {
 	// Get the backup directory.
 	backupDir := b.backupDirectory(name)
 
-	// Ensure the backup directory exists.
-	if err = os.MkdirAll(backupDir, 0700); err != nil {
-		return err
-	}
-
 	// Write out the new backup checkpoint file.
-	stackFile := filepath.Base(stackPath)
-	ext := filepath.Ext(stackFile)
+	stackFile := path.Base(stackPath)
+	ext := path.Ext(stackFile)
 	base := strings.TrimSuffix(stackFile, ext)
 	backupFile := fmt.Sprintf("%s.%v%s", base, time.Now().UnixNano(), ext)
-	return ioutil.WriteFile(filepath.Join(backupDir, backupFile), byts, 0600)
+	return writeObject(b.bucket, path.Join(backupDir, backupFile), byts)
 }
 
-func (b *localBackend) stackPath(stack tokens.QName) string {
-	path := filepath.Join(b.StateDir(), workspace.StackDir)
-	if stack != "" {
-		path = filepath.Join(path, fsutil.QnamePath(stack)+".json")
-	}
+// stacksDirectory returns the directory beneath which the checkpoints of stacks are stored.
+func stacksDirectory() string {
+	return path.Join(workspace.BookkeepingDir, workspace.StackDir)
+}
 
-	return path
+func (b *localBackend) stackPath(stack tokens.QName) string {
+	contract.Require(stack != "", "stack")
+	return path.Join(stacksDirectory(), string(stack)+".json")
 }
 
 func (b *localBackend) historyDirectory(stack tokens.QName) string {
 	contract.Require(stack != "", "stack")
-	return filepath.Join(b.StateDir(), workspace.HistoryDir, fsutil.QnamePath(stack))
+	return path.Join(workspace.BookkeepingDir, workspace.HistoryDir, string(stack))
 }
 
 func (b *localBackend) backupDirectory(stack tokens.QName) string {
 	contract.Require(stack != "", "stack")
-	return filepath.Join(b.StateDir(), workspace.BackupDir, fsutil.QnamePath(stack))
+	return path.Join(workspace.BookkeepingDir, workspace.BackupDir, string(stack))
 }
 
-// getHistory returns locally stored update history. The first element of the result will be
-// the most recent update record.
-func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, error) {
+// historyFiles returns the paths of the locally stored update history records for the given stack, without their
+// ".history.json" suffix. The first element of the result is the oldest update record.
+func (b *localBackend) historyFiles(name tokens.QName) ([]string, error) {
 	contract.Require(name != "", "name")
 
+	// History doesn't exist until a stack has been updated, in which case the list is empty.
 	dir := b.historyDirectory(name)
-	allFiles, err := ioutil.ReadDir(dir)
+	allFiles, err := b.bucket.List(dir)
 	if err != nil {
-		// History doesn't exist until a stack has been updated.
-		if os.IsNotExist(err) {
-			return nil, nil
+		return nil, err
+	}
+
+	// The list is sorted by file name, and because of how we name files, older updates come before newer ones.
+	var prefixes []string
+	for _, file := range allFiles {
+		// Collect all of the history files, ignoring the checkpoints.
+		if strings.HasSuffix(file, ".history.json") {
+			prefixes = append(prefixes, path.Join(dir, strings.TrimSuffix(file, ".history.json")))
 		}
+	}
+
+	return prefixes, nil
+}
+
+// getHistory returns locally stored update history. The first element of the result will be
+// the most recent update record.
+func (b *localBackend) getHistory(name tokens.QName) ([]backend.UpdateInfo, error) {
+	prefixes, err := b.historyFiles(name)
+	if err != nil {
 		return nil, err
 	}
 
 	var updates []backend.UpdateInfo
 
-	// os.ReadDir returns the array sorted by file name, but because of how we name files, older updates come before
-	// newer ones. Loop backwards so we added the newest updates to the array we will return first.
-	for i := len(allFiles) - 1; i >= 0; i-- {
-		file := allFiles[i]
-		filepath := path.Join(dir, file.Name())
-
-		// Open all of the history files, ignoring the checkpoints.
-		if !strings.HasSuffix(filepath, ".history.json") {
-			continue
-		}
+	// Loop backwards so we added the newest updates to the array we will return first.
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		filepath := fmt.Sprintf("%s.history.json", prefixes[i])
 
 		var update backend.UpdateInfo
-		b, err := ioutil.ReadFile(filepath)
+		byts, err := readObject(b.bucket, filepath)
 		if err != nil {
-			return nil, errors.Wrapf(err, "reading history file %s", filepath)
+			return nil, errors.Wrapf(err, "reading history file %s", b.bucket.Location(filepath))
 		}
-		err = json.Unmarshal(b, &update)
+		err = json.Unmarshal(byts, &update)
 		if err != nil {
-			return nil, errors.Wrapf(err, "reading history file %s", filepath)
+			return nil, errors.Wrapf(err, "reading history file %s", b.bucket.Location(filepath))
 		}
 
+		// Versions are not recorded in the history files themselves; they are implied by the order of the files.
+		update.Version = i + 1
+
 		updates = append(updates, update)
 	}
 
 	return updates, nil
 }
 
-// addToHistory saves the UpdateInfo and makes a copy of the current Checkpoint file.
+// getHistoricalCheckpoint loads the snapshot from the checkpoint that was recorded alongside the given version of the
+// stack's update history. Versions start at 1.
+func (b *localBackend) getHistoricalCheckpoint(name tokens.QName, version int) (*deploy.Snapshot, error) {
+	prefixes, err := b.historyFiles(name)
+	if err != nil {
+		return nil, err
+	}
+	if version < 1 || version > len(prefixes) {
+		return nil, errors.Errorf("stack '%s' has no update with version %d", name, version)
+	}
+
+	// Checkpoints are stored as chunks, except for those recorded by older versions of the CLI, which are copies.
+	manifestPath := fmt.Sprintf("%s.checkpoint.chunks.json", prefixes[version-1])
+	byts, err := b.readChunkedCheckpoint(name, manifestPath)
+	if os.IsNotExist(err) {
+		_, snapshot, err := b.readCheckpoint(name, fmt.Sprintf("%s.checkpoint.json", prefixes[version-1]))
+		return snapshot, err
+	} else if err != nil {
+		return nil, err
+	}
+	_, snapshot, err := b.decodeCheckpoint(name, b.bucket.Location(manifestPath), byts)
+	return snapshot, err
+}
+
+// addToHistory saves the UpdateInfo along with the chunks of the current Checkpoint file that are not already part of
+// the stack's history.
 func (b *localBackend) addToHistory(name tokens.QName, update backend.UpdateInfo) error {
 	contract.Require(name != "", "name")
 
 	dir := b.historyDirectory(name)
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return err
-	}
 
 	// Prefix for the update and checkpoint files.
 	pathPrefix := path.Join(dir, fmt.Sprintf("%s-%d", name, time.Now().UnixNano()))
 
-	// Save the history file.
-	byts, err := json.MarshalIndent(&update, "", "    ")
+	// Save the checkpoint first, so that every update in the history has one. (Assuming it aleady exists.)
+	checkpoint, err := b.readSignedCheckpoint(b.stackPath(name))
 	if err != nil {
 		return err
 	}
-
-	historyFile := fmt.Sprintf("%s.history.json", pathPrefix)
-	if err = ioutil.WriteFile(historyFile, byts, os.ModePerm); err != nil {
+	manifestFile := fmt.Sprintf("%s.checkpoint.chunks.json", pathPrefix)
+	if err = b.writeChunkedCheckpoint(name, manifestFile, checkpoint); err != nil {
 		return err
 	}
 
-	// Make a copy of the checkpoint file. (Assuming it aleady exists.)
-	byts, err = ioutil.ReadFile(b.stackPath(name))
+	// Save the history file.
+	byts, err := json.MarshalIndent(&update, "", "    ")
 	if err != nil {
 		return err
 	}
 
-	checkpointFile := fmt.Sprintf("%s.checkpoint.json", pathPrefix)
-	return ioutil.WriteFile(checkpointFile, byts, os.ModePerm)
+	historyFile := fmt.Sprintf("%s.history.json", pathPrefix)
+	return writeObject(b.bucket, historyFile, byts)
 }
@@ -27,7 +27,6 @@ import (
 	"os"
 	"path"
 	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -53,6 +52,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/retry"
 	"github.com/pulumi/pulumi/pkg/workspace"
@@ -132,12 +132,16 @@ type Backend interface {
 
 	CloudURL() string
 
+	// CurrentUserOrganizations returns the names of the organizations that the current user is a member of.
+	CurrentUserOrganizations(ctx context.Context) ([]string, error)
+
 	DownloadPlugin(
 		ctx context.Context, info workspace.PluginInfo,
-		progress bool, opts display.Options) (io.ReadCloser, error)
+		progress *pb.Pool, opts display.Options) (io.ReadCloser, error)
 
 	CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference) error
 	StackConsoleURL(stackRef backend.StackReference) (string, error)
+	ResourceConsoleURL(stackRef backend.StackReference, urn resource.URN) (string, error)
 }
 
 type cloudBackend struct {
@@ -146,6 +150,7 @@ type cloudBackend struct {
 	stackConfigFile string
 	client          *client.Client
 	currentProject  *workspace.Project
+	readOnly        bool // true if the backend was logged into in read-only mode.
 }
 
 // New creates a new Pulumi backend for the given cloud API URL and token.
@@ -155,6 +160,17 @@ func New(d diag.Sink, cloudURL, stackConfigFile string) (Backend, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "getting stored credentials")
 	}
+	refreshed, refreshErr := refreshExpiringAccessToken(context.Background(), cloudURL, apiToken)
+	if refreshErr != nil {
+		// Carry on with the expired token, so that commands that do not need the service, such as logout, still work.
+		d.Warningf(diag.Message("" /*urn*/, "%v"), refreshErr)
+	} else {
+		apiToken = refreshed
+	}
+	if apiToken == "" {
+		// Fall back to the access token in the environment, if any, so that CI systems need not log in first.
+		apiToken = os.Getenv(AccessTokenEnvVar)
+	}
 
 	// When stringifying backend references, we take the current project (if present) into account.
 	currentProject, err := workspace.DetectProject()
@@ -168,6 +184,7 @@ func New(d diag.Sink, cloudURL, stackConfigFile string) (Backend, error) {
 		stackConfigFile: stackConfigFile,
 		client:          client.NewClient(cloudURL, apiToken, d),
 		currentProject:  currentProject,
+		readOnly:        workspace.IsReadOnlyBackend(cloudURL),
 	}, nil
 }
 
@@ -251,9 +268,14 @@ func loginWithBrowser(ctx context.Context, d diag.Sink, cloudURL, stackConfigFil
 func Login(ctx context.Context, d diag.Sink, cloudURL, stackConfigFile string, opts display.Options) (Backend, error) {
 	cloudURL = ValueOrDefaultURL(cloudURL)
 
-	// If we have a saved access token, and it is valid, use it.
+	// If we have a saved access token, and it is valid, use it. An access token in the environment takes precedence,
+	// however, so that CI systems act as the account whose token they were given.
+	envToken := os.Getenv(AccessTokenEnvVar)
 	existingToken, err := workspace.GetAccessToken(cloudURL)
-	if err == nil && existingToken != "" {
+	if err == nil && existingToken != "" && (envToken == "" || envToken == existingToken) {
+		if refreshed, refreshErr := refreshExpiringAccessToken(ctx, cloudURL, existingToken); refreshErr == nil {
+			existingToken = refreshed
+		}
 		if valid, _ := IsValidAccessToken(ctx, cloudURL, existingToken); valid {
 			// Save the token. While it hasn't changed this will update the current cloud we are logged into, as well.
 			if err = workspace.StoreAccessToken(cloudURL, existingToken, true); err != nil {
@@ -262,11 +284,16 @@ func Login(ctx context.Context, d diag.Sink, cloudURL, stackConfigFile string, o
 
 			return New(d, cloudURL, stackConfigFile)
 		}
+
+		// If the token was obtained through single sign-on, log in through the same identity provider again.
+		if login, loginErr := workspace.GetSSOLogin(cloudURL); loginErr == nil && login != nil && envToken == "" {
+			return LoginWithSSO(ctx, d, cloudURL, stackConfigFile, login.Issuer, login.ClientID)
+		}
 	}
 
 	// We intentionally don't accept command-line args for the user's access token. Having it in
 	// .bash_history is not great, and specifying it via flag isn't of much use.
-	accessToken := os.Getenv(AccessTokenEnvVar)
+	accessToken := envToken
 	accountLink := cloudConsoleURL(cloudURL, "account", "tokens")
 
 	if accessToken != "" {
@@ -332,12 +359,22 @@ func Login(ctx context.Context, d diag.Sink, cloudURL, stackConfigFile string, o
 		}
 	}
 
+	return LoginWithToken(ctx, d, cloudURL, stackConfigFile, accessToken)
+}
+
+// LoginWithToken logs into the target cloud URL using the given access token, without prompting, and returns the cloud
+// backend for it.
+func LoginWithToken(ctx context.Context, d diag.Sink, cloudURL, stackConfigFile, accessToken string) (Backend, error) {
+	cloudURL = ValueOrDefaultURL(cloudURL)
+
 	// Try and use the credentials to see if they are valid.
 	valid, err := IsValidAccessToken(ctx, cloudURL, accessToken)
 	if err != nil {
 		return nil, err
 	} else if !valid {
-		return nil, errors.Errorf("invalid access token")
+		return nil, errutil.WithCode(errutil.ErrAuthInvalidToken,
+			"the token may have been revoked; create a new one in the Pulumi console and run `pulumi login` again",
+			errors.Errorf("invalid access token"))
 	}
 
 	// Save them.
@@ -363,6 +400,15 @@ func (b *cloudBackend) StackConsoleURL(stackRef backend.StackReference) (string,
 	return url, nil
 }
 
+// ResourceConsoleURL returns a link to the page of the resource with the given URN in the cloud console.
+func (b *cloudBackend) ResourceConsoleURL(stackRef backend.StackReference, urn resource.URN) (string, error) {
+	stackURL, err := b.StackConsoleURL(stackRef)
+	if err != nil {
+		return "", err
+	}
+	return joinConsoleURL(stackURL, consoleResourcePath(urn)), nil
+}
+
 func (b *cloudBackend) Name() string {
 	if b.url == PulumiCloudURL {
 		return "pulumi.com"
@@ -379,10 +425,27 @@ func (b *cloudBackend) URL() string {
 	return cloudConsoleURL(b.url, user)
 }
 
+func (b *cloudBackend) ReadOnly() bool {
+	return b.readOnly || backend.ReadOnly
+}
+
 func (b *cloudBackend) CurrentUser() (string, error) {
 	return b.client.GetPulumiAccountName(context.Background())
 }
 
+func (b *cloudBackend) CurrentUserOrganizations(ctx context.Context) ([]string, error) {
+	user, err := b.client.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orgs []string
+	for _, org := range user.Organizations {
+		orgs = append(orgs, org.GitHubLogin)
+	}
+	return orgs, nil
+}
+
 func (b *cloudBackend) CloudURL() string { return b.url }
 
 func (b *cloudBackend) ParseStackReference(s string) (backend.StackReference, error) {
@@ -468,44 +531,58 @@ func (b *cloudBackend) Logout() error {
 }
 
 // DownloadPlugin downloads a plugin as a tarball from the release endpoint.  The returned reader is a stream
-// that reads the tar.gz file, which should be expanded and closed after the download completes.  If progress
-// is true, the download will display a progress bar using stdout.
+// that reads the tar.gz file, which should be expanded and closed after the download completes.  The tarball is
+// verified against its published checksum and signature as described by workspace.PluginInfo.VerifyDownload, and so
+// has been downloaded in full by the time DownloadPlugin returns.  If progress is not nil, a bar that shows the
+// download's progress is added to it.
 func (b *cloudBackend) DownloadPlugin(ctx context.Context, info workspace.PluginInfo,
-	progress bool, opts display.Options) (io.ReadCloser, error) {
-
-	// Figure out the OS/ARCH pair for the download URL.
-	var os string
-	switch runtime.GOOS {
-	case "darwin", "linux", "windows":
-		os = runtime.GOOS
-	default:
-		return nil, errors.Errorf("unsupported plugin OS: %s", runtime.GOOS)
-	}
-	var arch string
-	switch runtime.GOARCH {
-	case "amd64":
-		arch = runtime.GOARCH
-	default:
-		return nil, errors.Errorf("unsupported plugin architecture: %s", runtime.GOARCH)
-	}
+	progress *pb.Pool, opts display.Options) (io.ReadCloser, error) {
+
+	var result io.ReadCloser
+	var size int64
+	var fetch workspace.PluginFileFetcher
+	if workspace.HasPluginMirror() {
+		// Plugins are downloaded from the mirror rather than the service.
+		var err error
+		if result, size, fetch, err = info.OpenDownload(); err != nil {
+			return nil, errors.Wrapf(err, "failed to download plugin")
+		}
+	} else {
+		// Figure out the OS/ARCH pair for the download URL.
+		os, arch, err := workspace.PluginPlatform()
+		if err != nil {
+			return nil, err
+		}
 
-	// Now make the client request.
-	result, size, err := b.client.DownloadPlugin(ctx, info, os, arch)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to download plugin")
+		// Now make the client request.
+		if result, size, err = b.client.DownloadPlugin(ctx, info, os, arch); err != nil {
+			return nil, errors.Wrapf(err, "failed to download plugin")
+		}
+		fetch = func(suffix string) (io.ReadCloser, error) {
+			return b.client.DownloadPluginFile(ctx, info, os, arch, suffix)
+		}
 	}
 
-	// If progress is requested, and we know the length, show a little animated ASCII progress bar.
-	if progress && size != -1 {
-		bar := pb.New(int(size))
+	// If progress is requested, show a little animated ASCII progress bar as the tarball is downloaded.
+	if progress != nil {
+		total := size
+		if total == -1 {
+			total = 0 // the bar counts the bytes downloaded so far, without a total.
+		}
+		bar := pb.New64(total)
 		result = newBarProxyReadCloser(bar, result)
-		bar.Prefix(opts.Color.Colorize(colors.SpecUnimportant + "Downloading plugin: "))
+		bar.Prefix(opts.Color.Colorize(
+			fmt.Sprintf("%sDownloading %s plugin %s: ", colors.SpecUnimportant, info.Kind, info)))
 		bar.Postfix(opts.Color.Colorize(colors.Reset))
-		bar.SetMaxWidth(80)
+		bar.SetMaxWidth(100)
 		bar.SetUnits(pb.U_BYTES)
-		bar.Start()
+		progress.Add(bar)
 	}
 
+	result, _, err := info.VerifyDownload(result, size, fetch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to verify plugin")
+	}
 	return result, nil
 }
 
@@ -530,6 +607,10 @@ func (b *cloudBackend) GetStack(ctx context.Context, stackRef backend.StackRefer
 func (b *cloudBackend) CreateStack(
 	ctx context.Context, stackRef backend.StackReference, _ interface{} /* No custom options for httpstate backend. */) (
 	backend.Stack, error) {
+	if err := backend.CheckWritable(b, "create stack '%s'", stackRef); err != nil {
+		return nil, err
+	}
+
 	stackID, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
 		return nil, err
@@ -583,6 +664,10 @@ func (b *cloudBackend) ListStacks(
 }
 
 func (b *cloudBackend) RemoveStack(ctx context.Context, stackRef backend.StackReference, force bool) (bool, error) {
+	if err := backend.CheckWritable(b, "remove stack '%s'", stackRef); err != nil {
+		return false, err
+	}
+
 	stack, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
 		return false, err
@@ -647,7 +732,7 @@ func (b *cloudBackend) Preview(ctx context.Context, stackRef backend.StackRefere
 	// We can skip PreviewtThenPromptThenExecute, and just go straight to Execute.
 	opts := backend.ApplierOptions{
 		DryRun:   true,
-		ShowLink: true,
+		ShowLink: !op.Opts.Display.IsJSON(),
 	}
 	return b.apply(
 		ctx, apitype.PreviewUpdate, stack, op, opts, nil /*events*/)
@@ -709,7 +794,7 @@ func (b *cloudBackend) createAndStartUpdate(
 	update, err := b.client.CreateUpdate(
 		ctx, action, stackID, op.Proj, workspaceStack.Config, metadata, op.Opts.Engine, dryRun)
 	if err != nil {
-		return client.UpdateIdentifier{}, 0, "", err
+		return client.UpdateIdentifier{}, 0, "", b.describeConflict(ctx, stackRef, stackID, err)
 	}
 
 	// Start the update. We use this opportunity to pass new tags to the service, to pick up any
@@ -720,7 +805,7 @@ func (b *cloudBackend) createAndStartUpdate(
 	}
 	version, token, err := b.client.StartUpdate(ctx, update, tags)
 	if err != nil {
-		return client.UpdateIdentifier{}, 0, "", err
+		return client.UpdateIdentifier{}, 0, "", b.describeConflict(ctx, stackRef, stackID, err)
 	}
 	// Any non-preview update will be considered part of the stack's update history.
 	if action != apitype.PreviewUpdate {
@@ -730,13 +815,70 @@ func (b *cloudBackend) createAndStartUpdate(
 	return update, version, token, nil
 }
 
+// describeConflict replaces an error that the service returned because another update of the stack is in progress
+// with a backend.UpdateConflictError that describes the update in progress, as recorded by the stack's history. Other
+// errors are returned unchanged.
+func (b *cloudBackend) describeConflict(ctx context.Context, stackRef backend.StackReference,
+	stackID client.StackIdentifier, err error) error {
+
+	errResp, ok := err.(*apitype.ErrorResponse)
+	if !ok || errResp.Code != http.StatusConflict {
+		return err
+	}
+
+	updates, historyErr := b.client.GetStackUpdates(ctx, stackID)
+	if historyErr != nil {
+		logging.V(3).Infof("error fetching the history of %s: %v", stackRef, historyErr)
+		return err
+	}
+	for _, update := range updates {
+		if update.Result != apitype.InProgressResult {
+			continue
+		}
+
+		conflict := backend.UpdateConflictError{
+			StackName: stackRef.String(),
+			Kind:      update.Kind,
+			User:      update.Environment[backend.ExecUser],
+			Hostname:  update.Environment[backend.ExecHostname],
+		}
+		if update.StartTime != 0 {
+			conflict.StartTime = time.Unix(update.StartTime, 0)
+		}
+		if conflict.User == "" {
+			conflict.User = update.Environment[backend.GitAuthor]
+		}
+		if pid, pidErr := strconv.Atoi(update.Environment[backend.ExecPID]); pidErr == nil {
+			conflict.PID = pid
+		}
+
+		// Link to the update in the console if possible, or else to the CI build that is running it.
+		if update.Version != 0 {
+			conflict.URL = b.CloudConsoleURL(b.cloudConsoleStackPath(stackID), "updates", strconv.Itoa(update.Version))
+		}
+		if conflict.URL == "" {
+			conflict.URL = update.Environment[backend.CIBuildURL]
+		}
+		return conflict
+	}
+	return err
+}
+
 // apply actually performs the provided type of update on a stack hosted in the Pulumi Cloud.
 func (b *cloudBackend) apply(ctx context.Context, kind apitype.UpdateKind, stack backend.Stack,
 	op backend.UpdateOperation, opts backend.ApplierOptions, events chan<- engine.Event) (engine.ResourceChanges, error) {
 	// Print a banner so it's clear this is going to the cloud.
 	actionLabel := backend.ActionLabel(kind, opts.DryRun)
-	fmt.Printf(op.Opts.Display.Color.Colorize(
-		colors.SpecHeadline+"%s (%s):"+colors.Reset+"\n"), actionLabel, stack.Ref())
+	if !op.Opts.Display.IsJSON() {
+		fmt.Printf(op.Opts.Display.Color.Colorize(
+			colors.SpecHeadline+"%s (%s):"+colors.Reset+"\n"), actionLabel, stack.Ref())
+	}
+
+	// The service delivers webhooks of its own, so the CLI only posts the project's webhooks for other backends.
+	if len(op.Proj.Webhooks) > 0 && !opts.DryRun {
+		b.d.Warningf(diag.Message("" /*urn*/, "the webhooks in Pulumi.yaml are not posted for stacks in the Pulumi "+
+			"service; configure webhooks in the service instead"))
+	}
 
 	// Create an update object to persist results.
 	update, version, token, err := b.createAndStartUpdate(ctx, kind, stack, op, opts.DryRun)
@@ -744,22 +886,30 @@ func (b *cloudBackend) apply(ctx context.Context, kind apitype.UpdateKind, stack
 		return nil, err
 	}
 
-	if opts.ShowLink {
-		// Print a URL at the end of the update pointing to the Pulumi Service.
-		var link string
-		base := b.cloudConsoleStackPath(update.StackIdentifier)
-		if !opts.DryRun {
-			link = b.CloudConsoleURL(base, "updates", strconv.Itoa(version))
-		} else {
-			link = b.CloudConsoleURL(base, "previews", update.UpdateID)
+	// Link to the update and to its resources in the Pulumi Service, both from the display and, in case the update is
+	// being watched, as soon as it starts.
+	var link string
+	base := b.cloudConsoleStackPath(update.StackIdentifier)
+	if !opts.DryRun {
+		link = b.CloudConsoleURL(base, "updates", strconv.Itoa(version))
+	} else {
+		link = b.CloudConsoleURL(base, "previews", update.UpdateID)
+	}
+	if link != "" {
+		op.Opts.Display.Permalink = link
+		op.Opts.Display.ResourceURL = func(urn resource.URN) string {
+			return b.CloudConsoleURL(base, consoleResourcePath(urn))
 		}
-		if link != "" {
-			defer func() {
+
+		if opts.ShowLink {
+			printLink := func(label string) {
 				fmt.Printf(
 					op.Opts.Display.Color.Colorize(
-						colors.SpecHeadline+"Permalink: "+
+						colors.SpecHeadline+label+": "+
 							colors.Underline+colors.BrightBlue+"%s"+colors.Reset+"\n"), link)
-			}()
+			}
+			printLink("View Live")
+			defer printLink("Permalink")
 		}
 	}
 
@@ -857,6 +1007,10 @@ func (b *cloudBackend) runEngineAction(
 }
 
 func (b *cloudBackend) CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference) error {
+	if err := backend.CheckWritable(b, "cancel the update of stack '%s'", stackRef); err != nil {
+		return err
+	}
+
 	stackID, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
 		return err
@@ -902,6 +1056,7 @@ func (b *cloudBackend) GetHistory(ctx context.Context, stackRef backend.StackRef
 		}
 
 		beUpdates = append(beUpdates, backend.UpdateInfo{
+			Version:         update.Version,
 			Kind:            update.Kind,
 			Message:         update.Message,
 			Environment:     update.Environment,
@@ -968,7 +1123,7 @@ func (b *cloudBackend) GetLogs(ctx context.Context, stackRef backend.StackRefere
 	if targetErr != nil {
 		return nil, targetErr
 	}
-	return filestate.GetLogsForTarget(target, logQuery)
+	return filestate.GetLogsForTarget(ctx, target, logQuery)
 }
 
 func (b *cloudBackend) ExportDeployment(ctx context.Context,
@@ -979,7 +1134,23 @@ func (b *cloudBackend) ExportDeployment(ctx context.Context,
 		return nil, err
 	}
 
-	deployment, err := b.client.ExportStackDeployment(ctx, stack)
+	deployment, err := b.client.ExportStackDeployment(ctx, stack, nil /*version*/)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deployment, nil
+}
+
+func (b *cloudBackend) ExportHistoricalDeployment(ctx context.Context,
+	stackRef backend.StackReference, version int) (*apitype.UntypedDeployment, error) {
+
+	stack, err := b.getCloudStackIdentifier(stackRef)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := b.client.ExportStackDeployment(ctx, stack, &version)
 	if err != nil {
 		return nil, err
 	}
@@ -990,6 +1161,10 @@ func (b *cloudBackend) ExportDeployment(ctx context.Context,
 func (b *cloudBackend) ImportDeployment(ctx context.Context, stackRef backend.StackReference,
 	deployment *apitype.UntypedDeployment) error {
 
+	if err := backend.CheckWritable(b, "import a deployment into stack '%s'", stackRef); err != nil {
+		return err
+	}
+
 	stack, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
 		return err
@@ -1003,7 +1178,7 @@ func (b *cloudBackend) ImportDeployment(ctx context.Context, stackRef backend.St
 	// Wait for the import to complete, which also polls and renders event output to STDOUT.
 	status, err := b.waitForUpdate(
 		ctx, backend.ActionLabel(apitype.ImportUpdate, false /*dryRun*/), update,
-		display.Options{Color: colors.Always})
+		display.Options{Color: cmdutil.GetGlobalColorization()})
 	if err != nil {
 		return errors.Wrap(err, "waiting for import")
 	} else if status != apitype.StatusSucceeded {
@@ -1216,6 +1391,10 @@ func (b *cloudBackend) GetStackTags(ctx context.Context,
 func (b *cloudBackend) UpdateStackTags(ctx context.Context,
 	stackRef backend.StackReference, tags map[apitype.StackTagName]string) error {
 
+	if err := backend.CheckWritable(b, "change the tags of stack '%s'", stackRef); err != nil {
+		return err
+	}
+
 	stack, err := b.getCloudStackIdentifier(stackRef)
 	if err != nil {
 		return err
@@ -1240,5 +1419,5 @@ func (c httpstateBackendClient) GetStackOutputs(ctx context.Context, name string
 }
 
 func (c httpstateBackendClient) DownloadPlugin(ctx context.Context, plug workspace.PluginInfo) (io.ReadCloser, error) {
-	return c.backend.DownloadPlugin(ctx, plug, false, display.Options{})
+	return c.backend.DownloadPlugin(ctx, plug, nil, display.Options{})
 }
@@ -0,0 +1,157 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstate
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// fakeUserService is a minimal Pulumi service that reports the user of each access token that it accepts.
+type fakeUserService struct {
+	t             *testing.T
+	users         map[string]string // the user of each access token that the service accepts.
+	organizations []apitype.OrganizationSummary
+}
+
+func (s *fakeUserService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	assert.Equal(s.t, "/api/user", req.URL.Path)
+	user, ok := s.users[strings.TrimPrefix(req.Header.Get("Authorization"), "token ")]
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		assert.NoError(s.t, json.NewEncoder(w).Encode(apitype.ErrorResponse{Code: 401, Message: "unauthorized"}))
+		return
+	}
+	assert.NoError(s.t, json.NewEncoder(w).Encode(apitype.User{GitHubLogin: user, Organizations: s.organizations}))
+}
+
+// useTestCredentials stores credentials in a temporary directory until the returned function is called.
+func useTestCredentials(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "pulumi-httpstate-test")
+	assert.NoError(t, err)
+	os.Setenv(workspace.PulumiCredentialsPathEnvVar, dir)
+	workspace.InsecureCredentialStorage = true
+	return func() {
+		workspace.InsecureCredentialStorage = false
+		os.Unsetenv(workspace.PulumiCredentialsPathEnvVar)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCurrentUserOrganizations(t *testing.T) {
+	defer useTestCredentials(t)()
+
+	service := &fakeUserService{t: t, users: map[string]string{"secret": "alice"},
+		organizations: []apitype.OrganizationSummary{{GitHubLogin: "acme"}, {GitHubLogin: "initech"}}}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	b, err := LoginWithToken(context.Background(), cmdutil.Diag(), server.URL, "", "secret")
+	assert.NoError(t, err)
+	name, err := b.CurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", name)
+	orgs, err := b.CurrentUserOrganizations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"acme", "initech"}, orgs)
+
+	// A user who is not a member of any organization has none.
+	service.organizations = nil
+	orgs, err = b.CurrentUserOrganizations(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, orgs)
+}
+
+func TestLoginWithToken(t *testing.T) {
+	defer useTestCredentials(t)()
+
+	server := httptest.NewServer(&fakeUserService{t: t, users: map[string]string{"secret": "alice"}})
+	defer server.Close()
+	ctx := context.Background()
+
+	// Invalid tokens are rejected without being stored.
+	_, err := LoginWithToken(ctx, cmdutil.Diag(), server.URL, "", "wrong")
+	assert.Error(t, err)
+	token, err := workspace.GetAccessToken(server.URL)
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+
+	// Valid ones are stored, and the service becomes the current backend.
+	_, err = LoginWithToken(ctx, cmdutil.Diag(), server.URL, "", "secret")
+	assert.NoError(t, err)
+	token, err = workspace.GetAccessToken(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", token)
+	creds, err := workspace.GetStoredCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL, creds.Current)
+}
+
+func TestAccessTokenEnvVar(t *testing.T) {
+	defer useTestCredentials(t)()
+
+	server := httptest.NewServer(&fakeUserService{t: t, users: map[string]string{"stored": "alice", "env": "ci"}})
+	defer server.Close()
+	ctx := context.Background()
+	defer os.Unsetenv(AccessTokenEnvVar)
+
+	// A service that has not been logged into is used with the access token in the environment.
+	os.Setenv(AccessTokenEnvVar, "env")
+	b, err := New(cmdutil.Diag(), server.URL, "")
+	assert.NoError(t, err)
+	name, err := b.CurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "ci", name)
+
+	// A stored access token is used in preference to the environment's by commands other than login...
+	assert.NoError(t, workspace.StoreAccessToken(server.URL, "stored", true))
+	b, err = New(cmdutil.Diag(), server.URL, "")
+	assert.NoError(t, err)
+	name, err = b.CurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", name)
+
+	// ... but logging in replaces it with the environment's.
+	b, err = Login(ctx, cmdutil.Diag(), server.URL, "", display.Options{})
+	assert.NoError(t, err)
+	name, err = b.CurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "ci", name)
+	token, err := workspace.GetAccessToken(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "env", token)
+
+	// Without it, the stored access token is used.
+	os.Unsetenv(AccessTokenEnvVar)
+	assert.NoError(t, workspace.StoreAccessToken(server.URL, "stored", true))
+	b, err = Login(ctx, cmdutil.Diag(), server.URL, "", display.Options{})
+	assert.NoError(t, err)
+	name, err = b.CurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", name)
+}
@@ -81,6 +81,10 @@ type httpCallOptions struct {
 	// RetryAllMethods allows non-GET calls to be retried if the server fails to return a response.
 	RetryAllMethods bool
 
+	// RetryPersistently retries calls that fail for a couple of minutes, rather than for a few seconds. It is used for
+	// calls, such as writes of the checkpoint, whose failure would lose the results of an update.
+	RetryPersistently bool
+
 	// GzipCompress compresses the request using gzip before sending it.
 	GzipCompress bool
 }
@@ -153,7 +157,7 @@ func pulumiAPICall(ctx context.Context, d diag.Sink, cloudAPI, method, path stri
 		opentracing.Tag{Key: "method", Value: method},
 		opentracing.Tag{Key: "path", Value: path},
 		opentracing.Tag{Key: "api", Value: cloudAPI},
-		opentracing.Tag{Key: "retry", Value: opts.RetryAllMethods})
+		opentracing.Tag{Key: "retry", Value: opts.RetryAllMethods || opts.RetryPersistently})
 	defer requestSpan.Finish()
 
 	req = req.WithContext(requestContext)
@@ -196,7 +200,9 @@ func pulumiAPICall(ctx context.Context, d diag.Sink, cloudAPI, method, path stri
 	}
 
 	var resp *http.Response
-	if req.Method == "GET" || opts.RetryAllMethods {
+	if opts.RetryPersistently {
+		resp, err = httputil.DoWithRetryOpts(req, http.DefaultClient, httputil.PersistentRetryOpts)
+	} else if req.Method == "GET" || opts.RetryAllMethods {
 		resp, err = httputil.DoWithRetry(req, http.DefaultClient)
 	} else {
 		resp, err = http.DefaultClient.Do(req)
@@ -83,6 +83,7 @@ func init() {
 	addEndpoint("DELETE", "/api/stacks/{orgName}/{stackName}", "deleteStack")
 	addEndpoint("GET", "/api/stacks/{orgName}/{stackName}", "getStack")
 	addEndpoint("GET", "/api/stacks/{orgName}/{stackName}/export", "exportStack")
+	addEndpoint("GET", "/api/stacks/{orgName}/{stackName}/export/{version}", "exportStackVersion")
 	addEndpoint("POST", "/api/stacks/{orgName}/{stackName}/import", "importStack")
 	addEndpoint("POST", "/api/stacks/{orgName}/{stackName}/encrypt", "encryptValue")
 	addEndpoint("POST", "/api/stacks/{orgName}/{stackName}/decrypt", "decryptValue")
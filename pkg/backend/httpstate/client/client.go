@@ -96,39 +96,63 @@ func getUpdatePath(update UpdateIdentifier, components ...string) string {
 	return getStackPath(update.StackIdentifier, components...)
 }
 
+// getPluginPath returns the API path to the given plugin's tarball for the given platform.
+func getPluginPath(info workspace.PluginInfo, os, arch string) string {
+	return fmt.Sprintf("/releases/plugins/pulumi-%s-%s-v%s-%s-%s.tar.gz", info.Kind, info.Name, info.Version, os, arch)
+}
+
 // GetPulumiAccountName returns the user implied by the API token associated with this client.
 func (pc *Client) GetPulumiAccountName(ctx context.Context) (string, error) {
 	if pc.apiUser == "" {
-		resp := struct {
-			GitHubLogin string `json:"githubLogin"`
-		}{}
-		if err := pc.restCall(ctx, "GET", "/api/user", nil, nil, &resp); err != nil {
+		if _, err := pc.GetCurrentUser(ctx); err != nil {
 			return "", err
 		}
+	}
 
-		if resp.GitHubLogin == "" {
-			return "", errors.New("unexpected response from server")
-		}
+	return pc.apiUser, nil
+}
 
-		pc.apiUser = resp.GitHubLogin
+// GetCurrentUser returns the user implied by the API token associated with this client, along with the organizations
+// that they are a member of.
+func (pc *Client) GetCurrentUser(ctx context.Context) (apitype.User, error) {
+	var user apitype.User
+	if err := pc.restCall(ctx, "GET", "/api/user", nil, nil, &user); err != nil {
+		return apitype.User{}, err
 	}
 
-	return pc.apiUser, nil
+	if user.GitHubLogin == "" {
+		return apitype.User{}, errors.New("unexpected response from server")
+	}
+
+	pc.apiUser = user.GitHubLogin
+	return user, nil
 }
 
 // DownloadPlugin downloads the indicated plugin from the Pulumi API.
 func (pc *Client) DownloadPlugin(ctx context.Context, info workspace.PluginInfo, os,
 	arch string) (io.ReadCloser, int64, error) {
 
-	endpoint := fmt.Sprintf("/releases/plugins/pulumi-%s-%s-v%s-%s-%s.tar.gz",
-		info.Kind, info.Name, info.Version, os, arch)
-	_, resp, err := pc.apiCall(ctx, "GET", endpoint, nil)
+	_, resp, err := pc.apiCall(ctx, "GET", getPluginPath(info, os, arch), nil)
 	if err != nil {
 		return nil, 0, err
 	}
 	return resp.Body, resp.ContentLength, nil
 }
 
+// DownloadPluginFile downloads the file with the given suffix (e.g. ".sha256") that is published alongside the
+// indicated plugin's tarball by the Pulumi API. If there is no such file, it returns nil and no error.
+func (pc *Client) DownloadPluginFile(ctx context.Context, info workspace.PluginInfo, os, arch,
+	suffix string) (io.ReadCloser, error) {
+
+	_, resp, err := pc.apiCall(ctx, "GET", getPluginPath(info, os, arch)+suffix, nil)
+	if errResp, ok := err.(*apitype.ErrorResponse); ok && errResp.Code == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // GetCLIVersionInfo asks the service for information about versions of the CLI (the newest version as well as the
 // oldest version before the CLI should warn about an upgrade).
 func (pc *Client) GetCLIVersionInfo(ctx context.Context) (semver.Version, semver.Version, error) {
@@ -313,12 +337,18 @@ func (pc *Client) GetStackUpdates(ctx context.Context, stack StackIdentifier) ([
 	return response.Updates, nil
 }
 
-// ExportStackDeployment exports the indicated stack's deployment as a raw JSON message.
+// ExportStackDeployment exports the indicated stack's deployment as a raw JSON message. If version is non-nil, the
+// deployment recorded by that version of the stack's update history is exported instead of the latest deployment.
 func (pc *Client) ExportStackDeployment(ctx context.Context,
-	stack StackIdentifier) (apitype.UntypedDeployment, error) {
+	stack StackIdentifier, version *int) (apitype.UntypedDeployment, error) {
+
+	path := getStackPath(stack, "export")
+	if version != nil {
+		path += fmt.Sprintf("/%d", *version)
+	}
 
 	var resp apitype.ExportStackResponse
-	if err := pc.restCall(ctx, "GET", getStackPath(stack, "export"), nil, nil, &resp); err != nil {
+	if err := pc.restCall(ctx, "GET", path, nil, nil, &resp); err != nil {
 		return apitype.UntypedDeployment{}, err
 	}
 
@@ -496,9 +526,10 @@ func (pc *Client) PatchUpdateCheckpoint(ctx context.Context, update UpdateIdenti
 	}
 
 	// It is safe to retry this PATCH operation, because it is logically idempotent, since we send the entire
-	// deployment instead of a set of changes to apply.
+	// deployment instead of a set of changes to apply. Because losing the checkpoint would lose track of the resources
+	// that the update has created, we retry it for longer than most calls.
 	return pc.updateRESTCall(ctx, "PATCH", getUpdatePath(update, "checkpoint"), nil, req, nil,
-		updateAccessToken(token), httpCallOptions{RetryAllMethods: true, GzipCompress: true})
+		updateAccessToken(token), httpCallOptions{RetryPersistently: true, GzipCompress: true})
 }
 
 // CancelUpdate cancels the indicated update.
@@ -519,7 +550,7 @@ func (pc *Client) CompleteUpdate(ctx context.Context, update UpdateIdentifier, s
 
 	// It is safe to retry this PATCH operation, because it is logically idempotent.
 	return pc.updateRESTCall(ctx, "POST", getUpdatePath(update, "complete"), nil, req, nil,
-		updateAccessToken(token), httpCallOptions{RetryAllMethods: true})
+		updateAccessToken(token), httpCallOptions{RetryPersistently: true})
 }
 
 // RecordEngineEvent posts an engine event to the Pulumi service.
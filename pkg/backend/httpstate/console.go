@@ -16,11 +16,24 @@ package httpstate
 
 import (
 	"net/url"
+	"os"
 	"path"
 	"strings"
+
+	"github.com/pulumi/pulumi/pkg/resource"
 )
 
-func cloudConsoleURL(cloudURL string, paths ...string) string {
+// ConsoleURLEnvVar may be set to the base URL of the web console of the Pulumi service, for self-hosted services
+// whose console's URL cannot be derived from their API's URL.
+const ConsoleURLEnvVar = "PULUMI_CONSOLE_URL"
+
+// consoleBaseURL returns the base URL of the web console of the Pulumi service with the given API URL, or the empty
+// string if it cannot be determined.
+func consoleBaseURL(cloudURL string) string {
+	if base := os.Getenv(ConsoleURLEnvVar); base != "" {
+		return base
+	}
+
 	u, err := url.Parse(cloudURL)
 	if err != nil {
 		return ""
@@ -35,6 +48,35 @@ func cloudConsoleURL(cloudURL string, paths ...string) string {
 		return "" // We couldn't figure out how to convert the api hostname into a console hostname
 	}
 
-	u.Path = path.Join(paths...)
+	u.Path = ""
+	return u.String()
+}
+
+// joinConsoleURL appends the given path elements, which must already be escaped, to the given console base URL. If the
+// base URL is empty or invalid, the result is empty.
+func joinConsoleURL(base string, paths ...string) string {
+	if base == "" {
+		return ""
+	}
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	rawPath := path.Join(append([]string{"/", u.EscapedPath()}, paths...)...)
+	if u.Path, err = url.PathUnescape(rawPath); err != nil {
+		return ""
+	}
+	u.RawPath = rawPath
 	return u.String()
 }
+
+func cloudConsoleURL(cloudURL string, paths ...string) string {
+	return joinConsoleURL(consoleBaseURL(cloudURL), paths...)
+}
+
+// consoleResourcePath returns the path, relative to a stack's console page, of the page of the resource with the given
+// URN.
+func consoleResourcePath(urn resource.URN) string {
+	return path.Join("resources", url.PathEscape(string(urn)))
+}
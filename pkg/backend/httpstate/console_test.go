@@ -4,7 +4,7 @@
 // you may not use this file except in compliance with the License.
 // You may obtain a copy of the License at
 //
-//     http://www.apache.org/licenses/LICENSE-2.0
+//	http://www.apache.org/licenses/LICENSE-2.0
 //
 // Unless required by applicable law or agreed to in writing, software
 // distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,6 +14,7 @@
 package httpstate
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +37,28 @@ func TestConsoleURL(t *testing.T) {
 
 	assert.Equal(t, "", cloudConsoleURL("not-even-a-rea-url", "pulumi-bot", "my-stack"))
 }
+
+func TestConsoleURLOverride(t *testing.T) {
+	os.Setenv(ConsoleURLEnvVar, "https://pulumi.example.com/console")
+	defer os.Unsetenv(ConsoleURLEnvVar)
+
+	assert.Equal(t,
+		"https://pulumi.example.com/console/pulumi-bot/my-stack",
+		cloudConsoleURL("https://example.com", "pulumi-bot", "my-stack"))
+
+	assert.Equal(t,
+		"https://pulumi.example.com/console/pulumi-bot/my-stack",
+		cloudConsoleURL("https://api.pulumi.com", "pulumi-bot", "my-stack"))
+
+	os.Setenv(ConsoleURLEnvVar, "not-even-a-rea-url")
+	assert.Equal(t, "", cloudConsoleURL("https://api.pulumi.com", "pulumi-bot", "my-stack"))
+}
+
+func TestResourceConsoleURL(t *testing.T) {
+	stackURL := cloudConsoleURL("https://api.pulumi.com", "pulumi-bot", "my-project", "my-stack")
+	assert.Equal(t,
+		"https://app.pulumi.com/pulumi-bot/my-project/my-stack/resources/"+
+			"urn:pulumi:my-stack::my-project::aws:s3%2Fbucket:Bucket::my-bucket",
+		joinConsoleURL(stackURL,
+			consoleResourcePath("urn:pulumi:my-stack::my-project::aws:s3/bucket:Bucket::my-bucket")))
+}
@@ -0,0 +1,361 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/skratchdot/open-golang/open"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+const (
+	// SSOIssuerEnvVar may be set to the URL of the OpenID Connect identity provider through which `pulumi login`
+	// logs in, as an alternative to passing --sso-issuer.
+	SSOIssuerEnvVar = "PULUMI_SSO_ISSUER"
+	// SSOClientIDEnvVar may be set to the OAuth client ID with which the CLI identifies itself to the identity
+	// provider.
+	SSOClientIDEnvVar = "PULUMI_SSO_CLIENT_ID"
+	// DefaultSSOClientID is the OAuth client ID used when none is specified.
+	DefaultSSOClientID = "pulumi-cli"
+
+	// ssoScope is the scope requested from the identity provider; offline_access asks for a refresh token.
+	ssoScope = "openid profile email offline_access"
+	// ssoRefreshMargin is how long before its expiry an access token is refreshed, so that it does not expire while a
+	// command is running.
+	ssoRefreshMargin = 5 * time.Minute
+)
+
+// ssoPollUnit is the unit of the polling interval that identity providers return, which tests shorten.
+var ssoPollUnit = time.Second
+
+// oidcProvider holds the endpoints of an OpenID Connect identity provider, as advertised by its discovery document.
+type oidcProvider struct {
+	Issuer                      string `json:"issuer"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+}
+
+// deviceAuthorization is an identity provider's response to a device authorization request (RFC 8628, section 3.2).
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// oauthToken is an identity provider's response to a token request (RFC 6749, sections 5.1 and 5.2).
+type oauthToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// oauthError is returned when an identity provider rejects a token request.
+type oauthError struct {
+	Code        string
+	Description string
+}
+
+func (e *oauthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+// discoverOIDCProvider fetches the discovery document of the identity provider with the given issuer URL.
+func discoverOIDCProvider(ctx context.Context, issuer string) (*oidcProvider, error) {
+	discoveryURL := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequest("GET", discoveryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", discoveryURL)
+	}
+	defer contract.IgnoreClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching %s: %s", discoveryURL, resp.Status)
+	}
+
+	var provider oidcProvider
+	if err = json.NewDecoder(resp.Body).Decode(&provider); err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", discoveryURL)
+	}
+	if provider.DeviceAuthorizationEndpoint == "" {
+		return nil, errors.Errorf("the identity provider at %s does not support the device authorization flow", issuer)
+	}
+	if provider.TokenEndpoint == "" {
+		return nil, errors.Errorf("the identity provider at %s does not have a token endpoint", issuer)
+	}
+	return &provider, nil
+}
+
+// postForm posts the given form to an identity provider's endpoint and decodes the JSON response into result. Responses
+// with error statuses are decoded too, as OAuth endpoints describe their errors in the response body.
+func postForm(ctx context.Context, endpoint string, form url.Values, result interface{}) (int, error) {
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, errors.Wrapf(err, "POST %s", endpoint)
+	}
+	defer contract.IgnoreClose(resp.Body)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, errors.Wrapf(err, "POST %s", endpoint)
+	}
+	if err = json.Unmarshal(body, result); err != nil {
+		return resp.StatusCode, errors.Errorf("POST %s: %s: unexpected response", endpoint, resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// requestToken sends a token request with the given parameters, returning an *oauthError if the provider rejects it.
+func requestToken(ctx context.Context, tokenURL string, form url.Values) (*oauthToken, error) {
+	var token oauthToken
+	status, err := postForm(ctx, tokenURL, form, &token)
+	if err != nil {
+		return nil, err
+	}
+	if token.Error != "" {
+		return nil, &oauthError{Code: token.Error, Description: token.ErrorDescription}
+	}
+	if status != http.StatusOK || token.AccessToken == "" {
+		return nil, errors.Errorf("POST %s: unexpected response (status %d)", tokenURL, status)
+	}
+	return &token, nil
+}
+
+// pollDeviceToken polls the identity provider's token endpoint until the user approves or denies the given device
+// authorization, or it expires (RFC 8628, section 3.4).
+func pollDeviceToken(ctx context.Context, tokenURL, clientID string, auth *deviceAuthorization) (*oauthToken, error) {
+	interval := time.Duration(auth.Interval) * ssoPollUnit
+	if auth.Interval <= 0 {
+		interval = 5 * ssoPollUnit
+	}
+	var deadline <-chan time.Time
+	if auth.ExpiresIn > 0 {
+		deadline = time.After(time.Duration(auth.ExpiresIn) * ssoPollUnit)
+	}
+
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {auth.DeviceCode},
+		"client_id":   {clientID},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, errors.New("the login code expired before the login was approved")
+		case <-time.After(interval):
+		}
+
+		token, err := requestToken(ctx, tokenURL, form)
+		if oauthErr, ok := err.(*oauthError); ok {
+			switch oauthErr.Code {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * ssoPollUnit
+				continue
+			case "access_denied":
+				return nil, errors.New("the login was denied")
+			case "expired_token":
+				return nil, errors.New("the login code expired before the login was approved")
+			}
+		}
+		return token, err
+	}
+}
+
+// refreshSSOToken uses the given login's refresh token to obtain a new access token, which is stored underneath the
+// given cloud URL along with the updated login.
+func refreshSSOToken(ctx context.Context, cloudURL string, login *workspace.SSOLogin) (string, error) {
+	if login.RefreshToken == "" {
+		return "", errors.Errorf("your single sign-on session for %s has expired; run `pulumi login` to log in again",
+			cloudURL)
+	}
+
+	token, err := requestToken(ctx, login.TokenURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {login.RefreshToken},
+		"client_id":     {login.ClientID},
+	})
+	if err != nil {
+		if _, ok := err.(*oauthError); ok {
+			return "", errors.Wrapf(err, "your single sign-on session for %s could not be renewed; run `pulumi login` "+
+				"to log in again", cloudURL)
+		}
+		return "", errors.Wrap(err, "refreshing the access token")
+	}
+
+	updated := *login
+	updated.Expiry = tokenExpiry(token)
+	if token.RefreshToken != "" {
+		// Providers that rotate refresh tokens invalidate the old one as soon as it is used.
+		updated.RefreshToken = token.RefreshToken
+	}
+	if err = workspace.StoreSSOLogin(cloudURL, token.AccessToken, updated, false); err != nil {
+		return "", errors.Wrap(err, "saving credentials")
+	}
+	logging.V(7).Infof("refreshed the access token for %s, which now expires at %v", cloudURL, updated.Expiry)
+	return token.AccessToken, nil
+}
+
+// tokenExpiry returns the time at which the given token expires, or the zero time if the provider did not say.
+func tokenExpiry(token *oauthToken) time.Time {
+	if token.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+}
+
+// refreshExpiringAccessToken returns the given access token, which is stored for the given cloud URL, or, if it was
+// obtained through single sign-on and is about to expire, a new access token with which it has been replaced.
+func refreshExpiringAccessToken(ctx context.Context, cloudURL, token string) (string, error) {
+	if token == "" {
+		return token, nil
+	}
+	login, err := workspace.GetSSOLogin(cloudURL)
+	if err != nil || login == nil {
+		return token, err
+	}
+	if login.Expiry.IsZero() || time.Until(login.Expiry) > ssoRefreshMargin {
+		return token, nil
+	}
+	return refreshSSOToken(ctx, cloudURL, login)
+}
+
+// validSSOToken returns the access token stored for the given cloud URL, refreshed if need be, if it was obtained
+// through single sign-on with the given identity provider and client ID and is accepted by the service. Otherwise, it
+// returns the empty string.
+func validSSOToken(ctx context.Context, cloudURL, issuer, clientID string) string {
+	login, err := workspace.GetSSOLogin(cloudURL)
+	if err != nil || login == nil || login.Issuer != issuer || login.ClientID != clientID {
+		return ""
+	}
+	token, err := workspace.GetAccessToken(cloudURL)
+	if err != nil {
+		return ""
+	}
+	if token, err = refreshExpiringAccessToken(ctx, cloudURL, token); err != nil {
+		return ""
+	}
+	if valid, _ := IsValidAccessToken(ctx, cloudURL, token); !valid {
+		return ""
+	}
+	return token
+}
+
+// LoginWithSSO logs into the target cloud URL through single sign-on with the OpenID Connect identity provider at the
+// given issuer URL, using the OAuth device authorization flow, and returns the cloud backend for it. The user approves
+// the login in a web browser, which need not be on the same machine. The Pulumi service must be configured to accept
+// the access tokens that the identity provider issues; they are refreshed automatically as they expire.
+func LoginWithSSO(ctx context.Context, d diag.Sink, cloudURL, stackConfigFile, issuer,
+	clientID string) (Backend, error) {
+
+	cloudURL = ValueOrDefaultURL(cloudURL)
+	if clientID == "" {
+		clientID = DefaultSSOClientID
+	}
+
+	// If we are already logged in through the same identity provider, and the access token is still valid (or can be
+	// refreshed), use it.
+	if token := validSSOToken(ctx, cloudURL, issuer, clientID); token != "" {
+		if err := workspace.StoreAccessToken(cloudURL, token, true); err != nil {
+			return nil, err
+		}
+		return New(d, cloudURL, stackConfigFile)
+	}
+
+	provider, err := discoverOIDCProvider(ctx, issuer)
+	if err != nil {
+		return nil, errors.Wrap(err, "discovering the identity provider")
+	}
+
+	var auth deviceAuthorization
+	status, err := postForm(ctx, provider.DeviceAuthorizationEndpoint, url.Values{
+		"client_id": {clientID},
+		"scope":     {ssoScope},
+	}, &auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "requesting a login code")
+	}
+	if status != http.StatusOK || auth.DeviceCode == "" || auth.VerificationURI == "" {
+		return nil, errors.Errorf("requesting a login code: the identity provider refused (status %d)", status)
+	}
+
+	// Print the code before trying to launch a browser, so that users on headless machines can finish the login on
+	// another device.
+	fmt.Printf("To log in, visit %s and enter the code %s\n", auth.VerificationURI, auth.UserCode)
+	if auth.VerificationURIComplete != "" {
+		if openErr := open.Run(auth.VerificationURIComplete); openErr == nil {
+			fmt.Println("We've launched your web browser to complete the login process.")
+		}
+	}
+	fmt.Println("\nWaiting for login to complete...")
+
+	token, err := pollDeviceToken(ctx, provider.TokenEndpoint, clientID, &auth)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := IsValidAccessToken(ctx, cloudURL, token.AccessToken)
+	if err != nil {
+		return nil, err
+	} else if !valid {
+		return nil, errors.Errorf("%s does not accept access tokens issued by %s", cloudURL, issuer)
+	}
+
+	login := workspace.SSOLogin{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		TokenURL:     provider.TokenEndpoint,
+		Expiry:       tokenExpiry(token),
+		RefreshToken: token.RefreshToken,
+	}
+	if err = workspace.StoreSSOLogin(cloudURL, token.AccessToken, login, true); err != nil {
+		return nil, err
+	}
+
+	return New(d, cloudURL, stackConfigFile)
+}
@@ -0,0 +1,207 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpstate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/backend/display"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// fakeIdentityProvider is a minimal OpenID Connect identity provider that supports the device authorization flow, and
+// that also plays the part of a Pulumi service that accepts the access tokens it issues.
+type fakeIdentityProvider struct {
+	t   *testing.T
+	url string
+
+	lock      sync.Mutex
+	polls     int             // the number of times the device code has been polled for.
+	issued    int             // the number of access tokens issued so far.
+	valid     map[string]bool // the access tokens that the service accepts.
+	refreshes map[string]bool // the refresh tokens that have not been used yet.
+}
+
+func (p *fakeIdentityProvider) reply(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	assert.NoError(p.t, json.NewEncoder(w).Encode(body))
+}
+
+// issue replies with a new access token and refresh token.
+func (p *fakeIdentityProvider) issue(w http.ResponseWriter) {
+	p.issued++
+	access, refresh := fmt.Sprintf("access-%d", p.issued), fmt.Sprintf("refresh-%d", p.issued)
+	p.valid[access], p.refreshes[refresh] = true, true
+	p.reply(w, http.StatusOK, map[string]interface{}{
+		"access_token":  access,
+		"refresh_token": refresh,
+		"token_type":    "Bearer",
+		"expires_in":    3600,
+	})
+}
+
+func (p *fakeIdentityProvider) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	assert.NoError(p.t, req.ParseForm())
+	switch req.URL.Path {
+	case "/.well-known/openid-configuration":
+		p.reply(w, http.StatusOK, map[string]string{
+			"issuer":                        p.url,
+			"device_authorization_endpoint": p.url + "/device",
+			"token_endpoint":                p.url + "/token",
+		})
+	case "/device":
+		assert.Equal(p.t, "pulumi-cli", req.PostForm.Get("client_id"))
+		assert.Contains(p.t, req.PostForm.Get("scope"), "offline_access")
+		p.reply(w, http.StatusOK, map[string]interface{}{
+			"device_code":      "device",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": p.url + "/activate",
+			"expires_in":       600,
+			"interval":         1,
+		})
+	case "/token":
+		assert.Equal(p.t, "pulumi-cli", req.PostForm.Get("client_id"))
+		switch req.PostForm.Get("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			assert.Equal(p.t, "device", req.PostForm.Get("device_code"))
+			p.polls++
+			switch p.polls {
+			case 1:
+				p.reply(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+			case 2:
+				p.reply(w, http.StatusBadRequest, map[string]string{"error": "slow_down"})
+			default:
+				p.issue(w)
+			}
+		case "refresh_token":
+			// Refresh tokens are rotated: each may be used only once.
+			refresh := req.PostForm.Get("refresh_token")
+			if !p.refreshes[refresh] {
+				p.reply(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+				return
+			}
+			delete(p.refreshes, refresh)
+			p.issue(w)
+		default:
+			p.reply(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		}
+	case "/api/user":
+		if !p.valid[strings.TrimPrefix(req.Header.Get("Authorization"), "token ")] {
+			p.reply(w, http.StatusUnauthorized, map[string]interface{}{"code": 401, "message": "unauthorized"})
+			return
+		}
+		p.reply(w, http.StatusOK, map[string]string{"githubLogin": "alice"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// expireSSOLogin makes the stored access token for the given cloud URL appear to be about to expire.
+func expireSSOLogin(t *testing.T, cloudURL string) {
+	creds, err := workspace.GetStoredCredentials()
+	assert.NoError(t, err)
+	login := creds.SSOLogins[cloudURL]
+	login.Expiry = time.Now().Add(time.Minute)
+	creds.SSOLogins[cloudURL] = login
+	assert.NoError(t, workspace.StoreCredentials(creds))
+}
+
+func TestLoginWithSSO(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-sso-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	os.Setenv(workspace.PulumiCredentialsPathEnvVar, dir)
+	defer os.Unsetenv(workspace.PulumiCredentialsPathEnvVar)
+	workspace.InsecureCredentialStorage = true
+	defer func() { workspace.InsecureCredentialStorage = false }()
+	defer func(old time.Duration) { ssoPollUnit = old }(ssoPollUnit)
+	ssoPollUnit = time.Millisecond
+
+	provider := &fakeIdentityProvider{t: t, valid: make(map[string]bool), refreshes: make(map[string]bool)}
+	server := httptest.NewServer(provider)
+	defer server.Close()
+	provider.url = server.URL
+	ctx := context.Background()
+
+	// Logging in waits until the login is approved, and stores the access token along with how to refresh it.
+	b, err := LoginWithSSO(ctx, cmdutil.Diag(), server.URL, "", server.URL, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, provider.polls)
+	assert.Equal(t, server.URL, b.CloudURL())
+	token, err := workspace.GetAccessToken(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "access-1", token)
+	login, err := workspace.GetSSOLogin(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL, login.Issuer)
+	assert.Equal(t, "pulumi-cli", login.ClientID)
+	assert.Equal(t, server.URL+"/token", login.TokenURL)
+	assert.Equal(t, "refresh-1", login.RefreshToken)
+	assert.True(t, login.Expiry.After(time.Now().Add(time.Hour-time.Minute)))
+
+	// Logging in again through the same provider reuses the token.
+	_, err = LoginWithSSO(ctx, cmdutil.Diag(), server.URL, "", server.URL, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, provider.issued)
+
+	// Tokens that are about to expire are refreshed when the backend is created, and the rotated refresh token is kept.
+	expireSSOLogin(t, server.URL)
+	_, err = New(cmdutil.Diag(), server.URL, "")
+	assert.NoError(t, err)
+	token, err = workspace.GetAccessToken(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "access-2", token)
+	login, err = workspace.GetSSOLogin(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh-2", login.RefreshToken)
+
+	// If the token cannot be refreshed, the expired token is kept, and logging in goes through the provider again.
+	expireSSOLogin(t, server.URL)
+	provider.refreshes = make(map[string]bool)
+	provider.valid = make(map[string]bool)
+	_, err = New(cmdutil.Diag(), server.URL, "")
+	assert.NoError(t, err)
+	token, err = workspace.GetAccessToken(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "access-2", token)
+	_, err = Login(ctx, cmdutil.Diag(), server.URL, "", display.Options{})
+	assert.NoError(t, err)
+	token, err = workspace.GetAccessToken(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "access-3", token)
+
+	// Logging in with an ordinary access token forgets the single sign-on login.
+	assert.NoError(t, workspace.StoreAccessToken(server.URL, "personal", true))
+	login, err = workspace.GetSSOLogin(server.URL)
+	assert.NoError(t, err)
+	assert.Nil(t, login)
+}
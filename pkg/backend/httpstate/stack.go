@@ -23,6 +23,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/backend"
 	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/operations"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/tokens"
@@ -36,6 +37,9 @@ type Stack interface {
 	OrgName() string                       // the organization that owns this stack.
 	ConsoleURL() (string, error)           // the URL to view the stack's information on Pulumi.com
 	Tags() map[apitype.StackTagName]string // the stack's tags.
+
+	// ResourceConsoleURL returns the URL to view the given resource's information on Pulumi.com.
+	ResourceConsoleURL(urn resource.URN) (string, error)
 }
 
 type cloudBackendReference struct {
@@ -159,6 +163,10 @@ func (s *cloudStack) ConsoleURL() (string, error) {
 	return s.b.StackConsoleURL(s.ref)
 }
 
+func (s *cloudStack) ResourceConsoleURL(urn resource.URN) (string, error) {
+	return s.b.ResourceConsoleURL(s.ref, urn)
+}
+
 // cloudStackSummary implements the backend.StackSummary interface, by wrapping
 // an apitype.StackSummary struct.
 type cloudStackSummary struct {
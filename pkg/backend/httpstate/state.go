@@ -17,6 +17,7 @@ package httpstate
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -284,6 +285,10 @@ func (b *cloudBackend) getTarget(ctx context.Context, stackRef backend.StackRefe
 	if err != nil {
 		return nil, err
 	}
+	credentials, err := stk.ResolveCredentials(filepath.Dir(stackConfigFile))
+	if err != nil {
+		return nil, err
+	}
 
 	decrypter, err := b.GetStackCrypter(stackRef)
 	if err != nil {
@@ -304,10 +309,11 @@ func (b *cloudBackend) getTarget(ctx context.Context, stackRef backend.StackRefe
 	}
 
 	return &deploy.Target{
-		Name:      stackRef.Name(),
-		Config:    stk.Config,
-		Decrypter: decrypter,
-		Snapshot:  snapshot,
+		Name:        stackRef.Name(),
+		Config:      stk.Config,
+		Decrypter:   decrypter,
+		Snapshot:    snapshot,
+		Credentials: credentials,
 	}, nil
 }
 
@@ -320,6 +326,13 @@ func convertStepEventMetadata(md engine.StepEventMetadata) apitype.StepEventMeta
 	for _, v := range md.Diffs {
 		diffs = append(diffs, string(v))
 	}
+	var reasons map[string]string
+	for k, v := range md.ReplaceReasons {
+		if reasons == nil {
+			reasons = make(map[string]string)
+		}
+		reasons[string(k)] = v
+	}
 
 	return apitype.StepEventMetadata{
 		Op:   string(md.Op),
@@ -330,10 +343,11 @@ func convertStepEventMetadata(md engine.StepEventMetadata) apitype.StepEventMeta
 		New: convertStepEventStateMetadata(md.New),
 		Res: convertStepEventStateMetadata(md.Res),
 
-		Keys:     keys,
-		Diffs:    diffs,
-		Logical:  md.Logical,
-		Provider: md.Provider,
+		Keys:           keys,
+		ReplaceReasons: reasons,
+		Diffs:          diffs,
+		Logical:        md.Logical,
+		Provider:       md.Provider,
 	}
 }
 
@@ -0,0 +1,82 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/operations"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/cmdutil"
+)
+
+// LogSession keeps the provider plugins that serve a stack's logs loaded across calls to GetLogs, so that following a
+// stack's logs does not launch every plugin anew on each poll. The plugins are loaded when they are first needed, and
+// shut down when the session is closed.
+type LogSession struct {
+	lock      sync.Mutex
+	ctx       *plugin.Context
+	providers *operations.PluginProviders
+}
+
+// NewLogSession returns a new, empty log session.
+func NewLogSession() *LogSession {
+	return &LogSession{}
+}
+
+// Providers returns the session's provider plugins, starting the session's plugin host if it has not been started.
+func (s *LogSession) Providers() (*operations.PluginProviders, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.providers == nil {
+		ctx, err := plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), nil, nil, nil, "", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.ctx, s.providers = ctx, operations.NewPluginProviders(ctx.Host)
+	}
+	return s.providers, nil
+}
+
+// Close shuts down any provider plugins that the session loaded.
+func (s *LogSession) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ctx == nil {
+		return nil
+	}
+	err := s.ctx.Close()
+	s.ctx, s.providers = nil, nil
+	return err
+}
+
+// logSessionKey is the type of the context key for LogSessions. It has a type of its own so that it cannot collide with
+// other keys, such as tracingOptionsKey, whose values are also empty structs.
+type logSessionKey struct{}
+
+// ContextWithLogSession returns a new context.Context that carries the indicated log session, which calls to GetLogs
+// made with the context will use.
+func ContextWithLogSession(ctx context.Context, session *LogSession) context.Context {
+	return context.WithValue(ctx, logSessionKey{}, session)
+}
+
+// LogSessionFromContext retrieves the log session carried by the given context, if any.
+func LogSessionFromContext(ctx context.Context) *LogSession {
+	session, _ := ctx.Value(logSessionKey{}).(*LogSession)
+	return session
+}
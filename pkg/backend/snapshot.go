@@ -147,7 +147,7 @@ func (sm *SnapshotManager) BeginMutation(step deploy.Step) (engine.SnapshotMutat
 		return sm.doDelete(step)
 	case deploy.OpReplace:
 		return &replaceSnapshotMutation{sm}, nil
-	case deploy.OpRead, deploy.OpReadReplacement:
+	case deploy.OpRead, deploy.OpReadReplacement, deploy.OpImport:
 		return sm.doRead(step)
 	case deploy.OpRefresh:
 		return &refreshSnapshotMutation{sm}, nil
@@ -284,6 +284,15 @@ func (csm *createSnapshotMutation) End(step deploy.Step, successful bool) error
 	})
 }
 
+var _ engine.PendingSnapshotMutation = (*createSnapshotMutation)(nil)
+
+func (csm *createSnapshotMutation) EndPending(step deploy.Step) error {
+	contract.Require(step != nil, "step != nil")
+	logging.V(9).Infof("SnapshotManager: createSnapshotMutation.EndPending(...)")
+	// The pending operation was recorded when the mutation began, so there is nothing to write.
+	return nil
+}
+
 func (sm *SnapshotManager) doUpdate(step deploy.Step) (engine.SnapshotMutation, error) {
 	logging.V(9).Infof("SnapshotManager.doUpdate(%s)", step.URN())
 	err := sm.mutate(func() bool {
@@ -511,7 +520,17 @@ func (sm *SnapshotManager) snap() *deploy.Snapshot {
 	}
 
 	manifest.Magic = manifest.NewMagic()
-	return deploy.NewSnapshot(manifest, resources, operations)
+	snap := deploy.NewSnapshot(manifest, resources, operations)
+
+	// Record the resources that this plan has finished with, so that the plan can be resumed if it fails.
+	completed := make(map[resource.URN]bool)
+	for _, res := range sm.resources {
+		if !completed[res.URN] {
+			completed[res.URN] = true
+			snap.CompletedResources = append(snap.CompletedResources, res.URN)
+		}
+	}
+	return snap
 }
 
 // saveSnapshot persists the current snapshot and optionally verifies it afterwards.
@@ -20,6 +20,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/pulumi/pulumi/pkg/engine"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/tokens"
@@ -328,8 +329,9 @@ func TestVexingDeployment(t *testing.T) {
 	cPrime := NewResource(string(c.URN), bPrime.URN)
 
 	// mocking out the behavior of a provider indicating that this resource needs to be deleted
-	createReplacement := deploy.NewCreateReplacementStep(nil, MockRegisterResourceEvent{}, c, cPrime, nil, nil, true)
-	replace := deploy.NewReplaceStep(nil, c, cPrime, nil, nil, true)
+	createReplacement := deploy.NewCreateReplacementStep(
+		nil, MockRegisterResourceEvent{}, c, cPrime, nil, nil, nil, true)
+	replace := deploy.NewReplaceStep(nil, c, cPrime, nil, nil, nil, true)
 	c.Delete = true
 
 	applyStep(createReplacement)
@@ -488,6 +490,31 @@ func TestRecordingCreateFailure(t *testing.T) {
 	assert.Len(t, snap.PendingOperations, 0)
 }
 
+func TestRecordingCreateTimeout(t *testing.T) {
+	resourceA := NewResource("a")
+	snap := NewSnapshot(nil)
+	manager, sp := MockSetup(t, snap)
+	step := deploy.NewCreateStep(nil, &MockRegisterResourceEvent{}, resourceA)
+	mutation, err := manager.BeginMutation(step)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	err = mutation.(engine.PendingSnapshotMutation).EndPending(step)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// A create that timed out may still have created the resource, so the "creating" operation
+	// should be left in the operations list for the user to resolve.
+	snap = sp.LastSnap()
+	assert.Len(t, snap.Resources, 0)
+	if assert.Len(t, snap.PendingOperations, 1) {
+		assert.Equal(t, resourceA.URN, snap.PendingOperations[0].Resource.URN)
+		assert.Equal(t, resource.OperationTypeCreating, snap.PendingOperations[0].Type)
+	}
+}
+
 func TestRecordingUpdateSuccess(t *testing.T) {
 	resourceA := NewResource("a")
 	resourceA.Inputs["key"] = resource.NewStringProperty("old")
@@ -84,10 +84,20 @@ const (
 	// CIPRHeadSHA is the SHA of the HEAD commit of a pull request running on CI. This is needed since the CI
 	// server will run at a different, merge commit. (headSHA merged into the target branch.)
 	CIPRHeadSHA = "ci.pr.headSHA"
+
+	// ExecUser is the name of the user who ran the pulumi operation.
+	ExecUser = "exec.user"
+	// ExecHostname is the name of the machine on which the pulumi operation ran.
+	ExecHostname = "exec.hostname"
+	// ExecPID is the ID of the process that ran the pulumi operation.
+	ExecPID = "exec.pid"
 )
 
 // UpdateInfo describes a previous update.
 type UpdateInfo struct {
+	// Version is the version of the stack produced by this update. Versions start at 1 and increase monotonically.
+	Version int `json:"version,omitempty"`
+
 	// Information known before an update is started.
 	Kind      apitype.UpdateKind `json:"kind"`
 	StartTime int64              `json:"startTime"`
@@ -106,4 +116,7 @@ type UpdateInfo struct {
 	Result          UpdateResult           `json:"result"`
 	EndTime         int64                  `json:"endTime"`
 	ResourceChanges engine.ResourceChanges `json:"resourceChanges,omitempty"`
+
+	// Timings describes where the time went during the update, if it changed any resources.
+	Timings *engine.TimingReport `json:"timings,omitempty"`
 }
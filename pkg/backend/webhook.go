@@ -0,0 +1,144 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/httputil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// Headers that accompany each webhook event. The signature header is only present if the webhook has a secret; it
+// holds "sha256=" followed by the hex-encoded HMAC-SHA256, keyed by the secret, of the timestamp header's value, a
+// period, and the request body. Receivers should recompute it, and may reject events whose timestamp is too old in
+// order to guard against replays.
+const (
+	WebhookEventHeader     = "Pulumi-Webhook-Event"
+	WebhookTimestampHeader = "Pulumi-Webhook-Timestamp"
+	WebhookSignatureHeader = "Pulumi-Webhook-Signature"
+)
+
+// WebhookEvent is the JSON body of an event posted to a project's webhooks.
+type WebhookEvent struct {
+	// Event is "started", "succeeded", or "failed".
+	Event string `json:"event"`
+	// Project and Stack identify the stack that is being updated.
+	Project tokens.PackageName `json:"project"`
+	Stack   string             `json:"stack"`
+	// Kind is the kind of update: "update", "refresh", or "destroy".
+	Kind apitype.UpdateKind `json:"kind"`
+	// StartTime and, once the update has finished, EndTime are Unix timestamps.
+	StartTime int64 `json:"startTime"`
+	EndTime   int64 `json:"endTime,omitempty"`
+	// Message is the message that describes the update, if any.
+	Message string `json:"message,omitempty"`
+	// ResourceChanges counts the resources changed by a finished update, by operation (e.g. "create").
+	ResourceChanges engine.ResourceChanges `json:"resourceChanges,omitempty"`
+	// Error is the error with which a failed update failed.
+	Error string `json:"error,omitempty"`
+}
+
+// webhookRetryOpts retries deliveries briefly, so that an unavailable receiver does not hold up the update for long.
+var webhookRetryOpts = httputil.RetryOpts{
+	MaxRetryCount: 3,
+	Delay:         time.Second,
+	Backoff:       2,
+	MaxDelay:      4 * time.Second,
+}
+
+// webhookTimeout bounds each attempt to deliver an event.
+const webhookTimeout = 10 * time.Second
+
+// SendWebhooks posts the given event to each of the given webhooks that subscribe to it. Webhooks are a side channel,
+// so failures to deliver events are reported to the given sink as warnings, rather than failing the update.
+func SendWebhooks(ctx context.Context, d diag.Sink, webhooks []workspace.ProjectWebhook, event WebhookEvent) {
+	body, err := json.Marshal(event)
+	contract.AssertNoError(err)
+
+	for _, webhook := range webhooks {
+		if !webhook.Wants(event.Event) {
+			continue
+		}
+		if err := sendWebhook(ctx, webhook, event.Event, body); err != nil {
+			d.Warningf(diag.Message("" /*urn*/, "could not post the %s event to the webhook %s: %v"),
+				event.Event, webhook.URL, err)
+		}
+	}
+}
+
+// sendWebhook posts the given event body to a single webhook, signing it if the webhook has a secret.
+func sendWebhook(ctx context.Context, webhook workspace.ProjectWebhook, event string, body []byte) error {
+	var secret string
+	if webhook.SecretEnv != "" {
+		// Never post events unsigned to a receiver that expects signatures.
+		if secret = os.Getenv(webhook.SecretEnv); secret == "" {
+			return errors.Errorf("its secret, %s, is not set", webhook.SecretEnv)
+		}
+	}
+
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pulumi-cli")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	if secret != "" {
+		req.Header.Set(WebhookSignatureHeader, signWebhookEvent(secret, timestamp, body))
+	}
+
+	resp, err := httputil.DoWithRetryOpts(req, &http.Client{Timeout: webhookTimeout}, webhookRetryOpts)
+	if err != nil {
+		return err
+	}
+	defer contract.IgnoreClose(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("the receiver responded with %s", resp.Status)
+	}
+	logging.V(7).Infof("posted the %s event to the webhook %s", event, webhook.URL)
+	return nil
+}
+
+// signWebhookEvent returns the value of the signature header for an event with the given timestamp and body.
+func signWebhookEvent(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, err := mac.Write([]byte(timestamp + "."))
+	contract.AssertNoError(err)
+	_, err = mac.Write(body)
+	contract.AssertNoError(err)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,133 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/engine"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestSendWebhooks(t *testing.T) {
+	defer func(old time.Duration) { webhookRetryOpts.Delay = old }(webhookRetryOpts.Delay)
+	webhookRetryOpts.Delay = time.Millisecond
+
+	var lock sync.Mutex
+	received := make(map[string][]*http.Request)
+	bodies := make(map[string][][]byte)
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		body, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+
+		// The first delivery to /flaky fails, and is retried.
+		if req.URL.Path == "/flaky" && failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received[req.URL.Path] = append(received[req.URL.Path], req)
+		bodies[req.URL.Path] = append(bodies[req.URL.Path], body)
+		if req.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("PULUMI_TEST_WEBHOOK_SECRET", "s3cr3t")
+	defer os.Unsetenv("PULUMI_TEST_WEBHOOK_SECRET")
+	webhooks := []workspace.ProjectWebhook{
+		{URL: server.URL + "/signed", SecretEnv: "PULUMI_TEST_WEBHOOK_SECRET"},
+		{
+			URL:    server.URL + "/finished",
+			Events: []string{workspace.WebhookUpdateSucceeded, workspace.WebhookUpdateFailed},
+		},
+		{URL: server.URL + "/flaky"},
+		{URL: server.URL + "/broken"},
+		{URL: server.URL + "/unsigned", SecretEnv: "PULUMI_TEST_WEBHOOK_MISSING_SECRET"},
+	}
+
+	var stderr bytes.Buffer
+	sink := diag.DefaultSink(ioutil.Discard, &stderr, diag.FormatOptions{Color: colors.Never})
+	event := WebhookEvent{
+		Event:     workspace.WebhookUpdateStarted,
+		Project:   "proj",
+		Stack:     "dev",
+		Kind:      apitype.UpdateUpdate,
+		StartTime: 1500000000,
+	}
+	SendWebhooks(context.Background(), sink, webhooks, event)
+	event.Event, event.EndTime = workspace.WebhookUpdateFailed, 1500000060
+	event.ResourceChanges, event.Error = engine.ResourceChanges{deploy.OpCreate: 2}, "boom"
+	SendWebhooks(context.Background(), sink, webhooks, event)
+
+	// Each webhook receives the events that it subscribes to, and failed deliveries are retried.
+	assert.Len(t, received["/signed"], 2)
+	assert.Len(t, received["/finished"], 1)
+	assert.Len(t, received["/flaky"], 2)
+	assert.Len(t, received["/broken"], 2)
+
+	// Events to webhooks with secrets are signed, and never sent unsigned.
+	req := received["/signed"][1]
+	assert.Equal(t, "failed", req.Header.Get(WebhookEventHeader))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t,
+		signWebhookEvent("s3cr3t", req.Header.Get(WebhookTimestampHeader), bodies["/signed"][1]),
+		req.Header.Get(WebhookSignatureHeader))
+	assert.Equal(t, "", received["/finished"][0].Header.Get(WebhookSignatureHeader))
+	assert.Empty(t, received["/unsigned"])
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(bodies["/finished"][0], &decoded))
+	assert.Equal(t, map[string]interface{}{
+		"event":           "failed",
+		"project":         "proj",
+		"stack":           "dev",
+		"kind":            "update",
+		"startTime":       float64(1500000000),
+		"endTime":         float64(1500000060),
+		"resourceChanges": map[string]interface{}{"create": float64(2)},
+		"error":           "boom",
+	}, decoded)
+
+	// Failures to deliver events are warnings.
+	assert.Contains(t, stderr.String(), "could not post the started event to the webhook "+server.URL+"/broken")
+	assert.Contains(t, stderr.String(), "PULUMI_TEST_WEBHOOK_MISSING_SECRET, is not set")
+}
+
+func TestSignWebhookEvent(t *testing.T) {
+	// The signature is the HMAC-SHA256 of "<timestamp>.<body>", as computed by e.g.
+	//     printf '1500000000.{}' | openssl dgst -sha256 -hmac key
+	assert.Equal(t, "sha256=a371dcd75e38f64492c80cda8741924eaeace5c7db6b0f8cda3a21bc70920df9",
+		signWebhookEvent("key", "1500000000", []byte("{}")))
+}
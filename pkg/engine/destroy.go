@@ -60,7 +60,7 @@ func newDestroySource(
 	}
 
 	// Like Update, if we're missing plugins, attempt to download the missing plugins.
-	if err := ensurePluginsAreInstalled(client, plugins); err != nil {
+	if err := ensurePluginsAreInstalled(client, plugctx.Diag, withoutMockedPlugins(opts, plugins)); err != nil {
 		logging.V(7).Infof("newDestroySource(): failed to install missing plugins: %v", err)
 	}
 
@@ -171,15 +171,35 @@ func GetResourcePropertiesSummary(step StepEventMetadata, indent int) string {
 			contract.Assert(err == nil)
 
 			// Elide references to default providers.
-			if prov.URN().Name() != "default" {
+			if !providers.IsDefaultProvider(prov.URN()) {
 				writeWithIndentNoPrefix(&b, indent+1, simplePropOp, "[provider=%s]\n", step.Provider)
 			}
 		}
 	}
 
+	// Explain why the resource must be replaced, if it is.
+	if reasons := GetReplaceReasons(step); reasons != "" {
+		writeWithIndentNoPrefix(&b, indent+1, deploy.OpReplace, "[replace: %s]\n", reasons)
+	}
+
 	return b.String()
 }
 
+// GetReplaceReasons describes the properties that force a step to replace its resource, along with any explanation
+// of why, e.g. "name (bucket names cannot be changed), region". It returns an empty string if the step does not
+// replace its resource.
+func GetReplaceReasons(step StepEventMetadata) string {
+	var reasons []string
+	for _, k := range step.Keys {
+		if reason := step.ReplaceReasons[k]; reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s (%s)", k, reason))
+		} else {
+			reasons = append(reasons, string(k))
+		}
+	}
+	return strings.Join(reasons, ", ")
+}
+
 func GetResourcePropertiesDetails(
 	step StepEventMetadata, indent int, planning bool, summary bool, debug bool) string {
 	var b bytes.Buffer
@@ -79,6 +79,7 @@ type SummaryEventPayload struct {
 	MaybeCorrupt    bool            // true if one or more resources may be corrupt
 	Duration        time.Duration   // the duration of the entire update operation (zero values for previews)
 	ResourceChanges ResourceChanges // count of changed resources, useful for reporting
+	Timings         *TimingReport   // where the time went during the update (nil for previews)
 }
 
 type ResourceOperationFailedPayload struct {
@@ -110,6 +111,9 @@ type StepEventMetadata struct {
 	Diffs    []resource.PropertyKey  // the keys causing diffs
 	Logical  bool                    // true if this step represents a logical operation in the program.
 	Provider string                  // the provider that performed this step.
+
+	// ReplaceReasons explains, for some or all of Keys, why a change to that key requires a replacement.
+	ReplaceReasons map[resource.PropertyKey]string
 }
 
 type StepEventStateMetadata struct {
@@ -185,6 +189,18 @@ func makeStepEventMetadata(op deploy.StepOp, step deploy.Step, debug bool) StepE
 	if differ, hasDiffs := step.(interface{ Diffs() []resource.PropertyKey }); hasDiffs {
 		diffs = differ.Diffs()
 	}
+	var reasons map[resource.PropertyKey]string
+	if reasoner, hasReasons := step.(interface {
+		Reasons() map[resource.PropertyKey]string
+	}); hasReasons {
+		reasons = reasoner.Reasons()
+	}
+
+	// Refreshes report their results without any changes to properties whose diffs are suppressed.
+	new := step.New()
+	if refresh, isRefresh := step.(*deploy.RefreshStep); isRefresh {
+		new = refresh.DisplayNew()
+	}
 
 	return StepEventMetadata{
 		Op:       op,
@@ -193,10 +209,12 @@ func makeStepEventMetadata(op deploy.StepOp, step deploy.Step, debug bool) StepE
 		Keys:     keys,
 		Diffs:    diffs,
 		Old:      makeStepEventStateMetadata(step.Old(), debug),
-		New:      makeStepEventStateMetadata(step.New(), debug),
+		New:      makeStepEventStateMetadata(new, debug),
 		Res:      makeStepEventStateMetadata(step.Res(), debug),
 		Logical:  step.Logical(),
 		Provider: step.Provider(),
+
+		ReplaceReasons: reasons,
 	}
 }
 
@@ -407,7 +425,7 @@ func (e *eventEmitter) previewSummaryEvent(resourceChanges ResourceChanges) {
 }
 
 func (e *eventEmitter) updateSummaryEvent(maybeCorrupt bool,
-	duration time.Duration, resourceChanges ResourceChanges) {
+	duration time.Duration, resourceChanges ResourceChanges, timings *TimingReport) {
 	contract.Requiref(e != nil, "e", "!= nil")
 
 	e.Chan <- Event{
@@ -417,6 +435,7 @@ func (e *eventEmitter) updateSummaryEvent(maybeCorrupt bool,
 			MaybeCorrupt:    maybeCorrupt,
 			Duration:        duration,
 			ResourceChanges: resourceChanges,
+			Timings:         timings,
 		},
 	}
 }
@@ -22,6 +22,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/mitchellh/copystructure"
@@ -125,7 +126,7 @@ func (j *Journal) Snap(base *deploy.Snapshot) *deploy.Snapshot {
 				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeCreating))
 			case deploy.OpDelete, deploy.OpDeleteReplaced, deploy.OpReadDiscard, deploy.OpDiscardReplaced:
 				ops = append(ops, resource.NewOperation(e.Step.Old(), resource.OperationTypeDeleting))
-			case deploy.OpRead, deploy.OpReadReplacement:
+			case deploy.OpRead, deploy.OpReadReplacement, deploy.OpImport:
 				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeReading))
 			case deploy.OpUpdate:
 				ops = append(ops, resource.NewOperation(e.Step.New(), resource.OperationTypeUpdating))
@@ -133,7 +134,8 @@ func (j *Journal) Snap(base *deploy.Snapshot) *deploy.Snapshot {
 		case JournalEntryFailure, JournalEntrySuccess:
 			switch e.Step.Op() {
 			// nolint: lll
-			case deploy.OpCreate, deploy.OpCreateReplacement, deploy.OpRead, deploy.OpReadReplacement, deploy.OpUpdate:
+			case deploy.OpCreate, deploy.OpCreateReplacement, deploy.OpRead, deploy.OpReadReplacement, deploy.OpUpdate,
+				deploy.OpImport:
 				doneOps[e.Step.New()] = true
 			case deploy.OpDelete, deploy.OpDeleteReplaced, deploy.OpReadDiscard, deploy.OpDiscardReplaced:
 				doneOps[e.Step.Old()] = true
@@ -157,7 +159,7 @@ func (j *Journal) Snap(base *deploy.Snapshot) *deploy.Snapshot {
 				}
 			case deploy.OpReplace:
 				// do nothing.
-			case deploy.OpRead, deploy.OpReadReplacement:
+			case deploy.OpRead, deploy.OpReadReplacement, deploy.OpImport:
 				resources = append(resources, e.Step.New())
 				if e.Step.Old() != nil {
 					dones[e.Step.Old()] = true
@@ -1000,13 +1002,26 @@ func TestSingleResourceDiffUnavailable(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestDestroyWithPendingDelete(t *testing.T) {
+func TestReplaceTargets(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
-			return &deploytest.Provider{}, nil
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+
+					return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+				},
+			}, nil
 		}),
 	}
-	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, _ *deploytest.ResourceMonitor) error {
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
 		return nil
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
@@ -1014,268 +1029,310 @@ func TestDestroyWithPendingDelete(t *testing.T) {
 	p := &TestPlan{
 		Options: UpdateOptions{host: host},
 	}
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
+	resB := p.NewURN("pkgA:m:typA", "resB", "")
 
-	resURN := p.NewURN("pkgA:m:typA", "resA", "")
-
-	// Create an old snapshot with two copies of a resource that share a URN: one that is pending deletion and one
-	// that is not.
-	old := &deploy.Snapshot{
-		Resources: []*resource.State{
-			{
-				Type:    resURN.Type(),
-				URN:     resURN,
-				Custom:  true,
-				ID:      "1",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-			},
-			{
-				Type:    resURN.Type(),
-				URN:     resURN,
-				Custom:  true,
-				ID:      "0",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-				Delete:  true,
-			},
-		},
-	}
+	// Run the initial update.
+	project := p.GetProject()
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
 
-	p.Steps = []TestStep{{
-		Op: Update,
-		Validate: func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
-			// Verify that we see a DeleteReplacement for the resource with ID 0 and a Delete for the resource with
-			// ID 1.
-			deletedID0, deletedID1 := false, false
+	// Now run an update that targets resA for replacement. Although neither resource has changed, resA should be
+	// replaced and resB left alone.
+	p.Options.ReplaceTargets = []resource.URN{resA}
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+			replaced := false
 			for _, entry := range j.Entries {
-				// Ignore non-terminal steps and steps that affect the injected default provider.
-				if entry.Kind != JournalEntrySuccess || entry.Step.URN() != resURN ||
-					(entry.Step.Op() != deploy.OpDelete && entry.Step.Op() != deploy.OpDeleteReplaced) {
-					continue
-				}
-
-				switch id := entry.Step.Old().ID; id {
-				case "0":
-					assert.False(t, deletedID0)
-					deletedID0 = true
-				case "1":
-					assert.False(t, deletedID1)
-					deletedID1 = true
-				default:
-					assert.Fail(t, "unexpected resource ID %v", string(id))
+				switch entry.Step.URN() {
+				case resA:
+					if entry.Step.Op() == deploy.OpCreateReplacement {
+						replaced = true
+					}
+				case resB:
+					assert.Equal(t, deploy.OpSame, entry.Step.Op())
 				}
 			}
-			assert.True(t, deletedID0)
-			assert.True(t, deletedID1)
-
+			assert.True(t, replaced)
 			return err
-		},
-	}}
-	p.Run(t, old)
+		})
+	assert.NoError(t, err)
+
+	// Targeting a resource that is not in the stack is an error.
+	p.Options.ReplaceTargets = []resource.URN{p.NewURN("pkgA:m:typA", "resC", "")}
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
 }
 
-func TestUpdateWithPendingDelete(t *testing.T) {
+func TestDestroyTargets(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{}, nil
 		}),
 	}
 
-	host := deploytest.NewPluginHost(nil, nil, nil, loaders...)
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		resA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, []resource.URN{resA}, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, resA, false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resD", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
 	p := &TestPlan{
 		Options: UpdateOptions{host: host},
 	}
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
+	resD := p.NewURN("pkgA:m:typA", "resD", "")
 
-	resURN := p.NewURN("pkgA:m:typA", "resA", "")
-
-	// Create an old snapshot with two copies of a resource that share a URN: one that is pending deletion and one
-	// that is not.
-	old := &deploy.Snapshot{
-		Resources: []*resource.State{
-			{
-				Type:    resURN.Type(),
-				URN:     resURN,
-				Custom:  true,
-				ID:      "1",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-			},
-			{
-				Type:    resURN.Type(),
-				URN:     resURN,
-				Custom:  true,
-				ID:      "0",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-				Delete:  true,
-			},
-		},
-	}
+	// Run the initial update.
+	project := p.GetProject()
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 5)
 
-	p.Steps = []TestStep{{
-		Op: Destroy,
-		Validate: func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
-			// Verify that we see a DeleteReplacement for the resource with ID 0 and a Delete for the resource with
-			// ID 1.
-			deletedID0, deletedID1 := false, false
+	// Destroying resA should also destroy resB, which depends on it, and resC, which is its child. resD and the
+	// default provider should be left intact.
+	p.Options.DestroyTargets = []resource.URN{resA}
+	snap, err = TestOp(Destroy).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+			deleted := make(map[string]bool)
 			for _, entry := range j.Entries {
-				// Ignore non-terminal steps and steps that affect the injected default provider.
-				if entry.Kind != JournalEntrySuccess || entry.Step.URN() != resURN ||
-					(entry.Step.Op() != deploy.OpDelete && entry.Step.Op() != deploy.OpDeleteReplaced) {
-					continue
-				}
-
-				switch id := entry.Step.Old().ID; id {
-				case "0":
-					assert.False(t, deletedID0)
-					deletedID0 = true
-				case "1":
-					assert.False(t, deletedID1)
-					deletedID1 = true
-				default:
-					assert.Fail(t, "unexpected resource ID %v", string(id))
-				}
+				assert.Equal(t, deploy.OpDelete, entry.Step.Op())
+				deleted[string(entry.Step.URN().Name())] = true
 			}
-			assert.True(t, deletedID0)
-			assert.True(t, deletedID1)
-
+			assert.Equal(t, map[string]bool{"resA": true, "resB": true, "resC": true}, deleted)
 			return err
-		},
-	}}
-	p.Run(t, old)
+		})
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 2)
+	for _, res := range snap.Resources {
+		assert.True(t, res.URN == resD || providers.IsProviderType(res.Type))
+	}
+
+	// Targeting a resource that is not in the stack is an error.
+	p.Options.DestroyTargets = []resource.URN{resA}
+	_, err = TestOp(Destroy).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
 }
 
-func TestParallelRefresh(t *testing.T) {
+func TestExcludeTargets(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{}, nil
 		}),
 	}
 
-	// Create a program that registers four resources, each of which depends on the resource that immediately precedes
-	// it.
+	value, registerB, registerE := 1, true, false
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		resA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
-			resource.PropertyMap{}, nil, false)
-		assert.NoError(t, err)
-
-		resB, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, []resource.URN{resA}, "",
-			resource.PropertyMap{}, nil, false)
+		props := resource.PropertyMap{"value": resource.NewNumberProperty(float64(value))}
+		resA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", props, nil, false)
 		assert.NoError(t, err)
-
-		resC, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resC", true, "", false, []resource.URN{resB}, "",
-			resource.PropertyMap{}, nil, false)
+		if registerB {
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, []resource.URN{resA}, "",
+				props, nil, false)
+			assert.NoError(t, err)
+		}
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, resA, false, nil, "", props, nil, false)
 		assert.NoError(t, err)
-
-		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resD", true, "", false, []resource.URN{resC}, "",
-			resource.PropertyMap{}, nil, false)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resD", true, "", false, nil, "", props, nil, false)
 		assert.NoError(t, err)
-
+		if registerE {
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resE", true, "", false, []resource.URN{resA}, "",
+				props, nil, false)
+			if err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
 	p := &TestPlan{
-		Options: UpdateOptions{Parallel: 4, host: host},
+		Options: UpdateOptions{host: host},
 	}
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
 
-	p.Steps = []TestStep{{Op: Update}}
-	snap := p.Run(t, nil)
+	// Run the initial update.
+	project := p.GetProject()
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 5)
 
+	// Change every resource and remove resB, but exclude resA. Only resD, which does not depend on resA, should be
+	// updated: resB depends on resA and resC is its child, so neither should be changed or deleted.
+	value, registerB = 2, false
+	p.Options.ExcludeTargets = []resource.URN{resA}
+	snap, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+			for _, entry := range j.Entries {
+				expected := deploy.OpSame
+				if entry.Step.URN().Name() == "resD" {
+					expected = deploy.OpUpdate
+				}
+				assert.Equal(t, expected, entry.Step.Op(), "%v", entry.Step.URN())
+			}
+			return err
+		})
+	assert.NoError(t, err)
 	assert.Len(t, snap.Resources, 5)
-	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
-	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
-	assert.Equal(t, string(snap.Resources[2].URN.Name()), "resB")
-	assert.Equal(t, string(snap.Resources[3].URN.Name()), "resC")
-	assert.Equal(t, string(snap.Resources[4].URN.Name()), "resD")
+	for _, res := range snap.Resources {
+		if res.URN.Name() == "resD" {
+			assert.Equal(t, float64(2), res.Inputs["value"].NumberValue())
+		} else if !providers.IsProviderType(res.Type) {
+			assert.Equal(t, float64(1), res.Inputs["value"].NumberValue())
+		}
+	}
 
-	p.Steps = []TestStep{{Op: Refresh}}
-	snap = p.Run(t, snap)
+	// A new resource that depends on an excluded resource cannot be created.
+	registerE = true
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
 
-	assert.Len(t, snap.Resources, 5)
-	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
-	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
-	assert.Equal(t, string(snap.Resources[2].URN.Name()), "resB")
-	assert.Equal(t, string(snap.Resources[3].URN.Name()), "resC")
-	assert.Equal(t, string(snap.Resources[4].URN.Name()), "resD")
+	// Excluding a resource that is not in the stack is an error.
+	registerE = false
+	p.Options.ExcludeTargets = []resource.URN{p.NewURN("pkgA:m:typA", "resF", "")}
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
 }
 
-func TestExternalRefresh(t *testing.T) {
+func TestRetryTransientErrors(t *testing.T) {
+	attempts := make(map[string]int)
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
-			return &deploytest.Provider{}, nil
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					name := string(urn.Name())
+					attempts[name]++
+					switch {
+					case name == "resA" && attempts[name] < 4:
+						return "", nil, resource.StatusOK, rpcerror.New(codes.ResourceExhausted, "rate exceeded")
+					case name == "resB":
+						return "", nil, resource.StatusOK, rpcerror.New(codes.InvalidArgument, "bad input")
+					}
+					return resource.ID(name), news, resource.StatusOK, nil
+				},
+			}, nil
 		}),
 	}
 
-	// Our program reads a resource and exits.
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		_, _, err := monitor.ReadResource("pkgA:m:typA", "resA", "resA-some-id", "", resource.PropertyMap{}, "")
-		if !assert.NoError(t, err) {
-			t.FailNow()
-		}
-
-		return nil
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.Error(t, err)
+		return err
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
 	p := &TestPlan{
 		Options: UpdateOptions{host: host},
-		Steps:   []TestStep{{Op: Update}},
 	}
 
-	// The read should place "resA" in the snapshot with the "External" bit set.
-	snap := p.Run(t, nil)
-	assert.Len(t, snap.Resources, 2)
-	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
-	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
-	assert.True(t, snap.Resources[1].External)
-
-	p = &TestPlan{
-		Options: UpdateOptions{host: host},
-		Steps:   []TestStep{{Op: Refresh}},
+	// Creates for pkgA are allowed four attempts, which is just enough for resA's rate limiting to clear up. resB's
+	// failure is not transient, so it must not be retried.
+	project := p.GetProject()
+	project.Retries = &workspace.ProjectRetries{
+		Providers: map[string]workspace.ProjectRetryPolicy{
+			"pkgA":        {MaxAttempts: 2, InitialDelay: "1ms"},
+			"pkgA:create": {MaxAttempts: 4},
+		},
 	}
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int{"resA": 4, "resB": 1}, attempts)
 
-	snap = p.Run(t, snap)
-	// A refresh should leave "resA" as it is in the snapshot. The External bit should still be set.
-	assert.Len(t, snap.Resources, 2)
-	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
-	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
-	assert.True(t, snap.Resources[1].External)
+	created := false
+	for _, res := range snap.Resources {
+		if res.URN.Name() == "resA" {
+			created = true
+			assert.Equal(t, resource.ID("resA"), res.ID)
+		}
+	}
+	assert.True(t, created)
 }
 
-func TestRefreshInitFailure(t *testing.T) {
-	p := &TestPlan{}
+func TestProviderLimits(t *testing.T) {
+	const resourceCount = 4
 
-	provURN := p.NewProviderURN("pkgA", "default", "")
-	resURN := p.NewURN("pkgA:m:typA", "resA", "")
-	res2URN := p.NewURN("pkgA:m:typA", "resB", "")
+	var lock sync.Mutex
+	attempts := 0
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
 
-	res2Outputs := resource.PropertyMap{"foo": resource.NewStringProperty("bar")}
+					lock.Lock()
+					defer lock.Unlock()
+					attempts++
+					return "", nil, resource.StatusOK, rpcerror.New(codes.ResourceExhausted, "rate exceeded")
+				},
+			}, nil
+		}),
+	}
 
-	//
-	// Refresh will persist any initialization errors that are returned by `Read`. This provider
-	// will error out or not based on the value of `refreshShouldFail`.
-	//
-	refreshShouldFail := false
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		var resources sync.WaitGroup
+		resources.Add(resourceCount)
+		for i := 0; i < resourceCount; i++ {
+			go func(idx int) {
+				defer resources.Done()
+				_, _, _, err := monitor.RegisterResource("pkgA:m:typA", fmt.Sprintf("res%d", idx), true, "",
+					false, nil, "", resource.PropertyMap{}, nil, false)
+				assert.Error(t, err)
+			}(i)
+		}
+		resources.Wait()
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	//
-	// Set up test environment to use `readFailProvider` as the underlying resource provider.
-	//
+	p := &TestPlan{
+		Options: UpdateOptions{host: host, ContinueOnError: true},
+	}
+
+	// Each create may be attempted up to five times, but only three retries are allowed over the whole update, so there
+	// are only seven attempts in all. Calls to the provider are limited to 100 per second, one at a time.
+	project := p.GetProject()
+	project.Retries = &workspace.ProjectRetries{
+		Default: &workspace.ProjectRetryPolicy{MaxAttempts: 5, InitialDelay: "1ms"},
+	}
+	project.Providers = map[string]workspace.ProjectProviderSettings{
+		"pkgA": {QPS: 100, Retry: &workspace.ProjectRetryPolicy{Budget: 3}},
+	}
+	start := time.Now()
+	_, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+	assert.Equal(t, resourceCount+3, attempts)
+
+	// The provider was configured, and then checked and created each resource, and each of those calls waited for the
+	// rate limit.
+	calls := 2 + resourceCount + attempts
+	assert.True(t, time.Since(start) >= time.Duration(calls-1)*10*time.Millisecond)
+}
+
+func TestProviderCredentials(t *testing.T) {
+	var configured resource.PropertyMap
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				ReadF: func(
-					urn resource.URN, id resource.ID, inputs, state resource.PropertyMap,
-				) (plugin.ReadResult, resource.Status, error) {
-					if refreshShouldFail && urn == resURN {
-						err := &plugin.InitError{
-							Reasons: []string{"Refresh reports continued to fail to initialize"},
-						}
-						return plugin.ReadResult{Outputs: resource.PropertyMap{}}, resource.StatusPartialFailure, err
-					} else if urn == res2URN {
-						return plugin.ReadResult{Outputs: res2Outputs}, resource.StatusOK, nil
-					}
-					return plugin.ReadResult{Outputs: resource.PropertyMap{}}, resource.StatusOK, nil
+				ConfigureF: func(news resource.PropertyMap) error {
+					configured = news
+					return nil
 				},
 			}, nil
 		}),
@@ -1289,1535 +1346,3091 @@ func TestRefreshInitFailure(t *testing.T) {
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p.Options.host = host
-
-	//
-	// Create an old snapshot with a single initialization failure.
-	//
-	old := &deploy.Snapshot{
-		Resources: []*resource.State{
-			{
-				Type:       resURN.Type(),
-				URN:        resURN,
-				Custom:     true,
-				ID:         "0",
-				Inputs:     resource.PropertyMap{},
-				Outputs:    resource.PropertyMap{},
-				InitErrors: []string{"Resource failed to initialize"},
-			},
-			{
-				Type:    res2URN.Type(),
-				URN:     res2URN,
-				Custom:  true,
-				ID:      "1",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-			},
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Config: config.Map{
+			config.MustMakeKey("pkgA", "region"): config.NewValue("us-west-2"),
 		},
 	}
 
-	//
-	// Refresh DOES NOT fail, causing the initialization error to disappear.
-	//
-	p.Steps = []TestStep{{Op: Refresh}}
-	snap := p.Run(t, old)
-
-	for _, resource := range snap.Resources {
-		switch urn := resource.URN; urn {
-		case provURN:
-			// break
-		case resURN:
-			assert.Empty(t, resource.InitErrors)
-		case res2URN:
-			assert.Equal(t, res2Outputs, resource.Outputs)
-		default:
-			t.Fatalf("unexpected resource %v", urn)
-		}
+	// The stack's credentials are passed to the provider, but do not override its configuration.
+	target := p.GetTarget(nil)
+	target.Credentials = map[tokens.Package]map[string]string{
+		"pkgA": {"profile": "prod", "region": "us-east-1"},
 	}
+	snap, err := TestOp(Update).Run(p.GetProject(), target, p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", configured["profile"].StringValue())
+	assert.Equal(t, "us-west-2", configured["region"].StringValue())
 
-	//
-	// Refresh DOES fail, causing the new initialization error to appear.
-	//
-	refreshShouldFail = true
-	p.Steps = []TestStep{{Op: Refresh, SkipPreview: true, ExpectFailure: true}}
-	snap = p.Run(t, old)
-	for _, resource := range snap.Resources {
-		switch urn := resource.URN; urn {
-		case provURN:
-			// break
-		case resURN:
-			assert.Equal(t, []string{"Refresh reports continued to fail to initialize"}, resource.InitErrors)
-		case res2URN:
-			assert.Equal(t, res2Outputs, resource.Outputs)
-		default:
-			t.Fatalf("unexpected resource %v", urn)
+	// The credentials are not recorded in the checkpoint.
+	for _, res := range snap.Resources {
+		if providers.IsProviderType(res.Type) {
+			assert.True(t, res.Inputs.HasValue("region"))
+			assert.False(t, res.Inputs.HasValue("profile"))
 		}
 	}
 }
 
-// Test that ensures that we log diagnostics for resources that receive an error from Check. (Note that this
-// is distinct from receiving non-error failures from Check.)
-func TestCheckFailureRecord(t *testing.T) {
+func TestReplaceReasons(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				CheckF: func(urn resource.URN,
-					olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
-					return nil, nil, errors.New("oh no, check had an error")
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+
+					if !olds["foo"].DeepEquals(news["foo"]) {
+						return plugin.DiffResult{
+							Changes:        plugin.DiffSome,
+							ReplaceKeys:    []resource.PropertyKey{"foo"},
+							ReplaceReasons: map[resource.PropertyKey]string{"foo": "foo is immutable"},
+						}, nil
+					}
+					return plugin.DiffResult{Changes: plugin.DiffNone}, nil
 				},
 			}, nil
 		}),
 	}
 
+	foo := "bar"
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil, nil, false)
-		assert.Error(t, err)
-		return err
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{"foo": resource.NewStringProperty(foo)}, nil, false)
+		assert.NoError(t, err)
+		return nil
 	})
-
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
 	p := &TestPlan{
 		Options: UpdateOptions{host: host},
-		Steps: []TestStep{{
-			Op:            Update,
-			ExpectFailure: true,
-			SkipPreview:   true,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-				sawFailure := false
-				for _, evt := range evts {
-					if evt.Type == DiagEvent {
-						e := evt.Payload.(DiagEventPayload)
-						msg := colors.Never.Colorize(e.Message)
-						sawFailure = msg == "oh no, check had an error\n" && e.Severity == diag.Error
-					}
-				}
-
-				assert.True(t, sawFailure)
-				return err
-			},
-		}},
 	}
+	project := p.GetProject()
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
 
-	p.Run(t, nil)
+	// Both the replacement and the logical replace steps carry the provider's explanation.
+	foo = "baz"
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, _ *Journal, events []Event, err error) error {
+			ops := make(map[deploy.StepOp]bool)
+			for _, e := range events {
+				if e.Type != ResourcePreEvent {
+					continue
+				}
+				md := e.Payload.(ResourcePreEventPayload).Metadata
+				if md.URN.Name() != "resA" || (md.Op != deploy.OpReplace && md.Op != deploy.OpCreateReplacement) {
+					continue
+				}
+				ops[md.Op] = true
+				assert.Equal(t, []resource.PropertyKey{"foo"}, md.Keys)
+				assert.Equal(t, "foo (foo is immutable)", GetReplaceReasons(md))
+			}
+			assert.Equal(t, map[deploy.StepOp]bool{deploy.OpReplace: true, deploy.OpCreateReplacement: true}, ops)
+			return err
+		})
+	assert.NoError(t, err)
 }
 
-// Test that checks that we emit diagnostics for properties that check says are invalid.
-func TestCheckFailureInvalidPropertyRecord(t *testing.T) {
+func TestResumeSkipsCompletedResources(t *testing.T) {
+	checks := make(map[string]int)
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
 				CheckF: func(urn resource.URN,
 					olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
-					return nil, []plugin.CheckFailure{{
-						Property: "someprop",
-						Reason:   "field is not valid",
-					}}, nil
+
+					checks[string(urn.Name())]++
+					return news, nil, nil
 				},
 			}, nil
 		}),
 	}
 
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil, nil, false)
-		assert.Error(t, err)
-		return err
+		for _, name := range []string{"resA", "resB"} {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true, "", false, nil, "",
+				resource.PropertyMap{"foo": resource.NewStringProperty("bar")}, nil, false)
+			assert.NoError(t, err)
+		}
+		return nil
 	})
-
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
 	p := &TestPlan{
 		Options: UpdateOptions{host: host},
-		Steps: []TestStep{{
-			Op:            Update,
-			ExpectFailure: true,
-			SkipPreview:   true,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-				sawFailure := false
-				for _, evt := range evts {
-					if evt.Type == DiagEvent {
-						e := evt.Payload.(DiagEventPayload)
-						msg := colors.Never.Colorize(e.Message)
-						sawFailure = strings.Contains(msg, "field is not valid") && e.Severity == diag.Error
-						if sawFailure {
-							break
-						}
-					}
-				}
-
-				assert.True(t, sawFailure)
-				return err
-			},
-		}},
 	}
+	project := p.GetProject()
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
 
-	p.Run(t, nil)
+	// Pretend that the last update failed after finishing resA. Resuming it must not check resA again, while resB,
+	// which was not completed, is checked as usual.
+	for _, res := range snap.Resources {
+		if res.URN.Name() == "resA" {
+			snap.CompletedResources = append(snap.CompletedResources, res.URN)
+		}
+	}
+	p.Options.Resume = true
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"resA": 1, "resB": 2}, checks)
 
+	// Without --resume every resource is checked.
+	p.Options.Resume = false
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"resA": 2, "resB": 3}, checks)
 }
 
-// Test that tests that Refresh can detect that resources have been deleted and removes them
-// from the snapshot.
-func TestRefreshWithDelete(t *testing.T) {
-	for _, parallelFactor := range []int{1, 4} {
-		t.Run(fmt.Sprintf("parallel-%d", parallelFactor), func(t *testing.T) {
-			loaders := []*deploytest.ProviderLoader{
-				deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
-					return &deploytest.Provider{
-						ReadF: func(
-							urn resource.URN, id resource.ID, inputs, state resource.PropertyMap,
-						) (plugin.ReadResult, resource.Status, error) {
-							// This thing doesn't exist. Returning nil from Read should trigger
-							// the engine to delete it from the snapshot.
-							return plugin.ReadResult{}, resource.StatusOK, nil
-						},
-					}, nil
-				}),
-			}
-
-			program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-				_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil, nil, false)
-				assert.NoError(t, err)
-				return err
-			})
-
-			host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-			p := &TestPlan{Options: UpdateOptions{host: host, Parallel: parallelFactor}}
+func TestRollback(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
 
-			p.Steps = []TestStep{{Op: Update}}
-			snap := p.Run(t, nil)
+	version := 1
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		comp, _, _, err := monitor.RegisterResource("pkgA:m:typComponent", "comp", false, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resA", true, comp, false, nil, "",
+			resource.PropertyMap{"foo": resource.NewNumberProperty(float64(version))}, nil, false)
+		assert.NoError(t, err)
 
-			p.Steps = []TestStep{{Op: Refresh}}
-			snap = p.Run(t, snap)
+		name := "resB"
+		if version > 1 {
+			name = "resC"
+		}
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", name, true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-			// Refresh succeeds and records that the resource in the snapshot doesn't exist anymore
-			provURN := p.NewProviderURN("pkgA", "default", "")
-			assert.Len(t, snap.Resources, 1)
-			assert.Equal(t, provURN, snap.Resources[0].URN)
-		})
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
 	}
-}
-
-// Tests that dependencies are correctly rewritten when refresh removes deleted resources.
-func TestRefreshDeleteDependencies(t *testing.T) {
-	p := &TestPlan{}
+	project := p.GetProject()
 
-	const resType = "pkgA:m:typA"
+	// Run two updates: the second changes resA and replaces resB with resC.
+	v1, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	version = 2
+	v2, err := TestOp(Update).Run(project, p.GetTarget(CloneSnapshot(t, v1)), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
 
-	urnA := p.NewURN(resType, "resA", "")
-	urnB := p.NewURN(resType, "resB", "")
-	urnC := p.NewURN(resType, "resC", "")
+	// Rolling back to the first snapshot must restore resA's inputs, recreate resB, and delete resC, all without
+	// running the program.
+	p.Options.RollbackSnapshot = v1
+	version = 3
+	snap, err := TestOp(Update).Run(project, p.GetTarget(v2), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+			ops := make(map[string]deploy.StepOp)
+			for _, entry := range j.Entries {
+				if entry.Step.Op() != deploy.OpSame {
+					ops[string(entry.Step.URN().Name())] = entry.Step.Op()
+				}
+			}
+			assert.Equal(t, map[string]deploy.StepOp{
+				"resA": deploy.OpUpdate,
+				"resB": deploy.OpCreate,
+				"resC": deploy.OpDelete,
+			}, ops)
+			return err
+		})
+	assert.NoError(t, err)
 
-	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
-		return &resource.State{
-			Type:         urn.Type(),
-			URN:          urn,
-			Custom:       true,
-			Delete:       delete,
-			ID:           id,
-			Inputs:       resource.PropertyMap{},
-			Outputs:      resource.PropertyMap{},
-			Dependencies: dependencies,
+	names := make(map[string]bool)
+	for _, res := range snap.Resources {
+		names[string(res.URN.Name())] = true
+		if res.URN.Name() == "resA" {
+			assert.Equal(t, resource.NewNumberProperty(1), res.Inputs["foo"])
 		}
 	}
+	assert.Equal(t, map[string]bool{"default": true, "comp": true, "resA": true, "resB": true}, names)
+}
 
-	oldResources := []*resource.State{
-		newResource(urnA, "0", false),
-		newResource(urnB, "1", false, urnA),
-		newResource(urnC, "2", false, urnA, urnB),
-		newResource(urnA, "3", true),
-		newResource(urnA, "4", true),
-		newResource(urnC, "5", true, urnA, urnB),
-	}
-
-	old := &deploy.Snapshot{
-		Resources: oldResources,
-	}
-
+func TestStreamingRegistrations(t *testing.T) {
+	// Resources are created as the program registers them, rather than once the whole program has run.
+	var programDone bool
+	var createdWhileRunning []string
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				ReadF: func(urn resource.URN, id resource.ID,
-					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
 
-					switch id {
-					case "0", "4":
-						// We want to delete resources A::0 and A::4.
-						return plugin.ReadResult{}, resource.StatusOK, nil
-					default:
-						return plugin.ReadResult{Inputs: inputs, Outputs: state}, resource.StatusOK, nil
+					if !programDone {
+						createdWhileRunning = append(createdWhileRunning, string(urn.Name()))
 					}
+					return resource.ID(urn.Name()), news, resource.StatusOK, nil
 				},
 			}, nil
 		}),
 	}
 
-	p.Options.host = deploytest.NewPluginHost(nil, nil, nil, loaders...)
-
-	p.Steps = []TestStep{{Op: Refresh}}
-	snap := p.Run(t, old)
-
-	provURN := p.NewProviderURN("pkgA", "default", "")
-
-	for _, r := range snap.Resources {
-		switch urn := r.URN; urn {
-		case provURN:
-			continue
-		case urnA, urnB, urnC:
-			// break
-		default:
-			t.Fatalf("unexpected resource %v", urn)
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		for _, name := range []string{"resA", "resB", "resC"} {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true, "", false, nil, "",
+				resource.PropertyMap{}, nil, false)
+			assert.NoError(t, err)
 		}
+		programDone = true
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-		switch r.ID {
-		case "1":
-			// A::0 was deleted, so B's dependency list should be empty.
-			assert.Equal(t, urnB, r.URN)
-			assert.Empty(t, r.Dependencies)
-		case "2":
-			// A::0 was deleted, so C's dependency list should only contain B.
-			assert.Equal(t, urnC, r.URN)
-			assert.Equal(t, []resource.URN{urnB}, r.Dependencies)
-		case "3":
-			// A::3 should not have changed.
-			assert.Equal(t, oldResources[3], r)
-		case "5":
-			// A::4 was deleted but A::3 was still refernceable by C, so C should not have changed.
-			assert.Equal(t, oldResources[5], r)
-		default:
-			t.Fatalf("unexepcted resource %v::%v", r.URN, r.ID)
-		}
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
 	}
+	_, err := TestOp(Update).Run(p.GetProject(), p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"resA", "resB", "resC"}, createdWhileRunning)
 }
 
-// Tests basic refresh functionality.
-func TestRefreshBasics(t *testing.T) {
-	p := &TestPlan{}
+func TestSavedPlan(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
 
-	const resType = "pkgA:m:typA"
+					if !olds.DeepEquals(news) {
+						return plugin.DiffResult{Changes: plugin.DiffSome}, nil
+					}
+					return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+				},
+			}, nil
+		}),
+	}
 
-	urnA := p.NewURN(resType, "resA", "")
-	urnB := p.NewURN(resType, "resB", "")
-	urnC := p.NewURN(resType, "resC", "")
+	// The value of resB's "bar" input is not known during previews, as if it were computed from another resource's
+	// outputs.
+	fooValue, registerC := "baz", false
+	program := deploytest.NewLanguageRuntime(func(info plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{"foo": resource.NewStringProperty(fooValue)}, nil, false)
+		assert.NoError(t, err)
 
-	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
-		return &resource.State{
-			Type:         urn.Type(),
-			URN:          urn,
-			Custom:       true,
-			Delete:       delete,
-			ID:           id,
-			Inputs:       resource.PropertyMap{},
-			Outputs:      resource.PropertyMap{},
-			Dependencies: dependencies,
+		bar := resource.NewStringProperty("qux")
+		if info.DryRun {
+			bar = resource.MakeComputed(resource.NewStringProperty(""))
 		}
-	}
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, nil, "",
+			resource.PropertyMap{"bar": bar}, nil, false)
+		assert.NoError(t, err)
 
-	oldResources := []*resource.State{
-		newResource(urnA, "0", false),
-		newResource(urnB, "1", false, urnA),
-		newResource(urnC, "2", false, urnA, urnB),
-		newResource(urnA, "3", true),
-		newResource(urnA, "4", true),
-		newResource(urnC, "5", true, urnA, urnB),
+		if registerC {
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, "", false, nil, "",
+				resource.PropertyMap{}, nil, false)
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
 	}
+	project := p.GetProject()
 
-	newStates := map[resource.ID]plugin.ReadResult{
-		// A::0 and A::3 will have no changes.
-		"0": {Outputs: resource.PropertyMap{}, Inputs: resource.PropertyMap{}},
-		"3": {Outputs: resource.PropertyMap{}, Inputs: resource.PropertyMap{}},
+	// Run the initial update.
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
 
-		// B::1 and A::4 will have changes. The latter will also have input changes.
-		"1": {Outputs: resource.PropertyMap{"foo": resource.NewStringProperty("bar")}, Inputs: resource.PropertyMap{}},
-		"4": {
-			Outputs: resource.PropertyMap{"baz": resource.NewStringProperty("qux")},
-			Inputs:  resource.PropertyMap{"oof": resource.NewStringProperty("zab")},
-		},
+	// Change resA's inputs and save the plan computed by a preview.
+	fooValue = "quux"
+	p.Options.SavePlan = deploy.NewSavedPlan()
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, true, p.BackendClient, nil)
+	assert.NoError(t, err)
+	plan := p.Options.SavePlan
+	p.Options.SavePlan = nil
 
-		// C::2 and C::5 will be deleted.
-		"2": {},
-		"5": {},
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
+	if assert.Contains(t, plan.Resources, resA) {
+		assert.Equal(t, []deploy.StepOp{deploy.OpUpdate}, plan.Resources[resA].Ops)
 	}
 
-	old := &deploy.Snapshot{
-		Resources: oldResources,
-	}
+	// An update that registers an additional resource violates the plan.
+	p.Options.FollowPlan = plan
+	registerC = true
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+
+	// As does an update that changes resA's inputs again.
+	registerC, fooValue = false, "corge"
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
 
+	// An update that matches the plan succeeds, even though resB's inputs were unknown when the plan was computed.
+	fooValue = "quux"
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+}
+
+func TestContinueOnError(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				ReadF: func(urn resource.URN, id resource.ID,
-					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
 
-					new, hasNewState := newStates[id]
-					assert.True(t, hasNewState)
-					return new, resource.StatusOK, nil
+					if urn.Name() == "resA" {
+						return "", nil, resource.StatusOK, errors.New("resA is flaky")
+					}
+					return resource.ID(urn.Name()), news, resource.StatusOK, nil
 				},
 			}, nil
 		}),
 	}
 
-	p.Options.host = deploytest.NewPluginHost(nil, nil, nil, loaders...)
-
-	p.Steps = []TestStep{{
-		Op: Refresh,
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
-			// Should see only refreshes.
-			for _, entry := range j.Entries {
-				assert.Equal(t, deploy.OpRefresh, entry.Step.Op())
-				resultOp := entry.Step.(*deploy.RefreshStep).ResultOp()
+	// resB depends on resA, so it is not registered if resA fails. resC is independent of both.
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		urnA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		if err == nil {
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false,
+				[]resource.URN{urnA}, "", resource.PropertyMap{}, nil, false)
+			assert.NoError(t, err)
+		}
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		return err
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-				old := entry.Step.Old()
-				if !old.Custom || providers.IsProviderType(old.Type) {
-					// Component and provider resources should never change.
-					assert.Equal(t, deploy.OpSame, resultOp)
-					continue
-				}
+	p := &TestPlan{
+		Options: UpdateOptions{host: host, ContinueOnError: true},
+	}
+	project := p.GetProject()
 
-				expected, new := newStates[old.ID], entry.Step.New()
-				if expected.Outputs == nil {
-					// If the resource was deleted, we want the result op to be an OpDelete.
-					assert.Nil(t, new)
-					assert.Equal(t, deploy.OpDelete, resultOp)
-				} else {
-					// If there were changes to the outputs, we want the result op to be an OpUpdate. Otherwise we want
-					// an OpSame.
-					if reflect.DeepEqual(old.Outputs, expected.Outputs) {
-						assert.Equal(t, deploy.OpSame, resultOp)
-					} else {
-						assert.Equal(t, deploy.OpUpdate, resultOp)
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, _ *Journal, events []Event, err error) error {
+			// The failure of resA should be summarized.
+			summarized := false
+			for _, e := range events {
+				if e.Type == DiagEvent {
+					payload := e.Payload.(DiagEventPayload)
+					if payload.Severity == diag.Error && strings.Contains(payload.Message, "failed to deploy") {
+						assert.Contains(t, payload.Message, string(p.NewURN("pkgA:m:typA", "resA", "")))
+						summarized = true
 					}
-
-					// Only the inputs and outputs should have changed (if anything changed).
-					old.Inputs = expected.Inputs
-					old.Outputs = expected.Outputs
-					assert.Equal(t, old, new)
 				}
 			}
+			assert.True(t, summarized)
 			return err
-		},
-	}}
-	snap := p.Run(t, old)
-
-	provURN := p.NewProviderURN("pkgA", "default", "")
+		})
+	assert.Error(t, err)
 
-	for _, r := range snap.Resources {
-		switch urn := r.URN; urn {
-		case provURN:
-			continue
-		case urnA, urnB, urnC:
-			// break
-		default:
-			t.Fatalf("unexpected resource %v", urn)
+	// resC should have been created despite the failure of resA.
+	var names []string
+	for _, res := range snap.Resources {
+		if res.Type == "pkgA:m:typA" {
+			names = append(names, string(res.URN.Name()))
 		}
+	}
+	assert.Equal(t, []string{"resC"}, names)
+}
 
-		// The only resources left in the checkpoint should be those that were not deleted by the refresh.
-		expected := newStates[r.ID]
-		assert.NotNil(t, expected)
-
-		idx, err := strconv.ParseInt(string(r.ID), 0, 0)
-		assert.NoError(t, err)
-
-		// The new resources should be equal to the old resources + the new inputs and outputs.
-		old := oldResources[int(idx)]
-		old.Inputs = expected.Inputs
-		old.Outputs = expected.Outputs
-		assert.Equal(t, old, r)
+func TestDestroyWithPendingDelete(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
 	}
-}
-
-// Tests that an interrupted refresh leaves behind an expected state.
-func TestCanceledRefresh(t *testing.T) {
-	p := &TestPlan{}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, _ *deploytest.ResourceMonitor) error {
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	const resType = "pkgA:m:typA"
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+	}
 
-	urnA := p.NewURN(resType, "resA", "")
-	urnB := p.NewURN(resType, "resB", "")
-	urnC := p.NewURN(resType, "resC", "")
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
 
-	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
-		return &resource.State{
-			Type:         urn.Type(),
-			URN:          urn,
-			Custom:       true,
-			Delete:       delete,
-			ID:           id,
-			Inputs:       resource.PropertyMap{},
-			Outputs:      resource.PropertyMap{},
-			Dependencies: dependencies,
-		}
+	// Create an old snapshot with two copies of a resource that share a URN: one that is pending deletion and one
+	// that is not.
+	old := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{
+				Type:    resURN.Type(),
+				URN:     resURN,
+				Custom:  true,
+				ID:      "1",
+				Inputs:  resource.PropertyMap{},
+				Outputs: resource.PropertyMap{},
+			},
+			{
+				Type:    resURN.Type(),
+				URN:     resURN,
+				Custom:  true,
+				ID:      "0",
+				Inputs:  resource.PropertyMap{},
+				Outputs: resource.PropertyMap{},
+				Delete:  true,
+			},
+		},
 	}
 
-	oldResources := []*resource.State{
-		newResource(urnA, "0", false),
-		newResource(urnB, "1", false),
-		newResource(urnC, "2", false),
+	p.Steps = []TestStep{{
+		Op: Update,
+		Validate: func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+			// Verify that we see a DeleteReplacement for the resource with ID 0 and a Delete for the resource with
+			// ID 1.
+			deletedID0, deletedID1 := false, false
+			for _, entry := range j.Entries {
+				// Ignore non-terminal steps and steps that affect the injected default provider.
+				if entry.Kind != JournalEntrySuccess || entry.Step.URN() != resURN ||
+					(entry.Step.Op() != deploy.OpDelete && entry.Step.Op() != deploy.OpDeleteReplaced) {
+					continue
+				}
+
+				switch id := entry.Step.Old().ID; id {
+				case "0":
+					assert.False(t, deletedID0)
+					deletedID0 = true
+				case "1":
+					assert.False(t, deletedID1)
+					deletedID1 = true
+				default:
+					assert.Fail(t, "unexpected resource ID %v", string(id))
+				}
+			}
+			assert.True(t, deletedID0)
+			assert.True(t, deletedID1)
+
+			return err
+		},
+	}}
+	p.Run(t, old)
+}
+
+func TestUpdateWithPendingDelete(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
 	}
 
-	newStates := map[resource.ID]resource.PropertyMap{
-		// A::0 and B::1 will have changes; D::3 will be deleted.
-		"0": {"foo": resource.NewStringProperty("bar")},
-		"1": {"baz": resource.NewStringProperty("qux")},
-		"2": nil,
+	host := deploytest.NewPluginHost(nil, nil, nil, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
 	}
 
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+	// Create an old snapshot with two copies of a resource that share a URN: one that is pending deletion and one
+	// that is not.
 	old := &deploy.Snapshot{
-		Resources: oldResources,
+		Resources: []*resource.State{
+			{
+				Type:    resURN.Type(),
+				URN:     resURN,
+				Custom:  true,
+				ID:      "1",
+				Inputs:  resource.PropertyMap{},
+				Outputs: resource.PropertyMap{},
+			},
+			{
+				Type:    resURN.Type(),
+				URN:     resURN,
+				Custom:  true,
+				ID:      "0",
+				Inputs:  resource.PropertyMap{},
+				Outputs: resource.PropertyMap{},
+				Delete:  true,
+			},
+		},
 	}
 
-	// Set up a cancelable context for the refresh operation.
-	ctx, cancel := context.WithCancel(context.Background())
+	p.Steps = []TestStep{{
+		Op: Destroy,
+		Validate: func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+			// Verify that we see a DeleteReplacement for the resource with ID 0 and a Delete for the resource with
+			// ID 1.
+			deletedID0, deletedID1 := false, false
+			for _, entry := range j.Entries {
+				// Ignore non-terminal steps and steps that affect the injected default provider.
+				if entry.Kind != JournalEntrySuccess || entry.Step.URN() != resURN ||
+					(entry.Step.Op() != deploy.OpDelete && entry.Step.Op() != deploy.OpDeleteReplaced) {
+					continue
+				}
 
-	// Serialize all refreshes s.t. we can cancel after the first is issued.
-	refreshes, cancelled := make(chan resource.ID), make(chan bool)
-	go func() {
-		<-refreshes
-		cancel()
-	}()
+				switch id := entry.Step.Old().ID; id {
+				case "0":
+					assert.False(t, deletedID0)
+					deletedID0 = true
+				case "1":
+					assert.False(t, deletedID1)
+					deletedID1 = true
+				default:
+					assert.Fail(t, "unexpected resource ID %v", string(id))
+				}
+			}
+			assert.True(t, deletedID0)
+			assert.True(t, deletedID1)
 
+			return err
+		},
+	}}
+	p.Run(t, old)
+}
+
+// Tests that independent resource operations run concurrently, but never more of them at once than requested.
+func TestParallelUpdate(t *testing.T) {
+	const resourceCount, parallel = 8, 2
+
+	var lock sync.Mutex
+	inflight, maxInflight := 0, 0
+	reached := make(chan bool)
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				ReadF: func(urn resource.URN, id resource.ID,
-					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					lock.Lock()
+					inflight++
+					if inflight > maxInflight {
+						maxInflight = inflight
+						if maxInflight == parallel {
+							close(reached)
+						}
+					}
+					lock.Unlock()
 
-					refreshes <- id
-					<-cancelled
+					// Hold each create open until the maximum degree of parallelism has been reached once, so that we
+					// know that the creates actually overlapped.
+					select {
+					case <-reached:
+					case <-time.After(10 * time.Second):
+					}
 
-					new, hasNewState := newStates[id]
-					assert.True(t, hasNewState)
-					return plugin.ReadResult{Outputs: new}, resource.StatusOK, nil
-				},
-				CancelF: func() error {
-					close(cancelled)
-					return nil
+					lock.Lock()
+					inflight--
+					lock.Unlock()
+
+					return resource.ID(urn.Name()), resource.PropertyMap{}, resource.StatusOK, nil
 				},
 			}, nil
 		}),
 	}
 
-	refreshed := make(map[resource.ID]bool)
-	op := TestOp(Refresh)
-	options := UpdateOptions{
-		Parallel: 1,
-		host:     deploytest.NewPluginHost(nil, nil, nil, loaders...),
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		errors := make([]error, resourceCount)
+		var resources sync.WaitGroup
+		resources.Add(resourceCount)
+		for i := 0; i < resourceCount; i++ {
+			go func(idx int) {
+				_, _, _, errors[idx] = monitor.RegisterResource("pkgA:m:typA", fmt.Sprintf("res%d", idx), true, "",
+					false, nil, "", resource.PropertyMap{}, nil, false)
+				resources.Done()
+			}(i)
+		}
+		resources.Wait()
+		for _, err := range errors {
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Parallel: parallel, host: host},
+		Steps:   []TestStep{{Op: Update}},
 	}
-	project, target := p.GetProject(), p.GetTarget(old)
-	validate := func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
-		for _, entry := range j.Entries {
-			assert.Equal(t, deploy.OpRefresh, entry.Step.Op())
-			resultOp := entry.Step.(*deploy.RefreshStep).ResultOp()
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, resourceCount+1)
+	assert.Equal(t, parallel, maxInflight)
+}
 
-			old := entry.Step.Old()
-			if !old.Custom || providers.IsProviderType(old.Type) {
-				// Component and provider resources should never change.
-				assert.Equal(t, deploy.OpSame, resultOp)
-				continue
-			}
+func TestProviderConcurrencyLimit(t *testing.T) {
+	const resourceCount, parallel, limit = 8, 8, 2
 
-			refreshed[old.ID] = true
+	var lock sync.Mutex
+	inflight, maxInflight := 0, 0
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
 
-			expected, new := newStates[old.ID], entry.Step.New()
-			if expected == nil {
-				// If the resource was deleted, we want the result op to be an OpDelete.
-				assert.Nil(t, new)
-				assert.Equal(t, deploy.OpDelete, resultOp)
-			} else {
-				// If there were changes to the outputs, we want the result op to be an OpUpdate. Otherwise we want
-				// an OpSame.
-				if reflect.DeepEqual(old.Outputs, expected) {
-					assert.Equal(t, deploy.OpSame, resultOp)
-				} else {
-					assert.Equal(t, deploy.OpUpdate, resultOp)
-				}
+					lock.Lock()
+					inflight++
+					if inflight > maxInflight {
+						maxInflight = inflight
+					}
+					lock.Unlock()
 
-				// Only the outputs should have changed (if anything changed).
-				old.Outputs = expected
-				assert.Equal(t, old, new)
-			}
-		}
-		return err
-	}
+					// Hold each create open for a moment so that creates would overlap if they were allowed to.
+					time.Sleep(10 * time.Millisecond)
 
-	snap, err := op.RunWithContext(ctx, project, target, options, false, nil, validate)
-	assert.Error(t, err)
-	assert.Equal(t, 1, len(refreshed))
+					lock.Lock()
+					inflight--
+					lock.Unlock()
 
-	provURN := p.NewProviderURN("pkgA", "default", "")
+					return resource.ID(urn.Name()), resource.PropertyMap{}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
 
-	for _, r := range snap.Resources {
-		switch urn := r.URN; urn {
-		case provURN:
-			continue
-		case urnA, urnB, urnC:
-			// break
-		default:
-			t.Fatalf("unexpected resource %v", urn)
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		errors := make([]error, resourceCount)
+		var resources sync.WaitGroup
+		resources.Add(resourceCount)
+		for i := 0; i < resourceCount; i++ {
+			go func(idx int) {
+				_, _, _, errors[idx] = monitor.RegisterResource("pkgA:m:typA", fmt.Sprintf("res%d", idx), true, "",
+					false, nil, "", resource.PropertyMap{}, nil, false)
+				resources.Done()
+			}(i)
+		}
+		resources.Wait()
+		for _, err := range errors {
+			assert.NoError(t, err)
 		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-		idx, err := strconv.ParseInt(string(r.ID), 0, 0)
-		assert.NoError(t, err)
+	p := &TestPlan{
+		Options: UpdateOptions{Parallel: parallel, host: host},
+	}
 
-		if refreshed[r.ID] {
-			// The refreshed resource should have its new state.
-			expected := newStates[r.ID]
-			if expected == nil {
-				assert.Fail(t, "refreshed resource was not deleted")
-			} else {
-				old := oldResources[int(idx)]
-				old.Outputs = expected
-				assert.Equal(t, old, r)
-			}
-		} else {
-			// Any resources that were not refreshed should retain their original state.
-			old := oldResources[int(idx)]
-			assert.Equal(t, old, r)
-		}
+	// The limit may be set either in the project's concurrency section or in its provider settings.
+	for _, configure := range []func(*workspace.Project){
+		func(project *workspace.Project) {
+			project.Concurrency = map[string]int{"pkgA": limit}
+		},
+		func(project *workspace.Project) {
+			project.Providers = map[string]workspace.ProjectProviderSettings{"pkgA": {Concurrency: limit}}
+		},
+	} {
+		inflight, maxInflight = 0, 0
+		project := p.GetProject()
+		configure(&project)
+		snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+		assert.NoError(t, err)
+		assert.Len(t, snap.Resources, resourceCount+1)
+		assert.True(t, maxInflight <= limit, "saw %d concurrent creates", maxInflight)
+		assert.True(t, maxInflight > 0)
 	}
 }
 
-// Tests that errors returned directly from the language host get logged by the engine.
-func TestLanguageHostDiagnostics(t *testing.T) {
+func TestParallelRefresh(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{}, nil
 		}),
 	}
 
-	errorText := "oh no"
-	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, _ *deploytest.ResourceMonitor) error {
-		// Exiting immediately with an error simulates a language exiting immediately with a non-zero exit code.
-		return errors.New(errorText)
-	})
-
-	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-	p := &TestPlan{
-		Options: UpdateOptions{host: host},
-		Steps: []TestStep{{
-			Op:            Update,
-			ExpectFailure: true,
-			SkipPreview:   true,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-				assert.Error(t, err)
-				sawExitCode := false
-				for _, evt := range evts {
-					if evt.Type == DiagEvent {
-						e := evt.Payload.(DiagEventPayload)
-						msg := colors.Never.Colorize(e.Message)
-						sawExitCode = strings.Contains(msg, errorText) && e.Severity == diag.Error
-						if sawExitCode {
-							break
-						}
-					}
-				}
-
-				assert.True(t, sawExitCode)
-				return err
-			},
-		}},
-	}
-
-	p.Run(t, nil)
-}
+	// Create a program that registers four resources, each of which depends on the resource that immediately precedes
+	// it.
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		resA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
 
-type brokenDecrypter struct {
-	ErrorMessage string
-}
+		resB, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, []resource.URN{resA}, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
 
-func (b brokenDecrypter) DecryptValue(ciphertext string) (string, error) {
-	return "", fmt.Errorf(b.ErrorMessage)
-}
+		resC, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resC", true, "", false, []resource.URN{resB}, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
 
-// Tests that the engine presents a reasonable error message when a decrypter fails to decrypt a config value.
-func TestBrokenDecrypter(t *testing.T) {
-	loaders := []*deploytest.ProviderLoader{
-		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
-			return &deploytest.Provider{}, nil
-		}),
-	}
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resD", true, "", false, []resource.URN{resC}, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
 
-	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, _ *deploytest.ResourceMonitor) error {
 		return nil
 	})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-	key := config.MustMakeKey("foo", "bar")
-	msg := "decryption failed"
-	configMap := make(config.Map)
-	configMap[key] = config.NewSecureValue("hunter2")
+
 	p := &TestPlan{
-		Options:   UpdateOptions{host: host},
-		Decrypter: brokenDecrypter{ErrorMessage: msg},
-		Config:    configMap,
-		Steps: []TestStep{{
-			Op:            Update,
-			ExpectFailure: true,
-			SkipPreview:   true,
-			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-				assert.Error(t, err)
-				decryptErr := err.(DecryptError)
-				assert.Equal(t, key, decryptErr.Key)
-				assert.Contains(t, decryptErr.Err.Error(), msg)
-				return err
-			},
-		}},
+		Options: UpdateOptions{Parallel: 4, host: host},
 	}
 
-	p.Run(t, nil)
+	p.Steps = []TestStep{{Op: Update}}
+	snap := p.Run(t, nil)
+
+	assert.Len(t, snap.Resources, 5)
+	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
+	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
+	assert.Equal(t, string(snap.Resources[2].URN.Name()), "resB")
+	assert.Equal(t, string(snap.Resources[3].URN.Name()), "resC")
+	assert.Equal(t, string(snap.Resources[4].URN.Name()), "resD")
+
+	p.Steps = []TestStep{{Op: Refresh}}
+	snap = p.Run(t, snap)
+
+	assert.Len(t, snap.Resources, 5)
+	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
+	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
+	assert.Equal(t, string(snap.Resources[2].URN.Name()), "resB")
+	assert.Equal(t, string(snap.Resources[3].URN.Name()), "resC")
+	assert.Equal(t, string(snap.Resources[4].URN.Name()), "resD")
 }
 
-func TestBadResourceType(t *testing.T) {
+func TestExternalRefresh(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{}, nil
 		}),
 	}
 
-	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
-		_, _, _, err := mon.RegisterResource(
-			"very:bad", "resA", true, "", false, nil, "", resource.PropertyMap{}, nil, false)
-		assert.Error(t, err)
-		rpcerr, ok := rpcerror.FromError(err)
-		assert.True(t, ok)
-		assert.Equal(t, codes.InvalidArgument, rpcerr.Code())
-		assert.Contains(t, rpcerr.Message(), "Type 'very:bad' is not a valid type token")
-
-		_, _, err = mon.ReadResource("very:bad", "someResource", "someId", "", resource.PropertyMap{}, "")
-		assert.Error(t, err)
-		rpcerr, ok = rpcerror.FromError(err)
-		assert.True(t, ok)
-		assert.Equal(t, codes.InvalidArgument, rpcerr.Code())
-		assert.Contains(t, rpcerr.Message(), "Type 'very:bad' is not a valid type token")
-
-		// Component resources may have any format type.
-		_, _, _, noErr := mon.RegisterResource(
-			"a:component", "resB", false /* custom */, "", false, nil, "", resource.PropertyMap{}, nil, false)
-		assert.NoError(t, noErr)
-
-		_, _, _, noErr = mon.RegisterResource(
-			"singlename", "resC", false /* custom */, "", false, nil, "", resource.PropertyMap{}, nil, false)
-		assert.NoError(t, noErr)
+	// Our program reads a resource and exits.
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, err := monitor.ReadResource("pkgA:m:typA", "resA", "resA-some-id", "", resource.PropertyMap{}, "")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
 
-		return err
+		return nil
 	})
-
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 	p := &TestPlan{
 		Options: UpdateOptions{host: host},
-		Steps: []TestStep{{
-			Op:            Update,
-			ExpectFailure: true,
-			SkipPreview:   true,
-		}},
+		Steps:   []TestStep{{Op: Update}},
 	}
 
-	p.Run(t, nil)
+	// The read should place "resA" in the snapshot with the "External" bit set.
+	snap := p.Run(t, nil)
+	assert.Len(t, snap.Resources, 2)
+	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
+	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
+	assert.True(t, snap.Resources[1].External)
+
+	p = &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps:   []TestStep{{Op: Refresh}},
+	}
+
+	snap = p.Run(t, snap)
+	// A refresh should leave "resA" as it is in the snapshot. The External bit should still be set.
+	assert.Len(t, snap.Resources, 2)
+	assert.Equal(t, string(snap.Resources[0].URN.Name()), "default") // provider
+	assert.Equal(t, string(snap.Resources[1].URN.Name()), "resA")
+	assert.True(t, snap.Resources[1].External)
 }
 
-// Tests that provider cancellation occurs as expected.
-func TestProviderCancellation(t *testing.T) {
-	const resourceCount = 4
+func TestRefreshInitFailure(t *testing.T) {
+	p := &TestPlan{}
 
-	// Set up a cancelable context for the refresh operation.
-	ctx, cancel := context.WithCancel(context.Background())
+	provURN := p.NewProviderURN("pkgA", "default", "")
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+	res2URN := p.NewURN("pkgA:m:typA", "resB", "")
 
-	// Wait for our resource ops, then cancel.
-	var ops sync.WaitGroup
-	ops.Add(resourceCount)
-	go func() {
-		ops.Wait()
-		cancel()
-	}()
+	res2Outputs := resource.PropertyMap{"foo": resource.NewStringProperty("bar")}
 
-	// Set up an independent cancelable context for the provider's operations.
-	provCtx, provCancel := context.WithCancel(context.Background())
+	//
+	// Refresh will persist any initialization errors that are returned by `Read`. This provider
+	// will error out or not based on the value of `refreshShouldFail`.
+	//
+	refreshShouldFail := false
+
+	//
+	// Set up test environment to use `readFailProvider` as the underlying resource provider.
+	//
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				CreateF: func(urn resource.URN,
-					inputs resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error) {
-
-					// Inform the waiter that we've entered a provider op and wait for cancellation.
-					ops.Done()
-					<-provCtx.Done()
-
-					return resource.ID(urn.Name()), resource.PropertyMap{}, resource.StatusOK, nil
-				},
-				CancelF: func() error {
-					provCancel()
-					return nil
+				ReadF: func(
+					urn resource.URN, id resource.ID, inputs, state resource.PropertyMap,
+				) (plugin.ReadResult, resource.Status, error) {
+					if refreshShouldFail && urn == resURN {
+						err := &plugin.InitError{
+							Reasons: []string{"Refresh reports continued to fail to initialize"},
+						}
+						return plugin.ReadResult{Outputs: resource.PropertyMap{}}, resource.StatusPartialFailure, err
+					} else if urn == res2URN {
+						return plugin.ReadResult{Outputs: res2Outputs}, resource.StatusOK, nil
+					}
+					return plugin.ReadResult{Outputs: resource.PropertyMap{}}, resource.StatusOK, nil
 				},
 			}, nil
 		}),
 	}
 
-	done := make(chan bool)
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		errors := make([]error, resourceCount)
-		var resources sync.WaitGroup
-		resources.Add(resourceCount)
-		for i := 0; i < resourceCount; i++ {
-			go func(idx int) {
-				_, _, _, errors[idx] = monitor.RegisterResource("pkgA:m:typA", fmt.Sprintf("res%d", idx), true, "",
-					false, nil, "", resource.PropertyMap{}, nil, false)
-				resources.Done()
-			}(i)
-		}
-		resources.Wait()
-		for _, err := range errors {
-			assert.NoError(t, err)
-		}
-		close(done)
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
 		return nil
 	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p := &TestPlan{}
-	op := TestOp(Update)
-	options := UpdateOptions{
-		Parallel: resourceCount,
-		host:     deploytest.NewPluginHost(nil, nil, program, loaders...),
-	}
-	project, target := p.GetProject(), p.GetTarget(nil)
-
-	_, err := op.RunWithContext(ctx, project, target, options, false, nil, nil)
-	assert.Error(t, err)
-
-	// Wait for the program to finish.
-	<-done
-}
+	p.Options.host = host
 
-// Tests that a preview works for a stack with pending operations.
-func TestPreviewWithPendingOperations(t *testing.T) {
-	p := &TestPlan{}
+	//
+	// Create an old snapshot with a single initialization failure.
+	//
+	old := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{
+				Type:       resURN.Type(),
+				URN:        resURN,
+				Custom:     true,
+				ID:         "0",
+				Inputs:     resource.PropertyMap{},
+				Outputs:    resource.PropertyMap{},
+				InitErrors: []string{"Resource failed to initialize"},
+			},
+			{
+				Type:    res2URN.Type(),
+				URN:     res2URN,
+				Custom:  true,
+				ID:      "1",
+				Inputs:  resource.PropertyMap{},
+				Outputs: resource.PropertyMap{},
+			},
+		},
+	}
 
-	const resType = "pkgA:m:typA"
-	urnA := p.NewURN(resType, "resA", "")
+	//
+	// Refresh DOES NOT fail, causing the initialization error to disappear.
+	//
+	p.Steps = []TestStep{{Op: Refresh}}
+	snap := p.Run(t, old)
 
-	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
-		return &resource.State{
-			Type:         urn.Type(),
-			URN:          urn,
-			Custom:       true,
-			Delete:       delete,
-			ID:           id,
-			Inputs:       resource.PropertyMap{},
-			Outputs:      resource.PropertyMap{},
-			Dependencies: dependencies,
+	for _, resource := range snap.Resources {
+		switch urn := resource.URN; urn {
+		case provURN:
+			// break
+		case resURN:
+			assert.Empty(t, resource.InitErrors)
+		case res2URN:
+			assert.Equal(t, res2Outputs, resource.Outputs)
+		default:
+			t.Fatalf("unexpected resource %v", urn)
 		}
 	}
 
-	old := &deploy.Snapshot{
-		PendingOperations: []resource.Operation{{
-			Resource: newResource(urnA, "0", false),
-			Type:     resource.OperationTypeUpdating,
-		}},
-		Resources: []*resource.State{
-			newResource(urnA, "0", false),
-		},
+	//
+	// Refresh DOES fail, causing the new initialization error to appear.
+	//
+	refreshShouldFail = true
+	p.Steps = []TestStep{{Op: Refresh, SkipPreview: true, ExpectFailure: true}}
+	snap = p.Run(t, old)
+	for _, resource := range snap.Resources {
+		switch urn := resource.URN; urn {
+		case provURN:
+			// break
+		case resURN:
+			assert.Equal(t, []string{"Refresh reports continued to fail to initialize"}, resource.InitErrors)
+		case res2URN:
+			assert.Equal(t, res2Outputs, resource.Outputs)
+		default:
+			t.Fatalf("unexpected resource %v", urn)
+		}
 	}
+}
 
+// Test that ensures that we log diagnostics for resources that receive an error from Check. (Note that this
+// is distinct from receiving non-error failures from Check.)
+func TestCheckFailureRecord(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
-			return &deploytest.Provider{}, nil
+			return &deploytest.Provider{
+				CheckF: func(urn resource.URN,
+					olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					return nil, nil, errors.New("oh no, check had an error")
+				},
+			}, nil
 		}),
 	}
 
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
-			resource.PropertyMap{}, nil, false)
-		assert.NoError(t, err)
-		return nil
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil, nil, false)
+		assert.Error(t, err)
+		return err
 	})
 
-	op := TestOp(Update)
-	options := UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loaders...)}
-	project, target := p.GetProject(), p.GetTarget(old)
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps: []TestStep{{
+			Op:            Update,
+			ExpectFailure: true,
+			SkipPreview:   true,
+			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+				sawFailure := false
+				for _, evt := range evts {
+					if evt.Type == DiagEvent {
+						e := evt.Payload.(DiagEventPayload)
+						msg := colors.Never.Colorize(e.Message)
+						sawFailure = msg == "oh no, check had an error\n" && e.Severity == diag.Error
+					}
+				}
 
-	// A preview should succeed despite the pending operations.
-	_, err := op.Run(project, target, options, true, nil, nil)
-	assert.NoError(t, err)
+				assert.True(t, sawFailure)
+				return err
+			},
+		}},
+	}
 
-	// But an update should fail.
-	_, err = op.Run(project, target, options, false, nil, nil)
-	assert.EqualError(t, err, deploy.PlanPendingOperationsError{}.Error())
+	p.Run(t, nil)
 }
 
-// Tests that a failed partial update causes the engine to persist the resource's old inputs and new outputs.
-func TestUpdatePartialFailure(t *testing.T) {
+// Test that checks that we emit diagnostics for properties that check says are invalid.
+func TestCheckFailureInvalidPropertyRecord(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				DiffF: func(urn resource.URN, id resource.ID, olds, news resource.PropertyMap) (plugin.DiffResult, error) {
-					return plugin.DiffResult{
-						Changes: plugin.DiffSome,
-					}, nil
-				},
-
-				UpdateF: func(urn resource.URN, id resource.ID, olds,
-					news resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
-					outputs := resource.NewPropertyMapFromMap(map[string]interface{}{
-						"output_prop": 42,
-					})
-
-					return outputs, resource.StatusPartialFailure, errors.New("update failed to apply")
+				CheckF: func(urn resource.URN,
+					olds, news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					return nil, []plugin.CheckFailure{{
+						Property: "someprop",
+						Reason:   "field is not valid",
+					}}, nil
 				},
 			}, nil
 		}),
 	}
 
-	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
-		_, _, _, err := mon.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
-			resource.NewPropertyMapFromMap(map[string]interface{}{
-				"input_prop": "new inputs",
-			}), nil, false)
-
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil, nil, false)
+		assert.Error(t, err)
 		return err
 	})
 
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
-	p := &TestPlan{Options: UpdateOptions{host: host}}
-
-	resURN := p.NewURN("pkgA:m:typA", "resA", "")
-	p.Steps = []TestStep{{
-		Op:            Update,
-		ExpectFailure: true,
-		SkipPreview:   true,
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-			assert.Error(t, err)
-			for _, entry := range j.Entries {
-				switch urn := entry.Step.URN(); urn {
-				case resURN:
-					assert.Equal(t, deploy.OpUpdate, entry.Step.Op())
-					switch entry.Kind {
-					case JournalEntryBegin:
-						continue
-					case JournalEntrySuccess:
-						inputs := entry.Step.New().Inputs
-						outputs := entry.Step.New().Outputs
-						assert.Len(t, inputs, 1)
-						assert.Len(t, outputs, 1)
-						assert.Equal(t,
-							resource.NewStringProperty("old inputs"), inputs[resource.PropertyKey("input_prop")])
-						assert.Equal(t,
-							resource.NewNumberProperty(42), outputs[resource.PropertyKey("output_prop")])
-					default:
-						t.Fatalf("unexpected journal operation: %d", entry.Kind)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps: []TestStep{{
+			Op:            Update,
+			ExpectFailure: true,
+			SkipPreview:   true,
+			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+				sawFailure := false
+				for _, evt := range evts {
+					if evt.Type == DiagEvent {
+						e := evt.Payload.(DiagEventPayload)
+						msg := colors.Never.Colorize(e.Message)
+						sawFailure = strings.Contains(msg, "field is not valid") && e.Severity == diag.Error
+						if sawFailure {
+							break
+						}
 					}
 				}
-			}
 
-			return err
+				assert.True(t, sawFailure)
+				return err
+			},
+		}},
+	}
+
+	p.Run(t, nil)
+
+}
+
+// Test that tests that Refresh can detect that resources have been deleted and removes them
+// from the snapshot.
+func TestRefreshWithDelete(t *testing.T) {
+	for _, parallelFactor := range []int{1, 4} {
+		t.Run(fmt.Sprintf("parallel-%d", parallelFactor), func(t *testing.T) {
+			loaders := []*deploytest.ProviderLoader{
+				deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+					return &deploytest.Provider{
+						ReadF: func(
+							urn resource.URN, id resource.ID, inputs, state resource.PropertyMap,
+						) (plugin.ReadResult, resource.Status, error) {
+							// This thing doesn't exist. Returning nil from Read should trigger
+							// the engine to delete it from the snapshot.
+							return plugin.ReadResult{}, resource.StatusOK, nil
+						},
+					}, nil
+				}),
+			}
+
+			program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+				_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil, nil, false)
+				assert.NoError(t, err)
+				return err
+			})
+
+			host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+			p := &TestPlan{Options: UpdateOptions{host: host, Parallel: parallelFactor}}
+
+			p.Steps = []TestStep{{Op: Update}}
+			snap := p.Run(t, nil)
+
+			p.Steps = []TestStep{{Op: Refresh}}
+			snap = p.Run(t, snap)
+
+			// Refresh succeeds and records that the resource in the snapshot doesn't exist anymore
+			provURN := p.NewProviderURN("pkgA", "default", "")
+			assert.Len(t, snap.Resources, 1)
+			assert.Equal(t, provURN, snap.Resources[0].URN)
+		})
+	}
+}
+
+// Tests that dependencies are correctly rewritten when refresh removes deleted resources.
+func TestRefreshDeleteDependencies(t *testing.T) {
+	p := &TestPlan{}
+
+	const resType = "pkgA:m:typA"
+
+	urnA := p.NewURN(resType, "resA", "")
+	urnB := p.NewURN(resType, "resB", "")
+	urnC := p.NewURN(resType, "resC", "")
+
+	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
+		return &resource.State{
+			Type:         urn.Type(),
+			URN:          urn,
+			Custom:       true,
+			Delete:       delete,
+			ID:           id,
+			Inputs:       resource.PropertyMap{},
+			Outputs:      resource.PropertyMap{},
+			Dependencies: dependencies,
+		}
+	}
+
+	oldResources := []*resource.State{
+		newResource(urnA, "0", false),
+		newResource(urnB, "1", false, urnA),
+		newResource(urnC, "2", false, urnA, urnB),
+		newResource(urnA, "3", true),
+		newResource(urnA, "4", true),
+		newResource(urnC, "5", true, urnA, urnB),
+	}
+
+	old := &deploy.Snapshot{
+		Resources: oldResources,
+	}
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					switch id {
+					case "0", "4":
+						// We want to delete resources A::0 and A::4.
+						return plugin.ReadResult{}, resource.StatusOK, nil
+					default:
+						return plugin.ReadResult{Inputs: inputs, Outputs: state}, resource.StatusOK, nil
+					}
+				},
+			}, nil
+		}),
+	}
+
+	p.Options.host = deploytest.NewPluginHost(nil, nil, nil, loaders...)
+
+	p.Steps = []TestStep{{Op: Refresh}}
+	snap := p.Run(t, old)
+
+	provURN := p.NewProviderURN("pkgA", "default", "")
+
+	for _, r := range snap.Resources {
+		switch urn := r.URN; urn {
+		case provURN:
+			continue
+		case urnA, urnB, urnC:
+			// break
+		default:
+			t.Fatalf("unexpected resource %v", urn)
+		}
+
+		switch r.ID {
+		case "1":
+			// A::0 was deleted, so B's dependency list should be empty.
+			assert.Equal(t, urnB, r.URN)
+			assert.Empty(t, r.Dependencies)
+		case "2":
+			// A::0 was deleted, so C's dependency list should only contain B.
+			assert.Equal(t, urnC, r.URN)
+			assert.Equal(t, []resource.URN{urnB}, r.Dependencies)
+		case "3":
+			// A::3 should not have changed.
+			assert.Equal(t, oldResources[3], r)
+		case "5":
+			// A::4 was deleted but A::3 was still refernceable by C, so C should not have changed.
+			assert.Equal(t, oldResources[5], r)
+		default:
+			t.Fatalf("unexepcted resource %v::%v", r.URN, r.ID)
+		}
+	}
+}
+
+// Tests that a preview or update that refreshes first plans against the refreshed state rather than the checkpoint.
+func TestUpdateWithRefresh(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					return "created-id", news, resource.StatusOK, nil
+				},
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+
+					if olds["foo"].DeepEquals(news["foo"]) {
+						return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+					}
+					return plugin.DiffResult{Changes: plugin.DiffSome}, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					// The resource has drifted from its checkpointed state.
+					drifted := resource.PropertyMap{"foo": resource.NewStringProperty("baz")}
+					return plugin.ReadResult{Inputs: drifted, Outputs: drifted}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{"foo": resource.NewStringProperty("bar")}, nil, false)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+	}
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+	// Run the initial update.
+	project := p.GetProject()
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+
+	expectOp := func(expected deploy.StepOp) ValidateFunc {
+		return func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+			found := false
+			for _, entry := range j.Entries {
+				if entry.Step.URN() == resURN && entry.Step.Op() != deploy.OpRefresh {
+					assert.Equal(t, expected, entry.Step.Op())
+					found = true
+				}
+			}
+			assert.True(t, found)
+			return err
+		}
+	}
+
+	// Without a refresh, the checkpoint matches the program, so the resource is unchanged.
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, true, p.BackendClient, expectOp(deploy.OpSame))
+	assert.NoError(t, err)
+
+	// With a refresh, both previews and updates see the drift and update the resource.
+	p.Options.Refresh = true
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, true, p.BackendClient, expectOp(deploy.OpUpdate))
+	assert.NoError(t, err)
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient,
+		expectOp(deploy.OpUpdate))
+	assert.NoError(t, err)
+}
+
+// Tests that a preview that refreshes first reports resources whose state has drifted as changes, even if the program
+// still matches their inputs.
+func TestPreviewWithRefreshReportsDrift(t *testing.T) {
+	drifted := false
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					return "created-id", resource.PropertyMap{"size": resource.NewNumberProperty(1)},
+						resource.StatusOK, nil
+				},
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+
+					return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					outputs := state
+					if drifted {
+						outputs = resource.PropertyMap{"size": resource.NewNumberProperty(2)}
+					}
+					return plugin.ReadResult{Inputs: inputs, Outputs: outputs}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{"foo": resource.NewStringProperty("bar")}, nil, false)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+	}
+
+	project := p.GetProject()
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+
+	// TestOp.Run doesn't report the changes from previews, so run them directly.
+	preview := func() ResourceChanges {
+		events := make(chan Event)
+		go func() {
+			for range events {
+			}
+		}()
+		defer close(events)
+
+		cancelCtx, _ := cancel.NewContext(context.Background())
+		ctx := &Context{
+			Cancel:          cancelCtx,
+			Events:          events,
+			SnapshotManager: newJournal(),
+			BackendClient:   p.BackendClient,
+		}
+		changes, err := Update(&updateInfo{project: project, target: p.GetTarget(snap)}, ctx, p.Options, true)
+		assert.NoError(t, err)
+		return changes
+	}
+
+	p.Options.Refresh = true
+	assert.False(t, preview().HasChanges())
+
+	drifted = true
+	changes := preview()
+	assert.True(t, changes.HasChanges())
+	assert.Equal(t, 1, changes[deploy.OpRefresh])
+	assert.Equal(t, 0, changes[deploy.OpUpdate])
+}
+
+// Tests that changes to properties whose diffs are suppressed are not reported as drift by a refresh, although the
+// refreshed state still records them.
+func TestRefreshSuppressesDiffs(t *testing.T) {
+	generation, size := 1.0, 1.0
+	outputs := func() resource.PropertyMap {
+		return resource.PropertyMap{
+			"size": resource.NewNumberProperty(size),
+			"metadata": resource.NewObjectProperty(resource.PropertyMap{
+				"generation": resource.NewNumberProperty(generation),
+			}),
+			"stamp": resource.NewStringProperty(fmt.Sprintf("t%v", generation)),
+		}
+	}
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					return "created-id", outputs(), resource.StatusOK, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					return plugin.ReadResult{Inputs: inputs, Outputs: outputs()}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	// The project suppresses a nested property of all resources, and the stack a property of pkgA:m:typA resources.
+	p := &TestPlan{
+		Options: UpdateOptions{
+			host:          host,
+			SuppressDiffs: []workspace.ProjectDiffSuppression{{Type: "pkgA:m:typA", Property: "stamp"}},
+		},
+	}
+	project := p.GetProject()
+	project.SuppressDiffs = []workspace.ProjectDiffSuppression{{Property: "metadata.generation"}}
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+
+	refresh := func(expected deploy.StepOp) *deploy.Snapshot {
+		snap, err := TestOp(Refresh).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient,
+			func(_ workspace.Project, _ deploy.Target, j *Journal, events []Event, err error) error {
+				for _, entry := range j.Entries {
+					if entry.Step.URN().Name() == "resA" {
+						assert.Equal(t, expected, entry.Step.(*deploy.RefreshStep).ResultOp())
+					}
+				}
+				return err
+			})
+		assert.NoError(t, err)
+		return snap
+	}
+
+	generation = 2
+	snap = refresh(deploy.OpSame)
+	for _, res := range snap.Resources {
+		if res.URN.Name() == "resA" {
+			assert.Equal(t, outputs(), res.Outputs)
+		}
+	}
+
+	generation, size = 3, 2
+	refresh(deploy.OpUpdate)
+}
+
+// Tests basic refresh functionality.
+func TestRefreshBasics(t *testing.T) {
+	p := &TestPlan{}
+
+	const resType = "pkgA:m:typA"
+
+	urnA := p.NewURN(resType, "resA", "")
+	urnB := p.NewURN(resType, "resB", "")
+	urnC := p.NewURN(resType, "resC", "")
+
+	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
+		return &resource.State{
+			Type:         urn.Type(),
+			URN:          urn,
+			Custom:       true,
+			Delete:       delete,
+			ID:           id,
+			Inputs:       resource.PropertyMap{},
+			Outputs:      resource.PropertyMap{},
+			Dependencies: dependencies,
+		}
+	}
+
+	oldResources := []*resource.State{
+		newResource(urnA, "0", false),
+		newResource(urnB, "1", false, urnA),
+		newResource(urnC, "2", false, urnA, urnB),
+		newResource(urnA, "3", true),
+		newResource(urnA, "4", true),
+		newResource(urnC, "5", true, urnA, urnB),
+	}
+
+	newStates := map[resource.ID]plugin.ReadResult{
+		// A::0 and A::3 will have no changes.
+		"0": {Outputs: resource.PropertyMap{}, Inputs: resource.PropertyMap{}},
+		"3": {Outputs: resource.PropertyMap{}, Inputs: resource.PropertyMap{}},
+
+		// B::1 and A::4 will have changes. The latter will also have input changes.
+		"1": {Outputs: resource.PropertyMap{"foo": resource.NewStringProperty("bar")}, Inputs: resource.PropertyMap{}},
+		"4": {
+			Outputs: resource.PropertyMap{"baz": resource.NewStringProperty("qux")},
+			Inputs:  resource.PropertyMap{"oof": resource.NewStringProperty("zab")},
+		},
+
+		// C::2 and C::5 will be deleted.
+		"2": {},
+		"5": {},
+	}
+
+	old := &deploy.Snapshot{
+		Resources: oldResources,
+	}
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					new, hasNewState := newStates[id]
+					assert.True(t, hasNewState)
+					return new, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	p.Options.host = deploytest.NewPluginHost(nil, nil, nil, loaders...)
+
+	p.Steps = []TestStep{{
+		Op: Refresh,
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+			// Should see only refreshes.
+			for _, entry := range j.Entries {
+				assert.Equal(t, deploy.OpRefresh, entry.Step.Op())
+				resultOp := entry.Step.(*deploy.RefreshStep).ResultOp()
+
+				old := entry.Step.Old()
+				if !old.Custom || providers.IsProviderType(old.Type) {
+					// Component and provider resources should never change.
+					assert.Equal(t, deploy.OpSame, resultOp)
+					continue
+				}
+
+				expected, new := newStates[old.ID], entry.Step.New()
+				if expected.Outputs == nil {
+					// If the resource was deleted, we want the result op to be an OpDelete.
+					assert.Nil(t, new)
+					assert.Equal(t, deploy.OpDelete, resultOp)
+				} else {
+					// If there were changes to the outputs, we want the result op to be an OpUpdate. Otherwise we want
+					// an OpSame.
+					if reflect.DeepEqual(old.Outputs, expected.Outputs) {
+						assert.Equal(t, deploy.OpSame, resultOp)
+					} else {
+						assert.Equal(t, deploy.OpUpdate, resultOp)
+					}
+
+					// Only the inputs and outputs should have changed (if anything changed).
+					old.Inputs = expected.Inputs
+					old.Outputs = expected.Outputs
+					assert.Equal(t, old, new)
+				}
+			}
+			return err
+		},
+	}}
+	snap := p.Run(t, old)
+
+	provURN := p.NewProviderURN("pkgA", "default", "")
+
+	for _, r := range snap.Resources {
+		switch urn := r.URN; urn {
+		case provURN:
+			continue
+		case urnA, urnB, urnC:
+			// break
+		default:
+			t.Fatalf("unexpected resource %v", urn)
+		}
+
+		// The only resources left in the checkpoint should be those that were not deleted by the refresh.
+		expected := newStates[r.ID]
+		assert.NotNil(t, expected)
+
+		idx, err := strconv.ParseInt(string(r.ID), 0, 0)
+		assert.NoError(t, err)
+
+		// The new resources should be equal to the old resources + the new inputs and outputs.
+		old := oldResources[int(idx)]
+		old.Inputs = expected.Inputs
+		old.Outputs = expected.Outputs
+		assert.Equal(t, old, r)
+	}
+}
+
+// Tests that an interrupted refresh leaves behind an expected state.
+func TestCanceledRefresh(t *testing.T) {
+	p := &TestPlan{}
+
+	const resType = "pkgA:m:typA"
+
+	urnA := p.NewURN(resType, "resA", "")
+	urnB := p.NewURN(resType, "resB", "")
+	urnC := p.NewURN(resType, "resC", "")
+
+	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
+		return &resource.State{
+			Type:         urn.Type(),
+			URN:          urn,
+			Custom:       true,
+			Delete:       delete,
+			ID:           id,
+			Inputs:       resource.PropertyMap{},
+			Outputs:      resource.PropertyMap{},
+			Dependencies: dependencies,
+		}
+	}
+
+	oldResources := []*resource.State{
+		newResource(urnA, "0", false),
+		newResource(urnB, "1", false),
+		newResource(urnC, "2", false),
+	}
+
+	newStates := map[resource.ID]resource.PropertyMap{
+		// A::0 and B::1 will have changes; D::3 will be deleted.
+		"0": {"foo": resource.NewStringProperty("bar")},
+		"1": {"baz": resource.NewStringProperty("qux")},
+		"2": nil,
+	}
+
+	old := &deploy.Snapshot{
+		Resources: oldResources,
+	}
+
+	// Set up a cancelable context for the refresh operation.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Serialize all refreshes s.t. we can cancel after the first is issued.
+	refreshes, cancelled := make(chan resource.ID), make(chan bool)
+	go func() {
+		<-refreshes
+		cancel()
+	}()
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					refreshes <- id
+					<-cancelled
+
+					new, hasNewState := newStates[id]
+					assert.True(t, hasNewState)
+					return plugin.ReadResult{Outputs: new}, resource.StatusOK, nil
+				},
+				CancelF: func() error {
+					close(cancelled)
+					return nil
+				},
+			}, nil
+		}),
+	}
+
+	refreshed := make(map[resource.ID]bool)
+	op := TestOp(Refresh)
+	options := UpdateOptions{
+		Parallel: 1,
+		host:     deploytest.NewPluginHost(nil, nil, nil, loaders...),
+	}
+	project, target := p.GetProject(), p.GetTarget(old)
+	validate := func(project workspace.Project, target deploy.Target, j *Journal, _ []Event, err error) error {
+		for _, entry := range j.Entries {
+			assert.Equal(t, deploy.OpRefresh, entry.Step.Op())
+			resultOp := entry.Step.(*deploy.RefreshStep).ResultOp()
+
+			old := entry.Step.Old()
+			if !old.Custom || providers.IsProviderType(old.Type) {
+				// Component and provider resources should never change.
+				assert.Equal(t, deploy.OpSame, resultOp)
+				continue
+			}
+
+			refreshed[old.ID] = true
+
+			expected, new := newStates[old.ID], entry.Step.New()
+			if expected == nil {
+				// If the resource was deleted, we want the result op to be an OpDelete.
+				assert.Nil(t, new)
+				assert.Equal(t, deploy.OpDelete, resultOp)
+			} else {
+				// If there were changes to the outputs, we want the result op to be an OpUpdate. Otherwise we want
+				// an OpSame.
+				if reflect.DeepEqual(old.Outputs, expected) {
+					assert.Equal(t, deploy.OpSame, resultOp)
+				} else {
+					assert.Equal(t, deploy.OpUpdate, resultOp)
+				}
+
+				// Only the outputs should have changed (if anything changed).
+				old.Outputs = expected
+				assert.Equal(t, old, new)
+			}
+		}
+		return err
+	}
+
+	snap, err := op.RunWithContext(ctx, project, target, options, false, nil, validate)
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(refreshed))
+
+	provURN := p.NewProviderURN("pkgA", "default", "")
+
+	for _, r := range snap.Resources {
+		switch urn := r.URN; urn {
+		case provURN:
+			continue
+		case urnA, urnB, urnC:
+			// break
+		default:
+			t.Fatalf("unexpected resource %v", urn)
+		}
+
+		idx, err := strconv.ParseInt(string(r.ID), 0, 0)
+		assert.NoError(t, err)
+
+		if refreshed[r.ID] {
+			// The refreshed resource should have its new state.
+			expected := newStates[r.ID]
+			if expected == nil {
+				assert.Fail(t, "refreshed resource was not deleted")
+			} else {
+				old := oldResources[int(idx)]
+				old.Outputs = expected
+				assert.Equal(t, old, r)
+			}
+		} else {
+			// Any resources that were not refreshed should retain their original state.
+			old := oldResources[int(idx)]
+			assert.Equal(t, old, r)
+		}
+	}
+}
+
+// Tests that errors returned directly from the language host get logged by the engine.
+func TestLanguageHostDiagnostics(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	errorText := "oh no"
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, _ *deploytest.ResourceMonitor) error {
+		// Exiting immediately with an error simulates a language exiting immediately with a non-zero exit code.
+		return errors.New(errorText)
+	})
+
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps: []TestStep{{
+			Op:            Update,
+			ExpectFailure: true,
+			SkipPreview:   true,
+			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+				assert.Error(t, err)
+				sawExitCode := false
+				for _, evt := range evts {
+					if evt.Type == DiagEvent {
+						e := evt.Payload.(DiagEventPayload)
+						msg := colors.Never.Colorize(e.Message)
+						sawExitCode = strings.Contains(msg, errorText) && e.Severity == diag.Error
+						if sawExitCode {
+							break
+						}
+					}
+				}
+
+				assert.True(t, sawExitCode)
+				return err
+			},
+		}},
+	}
+
+	p.Run(t, nil)
+}
+
+type brokenDecrypter struct {
+	ErrorMessage string
+}
+
+func (b brokenDecrypter) DecryptValue(ciphertext string) (string, error) {
+	return "", fmt.Errorf(b.ErrorMessage)
+}
+
+// Tests that the engine presents a reasonable error message when a decrypter fails to decrypt a config value.
+func TestBrokenDecrypter(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, _ *deploytest.ResourceMonitor) error {
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	key := config.MustMakeKey("foo", "bar")
+	msg := "decryption failed"
+	configMap := make(config.Map)
+	configMap[key] = config.NewSecureValue("hunter2")
+	p := &TestPlan{
+		Options:   UpdateOptions{host: host},
+		Decrypter: brokenDecrypter{ErrorMessage: msg},
+		Config:    configMap,
+		Steps: []TestStep{{
+			Op:            Update,
+			ExpectFailure: true,
+			SkipPreview:   true,
+			Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+				assert.Error(t, err)
+				decryptErr := err.(DecryptError)
+				assert.Equal(t, key, decryptErr.Key)
+				assert.Contains(t, decryptErr.Err.Error(), msg)
+				return err
+			},
+		}},
+	}
+
+	p.Run(t, nil)
+}
+
+func TestBadResourceType(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
+		_, _, _, err := mon.RegisterResource(
+			"very:bad", "resA", true, "", false, nil, "", resource.PropertyMap{}, nil, false)
+		assert.Error(t, err)
+		rpcerr, ok := rpcerror.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, rpcerr.Code())
+		assert.Contains(t, rpcerr.Message(), "Type 'very:bad' is not a valid type token")
+
+		_, _, err = mon.ReadResource("very:bad", "someResource", "someId", "", resource.PropertyMap{}, "")
+		assert.Error(t, err)
+		rpcerr, ok = rpcerror.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, rpcerr.Code())
+		assert.Contains(t, rpcerr.Message(), "Type 'very:bad' is not a valid type token")
+
+		// Component resources may have any format type.
+		_, _, _, noErr := mon.RegisterResource(
+			"a:component", "resB", false /* custom */, "", false, nil, "", resource.PropertyMap{}, nil, false)
+		assert.NoError(t, noErr)
+
+		_, _, _, noErr = mon.RegisterResource(
+			"singlename", "resC", false /* custom */, "", false, nil, "", resource.PropertyMap{}, nil, false)
+		assert.NoError(t, noErr)
+
+		return err
+	})
+
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps: []TestStep{{
+			Op:            Update,
+			ExpectFailure: true,
+			SkipPreview:   true,
+		}},
+	}
+
+	p.Run(t, nil)
+}
+
+// Tests that provider cancellation occurs as expected.
+func TestProviderCancellation(t *testing.T) {
+	const resourceCount = 4
+
+	// Set up a cancelable context for the refresh operation.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Wait for our resource ops, then cancel.
+	var ops sync.WaitGroup
+	ops.Add(resourceCount)
+	go func() {
+		ops.Wait()
+		cancel()
+	}()
+
+	// Set up an independent cancelable context for the provider's operations.
+	provCtx, provCancel := context.WithCancel(context.Background())
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, inputs resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					// Inform the waiter that we've entered a provider op and wait for cancellation.
+					ops.Done()
+					<-provCtx.Done()
+
+					return resource.ID(urn.Name()), resource.PropertyMap{}, resource.StatusOK, nil
+				},
+				CancelF: func() error {
+					provCancel()
+					return nil
+				},
+			}, nil
+		}),
+	}
+
+	done := make(chan bool)
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		errors := make([]error, resourceCount)
+		var resources sync.WaitGroup
+		resources.Add(resourceCount)
+		for i := 0; i < resourceCount; i++ {
+			go func(idx int) {
+				_, _, _, errors[idx] = monitor.RegisterResource("pkgA:m:typA", fmt.Sprintf("res%d", idx), true, "",
+					false, nil, "", resource.PropertyMap{}, nil, false)
+				resources.Done()
+			}(i)
+		}
+		resources.Wait()
+		for _, err := range errors {
+			assert.NoError(t, err)
+		}
+		close(done)
+		return nil
+	})
+
+	p := &TestPlan{}
+	op := TestOp(Update)
+	options := UpdateOptions{
+		Parallel: resourceCount,
+		host:     deploytest.NewPluginHost(nil, nil, program, loaders...),
+	}
+	project, target := p.GetProject(), p.GetTarget(nil)
+
+	_, err := op.RunWithContext(ctx, project, target, options, false, nil, nil)
+	assert.Error(t, err)
+
+	// Wait for the program to finish.
+	<-done
+}
+
+// Tests that a preview works for a stack with pending operations.
+func TestPreviewWithPendingOperations(t *testing.T) {
+	p := &TestPlan{}
+
+	const resType = "pkgA:m:typA"
+	urnA := p.NewURN(resType, "resA", "")
+
+	newResource := func(urn resource.URN, id resource.ID, delete bool, dependencies ...resource.URN) *resource.State {
+		return &resource.State{
+			Type:         urn.Type(),
+			URN:          urn,
+			Custom:       true,
+			Delete:       delete,
+			ID:           id,
+			Inputs:       resource.PropertyMap{},
+			Outputs:      resource.PropertyMap{},
+			Dependencies: dependencies,
+		}
+	}
+
+	old := &deploy.Snapshot{
+		PendingOperations: []resource.Operation{{
+			Resource: newResource(urnA, "0", false),
+			Type:     resource.OperationTypeUpdating,
+		}},
+		Resources: []*resource.State{
+			newResource(urnA, "0", false),
+		},
+	}
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		return nil
+	})
+
+	op := TestOp(Update)
+	options := UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loaders...)}
+	project, target := p.GetProject(), p.GetTarget(old)
+
+	// A preview should succeed despite the pending operations.
+	_, err := op.Run(project, target, options, true, nil, nil)
+	assert.NoError(t, err)
+
+	// But an update should fail.
+	_, err = op.Run(project, target, options, false, nil, nil)
+	assert.EqualError(t, err, deploy.PlanPendingOperationsError{}.Error())
+}
+
+// Tests that a failed partial update causes the engine to persist the resource's old inputs and new outputs.
+func TestUpdatePartialFailure(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID, olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+					return plugin.DiffResult{
+						Changes: plugin.DiffSome,
+					}, nil
+				},
+
+				UpdateF: func(urn resource.URN, id resource.ID, olds, news resource.PropertyMap,
+					timeout float64) (resource.PropertyMap, resource.Status, error) {
+					outputs := resource.NewPropertyMapFromMap(map[string]interface{}{
+						"output_prop": 42,
+					})
+
+					return outputs, resource.StatusPartialFailure, errors.New("update failed to apply")
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
+		_, _, _, err := mon.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.NewPropertyMapFromMap(map[string]interface{}{
+				"input_prop": "new inputs",
+			}), nil, false)
+
+		return err
+	})
+
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &TestPlan{Options: UpdateOptions{host: host}}
+
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+	p.Steps = []TestStep{{
+		Op:            Update,
+		ExpectFailure: true,
+		SkipPreview:   true,
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			assert.Error(t, err)
+			for _, entry := range j.Entries {
+				switch urn := entry.Step.URN(); urn {
+				case resURN:
+					assert.Equal(t, deploy.OpUpdate, entry.Step.Op())
+					switch entry.Kind {
+					case JournalEntryBegin:
+						continue
+					case JournalEntrySuccess:
+						inputs := entry.Step.New().Inputs
+						outputs := entry.Step.New().Outputs
+						assert.Len(t, inputs, 1)
+						assert.Len(t, outputs, 1)
+						assert.Equal(t,
+							resource.NewStringProperty("old inputs"), inputs[resource.PropertyKey("input_prop")])
+						assert.Equal(t,
+							resource.NewNumberProperty(42), outputs[resource.PropertyKey("output_prop")])
+					default:
+						t.Fatalf("unexpected journal operation: %d", entry.Kind)
+					}
+				}
+			}
+
+			return err
+		},
+	}}
+
+	old := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{
+				Type:   resURN.Type(),
+				URN:    resURN,
+				Custom: true,
+				ID:     "1",
+				Inputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+					"input_prop": "old inputs",
+				}),
+				Outputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+					"output_prop": 1,
+				}),
+			},
+		},
+	}
+
+	p.Run(t, old)
+}
+
+// Tests that the StackReference resource works as intended,
+func TestStackReference(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{}
+
+	// Test that the normal lifecycle works correctly.
+	program := deploytest.NewLanguageRuntime(func(info plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
+		_, _, state, err := mon.RegisterResource("pulumi:pulumi:StackReference", "other", true, "", false, nil, "",
+			resource.NewPropertyMapFromMap(map[string]interface{}{
+				"name": "other",
+			}), nil, false)
+		assert.NoError(t, err)
+		if !info.DryRun {
+			assert.Equal(t, "bar", state["outputs"].ObjectValue()["foo"].StringValue())
+		}
+		return nil
+	})
+	p := &TestPlan{
+		BackendClient: &deploytest.BackendClient{
+			GetStackOutputsF: func(ctx context.Context, name string) (resource.PropertyMap, error) {
+				switch name {
+				case "other":
+					return resource.NewPropertyMapFromMap(map[string]interface{}{
+						"foo": "bar",
+					}), nil
+				default:
+					return nil, errors.Errorf("unknown stack \"%s\"", name)
+				}
+			},
+		},
+		Options: UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loaders...)},
+		Steps:   MakeBasicLifecycleSteps(t, 2),
+	}
+	p.Run(t, nil)
+
+	// Test that changes to `name` cause replacement.
+	resURN := p.NewURN("pulumi:pulumi:StackReference", "other", "")
+	old := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{
+				Type:   resURN.Type(),
+				URN:    resURN,
+				Custom: true,
+				ID:     "1",
+				Inputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+					"name": "other2",
+				}),
+				Outputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+					"name":    "other2",
+					"outputs": resource.PropertyMap{},
+				}),
+			},
+		},
+	}
+	p.Steps = []TestStep{{
+		Op:          Update,
+		SkipPreview: true,
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			assert.NoError(t, err)
+			for _, entry := range j.Entries {
+				switch urn := entry.Step.URN(); urn {
+				case resURN:
+					switch entry.Step.Op() {
+					case deploy.OpCreateReplacement, deploy.OpDeleteReplaced, deploy.OpReplace:
+						// OK
+					default:
+						t.Fatalf("unexpected journal operation: %v", entry.Step.Op())
+					}
+				}
+			}
+
+			return err
 		},
 	}}
+	p.Run(t, old)
+
+	// Test that unknown stacks are handled appropriately.
+	program = deploytest.NewLanguageRuntime(func(info plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
+		_, _, _, err := mon.RegisterResource("pulumi:pulumi:StackReference", "other", true, "", false, nil, "",
+			resource.NewPropertyMapFromMap(map[string]interface{}{
+				"name": "rehto",
+			}), nil, false)
+		assert.Error(t, err)
+		return err
+	})
+	p.Options = UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loaders...)}
+	p.Steps = []TestStep{{
+		Op:            Update,
+		ExpectFailure: true,
+		SkipPreview:   true,
+	}}
+	p.Run(t, nil)
+
+	// Test that unknown properties cause errors.
+	program = deploytest.NewLanguageRuntime(func(info plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
+		_, _, _, err := mon.RegisterResource("pulumi:pulumi:StackReference", "other", true, "", false, nil, "",
+			resource.NewPropertyMapFromMap(map[string]interface{}{
+				"name": "other",
+				"foo":  "bar",
+			}), nil, false)
+		assert.Error(t, err)
+		return err
+	})
+	p.Options = UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loaders...)}
+	p.Run(t, nil)
+}
+
+type channelWriter struct {
+	channel chan []byte
+}
+
+func (cw *channelWriter) Write(d []byte) (int, error) {
+	cw.channel <- d
+	return len(d), nil
+}
+
+// Tests that a failed plugin load correctly shuts down the host.
+func TestLoadFailureShutdown(t *testing.T) {
+
+	// Note that the setup here is a bit baroque, and is intended to replicate the CLI architecture that lead to
+	// issue #2170. That issue--a panic on a closed channel--was caused by the intersection of several design choices:
+	//
+	// - The provider registry loads and configures the set of providers necessary for the resources currently in the
+	//   checkpoint it is processing at plan creation time. Registry creation fails promptly if a provider plugin
+	//   fails to load (e.g. because is binary is missing).
+	// - Provider configuration in the CLI's host happens asynchronously. This is meant to allow the engine to remain
+	//   responsive while plugins configure.
+	// - Providers may call back into the CLI's host for logging. Callbacks are processed as long as the CLI's plugin
+	//   context is open.
+	// - Log events from the CLI's host are delivered to the CLI's diagnostic streams via channels. The CLI closes
+	//   these channels once the engine operation it initiated completes.
+	//
+	// These choices gave rise to the following situation:
+	// 1. The provider registry loads a provider for package A and kicks off its configuration.
+	// 2. The provider registry attempts to load a provider for package B. The load fails, and the provider registry
+	//   creation call fails promptly.
+	// 3. The engine operation requested by the CLI fails promptly because provider registry creation failed.
+	// 4. The CLI shuts down its diagnostic channels.
+	// 5. The provider for package A calls back in to the host to log a message. The host then attempts to deliver
+	//    the message to the CLI's diagnostic channels, causing a panic.
+	//
+	// The fix was to properly close the plugin host during step (3) s.t. the host was no longer accepting callbacks
+	// and would not attempt to send messages to the CLI's diagnostic channels.
+	//
+	// As such, this test attempts to replicate the CLI architecture by using one provider that configures
+	// asynchronously and attempts to call back into the engine and a second provider that fails to load.
+
+	release, done := make(chan bool), make(chan bool)
+	sinkWriter := &channelWriter{channel: make(chan []byte)}
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoaderWithHost("pkgA", semver.MustParse("1.0.0"),
+			func(host plugin.Host) (plugin.Provider, error) {
+				return &deploytest.Provider{
+					ConfigureF: func(news resource.PropertyMap) error {
+						go func() {
+							<-release
+							host.Log(diag.Info, "", "configuring pkgA provider...", 0)
+							close(done)
+						}()
+						return nil
+					},
+				}, nil
+			}),
+		deploytest.NewProviderLoader("pkgB", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return nil, errors.New("pkgB load failure")
+		}),
+	}
+
+	p := &TestPlan{}
+	provAURN := p.NewProviderURN("pkgA", "default", "")
+	provBURN := p.NewProviderURN("pkgB", "default", "")
+
+	old := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{
+				Type:    provAURN.Type(),
+				URN:     provAURN,
+				Custom:  true,
+				ID:      "0",
+				Inputs:  resource.PropertyMap{},
+				Outputs: resource.PropertyMap{},
+			},
+			{
+				Type:    provBURN.Type(),
+				URN:     provBURN,
+				Custom:  true,
+				ID:      "1",
+				Inputs:  resource.PropertyMap{},
+				Outputs: resource.PropertyMap{},
+			},
+		},
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		return nil
+	})
+
+	op := TestOp(Update)
+	sink := diag.DefaultSink(sinkWriter, sinkWriter, diag.FormatOptions{Color: colors.Raw})
+	options := UpdateOptions{host: deploytest.NewPluginHost(sink, sink, program, loaders...)}
+	project, target := p.GetProject(), p.GetTarget(old)
+
+	_, err := op.Run(project, target, options, true, nil, nil)
+	assert.Error(t, err)
+
+	close(sinkWriter.channel)
+	close(release)
+	<-done
+}
+
+func TestDeleteBeforeReplace(t *testing.T) {
+	//             A
+	//    _________|_________
+	//    B        C        D
+	//          ___|___  ___|___
+	//          E  F  G  H  I  J
+	//             |__|
+	//             K  L
+	//
+	// For a given resource R in (A, C, D):
+	// - R will be the provider for its first dependent
+	// - A change to R will require that its second dependent be replaced
+	// - A change to R will not require that its third dependent be replaced
+	//
+	// In addition, K will have a requires-replacement property that depends on both F and G, and
+	// L will have a normal property that depends on both F and G.
+	//
+	// With that in mind, the following resources should require replacement: A, B, C, E, F, and K
+
+	p := &TestPlan{}
+
+	const resType = "pkgA:m:typA"
+	type propertyDependencies map[resource.PropertyKey][]resource.URN
+
+	urnA := p.NewProviderURN("pkgA", "A", "")
+	urnB := p.NewURN(resType, "B", "")
+	urnC := p.NewProviderURN("pkgA", "C", "")
+	urnD := p.NewProviderURN("pkgA", "D", "")
+	urnE := p.NewURN(resType, "E", "")
+	urnF := p.NewURN(resType, "F", "")
+	urnG := p.NewURN(resType, "G", "")
+	urnH := p.NewURN(resType, "H", "")
+	urnI := p.NewURN(resType, "I", "")
+	urnJ := p.NewURN(resType, "J", "")
+	urnK := p.NewURN(resType, "K", "")
+	urnL := p.NewURN(resType, "L", "")
+
+	newResource := func(urn resource.URN, id resource.ID, provider string, dependencies []resource.URN,
+		propertyDeps propertyDependencies) *resource.State {
+
+		inputs := resource.PropertyMap{}
+		for k := range propertyDeps {
+			inputs[k] = resource.NewStringProperty("foo")
+		}
+
+		return &resource.State{
+			Type:                 urn.Type(),
+			URN:                  urn,
+			Custom:               true,
+			Delete:               false,
+			ID:                   id,
+			Inputs:               inputs,
+			Outputs:              resource.PropertyMap{},
+			Dependencies:         dependencies,
+			Provider:             provider,
+			PropertyDependencies: propertyDeps,
+		}
+	}
 
 	old := &deploy.Snapshot{
 		Resources: []*resource.State{
-			{
-				Type:   resURN.Type(),
-				URN:    resURN,
-				Custom: true,
-				ID:     "1",
-				Inputs: resource.NewPropertyMapFromMap(map[string]interface{}{
-					"input_prop": "old inputs",
-				}),
-				Outputs: resource.NewPropertyMapFromMap(map[string]interface{}{
-					"output_prop": 1,
-				}),
-			},
+			newResource(urnA, "0", "", nil, nil),
+			newResource(urnB, "1", string(urnA)+"::0", nil, nil),
+			newResource(urnC, "2", "",
+				[]resource.URN{urnA},
+				propertyDependencies{"A": []resource.URN{urnA}}),
+			newResource(urnD, "3", "",
+				[]resource.URN{urnA},
+				propertyDependencies{"B": []resource.URN{urnA}}),
+			newResource(urnE, "4", string(urnC)+"::2", nil, nil),
+			newResource(urnF, "5", "",
+				[]resource.URN{urnC},
+				propertyDependencies{"A": []resource.URN{urnC}}),
+			newResource(urnG, "6", "",
+				[]resource.URN{urnC},
+				propertyDependencies{"B": []resource.URN{urnC}}),
+			newResource(urnH, "4", string(urnD)+"::3", nil, nil),
+			newResource(urnI, "5", "",
+				[]resource.URN{urnD},
+				propertyDependencies{"A": []resource.URN{urnD}}),
+			newResource(urnJ, "6", "",
+				[]resource.URN{urnD},
+				propertyDependencies{"B": []resource.URN{urnD}}),
+			newResource(urnK, "7", "",
+				[]resource.URN{urnF, urnG},
+				propertyDependencies{"A": []resource.URN{urnF, urnG}}),
+			newResource(urnL, "8", "",
+				[]resource.URN{urnF, urnG},
+				propertyDependencies{"B": []resource.URN{urnF, urnG}}),
+		},
+	}
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffConfigF: func(olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+					if !olds["A"].DeepEquals(news["A"]) {
+						return plugin.DiffResult{
+							ReplaceKeys:         []resource.PropertyKey{"A"},
+							DeleteBeforeReplace: true,
+						}, nil
+					}
+					return plugin.DiffResult{}, nil
+				},
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+
+					if !olds["A"].DeepEquals(news["A"]) {
+						return plugin.DiffResult{ReplaceKeys: []resource.PropertyKey{"A"}}, nil
+					}
+					return plugin.DiffResult{}, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		register := func(urn resource.URN, provider string, inputs resource.PropertyMap) resource.ID {
+			_, id, _, err := monitor.RegisterResource(urn.Type(), string(urn.Name()), true, "", false, nil, provider,
+				inputs, nil, false)
+			assert.NoError(t, err)
+			return id
+		}
+
+		idA := register(urnA, "", resource.PropertyMap{"A": resource.NewStringProperty("bar")})
+		register(urnB, string(urnA)+"::"+string(idA), nil)
+		idC := register(urnC, "", nil)
+		idD := register(urnD, "", nil)
+		register(urnE, string(urnC)+"::"+string(idC), nil)
+		register(urnF, "", nil)
+		register(urnG, "", nil)
+		register(urnH, string(urnD)+"::"+string(idD), nil)
+		register(urnI, "", nil)
+		register(urnJ, "", nil)
+		register(urnK, "", nil)
+		register(urnL, "", nil)
+
+		return nil
+	})
+
+	p.Options.host = deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p.Steps = []TestStep{{
+		Op:            Update,
+		ExpectFailure: false,
+		SkipPreview:   true,
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			assert.NoError(t, err)
+
+			replaced := make(map[resource.URN]bool)
+			for _, entry := range j.Entries {
+				if entry.Step.Op() == deploy.OpReplace {
+					replaced[entry.Step.URN()] = true
+				}
+			}
+
+			assert.Equal(t, map[resource.URN]bool{
+				urnA: true,
+				urnB: true,
+				urnC: true,
+				urnE: true,
+				urnF: true,
+				urnK: true,
+			}, replaced)
+
+			return err
 		},
+	}}
+
+	p.Run(t, old)
+}
+
+func TestPropertyDependenciesAdapter(t *testing.T) {
+	// Ensure that the eval source properly shims in property dependencies if none were reported (and does not if
+	// any were reported).
+
+	type propertyDependencies map[resource.PropertyKey][]resource.URN
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	const resType = "pkgA:m:typA"
+	var urnA, urnB, urnC, urnD resource.URN
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+
+		register := func(name string, inputs resource.PropertyMap, inputDeps propertyDependencies,
+			dependencies []resource.URN) resource.URN {
+
+			urn, _, _, err := monitor.RegisterResource(resType, name, true, "", false, dependencies, "", inputs,
+				inputDeps, false)
+			assert.NoError(t, err)
+
+			return urn
+		}
+
+		urnA = register("A", nil, nil, nil)
+		urnB = register("B", nil, nil, nil)
+		urnC = register("C", resource.PropertyMap{
+			"A": resource.NewStringProperty("foo"),
+			"B": resource.NewStringProperty("bar"),
+		}, nil, []resource.URN{urnA, urnB})
+		urnD = register("D", resource.PropertyMap{
+			"A": resource.NewStringProperty("foo"),
+			"B": resource.NewStringProperty("bar"),
+		}, propertyDependencies{
+			"A": []resource.URN{urnB},
+			"B": []resource.URN{urnA, urnC},
+		}, []resource.URN{urnA, urnB, urnC})
+
+		return nil
+	})
+
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+		Steps:   []TestStep{{Op: Update}},
+	}
+	snap := p.Run(t, nil)
+	for _, res := range snap.Resources {
+		switch res.URN {
+		case urnA, urnB:
+			assert.Empty(t, res.Dependencies)
+			assert.Empty(t, res.PropertyDependencies)
+		case urnC:
+			assert.Equal(t, []resource.URN{urnA, urnB}, res.Dependencies)
+			assert.EqualValues(t, propertyDependencies{
+				"A": res.Dependencies,
+				"B": res.Dependencies,
+			}, res.PropertyDependencies)
+		case urnD:
+			assert.Equal(t, []resource.URN{urnA, urnB, urnC}, res.Dependencies)
+			assert.EqualValues(t, propertyDependencies{
+				"A": []resource.URN{urnB},
+				"B": []resource.URN{urnA, urnC},
+			}, res.PropertyDependencies)
+		}
+	}
+}
+
+func TestExplicitDeleteBeforeReplace(t *testing.T) {
+	p := &TestPlan{}
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+
+					if !olds["A"].DeepEquals(news["A"]) {
+						return plugin.DiffResult{ReplaceKeys: []resource.PropertyKey{"A"}}, nil
+					}
+					return plugin.DiffResult{}, nil
+				},
+			}, nil
+		}),
 	}
 
-	p.Run(t, old)
-}
+	const resType = "pkgA:index:typ"
+
+	inputsA := resource.NewPropertyMapFromMap(map[string]interface{}{"A": "foo"})
+	dbrA := false
+	inputsB := resource.NewPropertyMapFromMap(map[string]interface{}{"A": "foo"})
+
+	var provURN, urnA, urnB resource.URN
+	var provID resource.ID
+	var err error
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		provURN, provID, _, err = monitor.RegisterResource(
+			providers.MakeProviderType("pkgA"), "provA", true, "", false, nil, "", nil, nil, false)
+		assert.NoError(t, err)
+
+		if provID == "" {
+			provID = providers.UnknownID
+		}
+		provRef, err := providers.NewReference(provURN, provID)
+		assert.NoError(t, err)
+		provA := provRef.String()
 
-// Tests that the StackReference resource works as intended,
-func TestStackReference(t *testing.T) {
-	loaders := []*deploytest.ProviderLoader{}
+		urnA, _, _, err = monitor.RegisterResource(resType, "resA", true, "", false, nil, provA, inputsA, nil, dbrA)
+		assert.NoError(t, err)
 
-	// Test that the normal lifecycle works correctly.
-	program := deploytest.NewLanguageRuntime(func(info plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
-		_, _, state, err := mon.RegisterResource("pulumi:pulumi:StackReference", "other", true, "", false, nil, "",
-			resource.NewPropertyMapFromMap(map[string]interface{}{
-				"name": "other",
-			}), nil, false)
+		inputDepsB := map[resource.PropertyKey][]resource.URN{"A": {urnA}}
+		urnB, _, _, err = monitor.RegisterResource(resType, "resB", true, "", false, []resource.URN{urnA}, provA,
+			inputsB, inputDepsB, false)
 		assert.NoError(t, err)
-		if !info.DryRun {
-			assert.Equal(t, "bar", state["outputs"].ObjectValue()["foo"].StringValue())
-		}
+
 		return nil
 	})
-	p := &TestPlan{
-		BackendClient: &deploytest.BackendClient{
-			GetStackOutputsF: func(ctx context.Context, name string) (resource.PropertyMap, error) {
-				switch name {
-				case "other":
-					return resource.NewPropertyMapFromMap(map[string]interface{}{
-						"foo": "bar",
-					}), nil
-				default:
-					return nil, errors.Errorf("unknown stack \"%s\"", name)
-				}
-			},
-		},
-		Options: UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loaders...)},
-		Steps:   MakeBasicLifecycleSteps(t, 2),
-	}
-	p.Run(t, nil)
 
-	// Test that changes to `name` cause replacement.
-	resURN := p.NewURN("pulumi:pulumi:StackReference", "other", "")
-	old := &deploy.Snapshot{
-		Resources: []*resource.State{
-			{
-				Type:   resURN.Type(),
-				URN:    resURN,
-				Custom: true,
-				ID:     "1",
-				Inputs: resource.NewPropertyMapFromMap(map[string]interface{}{
-					"name": "other2",
-				}),
-				Outputs: resource.NewPropertyMapFromMap(map[string]interface{}{
-					"name":    "other2",
-					"outputs": resource.PropertyMap{},
-				}),
-			},
-		},
-	}
+	p.Options.host = deploytest.NewPluginHost(nil, nil, program, loaders...)
+	p.Steps = []TestStep{{Op: Update}}
+	snap := p.Run(t, nil)
+
+	// Change the value of resA.A. Only resA should be replaced, and the replacement should be create-before-delete.
+	inputsA["A"] = resource.NewStringProperty("bar")
 	p.Steps = []TestStep{{
-		Op:          Update,
-		SkipPreview: true,
+		Op: Update,
+
 		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
 			assert.NoError(t, err)
-			for _, entry := range j.Entries {
-				switch urn := entry.Step.URN(); urn {
-				case resURN:
-					switch entry.Step.Op() {
-					case deploy.OpCreateReplacement, deploy.OpDeleteReplaced, deploy.OpReplace:
-						// OK
-					default:
-						t.Fatalf("unexpected journal operation: %v", entry.Step.Op())
-					}
-				}
-			}
+
+			AssertSameSteps(t, []StepSummary{
+				{Op: deploy.OpSame, URN: provURN},
+				{Op: deploy.OpCreateReplacement, URN: urnA},
+				{Op: deploy.OpReplace, URN: urnA},
+				{Op: deploy.OpSame, URN: urnB},
+				{Op: deploy.OpDeleteReplaced, URN: urnA},
+			}, j.SuccessfulSteps())
 
 			return err
 		},
 	}}
-	p.Run(t, old)
+	snap = p.Run(t, snap)
 
-	// Test that unknown stacks are handled appropriately.
-	program = deploytest.NewLanguageRuntime(func(info plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
-		_, _, _, err := mon.RegisterResource("pulumi:pulumi:StackReference", "other", true, "", false, nil, "",
-			resource.NewPropertyMapFromMap(map[string]interface{}{
-				"name": "rehto",
-			}), nil, false)
-		assert.Error(t, err)
-		return err
-	})
-	p.Options = UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loaders...)}
+	// Change the registration of resA such that it requires delete-before-replace and change the value of resA.A. Both
+	// resA and resB should be replaced, and the replacements should be delete-before-replace.
+	dbrA, inputsA["A"] = true, resource.NewStringProperty("baz")
 	p.Steps = []TestStep{{
-		Op:            Update,
-		ExpectFailure: true,
-		SkipPreview:   true,
-	}}
-	p.Run(t, nil)
+		Op: Update,
 
-	// Test that unknown properties cause errors.
-	program = deploytest.NewLanguageRuntime(func(info plugin.RunInfo, mon *deploytest.ResourceMonitor) error {
-		_, _, _, err := mon.RegisterResource("pulumi:pulumi:StackReference", "other", true, "", false, nil, "",
-			resource.NewPropertyMapFromMap(map[string]interface{}{
-				"name": "other",
-				"foo":  "bar",
-			}), nil, false)
-		assert.Error(t, err)
-		return err
-	})
-	p.Options = UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loaders...)}
-	p.Run(t, nil)
-}
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			assert.NoError(t, err)
 
-type channelWriter struct {
-	channel chan []byte
-}
+			AssertSameSteps(t, []StepSummary{
+				{Op: deploy.OpSame, URN: provURN},
+				{Op: deploy.OpDeleteReplaced, URN: urnB},
+				{Op: deploy.OpDeleteReplaced, URN: urnA},
+				{Op: deploy.OpReplace, URN: urnA},
+				{Op: deploy.OpCreateReplacement, URN: urnA},
+				{Op: deploy.OpReplace, URN: urnB},
+				{Op: deploy.OpCreateReplacement, URN: urnB},
+			}, j.SuccessfulSteps())
 
-func (cw *channelWriter) Write(d []byte) (int, error) {
-	cw.channel <- d
-	return len(d), nil
-}
+			return err
+		},
+	}}
+	snap = p.Run(t, snap)
 
-// Tests that a failed plugin load correctly shuts down the host.
-func TestLoadFailureShutdown(t *testing.T) {
+	// Change the value of resB.A. Only resB should be replaced, and the replacement should be create-before-delete.
+	inputsB["A"] = resource.NewStringProperty("qux")
+	p.Steps = []TestStep{{
+		Op: Update,
 
-	// Note that the setup here is a bit baroque, and is intended to replicate the CLI architecture that lead to
-	// issue #2170. That issue--a panic on a closed channel--was caused by the intersection of several design choices:
-	//
-	// - The provider registry loads and configures the set of providers necessary for the resources currently in the
-	//   checkpoint it is processing at plan creation time. Registry creation fails promptly if a provider plugin
-	//   fails to load (e.g. because is binary is missing).
-	// - Provider configuration in the CLI's host happens asynchronously. This is meant to allow the engine to remain
-	//   responsive while plugins configure.
-	// - Providers may call back into the CLI's host for logging. Callbacks are processed as long as the CLI's plugin
-	//   context is open.
-	// - Log events from the CLI's host are delivered to the CLI's diagnostic streams via channels. The CLI closes
-	//   these channels once the engine operation it initiated completes.
-	//
-	// These choices gave rise to the following situation:
-	// 1. The provider registry loads a provider for package A and kicks off its configuration.
-	// 2. The provider registry attempts to load a provider for package B. The load fails, and the provider registry
-	//   creation call fails promptly.
-	// 3. The engine operation requested by the CLI fails promptly because provider registry creation failed.
-	// 4. The CLI shuts down its diagnostic channels.
-	// 5. The provider for package A calls back in to the host to log a message. The host then attempts to deliver
-	//    the message to the CLI's diagnostic channels, causing a panic.
-	//
-	// The fix was to properly close the plugin host during step (3) s.t. the host was no longer accepting callbacks
-	// and would not attempt to send messages to the CLI's diagnostic channels.
-	//
-	// As such, this test attempts to replicate the CLI architecture by using one provider that configures
-	// asynchronously and attempts to call back into the engine and a second provider that fails to load.
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			assert.NoError(t, err)
 
-	release, done := make(chan bool), make(chan bool)
-	sinkWriter := &channelWriter{channel: make(chan []byte)}
+			AssertSameSteps(t, []StepSummary{
+				{Op: deploy.OpSame, URN: provURN},
+				{Op: deploy.OpSame, URN: urnA},
+				{Op: deploy.OpCreateReplacement, URN: urnB},
+				{Op: deploy.OpReplace, URN: urnB},
+				{Op: deploy.OpDeleteReplaced, URN: urnB},
+			}, j.SuccessfulSteps())
 
-	loaders := []*deploytest.ProviderLoader{
-		deploytest.NewProviderLoaderWithHost("pkgA", semver.MustParse("1.0.0"),
-			func(host plugin.Host) (plugin.Provider, error) {
-				return &deploytest.Provider{
-					ConfigureF: func(news resource.PropertyMap) error {
-						go func() {
-							<-release
-							host.Log(diag.Info, "", "configuring pkgA provider...", 0)
-							close(done)
-						}()
-						return nil
-					},
-				}, nil
-			}),
-		deploytest.NewProviderLoader("pkgB", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
-			return nil, errors.New("pkgB load failure")
-		}),
-	}
+			return err
+		},
+	}}
+	snap = p.Run(t, snap)
 
-	p := &TestPlan{}
-	provAURN := p.NewProviderURN("pkgA", "default", "")
-	provBURN := p.NewProviderURN("pkgB", "default", "")
+	// Change the registration of resA such that it no longer requires delete-before-replace and change the value of
+	// resA.A. Only resA should be replaced, and the replacement should be delete-before-replace.
+	dbrA, inputsA["A"] = false, resource.NewStringProperty("zam")
+	p.Steps = []TestStep{{
+		Op: Update,
 
-	old := &deploy.Snapshot{
-		Resources: []*resource.State{
-			{
-				Type:    provAURN.Type(),
-				URN:     provAURN,
-				Custom:  true,
-				ID:      "0",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-			},
-			{
-				Type:    provBURN.Type(),
-				URN:     provBURN,
-				Custom:  true,
-				ID:      "1",
-				Inputs:  resource.PropertyMap{},
-				Outputs: resource.PropertyMap{},
-			},
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
+			assert.NoError(t, err)
+
+			AssertSameSteps(t, []StepSummary{
+				{Op: deploy.OpSame, URN: provURN},
+				{Op: deploy.OpCreateReplacement, URN: urnA},
+				{Op: deploy.OpReplace, URN: urnA},
+				{Op: deploy.OpSame, URN: urnB},
+				{Op: deploy.OpDeleteReplaced, URN: urnA},
+			}, j.SuccessfulSteps())
+
+			return err
 		},
+	}}
+	p.Run(t, snap)
+}
+
+func TestImportResource(t *testing.T) {
+	creates := 0
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					creates++
+					return "created-id", news, resource.StatusOK, nil
+				},
+				DiffF: func(urn resource.URN, id resource.ID,
+					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+
+					if !olds["foo"].DeepEquals(news["foo"]) {
+						return plugin.DiffResult{
+							Changes:     plugin.DiffSome,
+							ChangedKeys: []resource.PropertyKey{"foo"},
+						}, nil
+					}
+					return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					if id != "existing-id" {
+						return plugin.ReadResult{}, resource.StatusOK, nil
+					}
+					return plugin.ReadResult{
+						Outputs: resource.PropertyMap{"foo": resource.NewStringProperty("bar")},
+					}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
 	}
 
+	importID, foo := resource.ID("existing-id"), "bar"
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		return nil
+		_, _, err := monitor.ImportResource("pkgA:m:typA", "resA", importID, "", resource.PropertyMap{
+			"foo": resource.NewStringProperty(foo),
+		}, "")
+		return err
 	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	op := TestOp(Update)
-	sink := diag.DefaultSink(sinkWriter, sinkWriter, diag.FormatOptions{Color: colors.Raw})
-	options := UpdateOptions{host: deploytest.NewPluginHost(sink, sink, program, loaders...)}
-	project, target := p.GetProject(), p.GetTarget(old)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+	}
+	project := p.GetProject()
 
-	_, err := op.Run(project, target, options, true, nil, nil)
+	// Importing a resource whose live state matches the program adopts it without creating anything.
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, j *Journal, _ []Event, err error) error {
+			for _, entry := range j.Entries {
+				if entry.Step.URN().Name() == "resA" {
+					assert.Equal(t, deploy.OpImport, entry.Step.Op())
+				}
+			}
+			return err
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, creates)
+	found := false
+	for _, res := range snap.Resources {
+		if res.URN.Name() == "resA" {
+			found = true
+			assert.Equal(t, resource.ID("existing-id"), res.ID)
+			assert.Equal(t, resource.NewStringProperty("bar"), res.Outputs["foo"])
+		}
+	}
+	assert.True(t, found)
+
+	// Inputs that do not match the live resource cause the import to fail.
+	foo = "baz"
+	_, err = TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
 	assert.Error(t, err)
 
-	close(sinkWriter.channel)
-	close(release)
-	<-done
+	// As does an ID that does not name an existing resource.
+	importID, foo = "missing-id", "bar"
+	_, err = TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 0, creates)
 }
 
-func TestDeleteBeforeReplace(t *testing.T) {
-	//             A
-	//    _________|_________
-	//    B        C        D
-	//          ___|___  ___|___
-	//          E  F  G  H  I  J
-	//             |__|
-	//             K  L
-	//
-	// For a given resource R in (A, C, D):
-	// - R will be the provider for its first dependent
-	// - A change to R will require that its second dependent be replaced
-	// - A change to R will not require that its third dependent be replaced
-	//
-	// In addition, K will have a requires-replacement property that depends on both F and G, and
-	// L will have a normal property that depends on both F and G.
-	//
-	// With that in mind, the following resources should require replacement: A, B, C, E, F, and K
+func TestImportResourceContinueOnError(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
 
-	p := &TestPlan{}
+					return resource.ID(urn.Name()), news, resource.StatusOK, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
 
-	const resType = "pkgA:m:typA"
-	type propertyDependencies map[resource.PropertyKey][]resource.URN
+					return plugin.ReadResult{}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
 
-	urnA := p.NewProviderURN("pkgA", "A", "")
-	urnB := p.NewURN(resType, "B", "")
-	urnC := p.NewProviderURN("pkgA", "C", "")
-	urnD := p.NewProviderURN("pkgA", "D", "")
-	urnE := p.NewURN(resType, "E", "")
-	urnF := p.NewURN(resType, "F", "")
-	urnG := p.NewURN(resType, "G", "")
-	urnH := p.NewURN(resType, "H", "")
-	urnI := p.NewURN(resType, "I", "")
-	urnJ := p.NewURN(resType, "J", "")
-	urnK := p.NewURN(resType, "K", "")
-	urnL := p.NewURN(resType, "L", "")
+	// The import of resA fails because its ID does not name an existing resource. resB is independent of it.
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, err := monitor.ImportResource("pkgA:m:typA", "resA", "missing-id", "", resource.PropertyMap{}, "")
+		assert.Error(t, err)
 
-	newResource := func(urn resource.URN, id resource.ID, provider string, dependencies []resource.URN,
-		propertyDeps propertyDependencies) *resource.State {
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		return err
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-		inputs := resource.PropertyMap{}
-		for k := range propertyDeps {
-			inputs[k] = resource.NewStringProperty("foo")
+	p := &TestPlan{
+		Options: UpdateOptions{host: host, ContinueOnError: true},
+	}
+
+	// The failed import must not block the program, so resB should still be created.
+	snap, err := TestOp(Update).Run(p.GetProject(), p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+
+	var names []string
+	for _, res := range snap.Resources {
+		if res.Type == "pkgA:m:typA" {
+			names = append(names, string(res.URN.Name()))
 		}
+	}
+	assert.Equal(t, []string{"resB"}, names)
+}
 
-		return &resource.State{
-			Type:                 urn.Type(),
-			URN:                  urn,
-			Custom:               true,
-			Delete:               false,
-			ID:                   id,
-			Inputs:               inputs,
-			Outputs:              resource.PropertyMap{},
-			Dependencies:         dependencies,
-			Provider:             provider,
-			PropertyDependencies: propertyDeps,
+func TestRolloutPhases(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	version, names := "v1", []string{"web-0", "web-1", "web-2"}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		for _, name := range names {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", name, true, "", false, nil, "",
+				resource.PropertyMap{"version": resource.NewStringProperty(version)}, nil, false)
+			assert.NoError(t, err)
 		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
 	}
+	project := p.GetProject()
+	project.Rollout = []workspace.ProjectRolloutPhase{{Name: "canary", Resources: []string{"web-0"}}, {}}
 
-	old := &deploy.Snapshot{
-		Resources: []*resource.State{
-			newResource(urnA, "0", "", nil, nil),
-			newResource(urnB, "1", string(urnA)+"::0", nil, nil),
-			newResource(urnC, "2", "",
-				[]resource.URN{urnA},
-				propertyDependencies{"A": []resource.URN{urnA}}),
-			newResource(urnD, "3", "",
-				[]resource.URN{urnA},
-				propertyDependencies{"B": []resource.URN{urnA}}),
-			newResource(urnE, "4", string(urnC)+"::2", nil, nil),
-			newResource(urnF, "5", "",
-				[]resource.URN{urnC},
-				propertyDependencies{"A": []resource.URN{urnC}}),
-			newResource(urnG, "6", "",
-				[]resource.URN{urnC},
-				propertyDependencies{"B": []resource.URN{urnC}}),
-			newResource(urnH, "4", string(urnD)+"::3", nil, nil),
-			newResource(urnI, "5", "",
-				[]resource.URN{urnD},
-				propertyDependencies{"A": []resource.URN{urnD}}),
-			newResource(urnJ, "6", "",
-				[]resource.URN{urnD},
-				propertyDependencies{"B": []resource.URN{urnD}}),
-			newResource(urnK, "7", "",
-				[]resource.URN{urnF, urnG},
-				propertyDependencies{"A": []resource.URN{urnF, urnG}}),
-			newResource(urnL, "8", "",
-				[]resource.URN{urnF, urnG},
-				propertyDependencies{"B": []resource.URN{urnF, urnG}}),
-		},
+	versions := func(snap *deploy.Snapshot) map[string]string {
+		result := make(map[string]string)
+		for _, res := range snap.Resources {
+			if res.Type == "pkgA:m:typA" {
+				result[string(res.URN.Name())] = res.Inputs["version"].StringValue()
+			}
+		}
+		return result
 	}
 
+	// With no phase selected, the initial update creates every resource.
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"web-0": "v1", "web-1": "v1", "web-2": "v1"}, versions(snap))
+
+	// The canary phase updates only web-0, and holds back both the update of web-1 and the deletion of web-2.
+	version, names = "v2", []string{"web-0", "web-1"}
+	p.Options.RolloutPhase = 1
+	snap, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"web-0": "v2", "web-1": "v1", "web-2": "v1"}, versions(snap))
+
+	// The final phase rolls out the remaining changes.
+	p.Options.RolloutPhase = 2
+	snap, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"web-0": "v2", "web-1": "v2"}, versions(snap))
+}
+
+func TestUpdateTimingReport(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				DiffConfigF: func(olds, news resource.PropertyMap) (plugin.DiffResult, error) {
-					if !olds["A"].DeepEquals(news["A"]) {
-						return plugin.DiffResult{
-							ReplaceKeys:         []resource.PropertyKey{"A"},
-							DeleteBeforeReplace: true,
-						}, nil
-					}
-					return plugin.DiffResult{}, nil
-				},
-				DiffF: func(urn resource.URN, id resource.ID,
-					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+				CreateF: func(urn resource.URN, news resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
 
-					if !olds["A"].DeepEquals(news["A"]) {
-						return plugin.DiffResult{ReplaceKeys: []resource.PropertyKey{"A"}}, nil
+					if urn.Name() != "resC" {
+						time.Sleep(20 * time.Millisecond)
 					}
-					return plugin.DiffResult{}, nil
+					return resource.ID(urn.Name()), news, resource.StatusOK, nil
 				},
 			}, nil
 		}),
 	}
 
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		register := func(urn resource.URN, provider string, inputs resource.PropertyMap) resource.ID {
-			_, id, _, err := monitor.RegisterResource(urn.Type(), string(urn.Name()), true, "", false, nil, provider,
-				inputs, nil, false)
+		// Register resC independently of the chain from resA to resB, as an asynchronous program would.
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resC", true, "", false, nil, "",
+				resource.PropertyMap{}, nil, false)
 			assert.NoError(t, err)
-			return id
-		}
-
-		idA := register(urnA, "", resource.PropertyMap{"A": resource.NewStringProperty("bar")})
-		register(urnB, string(urnA)+"::"+string(idA), nil)
-		idC := register(urnC, "", nil)
-		idD := register(urnD, "", nil)
-		register(urnE, string(urnC)+"::"+string(idC), nil)
-		register(urnF, "", nil)
-		register(urnG, "", nil)
-		register(urnH, string(urnD)+"::"+string(idD), nil)
-		register(urnI, "", nil)
-		register(urnJ, "", nil)
-		register(urnK, "", nil)
-		register(urnL, "", nil)
+		}()
 
+		urnA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, "", false, []resource.URN{urnA}, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
+		wg.Wait()
 		return nil
 	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p.Options.host = deploytest.NewPluginHost(nil, nil, program, loaders...)
-
-	p.Steps = []TestStep{{
-		Op:            Update,
-		ExpectFailure: false,
-		SkipPreview:   true,
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-			assert.NoError(t, err)
-
-			replaced := make(map[resource.URN]bool)
-			for _, entry := range j.Entries {
-				if entry.Step.Op() == deploy.OpReplace {
-					replaced[entry.Step.URN()] = true
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+	}
+	_, err := TestOp(Update).Run(p.GetProject(), p.GetTarget(nil), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, _ *Journal, events []Event, err error) error {
+			var report *TimingReport
+			for _, e := range events {
+				if e.Type == SummaryEvent {
+					report = e.Payload.(SummaryEventPayload).Timings
 				}
 			}
+			if !assert.NotNil(t, report) {
+				return err
+			}
 
-			assert.Equal(t, map[resource.URN]bool{
-				urnA: true,
-				urnB: true,
-				urnC: true,
-				urnE: true,
-				urnF: true,
-				urnK: true,
-			}, replaced)
-
+			// Every created resource is timed, and the critical path runs through resA to resB.
+			assert.Len(t, report.Resources, 4)
+			var path []string
+			for _, timing := range report.CriticalPath {
+				path = append(path, string(timing.URN.Name()))
+			}
+			assert.Equal(t, []string{"default", "resA", "resB"}, path)
+			assert.True(t, report.CriticalPathDuration >= 40*time.Millisecond)
+
+			// The calls made to the provider are counted, and the slow creates dominate their latency.
+			if assert.Len(t, report.Providers, 1) {
+				provider := report.Providers[0]
+				assert.Equal(t, tokens.Package("pkgA"), provider.Package)
+				calls := make(map[string]ProviderCallTiming)
+				for _, call := range provider.Calls {
+					calls[call.Method] = call
+				}
+				assert.Equal(t, 3, calls["Check"].Count)
+				assert.Equal(t, 3, calls["Create"].Count)
+				assert.True(t, calls["Create"].P95 >= 20*time.Millisecond)
+				assert.True(t, calls["Create"].Duration >= 40*time.Millisecond)
+				assert.True(t, provider.Duration >= calls["Create"].Duration)
+			}
 			return err
-		},
-	}}
-
-	p.Run(t, old)
+		})
+	assert.NoError(t, err)
 }
 
-func TestPropertyDependenciesAdapter(t *testing.T) {
-	// Ensure that the eval source properly shims in property dependencies if none were reported (and does not if
-	// any were reported).
-
-	type propertyDependencies map[resource.PropertyKey][]resource.URN
-
+func TestProviderConfigCheckedBeforePlanning(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
-			return &deploytest.Provider{}, nil
+			return &deploytest.Provider{
+				CheckConfigF: func(olds,
+					news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+					if news["region"].StringValue() != "us-west-2" {
+						return nil, []plugin.CheckFailure{{Property: "region", Reason: "unsupported region"}}, nil
+					}
+					return news, nil, nil
+				},
+			}, nil
 		}),
 	}
 
-	const resType = "pkgA:m:typA"
-	var urnA, urnB, urnC, urnD resource.URN
+	programRan := false
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-
-		register := func(name string, inputs resource.PropertyMap, inputDeps propertyDependencies,
-			dependencies []resource.URN) resource.URN {
-
-			urn, _, _, err := monitor.RegisterResource(resType, name, true, "", false, dependencies, "", inputs,
-				inputDeps, false)
-			assert.NoError(t, err)
-
-			return urn
-		}
-
-		urnA = register("A", nil, nil, nil)
-		urnB = register("B", nil, nil, nil)
-		urnC = register("C", resource.PropertyMap{
-			"A": resource.NewStringProperty("foo"),
-			"B": resource.NewStringProperty("bar"),
-		}, nil, []resource.URN{urnA, urnB})
-		urnD = register("D", resource.PropertyMap{
-			"A": resource.NewStringProperty("foo"),
-			"B": resource.NewStringProperty("bar"),
-		}, propertyDependencies{
-			"A": []resource.URN{urnB},
-			"B": []resource.URN{urnA, urnC},
-		}, []resource.URN{urnA, urnB, urnC})
-
+		programRan = true
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		assert.NoError(t, err)
 		return nil
-	})
-
+	}, workspace.PluginInfo{Kind: workspace.ResourcePlugin, Name: "pkgA"})
 	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	// Invalid configuration for a provider that the program requires fails the update before the program runs.
 	p := &TestPlan{
 		Options: UpdateOptions{host: host},
-		Steps:   []TestStep{{Op: Update}},
-	}
-	snap := p.Run(t, nil)
-	for _, res := range snap.Resources {
-		switch res.URN {
-		case urnA, urnB:
-			assert.Empty(t, res.Dependencies)
-			assert.Empty(t, res.PropertyDependencies)
-		case urnC:
-			assert.Equal(t, []resource.URN{urnA, urnB}, res.Dependencies)
-			assert.EqualValues(t, propertyDependencies{
-				"A": res.Dependencies,
-				"B": res.Dependencies,
-			}, res.PropertyDependencies)
-		case urnD:
-			assert.Equal(t, []resource.URN{urnA, urnB, urnC}, res.Dependencies)
-			assert.EqualValues(t, propertyDependencies{
-				"A": []resource.URN{urnB},
-				"B": []resource.URN{urnA, urnC},
-			}, res.PropertyDependencies)
-		}
+		Config: config.Map{
+			config.MustMakeKey("pkgA", "region"): config.NewValue("mars-north-1"),
+		},
+		Steps: []TestStep{{
+			Op:            Update,
+			ExpectFailure: true,
+			SkipPreview:   true,
+			Validate: func(_ workspace.Project, _ deploy.Target, _ *Journal, _ []Event, err error) error {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "pkgA:region: unsupported region")
+				assert.False(t, programRan)
+				return err
+			},
+		}},
 	}
-}
+	p.Run(t, nil)
 
-func TestExplicitDeleteBeforeReplace(t *testing.T) {
-	p := &TestPlan{}
+	// Valid configuration is checked, and the update proceeds.
+	p.Config[config.MustMakeKey("pkgA", "region")] = config.NewValue("us-west-2")
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true}}
+	p.Run(t, nil)
+	assert.True(t, programRan)
+}
 
+func TestMockProviders(t *testing.T) {
+	// The real provider must never be used while mock providers are enabled.
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
 			return &deploytest.Provider{
-				DiffF: func(urn resource.URN, id resource.ID,
-					olds, news resource.PropertyMap) (plugin.DiffResult, error) {
-
-					if !olds["A"].DeepEquals(news["A"]) {
-						return plugin.DiffResult{ReplaceKeys: []resource.PropertyKey{"A"}}, nil
-					}
-					return plugin.DiffResult{}, nil
+				CreateF: func(urn resource.URN, inputs resource.PropertyMap,
+					timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					return "", nil, resource.StatusOK, errors.New("the real provider should not be used")
 				},
 			}, nil
 		}),
 	}
 
-	const resType = "pkgA:index:typ"
-
-	inputsA := resource.NewPropertyMapFromMap(map[string]interface{}{"A": "foo"})
-	dbrA := false
-	inputsB := resource.NewPropertyMapFromMap(map[string]interface{}{"A": "foo"})
-
-	var provURN, urnA, urnB resource.URN
-	var provID resource.ID
-	var err error
+	size := "small"
+	var region resource.PropertyValue
 	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
-		provURN, provID, _, err = monitor.RegisterResource(
-			providers.MakeProviderType("pkgA"), "provA", true, "", false, nil, "", nil, nil, false)
-		assert.NoError(t, err)
-
-		if provID == "" {
-			provID = providers.UnknownID
-		}
-		provRef, err := providers.NewReference(provURN, provID)
+		outs, _, err := monitor.Invoke("pkgA:index:getRegion", resource.PropertyMap{}, "")
 		assert.NoError(t, err)
-		provA := provRef.String()
+		region = outs["name"]
 
-		urnA, _, _, err = monitor.RegisterResource(resType, "resA", true, "", false, nil, provA, inputsA, nil, dbrA)
+		props := resource.PropertyMap{"size": resource.NewStringProperty(size)}
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", props, nil, false)
 		assert.NoError(t, err)
-
-		inputDepsB := map[resource.PropertyKey][]resource.URN{"A": {urnA}}
-		urnB, _, _, err = monitor.RegisterResource(resType, "resB", true, "", false, []resource.URN{urnA}, provA,
-			inputsB, inputDepsB, false)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typB", "resB", true, "", false, nil, "", props, nil, false)
 		assert.NoError(t, err)
-
 		return nil
-	})
+	}, workspace.PluginInfo{Kind: workspace.ResourcePlugin, Name: "pkgA"})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-	p.Options.host = deploytest.NewPluginHost(nil, nil, program, loaders...)
-	p.Steps = []TestStep{{Op: Update}}
-	snap := p.Run(t, nil)
+	p := &TestPlan{
+		Options: UpdateOptions{
+			host: host,
+			MockProviders: &plugin.MockFixtures{
+				Resources: map[string]plugin.MockResource{
+					"pkgA:m:typA": {
+						ID:               "a-123",
+						Outputs:          map[string]interface{}{"arn": "arn:a-123"},
+						ReplaceOnChanges: []string{"size"},
+					},
+				},
+				Invokes: map[string]map[string]interface{}{
+					"pkgA:index:getRegion": {"name": "us-west-2"},
+				},
+			},
+		},
+	}
+	project := p.GetProject()
 
-	// Change the value of resA.A. Only resA should be replaced, and the replacement should be create-before-delete.
-	inputsA["A"] = resource.NewStringProperty("bar")
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.NewStringProperty("us-west-2"), region)
+	ids := make(map[tokens.Type]resource.ID)
+	for _, res := range snap.Resources {
+		ids[res.Type] = res.ID
+		if res.Type == "pkgA:m:typA" {
+			assert.Equal(t, resource.NewStringProperty("arn:a-123"), res.Outputs["arn"])
+			assert.Equal(t, resource.NewStringProperty("small"), res.Outputs["size"])
+		}
+	}
+	assert.Equal(t, resource.ID("a-123"), ids["pkgA:m:typA"])
+	assert.Contains(t, string(ids["pkgA:m:typB"]), "resB-")
+
+	// A change to a property that the fixtures mark as forcing replacement replaces only the first resource.
+	size = "large"
 	p.Steps = []TestStep{{
 		Op: Update,
-
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-			assert.NoError(t, err)
-
-			AssertSameSteps(t, []StepSummary{
-				{Op: deploy.OpSame, URN: provURN},
-				{Op: deploy.OpCreateReplacement, URN: urnA},
-				{Op: deploy.OpReplace, URN: urnA},
-				{Op: deploy.OpSame, URN: urnB},
-				{Op: deploy.OpDeleteReplaced, URN: urnA},
-			}, j.SuccessfulSteps())
-
+		Validate: func(project workspace.Project, target deploy.Target, j *Journal, events []Event, err error) error {
+			replaced, updated := make(map[resource.URN]bool), make(map[resource.URN]bool)
+			for _, entry := range j.Entries {
+				switch entry.Step.Op() {
+				case deploy.OpReplace:
+					replaced[entry.Step.URN()] = true
+				case deploy.OpUpdate:
+					updated[entry.Step.URN()] = true
+				}
+			}
+			resA, resB := p.NewURN("pkgA:m:typA", "resA", ""), p.NewURN("pkgA:m:typB", "resB", "")
+			assert.True(t, replaced[resA])
+			assert.False(t, replaced[resB])
+			assert.True(t, updated[resB])
 			return err
 		},
 	}}
-	snap = p.Run(t, snap)
-
-	// Change the registration of resA such that it requires delete-before-replace and change the value of resA.A. Both
-	// resA and resB should be replaced, and the replacements should be delete-before-replace.
-	dbrA, inputsA["A"] = true, resource.NewStringProperty("baz")
-	p.Steps = []TestStep{{
-		Op: Update,
+	p.Run(t, snap)
+}
 
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-			assert.NoError(t, err)
+func TestVersionedDefaultProviders(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("2.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
 
-			AssertSameSteps(t, []StepSummary{
-				{Op: deploy.OpSame, URN: provURN},
-				{Op: deploy.OpDeleteReplaced, URN: urnB},
-				{Op: deploy.OpDeleteReplaced, URN: urnA},
-				{Op: deploy.OpReplace, URN: urnA},
-				{Op: deploy.OpCreateReplacement, URN: urnA},
-				{Op: deploy.OpReplace, URN: urnB},
-				{Op: deploy.OpCreateReplacement, URN: urnB},
-			}, j.SuccessfulSteps())
+	badVersion := false
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		// Resources that request no version, or the version that the program requires, share the default provider.
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "", nil, nil, false)
+		if err != nil {
+			return err
+		}
+		_, _, _, err = monitor.RegisterVersionedResource("pkgA:m:typA", "resB", "1.0.0", nil)
+		if err != nil {
+			return err
+		}
 
+		// Resources that request another version get a default provider of their own.
+		_, _, _, err = monitor.RegisterVersionedResource("pkgA:m:typA", "resC", "2.0.0", nil)
+		if err != nil {
 			return err
-		},
-	}}
-	snap = p.Run(t, snap)
+		}
+		_, _, _, err = monitor.RegisterVersionedResource("pkgA:m:typA", "resD", "v2.0.0", nil)
+		if err != nil {
+			return err
+		}
 
-	// Change the value of resB.A. Only resB should be replaced, and the replacement should be create-before-delete.
-	inputsB["A"] = resource.NewStringProperty("qux")
-	p.Steps = []TestStep{{
-		Op: Update,
+		if badVersion {
+			_, _, _, err = monitor.RegisterVersionedResource("pkgA:m:typA", "resE", "not-a-version", nil)
+		}
+		return err
+	}, workspace.PluginInfo{Name: "pkgA", Kind: workspace.ResourcePlugin, Version: &semver.Version{Major: 1}})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-			assert.NoError(t, err)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+	}
+	snap, err := TestOp(Update).Run(p.GetProject(), p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
 
-			AssertSameSteps(t, []StepSummary{
-				{Op: deploy.OpSame, URN: provURN},
-				{Op: deploy.OpSame, URN: urnA},
-				{Op: deploy.OpCreateReplacement, URN: urnB},
-				{Op: deploy.OpReplace, URN: urnB},
-				{Op: deploy.OpDeleteReplaced, URN: urnB},
-			}, j.SuccessfulSteps())
+	providerURN := func(name tokens.QName) string {
+		return string(p.NewURN("pulumi:providers:pkgA", string(name), ""))
+	}
+	v1URN, v2URN := providerURN("default"), providerURN("default_2_0_0")
+	versions := make(map[string]string)
+	for _, res := range snap.Resources {
+		if providers.IsProviderType(res.Type) {
+			versions[string(res.URN)] = res.Inputs["version"].StringValue()
+			continue
+		}
 
-			return err
-		},
-	}}
-	snap = p.Run(t, snap)
+		ref, err := providers.ParseReference(res.Provider)
+		assert.NoError(t, err)
+		switch res.URN.Name() {
+		case "resA", "resB":
+			assert.Equal(t, v1URN, string(ref.URN()))
+		case "resC", "resD":
+			assert.Equal(t, v2URN, string(ref.URN()))
+		}
+	}
+	assert.Equal(t, map[string]string{v1URN: "1.0.0", v2URN: "2.0.0"}, versions)
 
-	// Change the registration of resA such that it no longer requires delete-before-replace and change the value of
-	// resA.A. Only resA should be replaced, and the replacement should be delete-before-replace.
-	dbrA, inputsA["A"] = false, resource.NewStringProperty("zam")
-	p.Steps = []TestStep{{
-		Op: Update,
+	// Versions that cannot be parsed are rejected.
+	badVersion = true
+	_, err = TestOp(Update).Run(p.GetProject(), p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+}
 
-		Validate: func(project workspace.Project, target deploy.Target, j *Journal, evts []Event, err error) error {
-			assert.NoError(t, err)
+func TestReservedDefaultProviderNames(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
 
-			AssertSameSteps(t, []StepSummary{
-				{Op: deploy.OpSame, URN: provURN},
-				{Op: deploy.OpCreateReplacement, URN: urnA},
-				{Op: deploy.OpReplace, URN: urnA},
-				{Op: deploy.OpSame, URN: urnB},
-				{Op: deploy.OpDeleteReplaced, URN: urnA},
-			}, j.SuccessfulSteps())
+	var providerName tokens.QName
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource(providers.MakeProviderType("pkgA"), string(providerName), true, "",
+			false, nil, "", nil, nil, false)
+		return err
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
 
-			return err
-		},
-	}}
-	p.Run(t, snap)
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+	}
+
+	// Explicit providers may be named like default providers as long as the name cannot be generated for one.
+	providerName = "default_east"
+	snap, err := TestOp(Update).Run(p.GetProject(), p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 1)
+	assert.False(t, providers.IsDefaultProvider(snap.Resources[0].URN))
+
+	// Names that may be generated for default providers are reserved.
+	for _, name := range []tokens.QName{"default", "default_1_2_3"} {
+		providerName = name
+		_, err = TestOp(Update).Run(p.GetProject(), p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+		assert.Error(t, err)
+	}
 }
@@ -17,7 +17,9 @@ package engine
 import (
 	"context"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
@@ -101,6 +103,24 @@ type planOptions struct {
 	// true if we should trust the dependency graph reported by the language host. Not all Pulumi-supported languages
 	// correctly report their dependencies, in which case this will be false.
 	trustDependencies bool
+
+	// the policies for retrying steps that fail with transient provider errors.
+	retries deploy.RetryPolicies
+
+	// the maximum number of simultaneous resource operations for each provider package, if limited.
+	concurrency map[string]int
+
+	// the properties whose changes a refresh does not report as drift.
+	suppressDiffs []deploy.DiffSuppression
+
+	// the phases of the project's staged rollout, if any.
+	rolloutPhases []deploy.RolloutPhase
+
+	// the project's plugin lock, if it has one.
+	pluginLock *workspace.PluginLock
+
+	// the calls made to resource providers during the plan.
+	providerMetrics *providerMetrics
 }
 
 // planSourceFunc is a callback that will be used to prepare for, and evaluate, the "new" state for a stack.
@@ -132,8 +152,38 @@ func plan(ctx *Context, info *planContext, opts planOptions, dryRun bool) (*plan
 	if err != nil {
 		return nil, err
 	}
+	if opts.MockProviders != nil {
+		plugctx.Host = plugin.NewMockProviderHost(plugctx.Host, opts.MockProviders)
+	}
+	plugctx.Host = newSupervisedHost(plugctx.Host, proj.ProviderHealth, opts.Diag)
+	plugctx.Host = newCredentialedHost(plugctx.Host, target.Credentials)
+	opts.providerMetrics = newProviderMetrics()
+	plugctx.Host = newMeteredHost(plugctx.Host, opts.providerMetrics, proj.Providers, ctx.Cancel.Canceled())
+	plugctx.Host = newHookedHost(plugctx.Host, proj, target.Name, info.Update.GetRoot(), opts.Diag)
 
 	opts.trustDependencies = proj.TrustResourceDependencies()
+	opts.concurrency = make(map[string]int)
+	for pkg, limit := range proj.Concurrency {
+		opts.concurrency[pkg] = limit
+	}
+	for pkg, settings := range proj.Providers {
+		if settings.Concurrency > 0 {
+			opts.concurrency[pkg] = settings.Concurrency
+		}
+	}
+	opts.suppressDiffs = diffSuppressions(proj.SuppressDiffs, opts.SuppressDiffs)
+	for _, phase := range proj.Rollout {
+		opts.rolloutPhases = append(opts.rolloutPhases, deploy.RolloutPhase{Resources: phase.Resources})
+	}
+	if opts.retries, err = retryPolicies(proj); err != nil {
+		contract.IgnoreClose(plugctx)
+		return nil, err
+	}
+	if opts.pluginLock, err = workspace.LoadPluginLock(projinfo.Root); err != nil {
+		contract.IgnoreClose(plugctx)
+		return nil, err
+	}
+
 	// Now create the state source.  This may issue an error if it can't create the source.  This entails,
 	// for example, loading any plugins which will be required to execute a program, among other things.
 	source, err := opts.SourceFunc(ctx.BackendClient, opts, proj, pwd, main, target, plugctx, dryRun)
@@ -169,6 +219,93 @@ func plan(ctx *Context, info *planContext, opts planOptions, dryRun bool) (*plan
 	}, nil
 }
 
+// retryPolicies computes the retry policies for a plan by layering the project's default retry policy, if any, on
+// top of the engine's defaults, each provider package's retry policy on top of that, and the policies for a package
+// and operation (e.g. "aws:create") on top of their package's. A package's policy may come either from the project's
+// retries or from its entry in the project's providers.
+func retryPolicies(proj *workspace.Project) (deploy.RetryPolicies, error) {
+	policies := deploy.RetryPolicies{Default: deploy.DefaultRetryPolicy}
+
+	packages := make(map[string]workspace.ProjectRetryPolicy)
+	operations := make(map[string]workspace.ProjectRetryPolicy)
+	if proj.Retries != nil {
+		for key, p := range proj.Retries.Providers {
+			if strings.Contains(key, ":") {
+				operations[key] = p
+			} else {
+				packages[key] = p
+			}
+		}
+	}
+	for pkg, settings := range proj.Providers {
+		if settings.Retry != nil {
+			packages[pkg] = *settings.Retry
+		}
+	}
+
+	var err error
+	if proj.Retries != nil && proj.Retries.Default != nil {
+		if policies.Default, err = mergeRetryPolicy(policies.Default, *proj.Retries.Default); err != nil {
+			return deploy.RetryPolicies{}, errors.Wrap(err, "invalid default retry policy")
+		}
+	}
+
+	policies.Overrides = make(map[string]deploy.RetryPolicy)
+	for pkg, p := range packages {
+		if policies.Overrides[pkg], err = mergeRetryPolicy(policies.Default, p); err != nil {
+			return deploy.RetryPolicies{}, errors.Wrapf(err, "invalid retry policy for '%s'", pkg)
+		}
+		if p.Budget > 0 {
+			if policies.Budgets == nil {
+				policies.Budgets = make(map[string]int)
+			}
+			policies.Budgets[pkg] = p.Budget
+		}
+	}
+	for key, p := range operations {
+		base, has := policies.Overrides[strings.SplitN(key, ":", 2)[0]]
+		if !has {
+			base = policies.Default
+		}
+		if policies.Overrides[key], err = mergeRetryPolicy(base, p); err != nil {
+			return deploy.RetryPolicies{}, errors.Wrapf(err, "invalid retry policy for '%s'", key)
+		}
+	}
+	return policies, nil
+}
+
+// diffSuppressions combines the project's and the stack's diff suppressions.
+func diffSuppressions(project, stack []workspace.ProjectDiffSuppression) []deploy.DiffSuppression {
+	var suppressions []deploy.DiffSuppression
+	for _, list := range [][]workspace.ProjectDiffSuppression{project, stack} {
+		for _, s := range list {
+			suppressions = append(suppressions, deploy.DiffSuppression{Type: tokens.Type(s.Type), Path: s.Property})
+		}
+	}
+	return suppressions
+}
+
+// mergeRetryPolicy returns a copy of base with any values set in the given project retry policy applied.
+func mergeRetryPolicy(base deploy.RetryPolicy, p workspace.ProjectRetryPolicy) (deploy.RetryPolicy, error) {
+	if err := p.Validate(); err != nil {
+		return deploy.RetryPolicy{}, err
+	}
+	if p.MaxAttempts != 0 {
+		base.MaxAttempts = p.MaxAttempts
+	}
+	if p.InitialDelay != "" {
+		d, err := time.ParseDuration(p.InitialDelay)
+		contract.AssertNoError(err)
+		base.InitialDelay = d
+	}
+	if p.MaxDelay != "" {
+		d, err := time.ParseDuration(p.MaxDelay)
+		contract.AssertNoError(err)
+		base.MaxDelay = d
+	}
+	return base, nil
+}
+
 type planResult struct {
 	Ctx     *planContext    // plan context information.
 	Plugctx *plugin.Context // the context containing plugins and their state.
@@ -212,6 +349,19 @@ func (planResult *planResult) Walk(cancelCtx *Context, events deploy.Events, pre
 			Refresh:           planResult.Options.Refresh,
 			RefreshOnly:       planResult.Options.isRefresh,
 			TrustDependencies: planResult.Options.trustDependencies,
+			ReplaceTargets:    planResult.Options.ReplaceTargets,
+			FollowPlan:        planResult.Options.FollowPlan,
+			SavePlan:          planResult.Options.SavePlan,
+			ContinueOnError:   planResult.Options.ContinueOnError,
+			DestroyTargets:    planResult.Options.DestroyTargets,
+			Retries:           planResult.Options.retries,
+			Resume:            planResult.Options.Resume,
+			ExcludeTargets:    planResult.Options.ExcludeTargets,
+
+			SuppressDiffs:       planResult.Options.suppressDiffs,
+			ProviderConcurrency: planResult.Options.concurrency,
+			RolloutPhases:       planResult.Options.rolloutPhases,
+			RolloutPhase:        planResult.Options.RolloutPhase,
 		}
 		err = planResult.Plan.Execute(ctx, opts, preview)
 		close(done)
@@ -314,6 +464,10 @@ func (acts *planActions) OnResourceStepPost(ctx interface{},
 		if acts.Opts.isRefresh && op == deploy.OpRefresh {
 			// Refreshes are handled specially.
 			op, record = step.(*deploy.RefreshStep).ResultOp(), true
+		} else if op == deploy.OpRefresh {
+			// When previewing an update that refreshes first, count the resources whose state has drifted, so that
+			// drift shows up in the summary and is caught by `--expect-no-changes`.
+			record = step.(*deploy.RefreshStep).ResultOp() != deploy.OpSame
 		}
 
 		if step.Op() == deploy.OpRead {
@@ -368,6 +522,5 @@ func assertSeen(seen map[resource.URN]deploy.Step, step deploy.Step) {
 }
 
 func isDefaultProviderStep(step deploy.Step) bool {
-	urn := step.URN()
-	return providers.IsProviderType(urn.Type()) && urn.Name() == "default"
+	return providers.IsDefaultProvider(step.URN())
 }
@@ -16,12 +16,19 @@ package engine
 
 import (
 	"context"
+	"sort"
+	"strings"
 
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/workspace"
@@ -83,6 +90,91 @@ func gatherPluginsFromProgram(plugctx *plugin.Context, prog plugin.ProgInfo) (pl
 	return set, nil
 }
 
+// gatherPluginsFromProject returns the set of plugins that the given project lists as required.
+func gatherPluginsFromProject(proj *workspace.Project) (pluginSet, error) {
+	logging.V(preparePluginLog).Infof("gatherPluginsFromProject(): gathering plugins from project")
+	set := newPluginSet()
+	projectPlugins, err := proj.RequiredPlugins()
+	if err != nil {
+		return set, err
+	}
+	for _, plug := range projectPlugins {
+		logging.V(preparePluginLog).Infof(
+			"gatherPluginsFromProject(): plugin %s %s is required by project", plug.Name, plug.Version)
+		set.Add(plug)
+	}
+	return set, nil
+}
+
+// lockPlugins pins the resource plugins in the given set at the versions in the given plugin lock. It fails if one of
+// the plugins is not pinned, or if a specific version of it is required that differs from the pinned version.
+func lockPlugins(lock *workspace.PluginLock, plugins pluginSet) (pluginSet, error) {
+	locked := newPluginSet()
+	for _, plug := range plugins.Values() {
+		if plug.Kind == workspace.ResourcePlugin {
+			version := lock.Version(plug.Kind, plug.Name)
+			switch {
+			case version == nil:
+				return nil, errors.Errorf("the resource plugin %s is not pinned in %s; "+
+					"run `pulumi plugin lock` to pin it", plug.Name, workspace.PluginLockFile)
+			case plug.Version != nil && !plug.Version.EQ(*version):
+				return nil, errors.Errorf("the resource plugin %s is required, but %s pins version %s; "+
+					"run `pulumi plugin lock` to update it", plug, workspace.PluginLockFile, version)
+			}
+			logging.V(preparePluginLog).Infof("lockPlugins(): plugin %s is pinned at %s", plug.Name, version)
+			plug.Version = version
+		}
+		locked.Add(plug)
+	}
+	return locked, nil
+}
+
+// verifyLockedPlugins checks that each of the resource plugins in the given set, whose versions are pinned, will be
+// loaded from the plugin cache at exactly its pinned version, rather than at another version or from the $PATH.
+// Plugins that are being debugged or that are linked to local builds are exempt, as they are used deliberately in
+// place of any version; a warning is issued for each linked plugin.
+func verifyLockedPlugins(d diag.Sink, plugins pluginSet) error {
+	links, err := workspace.GetPluginLinks()
+	if err != nil {
+		return err
+	}
+	return verifyLockedPluginsWithLinks(d, plugins, links)
+}
+
+// verifyLockedPluginsWithLinks is verifyLockedPlugins given the plugins that are linked to local builds.
+func verifyLockedPluginsWithLinks(d diag.Sink, plugins pluginSet, links []workspace.PluginLink) error {
+	debugPorts, err := plugin.GetDebugProviders()
+	if err != nil {
+		return err
+	}
+	for _, plug := range plugins.Values() {
+		if plug.Kind != workspace.ResourcePlugin || isDebugProvider(plug, debugPorts) {
+			continue
+		}
+		if link, linked := findPluginLink(plug, links); linked {
+			d.Warningf(diag.Message("" /*urn*/, "the resource plugin %s is pinned in %s, but is linked to %s, "+
+				"which will be loaded instead"), plug, workspace.PluginLockFile, link.Path)
+			continue
+		}
+		dir, path, err := workspace.GetPluginPath(plug.Kind, plug.Name, plug.Version)
+		if err != nil {
+			return err
+		}
+		expected, err := plug.DirPath()
+		if err != nil {
+			return err
+		}
+		switch {
+		case path == "":
+			return workspace.NewMissingError(plug)
+		case dir != expected:
+			return errors.Errorf("the resource plugin %s is pinned in %s, but %s would be loaded instead",
+				plug, workspace.PluginLockFile, path)
+		}
+	}
+	return nil
+}
+
 // gatherPluginsFromSnapshot inspects the snapshot associated with the given Target and returns the set of plugins
 // required to operate on the snapshot. The set of plugins is derived from first-class providers saved in the snapshot
 // and the plugins specified in the deployment manifest.
@@ -121,16 +213,25 @@ func gatherPluginsFromSnapshot(plugctx *plugin.Context, target *deploy.Target) (
 }
 
 // ensurePluginsAreInstalled inspects all plugins in the plugin set and, if any plugins are not currently installed,
-// uses the given backend client to install them. Installations are processed in parallel, though
-// ensurePluginsAreInstalled does not return until all installations are completed.
-func ensurePluginsAreInstalled(client deploy.BackendClient, plugins pluginSet) error {
+// uses the given backend client to install them, reporting each download to the given sink. Installations are processed
+// in parallel, though ensurePluginsAreInstalled does not return until all installations are completed.
+func ensurePluginsAreInstalled(client deploy.BackendClient, d diag.Sink, plugins pluginSet) error {
 	if client == nil {
 		logging.V(preparePluginLog).Infoln("ensurePluginsAreInstalled(): skipping due to nil client")
 		return nil
 	}
 	logging.V(preparePluginLog).Infof("ensurePluginsAreInstalled(): beginning")
+	debugPorts, err := plugin.GetDebugProviders()
+	if err != nil {
+		return err
+	}
 	var installTasks errgroup.Group
 	for _, plug := range plugins.Values() {
+		if isDebugProvider(plug, debugPorts) {
+			logging.V(preparePluginLog).Infof(
+				"ensurePluginsAreInstalled(): plugin %s %s is being debugged", plug.Name, plug.Version)
+			continue
+		}
 		_, path, err := workspace.GetPluginPath(plug.Kind, plug.Name, plug.Version)
 		if err == nil && path != "" {
 			logging.V(preparePluginLog).Infof(
@@ -143,23 +244,119 @@ func ensurePluginsAreInstalled(client deploy.BackendClient, plugins pluginSet) e
 		installTasks.Go(func() error {
 			logging.V(preparePluginLog).Infof(
 				"ensurePluginsAreInstalled(): plugin %s %s not installed, doing install", info.Name, info.Version)
-			return installPlugin(client, info)
+			return installPlugin(client, d, info)
 		})
 	}
 
-	err := installTasks.Wait()
+	err = installTasks.Wait()
 	logging.V(preparePluginLog).Infof("ensurePluginsAreInstalled(): completed")
 	return err
 }
 
+// isDebugProvider returns true if the given plugin is a resource plugin that the engine attaches to rather than
+// launches (see plugin.DebugProvidersEnvVar), given the ports on which such plugins are listening, and so need not be
+// installed.
+func isDebugProvider(plug workspace.PluginInfo, debugPorts map[tokens.Package]int) bool {
+	_, has := debugPorts[tokens.Package(plug.Name)]
+	return has && plug.Kind == workspace.ResourcePlugin
+}
+
+// findPluginLink returns the link, among the given links, of the given plugin to a local build, if it has one.
+func findPluginLink(plug workspace.PluginInfo, links []workspace.PluginLink) (workspace.PluginLink, bool) {
+	for _, link := range links {
+		if link.Kind == plug.Kind && link.Name == plug.Name {
+			return link, true
+		}
+	}
+	return workspace.PluginLink{}, false
+}
+
+// withoutMockedPlugins returns the plugins in the given set that the plan will load, leaving out resource plugins if
+// mock providers stand in for them.
+func withoutMockedPlugins(opts planOptions, plugins pluginSet) pluginSet {
+	if opts.MockProviders == nil {
+		return plugins
+	}
+	result := newPluginSet()
+	for _, plug := range plugins.Values() {
+		if plug.Kind != workspace.ResourcePlugin {
+			result.Add(plug)
+		}
+	}
+	return result
+}
+
 // ensurePluginsAreLoaded ensures that all of the plugins in the given plugin set that match the given plugin flags are
 // loaded.
 func ensurePluginsAreLoaded(plugctx *plugin.Context, plugins pluginSet, kinds plugin.Flags) error {
 	return plugctx.Host.EnsurePlugins(plugins.Values(), kinds)
 }
 
+// checkProviderConfig checks the stack's configuration for the default provider of each of the given packages, and
+// configures a provider with it, before any steps run. Otherwise, invalid configuration or missing credentials would
+// only be reported once the first resource of the package is registered, which may be well into an update. Packages
+// that the stack does not configure are skipped, since a program may use only explicit providers for them.
+func checkProviderConfig(plugctx *plugin.Context, target *deploy.Target,
+	versions map[tokens.Package]*semver.Version) error {
+
+	var pkgs []string
+	for pkg := range versions {
+		pkgs = append(pkgs, string(pkg))
+	}
+	sort.Strings(pkgs)
+
+	for _, name := range pkgs {
+		pkg := tokens.Package(name)
+		cfg, err := target.GetPackageConfig(pkg)
+		if err != nil {
+			return err
+		}
+		if len(cfg) == 0 {
+			continue
+		}
+
+		// Check the same inputs that the default provider will be given.
+		inputs := make(resource.PropertyMap)
+		for k, v := range cfg {
+			inputs[resource.PropertyKey(k.Name())] = resource.NewStringProperty(v)
+		}
+		if version := versions[pkg]; version != nil {
+			inputs["version"] = resource.NewStringProperty(version.String())
+		}
+
+		logging.V(preparePluginLog).Infof("checkProviderConfig(): checking the configuration of %s", pkg)
+		provider, err := plugctx.Host.Provider(pkg, versions[pkg])
+		if err != nil {
+			return err
+		} else if provider == nil {
+			continue
+		}
+		checked, failures, err := provider.CheckConfig(nil, inputs)
+		if err == nil && len(failures) == 0 {
+			err = provider.Configure(checked)
+		}
+		contract.IgnoreError(plugctx.Host.CloseProvider(provider))
+
+		if len(failures) != 0 {
+			var reasons []string
+			for _, failure := range failures {
+				reason := failure.Reason
+				if failure.Property != "" {
+					reason = string(pkg) + ":" + string(failure.Property) + ": " + reason
+				}
+				reasons = append(reasons, reason)
+			}
+			return errors.Errorf("the stack's configuration for the %s provider is invalid:\n    %s",
+				pkg, strings.Join(reasons, "\n    "))
+		} else if err != nil {
+			return errors.Wrapf(err, "could not configure the %s provider with the stack's configuration", pkg)
+		}
+	}
+	return nil
+}
+
 // installPlugin installs a plugin from the given backend client.
-func installPlugin(client deploy.BackendClient, plugin workspace.PluginInfo) error {
+func installPlugin(client deploy.BackendClient, d diag.Sink, plugin workspace.PluginInfo) error {
 	contract.Assert(client != nil)
 	logging.V(preparePluginLog).Infof("installPlugin(%s, %s): beginning install", plugin.Name, plugin.Version)
 	if plugin.Kind == workspace.LanguagePlugin {
@@ -170,6 +367,7 @@ func installPlugin(client deploy.BackendClient, plugin workspace.PluginInfo) err
 
 	logging.V(preparePluginVerboseLog).Infof(
 		"installPlugin(%s, %s): initiating download", plugin.Name, plugin.Version)
+	d.Infoerrf(diag.Message("" /*urn*/, "downloading the %s plugin %s"), plugin.Kind, plugin.String())
 	stream, err := client.DownloadPlugin(context.TODO(), plugin)
 	if err != nil {
 		return err
@@ -181,5 +379,6 @@ func installPlugin(client deploy.BackendClient, plugin workspace.PluginInfo) err
 	}
 
 	logging.V(7).Infof("installPlugin(%s, %s): successfully installed", plugin.Name, plugin.Version)
+	d.Infoerrf(diag.Message("" /*urn*/, "installed the %s plugin %s"), plugin.Kind, plugin.String())
 	return nil
 }
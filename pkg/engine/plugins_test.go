@@ -0,0 +1,84 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+func TestLockPlugins(t *testing.T) {
+	plugin := func(kind workspace.PluginKind, name, version string) workspace.PluginInfo {
+		info := workspace.PluginInfo{Kind: kind, Name: name}
+		if version != "" {
+			v := semver.MustParse(version)
+			info.Version = &v
+		}
+		return info
+	}
+	set := func(plugins ...workspace.PluginInfo) pluginSet {
+		result := newPluginSet()
+		for _, p := range plugins {
+			result.Add(p)
+		}
+		return result
+	}
+
+	lock := &workspace.PluginLock{}
+	lock.Pin(workspace.ResourcePlugin, "aws", semver.MustParse("0.17.1"))
+	lock.Pin(workspace.ResourcePlugin, "random", semver.MustParse("0.2.0"))
+
+	// Resource plugins are pinned at their locked versions; other plugins are left alone.
+	locked, err := lockPlugins(lock, set(
+		plugin(workspace.ResourcePlugin, "aws", "0.17.1"),
+		plugin(workspace.ResourcePlugin, "random", ""),
+		plugin(workspace.LanguagePlugin, "nodejs", "")))
+	assert.NoError(t, err)
+	assert.Equal(t, set(
+		plugin(workspace.ResourcePlugin, "aws", "0.17.1"),
+		plugin(workspace.ResourcePlugin, "random", "0.2.0"),
+		plugin(workspace.LanguagePlugin, "nodejs", "")), locked)
+
+	// Plugins that are required at other versions, or that are not pinned, are rejected.
+	_, err = lockPlugins(lock, set(plugin(workspace.ResourcePlugin, "aws", "0.17.2")))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "pins version 0.17.1")
+	_, err = lockPlugins(lock, set(plugin(workspace.ResourcePlugin, "gcp", "0.16.0")))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is not pinned")
+}
+
+func TestVerifyLockedPluginsLinked(t *testing.T) {
+	version := semver.MustParse("0.17.1")
+	plugins := newPluginSet()
+	plugins.Add(workspace.PluginInfo{Kind: workspace.ResourcePlugin, Name: "pulumi-test-linked", Version: &version})
+
+	// A pinned plugin that is linked to a local build is used regardless of its version, with a warning...
+	var stderr bytes.Buffer
+	sink := diag.DefaultSink(&stderr, &stderr, diag.FormatOptions{Color: colors.Never})
+	links := []workspace.PluginLink{{Kind: workspace.ResourcePlugin, Name: "pulumi-test-linked", Path: "/src/linked"}}
+	assert.NoError(t, verifyLockedPluginsWithLinks(sink, plugins, links))
+	assert.Contains(t, stderr.String(), "is linked to /src/linked")
+
+	// ...while one that is neither linked nor installed is reported as missing.
+	assert.Error(t, verifyLockedPluginsWithLinks(sink, plugins, nil))
+}
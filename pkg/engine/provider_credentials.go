@@ -0,0 +1,74 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/blang/semver"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// newCredentialedHost returns a host that loads plugins from the given host, and that configures the resource
+// providers it loads with the given per-package credentials. The credentials are added only when providers are
+// configured, so that they are never recorded in a provider's inputs, and so never in a checkpoint.
+func newCredentialedHost(host plugin.Host, credentials map[tokens.Package]map[string]string) plugin.Host {
+	if len(credentials) == 0 {
+		return host
+	}
+	return &credentialedHost{Host: host, credentials: credentials}
+}
+
+type credentialedHost struct {
+	plugin.Host
+	credentials map[tokens.Package]map[string]string
+}
+
+func (host *credentialedHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	prov, err := host.Host.Provider(pkg, version)
+	if prov == nil || err != nil {
+		return prov, err
+	}
+	creds, has := host.credentials[pkg]
+	if !has {
+		return prov, nil
+	}
+	return &credentialedProvider{Provider: prov, credentials: creds}, nil
+}
+
+func (host *credentialedHost) CloseProvider(provider plugin.Provider) error {
+	if credentialed, ok := provider.(*credentialedProvider); ok {
+		provider = credentialed.Provider
+	}
+	return host.Host.CloseProvider(provider)
+}
+
+// credentialedProvider adds credentials to the configuration of a provider.
+type credentialedProvider struct {
+	plugin.Provider
+	credentials map[string]string
+}
+
+// Configure configures the provider with the given inputs, plus any credentials whose settings the inputs lack.
+func (p *credentialedProvider) Configure(inputs resource.PropertyMap) error {
+	configured := inputs.Copy()
+	for k, v := range p.credentials {
+		if key := resource.PropertyKey(k); !configured.HasValue(key) {
+			configured[key] = resource.NewStringProperty(v)
+		}
+	}
+	return p.Provider.Configure(configured)
+}
@@ -0,0 +1,390 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil/rpcerror"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// providerHealthTimeout is how long a provider has to answer a health check before it is presumed hung.
+var providerHealthTimeout = 10 * time.Second
+
+// newSupervisedHost returns a host that loads plugins from the given host, and that restarts the resource providers
+// it loads if they crash or hang, according to the given settings.
+func newSupervisedHost(host plugin.Host, health *workspace.ProjectProviderHealth, d diag.Sink) plugin.Host {
+	if health.Restarts() == 0 && health.Timeout() == 0 {
+		return host
+	}
+	return &supervisedHost{Host: host, callTimeout: health.Timeout(), maxRestarts: health.Restarts(), diag: d}
+}
+
+type supervisedHost struct {
+	plugin.Host
+	callTimeout time.Duration // the longest that a repeatable call may take, or zero if there is no limit.
+	maxRestarts int           // the number of times that each provider may be restarted.
+	diag        diag.Sink
+}
+
+func (host *supervisedHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	prov, err := host.Host.Provider(pkg, version)
+	if prov == nil || err != nil {
+		return prov, err
+	}
+	return &supervisedProvider{host: host, pkg: pkg, version: version, current: prov}, nil
+}
+
+func (host *supervisedHost) CloseProvider(provider plugin.Provider) error {
+	if supervised, ok := provider.(*supervisedProvider); ok {
+		provider, _ = supervised.provider()
+	}
+	return host.Host.CloseProvider(provider)
+}
+
+// supervisedProvider restarts a provider that has crashed or hung. If a call fails because the provider is no longer
+// responding, the provider is restarted and reconfigured, and the call is replayed if it is safe to repeat. Creates,
+// updates, and deletes are never replayed, since they may have taken effect before the provider failed; instead, the
+// status of their resource is reported as unknown.
+type supervisedProvider struct {
+	host    *supervisedHost
+	pkg     tokens.Package
+	version *semver.Version
+
+	lock       sync.Mutex
+	current    plugin.Provider      // the running provider.
+	generation int                  // the number of times that the provider has been restarted.
+	config     resource.PropertyMap // the provider's configuration, if it has been configured.
+	configured bool                 // true if the provider has been configured.
+}
+
+// provider returns the running provider and its generation.
+func (p *supervisedProvider) provider() (plugin.Provider, int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.current, p.generation
+}
+
+// call calls the given method of the running provider, which must be safe to repeat, using the given function. If the
+// provider has stopped responding, it is restarted, and the call is replayed. Calls that take longer than the call
+// timeout are presumed to have hung.
+func (p *supervisedProvider) call(method string,
+	f func(prov plugin.Provider) (interface{}, error)) (interface{}, error) {
+
+	for {
+		prov, generation := p.provider()
+		var result interface{}
+		var err error
+		if p.host.callTimeout > 0 {
+			result, err = p.callWithTimeout(method, prov, f)
+		} else {
+			result, err = f(prov)
+		}
+		if !isProviderUnresponsive(err) || p.healthy(prov) {
+			return result, err
+		}
+
+		if restartErr := p.restart(generation, method, err); restartErr != nil {
+			return nil, restartErr
+		}
+		logging.V(7).Infof("replaying %s call to restarted %s provider", method, p.pkg)
+	}
+}
+
+// mutate calls the given method of the running provider, which creates, updates, or deletes a resource, using the
+// given function. If the provider stops responding during the call, it is restarted, but the call is not replayed, and
+// the status of the resource is reported as unknown, since the call may have taken effect before the provider failed.
+func (p *supervisedProvider) mutate(method string,
+	f func(prov plugin.Provider) (resource.Status, error)) (resource.Status, error) {
+
+	prov, generation := p.provider()
+	status, err := f(prov)
+	if !isProviderUnresponsive(err) || p.healthy(prov) {
+		return status, err
+	}
+
+	if restartErr := p.restart(generation, method, err); restartErr != nil {
+		return resource.StatusUnknown, restartErr
+	}
+	return resource.StatusUnknown, err
+}
+
+// callWithTimeout calls the given function, giving up if it does not return within the call timeout. The function is
+// given a view of the provider whose requests are canceled when the call is abandoned.
+func (p *supervisedProvider) callWithTimeout(method string, prov plugin.Provider,
+	f func(prov plugin.Provider) (interface{}, error)) (interface{}, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.host.callTimeout)
+	defer cancel()
+
+	type callResult struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		result, err := f(plugin.WithContext(ctx, prov))
+		done <- callResult{result: result, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.result, r.err
+	case <-ctx.Done():
+		return nil, rpcerror.Newf(codes.DeadlineExceeded, "%s call to the %s provider did not complete within %v",
+			method, p.pkg, p.host.callTimeout)
+	}
+}
+
+// isProviderUnresponsive returns true if the given error means that a provider may have crashed or hung.
+func isProviderUnresponsive(err error) bool {
+	if err == nil {
+		return false
+	}
+	rpcErr, ok := rpcerror.FromError(errors.Cause(err))
+	if !ok {
+		return false
+	}
+	return rpcErr.Code() == codes.Unavailable || rpcErr.Code() == codes.DeadlineExceeded
+}
+
+// healthy returns true if the given provider answers a health check promptly. A provider that answers is still
+// running, so the error that prompted the check came from the provider itself rather than from its failure.
+func (p *supervisedProvider) healthy(prov plugin.Provider) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), providerHealthTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := plugin.WithContext(ctx, prov).GetPluginInfo()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err == nil
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// restart replaces the provider of the given generation, which has stopped responding during a call to the given
+// method with the given error, with a new provider that has the same configuration. If the provider has already been
+// replaced, restart does nothing.
+func (p *supervisedProvider) restart(generation int, method string, cause error) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.generation != generation {
+		return nil
+	}
+	if p.generation >= p.host.maxRestarts {
+		return errutil.WithCode(errutil.ErrProviderUnresponsive,
+			"re-run the command with `-vvv --log-file=FILE` to capture the provider's own logs",
+			errors.Wrapf(cause, "the %s provider stopped responding and has already been restarted %d times",
+				p.pkg, p.generation))
+	}
+
+	p.host.diag.Warningf(diag.RawMessage("", fmt.Sprintf(
+		"the %s provider stopped responding during a %s call (%v); restarting it", p.pkg, method, cause)))
+	contract.IgnoreError(p.host.Host.CloseProvider(p.current))
+
+	prov, err := p.host.Host.Provider(p.pkg, p.version)
+	if err != nil {
+		return errors.Wrapf(err, "restarting the %s provider", p.pkg)
+	}
+	if p.configured {
+		if err = prov.Configure(p.config); err != nil {
+			contract.IgnoreError(p.host.Host.CloseProvider(prov))
+			return errors.Wrapf(err, "configuring the restarted %s provider", p.pkg)
+		}
+	}
+	p.current = prov
+	p.generation++
+	return nil
+}
+
+// checkResult holds the results of calls that return properties and check failures.
+type checkResult struct {
+	props    resource.PropertyMap
+	failures []plugin.CheckFailure
+}
+
+// readResult holds the results of a call to Read.
+type readResult struct {
+	result plugin.ReadResult
+	status resource.Status
+}
+
+func (p *supervisedProvider) Close() error {
+	prov, _ := p.provider()
+	return prov.Close()
+}
+
+func (p *supervisedProvider) Pkg() tokens.Package {
+	return p.pkg
+}
+
+func (p *supervisedProvider) CheckConfig(olds,
+	news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+
+	res, err := p.call("CheckConfig", func(prov plugin.Provider) (interface{}, error) {
+		props, failures, err := prov.CheckConfig(olds, news)
+		return checkResult{props: props, failures: failures}, err
+	})
+	r, _ := res.(checkResult)
+	return r.props, r.failures, err
+}
+
+func (p *supervisedProvider) DiffConfig(olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+	res, err := p.call("DiffConfig", func(prov plugin.Provider) (interface{}, error) {
+		return prov.DiffConfig(olds, news)
+	})
+	r, _ := res.(plugin.DiffResult)
+	return r, err
+}
+
+func (p *supervisedProvider) Configure(inputs resource.PropertyMap) error {
+	_, err := p.call("Configure", func(prov plugin.Provider) (interface{}, error) {
+		return nil, prov.Configure(inputs)
+	})
+	if err == nil {
+		p.lock.Lock()
+		p.config, p.configured = inputs, true
+		p.lock.Unlock()
+	}
+	return err
+}
+
+func (p *supervisedProvider) Check(urn resource.URN, olds, news resource.PropertyMap,
+	allowUnknowns bool) (resource.PropertyMap, []plugin.CheckFailure, error) {
+
+	res, err := p.call("Check", func(prov plugin.Provider) (interface{}, error) {
+		props, failures, err := prov.Check(urn, olds, news, allowUnknowns)
+		return checkResult{props: props, failures: failures}, err
+	})
+	r, _ := res.(checkResult)
+	return r.props, r.failures, err
+}
+
+func (p *supervisedProvider) Diff(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+	news resource.PropertyMap, allowUnknowns bool) (plugin.DiffResult, error) {
+
+	res, err := p.call("Diff", func(prov plugin.Provider) (interface{}, error) {
+		return prov.Diff(urn, id, olds, news, allowUnknowns)
+	})
+	r, _ := res.(plugin.DiffResult)
+	return r, err
+}
+
+func (p *supervisedProvider) Create(urn resource.URN, news resource.PropertyMap,
+	timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+	var id resource.ID
+	var outs resource.PropertyMap
+	status, err := p.mutate("Create", func(prov plugin.Provider) (resource.Status, error) {
+		var status resource.Status
+		var err error
+		id, outs, status, err = prov.Create(urn, news, timeout)
+		return status, err
+	})
+	return id, outs, status, err
+}
+
+func (p *supervisedProvider) Read(urn resource.URN, id resource.ID,
+	inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+	res, err := p.call("Read", func(prov plugin.Provider) (interface{}, error) {
+		result, status, err := prov.Read(urn, id, inputs, state)
+		return readResult{result: result, status: status}, err
+	})
+	r, _ := res.(readResult)
+	return r.result, r.status, err
+}
+
+func (p *supervisedProvider) Update(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+	news resource.PropertyMap, timeout float64) (resource.PropertyMap, resource.Status, error) {
+
+	var outs resource.PropertyMap
+	status, err := p.mutate("Update", func(prov plugin.Provider) (resource.Status, error) {
+		var status resource.Status
+		var err error
+		outs, status, err = prov.Update(urn, id, olds, news, timeout)
+		return status, err
+	})
+	return outs, status, err
+}
+
+func (p *supervisedProvider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
+
+	return p.mutate("Delete", func(prov plugin.Provider) (resource.Status, error) {
+		return prov.Delete(urn, id, props, timeout)
+	})
+}
+
+func (p *supervisedProvider) Invoke(tok tokens.ModuleMember,
+	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+
+	res, err := p.call("Invoke", func(prov plugin.Provider) (interface{}, error) {
+		props, failures, err := prov.Invoke(tok, args)
+		return checkResult{props: props, failures: failures}, err
+	})
+	r, _ := res.(checkResult)
+	return r.props, r.failures, err
+}
+
+func (p *supervisedProvider) GetPluginInfo() (workspace.PluginInfo, error) {
+	prov, _ := p.provider()
+	return prov.GetPluginInfo()
+}
+
+func (p *supervisedProvider) GetSchema(version int) ([]byte, error) {
+	res, err := p.call("GetSchema", func(prov plugin.Provider) (interface{}, error) {
+		return prov.GetSchema(version)
+	})
+	r, _ := res.([]byte)
+	return r, err
+}
+
+func (p *supervisedProvider) GetLogs(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	startTime, endTime *time.Time) ([]plugin.LogEntry, error) {
+
+	res, err := p.call("GetLogs", func(prov plugin.Provider) (interface{}, error) {
+		return prov.GetLogs(urn, id, props, startTime, endTime)
+	})
+	r, _ := res.([]plugin.LogEntry)
+	return r, err
+}
+
+func (p *supervisedProvider) SignalCancellation() error {
+	prov, _ := p.provider()
+	return prov.SignalCancellation()
+}
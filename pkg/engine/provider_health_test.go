@@ -0,0 +1,200 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/diag/colors"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil/rpcerror"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// crashingProviderLoader returns a loader for providers that crash during their first call to Check or Create, as
+// selected by crashOn. It returns the configurations that the providers it loads are given, in the order they are
+// loaded.
+func crashingProviderLoader(crashOn string) (*deploytest.ProviderLoader, func() []resource.PropertyMap) {
+	var lock sync.Mutex
+	var configs []resource.PropertyMap
+	crashed := false
+
+	loader := deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+		dead := false
+		crash := func(method string) error {
+			lock.Lock()
+			defer lock.Unlock()
+			if method == crashOn && !crashed {
+				crashed, dead = true, true
+			}
+			if dead {
+				return rpcerror.New(codes.Unavailable, "transport is closing")
+			}
+			return nil
+		}
+		return &deploytest.Provider{
+			ConfigureF: func(news resource.PropertyMap) error {
+				lock.Lock()
+				defer lock.Unlock()
+				configs = append(configs, news)
+				return nil
+			},
+			CheckF: func(urn resource.URN, olds, news resource.PropertyMap) (resource.PropertyMap,
+				[]plugin.CheckFailure, error) {
+				return news, nil, crash("Check")
+			},
+			CreateF: func(urn resource.URN, news resource.PropertyMap,
+				timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+				if err := crash("Create"); err != nil {
+					return "", nil, resource.StatusOK, err
+				}
+				return "id", resource.PropertyMap{}, resource.StatusOK, nil
+			},
+			GetPluginInfoF: func() (workspace.PluginInfo, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				if dead {
+					return workspace.PluginInfo{}, rpcerror.New(codes.Unavailable, "transport is closing")
+				}
+				return workspace.PluginInfo{Name: "pkgA"}, nil
+			},
+		}, nil
+	})
+	return loader, func() []resource.PropertyMap {
+		lock.Lock()
+		defer lock.Unlock()
+		return configs
+	}
+}
+
+func newCrashTestPlan(loader *deploytest.ProviderLoader) *TestPlan {
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+			resource.PropertyMap{}, nil, false)
+		return err
+	})
+	return &TestPlan{
+		Options: UpdateOptions{host: deploytest.NewPluginHost(nil, nil, program, loader)},
+		Config: config.Map{
+			config.MustMakeKey("pkgA", "region"): config.NewValue("us-west-2"),
+		},
+	}
+}
+
+// restartOnce returns provider health settings that allow each provider to be restarted once.
+func restartOnce() *workspace.ProjectProviderHealth {
+	restarts := 1
+	return &workspace.ProjectProviderHealth{MaxRestarts: &restarts}
+}
+
+func TestProviderRestartReplaysRepeatableCalls(t *testing.T) {
+	loader, configs := crashingProviderLoader("Check")
+	p := newCrashTestPlan(loader)
+
+	// The provider crashes while checking the resource, and is restarted with the same configuration. The check is
+	// replayed against the restarted provider, so the update succeeds.
+	project := p.GetProject()
+	project.ProviderHealth = restartOnce()
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 2)
+	if assert.Len(t, configs(), 2) {
+		assert.Equal(t, configs()[0], configs()[1])
+		assert.Equal(t, "us-west-2", configs()[1]["region"].StringValue())
+	}
+}
+
+func TestProviderRestartDoesNotReplayCreates(t *testing.T) {
+	loader, configs := crashingProviderLoader("Create")
+	p := newCrashTestPlan(loader)
+
+	// The provider crashes while creating the resource. It is restarted, but the create may have taken effect, so it
+	// is not replayed, even though the project's retry policy allows retries.
+	project := p.GetProject()
+	project.ProviderHealth = restartOnce()
+	project.Retries = &workspace.ProjectRetries{Default: &workspace.ProjectRetryPolicy{MaxAttempts: 3}}
+	_, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+	assert.Len(t, configs(), 2)
+}
+
+func TestProviderRestartsDisabled(t *testing.T) {
+	loader, configs := crashingProviderLoader("Check")
+	p := newCrashTestPlan(loader)
+
+	// Restarts must be opted into.
+	project := p.GetProject()
+	project.ProviderHealth = nil
+	_, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+	assert.Len(t, configs(), 1)
+}
+
+func TestProviderCallTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	prov := &deploytest.Provider{
+		CheckF: func(urn resource.URN, olds, news resource.PropertyMap) (resource.PropertyMap,
+			[]plugin.CheckFailure, error) {
+			<-block
+			return news, nil, nil
+		},
+		GetPluginInfoF: func() (workspace.PluginInfo, error) {
+			<-block
+			return workspace.PluginInfo{}, nil
+		},
+	}
+	host := &supervisedHost{callTimeout: time.Millisecond, maxRestarts: 0}
+	supervised := &supervisedProvider{host: host, pkg: "pkgA", current: prov}
+
+	// A hung check times out, and the health check that follows it times out too, so the provider is presumed hung.
+	// It may not be restarted, so the call fails.
+	defer func(timeout time.Duration) { providerHealthTimeout = timeout }(providerHealthTimeout)
+	providerHealthTimeout = time.Millisecond
+	_, _, err := supervised.Check("urn:pulumi:test::test::pkgA:m:typA::resA", nil, resource.PropertyMap{}, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "did not complete within 1ms")
+}
+
+func TestProviderCrashDuringCreate(t *testing.T) {
+	loader, _ := crashingProviderLoader("Create")
+	host := deploytest.NewPluginHost(nil, nil, nil, loader)
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	supervised := newSupervisedHost(host, restartOnce(), sink)
+	prov, err := supervised.Provider("pkgA", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, prov.Configure(resource.PropertyMap{}))
+
+	// The provider crashes while creating the resource, so whether the resource was created is unknown.
+	_, _, status, err := prov.Create("urn:pulumi:test::test::pkgA:m:typA::resA", resource.PropertyMap{}, 0)
+	assert.Error(t, err)
+	assert.Equal(t, resource.StatusUnknown, status)
+
+	// The restarted provider works.
+	_, _, status, err = prov.Create("urn:pulumi:test::test::pkgA:m:typA::resA", resource.PropertyMap{}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.StatusOK, status)
+}
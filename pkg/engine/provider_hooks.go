@@ -0,0 +1,220 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// providerHookEvent is the JSON document that a provider hook reads from stdin. While we can add fields to this
+// structure in the future, we should not change existing fields.
+type providerHookEvent struct {
+	Hook      string                 `json:"hook"`              // "before" or "after" the operation.
+	Operation string                 `json:"operation"`         // "create", "update", or "delete".
+	Project   string                 `json:"project"`           // the project being deployed.
+	Stack     string                 `json:"stack"`             // the stack being deployed.
+	URN       resource.URN           `json:"urn"`               // the resource's URN.
+	Type      tokens.Type            `json:"type"`              // the resource's type.
+	ID        resource.ID            `json:"id,omitempty"`      // the resource's ID, once it has one.
+	Olds      map[string]interface{} `json:"olds,omitempty"`    // the resource's old state, for updates and deletes.
+	News      map[string]interface{} `json:"news,omitempty"`    // the resource's new inputs, for creates and updates.
+	Outputs   map[string]interface{} `json:"outputs,omitempty"` // the resource's new state, after an operation.
+	Error     string                 `json:"error,omitempty"`   // the operation's error, after it fails.
+}
+
+// providerHookResponse is the JSON document that a provider hook may write to stdout. Writing nothing allows the
+// operation without comment.
+type providerHookResponse struct {
+	Veto     string   `json:"veto,omitempty"`     // if set, the reason that the operation must not proceed.
+	Messages []string `json:"messages,omitempty"` // messages to report against the resource.
+}
+
+// newHookedHost returns a host that loads plugins from the given host, and that runs the given project's provider
+// hooks, from the given directory, around each create, update, and delete made by the resource providers it loads.
+func newHookedHost(host plugin.Host, proj *workspace.Project, stack tokens.QName, root string,
+	d diag.Sink) plugin.Host {
+
+	if len(proj.ProviderHooks) == 0 {
+		return host
+	}
+	return &hookedHost{Host: host, hooks: &providerHooks{
+		hooks:   proj.ProviderHooks,
+		project: string(proj.Name),
+		stack:   string(stack),
+		root:    root,
+		diag:    d,
+	}}
+}
+
+type hookedHost struct {
+	plugin.Host
+	hooks *providerHooks
+}
+
+func (host *hookedHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	prov, err := host.Host.Provider(pkg, version)
+	if prov == nil || err != nil {
+		return prov, err
+	}
+	return &hookedProvider{Provider: prov, hooks: host.hooks}, nil
+}
+
+func (host *hookedHost) CloseProvider(provider plugin.Provider) error {
+	if hooked, ok := provider.(*hookedProvider); ok {
+		provider = hooked.Provider
+	}
+	return host.Host.CloseProvider(provider)
+}
+
+// providerHooks runs a project's provider hooks.
+type providerHooks struct {
+	hooks   []workspace.ProjectProviderHook
+	project string
+	stack   string
+	root    string
+	diag    diag.Sink
+}
+
+// before runs the hooks for the given operation before it is performed. It returns an error if a hook vetoes the
+// operation or fails to run, in which case the operation must not proceed.
+func (h *providerHooks) before(event providerHookEvent) error {
+	event.Hook = "before"
+	for _, hook := range h.hooks {
+		if !hook.Runs(event.Operation) {
+			continue
+		}
+		resp, err := h.run(hook, event)
+		if err != nil {
+			return err
+		}
+		if resp.Veto != "" {
+			return errors.Errorf("%s of %s was vetoed by provider hook '%s': %s",
+				event.Operation, event.URN, hook.Command, resp.Veto)
+		}
+	}
+	return nil
+}
+
+// after runs the hooks for the given operation after it has been performed. Because the operation can no longer be
+// vetoed, hooks that fail to run are reported as warnings.
+func (h *providerHooks) after(event providerHookEvent, opErr error) {
+	event.Hook = "after"
+	if opErr != nil {
+		event.Error = opErr.Error()
+	}
+	for _, hook := range h.hooks {
+		if !hook.Runs(event.Operation) {
+			continue
+		}
+		if _, err := h.run(hook, event); err != nil {
+			h.diag.Warningf(diag.RawMessage(event.URN, err.Error()))
+		}
+	}
+}
+
+// run runs a single hook for the given event, reporting any messages that it responds with.
+func (h *providerHooks) run(hook workspace.ProjectProviderHook, event providerHookEvent) (providerHookResponse, error) {
+	event.Project, event.Stack, event.Type = h.project, h.stack, event.URN.Type()
+	input, err := json.Marshal(event)
+	if err != nil {
+		return providerHookResponse{}, err
+	}
+
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", hook.Command)
+	} else {
+		c = exec.Command("sh", "-c", hook.Command)
+	}
+	var stdout, stderr bytes.Buffer
+	c.Dir, c.Env = h.root, os.Environ()
+	c.Stdin, c.Stdout, c.Stderr = bytes.NewReader(input), &stdout, &stderr
+	if err = c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.Errorf("%v: %s", err, msg)
+		}
+		return providerHookResponse{}, errors.Wrapf(err, "provider hook '%s' failed", hook.Command)
+	}
+
+	var resp providerHookResponse
+	if out := bytes.TrimSpace(stdout.Bytes()); len(out) > 0 {
+		if err = json.Unmarshal(out, &resp); err != nil {
+			return providerHookResponse{}, errors.Wrapf(err, "provider hook '%s' wrote an invalid response",
+				hook.Command)
+		}
+	}
+	for _, msg := range resp.Messages {
+		h.diag.Infof(diag.RawMessage(event.URN, msg))
+	}
+	return resp, nil
+}
+
+// hookedProvider runs a project's provider hooks around the creates, updates, and deletes that a provider performs.
+type hookedProvider struct {
+	plugin.Provider
+	hooks *providerHooks
+}
+
+func (p *hookedProvider) Create(urn resource.URN, news resource.PropertyMap,
+	timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+	event := providerHookEvent{Operation: "create", URN: urn, News: news.Mappable()}
+	if err := p.hooks.before(event); err != nil {
+		return "", nil, resource.StatusOK, err
+	}
+	id, outs, status, err := p.Provider.Create(urn, news, timeout)
+	event.ID, event.Outputs = id, outs.Mappable()
+	p.hooks.after(event, err)
+	return id, outs, status, err
+}
+
+func (p *hookedProvider) Update(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+	news resource.PropertyMap, timeout float64) (resource.PropertyMap, resource.Status, error) {
+
+	event := providerHookEvent{Operation: "update", URN: urn, ID: id, Olds: olds.Mappable(), News: news.Mappable()}
+	if err := p.hooks.before(event); err != nil {
+		return nil, resource.StatusOK, err
+	}
+	outs, status, err := p.Provider.Update(urn, id, olds, news, timeout)
+	event.Outputs = outs.Mappable()
+	p.hooks.after(event, err)
+	return outs, status, err
+}
+
+func (p *hookedProvider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
+
+	event := providerHookEvent{Operation: "delete", URN: urn, ID: id, Olds: props.Mappable()}
+	if err := p.hooks.before(event); err != nil {
+		return resource.StatusOK, err
+	}
+	status, err := p.Provider.Delete(urn, id, props, timeout)
+	p.hooks.after(event, err)
+	return status, err
+}
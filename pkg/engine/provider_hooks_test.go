@@ -0,0 +1,121 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// providerHookScript logs each event to the file named by its first argument, vetoes deletes, and annotates creates.
+const providerHookScript = `input=$(cat)
+echo "$input" >> "$1"
+case "$input" in
+  *'"hook":"before","operation":"delete"'*) echo '{"veto":"deletes are frozen"}' ;;
+  *'"hook":"before","operation":"create"'*) echo '{"messages":["name approved"]}' ;;
+esac
+`
+
+func TestProviderHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("provider hook test script requires sh")
+	}
+
+	dir, err := ioutil.TempDir("", "pulumi-provider-hooks")
+	assert.NoError(t, err)
+	defer func() { contract.IgnoreError(os.RemoveAll(dir)) }()
+	script, log := filepath.Join(dir, "hook.sh"), filepath.Join(dir, "events.log")
+	assert.NoError(t, ioutil.WriteFile(script, []byte(providerHookScript), 0700))
+
+	deletes := 0
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DeleteF: func(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+					timeout float64) (resource.Status, error) {
+
+					deletes++
+					return resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	createResource := true
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		if createResource {
+			_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, "", false, nil, "",
+				resource.PropertyMap{"name": resource.NewStringProperty("resA")}, nil, false)
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{host: host},
+	}
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+	project := p.GetProject()
+	project.ProviderHooks = []workspace.ProjectProviderHook{{Command: "sh " + script + " " + log}}
+
+	// The create is allowed, and the hook's message is reported against the resource.
+	snap, err := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient,
+		func(_ workspace.Project, _ deploy.Target, _ *Journal, events []Event, err error) error {
+			found := false
+			for _, e := range events {
+				if e.Type == DiagEvent {
+					payload := e.Payload.(DiagEventPayload)
+					found = found || payload.URN == resURN && payload.Severity == diag.Info &&
+						strings.Contains(payload.Message, "name approved")
+				}
+			}
+			assert.True(t, found)
+			return err
+		})
+	assert.NoError(t, err)
+	assert.Len(t, snap.Resources, 2)
+
+	// The hook saw the create before and after it happened.
+	b, err := ioutil.ReadFile(log)
+	assert.NoError(t, err)
+	events := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if assert.Len(t, events, 2) {
+		assert.Contains(t, events[0], `"hook":"before","operation":"create"`)
+		assert.Contains(t, events[0], `"news":{"name":"resA"}`)
+		assert.Contains(t, events[1], `"hook":"after","operation":"create"`)
+	}
+
+	// The delete is vetoed, so the provider never sees it.
+	createResource = false
+	_, err = TestOp(Update).Run(project, p.GetTarget(snap), p.Options, false, p.BackendClient, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 0, deletes)
+}
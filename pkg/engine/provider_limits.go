@@ -0,0 +1,82 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// tokenBucket limits the rate of events to a sustained number per second, while allowing up to a burst of events to
+// happen at once. It is safe for concurrent use.
+type tokenBucket struct {
+	lock   sync.Mutex
+	rate   float64   // the number of tokens added per second.
+	burst  float64   // the most tokens the bucket holds.
+	tokens float64   // the tokens in the bucket as of last; negative if callers are waiting for tokens.
+	last   time.Time // the time at which tokens was last brought up to date.
+	after  func(time.Duration) <-chan time.Time
+}
+
+// newTokenBucket returns a full bucket that allows the given number of events per second, in bursts of up to the given
+// size. A burst of less than 1 allows events only one at a time.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		after:  time.After,
+	}
+}
+
+// wait blocks until an event may happen without exceeding the bucket's rate, and takes a token for it. Callers are
+// served in the order in which they call wait. If the given channel is closed first, wait gives up its place in line
+// and returns an error.
+func (b *tokenBucket) wait(canceled <-chan struct{}) error {
+	b.lock.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	// Take a token now, even if that leaves the bucket in debt, and then wait for the debt to be repaid. This reserves
+	// the caller's place in line.
+	b.tokens--
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.lock.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-b.after(delay):
+		case <-canceled:
+			b.lock.Lock()
+			b.tokens++
+			b.lock.Unlock()
+			return errors.New("canceled while waiting for the provider's rate limit")
+		}
+	}
+	return nil
+}
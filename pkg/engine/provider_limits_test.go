@@ -0,0 +1,62 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	var delays []time.Duration
+	b := newTokenBucket(10, 3)
+	b.after = func(d time.Duration) <-chan time.Time {
+		delays = append(delays, d)
+		return time.After(0)
+	}
+
+	// A full bucket allows a burst of three events without waiting; after that, each event waits for its turn at ten
+	// events per second.
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, b.wait(nil))
+	}
+	if assert.Len(t, delays, 2) {
+		assert.InDelta(t, float64(100*time.Millisecond), float64(delays[0]), float64(5*time.Millisecond))
+		assert.InDelta(t, float64(200*time.Millisecond), float64(delays[1]), float64(5*time.Millisecond))
+	}
+
+	// Tokens accumulate again over time, but never beyond the burst size.
+	b.last = b.last.Add(-time.Hour)
+	delays = nil
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, b.wait(nil))
+	}
+	assert.Len(t, delays, 1)
+}
+
+func TestTokenBucketCanceled(t *testing.T) {
+	b := newTokenBucket(1, 1)
+	b.after = func(time.Duration) <-chan time.Time { return nil }
+
+	// The first event takes the only token. The second must wait, so it fails once the wait is canceled, and gives
+	// its token back to the next caller.
+	assert.NoError(t, b.wait(nil))
+	canceled := make(chan struct{})
+	close(canceled)
+	assert.Error(t, b.wait(canceled))
+	assert.InDelta(t, 0, b.tokens, 0.1)
+}
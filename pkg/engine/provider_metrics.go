@@ -0,0 +1,266 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blang/semver"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// ProviderTiming records the calls that an update made to the resource providers for a single package.
+type ProviderTiming struct {
+	Package  tokens.Package       `json:"package"`  // the providers' package.
+	Duration time.Duration        `json:"duration"` // the total time spent in calls to the providers.
+	Calls    []ProviderCallTiming `json:"calls"`    // the calls made to each method, in alphabetical order.
+}
+
+// ProviderCallTiming records the calls that an update made to a single method of a package's resource providers.
+type ProviderCallTiming struct {
+	Method   string        `json:"method"`   // the name of the method, e.g. Create.
+	Count    int           `json:"count"`    // the number of calls made.
+	Duration time.Duration `json:"duration"` // the total time spent in the calls.
+	P95      time.Duration `json:"p95"`      // the 95th percentile latency of the calls.
+}
+
+// providerMetrics measures the calls made to resource providers. It is safe for concurrent use.
+type providerMetrics struct {
+	lock  sync.Mutex
+	calls map[tokens.Package]map[string][]time.Duration
+}
+
+func newProviderMetrics() *providerMetrics {
+	return &providerMetrics{calls: make(map[tokens.Package]map[string][]time.Duration)}
+}
+
+// record records that a call to the given method of a provider for the given package took the given time.
+func (m *providerMetrics) record(pkg tokens.Package, method string, d time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	methods, ok := m.calls[pkg]
+	if !ok {
+		methods = make(map[string][]time.Duration)
+		m.calls[pkg] = methods
+	}
+	methods[method] = append(methods[method], d)
+}
+
+// report summarizes the recorded calls, with the packages whose providers took the most time first.
+func (m *providerMetrics) report() []ProviderTiming {
+	if m == nil {
+		return nil
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var result []ProviderTiming
+	for pkg, methods := range m.calls {
+		timing := ProviderTiming{Package: pkg}
+		for method, durations := range methods {
+			call := ProviderCallTiming{Method: method, Count: len(durations)}
+			for _, d := range durations {
+				call.Duration += d
+			}
+			sorted := append([]time.Duration{}, durations...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			call.P95 = sorted[(len(sorted)*95+99)/100-1]
+
+			timing.Duration += call.Duration
+			timing.Calls = append(timing.Calls, call)
+		}
+		sort.Slice(timing.Calls, func(i, j int) bool { return timing.Calls[i].Method < timing.Calls[j].Method })
+		result = append(result, timing)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Duration != result[j].Duration {
+			return result[i].Duration > result[j].Duration
+		}
+		return result[i].Package < result[j].Package
+	})
+	return result
+}
+
+// newMeteredHost returns a host that loads plugins from the given host, that limits the rate of the calls made to
+// the resource providers it loads according to the given provider settings, and that records those calls in the
+// given metrics. Calls that are waiting for their turn fail once the given channel is closed.
+func newMeteredHost(host plugin.Host, metrics *providerMetrics,
+	settings map[string]workspace.ProjectProviderSettings, canceled <-chan struct{}) plugin.Host {
+
+	limiters := make(map[tokens.Package]*tokenBucket)
+	for pkg, s := range settings {
+		if s.QPS > 0 {
+			limiters[tokens.Package(pkg)] = newTokenBucket(s.QPS, s.Burst)
+		}
+	}
+	return &meteredHost{Host: host, metrics: metrics, limiters: limiters, canceled: canceled}
+}
+
+type meteredHost struct {
+	plugin.Host
+	metrics  *providerMetrics
+	limiters map[tokens.Package]*tokenBucket // shared by all of the providers for each rate-limited package.
+	canceled <-chan struct{}
+}
+
+func (host *meteredHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	prov, err := host.Host.Provider(pkg, version)
+	if prov == nil || err != nil {
+		return prov, err
+	}
+	return &meteredProvider{
+		Provider: prov,
+		pkg:      pkg,
+		metrics:  host.metrics,
+		limiter:  host.limiters[pkg],
+		canceled: host.canceled,
+	}, nil
+}
+
+func (host *meteredHost) CloseProvider(provider plugin.Provider) error {
+	if metered, ok := provider.(*meteredProvider); ok {
+		provider = metered.Provider
+	}
+	return host.Host.CloseProvider(provider)
+}
+
+// meteredProvider limits the rate of the calls that the engine makes to a provider, and records the time each takes.
+type meteredProvider struct {
+	plugin.Provider
+	pkg      tokens.Package
+	metrics  *providerMetrics
+	limiter  *tokenBucket // nil if calls to the provider are not rate-limited.
+	canceled <-chan struct{}
+}
+
+// call waits until a call to the given method may be made without exceeding the provider's rate limit, and returns a
+// function that records the call's duration once it completes. Time spent waiting is not counted. It fails if the
+// update is canceled while waiting.
+func (p *meteredProvider) call(method string) (func(), error) {
+	if p.limiter != nil {
+		if err := p.limiter.wait(p.canceled); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	return func() {
+		p.metrics.record(p.pkg, method, time.Since(start))
+	}, nil
+}
+
+func (p *meteredProvider) CheckConfig(olds,
+	news resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+	done, err := p.call("CheckConfig")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+	return p.Provider.CheckConfig(olds, news)
+}
+
+func (p *meteredProvider) DiffConfig(olds, news resource.PropertyMap) (plugin.DiffResult, error) {
+	done, err := p.call("DiffConfig")
+	if err != nil {
+		return plugin.DiffResult{}, err
+	}
+	defer done()
+	return p.Provider.DiffConfig(olds, news)
+}
+
+func (p *meteredProvider) Configure(inputs resource.PropertyMap) error {
+	done, err := p.call("Configure")
+	if err != nil {
+		return err
+	}
+	defer done()
+	return p.Provider.Configure(inputs)
+}
+
+func (p *meteredProvider) Check(urn resource.URN, olds, news resource.PropertyMap,
+	allowUnknowns bool) (resource.PropertyMap, []plugin.CheckFailure, error) {
+	done, err := p.call("Check")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+	return p.Provider.Check(urn, olds, news, allowUnknowns)
+}
+
+func (p *meteredProvider) Diff(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+	news resource.PropertyMap, allowUnknowns bool) (plugin.DiffResult, error) {
+	done, err := p.call("Diff")
+	if err != nil {
+		return plugin.DiffResult{}, err
+	}
+	defer done()
+	return p.Provider.Diff(urn, id, olds, news, allowUnknowns)
+}
+
+func (p *meteredProvider) Create(urn resource.URN, news resource.PropertyMap,
+	timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+	done, err := p.call("Create")
+	if err != nil {
+		return "", nil, resource.StatusOK, err
+	}
+	defer done()
+	return p.Provider.Create(urn, news, timeout)
+}
+
+func (p *meteredProvider) Read(urn resource.URN, id resource.ID,
+	inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+	done, err := p.call("Read")
+	if err != nil {
+		return plugin.ReadResult{}, resource.StatusOK, err
+	}
+	defer done()
+	return p.Provider.Read(urn, id, inputs, state)
+}
+
+func (p *meteredProvider) Update(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+	news resource.PropertyMap, timeout float64) (resource.PropertyMap, resource.Status, error) {
+	done, err := p.call("Update")
+	if err != nil {
+		return nil, resource.StatusOK, err
+	}
+	defer done()
+	return p.Provider.Update(urn, id, olds, news, timeout)
+}
+
+func (p *meteredProvider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
+	done, err := p.call("Delete")
+	if err != nil {
+		return resource.StatusOK, err
+	}
+	defer done()
+	return p.Provider.Delete(urn, id, props, timeout)
+}
+
+func (p *meteredProvider) Invoke(tok tokens.ModuleMember,
+	args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+	done, err := p.call("Invoke")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+	return p.Provider.Invoke(tok, args)
+}
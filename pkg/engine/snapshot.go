@@ -47,3 +47,13 @@ type SnapshotMutation interface {
 	// failed to complete.
 	End(step deploy.Step, successful bool) error
 }
+
+// PendingSnapshotMutation is a SnapshotMutation that may be ended without completing the operation that it began.
+type PendingSnapshotMutation interface {
+	SnapshotMutation
+
+	// EndPending terminates the transaction but leaves its operation pending in the snapshot, because whether the
+	// operation took effect is unknown. Updates refuse to proceed while a snapshot has pending operations, so that the
+	// outcome of the operation is determined before the resource is changed again.
+	EndPending(step deploy.Step) error
+}
@@ -0,0 +1,166 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+)
+
+// ResourceTiming records how long an update spent applying the steps for a single resource.
+type ResourceTiming struct {
+	URN      resource.URN  `json:"urn"`      // the resource's URN.
+	Op       deploy.StepOp `json:"op"`       // the operation performed by the resource's first step.
+	Start    time.Time     `json:"start"`    // the time at which the resource's first step began.
+	Duration time.Duration `json:"duration"` // the time from the start of the first step to the end of the last.
+}
+
+// TimingReport describes where the time went during an update: how long each resource took, the critical path,
+// which is the chain of dependent resources that determined how long the update ran, and the calls made to each
+// package's resource providers.
+type TimingReport struct {
+	Resources            []ResourceTiming `json:"resources,omitempty"`            // changed resources, slowest first.
+	CriticalPath         []ResourceTiming `json:"criticalPath,omitempty"`         // in the order they ran.
+	CriticalPathDuration time.Duration    `json:"criticalPathDuration,omitempty"` // from first start to last end.
+	Providers            []ProviderTiming `json:"providers,omitempty"`            // the slowest providers first.
+}
+
+// resourceTimer measures the steps that an update applies to each resource. It is safe for concurrent use.
+type resourceTimer struct {
+	lock    sync.Mutex
+	starts  map[deploy.Step]time.Time
+	timings map[resource.URN]*timedResource
+	order   []resource.URN
+}
+
+// timedResource is the span of time covered by the steps applied to a single resource.
+type timedResource struct {
+	op           deploy.StepOp
+	start, end   time.Time
+	dependencies []resource.URN
+}
+
+func newResourceTimer() *resourceTimer {
+	return &resourceTimer{
+		starts:  make(map[deploy.Step]time.Time),
+		timings: make(map[resource.URN]*timedResource),
+	}
+}
+
+// stepStarted records that the given step began at the given time.
+func (t *resourceTimer) stepStarted(step deploy.Step, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.starts[step] = now
+}
+
+// stepFinished records that the given step finished at the given time.
+func (t *resourceTimer) stepFinished(step deploy.Step, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	start, ok := t.starts[step]
+	if !ok {
+		return
+	}
+	delete(t.starts, step)
+
+	timing, ok := t.timings[step.URN()]
+	if !ok {
+		timing = &timedResource{op: step.Op(), start: start}
+		t.timings[step.URN()] = timing
+		t.order = append(t.order, step.URN())
+	}
+	if start.Before(timing.start) {
+		timing.start = start
+	}
+	if now.After(timing.end) {
+		timing.end = now
+	}
+
+	// Only a resource's new state describes what it waited for; a deleted resource waits for its dependents instead.
+	if res := step.New(); res != nil {
+		timing.dependencies = append([]resource.URN{}, res.Dependencies...)
+		if res.Provider != "" {
+			if ref, err := providers.ParseReference(res.Provider); err == nil {
+				timing.dependencies = append(timing.dependencies, ref.URN())
+			}
+		}
+	}
+}
+
+// report summarizes the recorded timings, along with the given calls to resource providers.
+func (t *resourceTimer) report(providers []ProviderTiming) *TimingReport {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.order) == 0 {
+		if len(providers) == 0 {
+			return nil
+		}
+		return &TimingReport{Providers: providers}
+	}
+
+	makeTiming := func(urn resource.URN) ResourceTiming {
+		timing := t.timings[urn]
+		return ResourceTiming{URN: urn, Op: timing.op, Start: timing.start, Duration: timing.end.Sub(timing.start)}
+	}
+
+	report := &TimingReport{Providers: providers}
+	for _, urn := range t.order {
+		if t.timings[urn].op != deploy.OpSame {
+			report.Resources = append(report.Resources, makeTiming(urn))
+		}
+	}
+	sort.SliceStable(report.Resources, func(i, j int) bool {
+		return report.Resources[i].Duration > report.Resources[j].Duration
+	})
+
+	// The critical path ends with the resource that finished last. Working backwards, each resource on the path was
+	// held up by whichever of its dependencies finished last.
+	last := t.order[0]
+	for _, urn := range t.order {
+		if t.timings[urn].end.After(t.timings[last].end) {
+			last = urn
+		}
+	}
+	onPath := map[resource.URN]bool{last: true}
+	path := []resource.URN{last}
+	for {
+		var next resource.URN
+		for _, dep := range t.timings[path[len(path)-1]].dependencies {
+			timing, ok := t.timings[dep]
+			if ok && !onPath[dep] && (next == "" || timing.end.After(t.timings[next].end)) {
+				next = dep
+			}
+		}
+		if next == "" {
+			break
+		}
+		onPath[next] = true
+		path = append(path, next)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		report.CriticalPath = append(report.CriticalPath, makeTiming(path[i]))
+	}
+	report.CriticalPathDuration = t.timings[last].end.Sub(t.timings[path[len(path)-1]].start)
+
+	return report
+}
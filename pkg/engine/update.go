@@ -48,6 +48,40 @@ type UpdateOptions struct {
 	// true if the plan should refresh before executing.
 	Refresh bool
 
+	// the URNs of resources that should be replaced even if their diffs do not require it.
+	ReplaceTargets []resource.URN
+
+	// the URNs of resources that, along with any resources that depend on them, the update must leave unchanged.
+	ExcludeTargets []resource.URN
+
+	// an optional saved plan whose steps the update must match exactly.
+	FollowPlan *deploy.SavedPlan
+
+	// an optional saved plan in which to record the update's steps.
+	SavePlan *deploy.SavedPlan
+
+	// true if independent steps should continue to execute after a step fails.
+	ContinueOnError bool
+
+	// the URNs of resources to destroy, along with their dependents. If empty, a destroy deletes every resource.
+	DestroyTargets []resource.URN
+
+	// an optional earlier snapshot whose resources an update should restore, instead of running the program.
+	RollbackSnapshot *deploy.Snapshot
+
+	// the stack's diff suppressions, which apply in addition to any that the project declares.
+	SuppressDiffs []workspace.ProjectDiffSuppression
+
+	// true if the update is resuming a failed update, and may skip the resources that that update completed.
+	Resume bool
+
+	// the 1-based index of the phase of the project's staged rollout to deploy, or 0 to deploy every phase at once.
+	RolloutPhase int
+
+	// optional fixtures for mock providers that, if set, replace every resource provider, so that the update manages no
+	// real resources.
+	MockProviders *plugin.MockFixtures
+
 	// true if we should report events for steps that involve default providers.
 	reportDefaultProviderSteps bool
 
@@ -98,17 +132,22 @@ func newUpdateSource(
 	client deploy.BackendClient, opts planOptions, proj *workspace.Project, pwd, main string,
 	target *deploy.Target, plugctx *plugin.Context, dryRun bool) (deploy.Source, error) {
 
+	if opts.RollbackSnapshot != nil {
+		return newRollbackSource(client, opts, proj, target, plugctx)
+	}
+
 	// Before launching the source, ensure that we have all of the plugins that we need in order to proceed.
 	//
-	// There are two places that we need to look for plugins:
+	// There are three places that we need to look for plugins:
 	//   1. The language host, which reports to us the set of plugins that the program that's about to execute
 	//      needs in order to create new resources. This is purely advisory by the language host and not all
 	//      languages implement this (notably Python).
 	//   2. The snapshot. The snapshot contains plugins in two locations: first, in the manifest, all plugins
 	//      that were loaded are recorded. Second, all first class providers record the version of the plugin
 	//      to which they are bound.
+	//   3. The project, which may list plugins that the language host cannot detect.
 	//
-	// In order to get a complete view of the set of plugins that we need for an update, we must consult both
+	// In order to get a complete view of the set of plugins that we need for an update, we must consult all of these
 	// sources and merge their results into a list of plugins.
 	languagePlugins, err := gatherPluginsFromProgram(plugctx, plugin.ProgInfo{
 		Proj:    proj,
@@ -122,16 +161,34 @@ func newUpdateSource(
 	if err != nil {
 		return nil, err
 	}
-	allPlugins := languagePlugins.Union(snapshotPlugins)
+	projectPlugins, err := gatherPluginsFromProject(proj)
+	if err != nil {
+		return nil, err
+	}
+	requiredPlugins := languagePlugins.Union(projectPlugins)
+
+	// If the project has a plugin lock, the resource plugins that the program requires must be used at exactly the
+	// versions it pins. Plugins that are only required by the snapshot are bound to versions recorded in it already.
+	if opts.pluginLock != nil {
+		if requiredPlugins, err = lockPlugins(opts.pluginLock, requiredPlugins); err != nil {
+			return nil, err
+		}
+	}
+	allPlugins := requiredPlugins.Union(snapshotPlugins)
 
-	// If there are any plugins that are not available, we can attempt to install them here. This only works when using
-	// the http backend, since the local backend is not capable of installing plugins on its own.
+	// If there are any plugins that are not available, we can attempt to install them here. The Pulumi service serves
+	// plugins itself; other backends download them from the plugin release server.
 	//
 	// Note that this is purely a best-effort thing. If we can't install missing plugins, just proceed; we'll fail later
 	// with an error message indicating exactly what plugins are missing.
-	if err := ensurePluginsAreInstalled(client, allPlugins); err != nil {
+	if err := ensurePluginsAreInstalled(client, plugctx.Diag, withoutMockedPlugins(opts, allPlugins)); err != nil {
 		logging.V(7).Infof("newUpdateSource(): failed to install missing plugins: %v", err)
 	}
+	if opts.pluginLock != nil {
+		if err := verifyLockedPlugins(plugctx.Diag, withoutMockedPlugins(opts, requiredPlugins)); err != nil {
+			return nil, err
+		}
+	}
 
 	// Once we've installed all of the plugins we need, make sure that all analyzers and language plugins are
 	// loaded up and ready to go. Provider plugins are loaded lazily by the provider registry and thus don't
@@ -149,6 +206,25 @@ func newUpdateSource(
 		}
 		defaultProviderVersions[tokens.Package(p.Name)] = p.Version
 	}
+	if opts.pluginLock != nil {
+		// Pinned versions take precedence over other versions of the same plugins that the snapshot requires.
+		for _, p := range requiredPlugins.Values() {
+			if p.Kind == workspace.ResourcePlugin {
+				defaultProviderVersions[tokens.Package(p.Name)] = p.Version
+			}
+		}
+	}
+
+	// Check the configuration of the providers that the program requires before anything is planned.
+	requiredVersions := make(map[tokens.Package]*semver.Version)
+	for _, p := range requiredPlugins.Values() {
+		if p.Kind == workspace.ResourcePlugin {
+			requiredVersions[tokens.Package(p.Name)] = defaultProviderVersions[tokens.Package(p.Name)]
+		}
+	}
+	if err := checkProviderConfig(plugctx, target, requiredVersions); err != nil {
+		return nil, err
+	}
 
 	// If that succeeded, create a new source that will perform interpretation of the compiled program.
 	// TODO[pulumi/pulumi#88]: we are passing `nil` as the arguments map; we need to allow a way to pass these.
@@ -160,6 +236,31 @@ func newUpdateSource(
 	}, defaultProviderVersions, dryRun), nil
 }
 
+// newRollbackSource returns a source that restores the resources recorded in opts.RollbackSnapshot. Like a destroy,
+// a rollback doesn't run the program, so it only needs the plugins used by the current and restored snapshots.
+func newRollbackSource(client deploy.BackendClient, opts planOptions, proj *workspace.Project,
+	target *deploy.Target, plugctx *plugin.Context) (deploy.Source, error) {
+
+	currentPlugins, err := gatherPluginsFromSnapshot(plugctx, target)
+	if err != nil {
+		return nil, err
+	}
+	rollbackPlugins, err := gatherPluginsFromSnapshot(plugctx, &deploy.Target{Snapshot: opts.RollbackSnapshot})
+	if err != nil {
+		return nil, err
+	}
+	allPlugins := currentPlugins.Union(rollbackPlugins)
+
+	if err := ensurePluginsAreInstalled(client, plugctx.Diag, withoutMockedPlugins(opts, allPlugins)); err != nil {
+		logging.V(7).Infof("newRollbackSource(): failed to install missing plugins: %v", err)
+	}
+	if err := ensurePluginsAreLoaded(plugctx, allPlugins, plugin.AnalyzerPlugins); err != nil {
+		return nil, err
+	}
+
+	return deploy.NewSnapshotSource(proj.Name, opts.RollbackSnapshot), nil
+}
+
 func update(ctx *Context, info *planContext, opts planOptions, dryRun bool) (ResourceChanges, error) {
 	planResult, err := plan(ctx, info, opts, dryRun)
 	if err != nil {
@@ -193,7 +294,8 @@ func update(ctx *Context, info *planContext, opts planOptions, dryRun bool) (Res
 
 			if len(resourceChanges) != 0 {
 				// Print out the total number of steps performed (and their kinds), the duration, and any summary info.
-				opts.Events.updateSummaryEvent(actions.MaybeCorrupt, time.Since(start), resourceChanges)
+				opts.Events.updateSummaryEvent(actions.MaybeCorrupt, time.Since(start), resourceChanges,
+					actions.Timer.report(planResult.Options.providerMetrics.report()))
 			}
 		}
 	}
@@ -220,6 +322,7 @@ type updateActions struct {
 	MaybeCorrupt bool
 	Update       UpdateInfo
 	Opts         planOptions
+	Timer        *resourceTimer
 }
 
 func newUpdateActions(context *Context, u UpdateInfo, opts planOptions) *updateActions {
@@ -229,6 +332,7 @@ func newUpdateActions(context *Context, u UpdateInfo, opts planOptions) *updateA
 		Seen:    make(map[resource.URN]deploy.Step),
 		Update:  u,
 		Opts:    opts,
+		Timer:   newResourceTimer(),
 	}
 }
 
@@ -238,6 +342,8 @@ func (acts *updateActions) OnResourceStepPre(step deploy.Step) (interface{}, err
 	acts.Seen[step.URN()] = step
 	acts.MapLock.Unlock()
 
+	acts.Timer.stepStarted(step, time.Now())
+
 	// Skip reporting if necessary.
 	if shouldReportStep(step, acts.Opts) {
 		acts.Opts.Events.resourcePreEvent(step, false /*planning*/, acts.Opts.Debug)
@@ -255,6 +361,8 @@ func (acts *updateActions) OnResourceStepPost(
 	assertSeen(acts.Seen, step)
 	acts.MapLock.Unlock()
 
+	acts.Timer.stepFinished(step, time.Now())
+
 	// If we've already been terminated, exit without writing the checkpoint. We explicitly want to leave the
 	// checkpoint in an inconsistent state in this event.
 	if acts.Context.Cancel.TerminateErr() != nil {
@@ -331,6 +439,15 @@ func (acts *updateActions) OnResourceStepPost(
 		}
 	}
 
+	// If a create timed out, the resource may have been created, but its ID is unknown. Rather than forget it,
+	// leave the create pending in the checkpoint, so that the user resolves it before the stack is updated again.
+	_, timedOut := err.(*plugin.TimeoutError)
+	if timedOut && (step.Op() == deploy.OpCreate || step.Op() == deploy.OpCreateReplacement) {
+		if pending, ok := ctx.(PendingSnapshotMutation); ok {
+			return pending.EndPending(step)
+		}
+	}
+
 	// Write out the current snapshot. Note that even if a failure has occurred, we should still have a
 	// safe checkpoint.  Note that any error that occurs when writing the checkpoint trumps the error
 	// reported above.
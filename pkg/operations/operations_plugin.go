@@ -0,0 +1,189 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// PluginProviders loads and configures, on demand, the provider plugins that serve the logs of resources whose
+// packages have no built-in support. It keeps the providers it loads, and remembers those that serve no logs, so that
+// it may be shared by many queries, e.g. while following a stack's logs, without loading any plugin more than once.
+type PluginProviders struct {
+	host      plugin.Host
+	providers map[string]*pluginProvider // loaded providers by reference.
+	m         sync.Mutex
+}
+
+// pluginProvider is a provider loaded by PluginProviders.
+type pluginProvider struct {
+	provider plugin.Provider     // the configured provider; nil if it could not be loaded.
+	inputs   resource.PropertyMap // the inputs with which the provider was configured.
+	noLogs   bool                 // true if the provider does not serve logs.
+}
+
+// NewPluginProviders returns a PluginProviders that loads provider plugins from the given host.
+func NewPluginProviders(host plugin.Host) *PluginProviders {
+	return &PluginProviders{
+		host:      host,
+		providers: make(map[string]*pluginProvider),
+	}
+}
+
+// provider returns the configured provider plugin with the given reference, whose resource is in the given resources,
+// or nil if there is none. A provider that cannot be loaded, e.g. because its plugin is not installed, is skipped
+// rather than reported, so that it does not keep the logs of the stack's other resources from being shown.
+func (s *PluginProviders) provider(rawRef string, resources map[resource.URN]*Resource) plugin.Provider {
+	ref, err := providers.ParseReference(rawRef)
+	if err != nil {
+		logging.V(5).Infof("not fetching logs from provider %s: %v", rawRef, err)
+		return nil
+	}
+	res, ok := resources[ref.URN()]
+	if !ok || res.State.ID != ref.ID() {
+		return nil
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	// Reuse the provider loaded for an earlier query unless the provider resource's configuration has changed since.
+	if loaded, ok := s.providers[rawRef]; ok {
+		if loaded.inputs.DeepEquals(res.State.Inputs) {
+			if loaded.noLogs {
+				return nil
+			}
+			return loaded.provider
+		}
+		if loaded.provider != nil {
+			contract.IgnoreError(s.host.CloseProvider(loaded.provider))
+		}
+	}
+
+	prov, err := s.load(res)
+	if err != nil {
+		logging.V(5).Infof("not fetching logs from provider %s: %v", rawRef, err)
+	}
+	s.providers[rawRef] = &pluginProvider{provider: prov, inputs: res.State.Inputs}
+	return prov
+}
+
+func (s *PluginProviders) load(res *Resource) (plugin.Provider, error) {
+	pkg := providers.GetProviderPackage(res.State.Type)
+	version, err := providers.GetProviderVersion(res.State.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	prov, err := s.host.Provider(pkg, version)
+	if err != nil || prov == nil {
+		return nil, err
+	}
+	if err = prov.Configure(res.State.Inputs); err != nil {
+		contract.IgnoreError(s.host.CloseProvider(prov))
+		return nil, err
+	}
+	return prov, nil
+}
+
+// noLogs records that the provider with the given reference serves no logs, so that it is not asked again. The
+// provider is left loaded, as other resources' queries may still be waiting for it to answer.
+func (s *PluginProviders) noLogs(rawRef string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if loaded, ok := s.providers[rawRef]; ok && !loaded.noLogs {
+		logging.V(5).Infof("provider %s serves no logs", rawRef)
+		loaded.noLogs = true
+	}
+}
+
+// newPluginOpsProvider creates an OperationsProvider that answers operational queries about a custom resource by
+// asking the resource provider plugin that manages it, by way of the provider's GetLogs RPC.
+func newPluginOpsProvider(source *pluginSource, component *Resource) (Provider, error) {
+	prov := &pluginOpsProvider{
+		source:    source,
+		component: component,
+	}
+	return prov, nil
+}
+
+// pluginSource finds the provider plugins for the resources in a single resource tree.
+type pluginSource struct {
+	providers *PluginProviders
+	resources map[resource.URN]*Resource
+}
+
+func newPluginSource(providers *PluginProviders, root *Resource) *pluginSource {
+	resources := make(map[resource.URN]*Resource)
+	var visit func(r *Resource)
+	visit = func(r *Resource) {
+		if r.State != nil {
+			resources[r.State.URN] = r
+		}
+		for _, child := range r.Children {
+			visit(child)
+		}
+	}
+	visit(root)
+
+	return &pluginSource{providers: providers, resources: resources}
+}
+
+type pluginOpsProvider struct {
+	source    *pluginSource
+	component *Resource
+}
+
+var _ Provider = (*pluginOpsProvider)(nil)
+
+func (ops *pluginOpsProvider) GetLogs(query LogQuery) (*[]LogEntry, error) {
+	state := ops.component.State
+	if !state.Custom || state.Provider == "" || providers.IsProviderType(state.Type) {
+		return nil, nil
+	}
+	prov := ops.source.providers.provider(state.Provider, ops.source.resources)
+	if prov == nil {
+		return nil, nil
+	}
+
+	entries, err := prov.GetLogs(state.URN, state.ID, state.Outputs, query.StartTime, query.EndTime)
+	if err == plugin.ErrNoLogs {
+		ops.source.providers.noLogs(state.Provider)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		// Leave the resource's children, if it has any, to report their own logs.
+		return nil, nil
+	}
+
+	logs := make([]LogEntry, len(entries))
+	for i, entry := range entries {
+		logs[i] = LogEntry{
+			ID:        entry.ID,
+			Timestamp: entry.Timestamp,
+			Message:   entry.Message,
+		}
+	}
+	logging.V(5).Infof("GetLogs[%v] return %d logs", state.URN, len(logs))
+	return &logs, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+)
+
+func TestPluginOperationsProvider(t *testing.T) {
+	provURN := resource.NewURN("test", "proj", "", providers.MakeProviderType("pkgA"), "default")
+	provRef, err := providers.NewReference(provURN, "provider-id")
+	assert.NoError(t, err)
+
+	resA := resource.NewURN("test", "proj", "", "pkgA:m:typA", "resA")
+	resB := resource.NewURN("test", "proj", "", "pkgB:m:typB", "resB")
+	states := []*resource.State{
+		{Type: provURN.Type(), URN: provURN, Custom: true, ID: "provider-id", Inputs: resource.PropertyMap{}},
+		{Type: resA.Type(), URN: resA, Custom: true, ID: "a-id", Inputs: resource.PropertyMap{},
+			Outputs:  resource.PropertyMap{"logGroup": resource.NewStringProperty("group-a")},
+			Provider: provRef.String()},
+		// pkgB's provider resource is not in the tree, so its resources have no logs.
+		{Type: resB.Type(), URN: resB, Custom: true, ID: "b-id", Inputs: resource.PropertyMap{},
+			Provider: "urn:pulumi:test::proj::pulumi:providers:pkgB::default::b-provider-id"},
+	}
+
+	start := time.Unix(100, 0)
+	loads := 0
+	host := deploytest.NewPluginHost(nil, nil, nil,
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			loads++
+			return &deploytest.Provider{
+				GetLogsF: func(urn resource.URN, id resource.ID, props resource.PropertyMap,
+					startTime, endTime *time.Time) ([]plugin.LogEntry, error) {
+
+					assert.Equal(t, resA, urn)
+					assert.Equal(t, resource.ID("a-id"), id)
+					assert.Equal(t, "group-a", props["logGroup"].StringValue())
+					assert.Equal(t, &start, startTime)
+					assert.Nil(t, endTime)
+					return []plugin.LogEntry{
+						{ID: "stream", Timestamp: 200000, Message: "second"},
+						{ID: "stream", Timestamp: 100000, Message: "first"},
+					}, nil
+				},
+			}, nil
+		}))
+
+	// Providers are loaded once, and kept for later queries, such as the polls made while following logs.
+	tree, provs := NewResourceTree(states), NewPluginProviders(host)
+	for i := 0; i < 2; i++ {
+		logs, err := tree.PluginOperationsProvider(nil, provs).GetLogs(LogQuery{StartTime: &start})
+		assert.NoError(t, err)
+		if assert.NotNil(t, logs) {
+			assert.Equal(t, []LogEntry{
+				{ID: "stream", Timestamp: 100000, Message: "first"},
+				{ID: "stream", Timestamp: 200000, Message: "second"},
+			}, *logs)
+		}
+	}
+	assert.Equal(t, 1, loads)
+
+	// Without plugins, only packages with built-in support have logs.
+	logs, err := tree.OperationsProvider(nil).GetLogs(LogQuery{})
+	assert.NoError(t, err)
+	if assert.NotNil(t, logs) {
+		assert.Len(t, *logs, 0)
+	}
+}
+
+func TestPluginOperationsProviderNoLogs(t *testing.T) {
+	provURN := resource.NewURN("test", "proj", "", providers.MakeProviderType("pkgA"), "default")
+	provRef, err := providers.NewReference(provURN, "provider-id")
+	assert.NoError(t, err)
+
+	resA := resource.NewURN("test", "proj", "", "pkgA:m:typA", "resA")
+	states := []*resource.State{
+		{Type: provURN.Type(), URN: provURN, Custom: true, ID: "provider-id", Inputs: resource.PropertyMap{}},
+		{Type: resA.Type(), URN: resA, Custom: true, ID: "a-id", Inputs: resource.PropertyMap{},
+			Provider: provRef.String()},
+	}
+
+	calls := 0
+	host := deploytest.NewPluginHost(nil, nil, nil,
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				GetLogsF: func(urn resource.URN, id resource.ID, props resource.PropertyMap,
+					startTime, endTime *time.Time) ([]plugin.LogEntry, error) {
+
+					calls++
+					return nil, plugin.ErrNoLogs
+				},
+			}, nil
+		}))
+
+	// A provider that serves no logs is not an error, and is not asked again.
+	tree, provs := NewResourceTree(states), NewPluginProviders(host)
+	for i := 0; i < 3; i++ {
+		logs, err := tree.PluginOperationsProvider(nil, provs).GetLogs(LogQuery{})
+		assert.NoError(t, err)
+		if assert.NotNil(t, logs) {
+			assert.Len(t, *logs, 0)
+		}
+	}
+	assert.Equal(t, 1, calls)
+}
@@ -120,10 +120,22 @@ func (r *Resource) OperationsProvider(config map[config.Key]string) Provider {
 	}
 }
 
+// PluginOperationsProvider gets an OperationsProvider for this resource that, for resources whose packages have no
+// built-in support, asks the provider plugins that manage them, loaded by the given providers. The resource must be the
+// root of its tree, so that the provider resources can be found.
+func (r *Resource) PluginOperationsProvider(config map[config.Key]string, providers *PluginProviders) Provider {
+	return &resourceOperations{
+		resource:  r,
+		config:    config,
+		providers: newPluginSource(providers, r),
+	}
+}
+
 // ResourceOperations is an OperationsProvider for Resources
 type resourceOperations struct {
-	resource *Resource
-	config   map[config.Key]string
+	resource  *Resource
+	config    map[config.Key]string
+	providers *pluginSource // if non-nil, the source of provider plugins for packages without built-in support.
 }
 
 var _ Provider = (*resourceOperations)(nil)
@@ -168,8 +180,9 @@ func (ops *resourceOperations) GetLogs(query LogQuery) (*[]LogEntry, error) {
 	errch := make(chan error)
 	for _, child := range ops.resource.Children {
 		childOps := &resourceOperations{
-			resource: child,
-			config:   ops.config,
+			resource:  child,
+			config:    ops.config,
+			providers: ops.providers,
 		}
 		go func() {
 			childLogs, err := childOps.GetLogs(query)
@@ -255,6 +268,9 @@ func (ops *resourceOperations) getOperationsProvider() (Provider, error) {
 	case "aws":
 		return AWSOperationsProvider(ops.config, ops.resource)
 	default:
+		if ops.providers != nil {
+			return newPluginOpsProvider(ops.providers, ops.resource)
+		}
 		return nil, nil
 	}
 }
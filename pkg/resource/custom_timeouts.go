@@ -0,0 +1,28 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+// CustomTimeouts records the user-specified timeouts, in seconds, for the create, update and delete operations of a
+// resource. A zero timeout means that the default timeout for the operation should be used.
+type CustomTimeouts struct {
+	Create float64 `json:"create,omitempty" yaml:"create,omitempty"`
+	Update float64 `json:"update,omitempty" yaml:"update,omitempty"`
+	Delete float64 `json:"delete,omitempty" yaml:"delete,omitempty"`
+}
+
+// IsNotEmpty returns true if any of the timeouts have been set.
+func (c CustomTimeouts) IsNotEmpty() bool {
+	return c.Create != 0 || c.Update != 0 || c.Delete != 0
+}
@@ -3,6 +3,7 @@ package deploy
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
@@ -96,8 +97,8 @@ func (p *builtinProvider) Diff(urn resource.URN, id resource.ID, state, inputs r
 	return plugin.DiffResult{Changes: plugin.DiffNone}, nil
 }
 
-func (p *builtinProvider) Create(urn resource.URN,
-	inputs resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error) {
+func (p *builtinProvider) Create(urn resource.URN, inputs resource.PropertyMap,
+	timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
 
 	contract.Assert(urn.Type() == stackReferenceType)
 
@@ -109,8 +110,8 @@ func (p *builtinProvider) Create(urn resource.URN,
 	return id, state, resource.StatusOK, nil
 }
 
-func (p *builtinProvider) Update(urn resource.URN, id resource.ID, state,
-	inputs resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+func (p *builtinProvider) Update(urn resource.URN, id resource.ID, state, inputs resource.PropertyMap,
+	timeout float64) (resource.PropertyMap, resource.Status, error) {
 
 	contract.Failf("unexpected update for builtin resource %v", urn)
 	contract.Assert(urn.Type() == stackReferenceType)
@@ -118,8 +119,8 @@ func (p *builtinProvider) Update(urn resource.URN, id resource.ID, state,
 	return state, resource.StatusOK, errors.New("unexpected update for builtin resource")
 }
 
-func (p *builtinProvider) Delete(urn resource.URN, id resource.ID,
-	state resource.PropertyMap) (resource.Status, error) {
+func (p *builtinProvider) Delete(urn resource.URN, id resource.ID, state resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
 
 	contract.Assert(urn.Type() == stackReferenceType)
 
@@ -153,6 +154,15 @@ func (p *builtinProvider) GetPluginInfo() (workspace.PluginInfo, error) {
 	return workspace.PluginInfo{}, errors.New("the builtin provider does not report plugin info")
 }
 
+func (p *builtinProvider) GetSchema(version int) ([]byte, error) {
+	return nil, errors.New("the builtin provider does not publish a schema")
+}
+
+func (p *builtinProvider) GetLogs(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	startTime, endTime *time.Time) ([]plugin.LogEntry, error) {
+	return nil, nil
+}
+
 func (p *builtinProvider) SignalCancellation() error {
 	p.cancel()
 	return nil
@@ -175,5 +175,8 @@ func (host *pluginHost) EnsurePlugins(plugins []workspace.PluginInfo, kinds plug
 }
 func (host *pluginHost) GetRequiredPlugins(info plugin.ProgInfo,
 	kinds plugin.Flags) ([]workspace.PluginInfo, error) {
-	return nil, nil
+	if host.languageRuntime == nil {
+		return nil, nil
+	}
+	return host.languageRuntime.GetRequiredPlugins(info)
 }
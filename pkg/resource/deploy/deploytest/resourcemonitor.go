@@ -111,6 +111,68 @@ func (rm *ResourceMonitor) ReadResource(t tokens.Type, name string, id resource.
 	return resource.URN(resp.Urn), outs, nil
 }
 
+func (rm *ResourceMonitor) ImportResource(t tokens.Type, name string, id resource.ID, parent resource.URN,
+	inputs resource.PropertyMap, provider string) (resource.URN, resource.PropertyMap, error) {
+
+	// marshal inputs
+	ins, err := plugin.MarshalProperties(inputs, plugin.MarshalOptions{KeepUnknowns: true})
+	if err != nil {
+		return "", nil, err
+	}
+
+	// submit request
+	resp, err := rm.resmon.RegisterResource(context.Background(), &pulumirpc.RegisterResourceRequest{
+		Type:     string(t),
+		Name:     name,
+		Custom:   true,
+		Parent:   string(parent),
+		Provider: provider,
+		Object:   ins,
+		ImportId: string(id),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	// unmarshal outputs
+	outs, err := plugin.UnmarshalProperties(resp.Object, plugin.MarshalOptions{KeepUnknowns: true})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return resource.URN(resp.Urn), outs, nil
+}
+
+func (rm *ResourceMonitor) RegisterVersionedResource(t tokens.Type, name string, version string,
+	inputs resource.PropertyMap) (resource.URN, resource.ID, resource.PropertyMap, error) {
+
+	// marshal inputs
+	ins, err := plugin.MarshalProperties(inputs, plugin.MarshalOptions{KeepUnknowns: true})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// submit request
+	resp, err := rm.resmon.RegisterResource(context.Background(), &pulumirpc.RegisterResourceRequest{
+		Type:    string(t),
+		Name:    name,
+		Custom:  true,
+		Object:  ins,
+		Version: version,
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// unmarshal outputs
+	outs, err := plugin.UnmarshalProperties(resp.Object, plugin.MarshalOptions{KeepUnknowns: true})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return resource.URN(resp.Urn), resource.ID(resp.Id), outs, nil
+}
+
 func (rm *ResourceMonitor) Invoke(tok tokens.ModuleMember,
 	inputs resource.PropertyMap, provider string) (resource.PropertyMap, []*pulumirpc.CheckFailure, error) {
 
@@ -0,0 +1,42 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// DiffSuppression identifies a property whose changes are known to be noise, such as a server-computed timestamp or
+// generation counter. Changes to suppressed properties that a refresh observes are not reported as drift.
+type DiffSuppression struct {
+	Type tokens.Type // the type of resource the suppression applies to, or empty for all resources.
+	Path string      // a dotted path to the suppressed property (e.g. "metadata.generation").
+}
+
+// suppressDiffs returns a copy of news in which each property suppressed for resources of type t has its value from
+// olds, so that comparing the two reports no changes to those properties.
+func suppressDiffs(t tokens.Type, olds, news resource.PropertyMap,
+	suppressions []DiffSuppression) resource.PropertyMap {
+
+	for _, s := range suppressions {
+		if s.Type == "" || s.Type == t {
+			news = ignoreChange(news, olds, strings.Split(s.Path, "."))
+		}
+	}
+	return news
+}
@@ -44,11 +44,31 @@ type BackendClient interface {
 
 // Options controls the planning and deployment process.
 type Options struct {
-	Events            Events // an optional events callback interface.
-	Parallel          int    // the degree of parallelism for resource operations (<=1 for serial).
-	Refresh           bool   // whether or not to refresh before executing the plan.
-	RefreshOnly       bool   // whether or not to exit after refreshing.
-	TrustDependencies bool   // whether or not to trust the resource dependency graph.
+	Events            Events         // an optional events callback interface.
+	Parallel          int            // the degree of parallelism for resource operations (<=1 for serial).
+	Refresh           bool           // whether or not to refresh before executing the plan.
+	RefreshOnly       bool           // whether or not to exit after refreshing.
+	TrustDependencies bool           // whether or not to trust the resource dependency graph.
+	ReplaceTargets    []resource.URN // the URNs of resources to replace regardless of their diffs.
+	FollowPlan        *SavedPlan     // an optional saved plan whose steps the plan must match exactly.
+	SavePlan          *SavedPlan     // an optional saved plan in which to record the plan's steps.
+	ContinueOnError   bool           // whether or not to continue executing independent steps after a step fails.
+	DestroyTargets    []resource.URN // the URNs of resources to destroy, or, if empty, all unproduced resources.
+	Retries           RetryPolicies  // the policies for retrying steps that fail with transient provider errors.
+	Resume            bool           // whether or not to skip resources completed by the failed update being resumed.
+	ExcludeTargets    []resource.URN // the URNs of resources that, with their dependents, the plan must not change.
+
+	// SuppressDiffs lists the properties whose changes a refresh does not report as drift.
+	SuppressDiffs []DiffSuppression
+
+	// ProviderConcurrency optionally limits the number of resource operations that may be in flight at once for each
+	// provider package, keyed by package name (e.g. "aws").
+	ProviderConcurrency map[string]int
+
+	// RolloutPhases optionally splits the deployment into ordered phases, and RolloutPhase is the 1-based index of the
+	// phase to deploy, or 0 to deploy every phase at once.
+	RolloutPhases []RolloutPhase
+	RolloutPhase  int
 }
 
 // DegreeOfParallelism returns the degree of parallelism that should be used during the
@@ -273,7 +293,8 @@ func (p *Plan) generateURN(parent resource.URN, ty tokens.Type, name tokens.QNam
 
 // defaultProviderURN generates the URN for the global provider given a package.
 func defaultProviderURN(target *Target, source Source, pkg tokens.Package) resource.URN {
-	return resource.NewURN(target.Name, source.Project(), "", providers.MakeProviderType(pkg), "default")
+	return resource.NewURN(target.Name, source.Project(), "", providers.MakeProviderType(pkg),
+		providers.DefaultProviderName(nil))
 }
 
 // generateEventURN generates a URN for the resource associated with the given event.
@@ -16,6 +16,7 @@ package deploy
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/diag"
@@ -35,6 +36,8 @@ type planExecutor struct {
 
 	stepGen  *stepGenerator // step generator owned by this plan
 	stepExec *stepExecutor  // step executor owned by this plan
+
+	performed *SavedPlan // the steps that have been checked against opts.FollowPlan, if any
 }
 
 // execError creates an error appropriate for returning from planExecutor.Execute.
@@ -72,6 +75,27 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 		}
 	}()
 
+	// Ensure that any resources that are to be replaced exist in the base checkpoint.
+	for _, urn := range opts.ReplaceTargets {
+		if _, has := pe.plan.Olds()[urn]; !has {
+			return errors.Errorf("cannot replace resource '%s': it does not exist in the stack", urn)
+		}
+	}
+
+	// Likewise, ensure that any resources that are to be destroyed exist in the base checkpoint.
+	for _, urn := range opts.DestroyTargets {
+		if _, has := pe.plan.Olds()[urn]; !has {
+			return errors.Errorf("cannot destroy resource '%s': it does not exist in the stack", urn)
+		}
+	}
+
+	// Likewise, ensure that any resources that are to be excluded exist in the base checkpoint.
+	for _, urn := range opts.ExcludeTargets {
+		if _, has := pe.plan.Olds()[urn]; !has {
+			return errors.Errorf("cannot exclude resource '%s': it does not exist in the stack", urn)
+		}
+	}
+
 	// Before doing anything else, optionally refresh each resource in the base checkpoint.
 	if opts.Refresh {
 		if err := pe.refresh(callerCtx, opts, preview); err != nil {
@@ -101,7 +125,7 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 	ctx, cancel := context.WithCancel(callerCtx)
 
 	// Set up a step generator and executor for this plan.
-	pe.stepExec = newStepExecutor(ctx, cancel, pe.plan, opts, preview, false)
+	pe.stepExec = newStepExecutor(ctx, cancel, pe.plan, opts, preview, opts.ContinueOnError)
 
 	// We iterate the source in its own goroutine because iteration is blocking and we want the main loop to be able to
 	// respond to cancellation requests promptly.
@@ -147,7 +171,22 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 				}
 
 				if event.Event == nil {
+					// If any steps failed, the stack's resources may not reflect the program's desired state, so
+					// no resources are deleted.
+					if pe.stepExec.Errored() {
+						pe.stepExec.SignalCompletion()
+						return false, nil
+					}
+
 					deleteSteps := pe.stepGen.GenerateDeletes()
+
+					// If we are following a saved plan, all of its steps must now have been generated. Check this
+					// before any deletes are performed.
+					if checkErr := pe.checkSteps(opts, deleteSteps, true); checkErr != nil {
+						pe.reportError("", checkErr)
+						cancel()
+						return false, checkErr
+					}
 					deletes := pe.stepGen.ScheduleDeletes(deleteSteps)
 
 					// ScheduleDeletes gives us a list of lists of steps. Each list of steps can safely be executed in
@@ -168,7 +207,7 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 					return false, nil
 				}
 
-				if res := pe.handleSingleEvent(event.Event); res != nil {
+				if res := pe.handleSingleEvent(opts, event.Event); res != nil {
 					if resErr := res.Error(); resErr != nil {
 						logging.V(4).Infof("planExecutor.Execute(...): error handling event: %v", resErr)
 						pe.reportError(pe.plan.generateEventURN(event.Event), resErr)
@@ -189,6 +228,17 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 	pe.stepExec.WaitForCompletion()
 	logging.V(4).Infof("planExecutor.Execute(...): step executor has completed")
 
+	// If we continued past any failures, summarize them.
+	if failed := pe.stepExec.Failed(); len(failed) > 0 {
+		var urns []string
+		for _, urn := range failed {
+			urns = append(urns, string(urn))
+		}
+		pe.reportError("", errors.Errorf(
+			"the following resources failed to deploy, and resources that depend on them were skipped:\n    %s",
+			strings.Join(urns, "\n    ")))
+	}
+
 	// Figure out if execution failed and why. Step generation and execution errors trump cancellation.
 	if err != nil || pe.stepExec.Errored() {
 		err = execError("failed", preview)
@@ -200,7 +250,7 @@ func (pe *planExecutor) Execute(callerCtx context.Context, opts Options, preview
 
 // handleSingleEvent handles a single source event. For all incoming events, it produces a chain that needs
 // to be executed and schedules the chain for execution.
-func (pe *planExecutor) handleSingleEvent(event SourceEvent) *result.Result {
+func (pe *planExecutor) handleSingleEvent(opts Options, event SourceEvent) *result.Result {
 	contract.Require(event != nil, "event != nil")
 
 	var steps []Step
@@ -221,11 +271,40 @@ func (pe *planExecutor) handleSingleEvent(event SourceEvent) *result.Result {
 	if res != nil {
 		return res
 	}
+	if err := pe.checkSteps(opts, steps, false); err != nil {
+		return result.FromError(err)
+	}
 
 	pe.stepExec.ExecuteSerial(steps)
 	return nil
 }
 
+// checkSteps records the given steps in opts.SavePlan and checks them against opts.FollowPlan, if either is set. If
+// last is true, no further steps will be generated, so it is also an error for any steps in opts.FollowPlan to remain
+// unperformed.
+func (pe *planExecutor) checkSteps(opts Options, steps []Step, last bool) error {
+	if opts.SavePlan != nil {
+		for _, step := range steps {
+			opts.SavePlan.recordStep(step)
+		}
+	}
+
+	if opts.FollowPlan != nil {
+		if pe.performed == nil {
+			pe.performed = NewSavedPlan()
+		}
+		for _, step := range steps {
+			if err := opts.FollowPlan.checkStep(pe.performed, step); err != nil {
+				return err
+			}
+		}
+		if last {
+			return opts.FollowPlan.checkComplete(pe.performed)
+		}
+	}
+	return nil
+}
+
 // retirePendingDeletes deletes all resources that are pending deletion. Run before the start of a plan, this pass
 // ensures that the engine never sees any resources that are pending deletion from a previous plan.
 //
@@ -277,7 +356,7 @@ func (pe *planExecutor) refresh(callerCtx context.Context, opts Options, preview
 	// Create a refresh step for each resource in the old snapshot.
 	steps := make([]Step, len(prev.Resources))
 	for i := range prev.Resources {
-		steps[i] = NewRefreshStep(pe.plan, prev.Resources[i], nil)
+		steps[i] = NewRefreshStep(pe.plan, prev.Resources[i], opts.SuppressDiffs, nil)
 	}
 
 	// Fire up a worker pool and issue each refresh in turn.
@@ -15,8 +15,10 @@
 package providers
 
 import (
+	"regexp"
 	"strings"
 
+	"github.com/blang/semver"
 	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/resource"
@@ -52,6 +54,41 @@ func GetProviderPackage(typ tokens.Type) tokens.Package {
 	return tokens.Package(typ.Name())
 }
 
+// defaultProviderName is the name of the default provider for a package at the version that the program requires.
+const defaultProviderName = "default"
+
+var defaultProviderVersionReplacer = regexp.MustCompile("[^A-Za-z0-9]")
+
+// versionedDefaultProviderName matches the names that DefaultProviderName generates for non-nil versions.
+var versionedDefaultProviderName = regexp.MustCompile(
+	"^" + defaultProviderName + "_[0-9]+_[0-9]+_[0-9]+(_[A-Za-z0-9_]+)?$")
+
+// DefaultProviderName returns the name of the default provider for a package. The default provider at the version
+// that the program requires is named "default". Default providers at other versions, which are requested by packages
+// that were built against different versions of the provider, are named after their version, e.g. "default_1_2_3".
+func DefaultProviderName(version *semver.Version) tokens.QName {
+	if version == nil {
+		return defaultProviderName
+	}
+	suffix := defaultProviderVersionReplacer.ReplaceAllString(version.String(), "_")
+	return tokens.QName(defaultProviderName + "_" + suffix)
+}
+
+// IsDefaultProviderName returns true if the given name is one that DefaultProviderName may generate. These names are
+// reserved for default providers: the resource monitor refuses to register a provider with such a name on behalf of a
+// program, so a user-defined provider may be named e.g. "default_east" but not "default" or "default_1_2_3".
+func IsDefaultProviderName(name tokens.QName) bool {
+	return name == defaultProviderName || versionedDefaultProviderName.MatchString(string(name))
+}
+
+// IsDefaultProvider returns true if the given URN refers to a default provider.
+func IsDefaultProvider(urn resource.URN) bool {
+	if !IsProviderType(urn.Type()) {
+		return false
+	}
+	return IsDefaultProviderName(urn.Name())
+}
+
 func validateURN(urn resource.URN) error {
 	typ := urn.Type()
 	if typ.Module() != "pulumi:providers" {
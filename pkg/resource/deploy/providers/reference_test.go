@@ -17,6 +17,7 @@ package providers
 import (
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/pulumi/pulumi/pkg/resource"
@@ -74,3 +75,19 @@ func TestRoundTripReference(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, str, ref.String())
 }
+
+func TestDefaultProviderName(t *testing.T) {
+	version := semver.MustParse("1.2.3-alpha.1")
+	assert.Equal(t, tokens.QName("default"), DefaultProviderName(nil))
+	assert.Equal(t, tokens.QName("default_1_2_3_alpha_1"), DefaultProviderName(&version))
+
+	for _, name := range []tokens.QName{DefaultProviderName(nil), DefaultProviderName(&version)} {
+		assert.True(t, IsDefaultProvider(resource.NewURN("test", "test", "", "pulumi:providers:type", name)))
+		assert.False(t, IsDefaultProvider(resource.NewURN("test", "test", "", "pkg:index:type", name)))
+	}
+	assert.False(t, IsDefaultProvider(resource.NewURN("test", "test", "", "pulumi:providers:type", "defaults")))
+	assert.False(t, IsDefaultProvider(resource.NewURN("test", "test", "", "pulumi:providers:type", "default_east")))
+	assert.False(t, IsDefaultProvider(resource.NewURN("test", "test", "", "pulumi:providers:type", "default_1_2")))
+	assert.True(t, IsDefaultProviderName("default_1_2_3"))
+	assert.False(t, IsDefaultProviderName("default_us_west_2"))
+}
@@ -17,6 +17,7 @@ package providers
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/tokens"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/workspace"
 )
@@ -120,7 +122,7 @@ func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool,
 		}
 		provider, err := loadProvider(providerPkg, version, host, builtins)
 		if err != nil {
-			return nil, errors.Errorf("could not load plugin for %v provider '%v': %v", providerPkg, urn, err)
+			return nil, errors.Wrapf(err, "could not load plugin for %v provider '%v'", providerPkg, urn)
 		}
 		if provider == nil {
 			return nil, errors.Errorf("could not find plugin for %v provider '%v' at version %v", providerPkg, urn, version)
@@ -128,7 +130,9 @@ func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool,
 		if err := provider.Configure(res.Inputs); err != nil {
 			closeErr := host.CloseProvider(provider)
 			contract.IgnoreError(closeErr)
-			return nil, errors.Errorf("could not configure provider '%v': %v", urn, err)
+			return nil, errutil.WithCode(errutil.ErrProviderConfigureFailed,
+				"check the provider's settings in the stack's configuration with `pulumi config`",
+				errors.Errorf("could not configure provider '%v': %v", urn, err))
 		}
 
 		logging.V(7).Infof("loaded provider %v", ref)
@@ -305,8 +309,8 @@ func (r *Registry) Diff(urn resource.URN, id resource.ID, olds, news resource.Pr
 // registers it under the assigned (URN, ID).
 //
 // The provider must have been loaded by a prior call to Check.
-func (r *Registry) Create(urn resource.URN,
-	news resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error) {
+func (r *Registry) Create(urn resource.URN, news resource.PropertyMap,
+	timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
 
 	contract.Assert(!r.isPreview)
 
@@ -332,8 +336,8 @@ func (r *Registry) Create(urn resource.URN,
 // reference indicated by the (URN, ID) pair.
 //
 // THe provider must have been loaded by a prior call to Check.
-func (r *Registry) Update(urn resource.URN, id resource.ID, olds,
-	news resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+func (r *Registry) Update(urn resource.URN, id resource.ID, olds, news resource.PropertyMap,
+	timeout float64) (resource.PropertyMap, resource.Status, error) {
 
 	contract.Assert(!r.isPreview)
 
@@ -355,7 +359,8 @@ func (r *Registry) Update(urn resource.URN, id resource.ID, olds,
 
 // Delete unregisters and unloads the provider with the given URN and ID. The provider must have been loaded when the
 // registry was created (i.e. it must have been present in the state handed to NewRegistry).
-func (r *Registry) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap) (resource.Status, error) {
+func (r *Registry) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
 	contract.Assert(!r.isPreview)
 
 	ref := mustNewReference(urn, id)
@@ -386,6 +391,17 @@ func (r *Registry) GetPluginInfo() (workspace.PluginInfo, error) {
 	return workspace.PluginInfo{}, errors.New("the provider registry does not report plugin info")
 }
 
+func (r *Registry) GetSchema(version int) ([]byte, error) {
+	// return an error: this should not be called for the provider registry
+	return nil, errors.New("the provider registry does not publish a schema")
+}
+
+func (r *Registry) GetLogs(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	startTime, endTime *time.Time) ([]plugin.LogEntry, error) {
+	// Provider resources have no logs of their own.
+	return nil, nil
+}
+
 func (r *Registry) SignalCancellation() error {
 	// At the moment there isn't anything reasonable we can do here. In the future, it might be nice to plumb
 	// cancellation through the plugin loader and cancel any outstanding load requests here.
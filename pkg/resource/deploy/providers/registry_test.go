@@ -16,6 +16,7 @@ package providers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
@@ -109,7 +110,7 @@ func (prov *testProvider) Check(urn resource.URN,
 	olds, news resource.PropertyMap, _ bool) (resource.PropertyMap, []plugin.CheckFailure, error) {
 	return nil, nil, errors.New("unsupported")
 }
-func (prov *testProvider) Create(urn resource.URN, props resource.PropertyMap) (resource.ID,
+func (prov *testProvider) Create(urn resource.URN, props resource.PropertyMap, timeout float64) (resource.ID,
 	resource.PropertyMap, resource.Status, error) {
 	return "", nil, resource.StatusOK, errors.New("unsupported")
 }
@@ -121,12 +122,12 @@ func (prov *testProvider) Diff(urn resource.URN, id resource.ID,
 	olds resource.PropertyMap, news resource.PropertyMap, _ bool) (plugin.DiffResult, error) {
 	return plugin.DiffResult{}, errors.New("unsupported")
 }
-func (prov *testProvider) Update(urn resource.URN, id resource.ID,
-	olds resource.PropertyMap, news resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+func (prov *testProvider) Update(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+	news resource.PropertyMap, timeout float64) (resource.PropertyMap, resource.Status, error) {
 	return nil, resource.StatusOK, errors.New("unsupported")
 }
-func (prov *testProvider) Delete(urn resource.URN,
-	id resource.ID, props resource.PropertyMap) (resource.Status, error) {
+func (prov *testProvider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
 	return resource.StatusOK, errors.New("unsupported")
 }
 func (prov *testProvider) Invoke(tok tokens.ModuleMember,
@@ -139,6 +140,13 @@ func (prov *testProvider) GetPluginInfo() (workspace.PluginInfo, error) {
 		Version: &prov.version,
 	}, nil
 }
+func (prov *testProvider) GetSchema(version int) ([]byte, error) {
+	return nil, errors.New("unsupported")
+}
+func (prov *testProvider) GetLogs(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	startTime, endTime *time.Time) ([]plugin.LogEntry, error) {
+	return nil, errors.New("unsupported")
+}
 
 type providerLoader struct {
 	pkg     tokens.Package
@@ -423,7 +431,7 @@ func TestCRUD(t *testing.T) {
 		assert.False(t, p.(*testProvider).configured)
 
 		// Create
-		id, outs, status, err := r.Create(urn, inputs)
+		id, outs, status, err := r.Create(urn, inputs, 0)
 		assert.NoError(t, err)
 		assert.NotEqual(t, "", id)
 		assert.NotEqual(t, UnknownID, id)
@@ -468,7 +476,7 @@ func TestCRUD(t *testing.T) {
 		assert.Equal(t, old, p2)
 
 		// Update
-		outs, status, err := r.Update(urn, id, olds, inputs)
+		outs, status, err := r.Update(urn, id, olds, inputs, 0)
 		assert.NoError(t, err)
 		assert.Equal(t, resource.PropertyMap{}, outs)
 		assert.Equal(t, resource.StatusOK, status)
@@ -488,7 +496,7 @@ func TestCRUD(t *testing.T) {
 		assert.True(t, ok)
 
 		// Delete
-		status, err := r.Delete(urn, id, resource.PropertyMap{})
+		status, err := r.Delete(urn, id, resource.PropertyMap{}, 0)
 		assert.NoError(t, err)
 		assert.Equal(t, resource.StatusOK, status)
 
@@ -0,0 +1,112 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil/rpcerror"
+)
+
+// DefaultRetryPolicy is the retry policy used for provider operations when no other policy has been configured. Retries
+// must be opted into by raising MaxAttempts, as an operation that is retried may not be safe to repeat.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  1,
+	InitialDelay: time.Second,
+	MaxDelay:     30 * time.Second,
+}
+
+// RetryPolicy controls how a step whose provider operation failed with a transient error is retried. Delays between
+// attempts grow exponentially from InitialDelay, and are capped at MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts  int           // the maximum number of attempts, including the first (<=1 disables retries).
+	InitialDelay time.Duration // the delay before the first retry.
+	MaxDelay     time.Duration // the upper bound on the delay between attempts (<=0 for no bound).
+}
+
+// Delay returns the delay to wait before making the given attempt, where attempt 1 is the first retry.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.InitialDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// RetryPolicies is the set of retry policies in effect for a plan. Overrides are keyed either by a provider package
+// (e.g. "aws") or by a provider package and step operation (e.g. "aws:create"); the most specific match wins. Budgets
+// are keyed by provider package, and cap the total number of retries of that package's steps over the whole plan.
+type RetryPolicies struct {
+	Default   RetryPolicy            // the policy used when no override matches.
+	Overrides map[string]RetryPolicy // policies for specific packages or package operations.
+	Budgets   map[string]int         // the most retries allowed for each package's steps (absent for no limit).
+}
+
+// PolicyFor returns the retry policy that applies to the given step.
+func (ps RetryPolicies) PolicyFor(step Step) RetryPolicy {
+	pkg := stepPackage(step)
+	if p, has := ps.Overrides[pkg+":"+string(step.Op())]; has {
+		return p
+	}
+	if p, has := ps.Overrides[pkg]; has {
+		return p
+	}
+	return ps.Default
+}
+
+// stepPackage returns the name of the package that a step's resource type belongs to.
+func stepPackage(step Step) string {
+	// Component types needn't be well-formed module member tokens, so split off the package by hand.
+	return strings.SplitN(string(step.Type()), ":", 2)[0]
+}
+
+// isTransientStepError returns true if a step failed in a way that is safe to retry: the provider reported that the
+// resource was left untouched, and the error is one that typically clears up on its own. Steps that only read
+// resources may be retried after any rate limit or unavailable service. Steps that modify resources are only retried
+// after a rate limit, or when the provider has marked the error as retryable by attaching a RetryInfo detail to it,
+// as codes.Unavailable is also what the engine sees when a provider crashes part way through an operation.
+func isTransientStepError(op StepOp, status resource.Status, err error) bool {
+	if err == nil || status != resource.StatusOK {
+		return false
+	}
+	rpcErr, ok := rpcerror.FromError(err)
+	if !ok {
+		return false
+	}
+	for _, detail := range rpcErr.Details() {
+		if _, ok := detail.(*errdetails.RetryInfo); ok {
+			return true
+		}
+	}
+	switch rpcErr.Code() {
+	case codes.ResourceExhausted:
+		return true
+	case codes.Unavailable:
+		return op == OpRead || op == OpReadReplacement || op == OpRefresh || op == OpImport
+	default:
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/util/rpcutil/rpcerror"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, p.Delay(1))
+	assert.Equal(t, 2*time.Second, p.Delay(2))
+	assert.Equal(t, 4*time.Second, p.Delay(3))
+	assert.Equal(t, 5*time.Second, p.Delay(4))
+	assert.Equal(t, 5*time.Second, p.Delay(50))
+
+	p.MaxDelay = 0
+	assert.Equal(t, 8*time.Second, p.Delay(4))
+}
+
+func TestRetryPolicyFor(t *testing.T) {
+	urn := resource.URN("urn:pulumi:stack::proj::aws:s3/bucket:Bucket::b")
+	create := &CreateStep{new: &resource.State{URN: urn, Type: "aws:s3/bucket:Bucket"}}
+	del := &DeleteStep{old: &resource.State{URN: urn, Type: "aws:s3/bucket:Bucket"}}
+	other := &CreateStep{new: &resource.State{URN: urn, Type: "gcp:storage:Bucket"}}
+
+	policies := RetryPolicies{
+		Default: RetryPolicy{MaxAttempts: 1},
+		Overrides: map[string]RetryPolicy{
+			"aws":        {MaxAttempts: 2},
+			"aws:create": {MaxAttempts: 3},
+		},
+	}
+	assert.Equal(t, 3, policies.PolicyFor(create).MaxAttempts)
+	assert.Equal(t, 2, policies.PolicyFor(del).MaxAttempts)
+	assert.Equal(t, 1, policies.PolicyFor(other).MaxAttempts)
+}
+
+func TestIsTransientStepError(t *testing.T) {
+	throttled := rpcerror.New(codes.ResourceExhausted, "throttled")
+	unavailable := rpcerror.New(codes.Unavailable, "try again")
+	assert.False(t, isTransientStepError(OpCreate, resource.StatusOK, nil))
+	assert.True(t, isTransientStepError(OpCreate, resource.StatusOK, throttled))
+	assert.False(t, isTransientStepError(OpCreate, resource.StatusOK, rpcerror.New(codes.InvalidArgument, "bad")))
+	assert.False(t, isTransientStepError(OpCreate, resource.StatusUnknown, throttled))
+	assert.False(t, isTransientStepError(OpCreate, resource.StatusOK, errors.New("plain error")))
+
+	// An unavailable provider may have crashed part way through an operation, so only reads are retried.
+	assert.True(t, isTransientStepError(OpRead, resource.StatusOK, unavailable))
+	assert.True(t, isTransientStepError(OpRefresh, resource.StatusOK, unavailable))
+	assert.False(t, isTransientStepError(OpCreate, resource.StatusOK, unavailable))
+	assert.False(t, isTransientStepError(OpUpdate, resource.StatusOK, unavailable))
+	assert.False(t, isTransientStepError(OpDelete, resource.StatusOK, unavailable))
+
+	// Unless the provider has marked the error as retryable.
+	retryable := rpcerror.WithDetails(unavailable, &errdetails.RetryInfo{})
+	assert.True(t, isTransientStepError(OpCreate, resource.StatusOK, retryable))
+	assert.False(t, isTransientStepError(OpCreate, resource.StatusUnknown, retryable))
+}
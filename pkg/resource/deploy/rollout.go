@@ -0,0 +1,55 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"path"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+)
+
+// RolloutPhase is one phase of a staged rollout. Each phase is deployed by its own plan, during which changes to
+// existing resources that belong to later phases are held back.
+type RolloutPhase struct {
+	Resources []string // glob patterns matched against resource names, or empty to match every resource.
+}
+
+// rolloutPhaseOf returns the index of the phase to which the resource with the given URN belongs: the first phase
+// with a pattern that matches its name, or else the last phase.
+func rolloutPhaseOf(urn resource.URN, phases []RolloutPhase) int {
+	name := string(urn.Name())
+	for i, phase := range phases {
+		if len(phase.Resources) == 0 {
+			return i
+		}
+		for _, pattern := range phase.Resources {
+			if matched, err := path.Match(pattern, name); err == nil && matched {
+				return i
+			}
+		}
+	}
+	return len(phases) - 1
+}
+
+// isHeld returns true if changes to the given existing resource must be held back because it belongs to a later phase
+// of the rollout that this plan is deploying. Only custom resources are held: providers and components do not
+// themselves change any infrastructure.
+func isHeld(old *resource.State, opts Options) bool {
+	if opts.RolloutPhase == 0 || !old.Custom || providers.IsProviderType(old.Type) {
+		return false
+	}
+	return rolloutPhaseOf(old.URN, opts.RolloutPhases) >= opts.RolloutPhase
+}
@@ -0,0 +1,162 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+// SavedPlan records the steps that a plan computed for each resource, so that a later plan can be constrained to
+// perform exactly the same steps. This allows an update to apply precisely the changes that were reviewed in a
+// preview: if the program or the live state of the stack has since changed such that different steps would be taken,
+// the update fails.
+type SavedPlan struct {
+	Resources map[resource.URN]*PlannedResource // the steps planned for each resource.
+}
+
+// PlannedResource records the steps planned for a single resource.
+type PlannedResource struct {
+	Ops    []StepOp             // the operations planned for the resource, in the order they were planned.
+	Inputs resource.PropertyMap // the planned inputs of the resource, if any. Unknown values match any value.
+}
+
+// NewSavedPlan creates a new, empty saved plan.
+func NewSavedPlan() *SavedPlan {
+	return &SavedPlan{Resources: make(map[resource.URN]*PlannedResource)}
+}
+
+// recordStep records the given step in the plan.
+func (p *SavedPlan) recordStep(step Step) {
+	r, has := p.Resources[step.URN()]
+	if !has {
+		r = &PlannedResource{}
+		p.Resources[step.URN()] = r
+	}
+	r.Ops = append(r.Ops, step.Op())
+	if r.Inputs == nil && step.New() != nil {
+		r.Inputs = step.New().Inputs.Copy()
+	}
+}
+
+// checkStep returns an error if the given step is not the next step planned for its resource, given the steps that
+// have already been performed. If the step matches the plan, it is recorded in performed.
+func (p *SavedPlan) checkStep(performed *SavedPlan, step Step) error {
+	urn := step.URN()
+	planned, has := p.Resources[urn]
+	if !has {
+		return errors.Errorf("resource '%s' violates the plan: planned no operations, but got %s", urn, step.Op())
+	}
+
+	var next int
+	if r, has := performed.Resources[urn]; has {
+		next = len(r.Ops)
+	}
+	if next >= len(planned.Ops) {
+		return errors.Errorf("resource '%s' violates the plan: planned %s, but got an additional %s",
+			urn, describeOps(planned.Ops), step.Op())
+	}
+	// A resource whose inputs were not known when the plan was computed is planned as an update, but may turn out not
+	// to have changed. Such a resource is left untouched, which is always within the bounds of the plan.
+	if planned.Ops[next] != step.Op() && !(planned.Ops[next] == OpUpdate && step.Op() == OpSame) {
+		return errors.Errorf("resource '%s' violates the plan: planned %s, but got %s",
+			urn, planned.Ops[next], step.Op())
+	}
+
+	if step.New() != nil && planned.Inputs != nil && !plannedValueMatches(
+		resource.NewObjectProperty(planned.Inputs), resource.NewObjectProperty(step.New().Inputs)) {
+		return errors.Errorf("resource '%s' violates the plan: its inputs differ from the planned inputs", urn)
+	}
+
+	performed.recordStep(step)
+	return nil
+}
+
+// checkComplete returns an error if any of the steps in the plan have not been performed.
+func (p *SavedPlan) checkComplete(performed *SavedPlan) error {
+	for _, urn := range p.sortedURNs() {
+		planned := p.Resources[urn]
+
+		var next int
+		if r, has := performed.Resources[urn]; has {
+			next = len(r.Ops)
+		}
+		if next < len(planned.Ops) {
+			return errors.Errorf("resource '%s' violates the plan: planned %s, but it was not performed",
+				urn, describeOps(planned.Ops[next:]))
+		}
+	}
+	return nil
+}
+
+// sortedURNs returns the URNs of the resources in the plan in a stable order.
+func (p *SavedPlan) sortedURNs() []resource.URN {
+	var urns []resource.URN
+	for urn := range p.Resources {
+		urns = append(urns, urn)
+	}
+	sort.Slice(urns, func(i, j int) bool { return urns[i] < urns[j] })
+	return urns
+}
+
+// describeOps renders a list of operations for use in error messages.
+func describeOps(ops []StepOp) string {
+	strs := make([]string, len(ops))
+	for i, op := range ops {
+		strs[i] = string(op)
+	}
+	return strings.Join(strs, ", ")
+}
+
+// plannedValueMatches returns true if the actual value matches the planned value. Unknown planned values, which
+// arise from outputs that are not known until resources are created or updated, match any value.
+func plannedValueMatches(planned, actual resource.PropertyValue) bool {
+	switch {
+	case planned.IsComputed() || planned.IsOutput():
+		return true
+	case planned.IsArray():
+		if !actual.IsArray() || len(planned.ArrayValue()) != len(actual.ArrayValue()) {
+			return false
+		}
+		for i, elem := range planned.ArrayValue() {
+			if !plannedValueMatches(elem, actual.ArrayValue()[i]) {
+				return false
+			}
+		}
+		return true
+	case planned.IsObject():
+		if !actual.IsObject() {
+			return false
+		}
+		plannedObj, actualObj := planned.ObjectValue(), actual.ObjectValue()
+		for k, v := range plannedObj {
+			if !plannedValueMatches(v, actualObj[k]) {
+				return false
+			}
+		}
+		for k, v := range actualObj {
+			if _, has := plannedObj[k]; !has && v.HasValue() {
+				return false
+			}
+		}
+		return true
+	default:
+		return planned.DeepEquals(actual)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+func TestPlannedValueMatches(t *testing.T) {
+	unknown := resource.MakeComputed(resource.NewStringProperty(""))
+	planned := resource.NewObjectProperty(resource.PropertyMap{
+		"name": resource.NewStringProperty("foo"),
+		"id":   unknown,
+		"tags": resource.NewArrayProperty([]resource.PropertyValue{resource.NewStringProperty("a"), unknown}),
+	})
+
+	// Unknown planned values match any value.
+	assert.True(t, plannedValueMatches(planned, resource.NewObjectProperty(resource.PropertyMap{
+		"name": resource.NewStringProperty("foo"),
+		"id":   resource.NewStringProperty("i-1234"),
+		"tags": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewStringProperty("a"), resource.NewStringProperty("b"),
+		}),
+	})))
+
+	// Known planned values must match exactly.
+	assert.False(t, plannedValueMatches(planned, resource.NewObjectProperty(resource.PropertyMap{
+		"name": resource.NewStringProperty("bar"),
+		"id":   resource.NewStringProperty("i-1234"),
+		"tags": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewStringProperty("a"), resource.NewStringProperty("b"),
+		}),
+	})))
+
+	// As must the lengths of arrays and the keys of objects.
+	assert.False(t, plannedValueMatches(planned, resource.NewObjectProperty(resource.PropertyMap{
+		"name": resource.NewStringProperty("foo"),
+		"id":   resource.NewStringProperty("i-1234"),
+		"tags": resource.NewArrayProperty([]resource.PropertyValue{resource.NewStringProperty("a")}),
+	})))
+	assert.False(t, plannedValueMatches(planned, resource.NewObjectProperty(resource.PropertyMap{
+		"name":  resource.NewStringProperty("foo"),
+		"id":    resource.NewStringProperty("i-1234"),
+		"tags":  resource.NewArrayProperty([]resource.PropertyValue{resource.NewStringProperty("a"), unknown}),
+		"extra": resource.NewBoolProperty(true),
+	})))
+}
@@ -33,6 +33,10 @@ type Snapshot struct {
 	Manifest          Manifest             // a deployment manifest of versions, checksums, and so on.
 	Resources         []*resource.State    // fetches all resources and their associated states.
 	PendingOperations []resource.Operation // all currently pending resource operations.
+
+	// CompletedResources are the resources whose operations completed successfully during the update that produced
+	// this snapshot. If that update failed, resuming it need not revisit these resources.
+	CompletedResources []resource.URN
 }
 
 // Manifest captures versions for all binaries used to construct this snapshot.
@@ -0,0 +1,98 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+// SnapshotDiff describes the differences between the resources recorded in two snapshots.
+type SnapshotDiff struct {
+	Adds    []*resource.State // resources present only in the new snapshot, in the new snapshot's order.
+	Deletes []*resource.State // resources present only in the old snapshot, in the old snapshot's order.
+	Updates []ResourceDiff    // resources present in both snapshots whose state differs, in the new snapshot's order.
+}
+
+// AnyChanges returns true if the two snapshots differ in any way.
+func (diff *SnapshotDiff) AnyChanges() bool {
+	return len(diff.Adds) > 0 || len(diff.Deletes) > 0 || len(diff.Updates) > 0
+}
+
+// ResourceDiff describes the differences between two recorded states of a single resource.
+type ResourceDiff struct {
+	Old      *resource.State      // the resource's state in the old snapshot.
+	New      *resource.State      // the resource's state in the new snapshot.
+	Inputs   *resource.ObjectDiff // the differences between the resource's inputs, or nil if there are none.
+	Outputs  *resource.ObjectDiff // the differences between the resource's outputs, or nil if there are none.
+	Replaced bool                 // true if the resource's ID changed, meaning it was replaced in between.
+}
+
+// DiffSnapshots compares the resources recorded in two snapshots, matching them up by URN. Resources that are pending
+// deletion are ignored, since they do not reflect the desired state of either snapshot. Either snapshot may be nil,
+// in which case it is treated as having no resources.
+func DiffSnapshots(old, new *Snapshot) *SnapshotDiff {
+	oldResources, newResources := liveResources(old), liveResources(new)
+
+	oldByURN := make(map[resource.URN]*resource.State)
+	for _, res := range oldResources {
+		oldByURN[res.URN] = res
+	}
+	newByURN := make(map[resource.URN]*resource.State)
+	for _, res := range newResources {
+		newByURN[res.URN] = res
+	}
+
+	diff := &SnapshotDiff{}
+	for _, res := range newResources {
+		prior, has := oldByURN[res.URN]
+		if !has {
+			diff.Adds = append(diff.Adds, res)
+			continue
+		}
+
+		rd := ResourceDiff{
+			Old:      prior,
+			New:      res,
+			Inputs:   prior.Inputs.Diff(res.Inputs),
+			Outputs:  prior.Outputs.Diff(res.Outputs),
+			Replaced: prior.ID != res.ID,
+		}
+		if rd.Inputs != nil || rd.Outputs != nil || rd.Replaced {
+			diff.Updates = append(diff.Updates, rd)
+		}
+	}
+	for _, res := range oldResources {
+		if _, has := newByURN[res.URN]; !has {
+			diff.Deletes = append(diff.Deletes, res)
+		}
+	}
+
+	return diff
+}
+
+// liveResources returns the resources in the given snapshot that are not pending deletion.
+func liveResources(snap *Snapshot) []*resource.State {
+	if snap == nil {
+		return nil
+	}
+
+	var resources []*resource.State
+	for _, res := range snap.Resources {
+		if !res.Delete {
+			resources = append(resources, res)
+		}
+	}
+	return resources
+}
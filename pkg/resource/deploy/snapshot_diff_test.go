@@ -0,0 +1,71 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+func TestDiffSnapshotsEmpty(t *testing.T) {
+	diff := DiffSnapshots(nil, newSnapshot(nil, nil))
+	assert.False(t, diff.AnyChanges())
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	unchanged := newResource("unchanged")
+
+	oldUpdated := newResource("updated")
+	oldUpdated.Inputs["foo"] = resource.NewStringProperty("bar")
+	newUpdated := newResource("updated")
+	newUpdated.Inputs["foo"] = resource.NewStringProperty("baz")
+
+	oldReplaced := newResource("replaced")
+	oldReplaced.ID = "id-1"
+	newReplaced := newResource("replaced")
+	newReplaced.ID = "id-2"
+
+	deleted := newResource("deleted")
+	added := newResource("added")
+
+	pendingDelete := newResource("pending")
+	pendingDelete.Delete = true
+
+	old := newSnapshot([]*resource.State{unchanged, oldUpdated, oldReplaced, deleted}, nil)
+	new := newSnapshot([]*resource.State{unchanged, added, newUpdated, newReplaced, pendingDelete}, nil)
+
+	diff := DiffSnapshots(old, new)
+	assert.True(t, diff.AnyChanges())
+
+	if assert.Len(t, diff.Adds, 1) {
+		assert.Equal(t, added.URN, diff.Adds[0].URN)
+	}
+	if assert.Len(t, diff.Deletes, 1) {
+		assert.Equal(t, deleted.URN, diff.Deletes[0].URN)
+	}
+	if assert.Len(t, diff.Updates, 2) {
+		assert.Equal(t, newUpdated.URN, diff.Updates[0].New.URN)
+		assert.False(t, diff.Updates[0].Replaced)
+		assert.NotNil(t, diff.Updates[0].Inputs)
+		assert.True(t, diff.Updates[0].Inputs.Updated("foo"))
+		assert.Nil(t, diff.Updates[0].Outputs)
+
+		assert.Equal(t, newReplaced.URN, diff.Updates[1].New.URN)
+		assert.True(t, diff.Updates[1].Replaced)
+	}
+}
@@ -63,6 +63,7 @@ type RegisterResourceEvent interface {
 	// Goal returns the goal state for the resource object that was allocated by the program.
 	Goal() *resource.Goal
 	// Done indicates that we are done with this step.  It must be called to perform cleanup associated with the step.
+	// A nil result indicates that the resource could not be registered. Only the first call to Done has any effect.
 	Done(result *RegisterResult)
 }
 
@@ -102,7 +103,8 @@ type ReadResourceEvent interface {
 	Properties() resource.PropertyMap
 	// Dependencies returns the list of URNs upon which this read depends.
 	Dependencies() []resource.URN
-	// Done indicates that we are done with this event.
+	// Done indicates that we are done with this event. A nil result indicates that the resource could not be read.
+	// Only the first call to Done has any effect.
 	Done(result *ReadResult)
 }
 
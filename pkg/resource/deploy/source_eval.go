@@ -17,6 +17,8 @@ package deploy
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/blang/semver"
 	pbempty "github.com/golang/protobuf/ptypes/empty"
@@ -213,9 +215,14 @@ func (iter *evalSourceIterator) forkRun(opts Options) {
 // defaultProviders manages the registration of default providers. The default provider for a package is the provider
 // resource that will be used to manage resources that do not explicitly reference a provider. Default providers will
 // only be registered for packages that are used by resources registered by the user's Pulumi program.
+//
+// A package may have more than one default provider: resources, reads, and invokes may request a particular version of
+// their package's provider, e.g. because the library that registers them was built against that version, and each
+// version that is requested gets a default provider of its own. Requests that do not specify a version use the version
+// that the program requires.
 type defaultProviders struct {
 	versions  map[tokens.Package]*semver.Version
-	providers map[tokens.Package]providers.Reference
+	providers map[defaultProviderKey]providers.Reference
 	config    plugin.ConfigSource
 
 	requests chan defaultProviderRequest
@@ -230,13 +237,22 @@ type defaultProviderResponse struct {
 
 type defaultProviderRequest struct {
 	pkg      tokens.Package
+	version  *semver.Version
 	response chan<- defaultProviderResponse
 }
 
+// defaultProviderKey identifies a default provider by its package and version. The version is empty for the default
+// provider at the version that the program requires.
+type defaultProviderKey struct {
+	pkg     tokens.Package
+	version string
+}
+
 // newRegisterDefaultProviderEvent creates a RegisterResourceEvent and completion channel that can be sent to the
-// engine to register a default provider resource for the indicated package.
+// engine to register a default provider resource for the indicated package and version. If the version is nil, the
+// provider uses the version that the program requires.
 func (d *defaultProviders) newRegisterDefaultProviderEvent(
-	pkg tokens.Package) (*registerResourceEvent, <-chan *RegisterResult, error) {
+	pkg tokens.Package, version *semver.Version) (*registerResourceEvent, <-chan *RegisterResult, error) {
 
 	// Attempt to get the config for the package.
 	cfg, err := d.config.GetPackageConfig(pkg)
@@ -249,14 +265,18 @@ func (d *defaultProviders) newRegisterDefaultProviderEvent(
 	for k, v := range cfg {
 		inputs[resource.PropertyKey(k.Name())] = resource.NewStringProperty(v)
 	}
-	if version := d.versions[pkg]; version != nil {
+	if version != nil {
 		inputs["version"] = resource.NewStringProperty(version.String())
+	} else if required := d.versions[pkg]; required != nil {
+		inputs["version"] = resource.NewStringProperty(required.String())
 	}
 
 	// Create the result channel and the event.
 	done := make(chan *RegisterResult)
+	name := providers.DefaultProviderName(version)
 	event := &registerResourceEvent{
-		goal: resource.NewGoal(providers.MakeProviderType(pkg), "default", true, inputs, "", false, nil, "", nil, nil, false),
+		goal: resource.NewGoal(providers.MakeProviderType(pkg), name, true, inputs, "", false, nil, "", nil, nil,
+			false, nil, resource.CustomTimeouts{}, ""),
 		done: done,
 	}
 	return event, done, nil
@@ -269,15 +289,26 @@ func (d *defaultProviders) newRegisterDefaultProviderEvent(
 //
 // Note that this function must not be called from two goroutines concurrently; it is the responsibility of d.serve()
 // to ensure this.
-func (d *defaultProviders) handleRequest(pkg tokens.Package) (providers.Reference, error) {
-	logging.V(5).Infof("handling default provider request for package %s", pkg)
+func (d *defaultProviders) handleRequest(pkg tokens.Package, version *semver.Version) (providers.Reference, error) {
+	logging.V(5).Infof("handling default provider request for package %s (version %v)", pkg, version)
+
+	// Requests for the version that the program requires share its default provider.
+	if version != nil {
+		if required := d.versions[pkg]; required != nil && required.EQ(*version) {
+			version = nil
+		}
+	}
+	key := defaultProviderKey{pkg: pkg}
+	if version != nil {
+		key.version = version.String()
+	}
 
-	ref, ok := d.providers[pkg]
+	ref, ok := d.providers[key]
 	if ok {
 		return ref, nil
 	}
 
-	event, done, err := d.newRegisterDefaultProviderEvent(pkg)
+	event, done, err := d.newRegisterDefaultProviderEvent(pkg, version)
 	if err != nil {
 		return providers.Reference{}, err
 	}
@@ -297,6 +328,10 @@ func (d *defaultProviders) handleRequest(pkg tokens.Package) (providers.Referenc
 		return providers.Reference{}, context.Canceled
 	}
 
+	if result == nil {
+		return providers.Reference{}, errors.Errorf("failed to register the default provider for package %s", pkg)
+	}
+
 	logging.V(5).Infof("registered default provider for package %s: %s", pkg, result.State.URN)
 
 	id := result.State.ID
@@ -306,7 +341,7 @@ func (d *defaultProviders) handleRequest(pkg tokens.Package) (providers.Referenc
 
 	ref, err = providers.NewReference(result.State.URN, id)
 	contract.Assert(err == nil)
-	d.providers[pkg] = ref
+	d.providers[key] = ref
 
 	return ref, nil
 }
@@ -318,7 +353,7 @@ func (d *defaultProviders) serve() {
 		case req := <-d.requests:
 			// Note that we do not need to handle cancellation when sending the response: every message we receive is
 			// guaranteed to have something waiting on the other end of the response channel.
-			ref, err := d.handleRequest(req.pkg)
+			ref, err := d.handleRequest(req.pkg, req.version)
 			req.response <- defaultProviderResponse{ref: ref, err: err}
 		case <-d.cancel:
 			return
@@ -326,11 +361,14 @@ func (d *defaultProviders) serve() {
 	}
 }
 
-// getDefaultProviderRef fetches the provider reference for the default provider for a particular package.
-func (d *defaultProviders) getDefaultProviderRef(pkg tokens.Package) (providers.Reference, error) {
+// getDefaultProviderRef fetches the provider reference for the default provider for a particular package and version.
+// If the version is nil, the provider uses the version that the program requires.
+func (d *defaultProviders) getDefaultProviderRef(
+	pkg tokens.Package, version *semver.Version) (providers.Reference, error) {
+
 	response := make(chan defaultProviderResponse)
 	select {
-	case d.requests <- defaultProviderRequest{pkg: pkg, response: response}:
+	case d.requests <- defaultProviderRequest{pkg: pkg, version: version, response: response}:
 	case <-d.cancel:
 		return providers.Reference{}, context.Canceled
 	}
@@ -362,7 +400,7 @@ func newResourceMonitor(src *evalSource, provs ProviderSource, regChan chan *reg
 	// Create a new default provider manager.
 	d := &defaultProviders{
 		versions:  src.defaultProviderVersions,
-		providers: make(map[tokens.Package]providers.Reference),
+		providers: make(map[defaultProviderKey]providers.Reference),
 		config:    src.runinfo.Target,
 		requests:  make(chan defaultProviderRequest),
 		regChan:   regChan,
@@ -412,8 +450,10 @@ func (rm *resmon) Cancel() error {
 
 // getProviderReference fetches the provider reference for a resource, read, or invoke from the given package with the
 // given unparsed provider reference. If the unparsed provider reference is empty, this function returns a reference
-// to the default provider for the indicated package.
-func (rm *resmon) getProviderReference(pkg tokens.Package, rawProviderRef string) (providers.Reference, error) {
+// to the default provider for the indicated package at the indicated version, if any.
+func (rm *resmon) getProviderReference(pkg tokens.Package, rawProviderRef,
+	rawVersion string) (providers.Reference, error) {
+
 	if rawProviderRef != "" {
 		ref, err := providers.ParseReference(rawProviderRef)
 		if err != nil {
@@ -422,7 +462,17 @@ func (rm *resmon) getProviderReference(pkg tokens.Package, rawProviderRef string
 		return ref, nil
 	}
 
-	ref, err := rm.defaultProviders.getDefaultProviderRef(pkg)
+	var version *semver.Version
+	if rawVersion != "" {
+		v, err := semver.ParseTolerant(rawVersion)
+		if err != nil {
+			return providers.Reference{}, rpcerror.New(codes.InvalidArgument,
+				fmt.Sprintf("could not parse provider version %q: %v", rawVersion, err))
+		}
+		version = &v
+	}
+
+	ref, err := rm.defaultProviders.getDefaultProviderRef(pkg, version)
 	if err != nil {
 		return providers.Reference{}, err
 	}
@@ -431,9 +481,9 @@ func (rm *resmon) getProviderReference(pkg tokens.Package, rawProviderRef string
 
 // getProvider fetches the provider plugin for a resource, read, or invoke from the given package with the given
 // unparsed provider reference. If the unparsed provider reference is empty, this function returns the plugin for the
-// indicated package's default provider.
-func (rm *resmon) getProvider(pkg tokens.Package, rawProviderRef string) (plugin.Provider, error) {
-	providerRef, err := rm.getProviderReference(pkg, rawProviderRef)
+// indicated package's default provider at the indicated version, if any.
+func (rm *resmon) getProvider(pkg tokens.Package, rawProviderRef, rawVersion string) (plugin.Provider, error) {
+	providerRef, err := rm.getProviderReference(pkg, rawProviderRef, rawVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -449,7 +499,7 @@ func (rm *resmon) Invoke(ctx context.Context, req *pulumirpc.InvokeRequest) (*pu
 	// Fetch the token and load up the resource provider if necessary.
 	tok := tokens.ModuleMember(req.GetTok())
 
-	prov, err := rm.getProvider(tok.Package(), req.GetProvider())
+	prov, err := rm.getProvider(tok.Package(), req.GetProvider(), req.GetVersion())
 	if err != nil {
 		return nil, err
 	}
@@ -496,7 +546,7 @@ func (rm *resmon) ReadResource(ctx context.Context,
 
 	provider := req.GetProvider()
 	if !providers.IsProviderType(t) && provider == "" {
-		ref, provErr := rm.defaultProviders.getDefaultProviderRef(t.Package())
+		ref, provErr := rm.getProviderReference(t.Package(), "", req.GetVersion())
 		if provErr != nil {
 			return nil, provErr
 		}
@@ -543,8 +593,10 @@ func (rm *resmon) ReadResource(ctx context.Context,
 		logging.V(5).Infof("ResourceMonitor.ReadResource operation canceled, name=%s", name)
 		return nil, rpcerror.New(codes.Unavailable, "resource monitor shut down while waiting on step's done channel")
 	}
+	if result == nil {
+		return nil, rpcerror.New(codes.Aborted, "the resource could not be read; see its diagnostics for details")
+	}
 
-	contract.Assert(result != nil)
 	marshaled, err := plugin.MarshalProperties(result.State.Outputs, plugin.MarshalOptions{
 		Label:        label,
 		KeepUnknowns: true,
@@ -559,6 +611,43 @@ func (rm *resmon) ReadResource(ctx context.Context,
 	}, nil
 }
 
+// parseCustomTimeouts converts the custom timeouts in a resource registration request, which are expressed as duration
+// strings (e.g. "5m" or "1h30m"), into seconds. An empty string leaves the corresponding timeout unset.
+func parseCustomTimeouts(
+	timeouts *pulumirpc.RegisterResourceRequest_CustomTimeouts) (resource.CustomTimeouts, error) {
+
+	parse := func(op, timeout string) (float64, error) {
+		if timeout == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid %s timeout", op)
+		}
+		if d <= 0 {
+			return 0, errors.Errorf("invalid %s timeout %q: timeouts must be positive", op, timeout)
+		}
+		return d.Seconds(), nil
+	}
+
+	var result resource.CustomTimeouts
+	if timeouts == nil {
+		return result, nil
+	}
+
+	var err error
+	if result.Create, err = parse("create", timeouts.GetCreate()); err != nil {
+		return result, err
+	}
+	if result.Update, err = parse("update", timeouts.GetUpdate()); err != nil {
+		return result, err
+	}
+	if result.Delete, err = parse("delete", timeouts.GetDelete()); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
 // RegisterResource is invoked by a language process when a new resource has been allocated.
 func (rm *resmon) RegisterResource(ctx context.Context,
 	req *pulumirpc.RegisterResourceRequest) (*pulumirpc.RegisterResourceResponse, error) {
@@ -570,6 +659,8 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 	parent := resource.URN(req.GetParent())
 	protect := req.GetProtect()
 	deleteBeforeReplace := req.GetDeleteBeforeReplace()
+	ignoreChanges := req.GetIgnoreChanges()
+	importID := resource.ID(req.GetImportId())
 	var t tokens.Type
 
 	// Custom resources must have a three-part type so that we can 1) identify if they are providers and 2) retrieve the
@@ -585,10 +676,21 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 		t = tokens.Type(req.GetType())
 	}
 
+	// Only custom resources have provider IDs, so only they may be imported.
+	if importID != "" && !custom {
+		return nil, rpcerror.New(codes.InvalidArgument, "only custom resources may be imported")
+	}
+
+	// Names that may be generated for default providers are reserved so that default providers can be recognized by
+	// name alone.
+	if providers.IsProviderType(t) && providers.IsDefaultProviderName(name) {
+		return nil, rpcerror.Newf(codes.InvalidArgument, "the provider name %s is reserved for default providers", name)
+	}
+
 	label := fmt.Sprintf("ResourceMonitor.RegisterResource(%s,%s)", t, name)
 	provider := req.GetProvider()
 	if custom && !providers.IsProviderType(t) && provider == "" {
-		ref, err := rm.defaultProviders.getDefaultProviderRef(t.Package())
+		ref, err := rm.getProviderReference(t.Package(), "", req.GetVersion())
 		if err != nil {
 			return nil, err
 		}
@@ -606,6 +708,11 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 		return nil, err
 	}
 
+	customTimeouts, err := parseCustomTimeouts(req.GetCustomTimeouts())
+	if err != nil {
+		return nil, rpcerror.New(codes.InvalidArgument, err.Error())
+	}
+
 	propertyDependencies := make(map[resource.PropertyKey][]resource.URN)
 	if len(req.GetPropertyDependencies()) == 0 {
 		// If this request did not specify property dependencies, treat each property as depending on every resource
@@ -626,13 +733,14 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 
 	logging.V(5).Infof(
 		"ResourceMonitor.RegisterResource received: t=%v, name=%v, custom=%v, #props=%v, parent=%v, protect=%v, "+
-			"provider=%v, deps=%v, deleteBeforeReplace=%v",
-		t, name, custom, len(props), parent, protect, provider, dependencies, deleteBeforeReplace)
+			"provider=%v, deps=%v, deleteBeforeReplace=%v, ignoreChanges=%v, customTimeouts=%v, importID=%v",
+		t, name, custom, len(props), parent, protect, provider, dependencies, deleteBeforeReplace, ignoreChanges,
+		customTimeouts, importID)
 
 	// Send the goal state to the engine.
 	step := &registerResourceEvent{
 		goal: resource.NewGoal(t, name, custom, props, parent, protect, dependencies, provider, nil,
-			propertyDependencies, deleteBeforeReplace),
+			propertyDependencies, deleteBeforeReplace, ignoreChanges, customTimeouts, importID),
 		done: make(chan *RegisterResult),
 	}
 
@@ -651,6 +759,9 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 		logging.V(5).Infof("ResourceMonitor.RegisterResource operation canceled, name=%s", name)
 		return nil, rpcerror.New(codes.Unavailable, "resource monitor shut down while waiting on step's done channel")
 	}
+	if result == nil {
+		return nil, rpcerror.New(codes.Aborted, "the resource could not be deployed; see its diagnostics for details")
+	}
 
 	state := result.State
 	props = state.All()
@@ -726,6 +837,7 @@ func (rm *resmon) RegisterResourceOutputs(ctx context.Context,
 type registerResourceEvent struct {
 	goal *resource.Goal       // the resource goal state produced by the iterator.
 	done chan *RegisterResult // the channel to communicate with after the resource state is available.
+	once sync.Once            // ensures that the result is only communicated once.
 }
 
 var _ RegisterResourceEvent = (*registerResourceEvent)(nil)
@@ -738,7 +850,7 @@ func (g *registerResourceEvent) Goal() *resource.Goal {
 
 func (g *registerResourceEvent) Done(result *RegisterResult) {
 	// Communicate the resulting state back to the RPC thread, which is parked awaiting our reply.
-	g.done <- result
+	g.once.Do(func() { g.done <- result })
 }
 
 type registerResourceOutputsEvent struct {
@@ -773,6 +885,7 @@ type readResourceEvent struct {
 	props        resource.PropertyMap
 	dependencies []resource.URN
 	done         chan *ReadResult
+	once         sync.Once
 }
 
 var _ ReadResourceEvent = (*readResourceEvent)(nil)
@@ -787,5 +900,5 @@ func (g *readResourceEvent) Parent() resource.URN             { return g.parent
 func (g *readResourceEvent) Properties() resource.PropertyMap { return g.props }
 func (g *readResourceEvent) Dependencies() []resource.URN     { return g.dependencies }
 func (g *readResourceEvent) Done(result *ReadResult) {
-	g.done <- result
+	g.once.Do(func() { g.done <- result })
 }
@@ -30,6 +30,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/workspace"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
 )
 
 type testRegEvent struct {
@@ -61,7 +62,7 @@ func fixedProgram(steps []RegisterResourceEvent) deploytest.ProgramFunc {
 			}
 			s.Done(&RegisterResult{
 				State: resource.NewState(g.Type, urn, g.Custom, false, id, g.Properties, outs, g.Parent, g.Protect,
-					false, g.Dependencies, nil, g.Provider, g.PropertyDependencies, false),
+					false, g.Dependencies, nil, g.Provider, g.PropertyDependencies, false, resource.CustomTimeouts{}),
 			})
 		}
 		return nil
@@ -143,16 +144,16 @@ func TestRegisterNoDefaultProviders(t *testing.T) {
 		// Register a component resource.
 		&testRegEvent{
 			goal: resource.NewGoal(componentURN.Type(), componentURN.Name(), false, resource.PropertyMap{}, "", false,
-				nil, "", []string{}, nil, false),
+				nil, "", []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 		// Register a couple resources using provider A.
 		&testRegEvent{
 			goal: resource.NewGoal("pkgA:index:typA", "res1", true, resource.PropertyMap{}, componentURN, false, nil,
-				providerARef.String(), []string{}, nil, false),
+				providerARef.String(), []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 		&testRegEvent{
 			goal: resource.NewGoal("pkgA:index:typA", "res2", true, resource.PropertyMap{}, componentURN, false, nil,
-				providerARef.String(), []string{}, nil, false),
+				providerARef.String(), []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 		// Register two more providers.
 		newProviderEvent("pkgA", "providerB", nil, ""),
@@ -160,11 +161,11 @@ func TestRegisterNoDefaultProviders(t *testing.T) {
 		// Register a few resources that use the new providers.
 		&testRegEvent{
 			goal: resource.NewGoal("pkgB:index:typB", "res3", true, resource.PropertyMap{}, "", false, nil,
-				providerBRef.String(), []string{}, nil, false),
+				providerBRef.String(), []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 		&testRegEvent{
 			goal: resource.NewGoal("pkgB:index:typC", "res4", true, resource.PropertyMap{}, "", false, nil,
-				providerCRef.String(), []string{}, nil, false),
+				providerCRef.String(), []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 	}
 
@@ -198,7 +199,7 @@ func TestRegisterNoDefaultProviders(t *testing.T) {
 		reg.Done(&RegisterResult{
 			State: resource.NewState(goal.Type, urn, goal.Custom, false, id, goal.Properties, resource.PropertyMap{},
 				goal.Parent, goal.Protect, false, goal.Dependencies, nil, goal.Provider, goal.PropertyDependencies,
-				false),
+				false, resource.CustomTimeouts{}),
 		})
 
 		processed++
@@ -227,25 +228,25 @@ func TestRegisterDefaultProviders(t *testing.T) {
 		// Register a component resource.
 		&testRegEvent{
 			goal: resource.NewGoal(componentURN.Type(), componentURN.Name(), false, resource.PropertyMap{}, "", false,
-				nil, "", []string{}, nil, false),
+				nil, "", []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 		// Register a couple resources from package A.
 		&testRegEvent{
 			goal: resource.NewGoal("pkgA:m:typA", "res1", true, resource.PropertyMap{},
-				componentURN, false, nil, "", []string{}, nil, false),
+				componentURN, false, nil, "", []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 		&testRegEvent{
 			goal: resource.NewGoal("pkgA:m:typA", "res2", true, resource.PropertyMap{},
-				componentURN, false, nil, "", []string{}, nil, false),
+				componentURN, false, nil, "", []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 		// Register a few resources from other packages.
 		&testRegEvent{
 			goal: resource.NewGoal("pkgB:m:typB", "res3", true, resource.PropertyMap{}, "", false,
-				nil, "", []string{}, nil, false),
+				nil, "", []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 		&testRegEvent{
 			goal: resource.NewGoal("pkgB:m:typC", "res4", true, resource.PropertyMap{}, "", false,
-				nil, "", []string{}, nil, false),
+				nil, "", []string{}, nil, false, nil, resource.CustomTimeouts{}, ""),
 		},
 	}
 
@@ -290,7 +291,7 @@ func TestRegisterDefaultProviders(t *testing.T) {
 		reg.Done(&RegisterResult{
 			State: resource.NewState(goal.Type, urn, goal.Custom, false, id, goal.Properties, resource.PropertyMap{},
 				goal.Parent, goal.Protect, false, goal.Dependencies, nil, goal.Provider, goal.PropertyDependencies,
-				false),
+				false, resource.CustomTimeouts{}),
 		})
 
 		processed++
@@ -380,7 +381,7 @@ func TestReadInvokeNoDefaultProviders(t *testing.T) {
 		read.Done(&ReadResult{
 			State: resource.NewState(read.Type(), urn, true, false, read.ID(), read.Properties(),
 				resource.PropertyMap{}, read.Parent(), false, false, read.Dependencies(), nil, read.Provider(), nil,
-				false),
+				false, resource.CustomTimeouts{}),
 		})
 		reads++
 	}
@@ -465,7 +466,7 @@ func TestReadInvokeDefaultProviders(t *testing.T) {
 			e.Done(&RegisterResult{
 				State: resource.NewState(goal.Type, urn, goal.Custom, false, id, goal.Properties, resource.PropertyMap{},
 					goal.Parent, goal.Protect, false, goal.Dependencies, nil, goal.Provider, goal.PropertyDependencies,
-					false),
+					false, resource.CustomTimeouts{}),
 			})
 			registers++
 
@@ -473,7 +474,8 @@ func TestReadInvokeDefaultProviders(t *testing.T) {
 			urn := newURN(e.Type(), string(e.Name()), e.Parent())
 			e.Done(&ReadResult{
 				State: resource.NewState(e.Type(), urn, true, false, e.ID(), e.Properties(),
-					resource.PropertyMap{}, e.Parent(), false, false, e.Dependencies(), nil, e.Provider(), nil, false),
+					resource.PropertyMap{}, e.Parent(), false, false, e.Dependencies(), nil, e.Provider(), nil, false,
+					resource.CustomTimeouts{}),
 			})
 			reads++
 		}
@@ -483,3 +485,22 @@ func TestReadInvokeDefaultProviders(t *testing.T) {
 	assert.Equal(t, expectedReads, reads)
 	assert.Equal(t, expectedInvokes, int(invokes))
 }
+
+func TestParseCustomTimeouts(t *testing.T) {
+	timeouts, err := parseCustomTimeouts(nil)
+	assert.NoError(t, err)
+	assert.False(t, timeouts.IsNotEmpty())
+
+	timeouts, err = parseCustomTimeouts(&pulumirpc.RegisterResourceRequest_CustomTimeouts{
+		Create: "1h30m",
+		Delete: "90s",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, resource.CustomTimeouts{Create: 5400, Delete: 90}, timeouts)
+
+	_, err = parseCustomTimeouts(&pulumirpc.RegisterResourceRequest_CustomTimeouts{Update: "ten minutes"})
+	assert.Error(t, err)
+
+	_, err = parseCustomTimeouts(&pulumirpc.RegisterResourceRequest_CustomTimeouts{Update: "-5m"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// NewSnapshotSource returns a planning source that, rather than running a program, registers the resources recorded
+// in an existing snapshot with the same inputs and options they were recorded with. Planning against this source
+// brings a stack's resources back to the state captured by the snapshot, e.g. to roll back a bad update.
+func NewSnapshotSource(project tokens.PackageName, snap *Snapshot) Source {
+	return &snapshotSource{project: project, snap: snap}
+}
+
+// A snapshotSource replays the resources recorded in a snapshot.
+type snapshotSource struct {
+	project tokens.PackageName
+	snap    *Snapshot
+}
+
+func (src *snapshotSource) Close() error                { return nil }
+func (src *snapshotSource) Project() tokens.PackageName { return src.project }
+func (src *snapshotSource) Info() interface{}           { return nil }
+
+func (src *snapshotSource) Iterate(
+	ctx context.Context, opts Options, providers ProviderSource) (SourceIterator, error) {
+	var resources []*resource.State
+	if src.snap != nil {
+		for _, res := range src.snap.Resources {
+			// Resources that were pending deletion were not part of the snapshot's desired state.
+			if !res.Delete {
+				resources = append(resources, res)
+			}
+		}
+	}
+
+	return &snapshotSourceIterator{
+		ctx:       ctx,
+		resources: resources,
+		refs:      make(map[string]string),
+	}, nil
+}
+
+// snapshotSourceIterator issues an event for each resource in a snapshot, in order. Like a program, it waits for each
+// resource to be registered before moving on, both so that the resource's dependencies are always available and so
+// that references to providers can be rewritten to refer to the providers' current IDs.
+type snapshotSourceIterator struct {
+	ctx       context.Context
+	resources []*resource.State
+	current   int
+
+	wait func() error      // waits for the previously issued event to complete, if any.
+	next SourceEvent       // an event to issue before moving on to the next resource, if any.
+	refs map[string]string // maps recorded provider references to the references of the registered providers.
+}
+
+func (iter *snapshotSourceIterator) Close() error {
+	return nil // nothing to do.
+}
+
+func (iter *snapshotSourceIterator) Next() (SourceEvent, error) {
+	if iter.wait != nil {
+		wait := iter.wait
+		iter.wait = nil
+		if err := wait(); err != nil {
+			return nil, err
+		}
+	}
+	if iter.next != nil {
+		event := iter.next
+		iter.next = nil
+		return event, nil
+	}
+
+	if iter.current >= len(iter.resources) {
+		return nil, nil
+	}
+	res := iter.resources[iter.current]
+	iter.current++
+
+	provider := res.Provider
+	if ref, has := iter.refs[provider]; has {
+		provider = ref
+	}
+
+	// Resources that were read rather than managed are simply read again.
+	if res.External {
+		return iter.read(res, provider), nil
+	}
+	return iter.register(res, provider), nil
+}
+
+// read returns an event that reads the given external resource.
+func (iter *snapshotSourceIterator) read(res *resource.State, provider string) SourceEvent {
+	done := make(chan *ReadResult, 1)
+	iter.wait = func() error {
+		select {
+		case result := <-done:
+			if result == nil {
+				return errors.Errorf("failed to read resource '%s'", res.URN)
+			}
+			return nil
+		case <-iter.ctx.Done():
+			return iter.ctx.Err()
+		}
+	}
+
+	return &readResourceEvent{
+		id:           res.ID,
+		name:         res.URN.Name(),
+		baseType:     res.Type,
+		provider:     provider,
+		parent:       res.Parent,
+		props:        res.Inputs,
+		dependencies: res.Dependencies,
+		done:         done,
+	}
+}
+
+// register returns an event that registers the given resource with its recorded inputs and options.
+func (iter *snapshotSourceIterator) register(res *resource.State, provider string) SourceEvent {
+	done := make(chan *RegisterResult, 1)
+	iter.wait = func() error {
+		var result *RegisterResult
+		select {
+		case result = <-done:
+		case <-iter.ctx.Done():
+			return iter.ctx.Err()
+		}
+		if result == nil {
+			return errors.Errorf("failed to register resource '%s'", res.URN)
+		}
+
+		// Later resources must refer to this provider by its current ID, which may differ from the recorded one.
+		if providers.IsProviderType(res.Type) {
+			old, err := providers.NewReference(res.URN, res.ID)
+			if err != nil {
+				return err
+			}
+			new, err := providers.NewReference(result.State.URN, result.State.ID)
+			if err != nil {
+				return err
+			}
+			iter.refs[old.String()] = new.String()
+		}
+
+		// Component resources also get their recorded outputs back.
+		if !res.Custom {
+			outputsDone := make(chan bool, 1)
+			iter.next = &registerResourceOutputsEvent{urn: res.URN, outputs: res.Outputs, done: outputsDone}
+			iter.wait = func() error {
+				select {
+				case <-outputsDone:
+					return nil
+				case <-iter.ctx.Done():
+					return iter.ctx.Err()
+				}
+			}
+		}
+		return nil
+	}
+
+	goal := resource.NewGoal(res.Type, res.URN.Name(), res.Custom, res.Inputs, res.Parent, res.Protect,
+		res.Dependencies, provider, nil, res.PropertyDependencies, false, nil, res.CustomTimeouts, "")
+	return &registerResourceEvent{goal: goal, done: done}
+}
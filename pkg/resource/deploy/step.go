@@ -99,14 +99,15 @@ func (s *SameStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error
 
 // CreateStep is a mutating step that creates an entirely new resource.
 type CreateStep struct {
-	plan          *Plan                  // the current plan.
-	reg           RegisterResourceEvent  // the registration intent to convey a URN back to.
-	old           *resource.State        // the state of the existing resource (only for replacements).
-	new           *resource.State        // the state of the resource after this step.
-	keys          []resource.PropertyKey // the keys causing replacement (only for replacements).
-	diffs         []resource.PropertyKey // the keys causing a diff (only for replacements).
-	replacing     bool                   // true if this is a create due to a replacement.
-	pendingDelete bool                   // true if this replacement should create a pending delete.
+	plan          *Plan                           // the current plan.
+	reg           RegisterResourceEvent           // the registration intent to convey a URN back to.
+	old           *resource.State                 // the state of the existing resource (only for replacements).
+	new           *resource.State                 // the state of the resource after this step.
+	keys          []resource.PropertyKey          // the keys causing replacement (only for replacements).
+	reasons       map[resource.PropertyKey]string // explanations of why keys cause replacement, where known.
+	diffs         []resource.PropertyKey          // the keys causing a diff (only for replacements).
+	replacing     bool                            // true if this is a create due to a replacement.
+	pendingDelete bool                            // true if this replacement should create a pending delete.
 }
 
 var _ Step = (*CreateStep)(nil)
@@ -127,7 +128,8 @@ func NewCreateStep(plan *Plan, reg RegisterResourceEvent, new *resource.State) S
 }
 
 func NewCreateReplacementStep(plan *Plan, reg RegisterResourceEvent,
-	old *resource.State, new *resource.State, keys, diffs []resource.PropertyKey, pendingDelete bool) Step {
+	old *resource.State, new *resource.State, keys, diffs []resource.PropertyKey,
+	reasons map[resource.PropertyKey]string, pendingDelete bool) Step {
 	contract.Assert(reg != nil)
 	contract.Assert(old != nil)
 	contract.Assert(old.URN != "")
@@ -146,6 +148,7 @@ func NewCreateReplacementStep(plan *Plan, reg RegisterResourceEvent,
 		old:           old,
 		new:           new,
 		keys:          keys,
+		reasons:       reasons,
 		diffs:         diffs,
 		replacing:     true,
 		pendingDelete: pendingDelete,
@@ -158,16 +161,17 @@ func (s *CreateStep) Op() StepOp {
 	}
 	return OpCreate
 }
-func (s *CreateStep) Plan() *Plan                   { return s.plan }
-func (s *CreateStep) Type() tokens.Type             { return s.new.Type }
-func (s *CreateStep) Provider() string              { return s.new.Provider }
-func (s *CreateStep) URN() resource.URN             { return s.new.URN }
-func (s *CreateStep) Old() *resource.State          { return s.old }
-func (s *CreateStep) New() *resource.State          { return s.new }
-func (s *CreateStep) Res() *resource.State          { return s.new }
-func (s *CreateStep) Keys() []resource.PropertyKey  { return s.keys }
-func (s *CreateStep) Diffs() []resource.PropertyKey { return s.diffs }
-func (s *CreateStep) Logical() bool                 { return !s.replacing }
+func (s *CreateStep) Plan() *Plan                              { return s.plan }
+func (s *CreateStep) Type() tokens.Type                        { return s.new.Type }
+func (s *CreateStep) Provider() string                         { return s.new.Provider }
+func (s *CreateStep) URN() resource.URN                        { return s.new.URN }
+func (s *CreateStep) Old() *resource.State                     { return s.old }
+func (s *CreateStep) New() *resource.State                     { return s.new }
+func (s *CreateStep) Res() *resource.State                     { return s.new }
+func (s *CreateStep) Keys() []resource.PropertyKey             { return s.keys }
+func (s *CreateStep) Reasons() map[resource.PropertyKey]string { return s.reasons }
+func (s *CreateStep) Diffs() []resource.PropertyKey            { return s.diffs }
+func (s *CreateStep) Logical() bool                            { return !s.replacing }
 
 func (s *CreateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
 	var resourceError error
@@ -179,7 +183,7 @@ func (s *CreateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 			if err != nil {
 				return resource.StatusOK, nil, err
 			}
-			id, outs, rst, err := prov.Create(s.URN(), s.new.Inputs)
+			id, outs, rst, err := prov.Create(s.URN(), s.new.Inputs, s.new.CustomTimeouts.Create)
 			if err != nil {
 				if rst != resource.StatusPartialFailure {
 					return rst, nil, err
@@ -298,7 +302,7 @@ func (s *DeleteStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 			if err != nil {
 				return resource.StatusOK, nil, err
 			}
-			if rst, err := prov.Delete(s.URN(), s.old.ID, s.old.All()); err != nil {
+			if rst, err := prov.Delete(s.URN(), s.old.ID, s.old.All(), s.old.CustomTimeouts.Delete); err != nil {
 				return rst, nil, err
 			}
 		}
@@ -400,7 +404,8 @@ func (s *UpdateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 			}
 
 			// Update to the combination of the old "all" state (including outputs), but overwritten with new inputs.
-			outs, rst, upderr := prov.Update(s.URN(), s.old.ID, s.old.All(), s.new.Inputs)
+			outs, rst, upderr := prov.Update(s.URN(), s.old.ID, s.old.All(), s.new.Inputs,
+				s.new.CustomTimeouts.Update)
 			if upderr != nil {
 				if rst != resource.StatusPartialFailure {
 					return rst, nil, upderr
@@ -431,18 +436,19 @@ func (s *UpdateStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 // a creation of the new resource, any number of intervening updates of dependents to the new resource, and then
 // a deletion of the now-replaced old resource.  This logical step is primarily here for tools and visualization.
 type ReplaceStep struct {
-	plan          *Plan                  // the current plan.
-	old           *resource.State        // the state of the existing resource.
-	new           *resource.State        // the new state snapshot.
-	keys          []resource.PropertyKey // the keys causing replacement.
-	diffs         []resource.PropertyKey // the keys causing a diff.
-	pendingDelete bool                   // true if a pending deletion should happen.
+	plan          *Plan                           // the current plan.
+	old           *resource.State                 // the state of the existing resource.
+	new           *resource.State                 // the new state snapshot.
+	keys          []resource.PropertyKey          // the keys causing replacement.
+	reasons       map[resource.PropertyKey]string // explanations of why keys cause replacement, where known.
+	diffs         []resource.PropertyKey          // the keys causing a diff.
+	pendingDelete bool                            // true if a pending deletion should happen.
 }
 
 var _ Step = (*ReplaceStep)(nil)
 
 func NewReplaceStep(plan *Plan, old *resource.State, new *resource.State,
-	keys, diffs []resource.PropertyKey, pendingDelete bool) Step {
+	keys, diffs []resource.PropertyKey, reasons map[resource.PropertyKey]string, pendingDelete bool) Step {
 	contract.Assert(old != nil)
 	contract.Assert(old.URN != "")
 	contract.Assert(old.ID != "" || !old.Custom)
@@ -456,22 +462,24 @@ func NewReplaceStep(plan *Plan, old *resource.State, new *resource.State,
 		old:           old,
 		new:           new,
 		keys:          keys,
+		reasons:       reasons,
 		diffs:         diffs,
 		pendingDelete: pendingDelete,
 	}
 }
 
-func (s *ReplaceStep) Op() StepOp                    { return OpReplace }
-func (s *ReplaceStep) Plan() *Plan                   { return s.plan }
-func (s *ReplaceStep) Type() tokens.Type             { return s.old.Type }
-func (s *ReplaceStep) Provider() string              { return s.old.Provider }
-func (s *ReplaceStep) URN() resource.URN             { return s.old.URN }
-func (s *ReplaceStep) Old() *resource.State          { return s.old }
-func (s *ReplaceStep) New() *resource.State          { return s.new }
-func (s *ReplaceStep) Res() *resource.State          { return s.new }
-func (s *ReplaceStep) Keys() []resource.PropertyKey  { return s.keys }
-func (s *ReplaceStep) Diffs() []resource.PropertyKey { return s.diffs }
-func (s *ReplaceStep) Logical() bool                 { return true }
+func (s *ReplaceStep) Op() StepOp                               { return OpReplace }
+func (s *ReplaceStep) Plan() *Plan                              { return s.plan }
+func (s *ReplaceStep) Type() tokens.Type                        { return s.old.Type }
+func (s *ReplaceStep) Provider() string                         { return s.old.Provider }
+func (s *ReplaceStep) URN() resource.URN                        { return s.old.URN }
+func (s *ReplaceStep) Old() *resource.State                     { return s.old }
+func (s *ReplaceStep) New() *resource.State                     { return s.new }
+func (s *ReplaceStep) Res() *resource.State                     { return s.new }
+func (s *ReplaceStep) Keys() []resource.PropertyKey             { return s.keys }
+func (s *ReplaceStep) Reasons() map[resource.PropertyKey]string { return s.reasons }
+func (s *ReplaceStep) Diffs() []resource.PropertyKey            { return s.diffs }
+func (s *ReplaceStep) Logical() bool                            { return true }
 
 func (s *ReplaceStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
 	// If this is a pending delete, we should have marked the old resource for deletion in the CreateReplacement step.
@@ -597,26 +605,110 @@ func (s *ReadStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error
 	return resourceStatus, complete, resourceError
 }
 
+// ImportStep is a mutating step that adopts an existing resource, identified by its provider ID, in place of creating
+// a new one. The live state of the resource is read from its provider, and the import fails if the resource does not
+// exist or if the program's inputs differ from it.
+type ImportStep struct {
+	plan *Plan                 // the current plan.
+	reg  RegisterResourceEvent // the registration intent to convey a URN back to.
+	new  *resource.State       // the state of the resource after this step.
+	id   resource.ID           // the ID of the existing resource to import.
+}
+
+var _ Step = (*ImportStep)(nil)
+
+func NewImportStep(plan *Plan, reg RegisterResourceEvent, new *resource.State, id resource.ID) Step {
+	contract.Assert(reg != nil)
+	contract.Assert(new != nil)
+	contract.Assert(new.URN != "")
+	contract.Assert(new.ID == "")
+	contract.Assert(new.Custom)
+	contract.Assert(new.Provider != "" || providers.IsProviderType(new.Type))
+	contract.Assert(!new.Delete)
+	contract.Assert(!new.External)
+	contract.Assert(id != "")
+	return &ImportStep{
+		plan: plan,
+		reg:  reg,
+		new:  new,
+		id:   id,
+	}
+}
+
+func (s *ImportStep) Op() StepOp           { return OpImport }
+func (s *ImportStep) Plan() *Plan          { return s.plan }
+func (s *ImportStep) Type() tokens.Type    { return s.new.Type }
+func (s *ImportStep) Provider() string     { return s.new.Provider }
+func (s *ImportStep) URN() resource.URN    { return s.new.URN }
+func (s *ImportStep) Old() *resource.State { return nil }
+func (s *ImportStep) New() *resource.State { return s.new }
+func (s *ImportStep) Res() *resource.State { return s.new }
+func (s *ImportStep) Logical() bool        { return true }
+
+func (s *ImportStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
+	// Like Read steps, Import steps run during previews so that a missing or mismatched resource is reported early.
+	prov, err := getProvider(s)
+	if err != nil {
+		return resource.StatusOK, nil, err
+	}
+	read, rst, err := prov.Read(s.URN(), s.id, nil, nil)
+	if err != nil {
+		if initErr, isInitErr := err.(*plugin.InitError); isInitErr {
+			s.new.InitErrors = initErr.Reasons
+		}
+		return rst, nil, err
+	}
+	if read.Outputs == nil {
+		return rst, nil, errors.Errorf("resource '%v' does not exist", s.id)
+	}
+
+	// Ensure that the program's inputs describe the resource as it exists; otherwise the next update would
+	// silently modify the imported resource.
+	diff, err := prov.Diff(s.URN(), s.id, read.Outputs, s.new.Inputs, preview)
+	if err != nil {
+		return rst, nil, err
+	}
+	if diff.Changes == plugin.DiffSome {
+		keys := diff.ChangedKeys
+		if len(keys) == 0 {
+			keys = diff.ReplaceKeys
+		}
+		if len(keys) == 0 {
+			return rst, nil, errors.Errorf("inputs to import do not match the existing resource '%v'", s.id)
+		}
+		return rst, nil, errors.Errorf("inputs to import do not match the existing resource '%v': %v differ",
+			s.id, keys)
+	}
+
+	s.new.ID = s.id
+	s.new.Outputs = read.Outputs
+
+	complete := func() { s.reg.Done(&RegisterResult{State: s.new}) }
+	return rst, complete, nil
+}
+
 // RefreshStep is a step used to track the progress of a refresh operation. A refresh operation updates the an existing
 // resource by reading its current state from its provider plugin. These steps are not issued by the step generator;
 // instead, they are issued by the plan executor as the optional first step in plan execution.
 type RefreshStep struct {
-	plan *Plan           // the plan that produced this refresh
-	old  *resource.State // the old resource state, if one exists for this urn
-	new  *resource.State // the new resource state, to be used to query the provider
-	done chan<- bool     // the channel to use to signal completion, if any
+	plan         *Plan             // the plan that produced this refresh
+	old          *resource.State   // the old resource state, if one exists for this urn
+	new          *resource.State   // the new resource state, to be used to query the provider
+	suppressions []DiffSuppression // the properties whose changes are not reported as drift
+	done         chan<- bool       // the channel to use to signal completion, if any
 }
 
 // NewRefreshStep creates a new Refresh step.
-func NewRefreshStep(plan *Plan, old *resource.State, done chan<- bool) Step {
+func NewRefreshStep(plan *Plan, old *resource.State, suppressions []DiffSuppression, done chan<- bool) Step {
 	contract.Assert(old != nil)
 
 	// NOTE: we set the new state to the old state by default so that we don't interpret step failures as deletes.
 	return &RefreshStep{
-		plan: plan,
-		old:  old,
-		new:  old,
-		done: done,
+		plan:         plan,
+		old:          old,
+		new:          old,
+		suppressions: suppressions,
+		done:         done,
 	}
 }
 
@@ -636,12 +728,23 @@ func (s *RefreshStep) ResultOp() StepOp {
 	if s.new == nil {
 		return OpDelete
 	}
-	if s.new == s.old || s.old.Outputs.Diff(s.new.Outputs) == nil {
+	if s.new == s.old || s.old.Outputs.Diff(s.DisplayNew().Outputs) == nil {
 		return OpSame
 	}
 	return OpUpdate
 }
 
+// DisplayNew returns the state of the resource after reading it, with any properties whose diffs are suppressed
+// reset to their old values so that changes to them are not reported. The state recorded by the refresh is unchanged.
+func (s *RefreshStep) DisplayNew() *resource.State {
+	if s.new == nil || s.new == s.old || len(s.suppressions) == 0 {
+		return s.new
+	}
+	display := *s.new
+	display.Outputs = suppressDiffs(s.new.Type, s.old.Outputs, s.new.Outputs, s.suppressions)
+	return &display
+}
+
 func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
 	var complete func()
 	if s.done != nil {
@@ -680,7 +783,7 @@ func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, er
 	if outputs != nil {
 		s.new = resource.NewState(s.old.Type, s.old.URN, s.old.Custom, s.old.Delete, s.old.ID, inputs, outputs,
 			s.old.Parent, s.old.Protect, s.old.External, s.old.Dependencies, initErrors, s.old.Provider,
-			s.old.PropertyDependencies, s.old.PendingReplacement)
+			s.old.PropertyDependencies, s.old.PendingReplacement, s.old.CustomTimeouts)
 	} else {
 		s.new = nil
 	}
@@ -705,6 +808,7 @@ const (
 	OpReadDiscard          StepOp = "discard"                // removing a resource that was read.
 	OpDiscardReplaced      StepOp = "discard-replaced"       // discarding a read resource that was replaced.
 	OpRemovePendingReplace StepOp = "remove-pending-replace" // removing a pending replace resource.
+	OpImport               StepOp = "import"                 // importing an existing resource.
 )
 
 // StepOps contains the full set of step operation types.
@@ -722,6 +826,7 @@ var StepOps = []StepOp{
 	OpReadDiscard,
 	OpDiscardReplaced,
 	OpRemovePendingReplace,
+	OpImport,
 }
 
 // Color returns a suggested color for lines of this op type.
@@ -749,6 +854,8 @@ func (op StepOp) Color() string {
 		return colors.SpecUpdate
 	case OpReadDiscard, OpDiscardReplaced:
 		return colors.SpecDelete
+	case OpImport:
+		return colors.SpecCreate
 	default:
 		contract.Failf("Unrecognized resource step op: '%v'", op)
 		return ""
@@ -787,6 +894,8 @@ func (op StepOp) RawPrefix() string {
 		return "< "
 	case OpDiscardReplaced:
 		return "<<"
+	case OpImport:
+		return "= "
 	default:
 		contract.Failf("Unrecognized resource step op: %v", op)
 		return ""
@@ -803,6 +912,8 @@ func (op StepOp) PastTense() string {
 		return "read"
 	case OpReadDiscard, OpDiscardReplaced:
 		return "discarded"
+	case OpImport:
+		return "imported"
 	default:
 		contract.Failf("Unexpected resource step op: %v", op)
 		return ""
@@ -19,9 +19,11 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 )
@@ -96,6 +98,14 @@ type stepExecutor struct {
 	ctx      context.Context    // cancellation context for the current plan.
 	cancel   context.CancelFunc // CancelFunc that cancels the above context.
 	sawError atomic.Value       // atomic boolean indicating whether or not the step excecutor saw that there was an error.
+
+	failedLock sync.Mutex     // a lock protecting failed.
+	failed     []resource.URN // the URNs of the resources whose steps failed, if continueOnError is set.
+
+	providerSlots map[string]chan struct{} // semaphores limiting the in-flight operations of each limited package.
+
+	retriesLock sync.Mutex     // a lock protecting retries.
+	retries     map[string]int // the number of retries made so far for each package with a retry budget.
 }
 
 //
@@ -186,6 +196,14 @@ func (se *stepExecutor) Errored() bool {
 	return se.sawError.Load().(bool)
 }
 
+// Failed returns the URNs of the resources whose steps failed, in the order in which they failed. Failures are only
+// recorded if the step executor continues after errors.
+func (se *stepExecutor) Failed() []resource.URN {
+	se.failedLock.Lock()
+	defer se.failedLock.Unlock()
+	return append([]resource.URN(nil), se.failed...)
+}
+
 // SignalCompletion signals to the stepExecutor that there are no more chains left to execute. All worker
 // threads will terminate as soon as they retire all of the work they are currently executing.
 func (se *stepExecutor) SignalCompletion() {
@@ -209,7 +227,7 @@ func (se *stepExecutor) WaitForCompletion() {
 // executeChain executes a chain, one step at a time. If any step in the chain fails to execute, or if the
 // context is canceled, the chain stops execution.
 func (se *stepExecutor) executeChain(workerID int, chain chain) {
-	for _, step := range chain {
+	for i, step := range chain {
 		select {
 		case <-se.ctx.Done():
 			se.log(workerID, "step %v on %v canceled", step.Op(), step.URN())
@@ -229,11 +247,44 @@ func (se *stepExecutor) executeChain(workerID int, chain chain) {
 				diagMsg := diag.RawMessage(step.URN(), err.Error())
 				se.plan.Diag().Errorf(diagMsg)
 			}
+			if se.continueOnError {
+				se.abandonChain(step.URN(), chain[i:])
+			}
 			return
 		}
 	}
 }
 
+// abandonChain records the failure of the resource with the given URN and informs the program that the remaining
+// steps in its chain will not be executed. The program will then fail to register any resources that depend on the
+// abandoned resource, but may continue to register resources that do not.
+func (se *stepExecutor) abandonChain(urn resource.URN, remaining chain) {
+	se.failedLock.Lock()
+	se.failed = append(se.failed, urn)
+	se.failedLock.Unlock()
+
+	for _, step := range remaining {
+		var reg RegisterResourceEvent
+		switch s := step.(type) {
+		case *SameStep:
+			reg = s.reg
+		case *CreateStep:
+			reg = s.reg
+		case *UpdateStep:
+			reg = s.reg
+		case *ImportStep:
+			reg = s.reg
+		case *ReadStep:
+			if s.event != nil {
+				s.event.Done(nil)
+			}
+		}
+		if reg != nil {
+			reg.Done(nil)
+		}
+	}
+}
+
 func (se *stepExecutor) cancelDueToError() {
 	se.sawError.Store(true)
 	if !se.continueOnError {
@@ -267,7 +318,7 @@ func (se *stepExecutor) executeStep(workerID int, step Step) error {
 	}
 
 	se.log(workerID, "applying step %v on %v (preview %v)", step.Op(), step.URN(), se.preview)
-	status, stepComplete, err := step.Apply(se.preview)
+	status, stepComplete, err := se.applyStep(workerID, step)
 
 	if err == nil {
 		// If we have a state object, and this is a create or update, remember it, as we may need to update it later.
@@ -303,6 +354,81 @@ func (se *stepExecutor) executeStep(workerID int, step Step) error {
 	return nil
 }
 
+// applyStep applies a step, retrying it according to the plan's retry policy for as long as it fails with transient
+// provider errors.
+func (se *stepExecutor) applyStep(workerID int, step Step) (resource.Status, StepCompleteFunc, error) {
+	policy := se.opts.Retries.PolicyFor(step)
+	for attempt := 1; ; attempt++ {
+		release, err := se.acquireProviderSlot(workerID, step)
+		if err != nil {
+			return resource.StatusOK, nil, err
+		}
+		status, stepComplete, err := step.Apply(se.preview)
+		release()
+		if attempt >= policy.MaxAttempts || !isTransientStepError(step.Op(), status, err) {
+			return status, stepComplete, err
+		}
+		if !se.spendRetry(step) {
+			se.plan.Diag().Warningf(diag.RawMessage(step.URN(), fmt.Sprintf(
+				"%v failed with a transient error, but the retry budget for %s has been used up: %v",
+				step.Op(), stepPackage(step), err)))
+			return status, stepComplete, err
+		}
+
+		delay := policy.Delay(attempt)
+		se.log(workerID, "step %v on %v failed with a transient error, retrying in %v: %v",
+			step.Op(), step.URN(), delay, err)
+		se.plan.Diag().Warningf(diag.RawMessage(step.URN(), fmt.Sprintf(
+			"%v failed with a transient error, retrying in %v (attempt %d of %d): %v",
+			step.Op(), delay, attempt+1, policy.MaxAttempts, err)))
+
+		select {
+		case <-time.After(delay):
+		case <-se.ctx.Done():
+			return status, stepComplete, err
+		}
+	}
+}
+
+// spendRetry returns true if the step may be retried without exceeding the retry budget for its package, if there is
+// one, and counts the retry against the budget.
+func (se *stepExecutor) spendRetry(step Step) bool {
+	budget, limited := se.opts.Retries.Budgets[stepPackage(step)]
+	if !limited {
+		return true
+	}
+
+	se.retriesLock.Lock()
+	defer se.retriesLock.Unlock()
+	if se.retries[stepPackage(step)] >= budget {
+		return false
+	}
+	se.retries[stepPackage(step)]++
+	return true
+}
+
+// acquireProviderSlot blocks until the step may talk to its provider without exceeding the concurrency limit for its
+// package, if there is one, and returns a function that gives the slot back. Same steps and steps for component
+// resources never call a provider and so are not limited.
+func (se *stepExecutor) acquireProviderSlot(workerID int, step Step) (func(), error) {
+	slots, limited := se.providerSlots[stepPackage(step)]
+	if !limited || step.Op() == OpSame || !step.Res().Custom {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+	default:
+		se.log(workerID, "step %v on %v waiting for a %s concurrency slot", step.Op(), step.URN(), stepPackage(step))
+		select {
+		case slots <- struct{}{}:
+		case <-se.ctx.Done():
+			return nil, errors.New("plan was canceled while waiting for a provider concurrency slot")
+		}
+	}
+	return func() { <-slots }, nil
+}
+
 // log is a simple logging helper for the step executor.
 func (se *stepExecutor) log(workerID int, msg string, args ...interface{}) {
 	if logging.V(stepExecutorLogLevel) {
@@ -379,10 +505,19 @@ func newStepExecutor(ctx context.Context, cancel context.CancelFunc, plan *Plan,
 		incomingChains:  make(chan incomingChain),
 		ctx:             ctx,
 		cancel:          cancel,
+		retries:         make(map[string]int),
 	}
 
 	exec.sawError.Store(false)
 
+	if len(opts.ProviderConcurrency) > 0 {
+		exec.providerSlots = make(map[string]chan struct{})
+		for pkg, limit := range opts.ProviderConcurrency {
+			contract.Assertf(limit > 0, "concurrency limit for '%s' must be positive", pkg)
+			exec.providerSlots[pkg] = make(chan struct{}, limit)
+		}
+	}
+
 	// If we're being asked to run as parallel as possible, spawn a single worker that launches chain executions
 	// asynchronously.
 	if opts.InfiniteParallelism() {
@@ -15,6 +15,8 @@
 package deploy
 
 import (
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/resource"
@@ -47,6 +49,15 @@ type stepGenerator struct {
 	// a map from URN to a list of property keys that caused the replacement of a dependent resource during a
 	// delete-before-replace.
 	dependentReplaceKeys map[resource.URN][]resource.PropertyKey
+
+	// the set of URNs of resources that must be replaced regardless of their diffs.
+	replaceTargets map[resource.URN]bool
+
+	// the set of URNs of resources completed by the failed update that this plan is resuming, if any.
+	resumed map[resource.URN]bool
+
+	// the set of URNs of resources that were excluded from this plan, along with those that depend on them.
+	excluded map[resource.URN]bool
 }
 
 // GenerateReadSteps is responsible for producing one or more steps required to service
@@ -66,8 +77,9 @@ func (sg *stepGenerator) GenerateReadSteps(event ReadResourceEvent) ([]Step, *re
 		event.Dependencies(),
 		nil, /* initErrors */
 		event.Provider(),
-		nil, /* propertyDependencies */
-		false)
+		nil,   /* propertyDependencies */
+		false, /* pendingReplacement */
+		resource.CustomTimeouts{})
 	old, hasOld := sg.plan.Olds()[urn]
 
 	// If the snapshot has an old resource for this URN and it's not external, we're going
@@ -89,7 +101,7 @@ func (sg *stepGenerator) GenerateReadSteps(event ReadResourceEvent) ([]Step, *re
 		sg.replaces[urn] = true
 		return []Step{
 			NewReadReplacementStep(sg.plan, event, old, newState),
-			NewReplaceStep(sg.plan, old, newState, nil, nil, true),
+			NewReplaceStep(sg.plan, old, newState, nil, nil, nil, true),
 		}, nil
 	}
 
@@ -131,11 +143,16 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, *re
 		oldOutputs = old.Outputs
 	}
 
+	// If the resource asked that changes to some of its properties be ignored, use the old values of those properties.
+	inputs := goal.Properties
+	if hasOld && len(goal.IgnoreChanges) > 0 {
+		inputs = processIgnoreChanges(inputs, oldInputs, goal.IgnoreChanges)
+	}
+
 	// Produce a new state object that we'll build up as operations are performed.  Ultimately, this is what will
 	// get serialized into the checkpoint file.
-	inputs := goal.Properties
 	new := resource.NewState(goal.Type, urn, goal.Custom, false, "", inputs, nil, goal.Parent, goal.Protect, false,
-		goal.Dependencies, goal.InitErrors, goal.Provider, goal.PropertyDependencies, false)
+		goal.Dependencies, goal.InitErrors, goal.Provider, goal.PropertyDependencies, false, goal.CustomTimeouts)
 
 	// Fetch the provider for this resource.
 	prov, err := sg.getResourceProvider(urn, goal.Custom, goal.Provider, goal.Type)
@@ -152,8 +169,29 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, *re
 	// We may be creating this resource if it previously existed in the snapshot as an External resource
 	wasExternal := hasOld && old.External
 
-	// Ensure the provider is okay with this resource and fetch the inputs to pass to subsequent methods.
-	if prov != nil {
+	// If we are resuming an update that already brought this resource up to date, or if the resource belongs to a
+	// later phase of a staged rollout, reuse its checked inputs rather than asking the provider to check it again.
+	// With the inputs unchanged, the resource will not be diffed either.
+	reusable := hasOld && !recreating && !wasExternal && old.Provider == goal.Provider
+	if reusable && sg.resumed[urn] {
+		logging.V(7).Infof("Planner skipping check of '%v', which was completed by the update being resumed", urn)
+		inputs = oldInputs
+		new.Inputs = inputs
+	} else if sg.isExcluded(urn, goal) {
+		if !reusable {
+			return nil, result.Errorf("cannot exclude resource '%v' from the update: "+
+				"it depends on an excluded resource but must be created", urn)
+		}
+		logging.V(7).Infof("Planner leaving '%v' unchanged because it was excluded from the update", urn)
+		sg.excluded[urn] = true
+		inputs = oldInputs
+		new.Inputs = inputs
+	} else if reusable && isHeld(old, sg.opts) {
+		logging.V(7).Infof("Planner holding back changes to '%v' until its rollout phase", urn)
+		inputs = oldInputs
+		new.Inputs = inputs
+	} else if prov != nil {
+		// Ensure the provider is okay with this resource and fetch the inputs to pass to subsequent methods.
 		var failures []plugin.CheckFailure
 
 		// If we are re-creating this resource because it was deleted earlier, the old inputs are now
@@ -222,8 +260,8 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, *re
 		sg.replaces[urn] = true
 		keys := sg.dependentReplaceKeys[urn]
 		return []Step{
-			NewReplaceStep(sg.plan, old, new, nil, nil, false),
-			NewCreateReplacementStep(sg.plan, event, old, new, keys, nil, false),
+			NewReplaceStep(sg.plan, old, new, nil, nil, nil, false),
+			NewCreateReplacementStep(sg.plan, event, old, new, keys, nil, nil, false),
 		}, nil
 	}
 
@@ -243,8 +281,8 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, *re
 		}
 
 		return []Step{
-			NewCreateReplacementStep(sg.plan, event, old, new, nil, nil, true),
-			NewReplaceStep(sg.plan, old, new, nil, nil, true),
+			NewCreateReplacementStep(sg.plan, event, old, new, nil, nil, nil, true),
+			NewReplaceStep(sg.plan, old, new, nil, nil, nil, true),
 		}, nil
 	}
 
@@ -262,7 +300,11 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, *re
 
 		var diff plugin.DiffResult
 		if old.Provider != new.Provider {
-			diff = plugin.DiffResult{Changes: plugin.DiffSome, ReplaceKeys: []resource.PropertyKey{"provider"}}
+			diff = plugin.DiffResult{
+				Changes:        plugin.DiffSome,
+				ReplaceKeys:    []resource.PropertyKey{"provider"},
+				ReplaceReasons: map[resource.PropertyKey]string{"provider": "the resource's provider changed"},
+			}
 		} else {
 			// Determine whether the change resulted in a diff.
 			d, diffErr := sg.diff(urn, old.ID, oldInputs, oldOutputs, inputs, prov, allowUnknowns)
@@ -279,6 +321,16 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, *re
 			diff = d
 		}
 
+		// If the resource was named as a target of a forced replacement, replace it regardless of its diff.
+		if sg.replaceTargets[urn] && goal.Custom && !sg.excluded[urn] {
+			logging.V(7).Infof("Planner forcing replacement of '%v'", urn)
+			diff.Changes = plugin.DiffSome
+			if !diff.Replace() {
+				diff.ReplaceKeys = []resource.PropertyKey{"id"}
+				diff.ReplaceReasons = map[resource.PropertyKey]string{"id": "the resource was targeted for replacement"}
+			}
+		}
+
 		// Ensure that we received a sensible response.
 		if diff.Changes != plugin.DiffNone && diff.Changes != plugin.DiffSome {
 			return nil, result.Errorf(
@@ -363,14 +415,17 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, *re
 
 					return append(steps,
 						NewDeleteReplacementStep(sg.plan, old, true),
-						NewReplaceStep(sg.plan, old, new, diff.ReplaceKeys, diff.ChangedKeys, false),
-						NewCreateReplacementStep(sg.plan, event, old, new, diff.ReplaceKeys, diff.ChangedKeys, false),
+						NewReplaceStep(sg.plan, old, new,
+							diff.ReplaceKeys, diff.ChangedKeys, diff.ReplaceReasons, false),
+						NewCreateReplacementStep(sg.plan, event, old, new,
+							diff.ReplaceKeys, diff.ChangedKeys, diff.ReplaceReasons, false),
 					), nil
 				}
 
 				return []Step{
-					NewCreateReplacementStep(sg.plan, event, old, new, diff.ReplaceKeys, diff.ChangedKeys, true),
-					NewReplaceStep(sg.plan, old, new, diff.ReplaceKeys, diff.ChangedKeys, true),
+					NewCreateReplacementStep(sg.plan, event, old, new,
+						diff.ReplaceKeys, diff.ChangedKeys, diff.ReplaceReasons, true),
+					NewReplaceStep(sg.plan, old, new, diff.ReplaceKeys, diff.ChangedKeys, diff.ReplaceReasons, true),
 					// note that the delete step is generated "later" on, after all creates/updates finish.
 				}, nil
 			}
@@ -400,8 +455,13 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, *re
 
 	// Case 4: Not Case 1, 2, or 3
 	//  If a resource isn't being recreated and it's not being updated or replaced,
-	//  it's just being created.
+	//  it's just being created. If the program named an existing resource to import,
+	//  that resource is adopted instead.
 	sg.creates[urn] = true
+	if goal.ImportID != "" {
+		logging.V(7).Infof("Planner decided to import '%v' (id=%v, inputs=%v)", urn, goal.ImportID, new.Inputs)
+		return []Step{NewImportStep(sg.plan, event, new, goal.ImportID)}, nil
+	}
 	logging.V(7).Infof("Planner decided to create '%v' (inputs=%v)", urn, new.Inputs)
 	return []Step{NewCreateStep(sg.plan, event, new)}, nil
 }
@@ -412,6 +472,13 @@ func (sg *stepGenerator) GenerateDeletes() []Step {
 	// dependencies prior to their dependent nodes.
 	var dels []Step
 	if prev := sg.plan.prev; prev != nil {
+		// If only specific resources are being destroyed, resources outside of that set are left alone even though they
+		// were not seen.
+		var targeted map[resource.URN]bool
+		if len(sg.opts.DestroyTargets) > 0 {
+			targeted = computeDependents(prev.Resources, sg.opts.DestroyTargets)
+		}
+
 		for i := len(prev.Resources) - 1; i >= 0; i-- {
 			// If this resource is explicitly marked for deletion or wasn't seen at all, delete it.
 			res := prev.Resources[i]
@@ -446,7 +513,8 @@ func (sg *stepGenerator) GenerateDeletes() []Step {
 				logging.V(7).Infof("Planner decided to delete '%v' due to replacement", res.URN)
 				sg.deletes[res.URN] = true
 				dels = append(dels, NewDeleteReplacementStep(sg.plan, res, false))
-			} else if !sg.sames[res.URN] && !sg.updates[res.URN] && !sg.replaces[res.URN] && !sg.reads[res.URN] {
+			} else if !sg.sames[res.URN] && !sg.updates[res.URN] && !sg.replaces[res.URN] && !sg.reads[res.URN] &&
+				(targeted == nil || targeted[res.URN]) && !isHeld(res, sg.opts) && !sg.excluded[res.URN] {
 				// NOTE: we deliberately do not check sg.deletes here, as it is possible for us to issue multiple
 				// delete steps for the same URN if the old checkpoint contained pending deletes.
 				logging.V(7).Infof("Planner decided to delete '%v'", res.URN)
@@ -462,6 +530,64 @@ func (sg *stepGenerator) GenerateDeletes() []Step {
 	return dels
 }
 
+// computeDependents returns the set of resources that are affected by changes to the given targets: the targets
+// themselves, along with any resources that depend on them, are managed by them, or are descended from them.
+func computeDependents(resources []*resource.State, targets []resource.URN) map[resource.URN]bool {
+	result := make(map[resource.URN]bool)
+	for _, urn := range targets {
+		result[urn] = true
+	}
+
+	// Resources are stored in dependency order, with parents preceding their children, so a single forward pass
+	// suffices to find every resource that transitively depends upon a target.
+	for _, res := range resources {
+		if result[res.URN] {
+			continue
+		}
+		if res.Parent != "" && result[res.Parent] {
+			result[res.URN] = true
+			continue
+		}
+		if res.Provider != "" {
+			ref, err := providers.ParseReference(res.Provider)
+			contract.Assert(err == nil)
+			if result[ref.URN()] {
+				result[res.URN] = true
+				continue
+			}
+		}
+		for _, dep := range res.Dependencies {
+			if result[dep] {
+				result[res.URN] = true
+				break
+			}
+		}
+	}
+	return result
+}
+
+// isExcluded returns true if the resource with the given URN and goal must be left unchanged by this plan, either
+// because it was excluded itself or because it depends on, is managed by, or is descended from an excluded resource.
+func (sg *stepGenerator) isExcluded(urn resource.URN, goal *resource.Goal) bool {
+	if len(sg.excluded) == 0 {
+		return false
+	}
+	if sg.excluded[urn] || (goal.Parent != "" && sg.excluded[goal.Parent]) {
+		return true
+	}
+	if goal.Provider != "" {
+		if ref, err := providers.ParseReference(goal.Provider); err == nil && sg.excluded[ref.URN()] {
+			return true
+		}
+	}
+	for _, dep := range goal.Dependencies {
+		if sg.excluded[dep] {
+			return true
+		}
+	}
+	return false
+}
+
 // GeneratePendingDeletes generates delete steps for all resources that are pending deletion. This function should be
 // called at the start of a plan in order to find all resources that are pending deletion from the prevous plan.
 func (sg *stepGenerator) GeneratePendingDeletes() []Step {
@@ -742,8 +868,66 @@ func (sg *stepGenerator) calculateDependentReplacements(root *resource.State) ([
 	return toReplace, nil
 }
 
+// processIgnoreChanges returns a copy of inputs in which the value of each property path in ignoreChanges has been
+// replaced with its value in oldInputs. This has the effect of ensuring that no changes will be made to the
+// corresponding properties. Each path is a dotted list of property names that selects a top-level property (e.g.
+// "desiredCount") or a property of a nested object (e.g. "tags.owner").
+func processIgnoreChanges(inputs, oldInputs resource.PropertyMap, ignoreChanges []string) resource.PropertyMap {
+	for _, path := range ignoreChanges {
+		inputs = ignoreChange(inputs, oldInputs, strings.Split(path, "."))
+	}
+	return inputs
+}
+
+// ignoreChange returns a copy of news in which the property at the given path has its value from olds. If olds does
+// not have a value at the path, the property is removed. If news does not have an object at some element of the
+// path, there is nothing to preserve the value in and news is returned unchanged.
+func ignoreChange(news, olds resource.PropertyMap, path []string) resource.PropertyMap {
+	key := resource.PropertyKey(path[0])
+	old, hasOld := olds[key]
+
+	result := news.Copy()
+	if len(path) == 1 {
+		if hasOld {
+			result[key] = old
+		} else {
+			delete(result, key)
+		}
+		return result
+	}
+
+	new, hasNew := news[key]
+	if !hasNew || !new.IsObject() {
+		return news
+	}
+	var oldObj resource.PropertyMap
+	if hasOld && old.IsObject() {
+		oldObj = old.ObjectValue()
+	}
+	result[key] = resource.NewObjectProperty(ignoreChange(new.ObjectValue(), oldObj, path[1:]))
+	return result
+}
+
 // newStepGenerator creates a new step generator that operates on the given plan.
 func newStepGenerator(plan *Plan, opts Options) *stepGenerator {
+	replaceTargets := make(map[resource.URN]bool)
+	for _, urn := range opts.ReplaceTargets {
+		replaceTargets[urn] = true
+	}
+
+	var excluded map[resource.URN]bool
+	if len(opts.ExcludeTargets) > 0 && plan.prev != nil {
+		excluded = computeDependents(plan.prev.Resources, opts.ExcludeTargets)
+	}
+
+	var resumed map[resource.URN]bool
+	if opts.Resume && plan.prev != nil {
+		resumed = make(map[resource.URN]bool)
+		for _, urn := range plan.prev.CompletedResources {
+			resumed[urn] = true
+		}
+	}
+
 	return &stepGenerator{
 		plan:                 plan,
 		opts:                 opts,
@@ -756,5 +940,8 @@ func newStepGenerator(plan *Plan, opts Options) *stepGenerator {
 		deletes:              make(map[resource.URN]bool),
 		pendingDeletes:       make(map[*resource.State]bool),
 		dependentReplaceKeys: make(map[resource.URN][]resource.PropertyKey),
+		replaceTargets:       replaceTargets,
+		resumed:              resumed,
+		excluded:             excluded,
 	}
 }
@@ -0,0 +1,57 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+func TestProcessIgnoreChanges(t *testing.T) {
+	olds := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"desiredCount": 3,
+		"name":         "old",
+		"tags": map[string]interface{}{
+			"owner": "controller",
+			"env":   "prod",
+		},
+	})
+	news := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"desiredCount": 1,
+		"name":         "new",
+		"tags": map[string]interface{}{
+			"env": "dev",
+		},
+		"added": true,
+	})
+
+	ignored := processIgnoreChanges(news, olds, []string{"desiredCount", "tags.owner", "added", "missing.nested"})
+	assert.Equal(t, resource.NewPropertyMapFromMap(map[string]interface{}{
+		"desiredCount": 3,
+		"name":         "new",
+		"tags": map[string]interface{}{
+			"owner": "controller",
+			"env":   "dev",
+		},
+	}), ignored)
+
+	// The original inputs must not have been modified.
+	assert.Equal(t, resource.NewNumberProperty(1), news["desiredCount"])
+	assert.False(t, news["tags"].ObjectValue().HasValue("owner"))
+	assert.True(t, news.HasValue("added"))
+}
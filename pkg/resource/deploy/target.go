@@ -21,10 +21,11 @@ import (
 
 // Target represents information about a deployment target.
 type Target struct {
-	Name      tokens.QName     // the target stack name.
-	Config    config.Map       // optional configuration key/value pairs.
-	Decrypter config.Decrypter // decrypter for secret configuration values.
-	Snapshot  *Snapshot        // the last snapshot deployed to the target.
+	Name        tokens.QName                         // the target stack name.
+	Config      config.Map                           // optional configuration key/value pairs.
+	Decrypter   config.Decrypter                     // decrypter for secret configuration values.
+	Snapshot    *Snapshot                            // the last snapshot deployed to the target.
+	Credentials map[tokens.Package]map[string]string // optional credentials for providers; never persisted.
 }
 
 // GetPackageConfig returns the set of configuration parameters for the indicated package, if any.
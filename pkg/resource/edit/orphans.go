@@ -0,0 +1,124 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edit
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// FindUnreachableResources returns the resources in the given snapshot that cannot be reached from the root stack
+// resource by following parent links, e.g. because they were parented to a resource that was removed from the state
+// by hand. Provider resources and resources that are pending deletion are never considered unreachable, nor is
+// anything in a snapshot that has no root stack resource at all, as is the case for very old stacks.
+func FindUnreachableResources(snapshot *deploy.Snapshot) []*resource.State {
+	contract.Require(snapshot != nil, "snapshot")
+
+	// Resources are stored in dependency order, so a resource's parent always precedes it. This means that we can
+	// decide reachability in a single pass.
+	var root *resource.State
+	reachable := make(map[resource.URN]bool)
+	for _, res := range snapshot.Resources {
+		if res.Type == resource.RootStackType && res.Parent == "" {
+			root = res
+			reachable[res.URN] = true
+		}
+	}
+	if root == nil {
+		return nil
+	}
+
+	var unreachable []*resource.State
+	for _, res := range snapshot.Resources {
+		switch {
+		case res == root || res.Delete || providers.IsProviderType(res.Type):
+			continue
+		case res.Parent != "" && reachable[res.Parent]:
+			reachable[res.URN] = true
+		default:
+			unreachable = append(unreachable, res)
+		}
+	}
+	return unreachable
+}
+
+// FindMissingResources asks the provider of each custom resource in the given snapshot whether the resource still
+// exists, and returns the resources that their providers report have been deleted. Providers are loaded and
+// configured using the given plugin host and the provider resources in the snapshot. Resources that do not refer to
+// a provider resource, as is the case for very old stacks, are skipped.
+func FindMissingResources(host plugin.Host, snapshot *deploy.Snapshot) ([]*resource.State, error) {
+	contract.Require(host != nil, "host")
+	contract.Require(snapshot != nil, "snapshot")
+
+	reg, err := providers.NewRegistry(host, snapshot.Resources, false /*isPreview*/, nil /*builtins*/)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []*resource.State
+	for _, res := range snapshot.Resources {
+		if !res.Custom || res.Delete || res.ID == "" || res.Provider == "" || providers.IsProviderType(res.Type) {
+			continue
+		}
+
+		ref, err := providers.ParseReference(res.Provider)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing provider reference for resource '%v'", res.URN)
+		}
+		prov, ok := reg.GetProvider(ref)
+		if !ok {
+			return nil, errors.Errorf("unknown provider '%v' for resource '%v'", ref, res.URN)
+		}
+
+		result, _, err := prov.Read(res.URN, res.ID, res.Inputs, res.Outputs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading resource '%v'", res.URN)
+		}
+		if result.Outputs == nil {
+			missing = append(missing, res)
+		}
+	}
+	return missing, nil
+}
+
+// DeleteResources deletes the given resources from the snapshot. Resources are deleted in reverse dependency order, so
+// the condemned set may include resources that depend upon one another. Resources that cannot be deleted, because
+// they are protected or because resources outside of the condemned set depend upon them, are left in place and
+// returned along with the reason they could not be deleted.
+func DeleteResources(snapshot *deploy.Snapshot, condemned []*resource.State) map[*resource.State]error {
+	contract.Require(snapshot != nil, "snapshot")
+
+	isCondemned := make(map[*resource.State]bool)
+	for _, res := range condemned {
+		isCondemned[res] = true
+	}
+
+	failures := make(map[*resource.State]error)
+	for i := len(snapshot.Resources) - 1; i >= 0; i-- {
+		res := snapshot.Resources[i]
+		if !isCondemned[res] {
+			continue
+		}
+		if err := DeleteResource(snapshot, res); err != nil {
+			failures[res] = err
+		}
+	}
+	return failures
+}
@@ -0,0 +1,101 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edit
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+)
+
+func NewRootStackResource() *resource.State {
+	return &resource.State{
+		Type:    resource.RootStackType,
+		URN:     resource.NewURN("test", "test", "", resource.RootStackType, "test-test"),
+		Inputs:  resource.PropertyMap{},
+		Outputs: resource.PropertyMap{},
+	}
+}
+
+func TestFindUnreachableResources(t *testing.T) {
+	root := NewRootStackResource()
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	a.Parent = root.URN
+	b := NewResource("b", pA)
+	c := NewResource("c", pA)
+	c.Parent = b.URN
+	d := NewResource("d", pA)
+	d.Parent = root.URN
+	d.Delete = true
+
+	snap := NewSnapshot([]*resource.State{root, pA, a, b, c, d})
+	assert.Equal(t, []*resource.State{b, c}, FindUnreachableResources(snap))
+
+	// Without a root stack resource, nothing is considered unreachable.
+	snap = NewSnapshot([]*resource.State{pA, a, b, c, d})
+	assert.Empty(t, FindUnreachableResources(snap))
+}
+
+func TestFindMissingResources(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	a.Custom, a.ID = true, "a-id"
+	b := NewResource("b", pA)
+	b.Custom, b.ID = true, "b-id"
+	c := NewResource("c", nil)
+	c.Custom, c.ID = true, "c-id"
+
+	loader := deploytest.NewProviderLoader("a", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+		return &deploytest.Provider{
+			ReadF: func(urn resource.URN, id resource.ID,
+				inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+				if id == "b-id" {
+					return plugin.ReadResult{}, resource.StatusOK, nil
+				}
+				return plugin.ReadResult{Outputs: state}, resource.StatusOK, nil
+			},
+		}, nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, nil, loader)
+
+	missing, err := FindMissingResources(host, NewSnapshot([]*resource.State{pA, a, b, c}))
+	assert.NoError(t, err)
+	assert.Equal(t, []*resource.State{b}, missing)
+}
+
+func TestDeleteResources(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA, a.URN)
+	c := NewResource("c", pA)
+	d := NewResource("d", pA, c.URN)
+	e := NewResource("e", pA)
+	e.Protect = true
+	snap := NewSnapshot([]*resource.State{pA, a, b, c, d, e})
+
+	// a and b can be deleted together, but c cannot be deleted while d depends upon it.
+	failures := DeleteResources(snap, []*resource.State{a, b, c, e})
+	assert.Equal(t, []*resource.State{pA, c, d, e}, snap.Resources)
+	assert.Len(t, failures, 2)
+	assert.IsType(t, ResourceHasDependenciesError{}, failures[c])
+	assert.IsType(t, ResourceProtectedError{}, failures[e])
+}
@@ -0,0 +1,108 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edit
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// PendingDeletions returns the resources in the given snapshot that are pending deletion. Resources end up in this
+// state when they are replaced but the deletion of the old resource fails.
+func PendingDeletions(snapshot *deploy.Snapshot) []*resource.State {
+	contract.Require(snapshot != nil, "snapshot")
+
+	var pending []*resource.State
+	for _, res := range snapshot.Resources {
+		if res.Delete {
+			pending = append(pending, res)
+		}
+	}
+	return pending
+}
+
+// ForgetPendingDeletion removes a resource that is pending deletion from the snapshot, without deleting the resource
+// itself. Dependencies always refer to the live resource with a given URN, so nothing in the snapshot can depend upon
+// a resource that is pending deletion.
+func ForgetPendingDeletion(snapshot *deploy.Snapshot, res *resource.State) error {
+	contract.Require(snapshot != nil, "snapshot")
+	contract.Require(res != nil, "res")
+
+	if !res.Delete {
+		return errors.Errorf("resource '%v' is not pending deletion", res.URN)
+	}
+
+	var resources []*resource.State
+	for _, r := range snapshot.Resources {
+		if r != res {
+			resources = append(resources, r)
+		}
+	}
+	snapshot.Resources = resources
+	return nil
+}
+
+// RetryPendingDeletions asks the providers of the given resources, which must be pending deletion, to delete them
+// again, and removes each resource that is successfully deleted from the snapshot. Providers are loaded and
+// configured using the given plugin host and the provider resources in the snapshot. Resources that could not be
+// deleted are left in place and returned along with the reason they could not be deleted.
+func RetryPendingDeletions(host plugin.Host, snapshot *deploy.Snapshot,
+	condemned []*resource.State) (map[*resource.State]error, error) {
+
+	contract.Require(host != nil, "host")
+	contract.Require(snapshot != nil, "snapshot")
+
+	reg, err := providers.NewRegistry(host, snapshot.Resources, false /*isPreview*/, nil /*builtins*/)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make(map[*resource.State]error)
+	for _, res := range condemned {
+		if !res.Delete {
+			failures[res] = errors.Errorf("resource '%v' is not pending deletion", res.URN)
+			continue
+		}
+
+		if res.Custom {
+			if res.Provider == "" {
+				failures[res] = errors.Errorf("resource '%v' does not refer to a provider", res.URN)
+				continue
+			}
+			ref, err := providers.ParseReference(res.Provider)
+			if err != nil {
+				failures[res] = err
+				continue
+			}
+			prov, ok := reg.GetProvider(ref)
+			if !ok {
+				failures[res] = errors.Errorf("unknown provider '%v'", ref)
+				continue
+			}
+			if _, err = prov.Delete(res.URN, res.ID, res.Outputs, res.CustomTimeouts.Delete); err != nil {
+				failures[res] = err
+				continue
+			}
+		}
+
+		contract.AssertNoError(ForgetPendingDeletion(snapshot, res))
+	}
+	return failures, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+)
+
+func TestForgetPendingDeletion(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	aOld := NewResource("a", pA)
+	aOld.Delete = true
+	b := NewResource("b", pA, a.URN)
+	snap := NewSnapshot([]*resource.State{pA, aOld, a, b})
+
+	assert.Equal(t, []*resource.State{aOld}, PendingDeletions(snap))
+
+	assert.Error(t, ForgetPendingDeletion(snap, a))
+	assert.NoError(t, ForgetPendingDeletion(snap, aOld))
+	assert.Equal(t, []*resource.State{pA, a, b}, snap.Resources)
+	assert.Empty(t, PendingDeletions(snap))
+}
+
+func TestRetryPendingDeletions(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	a.Custom, a.ID = true, "a-id"
+	aOld1 := NewResource("a", pA)
+	aOld1.Custom, aOld1.ID, aOld1.Delete = true, "a-old-1", true
+	aOld2 := NewResource("a", pA)
+	aOld2.Custom, aOld2.ID, aOld2.Delete = true, "a-old-2", true
+	snap := NewSnapshot([]*resource.State{pA, aOld1, aOld2, a})
+
+	var deleted []resource.ID
+	loader := deploytest.NewProviderLoader("a", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+		return &deploytest.Provider{
+			DeleteF: func(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+				timeout float64) (resource.Status, error) {
+				if id == "a-old-2" {
+					return resource.StatusOK, errors.New("still in use")
+				}
+				deleted = append(deleted, id)
+				return resource.StatusOK, nil
+			},
+		}, nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, nil, loader)
+
+	failures, err := RetryPendingDeletions(host, snap, []*resource.State{aOld1, aOld2, a})
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.ID{"a-old-1"}, deleted)
+	assert.Len(t, failures, 2)
+	assert.EqualError(t, failures[aOld2], "still in use")
+	assert.Error(t, failures[a])
+	assert.Equal(t, []*resource.State{pA, aOld2, a}, snap.Resources)
+}
@@ -0,0 +1,230 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/tokens"
+	"github.com/pulumi/pulumi/pkg/workspace"
+)
+
+// MockFixtures configures the responses of the mock providers that stand in for real resource providers when mock
+// providers are enabled, so that programs and plans can be exercised without credentials for any cloud.
+type MockFixtures struct {
+	// Resources maps a resource URN, or a resource type token that applies to every resource of that type, to the
+	// responses that a mock provider makes for the resource. URNs take precedence over type tokens.
+	Resources map[string]MockResource `json:"resources,omitempty"`
+	// Invokes maps a function token to the outputs that a mock provider returns when the function is invoked.
+	Invokes map[string]map[string]interface{} `json:"invokes,omitempty"`
+}
+
+// MockResource describes the responses that a mock provider makes for a resource.
+type MockResource struct {
+	// ID is the ID that the resource is given when it is created. If empty, an ID is synthesized from the resource's
+	// name and URN.
+	ID string `json:"id,omitempty"`
+	// Outputs are added to the resource's inputs to form its outputs when it is created or updated.
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+	// ReplaceOnChanges lists the input properties whose changes require the resource to be replaced.
+	ReplaceOnChanges []string `json:"replaceOnChanges,omitempty"`
+}
+
+// LoadMockFixtures reads mock provider fixtures from the JSON file at the given path.
+func LoadMockFixtures(path string) (*MockFixtures, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading mock provider fixtures")
+	}
+	var fixtures MockFixtures
+	if err = json.Unmarshal(b, &fixtures); err != nil {
+		return nil, errors.Wrapf(err, "could not parse mock provider fixtures %s", path)
+	}
+	return &fixtures, nil
+}
+
+// resource returns the fixture for the resource with the given URN, or an empty fixture if there is none.
+func (fixtures *MockFixtures) resource(urn resource.URN) MockResource {
+	if fixtures == nil {
+		return MockResource{}
+	}
+	if res, has := fixtures.Resources[string(urn)]; has {
+		return res
+	}
+	return fixtures.Resources[string(urn.Type())]
+}
+
+// NewMockProviderHost returns a host that loads analyzers and language runtimes from the given host, but that replaces
+// every resource provider with a mock that responds according to the given fixtures.
+func NewMockProviderHost(host Host, fixtures *MockFixtures) Host {
+	return &mockProviderHost{Host: host, fixtures: fixtures}
+}
+
+type mockProviderHost struct {
+	Host
+	fixtures *MockFixtures
+}
+
+func (host *mockProviderHost) Provider(pkg tokens.Package, version *semver.Version) (Provider, error) {
+	return NewMockProvider(pkg, host.fixtures), nil
+}
+
+func (host *mockProviderHost) CloseProvider(provider Provider) error {
+	return provider.Close()
+}
+
+func (host *mockProviderHost) EnsurePlugins(plugins []workspace.PluginInfo, kinds Flags) error {
+	return host.Host.EnsurePlugins(plugins, kinds&^ResourcePlugins)
+}
+
+// NewMockProvider returns a provider for the given package that manages no real resources. It accepts any
+// configuration and inputs, reports changes to inputs as updates, and gives the resources it creates the IDs and
+// outputs that the given fixtures specify.
+func NewMockProvider(pkg tokens.Package, fixtures *MockFixtures) Provider {
+	return &mockProvider{pkg: pkg, fixtures: fixtures}
+}
+
+type mockProvider struct {
+	pkg      tokens.Package
+	fixtures *MockFixtures
+}
+
+func (p *mockProvider) Close() error {
+	return nil
+}
+
+func (p *mockProvider) Pkg() tokens.Package {
+	return p.pkg
+}
+
+func (p *mockProvider) CheckConfig(olds, news resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error) {
+	return news, nil, nil
+}
+
+func (p *mockProvider) DiffConfig(olds, news resource.PropertyMap) (DiffResult, error) {
+	return DiffResult{}, nil
+}
+
+func (p *mockProvider) Configure(inputs resource.PropertyMap) error {
+	return nil
+}
+
+func (p *mockProvider) Check(urn resource.URN, olds, news resource.PropertyMap,
+	allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
+	return news, nil, nil
+}
+
+func (p *mockProvider) Diff(urn resource.URN, id resource.ID, olds resource.PropertyMap, news resource.PropertyMap,
+	allowUnknowns bool) (DiffResult, error) {
+
+	diff := olds.Diff(news)
+	if diff == nil {
+		return DiffResult{Changes: DiffNone}, nil
+	}
+
+	replaceOnChanges := make(map[resource.PropertyKey]bool)
+	for _, k := range p.fixtures.resource(urn).ReplaceOnChanges {
+		replaceOnChanges[resource.PropertyKey(k)] = true
+	}
+	var changed, replaces []resource.PropertyKey
+	for _, k := range diff.Keys() {
+		changed = append(changed, k)
+		if replaceOnChanges[k] {
+			replaces = append(replaces, k)
+		}
+	}
+	return DiffResult{Changes: DiffSome, ChangedKeys: changed, ReplaceKeys: replaces}, nil
+}
+
+func (p *mockProvider) Create(urn resource.URN, news resource.PropertyMap,
+	timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+	fixture := p.fixtures.resource(urn)
+	id := resource.ID(fixture.ID)
+	if id == "" {
+		h := fnv.New32a()
+		_, err := h.Write([]byte(urn))
+		if err != nil {
+			return "", nil, resource.StatusOK, err
+		}
+		id = resource.ID(fmt.Sprintf("%s-%08x", urn.Name(), h.Sum32()))
+	}
+	return id, p.outputs(news, fixture), resource.StatusOK, nil
+}
+
+func (p *mockProvider) Read(urn resource.URN, id resource.ID,
+	inputs, state resource.PropertyMap) (ReadResult, resource.Status, error) {
+
+	if state == nil {
+		state = p.outputs(inputs, p.fixtures.resource(urn))
+	}
+	return ReadResult{Inputs: inputs, Outputs: state}, resource.StatusOK, nil
+}
+
+func (p *mockProvider) Update(urn resource.URN, id resource.ID, olds resource.PropertyMap, news resource.PropertyMap,
+	timeout float64) (resource.PropertyMap, resource.Status, error) {
+	return p.outputs(news, p.fixtures.resource(urn)), resource.StatusOK, nil
+}
+
+func (p *mockProvider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
+	return resource.StatusOK, nil
+}
+
+func (p *mockProvider) Invoke(tok tokens.ModuleMember,
+	args resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error) {
+
+	if p.fixtures == nil {
+		return resource.PropertyMap{}, nil, nil
+	}
+	return resource.NewPropertyMapFromMap(p.fixtures.Invokes[string(tok)]), nil, nil
+}
+
+func (p *mockProvider) GetPluginInfo() (workspace.PluginInfo, error) {
+	return workspace.PluginInfo{
+		Name: string(p.pkg),
+		Kind: workspace.ResourcePlugin,
+	}, nil
+}
+
+func (p *mockProvider) GetSchema(version int) ([]byte, error) {
+	return nil, errors.Errorf("the mock provider for package %s has no schema", p.pkg)
+}
+
+func (p *mockProvider) GetLogs(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	startTime, endTime *time.Time) ([]LogEntry, error) {
+	return nil, nil
+}
+
+func (p *mockProvider) SignalCancellation() error {
+	return nil
+}
+
+// outputs returns the outputs of a resource with the given inputs: the inputs, overlaid with the fixture's outputs.
+func (p *mockProvider) outputs(inputs resource.PropertyMap, fixture MockResource) resource.PropertyMap {
+	outputs := inputs.Copy()
+	for k, v := range resource.NewPropertyMapFromMap(fixture.Outputs) {
+		outputs[k] = v
+	}
+	return outputs
+}
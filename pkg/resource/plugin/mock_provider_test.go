@@ -0,0 +1,72 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+)
+
+func TestLoadMockFixtures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mock-fixtures")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "fixtures.json")
+	err = ioutil.WriteFile(path, []byte(`{
+		"resources": {
+			"pkgA:m:typA": {"id": "a-123", "outputs": {"arn": "arn:a"}},
+			"urn:pulumi:stack::proj::pkgA:m:typA::special": {"id": "special-1"}
+		},
+		"invokes": {"pkgA:index:getRegion": {"name": "us-west-2"}}
+	}`), 0600)
+	assert.NoError(t, err)
+	fixtures, err := LoadMockFixtures(path)
+	assert.NoError(t, err)
+
+	prov := NewMockProvider("pkgA", fixtures)
+	news := resource.PropertyMap{"size": resource.NewStringProperty("small")}
+
+	// Fixtures for a resource's URN take precedence over those for its type.
+	id, outs, _, err := prov.Create("urn:pulumi:stack::proj::pkgA:m:typA::resA", news, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.ID("a-123"), id)
+	assert.Equal(t, resource.NewStringProperty("arn:a"), outs["arn"])
+	assert.Equal(t, resource.NewStringProperty("small"), outs["size"])
+	id, _, _, err = prov.Create("urn:pulumi:stack::proj::pkgA:m:typA::special", news, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.ID("special-1"), id)
+
+	// Resources without fixtures get stable IDs derived from their names.
+	id, _, _, err = prov.Create("urn:pulumi:stack::proj::pkgA:m:typB::resB", news, 0)
+	assert.NoError(t, err)
+	again, _, _, err := prov.Create("urn:pulumi:stack::proj::pkgA:m:typB::resB", news, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, id, again)
+	assert.Contains(t, string(id), "resB-")
+
+	outs, _, err = prov.Invoke("pkgA:index:getRegion", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.NewStringProperty("us-west-2"), outs["name"])
+
+	_, err = LoadMockFixtures(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
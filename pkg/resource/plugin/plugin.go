@@ -16,6 +16,7 @@ package plugin
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
@@ -35,13 +36,17 @@ import (
 	"github.com/pulumi/pulumi/pkg/diag"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+	"github.com/pulumi/pulumi/pkg/workspace"
 )
 
 type plugin struct {
-	stdoutDone <-chan bool
-	stderrDone <-chan bool
+	stdoutDone   <-chan bool
+	stderrDone   <-chan bool
+	logsToStderr bool   // true if the plugin was asked to log to stderr, so that its logs join this process's.
+	cleanup      func() // removes the verified copy of the plugin's executable, if any, once the plugin has exited.
 
 	Bin    string
 	Args   []string
@@ -72,12 +77,26 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 		logging.V(9).Infof("Launching plugin '%v' from '%v' with args: %v", prefix, bin, argstr)
 	}
 
+	// If the installation requires plugins to be signed, refuse to execute any plugin that isn't. Otherwise, execute
+	// the copy of the plugin that was verified.
+	policy, err := workspace.GetPluginTrustPolicy()
+	if err != nil {
+		return nil, errors.Wrap(err, "loading plugin trust policy")
+	}
+	exe, cleanup, err := policy.VerifyExecutable(bin)
+	if err != nil {
+		return nil, errutil.WithCode(errutil.ErrPluginUntrusted,
+			"run `pulumi plugin trust add` to trust the key that the plugin was signed with", err)
+	}
+
 	// Try to execute the binary.
-	plug, err := execPlugin(bin, args, ctx.Pwd)
+	plug, err := execPlugin(exe, args, ctx.Pwd)
 	if err != nil {
+		cleanup()
 		return nil, errors.Wrapf(err, "failed to load plugin %s", bin)
 	}
 	contract.Assert(plug != nil)
+	plug.Bin, plug.cleanup = bin, cleanup
 
 	// If we did not successfully launch the plugin, we still need to wait for stderr and stdout to drain.
 	defer func() {
@@ -100,7 +119,9 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 			}
 
 			if strings.TrimSpace(msg) != "" {
-				if stderr {
+				if stderr && plug.logsToStderr && logging.IsLogLine(msg) {
+					logging.Passthrough(msg)
+				} else if stderr {
 					ctx.Diag.Infoerrf(diag.StreamMessage("" /*urn*/, msg, errStreamID))
 				} else {
 					ctx.Diag.Infof(diag.StreamMessage("" /*urn*/, msg, outStreamID))
@@ -150,8 +171,35 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 	go runtrace(plug.Stdout, false, stdoutDone)
 
 	// Now that we have the port, go ahead and create a gRPC client connection to it.
+	conn, err := dialPlugin(port, bin, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Done; store the connection and return the plugin info.
+	plug.Conn = conn
+	return plug, nil
+}
+
+// attachPlugin connects to a plugin that is already listening on the given port, having been launched outside of the
+// engine (e.g. under a debugger) rather than by it.  Closing the plugin closes the connection to it, but leaves the
+// plugin running.
+func attachPlugin(port int, prefix string) (*plugin, error) {
+	logging.V(9).Infof("Attaching to plugin '%v' on port %v", prefix, port)
+
+	bin := fmt.Sprintf("127.0.0.1:%d", port)
+	conn, err := dialPlugin(strconv.Itoa(port), bin, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin{Bin: bin, Conn: conn}, nil
+}
+
+// dialPlugin creates a gRPC client connection to the plugin listening on the given port, and waits for the plugin to
+// begin responding to RPCs.
+func dialPlugin(port, bin, prefix string) (*grpc.ClientConn, error) {
 	conn, err := grpc.Dial("127.0.0.1:"+port, grpc.WithInsecure(), grpc.WithUnaryInterceptor(
-		rpcutil.OpenTracingClientInterceptor(),
+		rpcutil.ClientInterceptor(),
 	))
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not dial plugin [%v] over RPC", bin)
@@ -188,6 +236,7 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 					}
 
 					// Unexpected error; get outta dodge.
+					contract.IgnoreClose(conn)
 					return nil, errors.Wrapf(err, "%v plugin [%v] did not come alive", prefix, bin)
 				}
 			}
@@ -195,21 +244,23 @@ func newPlugin(ctx *Context, bin string, prefix string, args []string) (*plugin,
 		}
 		// Not ready yet; ask the gRPC client APIs to block until the state transitions again so we can retry.
 		if !conn.WaitForStateChange(timeout, s) {
+			contract.IgnoreClose(conn)
 			return nil, errors.Errorf("%v plugin [%v] did not begin responding to RPC connections", prefix, bin)
 		}
 	}
 
-	// Done; store the connection and return the plugin info.
-	plug.Conn = conn
-	return plug, nil
+	return conn, nil
 }
 
 func execPlugin(bin string, pluginArgs []string, pwd string) (*plugin, error) {
 	var args []string
-	// Flow the logging information if set.
+	// Flow the logging information if set. If this process's logs go to stderr or to a file rather than to glog's
+	// files, the plugin logs to stderr, from which its logs are copied to the same destination.
+	logsToStderr := false
 	if logging.LogFlow {
-		if logging.LogToStderr {
+		if logging.LogToStderr || logging.LogFile != "" {
 			args = append(args, "-logtostderr")
+			logsToStderr = true
 		}
 		if logging.Verbose > 0 {
 			args = append(args, "-v="+strconv.Itoa(logging.Verbose))
@@ -232,12 +283,13 @@ func execPlugin(bin string, pluginArgs []string, pwd string) (*plugin, error) {
 	}
 
 	return &plugin{
-		Bin:    bin,
-		Args:   args,
-		Proc:   cmd.Process,
-		Stdin:  in,
-		Stdout: out,
-		Stderr: err,
+		logsToStderr: logsToStderr,
+		Bin:          bin,
+		Args:         args,
+		Proc:         cmd.Process,
+		Stdin:        in,
+		Stdout:       out,
+		Stderr:       err,
 	}, nil
 }
 
@@ -247,6 +299,11 @@ func (p *plugin) Close() error {
 		contract.IgnoreError(closerr)
 	}
 
+	// Plugins that we attached to rather than launched are left running.
+	if p.Proc == nil {
+		return nil
+	}
+
 	var result error
 
 	// On each platform, plugins are not loaded directly, instead a shell launches each plugin as a child process, so
@@ -269,5 +326,9 @@ func (p *plugin) Close() error {
 		<-p.stderrDone
 	}
 
+	if p.cleanup != nil {
+		p.cleanup()
+	}
+
 	return result
 }
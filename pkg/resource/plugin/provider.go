@@ -16,6 +16,9 @@ package plugin
 
 import (
 	"io"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/tokens"
@@ -52,22 +55,34 @@ type Provider interface {
 	// Diff checks what impacts a hypothetical update will have on the resource's properties.
 	Diff(urn resource.URN, id resource.ID, olds resource.PropertyMap, news resource.PropertyMap,
 		allowUnknowns bool) (DiffResult, error)
-	// Create allocates a new instance of the provided resource and returns its unique resource.ID.
-	Create(urn resource.URN, news resource.PropertyMap) (resource.ID, resource.PropertyMap, resource.Status, error)
+	// Create allocates a new instance of the provided resource and returns its unique resource.ID. The timeout is the
+	// number of seconds that the operation may take, or zero to use the default timeout.
+	Create(urn resource.URN, news resource.PropertyMap,
+		timeout float64) (resource.ID, resource.PropertyMap, resource.Status, error)
 	// Read the current live state associated with a resource.  Enough state must be include in the inputs to uniquely
 	// identify the resource; this is typically just the resource ID, but may also include some properties.  If the
 	// resource is missing (for instance, because it has been deleted), the resulting property map will be nil.
 	Read(urn resource.URN, id resource.ID,
 		inputs, state resource.PropertyMap) (ReadResult, resource.Status, error)
-	// Update updates an existing resource with new values.
-	Update(urn resource.URN, id resource.ID,
-		olds resource.PropertyMap, news resource.PropertyMap) (resource.PropertyMap, resource.Status, error)
-	// Delete tears down an existing resource.
-	Delete(urn resource.URN, id resource.ID, props resource.PropertyMap) (resource.Status, error)
+	// Update updates an existing resource with new values. The timeout is the number of seconds that the operation may
+	// take, or zero to use the default timeout.
+	Update(urn resource.URN, id resource.ID, olds resource.PropertyMap, news resource.PropertyMap,
+		timeout float64) (resource.PropertyMap, resource.Status, error)
+	// Delete tears down an existing resource. The timeout is the number of seconds that the operation may take, or zero
+	// to use the default timeout.
+	Delete(urn resource.URN, id resource.ID, props resource.PropertyMap, timeout float64) (resource.Status, error)
 	// Invoke dynamically executes a built-in function in the provider.
 	Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (resource.PropertyMap, []CheckFailure, error)
 	// GetPluginInfo returns this plugin's information.
 	GetPluginInfo() (workspace.PluginInfo, error)
+	// GetSchema returns the JSON-encoded schema that describes this provider's configuration, resources, and
+	// functions, in the given version of the schema format.
+	GetSchema(version int) ([]byte, error)
+	// GetLogs returns the runtime logs of a resource, such as those written by a serverless function, that were written
+	// between startTime and endTime, either of which may be nil to leave that end of the range open. Providers that
+	// keep no logs for the resource return no entries, and providers that keep no logs at all return ErrNoLogs.
+	GetLogs(urn resource.URN, id resource.ID, props resource.PropertyMap,
+		startTime, endTime *time.Time) ([]LogEntry, error)
 
 	// SignalCancellation asks all resource providers to gracefully shut down and abort any ongoing
 	// operations. Operation aborted in this way will return an error (e.g., `Update` and `Create`
@@ -77,6 +92,55 @@ type Provider interface {
 	SignalCancellation() error
 }
 
+// ErrNoLogs is returned by a provider's GetLogs method if the provider does not implement the GetLogs RPC, and so keeps
+// no logs for any of its resources.
+var ErrNoLogs = errors.New("the provider does not serve logs")
+
+// LogEntry is an entry in the runtime logs of a resource.
+type LogEntry struct {
+	ID        string // the name of the log stream, e.g. a function's name, that the entry was written to.
+	Timestamp int64  // the time at which the entry was written, in milliseconds since the Unix epoch.
+	Message   string // the text of the entry.
+}
+
+// ProviderInterfaceVersion is the newest version of the resource provider RPC interface that the engine supports. The
+// engine uses the older of this and the version that a provider reports from GetPluginInfo, so that providers written
+// against older interfaces keep working. It is only incremented for incompatible changes to the interface; features
+// that providers may adopt one at a time are negotiated as capabilities instead.
+const ProviderInterfaceVersion = 1
+
+// Capabilities are the optional features of the resource provider RPC interface.
+const (
+	// ConfigureArgsCapability means that the provider accepts its configuration as structured properties, in the
+	// args field of ConfigureRequest, as well as the variables field.
+	ConfigureArgsCapability = "configureArgs"
+)
+
+// providerCapabilities lists the capabilities that the engine supports.
+var providerCapabilities = []string{ConfigureArgsCapability}
+
+// negotiateProviderInterface returns the interface version and capabilities that the engine should use with a provider
+// that reports the given interface version and capabilities. Providers that report no version support version 1.
+func negotiateProviderInterface(version int32, capabilities []string) (int32, []string) {
+	if version < 1 {
+		version = 1
+	} else if version > ProviderInterfaceVersion {
+		version = ProviderInterfaceVersion
+	}
+
+	supported := make(map[string]bool)
+	for _, c := range capabilities {
+		supported[c] = true
+	}
+	var negotiated []string
+	for _, c := range providerCapabilities {
+		if supported[c] {
+			negotiated = append(negotiated, c)
+		}
+	}
+	return version, negotiated
+}
+
 // CheckFailure indicates that a call to check failed; it contains the property and reason for the failure.
 type CheckFailure struct {
 	Property resource.PropertyKey // the property that failed checking.
@@ -97,11 +161,12 @@ const (
 
 // DiffResult indicates whether an operation should replace or update an existing resource.
 type DiffResult struct {
-	Changes             DiffChanges            // true if this diff represents a changed resource.
-	ReplaceKeys         []resource.PropertyKey // an optional list of replacement keys.
-	StableKeys          []resource.PropertyKey // an optional list of property keys that are stable.
-	ChangedKeys         []resource.PropertyKey // an optional list of keys that changed.
-	DeleteBeforeReplace bool                   // if true, this resource must be deleted before recreating it.
+	Changes             DiffChanges                     // true if this diff represents a changed resource.
+	ReplaceKeys         []resource.PropertyKey          // an optional list of replacement keys.
+	ReplaceReasons      map[resource.PropertyKey]string // optional explanations of why keys force replacement.
+	StableKeys          []resource.PropertyKey          // an optional list of property keys that are stable.
+	ChangedKeys         []resource.PropertyKey          // an optional list of keys that changed.
+	DeleteBeforeReplace bool                            // if true, this resource must be deleted before recreating it.
 }
 
 // Replace returns true if this diff represents a replacement.
@@ -15,13 +15,19 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/blang/semver"
 	pbempty "github.com/golang/protobuf/ptypes/empty"
 	_struct "github.com/golang/protobuf/ptypes/struct"
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 
@@ -34,20 +40,127 @@ import (
 	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
 )
 
+// resourceTimeoutGracePeriod is how much longer than a resource's custom timeout the engine waits for its provider to
+// respond. Providers are sent the timeout themselves, so this gives them the chance to report their own timeout, along
+// with any partial state, before the engine abandons the request.
+var resourceTimeoutGracePeriod = time.Minute
+
+// DefaultResourceTimeout is the longest that the engine waits for a provider to create, update or delete a resource
+// when no custom timeout has been specified for the operation.
+const DefaultResourceTimeout = 2 * time.Hour
+
+// DefaultResourceTimeoutEnvVar may be set to a duration, e.g. "6h", to wait that long instead of DefaultResourceTimeout
+// for operations without a custom timeout, or to "0" to wait for them as long as their providers need.
+const DefaultResourceTimeoutEnvVar = "PULUMI_DEFAULT_RESOURCE_TIMEOUT"
+
+// getDefaultResourceTimeout returns how long to wait for an operation without a custom timeout, as set by
+// PULUMI_DEFAULT_RESOURCE_TIMEOUT. Zero means there is no limit.
+func getDefaultResourceTimeout() time.Duration {
+	value := strings.TrimSpace(os.Getenv(DefaultResourceTimeoutEnvVar))
+	if value == "" {
+		return DefaultResourceTimeout
+	}
+	if value == "0" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		logging.Warningf("ignoring %s=%q, which is not a non-negative duration such as \"6h\"",
+			DefaultResourceTimeoutEnvVar, value)
+		return DefaultResourceTimeout
+	}
+	return timeout
+}
+
 // provider reflects a resource plugin, loaded dynamically for a single package.
 type provider struct {
 	ctx       *Context                         // a plugin context for caching, etc.
+	reqctx    context.Context                  // the context to make requests within, if not the plugin context's.
 	pkg       tokens.Package                   // the Pulumi package containing this provider's resources.
 	plug      *plugin                          // the actual plugin process wrapper.
 	clientRaw pulumirpc.ResourceProviderClient // the raw provider client; usually unsafe to use directly.
-	cfgerr    error                            // non-nil if a configure call fails.
-	cfgknown  bool                             // true if all configuration values are known.
-	cfgdone   chan bool                        // closed when configuration has completed.
+
+	*providerState
+}
+
+// providerState is the state of a provider that is shared with the views of it returned by WithContext.
+type providerState struct {
+	cfgerr   error     // non-nil if a configure call fails.
+	cfgknown bool      // true if all configuration values are known.
+	cfgdone  chan bool // closed when configuration has completed.
+
+	// The interface version and capabilities negotiated with the provider by GetPluginInfo, if it has been called.
+	// GetPluginInfo may be called concurrently with other requests, such as health checks, so these are guarded by
+	// negotiationLock.
+	negotiationLock sync.Mutex
+	negotiated      bool
+	version         int32
+	capabilities    []string
+}
+
+func newProvider(ctx *Context, pkg tokens.Package, plug *plugin, client pulumirpc.ResourceProviderClient) *provider {
+	return &provider{
+		ctx:           ctx,
+		pkg:           pkg,
+		plug:          plug,
+		clientRaw:     client,
+		providerState: &providerState{cfgdone: make(chan bool)},
+	}
+}
+
+// WithContext returns a view of the given provider that makes its requests within the given context, so that they are
+// abandoned if the context is canceled. Providers that are not backed by a plugin are returned unchanged. Configure
+// requests, which complete in the background, are always made within the plugin context.
+func WithContext(ctx context.Context, prov Provider) Provider {
+	p, ok := prov.(*provider)
+	if !ok {
+		return prov
+	}
+	view := *p
+	view.reqctx = ctx
+	return &view
+}
+
+// DebugProvidersEnvVar may be set to a comma-separated list of <package>:<port> pairs, e.g. "aws:50051", in which case
+// the engine attaches to the providers for the given packages that are already listening on the given ports, rather
+// than launching them.  This allows provider authors to run their providers under a debugger during a real preview or
+// update.  A provider that is attached to is used regardless of the version of the provider that is required.
+const DebugProvidersEnvVar = "PULUMI_DEBUG_PROVIDERS"
+
+// GetDebugProviders returns the ports on which the providers that the engine should attach to, rather than launch, are
+// listening, keyed by package, as set by PULUMI_DEBUG_PROVIDERS.
+func GetDebugProviders() (map[tokens.Package]int, error) {
+	ports := make(map[tokens.Package]int)
+	for _, pair := range strings.Split(os.Getenv(DebugProvidersEnvVar), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		colon := strings.LastIndex(pair, ":")
+		if colon == -1 {
+			return nil, errors.Errorf("%s holds %q, which is not of the form <package>:<port>",
+				DebugProvidersEnvVar, pair)
+		}
+		port, err := strconv.Atoi(pair[colon+1:])
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, errors.Errorf("%s holds %q, whose port is invalid", DebugProvidersEnvVar, pair)
+		}
+		ports[tokens.Package(pair[:colon])] = port
+	}
+	return ports, nil
 }
 
 // NewProvider attempts to bind to a given package's resource plugin and then creates a gRPC connection to it.  If the
 // plugin could not be found, or an error occurs while creating the child process, an error is returned.
 func NewProvider(host Host, ctx *Context, pkg tokens.Package, version *semver.Version) (Provider, error) {
+	// If the provider is being debugged, attach to it rather than launching it.
+	debugPorts, err := GetDebugProviders()
+	if err != nil {
+		return nil, err
+	}
+	if port, has := debugPorts[pkg]; has {
+		return attachProvider(host, ctx, pkg, port)
+	}
+
 	// Load the plugin's path by using the standard workspace logic.
 	_, path, err := workspace.GetPluginPath(
 		workspace.ResourcePlugin, strings.Replace(string(pkg), tokens.QNameDelimiter, "_", -1), version)
@@ -67,13 +180,28 @@ func NewProvider(host Host, ctx *Context, pkg tokens.Package, version *semver.Ve
 	}
 	contract.Assertf(plug != nil, "unexpected nil resource plugin for %s", pkg)
 
-	return &provider{
-		ctx:       ctx,
-		pkg:       pkg,
-		plug:      plug,
-		clientRaw: pulumirpc.NewResourceProviderClient(plug.Conn),
-		cfgdone:   make(chan bool),
-	}, nil
+	return newProvider(ctx, pkg, plug, pulumirpc.NewResourceProviderClient(plug.Conn)), nil
+}
+
+// attachProvider connects to the given package's resource plugin, which is already listening on the given port, and
+// tells it how to connect back to the engine.
+func attachProvider(host Host, ctx *Context, pkg tokens.Package, port int) (Provider, error) {
+	plug, err := attachPlugin(port, fmt.Sprintf("%v (resource)", pkg))
+	if err != nil {
+		return nil, errors.Wrapf(err, "attaching to the %s provider on port %d", pkg, port)
+	}
+
+	// Providers that do not support being attached to must have been given the engine's address some other way.
+	client := pulumirpc.NewResourceProviderClient(plug.Conn)
+	if _, err = client.Attach(ctx.Request(), &pulumirpc.PluginAttach{Address: host.ServerAddr()}); err != nil {
+		if rpcerr := rpcerror.Convert(err); rpcerr.Code() != codes.Unimplemented {
+			contract.IgnoreClose(plug)
+			return nil, errors.Wrapf(rpcerr, "attaching to the %s provider on port %d", pkg, port)
+		}
+	}
+	logging.V(7).Infof("attached to the %s provider on port %d", pkg, port)
+
+	return newProvider(ctx, pkg, plug, client), nil
 }
 
 func (p *provider) Pkg() tokens.Package { return p.pkg }
@@ -142,7 +270,7 @@ func (p *provider) Configure(inputs resource.PropertyMap) error {
 
 	// Convert the inputs to a config map. If any are unknown, do not configure the underlying plugin: instead, leavce
 	// the cfgknown bit unset and carry on.
-	config := make(map[string]string)
+	config, args := make(map[string]string), make(resource.PropertyMap)
 	for k, v := range inputs {
 		if k == "version" {
 			continue
@@ -156,6 +284,7 @@ func (p *provider) Configure(inputs resource.PropertyMap) error {
 			// Pass the older spelling of a configuration key across the RPC interface, for now, to support
 			// providers which are on the older plan.
 			config[string(p.Pkg())+":config:"+string(k)] = v.StringValue()
+			args[k] = v
 		default:
 			p.cfgerr = errors.Errorf("provider property values must be strings; '%v' is a %v", k, v.TypeString())
 			close(p.cfgdone)
@@ -166,7 +295,10 @@ func (p *provider) Configure(inputs resource.PropertyMap) error {
 	// Spawn the configure to happen in parallel.  This ensures that we remain responsive elsewhere that might
 	// want to make forward progress, even as the configure call is happening.
 	go func() {
-		_, err := p.clientRaw.Configure(p.ctx.Request(), &pulumirpc.ConfigureRequest{Variables: config})
+		req, err := p.configureRequest(label, config, args)
+		if err == nil {
+			_, err = p.clientRaw.Configure(p.ctx.Request(), req)
+		}
 		if err != nil {
 			rpcError := rpcerror.Convert(err)
 			logging.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
@@ -180,6 +312,44 @@ func (p *provider) Configure(inputs resource.PropertyMap) error {
 	return nil
 }
 
+// negotiatedInterface returns the interface version and capabilities negotiated with the provider, and whether they
+// have been negotiated yet.
+func (p *provider) negotiatedInterface() (int32, []string, bool) {
+	p.negotiationLock.Lock()
+	defer p.negotiationLock.Unlock()
+	return p.version, p.capabilities, p.negotiated
+}
+
+// configureRequest creates the request with which to configure the provider, negotiating the interface version and
+// capabilities with the provider first if GetPluginInfo has not done so already.
+func (p *provider) configureRequest(label string, config map[string]string,
+	args resource.PropertyMap) (*pulumirpc.ConfigureRequest, error) {
+
+	version, capabilities, negotiated := p.negotiatedInterface()
+	if !negotiated {
+		if _, err := p.GetPluginInfo(); err != nil {
+			return nil, err
+		}
+		version, capabilities, _ = p.negotiatedInterface()
+	}
+
+	req := &pulumirpc.ConfigureRequest{
+		Variables:        config,
+		InterfaceVersion: version,
+		Capabilities:     capabilities,
+	}
+	for _, c := range capabilities {
+		if c == ConfigureArgsCapability {
+			margs, err := MarshalProperties(args, MarshalOptions{Label: fmt.Sprintf("%s.args", label)})
+			if err != nil {
+				return nil, err
+			}
+			req.Args = margs
+		}
+	}
+	return req, nil
+}
+
 // Check validates that the given property bag is valid for a resource of the given type.
 func (p *provider) Check(urn resource.URN,
 	olds, news resource.PropertyMap, allowUnknowns bool) (resource.PropertyMap, []CheckFailure, error) {
@@ -209,7 +379,7 @@ func (p *provider) Check(urn resource.URN,
 		return nil, nil, err
 	}
 
-	resp, err := client.Check(p.ctx.Request(), &pulumirpc.CheckRequest{
+	resp, err := client.Check(p.request(), &pulumirpc.CheckRequest{
 		Urn:  string(urn),
 		Olds: molds,
 		News: mnews,
@@ -278,7 +448,7 @@ func (p *provider) Diff(urn resource.URN, id resource.ID,
 		return DiffResult{}, err
 	}
 
-	resp, err := client.Diff(p.ctx.Request(), &pulumirpc.DiffRequest{
+	resp, err := client.Diff(p.request(), &pulumirpc.DiffRequest{
 		Id:   string(id),
 		Urn:  string(urn),
 		Olds: molds,
@@ -294,6 +464,13 @@ func (p *provider) Diff(urn resource.URN, id resource.ID,
 	for _, replace := range resp.GetReplaces() {
 		replaces = append(replaces, resource.PropertyKey(replace))
 	}
+	var reasons map[resource.PropertyKey]string
+	for k, reason := range resp.GetReplaceReasons() {
+		if reasons == nil {
+			reasons = make(map[resource.PropertyKey]string)
+		}
+		reasons[resource.PropertyKey(k)] = reason
+	}
 	var stables []resource.PropertyKey
 	for _, stable := range resp.GetStables() {
 		stables = append(stables, resource.PropertyKey(stable))
@@ -311,6 +488,7 @@ func (p *provider) Diff(urn resource.URN, id resource.ID,
 	return DiffResult{
 		Changes:             DiffChanges(changes),
 		ReplaceKeys:         replaces,
+		ReplaceReasons:      reasons,
 		StableKeys:          stables,
 		ChangedKeys:         diffs,
 		DeleteBeforeReplace: deleteBeforeReplace,
@@ -318,7 +496,7 @@ func (p *provider) Diff(urn resource.URN, id resource.ID,
 }
 
 // Create allocates a new instance of the provided resource and assigns its unique resource.ID and outputs afterwards.
-func (p *provider) Create(urn resource.URN, props resource.PropertyMap) (resource.ID,
+func (p *provider) Create(urn resource.URN, props resource.PropertyMap, timeout float64) (resource.ID,
 	resource.PropertyMap, resource.Status, error) {
 	contract.Assert(urn != "")
 	contract.Assert(props != nil)
@@ -344,11 +522,19 @@ func (p *provider) Create(urn resource.URN, props resource.PropertyMap) (resourc
 	var liveObject *_struct.Struct
 	var resourceError error
 	var resourceStatus = resource.StatusOK
-	resp, err := client.Create(p.ctx.Request(), &pulumirpc.CreateRequest{
+	ctx, cancel, deadline := p.requestContext(timeout)
+	defer cancel()
+	resp, err := client.Create(ctx, &pulumirpc.CreateRequest{
 		Urn:        string(urn),
 		Properties: mprops,
+		Timeout:    timeout,
 	})
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			logging.V(7).Infof("%s timed out after %v", label, deadline)
+			return "", nil, resource.StatusUnknown, &TimeoutError{Op: "create", URN: urn, Timeout: deadline}
+		}
+
 		resourceStatus, id, liveObject, _, resourceError = parseError(err)
 		logging.V(7).Infof("%s failed: %v", label, resourceError)
 
@@ -424,7 +610,7 @@ func (p *provider) Read(urn resource.URN, id resource.ID,
 	var liveInputs *_struct.Struct
 	var resourceError error
 	var resourceStatus = resource.StatusOK
-	resp, err := client.Read(p.ctx.Request(), &pulumirpc.ReadRequest{
+	resp, err := client.Read(p.request(), &pulumirpc.ReadRequest{
 		Id:         string(id),
 		Urn:        string(urn),
 		Properties: mstate,
@@ -476,8 +662,8 @@ func (p *provider) Read(urn resource.URN, id resource.ID,
 }
 
 // Update updates an existing resource with new values.
-func (p *provider) Update(urn resource.URN, id resource.ID,
-	olds resource.PropertyMap, news resource.PropertyMap) (resource.PropertyMap, resource.Status, error) {
+func (p *provider) Update(urn resource.URN, id resource.ID, olds resource.PropertyMap, news resource.PropertyMap,
+	timeout float64) (resource.PropertyMap, resource.Status, error) {
 	contract.Assert(urn != "")
 	contract.Assert(id != "")
 	contract.Assert(news != nil)
@@ -508,13 +694,21 @@ func (p *provider) Update(urn resource.URN, id resource.ID,
 	var liveObject *_struct.Struct
 	var resourceError error
 	var resourceStatus = resource.StatusOK
-	resp, err := client.Update(p.ctx.Request(), &pulumirpc.UpdateRequest{
-		Id:   string(id),
-		Urn:  string(urn),
-		Olds: molds,
-		News: mnews,
+	ctx, cancel, deadline := p.requestContext(timeout)
+	defer cancel()
+	resp, err := client.Update(ctx, &pulumirpc.UpdateRequest{
+		Id:      string(id),
+		Urn:     string(urn),
+		Olds:    molds,
+		News:    mnews,
+		Timeout: timeout,
 	})
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			logging.V(7).Infof("%s timed out after %v", label, deadline)
+			return nil, resource.StatusUnknown, &TimeoutError{Op: "update", URN: urn, Timeout: deadline}
+		}
+
 		resourceStatus, _, liveObject, _, resourceError = parseError(err)
 		logging.V(7).Infof("%s failed: %v", label, resourceError)
 
@@ -540,7 +734,8 @@ func (p *provider) Update(urn resource.URN, id resource.ID,
 }
 
 // Delete tears down an existing resource.
-func (p *provider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap) (resource.Status, error) {
+func (p *provider) Delete(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	timeout float64) (resource.Status, error) {
 	contract.Assert(urn != "")
 	contract.Assert(id != "")
 
@@ -561,11 +756,19 @@ func (p *provider) Delete(urn resource.URN, id resource.ID, props resource.Prope
 	// We should only be calling {Create,Update,Delete} if the provider is fully configured.
 	contract.Assert(p.cfgknown)
 
-	if _, err := client.Delete(p.ctx.Request(), &pulumirpc.DeleteRequest{
+	ctx, cancel, deadline := p.requestContext(timeout)
+	defer cancel()
+	if _, err := client.Delete(ctx, &pulumirpc.DeleteRequest{
 		Id:         string(id),
 		Urn:        string(urn),
 		Properties: mprops,
+		Timeout:    timeout,
 	}); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			logging.V(7).Infof("%s timed out after %v", label, deadline)
+			return resource.StatusUnknown, &TimeoutError{Op: "delete", URN: urn, Timeout: deadline}
+		}
+
 		resourceStatus, rpcErr := resourceStateAndError(err)
 		logging.V(7).Infof("%s failed: %v", label, rpcErr)
 		return resourceStatus, rpcErr
@@ -599,7 +802,7 @@ func (p *provider) Invoke(tok tokens.ModuleMember, args resource.PropertyMap) (r
 		return nil, nil, err
 	}
 
-	resp, err := client.Invoke(p.ctx.Request(), &pulumirpc.InvokeRequest{Tok: string(tok), Args: margs})
+	resp, err := client.Invoke(p.request(), &pulumirpc.InvokeRequest{Tok: string(tok), Args: margs})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
 		logging.V(7).Infof("%s failed: %v", label, rpcError.Message())
@@ -630,7 +833,7 @@ func (p *provider) GetPluginInfo() (workspace.PluginInfo, error) {
 
 	// Calling GetPluginInfo happens immediately after loading, and does not require configuration to proceed.
 	// Thus, we access the clientRaw property, rather than calling getClient.
-	resp, err := p.clientRaw.GetPluginInfo(p.ctx.Request(), &pbempty.Empty{})
+	resp, err := p.clientRaw.GetPluginInfo(p.request(), &pbempty.Empty{})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
 		logging.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
@@ -646,6 +849,12 @@ func (p *provider) GetPluginInfo() (workspace.PluginInfo, error) {
 		version = &sv
 	}
 
+	interfaceVersion, capabilities := negotiateProviderInterface(resp.GetInterfaceVersion(), resp.GetCapabilities())
+	p.negotiationLock.Lock()
+	p.version, p.capabilities, p.negotiated = interfaceVersion, capabilities, true
+	p.negotiationLock.Unlock()
+	logging.V(7).Infof("%s negotiated interface version %d (capabilities=%v)", label, interfaceVersion, capabilities)
+
 	return workspace.PluginInfo{
 		Name:    string(p.pkg),
 		Path:    p.plug.Bin,
@@ -654,8 +863,83 @@ func (p *provider) GetPluginInfo() (workspace.PluginInfo, error) {
 	}, nil
 }
 
+// GetSchema returns the JSON-encoded schema that describes this provider's configuration, resources, and functions.
+func (p *provider) GetSchema(version int) ([]byte, error) {
+	label := fmt.Sprintf("%s.GetSchema(%d)", p.label(), version)
+	logging.V(7).Infof("%s executing", label)
+
+	// Like GetPluginInfo, GetSchema does not require configuration, so we access the clientRaw property.
+	resp, err := p.clientRaw.GetSchema(p.request(), &pulumirpc.GetSchemaRequest{Version: int32(version)})
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+		if rpcError.Code() == codes.Unimplemented {
+			return nil, errors.Errorf("the %s provider does not publish a schema", p.pkg)
+		}
+		return nil, rpcError
+	}
+
+	logging.V(7).Infof("%s success (#bytes=%d)", label, len(resp.GetSchema()))
+	return []byte(resp.GetSchema()), nil
+}
+
+// GetLogs returns the runtime logs of a resource that were written between startTime and endTime. Providers that
+// predate the GetLogs RPC keep no logs, so they return ErrNoLogs.
+func (p *provider) GetLogs(urn resource.URN, id resource.ID, props resource.PropertyMap,
+	startTime, endTime *time.Time) ([]LogEntry, error) {
+
+	contract.Assert(urn != "")
+
+	label := fmt.Sprintf("%s.GetLogs(%s,%s)", p.label(), id, urn)
+	logging.V(7).Infof("%s executing (#props=%d)", label, len(props))
+
+	// Get the RPC client and ensure it's configured.
+	client, err := p.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	mprops, err := MarshalProperties(props, MarshalOptions{Label: label, ElideAssetContents: true})
+	if err != nil {
+		return nil, err
+	}
+	req := &pulumirpc.GetLogsRequest{
+		Urn:        string(urn),
+		Id:         string(id),
+		Properties: mprops,
+	}
+	if startTime != nil {
+		req.StartTime = startTime.UnixNano() / int64(time.Millisecond)
+	}
+	if endTime != nil {
+		req.EndTime = endTime.UnixNano() / int64(time.Millisecond)
+	}
+
+	resp, err := client.GetLogs(p.request(), req)
+	if err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.V(7).Infof("%s failed: err=%v", label, rpcError.Message())
+		if rpcError.Code() == codes.Unimplemented {
+			return nil, ErrNoLogs
+		}
+		return nil, rpcError
+	}
+
+	var entries []LogEntry
+	for _, entry := range resp.GetEntries() {
+		entries = append(entries, LogEntry{
+			ID:        entry.GetId(),
+			Timestamp: entry.GetTimestamp(),
+			Message:   entry.GetMessage(),
+		})
+	}
+
+	logging.V(7).Infof("%s success (#entries=%d)", label, len(entries))
+	return entries, nil
+}
+
 func (p *provider) SignalCancellation() error {
-	_, err := p.clientRaw.Cancel(p.ctx.Request(), &pbempty.Empty{})
+	_, err := p.clientRaw.Cancel(p.request(), &pbempty.Empty{})
 	if err != nil {
 		rpcError := rpcerror.Convert(err)
 		logging.V(8).Infof("provider received rpc error `%s`: `%s`", rpcError.Code(),
@@ -701,6 +985,48 @@ func createConfigureError(rpcerr *rpcerror.Error) error {
 	return rpcerr
 }
 
+// request returns the context within which to make a request.
+func (p *provider) request() context.Context {
+	if p.reqctx == nil {
+		return p.ctx.Request()
+	}
+	return opentracing.ContextWithSpan(p.reqctx, p.ctx.tracingSpan)
+}
+
+// requestContext returns a context for a create, update or delete request, along with the request's timeout. If the
+// given timeout, in seconds, is zero, the context is canceled once the default timeout has elapsed (see
+// DefaultResourceTimeoutEnvVar), or never if that is zero. Otherwise, the context is canceled once the timeout and
+// resourceTimeoutGracePeriod have elapsed.
+func (p *provider) requestContext(timeout float64) (context.Context, context.CancelFunc, time.Duration) {
+	if timeout <= 0 {
+		deadline := getDefaultResourceTimeout()
+		if deadline == 0 {
+			ctx, cancel := context.WithCancel(p.request())
+			return ctx, cancel, 0
+		}
+		ctx, cancel := context.WithTimeout(p.request(), deadline)
+		return ctx, cancel, deadline
+	}
+	deadline := time.Duration(timeout * float64(time.Second))
+	ctx, cancel := context.WithTimeout(p.request(), deadline+resourceTimeoutGracePeriod)
+	return ctx, cancel, deadline
+}
+
+// TimeoutError is returned when a provider does not complete an operation on a resource within the resource's custom
+// timeout. The engine stops waiting for the provider, so whether the operation took effect is unknown.
+type TimeoutError struct {
+	Op      string        // the operation that timed out: "create", "update" or "delete".
+	URN     resource.URN  // the URN of the resource.
+	Timeout time.Duration // the resource's custom timeout for the operation.
+}
+
+var _ error = (*TimeoutError)(nil)
+
+func (err *TimeoutError) Error() string {
+	return fmt.Sprintf("%s of resource '%s' timed out after %v; if the operation is expected to take longer, "+
+		"specify a longer custom timeout for the resource", err.Op, err.URN, err.Timeout)
+}
+
 // resourceStateAndError interprets an error obtained from a gRPC endpoint.
 //
 // gRPC gives us a `status.Status` structure as an `error` whenever our
@@ -0,0 +1,210 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pulumi/pulumi/pkg/util/rpcutil"
+	pulumirpc "github.com/pulumi/pulumi/sdk/proto/go"
+)
+
+func TestNegotiateProviderInterface(t *testing.T) {
+	// Providers that predate negotiation report neither a version nor capabilities.
+	version, capabilities := negotiateProviderInterface(0, nil)
+	assert.Equal(t, int32(1), version)
+	assert.Empty(t, capabilities)
+
+	// Providers that are newer than the engine are used with the engine's interface version.
+	version, _ = negotiateProviderInterface(ProviderInterfaceVersion+1, nil)
+	assert.Equal(t, int32(ProviderInterfaceVersion), version)
+
+	// Only the capabilities that both support are used.
+	_, capabilities = negotiateProviderInterface(1, []string{"someFutureCapability", ConfigureArgsCapability})
+	assert.Equal(t, []string{ConfigureArgsCapability}, capabilities)
+}
+
+// attachTestProvider is a provider that records the address of the engine that attaches to it.
+type attachTestProvider struct {
+	pulumirpc.ResourceProviderServer
+	attached chan string
+}
+
+func (p *attachTestProvider) Attach(ctx context.Context, req *pulumirpc.PluginAttach) (*pbempty.Empty, error) {
+	p.attached <- req.GetAddress()
+	return &pbempty.Empty{}, nil
+}
+
+// attachTestHost is a host whose only purpose is to report the address of the engine.
+type attachTestHost struct {
+	Host
+}
+
+func (attachTestHost) ServerAddr() string { return "127.0.0.1:12345" }
+
+func TestAttachProvider(t *testing.T) {
+	prov := &attachTestProvider{attached: make(chan string, 1)}
+	port, done, err := rpcutil.Serve(0, nil, []func(*grpc.Server) error{
+		func(srv *grpc.Server) error {
+			pulumirpc.RegisterResourceProviderServer(srv, prov)
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+
+	// Providers that are being debugged are attached to rather than launched, and told the engine's address.
+	os.Setenv(DebugProvidersEnvVar, fmt.Sprintf("other:1, test:%d", port))
+	defer os.Unsetenv(DebugProvidersEnvVar)
+	p, err := NewProvider(attachTestHost{}, &Context{}, "test", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:12345", <-prov.attached)
+
+	// Closing the provider leaves it running.
+	assert.NoError(t, p.Close())
+	select {
+	case err = <-done:
+		assert.Fail(t, "the provider stopped", "%v", err)
+	default:
+	}
+
+	os.Setenv(DebugProvidersEnvVar, "test")
+	_, err = GetDebugProviders()
+	assert.Error(t, err)
+	os.Setenv(DebugProvidersEnvVar, "test:port")
+	_, err = GetDebugProviders()
+	assert.Error(t, err)
+}
+
+func TestRequestContext(t *testing.T) {
+	p := newProvider(&Context{}, "pkgA", &plugin{}, nil)
+
+	// Without a custom timeout, a request is given the default timeout...
+	before := time.Now()
+	ctx, cancel, timeout := p.requestContext(0)
+	deadline, hasDeadline := ctx.Deadline()
+	assert.True(t, hasDeadline)
+	assert.Equal(t, DefaultResourceTimeout, timeout)
+	assert.False(t, deadline.Before(before.Add(DefaultResourceTimeout)))
+	cancel()
+
+	// ...which may be changed...
+	os.Setenv(DefaultResourceTimeoutEnvVar, "10m")
+	defer os.Unsetenv(DefaultResourceTimeoutEnvVar)
+	ctx, cancel, timeout = p.requestContext(0)
+	_, hasDeadline = ctx.Deadline()
+	assert.True(t, hasDeadline)
+	assert.Equal(t, 10*time.Minute, timeout)
+	cancel()
+
+	// ...or lifted, in which case a request may take as long as the provider needs.
+	os.Setenv(DefaultResourceTimeoutEnvVar, "0")
+	ctx, cancel, timeout = p.requestContext(0)
+	_, hasDeadline = ctx.Deadline()
+	assert.False(t, hasDeadline)
+	assert.Equal(t, time.Duration(0), timeout)
+	cancel()
+
+	// An invalid default is ignored.
+	os.Setenv(DefaultResourceTimeoutEnvVar, "forever")
+	_, cancel, timeout = p.requestContext(0)
+	assert.Equal(t, DefaultResourceTimeout, timeout)
+	cancel()
+
+	// Otherwise, the provider is given a grace period in which to report its own timeout.
+	before = time.Now()
+	ctx, cancel, timeout = p.requestContext(90)
+	defer cancel()
+	deadline, hasDeadline = ctx.Deadline()
+	assert.True(t, hasDeadline)
+	assert.Equal(t, 90*time.Second, timeout)
+	assert.False(t, deadline.Before(before.Add(timeout+resourceTimeoutGracePeriod)))
+
+	err := &TimeoutError{Op: "create", URN: "urn:pulumi:test::proj::pkg:m:typ::a", Timeout: timeout}
+	assert.Equal(t, "create of resource 'urn:pulumi:test::proj::pkg:m:typ::a' timed out after 1m30s; if the operation "+
+		"is expected to take longer, specify a longer custom timeout for the resource", err.Error())
+}
+
+// pluginInfoTestClient is a provider client that only answers GetPluginInfo.
+type pluginInfoTestClient struct {
+	pulumirpc.ResourceProviderClient
+}
+
+func (pluginInfoTestClient) GetPluginInfo(ctx context.Context, req *pbempty.Empty,
+	opts ...grpc.CallOption) (*pulumirpc.PluginInfo, error) {
+	return &pulumirpc.PluginInfo{
+		Version:          "1.0.0",
+		InterfaceVersion: ProviderInterfaceVersion,
+		Capabilities:     []string{ConfigureArgsCapability},
+	}, nil
+}
+
+func TestConcurrentNegotiation(t *testing.T) {
+	p := newProvider(&Context{}, "pkgA", &plugin{}, pluginInfoTestClient{})
+
+	// Health checks may fetch the plugin's information while it is being configured.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := p.GetPluginInfo()
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			req, err := p.configureRequest("test", nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, int32(ProviderInterfaceVersion), req.GetInterfaceVersion())
+			assert.Equal(t, []string{ConfigureArgsCapability}, req.GetCapabilities())
+		}()
+	}
+	wg.Wait()
+}
+
+// cancelTestClient is a provider client whose GetPluginInfo waits until its request is canceled.
+type cancelTestClient struct {
+	pulumirpc.ResourceProviderClient
+}
+
+func (cancelTestClient) GetPluginInfo(ctx context.Context, req *pbempty.Empty,
+	opts ...grpc.CallOption) (*pulumirpc.PluginInfo, error) {
+	<-ctx.Done()
+	return nil, status.Error(codes.Canceled, ctx.Err().Error())
+}
+
+func TestWithContext(t *testing.T) {
+	p := newProvider(&Context{}, "pkgA", &plugin{}, cancelTestClient{})
+
+	// A view of the provider makes its requests within the given context, so canceling the context abandons them.
+	ctx, cancel := context.WithCancel(context.Background())
+	view := WithContext(ctx, p)
+	cancel()
+	_, err := view.GetPluginInfo()
+	assert.Error(t, err)
+
+	// The view shares the provider's configuration.
+	assert.True(t, view.(*provider).providerState == p.providerState)
+}
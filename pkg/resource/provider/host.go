@@ -33,20 +33,30 @@ type HostClient struct {
 
 // NewHostClient dials the target address, connects over gRPC, and returns a client interface.
 func NewHostClient(addr string) (*HostClient, error) {
+	host := &HostClient{}
+	if err := host.attach(addr); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// attach dials the target address and connects the client to it over gRPC.
+func (host *HostClient) attach(addr string) error {
 	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithUnaryInterceptor(
 		rpcutil.OpenTracingClientInterceptor(),
 	))
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &HostClient{
-		conn:   conn,
-		client: lumirpc.NewEngineClient(conn),
-	}, nil
+	host.conn, host.client = conn, lumirpc.NewEngineClient(conn)
+	return nil
 }
 
 // Close closes and renders the connection and client unusable.
 func (host *HostClient) Close() error {
+	if host.conn == nil {
+		return nil // the client was never connected to the engine.
+	}
 	return host.conn.Close()
 }
 
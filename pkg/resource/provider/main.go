@@ -17,10 +17,14 @@ package provider
 import (
 	"flag"
 	"fmt"
+	"os"
 
+	pbempty "github.com/golang/protobuf/ptypes/empty"
 	"github.com/pkg/errors"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
 	"github.com/pulumi/pulumi/pkg/util/cmdutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/rpcutil"
@@ -41,14 +45,16 @@ func Main(name string, provMaker func(*HostClient) (pulumirpc.ResourceProviderSe
 	logging.InitLogging(false, 0, false)
 	cmdutil.InitTracing(name, name, tracing)
 
-	// Read the non-flags args and connect to the engine.
+	// Read the non-flags args and connect to the engine.  If there are none, the provider was launched by hand (e.g.
+	// under a debugger) rather than by the engine, and the engine will tell it how to connect when it attaches.
 	args := flag.Args()
-	if len(args) == 0 {
-		return errors.New("fatal: could not connect to host RPC; missing argument")
-	}
-	host, err := NewHostClient(args[0])
-	if err != nil {
-		return errors.Errorf("fatal: could not connect to host RPC: %v", err)
+	host := &HostClient{}
+	attach := len(args) == 0
+	if !attach {
+		var err error
+		if host, err = NewHostClient(args[0]); err != nil {
+			return errors.Errorf("fatal: could not connect to host RPC: %v", err)
+		}
 	}
 
 	// Fire up a gRPC server, letting the kernel choose a free port for us.
@@ -58,6 +64,9 @@ func Main(name string, provMaker func(*HostClient) (pulumirpc.ResourceProviderSe
 			if proverr != nil {
 				return fmt.Errorf("failed to create resource provider: %v", proverr)
 			}
+			if attach {
+				prov = &attachableProvider{ResourceProviderServer: prov, host: host}
+			}
 			pulumirpc.RegisterResourceProviderServer(srv, prov)
 			return nil
 		},
@@ -68,6 +77,10 @@ func Main(name string, provMaker func(*HostClient) (pulumirpc.ResourceProviderSe
 
 	// The resource provider protocol requires that we now write out the port we have chosen to listen on.
 	fmt.Printf("%d\n", port)
+	if attach {
+		fmt.Fprintf(os.Stderr, "waiting for the engine to attach; set %s=%s:%d to have it do so\n",
+			plugin.DebugProvidersEnvVar, name, port)
+	}
 
 	// Finally, wait for the server to stop serving.
 	if err := <-done; err != nil {
@@ -76,3 +89,18 @@ func Main(name string, provMaker func(*HostClient) (pulumirpc.ResourceProviderSe
 
 	return nil
 }
+
+// attachableProvider is a provider that was launched outside of the engine, and whose host client is connected to the
+// engine when the engine attaches to it.
+type attachableProvider struct {
+	pulumirpc.ResourceProviderServer
+	host *HostClient
+}
+
+// Attach connects the provider's host client to the engine at the given address.
+func (p *attachableProvider) Attach(ctx context.Context, req *pulumirpc.PluginAttach) (*pbempty.Empty, error) {
+	if err := p.host.attach(req.GetAddress()); err != nil {
+		return nil, err
+	}
+	return &pbempty.Empty{}, nil
+}
@@ -32,12 +32,16 @@ type Goal struct {
 	InitErrors           []string              // errors encountered as we attempted to initialize the resource.
 	PropertyDependencies map[PropertyKey][]URN // the set of dependencies that affect each property.
 	DeleteBeforeReplace  bool                  // true if this resource should be deleted prior to replacement.
+	IgnoreChanges        []string              // a list of property paths to ignore when diffing.
+	CustomTimeouts       CustomTimeouts        // the custom timeouts for the resource's CRUD operations.
+	ImportID             ID                    // if set, the ID of an existing resource to adopt instead of creating.
 }
 
 // NewGoal allocates a new resource goal state.
 func NewGoal(t tokens.Type, name tokens.QName, custom bool, props PropertyMap,
 	parent URN, protect bool, dependencies []URN, provider string, initErrors []string,
-	propertyDependencies map[PropertyKey][]URN, deleteBeforeReplace bool) *Goal {
+	propertyDependencies map[PropertyKey][]URN, deleteBeforeReplace bool, ignoreChanges []string,
+	customTimeouts CustomTimeouts, importID ID) *Goal {
 
 	return &Goal{
 		Type:                 t,
@@ -51,5 +55,8 @@ func NewGoal(t tokens.Type, name tokens.QName, custom bool, props PropertyMap,
 		InitErrors:           initErrors,
 		PropertyDependencies: propertyDependencies,
 		DeleteBeforeReplace:  deleteBeforeReplace,
+		IgnoreChanges:        ignoreChanges,
+		CustomTimeouts:       customTimeouts,
+		ImportID:             importID,
 	}
 }
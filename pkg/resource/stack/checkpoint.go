@@ -19,10 +19,7 @@ package stack
 import (
 	"encoding/json"
 
-	"github.com/pkg/errors"
-
 	"github.com/pulumi/pulumi/pkg/apitype"
-	"github.com/pulumi/pulumi/pkg/apitype/migrate"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
@@ -30,53 +27,11 @@ import (
 	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
+// UnmarshalVersionedCheckpointToLatestCheckpoint unmarshals a versioned checkpoint and migrates it to the current
+// schema version. Use MigrateCheckpoint to also learn which migrations were applied.
 func UnmarshalVersionedCheckpointToLatestCheckpoint(bytes []byte) (*apitype.CheckpointV3, error) {
-	var versionedCheckpoint apitype.VersionedCheckpoint
-	if err := json.Unmarshal(bytes, &versionedCheckpoint); err != nil {
-		return nil, err
-	}
-
-	switch versionedCheckpoint.Version {
-	case 0:
-		// The happens when we are loading a checkpoint file from before we started to version things. Go's
-		// json package did not support strict marshalling before 1.10, and we use 1.9 in our toolchain today.
-		// After we upgrade, we could consider rewriting this code to use DisallowUnknownFields() on the decoder
-		// to have the old checkpoint not even deserialize as an apitype.VersionedCheckpoint.
-		var v1checkpoint apitype.CheckpointV1
-		if err := json.Unmarshal(bytes, &v1checkpoint); err != nil {
-			return nil, err
-		}
-
-		v2checkpoint := migrate.UpToCheckpointV2(v1checkpoint)
-		v3checkpoint := migrate.UpToCheckpointV3(v2checkpoint)
-		return &v3checkpoint, nil
-	case 1:
-		var v1checkpoint apitype.CheckpointV1
-		if err := json.Unmarshal(versionedCheckpoint.Checkpoint, &v1checkpoint); err != nil {
-			return nil, err
-		}
-
-		v2checkpoint := migrate.UpToCheckpointV2(v1checkpoint)
-		v3checkpoint := migrate.UpToCheckpointV3(v2checkpoint)
-		return &v3checkpoint, nil
-	case 2:
-		var v2checkpoint apitype.CheckpointV2
-		if err := json.Unmarshal(versionedCheckpoint.Checkpoint, &v2checkpoint); err != nil {
-			return nil, err
-		}
-
-		v3checkpoint := migrate.UpToCheckpointV3(v2checkpoint)
-		return &v3checkpoint, nil
-	case 3:
-		var v3checkpoint apitype.CheckpointV3
-		if err := json.Unmarshal(versionedCheckpoint.Checkpoint, &v3checkpoint); err != nil {
-			return nil, err
-		}
-
-		return &v3checkpoint, nil
-	default:
-		return nil, errors.Errorf("unsupported checkpoint version %d", versionedCheckpoint.Version)
-	}
+	chk, _, err := MigrateCheckpoint(bytes)
+	return chk, err
 }
 
 // SerializeCheckpoint turns a snapshot into a data structure suitable for serialization.
@@ -0,0 +1,352 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+// The functions in this file read and write checkpoints one resource at a time, rather than materializing the
+// serialized form of the entire checkpoint in memory. For stacks with many resources, the serialized form is several
+// times larger than the snapshot itself.
+
+// ErrCheckpointNotStreamable is returned by DecodeCheckpoint if the checkpoint is not in a form that can be decoded
+// incrementally, e.g. because it uses an older schema version. Such checkpoints must be read in their entirety and
+// passed to UnmarshalVersionedCheckpointToLatestCheckpoint instead.
+var ErrCheckpointNotStreamable = errors.New("checkpoint cannot be decoded incrementally")
+
+// checkpointIndent is the indentation used when writing checkpoints. It matches that of encoding.JSON, so that
+// WriteCheckpoint produces exactly the same bytes as marshaling the result of SerializeCheckpoint.
+const checkpointIndent = "    "
+
+// WriteCheckpoint writes the checkpoint for the given stack, configuration, and snapshot to w as indented JSON, stamped
+// with the given serial number. The output is identical to that of marshaling the result of SerializeCheckpoint (with
+// its Serial set to serial) with encoding.JSON, but resources are serialized and written one at a time.
+func WriteCheckpoint(w io.Writer, stack tokens.QName, config config.Map, snap *deploy.Snapshot, serial int64) error {
+	sw := &streamWriter{w: w}
+
+	sw.printf("{\n%s\"version\": %d,\n", checkpointIndent, apitype.DeploymentSchemaVersionCurrent)
+	if serial != 0 {
+		sw.printf("%s\"serial\": %d,\n", checkpointIndent, serial)
+	}
+	sw.printf("%s\"checkpoint\": {\n", checkpointIndent)
+	prefix := strings.Repeat(checkpointIndent, 2)
+	sw.field(prefix, "stack", stack, true)
+	if len(config) > 0 {
+		sw.field(prefix, "config", config, false)
+	}
+	if snap != nil {
+		sw.printf(",\n%s\"latest\": {\n", prefix)
+		writeDeployment(sw, strings.Repeat(checkpointIndent, 3), snap)
+		sw.printf("\n%s}", prefix)
+	}
+	sw.printf("\n%s}\n}", checkpointIndent)
+
+	return sw.err
+}
+
+// writeDeployment writes the fields of the serialized form of the given snapshot, each preceded by prefix.
+func writeDeployment(sw *streamWriter, prefix string, snap *deploy.Snapshot) {
+	sw.field(prefix, "manifest", serializeManifest(snap.Manifest), true)
+
+	if len(snap.Resources) > 0 {
+		sw.printf(",\n%s\"resources\": [", prefix)
+		elemPrefix := prefix + checkpointIndent
+		for i, res := range snap.Resources {
+			if i > 0 {
+				sw.printf(",")
+			}
+			sw.printf("\n%s", elemPrefix)
+			sw.value(elemPrefix, SerializeResource(res))
+		}
+		sw.printf("\n%s]", prefix)
+	}
+
+	if len(snap.PendingOperations) > 0 {
+		var operations []apitype.OperationV2
+		for _, op := range snap.PendingOperations {
+			operations = append(operations, SerializeOperation(op))
+		}
+		sw.field(prefix, "pending_operations", operations, false)
+	}
+
+	if len(snap.CompletedResources) > 0 {
+		sw.field(prefix, "completed_resources", snap.CompletedResources, false)
+	}
+}
+
+// streamWriter writes JSON fragments to an underlying writer, remembering the first error encountered.
+type streamWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *streamWriter) printf(format string, args ...interface{}) {
+	if sw.err == nil {
+		_, sw.err = fmt.Fprintf(sw.w, format, args...)
+	}
+}
+
+// field writes a single object field. Fields other than the first in an object are separated from their predecessor.
+func (sw *streamWriter) field(prefix, name string, v interface{}, first bool) {
+	if !first {
+		sw.printf(",\n")
+	}
+	sw.printf("%s\"%s\": ", prefix, name)
+	sw.value(prefix, v)
+}
+
+// value writes a single value, indenting any lines after the first with prefix.
+func (sw *streamWriter) value(prefix string, v interface{}) {
+	if sw.err != nil {
+		return
+	}
+	b, err := json.MarshalIndent(v, prefix, checkpointIndent)
+	if err != nil {
+		sw.err = err
+		return
+	}
+	_, sw.err = sw.w.Write(b)
+}
+
+// DecodeCheckpoint reads a checkpoint from r, returning its configuration and snapshot. Resources are deserialized
+// as they are read, so the serialized form of the checkpoint is never held in memory in its entirety. If the
+// checkpoint does not use the current schema version, ErrCheckpointNotStreamable is returned and the caller should
+// fall back to reading the checkpoint in its entirety.
+func DecodeCheckpoint(r io.Reader) (config.Map, *deploy.Snapshot, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, nil, err
+	}
+
+	// The version must come first, so that we know how to interpret the checkpoint before we start reading it. This
+	// is the case for all checkpoints written by SerializeCheckpoint and WriteCheckpoint.
+	key, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if key != "version" {
+		return nil, nil, ErrCheckpointNotStreamable
+	}
+	var version int
+	if err = dec.Decode(&version); err != nil {
+		return nil, nil, err
+	}
+	if version != apitype.DeploymentSchemaVersionCurrent {
+		return nil, nil, ErrCheckpointNotStreamable
+	}
+
+	var cfg config.Map
+	var snap *deploy.Snapshot
+	for dec.More() {
+		if key, err = dec.Token(); err != nil {
+			return nil, nil, err
+		}
+		switch key {
+		case "serial":
+			if err = skipValue(dec); err != nil {
+				return nil, nil, err
+			}
+		case "checkpoint":
+			if cfg, snap, err = decodeCheckpointV3(dec); err != nil {
+				return nil, nil, err
+			}
+		default:
+			// Encrypted checkpoints and the like are not something we know how to read.
+			return nil, nil, ErrCheckpointNotStreamable
+		}
+	}
+	if err = expectDelim(dec, '}'); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, snap, nil
+}
+
+// ReadCheckpointSerial reads the serial number of the checkpoint in r without reading the rest of the checkpoint. The
+// serial number precedes the checkpoint itself in checkpoints written by SerializeCheckpoint and WriteCheckpoint, so
+// only the beginning of r is consumed. Zero is returned for checkpoints that have no serial number.
+func ReadCheckpointSerial(r io.Reader) (int64, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return 0, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return 0, err
+		}
+		switch key {
+		case "version":
+			if err = skipValue(dec); err != nil {
+				return 0, err
+			}
+		case "serial":
+			var serial int64
+			if err = dec.Decode(&serial); err != nil {
+				return 0, err
+			}
+			return serial, nil
+		default:
+			return 0, nil
+		}
+	}
+	return 0, nil
+}
+
+// decodeCheckpointV3 incrementally decodes an apitype.CheckpointV3.
+func decodeCheckpointV3(dec *json.Decoder) (config.Map, *deploy.Snapshot, error) {
+	var cfg config.Map
+	var snap *deploy.Snapshot
+	err := decodeObject(dec, func(key string) error {
+		switch key {
+		case "config":
+			return dec.Decode(&cfg)
+		case "latest":
+			var err error
+			snap, err = decodeDeploymentV3(dec)
+			return err
+		default:
+			return skipValue(dec)
+		}
+	})
+	return cfg, snap, err
+}
+
+// decodeDeploymentV3 incrementally decodes an apitype.DeploymentV3 and deserializes it into a snapshot.
+func decodeDeploymentV3(dec *json.Decoder) (*deploy.Snapshot, error) {
+	var manifest apitype.ManifestV1
+	var resources []*resource.State
+	var operations []apitype.OperationV2
+	var completed []resource.URN
+	isNull, err := decodeObjectOrNull(dec, func(key string) error {
+		switch key {
+		case "manifest":
+			return dec.Decode(&manifest)
+		case "resources":
+			return decodeArray(dec, func() error {
+				var res apitype.ResourceV3
+				if err := dec.Decode(&res); err != nil {
+					return err
+				}
+				desres, err := DeserializeResource(res)
+				if err != nil {
+					return err
+				}
+				resources = append(resources, desres)
+				return nil
+			})
+		case "pending_operations":
+			return dec.Decode(&operations)
+		case "completed_resources":
+			return dec.Decode(&completed)
+		default:
+			return skipValue(dec)
+		}
+	})
+	if err != nil || isNull {
+		return nil, err
+	}
+
+	snap, err := deserializeSnapshot(manifest, resources, operations)
+	if err != nil {
+		return nil, err
+	}
+	snap.CompletedResources = completed
+	return snap, nil
+}
+
+// decodeObject decodes a JSON object, calling field for each of its keys. field must consume the key's value. Keys
+// are lower-cased, as encoding/json matches them case-insensitively.
+func decodeObject(dec *json.Decoder, field func(key string) error) error {
+	isNull, err := decodeObjectOrNull(dec, field)
+	if err == nil && isNull {
+		err = errors.New("expected an object, got null")
+	}
+	return err
+}
+
+// decodeObjectOrNull is like decodeObject, but also accepts a null, in which case it returns true.
+func decodeObjectOrNull(dec *json.Decoder, field func(key string) error) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	if tok == nil {
+		return true, nil
+	}
+	if tok != json.Delim('{') {
+		return false, errors.Errorf("expected an object, got %v", tok)
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		if err = field(strings.ToLower(key.(string))); err != nil {
+			return false, err
+		}
+	}
+	return false, expectDelim(dec, '}')
+}
+
+// decodeArray decodes a JSON array, calling elem to consume each of its elements. A null is treated as an empty
+// array.
+func decodeArray(dec *json.Decoder, elem func() error) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return err
+	}
+	if tok != json.Delim('[') {
+		return errors.Errorf("expected an array, got %v", tok)
+	}
+
+	for dec.More() {
+		if err = elem(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// skipValue consumes and discards the next value.
+func skipValue(dec *json.Decoder) error {
+	var ignored json.RawMessage
+	return dec.Decode(&ignored)
+}
+
+// expectDelim consumes the next token, which must be the given delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return errors.Errorf("expected '%v', got %v", delim, tok)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/config"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func loadTestSnapshot(t *testing.T) *deploy.Snapshot {
+	byts, err := ioutil.ReadFile("testdata/checkpoint-v1.json")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	chk, err := UnmarshalVersionedCheckpointToLatestCheckpoint(byts)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	snap, err := DeserializeCheckpoint(chk)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// Add a pending operation and a completed resource, so that all parts of the snapshot are exercised.
+	snap.PendingOperations = []resource.Operation{
+		resource.NewOperation(snap.Resources[len(snap.Resources)-1], resource.OperationTypeUpdating),
+	}
+	snap.CompletedResources = []resource.URN{snap.Resources[0].URN}
+	return snap
+}
+
+func TestWriteCheckpointMatchesSerializeCheckpoint(t *testing.T) {
+	cfg := config.Map{
+		config.MustMakeKey("proj", "a"): config.NewValue("<b>"),
+		config.MustMakeKey("proj", "c"): config.NewSecureValue("ZGVhZGJlZWY="),
+	}
+
+	for _, snap := range []*deploy.Snapshot{nil, deploy.NewSnapshot(deploy.Manifest{}, nil, nil), loadTestSnapshot(t)} {
+		for _, c := range []config.Map{nil, cfg} {
+			for _, serial := range []int64{0, 42} {
+				chk := SerializeCheckpoint("test", c, snap)
+				chk.Serial = serial
+				expected, err := encoding.JSON.Marshal(chk)
+				assert.NoError(t, err)
+
+				var buf bytes.Buffer
+				err = WriteCheckpoint(&buf, "test", c, snap, serial)
+				assert.NoError(t, err)
+				assert.Equal(t, string(expected), buf.String())
+			}
+		}
+	}
+}
+
+func TestDecodeCheckpoint(t *testing.T) {
+	cfg := config.Map{
+		config.MustMakeKey("proj", "a"): config.NewValue("b"),
+	}
+	snap := loadTestSnapshot(t)
+
+	var buf bytes.Buffer
+	err := WriteCheckpoint(&buf, "test", cfg, snap, 0)
+	assert.NoError(t, err)
+
+	chk, err := UnmarshalVersionedCheckpointToLatestCheckpoint(buf.Bytes())
+	assert.NoError(t, err)
+	expected, err := DeserializeCheckpoint(chk)
+	assert.NoError(t, err)
+
+	actualConfig, actual, err := DecodeCheckpoint(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, actualConfig)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadCheckpointSerial(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCheckpoint(&buf, "test", nil, loadTestSnapshot(t), 42)
+	assert.NoError(t, err)
+
+	serial, err := ReadCheckpointSerial(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), serial)
+
+	// The serial number must not prevent the checkpoint from being decoded incrementally.
+	_, snap, err := DecodeCheckpoint(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.NotNil(t, snap)
+
+	// Checkpoints without a serial number, such as those written by older CLIs, have a serial number of zero.
+	byts, err := ioutil.ReadFile("testdata/checkpoint-v1.json")
+	assert.NoError(t, err)
+	serial, err = ReadCheckpointSerial(bytes.NewReader(byts))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), serial)
+}
+
+func TestDecodeEmptyCheckpoint(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCheckpoint(&buf, "test", nil, nil, 0)
+	assert.NoError(t, err)
+
+	cfg, snap, err := DecodeCheckpoint(&buf)
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+	assert.Nil(t, snap)
+}
+
+func TestDecodeOldCheckpoint(t *testing.T) {
+	byts, err := ioutil.ReadFile("testdata/checkpoint-v1.json")
+	assert.NoError(t, err)
+
+	_, _, err = DecodeCheckpoint(bytes.NewReader(byts))
+	assert.Equal(t, ErrCheckpointNotStreamable, err)
+}
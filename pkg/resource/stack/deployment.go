@@ -15,14 +15,12 @@
 package stack
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
 	"github.com/pulumi/pulumi/pkg/apitype"
-	"github.com/pulumi/pulumi/pkg/apitype/migrate"
 	"github.com/pulumi/pulumi/pkg/resource"
 	"github.com/pulumi/pulumi/pkg/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/util/contract"
@@ -52,23 +50,7 @@ func SerializeDeployment(snap *deploy.Snapshot) *apitype.DeploymentV3 {
 	contract.Require(snap != nil, "snap")
 
 	// Capture the version information into a manifest.
-	manifest := apitype.ManifestV1{
-		Time:    snap.Manifest.Time,
-		Magic:   snap.Manifest.Magic,
-		Version: snap.Manifest.Version,
-	}
-	for _, plug := range snap.Manifest.Plugins {
-		var version string
-		if plug.Version != nil {
-			version = plug.Version.String()
-		}
-		manifest.Plugins = append(manifest.Plugins, apitype.PluginInfoV1{
-			Name:    plug.Name,
-			Path:    plug.Path,
-			Type:    plug.Kind,
-			Version: version,
-		})
-	}
+	manifest := serializeManifest(snap.Manifest)
 
 	// Serialize all vertices and only include a vertex section if non-empty.
 	var resources []apitype.ResourceV3
@@ -82,59 +64,78 @@ func SerializeDeployment(snap *deploy.Snapshot) *apitype.DeploymentV3 {
 	}
 
 	return &apitype.DeploymentV3{
-		Manifest:          manifest,
-		Resources:         resources,
-		PendingOperations: operations,
+		Manifest:           manifest,
+		Resources:          resources,
+		PendingOperations:  operations,
+		CompletedResources: snap.CompletedResources,
+	}
+}
+
+// serializeManifest serializes a snapshot's manifest.
+func serializeManifest(m deploy.Manifest) apitype.ManifestV1 {
+	manifest := apitype.ManifestV1{
+		Time:    m.Time,
+		Magic:   m.Magic,
+		Version: m.Version,
+	}
+	for _, plug := range m.Plugins {
+		var version string
+		if plug.Version != nil {
+			version = plug.Version.String()
+		}
+		manifest.Plugins = append(manifest.Plugins, apitype.PluginInfoV1{
+			Name:    plug.Name,
+			Path:    plug.Path,
+			Type:    plug.Kind,
+			Version: version,
+		})
 	}
+	return manifest
 }
 
 // DeserializeUntypedDeployment deserializes an untyped deployment and produces a `deploy.Snapshot`
 // from it. DeserializeDeployment will return an error if the untyped deployment's version is
 // not within the range `DeploymentSchemaVersionCurrent` and `DeploymentSchemaVersionOldestSupported`.
 func DeserializeUntypedDeployment(deployment *apitype.UntypedDeployment) (*deploy.Snapshot, error) {
-	contract.Require(deployment != nil, "deployment")
-	switch {
-	case deployment.Version > apitype.DeploymentSchemaVersionCurrent:
-		return nil, ErrDeploymentSchemaVersionTooNew
-	case deployment.Version < DeploymentSchemaVersionOldestSupported:
-		return nil, ErrDeploymentSchemaVersionTooOld
+	v3deployment, _, err := MigrateUntypedDeployment(deployment)
+	if err != nil {
+		return nil, err
 	}
+	return DeserializeDeploymentV3(*v3deployment)
+}
 
-	var v3deployment apitype.DeploymentV3
-	switch deployment.Version {
-	case 1:
-		var v1deployment apitype.DeploymentV1
-		if err := json.Unmarshal([]byte(deployment.Deployment), &v1deployment); err != nil {
-			return nil, err
-		}
-		v2deployment := migrate.UpToDeploymentV2(v1deployment)
-		v3deployment = migrate.UpToDeploymentV3(v2deployment)
-	case 2:
-		var v2deployment apitype.DeploymentV2
-		if err := json.Unmarshal([]byte(deployment.Deployment), &v2deployment); err != nil {
-			return nil, err
-		}
-		v3deployment = migrate.UpToDeploymentV3(v2deployment)
-	case 3:
-		if err := json.Unmarshal([]byte(deployment.Deployment), &v3deployment); err != nil {
+// DeserializeDeploymentV3 deserializes a typed DeploymentV3 into a `deploy.Snapshot`.
+func DeserializeDeploymentV3(deployment apitype.DeploymentV3) (*deploy.Snapshot, error) {
+	// For every serialized resource vertex, create a ResourceDeployment out of it.
+	var resources []*resource.State
+	for _, res := range deployment.Resources {
+		desres, err := DeserializeResource(res)
+		if err != nil {
 			return nil, err
 		}
-	default:
-		contract.Failf("unrecognized version: %d", deployment.Version)
+		resources = append(resources, desres)
 	}
 
-	return DeserializeDeploymentV3(v3deployment)
+	snap, err := deserializeSnapshot(deployment.Manifest, resources, deployment.PendingOperations)
+	if err != nil {
+		return nil, err
+	}
+	snap.CompletedResources = deployment.CompletedResources
+	return snap, nil
 }
 
-// DeserializeDeploymentV3 deserializes a typed DeploymentV3 into a `deploy.Snapshot`.
-func DeserializeDeploymentV3(deployment apitype.DeploymentV3) (*deploy.Snapshot, error) {
+// deserializeSnapshot creates a snapshot from a serialized manifest and pending operations and the already
+// deserialized resources.
+func deserializeSnapshot(m apitype.ManifestV1, resources []*resource.State,
+	operations []apitype.OperationV2) (*deploy.Snapshot, error) {
+
 	// Unpack the versions.
 	manifest := deploy.Manifest{
-		Time:    deployment.Manifest.Time,
-		Magic:   deployment.Manifest.Magic,
-		Version: deployment.Manifest.Version,
+		Time:    m.Time,
+		Magic:   m.Magic,
+		Version: m.Version,
 	}
-	for _, plug := range deployment.Manifest.Plugins {
+	for _, plug := range m.Plugins {
 		var version *semver.Version
 		if v := plug.Version; v != "" {
 			sv, err := semver.ParseTolerant(v)
@@ -150,18 +151,8 @@ func DeserializeDeploymentV3(deployment apitype.DeploymentV3) (*deploy.Snapshot,
 		})
 	}
 
-	// For every serialized resource vertex, create a ResourceDeployment out of it.
-	var resources []*resource.State
-	for _, res := range deployment.Resources {
-		desres, err := DeserializeResource(res)
-		if err != nil {
-			return nil, err
-		}
-		resources = append(resources, desres)
-	}
-
 	var ops []resource.Operation
-	for _, op := range deployment.PendingOperations {
+	for _, op := range operations {
 		desop, err := DeserializeOperation(op)
 		if err != nil {
 			return nil, err
@@ -187,6 +178,12 @@ func SerializeResource(res *resource.State) apitype.ResourceV3 {
 		outputs = SerializeProperties(outp)
 	}
 
+	var customTimeouts *resource.CustomTimeouts
+	if res.CustomTimeouts.IsNotEmpty() {
+		timeouts := res.CustomTimeouts
+		customTimeouts = &timeouts
+	}
+
 	return apitype.ResourceV3{
 		URN:                  res.URN,
 		Custom:               res.Custom,
@@ -203,6 +200,7 @@ func SerializeResource(res *resource.State) apitype.ResourceV3 {
 		Provider:             res.Provider,
 		PropertyDependencies: res.PropertyDependencies,
 		PendingReplacement:   res.PendingReplacement,
+		CustomTimeouts:       customTimeouts,
 	}
 }
 
@@ -271,10 +269,15 @@ func DeserializeResource(res apitype.ResourceV3) (*resource.State, error) {
 		return nil, err
 	}
 
+	var customTimeouts resource.CustomTimeouts
+	if res.CustomTimeouts != nil {
+		customTimeouts = *res.CustomTimeouts
+	}
+
 	return resource.NewState(
 		res.Type, res.URN, res.Custom, res.Delete, res.ID,
 		inputs, outputs, res.Parent, res.Protect, res.External, res.Dependencies, res.InitErrors, res.Provider,
-		res.PropertyDependencies, res.PendingReplacement), nil
+		res.PropertyDependencies, res.PendingReplacement, customTimeouts), nil
 }
 
 func DeserializeOperation(op apitype.OperationV2) (resource.Operation, error) {
@@ -78,6 +78,7 @@ func TestDeploymentSerialization(t *testing.T) {
 		"",
 		nil,
 		false,
+		resource.CustomTimeouts{},
 	)
 
 	dep := SerializeResource(res)
@@ -149,6 +150,23 @@ func TestDeploymentSerialization(t *testing.T) {
 	assert.Equal(t, 0, len(dep.Outputs["out-empty-map"].(map[string]interface{})))
 }
 
+func TestCustomTimeoutsSerialization(t *testing.T) {
+	urn := resource.NewURN("stack", "proj", "", "pkgA:m:typA", "resA")
+	res := resource.NewState("pkgA:m:typA", urn, true, false, "id", resource.PropertyMap{}, resource.PropertyMap{},
+		"", false, false, nil, nil, "", nil, false, resource.CustomTimeouts{Create: 600, Delete: 30})
+
+	dep := SerializeResource(res)
+	assert.Equal(t, &resource.CustomTimeouts{Create: 600, Delete: 30}, dep.CustomTimeouts)
+
+	back, err := DeserializeResource(dep)
+	assert.NoError(t, err)
+	assert.Equal(t, res.CustomTimeouts, back.CustomTimeouts)
+
+	// Resources without custom timeouts do not record them.
+	res.CustomTimeouts = resource.CustomTimeouts{}
+	assert.Nil(t, SerializeResource(res).CustomTimeouts)
+}
+
 func TestLoadTooNewDeployment(t *testing.T) {
 	untypedDeployment := &apitype.UntypedDeployment{
 		Version: apitype.DeploymentSchemaVersionCurrent + 1,
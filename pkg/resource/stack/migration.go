@@ -0,0 +1,159 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/apitype/migrate"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// schemaMigrations describes the migration from each schema version to the next, keyed by the version being migrated
+// from. Every version between DeploymentSchemaVersionOldestSupported and apitype.DeploymentSchemaVersionCurrent must
+// have an entry.
+var schemaMigrations = map[int]string{
+	1: "resources gain the 'external' and 'provider' fields; the deprecated 'defaults' field is dropped",
+	2: "resources gain per-property dependencies, which conservatively depend on all of the resource's dependencies",
+}
+
+// MigrationStep describes a single migration from one schema version to the next.
+type MigrationStep struct {
+	From        int    // the version being migrated from.
+	To          int    // the version being migrated to.
+	Description string // a human-readable description of what the migration changes.
+}
+
+// MigrationReport describes the migrations applied to a deployment or checkpoint in order to bring it up to the
+// current schema version.
+type MigrationReport struct {
+	FromVersion int             // the schema version of the deployment as it was read.
+	ToVersion   int             // the schema version of the deployment after migration.
+	Steps       []MigrationStep // the migrations that were applied, in order.
+}
+
+// Migrated returns true if any migrations were applied.
+func (r *MigrationReport) Migrated() bool {
+	return len(r.Steps) > 0
+}
+
+// newMigrationReport creates a report for the migration of a deployment from the given version to the current one.
+func newMigrationReport(from int) *MigrationReport {
+	report := &MigrationReport{FromVersion: from, ToVersion: apitype.DeploymentSchemaVersionCurrent}
+	for v := from; v < apitype.DeploymentSchemaVersionCurrent; v++ {
+		description, has := schemaMigrations[v]
+		contract.Assertf(has, "missing migration from schema version %d", v)
+		report.Steps = append(report.Steps, MigrationStep{From: v, To: v + 1, Description: description})
+	}
+	return report
+}
+
+// checkSchemaVersion returns an error if the given schema version cannot be migrated to the current one.
+func checkSchemaVersion(version int) error {
+	switch {
+	case version > apitype.DeploymentSchemaVersionCurrent:
+		return ErrDeploymentSchemaVersionTooNew
+	case version < DeploymentSchemaVersionOldestSupported:
+		return ErrDeploymentSchemaVersionTooOld
+	}
+	return nil
+}
+
+// MigrateUntypedDeployment migrates an untyped deployment to the current schema version, returning the migrated
+// deployment and a report of the migrations that were applied. It returns ErrDeploymentSchemaVersionTooOld or
+// ErrDeploymentSchemaVersionTooNew if the deployment's version cannot be migrated.
+func MigrateUntypedDeployment(deployment *apitype.UntypedDeployment) (*apitype.DeploymentV3, *MigrationReport, error) {
+	contract.Require(deployment != nil, "deployment")
+	if err := checkSchemaVersion(deployment.Version); err != nil {
+		return nil, nil, err
+	}
+
+	var v3deployment apitype.DeploymentV3
+	switch deployment.Version {
+	case 1:
+		var v1deployment apitype.DeploymentV1
+		if err := json.Unmarshal([]byte(deployment.Deployment), &v1deployment); err != nil {
+			return nil, nil, err
+		}
+		v2deployment := migrate.UpToDeploymentV2(v1deployment)
+		v3deployment = migrate.UpToDeploymentV3(v2deployment)
+	case 2:
+		var v2deployment apitype.DeploymentV2
+		if err := json.Unmarshal([]byte(deployment.Deployment), &v2deployment); err != nil {
+			return nil, nil, err
+		}
+		v3deployment = migrate.UpToDeploymentV3(v2deployment)
+	case 3:
+		if err := json.Unmarshal([]byte(deployment.Deployment), &v3deployment); err != nil {
+			return nil, nil, err
+		}
+	default:
+		contract.Failf("unrecognized version: %d", deployment.Version)
+	}
+
+	return &v3deployment, newMigrationReport(deployment.Version), nil
+}
+
+// MigrateCheckpoint unmarshals a versioned checkpoint and migrates it to the current schema version, returning the
+// migrated checkpoint and a report of the migrations that were applied. Checkpoints written before versioning was
+// introduced are treated as version 1.
+func MigrateCheckpoint(bytes []byte) (*apitype.CheckpointV3, *MigrationReport, error) {
+	var versionedCheckpoint apitype.VersionedCheckpoint
+	if err := json.Unmarshal(bytes, &versionedCheckpoint); err != nil {
+		return nil, nil, err
+	}
+
+	version, raw := versionedCheckpoint.Version, []byte(versionedCheckpoint.Checkpoint)
+	if version == 0 {
+		// The happens when we are loading a checkpoint file from before we started to version things. Go's
+		// json package did not support strict marshalling before 1.10, and we use 1.9 in our toolchain today.
+		// After we upgrade, we could consider rewriting this code to use DisallowUnknownFields() on the decoder
+		// to have the old checkpoint not even deserialize as an apitype.VersionedCheckpoint.
+		version, raw = 1, bytes
+	}
+	if version > apitype.DeploymentSchemaVersionCurrent {
+		return nil, nil, errors.Errorf(
+			"unsupported checkpoint version %d; this version of the Pulumi CLI understands versions up to %d. "+
+				"Please update your version of the Pulumi CLI", version, apitype.DeploymentSchemaVersionCurrent)
+	}
+
+	var v3checkpoint apitype.CheckpointV3
+	switch version {
+	case 1:
+		var v1checkpoint apitype.CheckpointV1
+		if err := json.Unmarshal(raw, &v1checkpoint); err != nil {
+			return nil, nil, err
+		}
+		v2checkpoint := migrate.UpToCheckpointV2(v1checkpoint)
+		v3checkpoint = migrate.UpToCheckpointV3(v2checkpoint)
+	case 2:
+		var v2checkpoint apitype.CheckpointV2
+		if err := json.Unmarshal(raw, &v2checkpoint); err != nil {
+			return nil, nil, err
+		}
+		v3checkpoint = migrate.UpToCheckpointV3(v2checkpoint)
+	case 3:
+		if err := json.Unmarshal(raw, &v3checkpoint); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, errors.Errorf("unsupported checkpoint version %d", versionedCheckpoint.Version)
+	}
+
+	return &v3checkpoint, newMigrationReport(version), nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+)
+
+func TestMigrateV0Checkpoint(t *testing.T) {
+	bytes, err := ioutil.ReadFile("testdata/checkpoint-v0.json")
+	assert.NoError(t, err)
+
+	chk, report, err := MigrateCheckpoint(bytes)
+	assert.NoError(t, err)
+	assert.Len(t, chk.Latest.Resources, 30)
+	assert.True(t, report.Migrated())
+	assert.Equal(t, 1, report.FromVersion)
+	assert.Equal(t, apitype.DeploymentSchemaVersionCurrent, report.ToVersion)
+	if assert.Len(t, report.Steps, 2) {
+		assert.Equal(t, 1, report.Steps[0].From)
+		assert.Equal(t, 2, report.Steps[0].To)
+		assert.Equal(t, 2, report.Steps[1].From)
+		assert.Equal(t, 3, report.Steps[1].To)
+	}
+}
+
+func TestMigrateCurrentCheckpoint(t *testing.T) {
+	bytes, err := json.Marshal(SerializeCheckpoint("stack", nil, nil))
+	assert.NoError(t, err)
+
+	_, report, err := MigrateCheckpoint(bytes)
+	assert.NoError(t, err)
+	assert.False(t, report.Migrated())
+	assert.Equal(t, apitype.DeploymentSchemaVersionCurrent, report.FromVersion)
+}
+
+func TestMigrateCheckpointTooNew(t *testing.T) {
+	bytes, err := json.Marshal(apitype.VersionedCheckpoint{
+		Version:    apitype.DeploymentSchemaVersionCurrent + 1,
+		Checkpoint: json.RawMessage("{}"),
+	})
+	assert.NoError(t, err)
+
+	_, _, err = MigrateCheckpoint(bytes)
+	assert.Error(t, err)
+}
+
+func TestMigrateUntypedDeployment(t *testing.T) {
+	deployment, report, err := MigrateUntypedDeployment(&apitype.UntypedDeployment{
+		Version: 2,
+		Deployment: json.RawMessage(`{"manifest":{},"resources":[{"urn":"urn:pulumi:stack::proj::a:b:c::name",` +
+			`"custom":true,"type":"a:b:c","inputs":{"foo":"bar"},` +
+			`"dependencies":["urn:pulumi:stack::proj::a:b:c::dep"]}]}`),
+	})
+	assert.NoError(t, err)
+	assert.True(t, report.Migrated())
+	assert.Equal(t, 2, report.FromVersion)
+	assert.Len(t, report.Steps, 1)
+	if assert.Len(t, deployment.Resources, 1) {
+		assert.Len(t, deployment.Resources[0].PropertyDependencies["foo"], 1)
+	}
+
+	_, _, err = MigrateUntypedDeployment(&apitype.UntypedDeployment{
+		Version: apitype.DeploymentSchemaVersionCurrent + 1,
+	})
+	assert.Equal(t, ErrDeploymentSchemaVersionTooNew, err)
+}
@@ -0,0 +1,120 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/plugin"
+)
+
+// SerializePlan serializes a saved plan so that it can be written to a file.
+func SerializePlan(plan *deploy.SavedPlan) *apitype.PlanV1 {
+	resources := make(map[resource.URN]apitype.PlannedResourceV1)
+	for urn, r := range plan.Resources {
+		ops := make([]string, len(r.Ops))
+		for i, op := range r.Ops {
+			ops[i] = string(op)
+		}
+
+		var inputs map[string]interface{}
+		if r.Inputs != nil {
+			// Unknown values are normally omitted when serializing properties, but they must be preserved here so that
+			// they are not mistaken for missing values when the plan is checked.
+			inputs = SerializeProperties(replaceUnknowns(resource.NewObjectProperty(r.Inputs)).ObjectValue())
+		}
+
+		resources[urn] = apitype.PlannedResourceV1{Ops: ops, Inputs: inputs}
+	}
+
+	return &apitype.PlanV1{
+		Version:   apitype.PlanSchemaVersionCurrent,
+		Resources: resources,
+	}
+}
+
+// DeserializePlan deserializes a saved plan that was serialized by SerializePlan.
+func DeserializePlan(plan apitype.PlanV1) (*deploy.SavedPlan, error) {
+	if plan.Version > apitype.PlanSchemaVersionCurrent {
+		return nil, errors.Errorf("plan version %d is too new; please upgrade the Pulumi CLI", plan.Version)
+	}
+
+	result := deploy.NewSavedPlan()
+	for urn, r := range plan.Resources {
+		ops := make([]deploy.StepOp, len(r.Ops))
+		for i, op := range r.Ops {
+			ops[i] = deploy.StepOp(op)
+		}
+
+		var inputs resource.PropertyMap
+		if r.Inputs != nil {
+			props, err := DeserializeProperties(r.Inputs)
+			if err != nil {
+				return nil, errors.Wrapf(err, "deserializing inputs of resource '%s'", urn)
+			}
+			inputs = restoreUnknowns(resource.NewObjectProperty(props)).ObjectValue()
+		}
+
+		result.Resources[urn] = &deploy.PlannedResource{Ops: ops, Inputs: inputs}
+	}
+	return result, nil
+}
+
+// replaceUnknowns replaces any unknown values within v with the unknown value sentinel.
+func replaceUnknowns(v resource.PropertyValue) resource.PropertyValue {
+	switch {
+	case v.IsComputed() || v.IsOutput():
+		return resource.NewStringProperty(plugin.UnknownStringValue)
+	case v.IsArray():
+		arr := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for i, elem := range v.ArrayValue() {
+			arr[i] = replaceUnknowns(elem)
+		}
+		return resource.NewArrayProperty(arr)
+	case v.IsObject():
+		obj := make(resource.PropertyMap)
+		for k, elem := range v.ObjectValue() {
+			obj[k] = replaceUnknowns(elem)
+		}
+		return resource.NewObjectProperty(obj)
+	default:
+		return v
+	}
+}
+
+// restoreUnknowns replaces any occurrences of the unknown value sentinel within v with unknown values.
+func restoreUnknowns(v resource.PropertyValue) resource.PropertyValue {
+	switch {
+	case v.IsString() && v.StringValue() == plugin.UnknownStringValue:
+		return resource.MakeComputed(resource.NewStringProperty(""))
+	case v.IsArray():
+		arr := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for i, elem := range v.ArrayValue() {
+			arr[i] = restoreUnknowns(elem)
+		}
+		return resource.NewArrayProperty(arr)
+	case v.IsObject():
+		obj := make(resource.PropertyMap)
+		for k, elem := range v.ObjectValue() {
+			obj[k] = restoreUnknowns(elem)
+		}
+		return resource.NewObjectProperty(obj)
+	default:
+		return v
+	}
+}
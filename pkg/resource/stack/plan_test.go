@@ -0,0 +1,64 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/apitype"
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+)
+
+func TestPlanRoundTrip(t *testing.T) {
+	urn := resource.URN("urn:pulumi:stack::proj::pkgA:m:typA::resA")
+	plan := deploy.NewSavedPlan()
+	plan.Resources[urn] = &deploy.PlannedResource{
+		Ops: []deploy.StepOp{deploy.OpCreateReplacement, deploy.OpReplace, deploy.OpDeleteReplaced},
+		Inputs: resource.PropertyMap{
+			"foo": resource.NewStringProperty("bar"),
+			"baz": resource.MakeComputed(resource.NewStringProperty("")),
+			"qux": resource.NewArrayProperty([]resource.PropertyValue{
+				resource.NewNumberProperty(1),
+				resource.MakeComputed(resource.NewStringProperty("")),
+			}),
+		},
+	}
+
+	b, err := json.Marshal(SerializePlan(plan))
+	assert.NoError(t, err)
+
+	var serialized apitype.PlanV1
+	assert.NoError(t, json.Unmarshal(b, &serialized))
+	assert.Equal(t, apitype.PlanSchemaVersionCurrent, serialized.Version)
+
+	deserialized, err := DeserializePlan(serialized)
+	assert.NoError(t, err)
+	assert.Equal(t, plan.Resources[urn].Ops, deserialized.Resources[urn].Ops)
+
+	// Unknown values must survive the round trip rather than being dropped.
+	inputs := deserialized.Resources[urn].Inputs
+	assert.Equal(t, "bar", inputs["foo"].StringValue())
+	assert.True(t, inputs["baz"].IsComputed())
+	assert.True(t, inputs["qux"].ArrayValue()[1].IsComputed())
+}
+
+func TestDeserializePlanTooNew(t *testing.T) {
+	_, err := DeserializePlan(apitype.PlanV1{Version: apitype.PlanSchemaVersionCurrent + 1})
+	assert.Error(t, err)
+}
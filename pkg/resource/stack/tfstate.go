@@ -0,0 +1,293 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+const (
+	// TerraformStateVersion is the version of the Terraform state format produced by SerializeTerraformState.
+	TerraformStateVersion = 3
+	// terraformStateToolVersion is the Terraform version we claim produced the state. Tools that read Terraform state
+	// refuse state written by versions newer than themselves, so this is the last release using state version 3.
+	terraformStateToolVersion = "0.11.14"
+)
+
+// TerraformState is a best-effort rendering of a snapshot in the Terraform state file format (version 3). It is
+// intended for consumption by tools that read Terraform state for inventory purposes; it cannot be used by Terraform
+// to manage the resources it describes.
+type TerraformState struct {
+	Version          int                    `json:"version"`
+	TerraformVersion string                 `json:"terraform_version"`
+	Serial           int64                  `json:"serial"`
+	Lineage          string                 `json:"lineage"`
+	Modules          []TerraformModuleState `json:"modules"`
+}
+
+// TerraformModuleState is the state of a single Terraform module. Snapshots are always rendered as a single root
+// module.
+type TerraformModuleState struct {
+	Path      []string                          `json:"path"`
+	Outputs   map[string]TerraformOutputState   `json:"outputs"`
+	Resources map[string]TerraformResourceState `json:"resources"`
+	DependsOn []string                          `json:"depends_on"`
+}
+
+// TerraformOutputState is the state of a single Terraform output.
+type TerraformOutputState struct {
+	Sensitive bool        `json:"sensitive"`
+	Type      string      `json:"type"`
+	Value     interface{} `json:"value"`
+}
+
+// TerraformResourceState is the state of a single Terraform resource.
+type TerraformResourceState struct {
+	Type      string                   `json:"type"`
+	DependsOn []string                 `json:"depends_on"`
+	Primary   TerraformInstanceState   `json:"primary"`
+	Deposed   []TerraformInstanceState `json:"deposed"`
+	Provider  string                   `json:"provider"`
+}
+
+// TerraformInstanceState is the state of a single instance of a Terraform resource. Attributes are stored in
+// Terraform's flattened form, e.g. a list property "foo" with one element is stored as "foo.#" and "foo.0".
+type TerraformInstanceState struct {
+	ID         string                 `json:"id"`
+	Attributes map[string]string      `json:"attributes"`
+	Meta       map[string]interface{} `json:"meta"`
+	Tainted    bool                   `json:"tainted"`
+}
+
+// SerializeTerraformState renders a snapshot as Terraform state. The mapping is best-effort: Pulumi type tokens and
+// property names are translated using the conventions of bridged Terraform providers (e.g. "aws:s3/bucket:Bucket"
+// becomes "aws_s3_bucket" and "forceDestroy" becomes "force_destroy"), which will not be accurate for every resource.
+// Component resources, provider resources, and resources pending deletion are omitted. The stack's outputs become
+// the root module's outputs.
+func SerializeTerraformState(snap *deploy.Snapshot) *TerraformState {
+	module := TerraformModuleState{
+		Path:      []string{"root"},
+		Outputs:   make(map[string]TerraformOutputState),
+		Resources: make(map[string]TerraformResourceState),
+		DependsOn: []string{},
+	}
+
+	var lineage string
+	if snap != nil {
+		// First assign addresses to each resource we will emit, so that dependencies can be translated.
+		addresses := make(map[resource.URN]string)
+		for _, res := range snap.Resources {
+			if res.Type == resource.RootStackType {
+				lineage = terraformLineage(string(res.URN))
+				for k, v := range res.Outputs {
+					module.Outputs[string(k)] = terraformOutput(v)
+				}
+				continue
+			}
+			if !res.Custom || res.Delete || providers.IsProviderType(res.Type) {
+				continue
+			}
+
+			address := fmt.Sprintf("%s.%s", terraformType(res.Type), res.URN.Name())
+			if _, has := module.Resources[address]; has {
+				// Multiple resources may share a name if they have different parents; disambiguate them as if they
+				// were created by a resource with a count.
+				for i := 1; ; i++ {
+					if _, has = module.Resources[fmt.Sprintf("%s.%d", address, i)]; !has {
+						address = fmt.Sprintf("%s.%d", address, i)
+						break
+					}
+				}
+			}
+			addresses[res.URN] = address
+			module.Resources[address] = TerraformResourceState{}
+		}
+
+		for _, res := range snap.Resources {
+			address, has := addresses[res.URN]
+			if !has {
+				continue
+			}
+
+			dependsOn := []string{}
+			for _, dep := range res.Dependencies {
+				if depAddress, has := addresses[dep]; has {
+					dependsOn = append(dependsOn, depAddress)
+				}
+			}
+			sort.Strings(dependsOn)
+
+			attributes := make(map[string]string)
+			flattenTerraformAttributes(attributes, "", res.Outputs, true)
+			attributes["id"] = string(res.ID)
+
+			module.Resources[address] = TerraformResourceState{
+				Type:      terraformType(res.Type),
+				DependsOn: dependsOn,
+				Primary: TerraformInstanceState{
+					ID:         string(res.ID),
+					Attributes: attributes,
+					Meta:       map[string]interface{}{},
+					Tainted:    len(res.InitErrors) > 0,
+				},
+				Deposed:  []TerraformInstanceState{},
+				Provider: fmt.Sprintf("provider.%s", res.Type.Package()),
+			}
+		}
+	}
+
+	return &TerraformState{
+		Version:          TerraformStateVersion,
+		TerraformVersion: terraformStateToolVersion,
+		Serial:           1,
+		Lineage:          lineage,
+		Modules:          []TerraformModuleState{module},
+	}
+}
+
+// terraformType translates a Pulumi type token into the name of the Terraform resource type that a bridged provider
+// would have mapped it from, e.g. "aws:s3/bucket:Bucket" becomes "aws_s3_bucket".
+func terraformType(t tokens.Type) string {
+	name := snakeCase(string(t.Name()))
+
+	// Bridged providers name modules "<module>/<member>"; only the first component is part of the Terraform name.
+	mod := string(t.Module().Name())
+	if slash := strings.Index(mod, "/"); slash != -1 {
+		mod = mod[:slash]
+	}
+	if mod != "" && mod != "index" && !strings.HasPrefix(name, mod+"_") {
+		name = mod + "_" + name
+	}
+
+	// Similarly, don't repeat the package name if the type name already starts with it.
+	pkg := string(t.Package())
+	if strings.HasPrefix(name, pkg+"_") {
+		return name
+	}
+	return fmt.Sprintf("%s_%s", pkg, name)
+}
+
+// terraformLineage derives a stable lineage identifier, formatted as a UUID, from the given seed.
+func terraformLineage(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// terraformOutput translates a stack output into a Terraform output.
+func terraformOutput(v resource.PropertyValue) TerraformOutputState {
+	switch {
+	case v.IsArray():
+		return TerraformOutputState{Type: "list", Value: v.Mappable()}
+	case v.IsObject():
+		return TerraformOutputState{Type: "map", Value: v.Mappable()}
+	default:
+		value, _ := terraformAttribute(v)
+		return TerraformOutputState{Type: "string", Value: value}
+	}
+}
+
+// flattenTerraformAttributes flattens the given properties into Terraform's attribute format, adding them to attrs.
+// If snake is true, the property names are translated to Terraform naming conventions. Bridged providers only
+// translate the names of schema-defined properties, so map-typed values (objects that are not list elements, such as
+// tags) retain their original keys.
+func flattenTerraformAttributes(attrs map[string]string, prefix string, props resource.PropertyMap, snake bool) {
+	for k, v := range props {
+		key := string(k)
+		if strings.HasPrefix(key, "__") {
+			// Skip internal properties, such as "__defaults".
+			continue
+		}
+		if snake {
+			key = snakeCase(key)
+		}
+		flattenTerraformAttribute(attrs, prefix+key, v)
+	}
+}
+
+func flattenTerraformAttribute(attrs map[string]string, key string, v resource.PropertyValue) {
+	switch {
+	case v.IsArray():
+		arr := v.ArrayValue()
+		attrs[key+".#"] = strconv.Itoa(len(arr))
+		for i, elem := range arr {
+			elemKey := fmt.Sprintf("%s.%d", key, i)
+			if elem.IsObject() {
+				// Objects within lists are nested blocks, whose property names are translated.
+				obj := elem.ObjectValue()
+				attrs[elemKey+".%"] = strconv.Itoa(len(obj))
+				flattenTerraformAttributes(attrs, elemKey+".", obj, true)
+			} else {
+				flattenTerraformAttribute(attrs, elemKey, elem)
+			}
+		}
+	case v.IsObject():
+		obj := v.ObjectValue()
+		attrs[key+".%"] = strconv.Itoa(len(obj))
+		flattenTerraformAttributes(attrs, key+".", obj, false)
+	default:
+		if value, ok := terraformAttribute(v); ok {
+			attrs[key] = value
+		}
+	}
+}
+
+// terraformAttribute renders a scalar property value as a Terraform attribute value. It returns false if the value
+// has no sensible rendering, such as for null or unknown values.
+func terraformAttribute(v resource.PropertyValue) (string, bool) {
+	switch {
+	case v.IsBool():
+		return strconv.FormatBool(v.BoolValue()), true
+	case v.IsNumber():
+		return strconv.FormatFloat(v.NumberValue(), 'f', -1, 64), true
+	case v.IsString():
+		return v.StringValue(), true
+	case v.IsAsset():
+		return v.AssetValue().Hash, true
+	case v.IsArchive():
+		return v.ArchiveValue().Hash, true
+	default:
+		return "", false
+	}
+}
+
+// snakeCase translates a camelCase name into snake_case, e.g. "forceDestroy" becomes "force_destroy".
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word at an upper case letter that follows a lower case letter or digit, or that begins a
+			// new word after an acronym (as in "URLPath").
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
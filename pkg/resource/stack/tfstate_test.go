@@ -0,0 +1,131 @@
+// Copyright 2016-2018, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/resource"
+	"github.com/pulumi/pulumi/pkg/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/tokens"
+)
+
+func TestTerraformType(t *testing.T) {
+	assert.Equal(t, "aws_s3_bucket", terraformType("aws:s3/bucket:Bucket"))
+	assert.Equal(t, "aws_s3_bucket_policy", terraformType("aws:s3/bucketPolicy:BucketPolicy"))
+	assert.Equal(t, "random_string", terraformType("random:index/randomString:RandomString"))
+	assert.Equal(t, "aws_iam_role", terraformType("aws:iam/role:Role"))
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "force_destroy", snakeCase("forceDestroy"))
+	assert.Equal(t, "arn", snakeCase("arn"))
+	assert.Equal(t, "website_endpoint", snakeCase("websiteEndpoint"))
+	assert.Equal(t, "url_path", snakeCase("URLPath"))
+	assert.Equal(t, "ipv6_cidr_block", snakeCase("ipv6CidrBlock"))
+}
+
+func TestSerializeTerraformState(t *testing.T) {
+	newURN := func(ty tokens.Type, name string) resource.URN {
+		return resource.NewURN("stack", "proj", "", ty, tokens.QName(name))
+	}
+
+	stackRes := &resource.State{
+		Type: "pulumi:pulumi:Stack",
+		URN:  newURN("pulumi:pulumi:Stack", "proj-stack"),
+		Outputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+			"bucketName": "my-bucket-1234",
+			"ports":      []interface{}{float64(80), float64(443)},
+		}),
+	}
+	provider := &resource.State{
+		Type:   "pulumi:providers:aws",
+		URN:    newURN("pulumi:providers:aws", "default"),
+		Custom: true,
+		ID:     "provider-id",
+	}
+	bucket := &resource.State{
+		Type:   "aws:s3/bucket:Bucket",
+		URN:    newURN("aws:s3/bucket:Bucket", "my-bucket"),
+		Custom: true,
+		ID:     "my-bucket-1234",
+		Outputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+			"forceDestroy": true,
+			"tags": map[string]interface{}{
+				"Name": "my-bucket",
+			},
+			"corsRules": []interface{}{
+				map[string]interface{}{
+					"allowedMethods": []interface{}{"GET"},
+				},
+			},
+			"__defaults": []interface{}{},
+		}),
+	}
+	object := &resource.State{
+		Type:         "aws:s3/bucketObject:BucketObject",
+		URN:          newURN("aws:s3/bucketObject:BucketObject", "index"),
+		Custom:       true,
+		ID:           "index.html",
+		Dependencies: []resource.URN{stackRes.URN, bucket.URN},
+		Outputs:      resource.PropertyMap{},
+	}
+	deleted := &resource.State{
+		Type:    "aws:s3/bucket:Bucket",
+		URN:     newURN("aws:s3/bucket:Bucket", "old-bucket"),
+		Custom:  true,
+		Delete:  true,
+		ID:      "old-bucket-1234",
+		Outputs: resource.PropertyMap{},
+	}
+
+	state := SerializeTerraformState(
+		deploy.NewSnapshot(deploy.Manifest{}, []*resource.State{stackRes, provider, bucket, object, deleted}, nil))
+
+	assert.Equal(t, TerraformStateVersion, state.Version)
+	assert.NotEmpty(t, state.Lineage)
+	if !assert.Len(t, state.Modules, 1) {
+		t.FailNow()
+	}
+	module := state.Modules[0]
+
+	assert.Equal(t, TerraformOutputState{Type: "string", Value: "my-bucket-1234"}, module.Outputs["bucketName"])
+	assert.Equal(t, "list", module.Outputs["ports"].Type)
+
+	assert.Len(t, module.Resources, 2)
+	bucketState, has := module.Resources["aws_s3_bucket.my-bucket"]
+	if assert.True(t, has) {
+		assert.Equal(t, "aws_s3_bucket", bucketState.Type)
+		assert.Equal(t, "provider.aws", bucketState.Provider)
+		assert.Equal(t, "my-bucket-1234", bucketState.Primary.ID)
+		assert.Equal(t, map[string]string{
+			"id":                             "my-bucket-1234",
+			"force_destroy":                  "true",
+			"tags.%":                         "1",
+			"tags.Name":                      "my-bucket",
+			"cors_rules.#":                   "1",
+			"cors_rules.0.%":                 "1",
+			"cors_rules.0.allowed_methods.#": "1",
+			"cors_rules.0.allowed_methods.0": "GET",
+		}, bucketState.Primary.Attributes)
+	}
+
+	objectState, has := module.Resources["aws_s3_bucket_object.index"]
+	if assert.True(t, has) {
+		assert.Equal(t, []string{"aws_s3_bucket.my-bucket"}, objectState.DependsOn)
+	}
+}
@@ -811,7 +811,7 @@ func (pt *programTester) testLifeCycleInitialize(dir string) error {
 func (pt *programTester) testLifeCycleDestroy(dir string) error {
 	// Destroy and remove the stack.
 	fprintf(pt.opts.Stdout, "Destroying stack\n")
-	destroy := []string{"destroy", "--non-interactive", "--skip-preview"}
+	destroy := []string{"destroy", "--non-interactive", "--yes", "--skip-preview"}
 	if pt.opts.GetDebugUpdates() {
 		destroy = append(destroy, "-d")
 	}
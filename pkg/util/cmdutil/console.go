@@ -17,10 +17,11 @@ package cmdutil
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
-	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/crypto/ssh/terminal"
 
@@ -39,6 +40,11 @@ func EmojiOr(e, or string) string {
 	return or
 }
 
+// Plain may be set to true in order to keep output stable and line-oriented, so that it reads well in logs such as
+// those kept by CI systems: colors and emoji are turned off unless they are explicitly requested, and progress is
+// printed one line at a time rather than redrawn in place. It defaults to true when running in a known CI system.
+var Plain = ciutil.IsCI()
+
 // DisableInteractive may be set to true in order to disable prompts. This is useful when running in a non-attended
 // scenario, such as in continuous integration, or when using the Pulumi CLI/SDK in a programmatic way.
 var DisableInteractive bool
@@ -99,6 +105,7 @@ type Table struct {
 	Headers []string
 	Rows    []TableRow // Rows of the table.
 	Prefix  string     // Optional prefix to print before each row
+	Full    bool       // Wrap values that do not fit in the terminal onto more lines, instead of truncating them
 }
 
 // TableRow is a row in a table we want to print.  It can be a series of a columns, followed
@@ -108,6 +115,12 @@ type TableRow struct {
 	AdditionalInfo string   // an optional line of information to print after the row
 }
 
+// tableEllipsis marks the end of a value that was truncated to fit in its column.
+const tableEllipsis = "..."
+
+// minTableColumnWidth is the width below which columns are not narrowed to fit a table in the terminal.
+const minTableColumnWidth = 12
+
 // PrintTable prints a grid of rows and columns.  Width of columns is automatically determined by
 // the max length of the items in each column.  A default gap of two spaces is printed between each
 // column.
@@ -117,12 +130,29 @@ func PrintTable(table Table) {
 
 // PrintTableWithGap prints a grid of rows and columns.  Width of columns is automatically determined
 // by the max length of the items in each column.  A gap can be specified between the columns.
+//
+// If the table is printed to a terminal that is too narrow for it, its widest columns are narrowed, and values that
+// do not fit in them are truncated or, if the table is Full, wrapped onto more lines.
 func PrintTableWithGap(table Table, columnGap string) {
+	fprintTable(os.Stdout, table, columnGap, terminalWidth())
+}
+
+// terminalWidth returns the width of the terminal to which stdout is written, or 0 if it isn't a terminal.
+func terminalWidth() int {
+	width, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// fprintTable prints a table to w, fitting it within the given width, unless that is 0.
+func fprintTable(w io.Writer, table Table, columnGap string, width int) {
 	columnCount := len(table.Headers)
 
 	// Figure out the preferred column width for each column.  It will be set to the max length of
 	// any item in that column.
-	preferredColumnWidths := make([]int, columnCount)
+	columnWidths := make([]int, columnCount)
 
 	allRows := []TableRow{{
 		Columns: table.Headers,
@@ -132,45 +162,113 @@ func PrintTableWithGap(table Table, columnGap string) {
 
 	for rowIndex, row := range allRows {
 		columns := row.Columns
-		if len(columns) != len(preferredColumnWidths) {
+		if len(columns) != len(columnWidths) {
 			panic(fmt.Sprintf(
 				"Error printing table.  Column count of row %v didn't match header column count. %v != %v",
-				rowIndex, len(columns), len(preferredColumnWidths)))
+				rowIndex, len(columns), len(columnWidths)))
 		}
 
 		for columnIndex, val := range columns {
-			preferredColumnWidths[columnIndex] = max(preferredColumnWidths[columnIndex], len(val))
+			columnWidths[columnIndex] = max(columnWidths[columnIndex], utf8.RuneCountInString(val))
 		}
 	}
 
-	format := ""
-	for i, maxWidth := range preferredColumnWidths {
-		if i < len(preferredColumnWidths)-1 {
-			format += "%-" + strconv.Itoa(maxWidth+len(columnGap)) + "s"
-		} else {
-			// do not want whitespace appended to the last column.  It would cause wrapping on lines
-			// that were not actually long if some other line was very long.
-			format += "%s"
-		}
+	if width > 0 {
+		fitColumnWidths(columnWidths, width-utf8.RuneCountInString(table.Prefix)-len(columnGap)*(columnCount-1))
 	}
-	format += "\n"
 
-	columns := make([]interface{}, columnCount)
 	for _, row := range allRows {
+		// Split each value into the lines that it occupies in its column.
+		var cells [][]string
+		height := 1
 		for columnIndex, value := range row.Columns {
-			// Now, ensure we have the requested gap between columns as well.
-			if columnIndex < columnCount-1 {
-				value += columnGap
+			var lines []string
+			switch {
+			case utf8.RuneCountInString(value) <= columnWidths[columnIndex]:
+				lines = []string{value}
+			case table.Full:
+				lines = wrapTableValue(value, columnWidths[columnIndex])
+			default:
+				lines = []string{truncateTableValue(value, columnWidths[columnIndex])}
 			}
+			cells = append(cells, lines)
+			height = max(height, len(lines))
+		}
 
-			columns[columnIndex] = value
+		for lineIndex := 0; lineIndex < height; lineIndex++ {
+			line := table.Prefix
+			for columnIndex, lines := range cells {
+				value := ""
+				if lineIndex < len(lines) {
+					value = lines[lineIndex]
+				}
+
+				// do not want whitespace appended to the last column.  It would cause wrapping on lines
+				// that were not actually long if some other line was very long.
+				if columnIndex < columnCount-1 {
+					value += strings.Repeat(" ", columnWidths[columnIndex]-utf8.RuneCountInString(value)) + columnGap
+				}
+				line += value
+			}
+			fmt.Fprintln(w, strings.TrimRight(line, " "))
 		}
 
-		fmt.Printf(table.Prefix+format, columns...)
 		if row.AdditionalInfo != "" {
-			fmt.Print(row.AdditionalInfo)
+			fmt.Fprint(w, row.AdditionalInfo)
+		}
+	}
+}
+
+// fitColumnWidths narrows the widest of the given column widths, one character at a time, until their sum is no more
+// than the available width or no column may be narrowed further.
+func fitColumnWidths(widths []int, available int) {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	for total > available {
+		widest := -1
+		for i, w := range widths {
+			if w > minTableColumnWidth && (widest == -1 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest == -1 {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// truncateTableValue shortens a value to the given width, ending it with an ellipsis.
+func truncateTableValue(value string, width int) string {
+	runes := []rune(value)
+	if width <= len(tableEllipsis) {
+		return string(runes[:width])
+	}
+	return string(runes[:width-len(tableEllipsis)]) + tableEllipsis
+}
+
+// wrapTableValue splits a value into lines of at most the given width, breaking them at spaces where possible.
+func wrapTableValue(value string, width int) []string {
+	var lines []string
+	runes := []rune(value)
+	for len(runes) > width {
+		split := width
+		for i := width; i > 0; i-- {
+			if runes[i] == ' ' {
+				split = i
+				break
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(runes[:split]), " "))
+		runes = runes[split:]
+		for len(runes) > 0 && runes[0] == ' ' {
+			runes = runes[1:]
 		}
 	}
+	return append(lines, string(runes))
 }
 
 func max(a, b int) int {
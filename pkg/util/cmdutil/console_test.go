@@ -0,0 +1,87 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintTable(t *testing.T) {
+	arn := "arn:aws:iam::123456789012:role/deployment-role-with-a-long-name"
+	table := Table{
+		Headers: []string{"KEY", "VALUE"},
+		Rows: []TableRow{
+			{Columns: []string{"aws:region", "us-west-2"}},
+			{Columns: []string{"roleArn", arn}},
+			{Columns: []string{"greeting", "héllo wörld"}, AdditionalInfo: "    note\n"},
+		},
+		Prefix: "  ",
+	}
+	print := func(table Table, width int) string {
+		var buf bytes.Buffer
+		fprintTable(&buf, table, "  ", width)
+		return buf.String()
+	}
+
+	// Without a width, columns are as wide as their widest values, and non-ASCII values stay aligned.
+	assert.Equal(t,
+		"  KEY         VALUE\n"+
+			"  aws:region  us-west-2\n"+
+			"  roleArn     "+arn+"\n"+
+			"  greeting    héllo wörld\n"+
+			"    note\n",
+		print(table, 0))
+
+	// Values that do not fit in a narrow terminal are truncated...
+	assert.Equal(t,
+		"  KEY         VALUE\n"+
+			"  aws:region  us-west-2\n"+
+			"  roleArn     arn:aws:iam::1234567890...\n"+
+			"  greeting    héllo wörld\n"+
+			"    note\n",
+		print(table, 40))
+
+	// ...or wrapped, keeping their column aligned.
+	table.Full = true
+	assert.Equal(t,
+		"  KEY         VALUE\n"+
+			"  aws:region  us-west-2\n"+
+			"  roleArn     arn:aws:iam::123456789012:\n"+
+			"              role/deployment-role-with-\n"+
+			"              a-long-name\n"+
+			"  greeting    héllo wörld\n"+
+			"    note\n",
+		print(table, 40))
+}
+
+func TestWrapTableValue(t *testing.T) {
+	assert.Equal(t, []string{"the quick", "brown fox"}, wrapTableValue("the quick brown fox", 12))
+	assert.Equal(t, []string{"abcd", "efgh", "ij"}, wrapTableValue("abcdefghij", 4))
+	assert.Equal(t, "abcd...", truncateTableValue("abcdefghij", 7))
+}
+
+func TestFitColumnWidths(t *testing.T) {
+	widths := []int{10, 50, 30}
+	fitColumnWidths(widths, 60)
+	assert.Equal(t, []int{10, 25, 25}, widths)
+
+	// Columns are not narrowed beyond a minimum width.
+	widths = []int{10, 50, 30}
+	fitColumnWidths(widths, 20)
+	assert.Equal(t, []int{10, minTableColumnWidth, minTableColumnWidth}, widths)
+}
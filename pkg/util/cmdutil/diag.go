@@ -46,6 +46,11 @@ func GetGlobalColorization() colors.Colorization {
 		return colors.Never
 	}
 
+	// Plain output is meant to be read in logs, where control sequences only get in the way.
+	if Plain {
+		return colors.Never
+	}
+
 	// Disable colors if we're not in an interactive session (i.e. we're redirecting stdout).  This
 	// will just inject color tags into the stream which are not desirable here.
 	if !InteractiveTerminal() {
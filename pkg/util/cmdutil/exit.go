@@ -15,6 +15,7 @@
 package cmdutil
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -23,6 +24,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/diag"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/result"
 )
@@ -123,8 +126,25 @@ func RunResultFunc(run func(cmd *cobra.Command, args []string) *result.Result) f
 				return
 			}
 
+			// If the command asked for a specific exit code, use it, reporting the underlying error, if any.
+			err, code := res.Error(), -1
+			if exitErr, ok := err.(*ExitCodeError); ok {
+				if exitErr.Err == nil {
+					os.Exit(exitErr.Code)
+					return
+				}
+				err, code = exitErr.Err, exitErr.Code
+			}
+
+			// If the command was asked for JSON output, report the error as a JSON object, so that programs driving the
+			// CLI can act on it without parsing the message.
+			if jsonFlag := cmd.Flags().Lookup("json"); jsonFlag != nil && jsonFlag.Value.String() == "true" {
+				logging.V(3).Infof("%s", DetailedError(err))
+				exitJSONError(code, err)
+				return
+			}
+
 			// If there is a stack trace, and logging is enabled, append it.  Otherwise, debug logging it.
-			err := res.Error()
 
 			var msg string
 			if logging.LogToStderr {
@@ -133,12 +153,29 @@ func RunResultFunc(run func(cmd *cobra.Command, args []string) *result.Result) f
 				msg = errorMessage(err)
 				logging.V(3).Infof(DetailedError(err))
 			}
+			if _, hint := errutil.CodeOf(err); hint != "" {
+				msg += "\n    " + hint
+			}
 
-			ExitError(msg)
+			exitErrorCode(code, msg)
 		}
 	}
 }
 
+// ExitCodeError may be returned by a command to make it exit with a specific exit code. If Err is not nil, it is
+// reported as usual before exiting; otherwise, the command exits silently.
+type ExitCodeError struct {
+	Code int   // the code to exit with.
+	Err  error // the underlying error, if any.
+}
+
+func (e *ExitCodeError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit code %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
 // Exit exits with a given error.
 func Exit(err error) {
 	ExitError(errorMessage(err))
@@ -155,6 +192,33 @@ func exitErrorCode(code int, msg string, args ...interface{}) {
 	os.Exit(code)
 }
 
+// jsonError is the JSON representation of an error, reported by commands that were asked for JSON output.
+type jsonError struct {
+	Code     errutil.ErrorCode     `json:"code,omitempty"`     // the error's code, if it has one.
+	Category errutil.ErrorCategory `json:"category,omitempty"` // the category of the error's code, if it has one.
+	Message  string                `json:"message"`
+	Hint     string                `json:"hint,omitempty"`
+}
+
+func makeJSONError(err error) jsonError {
+	code, hint := errutil.CodeOf(err)
+	result := jsonError{Code: code, Message: errorMessage(err), Hint: hint}
+	if code != "" {
+		result.Category = code.Category()
+	}
+	return result
+}
+
+// exitJSONError writes the given error to stderr as a JSON object and exits with the given error exit code.
+func exitJSONError(code int, err error) {
+	out, jsonErr := json.Marshal(struct {
+		Error jsonError `json:"error"`
+	}{makeJSONError(err)})
+	contract.IgnoreError(jsonErr)
+	fmt.Fprintln(os.Stderr, string(out))
+	os.Exit(code)
+}
+
 // errorMessage returns a message, possibly cleaning up the text if appropriate.
 func errorMessage(err error) string {
 	if multi, ok := err.(*multierror.Error); ok {
@@ -0,0 +1,31 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Offline may be set to true in order to forbid network requests, as is necessary in air-gapped environments. Anything
+// that would need the network fails right away with an error from OfflineError instead of waiting for it to time out.
+var Offline bool
+
+// OfflineError returns the error reported when an action, described by the given format string and arguments, requires
+// network access but Offline is set.
+func OfflineError(format string, args ...interface{}) error {
+	return errors.Errorf("%s requires network access, which is disabled by --offline", fmt.Sprintf(format, args...))
+}
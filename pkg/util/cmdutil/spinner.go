@@ -22,9 +22,9 @@ import (
 
 // NewSpinnerAndTicker returns a new Spinner and a ticker that will fire an event when the next call
 // to Spinner.Tick() should be called.  NewSpinnerAndTicket takes into account if stdout is
-// connected to a tty or not and returns either a nice animated spinner that updates quickly, using
-// the specified ttyFrames, or a simple spinner that just prints a dot on each tick and updates
-// slowly.
+// connected to a tty (and whether Plain output was requested) and returns either a nice animated
+// spinner that updates quickly, using the specified ttyFrames, or a simple spinner that just prints
+// a dot on each tick and updates slowly.
 func NewSpinnerAndTicker(prefix string, ttyFrames []string, timesPerSecond time.Duration) (Spinner, *time.Ticker) {
 	if ttyFrames == nil {
 		// If explicit tick frames weren't specified, default to unicode for Mac and ASCII for Windows/Linux.
@@ -35,7 +35,7 @@ func NewSpinnerAndTicker(prefix string, ttyFrames []string, timesPerSecond time.
 		}
 	}
 
-	if InteractiveTerminal() {
+	if InteractiveTerminal() && !Plain {
 		return &ttySpinner{
 			prefix: prefix,
 			frames: ttyFrames,
@@ -0,0 +1,35 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainSpinner(t *testing.T) {
+	defer func(plain bool) { Plain = plain }(Plain)
+
+	// Plain output is never redrawn in place, so it always gets the spinner that prints one dot per tick.
+	Plain = true
+	spinner, ticker := NewSpinnerAndTicker("Loading", nil, 8)
+	defer ticker.Stop()
+
+	dots, ok := spinner.(*dotSpinner)
+	if assert.True(t, ok, "expected a dot spinner, got %T", spinner) {
+		assert.Equal(t, "Loading", dots.prefix)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errutil classifies the failures reported by the CLI with stable codes and hints for fixing them.
+package errutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ErrorCategory is the broad kind of a failure reported by the CLI.
+type ErrorCategory string
+
+const (
+	// ConfigErrorCategory covers missing, malformed, or undecryptable stack configuration.
+	ConfigErrorCategory ErrorCategory = "config"
+	// AuthErrorCategory covers missing or invalid credentials.
+	AuthErrorCategory ErrorCategory = "auth"
+	// PluginErrorCategory covers plugins that cannot be found, trusted, or started.
+	PluginErrorCategory ErrorCategory = "plugin"
+	// ProviderErrorCategory covers resource providers that fail once they are running.
+	ProviderErrorCategory ErrorCategory = "provider"
+	// StateErrorCategory covers stacks and their checkpoints: stacks that don't exist, concurrent updates, etc.
+	StateErrorCategory ErrorCategory = "state"
+)
+
+// ErrorCode identifies a particular kind of failure. Codes are of the form "<category>/<name>" and are stable: once
+// released, a code keeps its meaning, so that tools driving the CLI may rely on it.
+type ErrorCode string
+
+// The codes of the failures that the CLI knows how to classify.
+const (
+	ErrConfigKeyNotFound         ErrorCode = "config/key-not-found"
+	ErrConfigIncorrectPassphrase ErrorCode = "config/incorrect-passphrase"
+
+	ErrAuthNotLoggedIn  ErrorCode = "auth/not-logged-in"
+	ErrAuthInvalidToken ErrorCode = "auth/invalid-token"
+
+	ErrPluginNotFound  ErrorCode = "plugin/not-found"
+	ErrPluginUntrusted ErrorCode = "plugin/untrusted"
+
+	ErrProviderConfigureFailed ErrorCode = "provider/configure-failed"
+	ErrProviderUnresponsive    ErrorCode = "provider/unresponsive"
+
+	ErrStackNotFound       ErrorCode = "state/stack-not-found"
+	ErrStackAlreadyExists  ErrorCode = "state/stack-already-exists"
+	ErrStackUpdateConflict ErrorCode = "state/update-conflict"
+	ErrStackChanged        ErrorCode = "state/concurrent-modification"
+)
+
+// Category returns the category of the failures identified by this code.
+func (c ErrorCode) Category() ErrorCategory {
+	return ErrorCategory(strings.SplitN(string(c), "/", 2)[0])
+}
+
+// ErrorWithCode is implemented by errors that identify the kind of failure they represent.
+type ErrorWithCode interface {
+	error
+	// Code returns the code that identifies the kind of failure.
+	Code() ErrorCode
+}
+
+// ErrorWithHint is implemented by errors that can suggest how to fix the failure they represent.
+type ErrorWithHint interface {
+	error
+	// Hint returns a suggestion, e.g. "did you mean 'dev'?" or a command to run, or "" if there is none.
+	Hint() string
+}
+
+// codedError annotates an error with a code and a hint. See WithCode.
+type codedError struct {
+	err  error
+	code ErrorCode
+	hint string
+}
+
+// WithCode annotates err with the code that identifies the kind of failure it represents and, if hint is not
+// empty, a suggestion for how to fix it. The annotated error's message is err's.
+func WithCode(code ErrorCode, hint string, err error) error {
+	return &codedError{err: err, code: code, hint: hint}
+}
+
+func (e *codedError) Error() string   { return e.err.Error() }
+func (e *codedError) Cause() error    { return e.err }
+func (e *codedError) Code() ErrorCode { return e.code }
+func (e *codedError) Hint() string    { return e.hint }
+
+// CodeOf returns the code and hint of the first error in err's chain of causes that has a code, or "" if there is
+// none. A multierror with a single error is looked through.
+func CodeOf(err error) (ErrorCode, string) {
+	for err != nil {
+		if multi, ok := err.(*multierror.Error); ok && len(multi.WrappedErrors()) == 1 {
+			err = multi.WrappedErrors()[0]
+			continue
+		}
+		if coded, ok := err.(ErrorWithCode); ok {
+			var hint string
+			if hinted, ok := err.(ErrorWithHint); ok {
+				hint = hinted.Hint()
+			}
+			return coded.Code(), hint
+		}
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = causer.Cause()
+	}
+	return "", ""
+}
+
+// DidYouMean returns a hint suggesting the candidate that is most similar to the given misspelled name, or "" if no
+// candidate is similar enough to be worth suggesting.
+func DidYouMean(name string, candidates []string) string {
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	best, bestDistance := "", maxDistance+1
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(c)); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf("did you mean '%s'?", best)
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev, cur := make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
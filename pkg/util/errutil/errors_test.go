@@ -0,0 +1,60 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errutil
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	err := WithCode(ErrStackNotFound, "did you mean 'dev'?", errors.New("no stack named 'dve' found"))
+	assert.Equal(t, "no stack named 'dve' found", err.Error())
+	assert.Equal(t, StateErrorCategory, ErrStackNotFound.Category())
+
+	code, hint := CodeOf(err)
+	assert.Equal(t, ErrStackNotFound, code)
+	assert.Equal(t, "did you mean 'dev'?", hint)
+
+	// Codes are found through wrapping and single-error multierrors.
+	code, hint = CodeOf(multierror.Append(nil, errors.Wrap(err, "selecting stack")))
+	assert.Equal(t, ErrStackNotFound, code)
+	assert.Equal(t, "did you mean 'dev'?", hint)
+
+	// The outermost code wins.
+	code, hint = CodeOf(WithCode(ErrStackChanged, "", errors.Wrap(err, "saving")))
+	assert.Equal(t, ErrStackChanged, code)
+	assert.Equal(t, "", hint)
+
+	code, hint = CodeOf(errors.New("boom"))
+	assert.Equal(t, ErrorCode(""), code)
+	assert.Equal(t, "", hint)
+
+	code, _ = CodeOf(multierror.Append(nil, err, errors.New("boom")))
+	assert.Equal(t, ErrorCode(""), code)
+}
+
+func TestDidYouMean(t *testing.T) {
+	candidates := []string{"dev", "production", "aws:region", "staging"}
+	assert.Equal(t, "did you mean 'dev'?", DidYouMean("dve", candidates))
+	assert.Equal(t, "did you mean 'production'?", DidYouMean("prodution", candidates))
+	assert.Equal(t, "did you mean 'aws:region'?", DidYouMean("aws:regoin", candidates))
+	assert.Equal(t, "did you mean 'staging'?", DidYouMean("STAGING", candidates))
+	assert.Equal(t, "", DidYouMean("test", candidates))
+	assert.Equal(t, "", DidYouMean("dev", nil))
+}
@@ -0,0 +1,114 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// fileStamp records enough about a file to tell whether it has changed.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// FileSnapshot records the state of every file beneath a set of root directories at a point in time.
+type FileSnapshot map[string]fileStamp
+
+// SnapshotFiles records the state of every file beneath the given roots. Directories for which skipDir returns true
+// are not descended into; this is typically used to skip version control and dependency directories.
+func SnapshotFiles(roots []string, skipDir func(path string) bool) (FileSnapshot, error) {
+	snap := make(FileSnapshot)
+	for _, root := range roots {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				// Files may come and go while we're walking; just skip anything that has disappeared.
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				if path != root && skipDir != nil && skipDir(path) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			snap[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+// Changes returns the sorted paths of the files that were added, removed, or modified between this snapshot and a
+// later one.
+func (snap FileSnapshot) Changes(later FileSnapshot) []string {
+	var changed []string
+	for path, stamp := range later {
+		if old, has := snap[path]; !has || old.size != stamp.size || !old.modTime.Equal(stamp.modTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range snap {
+		if _, has := later[path]; !has {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// WaitForChanges polls the files beneath the given roots every interval until they differ from the base snapshot,
+// and then keeps polling until no further changes have been seen for the quiet period, so that a burst of edits (such
+// as a save-all in an editor, or a build) is reported as a single batch. It returns the snapshot of the files once
+// they have settled, along with the paths that changed relative to base.
+func WaitForChanges(ctx context.Context, base FileSnapshot, roots []string, skipDir func(path string) bool,
+	interval, quiet time.Duration) (FileSnapshot, []string, error) {
+
+	latest := base
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		snap, err := SnapshotFiles(roots, skipDir)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(latest.Changes(snap)) > 0 {
+			latest, lastChange = snap, time.Now()
+			continue
+		}
+		if !lastChange.IsZero() && time.Since(lastChange) >= quiet {
+			if changed := base.Changes(latest); len(changed) > 0 {
+				return latest, changed, nil
+			}
+			// The files were changed and then changed back; keep waiting.
+			lastChange = time.Time{}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsutil
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-watch-test")
+	assert.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	write := func(name, contents string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0600))
+	}
+	write("index.js", "a")
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "node_modules"), 0700))
+	write(filepath.Join("node_modules", "dep.js"), "a")
+
+	skip := func(path string) bool { return filepath.Base(path) == "node_modules" }
+	base, err := SnapshotFiles([]string{dir}, skip)
+	assert.NoError(t, err)
+	assert.Len(t, base, 1)
+
+	// Changes to skipped directories are ignored, and edits to several files are reported as one batch.
+	write(filepath.Join("node_modules", "dep.js"), "bb")
+	write("index.js", "bb")
+	write("new.js", "a")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	snap, changed, err := WaitForChanges(ctx, base, []string{dir}, skip, 10*time.Millisecond, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "index.js"), filepath.Join(dir, "new.js")}, changed)
+	assert.Len(t, snap, 2)
+
+	// Cancellation stops the wait.
+	cancel()
+	_, _, err = WaitForChanges(ctx, snap, []string{dir}, skip, 10*time.Millisecond, 50*time.Millisecond)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -15,27 +15,60 @@
 package httputil
 
 import (
-	"context"
 	"net/http"
 	"time"
 
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
 	"github.com/pulumi/pulumi/pkg/util/retry"
 )
 
 // maxRetryCount is the number of times to try an http request before giving up an returning the last error
 const maxRetryCount = 5
 
+// RetryOpts controls how DoWithRetryOpts retries a request. Zero values are replaced with the defaults used by
+// DoWithRetry.
+type RetryOpts struct {
+	MaxRetryCount int           // the number of times to try the request before giving up.
+	Delay         time.Duration // the delay before the first retry.
+	Backoff       float64       // the multiplier by which the delay grows with each retry.
+	MaxDelay      time.Duration // the maximum delay between retries.
+}
+
+// PersistentRetryOpts retries a request for a couple of minutes before giving up. It is meant for requests, such as
+// writes of a stack's checkpoint, whose failure would lose work that is expensive to repeat.
+var PersistentRetryOpts = RetryOpts{
+	MaxRetryCount: 10,
+	Delay:         time.Second,
+	Backoff:       2,
+	MaxDelay:      30 * time.Second,
+}
+
+// IsRetryable returns true if a request that resulted in the given response or error may succeed if it is retried:
+// that is, if it failed to reach the server, or the server responded with 429 Too Many Requests or a 5xx status.
+func IsRetryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return res.StatusCode == http.StatusTooManyRequests || (500 <= res.StatusCode && res.StatusCode <= 599)
+}
+
 // DoWithRetry calls client.Do, and in the case of an error, retries the operation again after a slight delay.
 func DoWithRetry(req *http.Request, client *http.Client) (*http.Response, error) {
+	return DoWithRetryOpts(req, client, RetryOpts{})
+}
+
+// DoWithRetryOpts calls client.Do, and in the case of an error, retries the operation with exponential backoff, as
+// directed by the given options. Retries stop early if the request's context is canceled.
+func DoWithRetryOpts(req *http.Request, client *http.Client, opts RetryOpts) (*http.Response, error) {
 	contract.Assertf(req.ContentLength == 0 || req.GetBody != nil,
 		"Retryable request must have no body or rewindable body")
 
-	inRange := func(test, lower, upper int) bool {
-		return lower <= test && test <= upper
+	retryCount := opts.MaxRetryCount
+	if retryCount == 0 {
+		retryCount = maxRetryCount
 	}
-
-	_, res, err := retry.Until(context.Background(), retry.Acceptor{
+	acceptor := retry.Acceptor{
 		Accept: func(try int, nextRetryTime time.Duration) (bool, interface{}, error) {
 			if try > 0 && req.GetBody != nil {
 				// Reset request body, if present, for retries.
@@ -47,10 +80,10 @@ func DoWithRetry(req *http.Request, client *http.Client) (*http.Response, error)
 			}
 
 			res, resErr := client.Do(req)
-			if resErr == nil && !inRange(res.StatusCode, 500, 599) {
+			if !IsRetryable(res, resErr) {
 				return true, res, nil
 			}
-			if try >= (maxRetryCount - 1) {
+			if try >= (retryCount - 1) {
 				return true, res, resErr
 			}
 
@@ -58,17 +91,44 @@ func DoWithRetry(req *http.Request, client *http.Client) (*http.Response, error)
 			if resErr == nil {
 				contract.IgnoreError(res.Body.Close())
 			}
+			// Leave out the query, which may hold credentials such as a shared access signature.
+			target := *req.URL
+			target.RawQuery = ""
+			logging.V(7).Infof("retrying %s %s in %v: %v", req.Method, &target, nextRetryTime,
+				describeFailure(res, resErr))
 			return false, nil, nil
 		},
-	})
+	}
+	if opts.Delay != 0 {
+		acceptor.Delay = &opts.Delay
+	}
+	if opts.Backoff != 0 {
+		acceptor.Backoff = &opts.Backoff
+	}
+	if opts.MaxDelay != 0 {
+		acceptor.MaxDelay = &opts.MaxDelay
+	}
 
+	_, res, err := retry.Until(req.Context(), acceptor)
 	if err != nil {
 		return nil, err
 	}
+	if res == nil {
+		// The context was canceled before the request succeeded.
+		return nil, req.Context().Err()
+	}
 
 	return res.(*http.Response), nil
 }
 
+// describeFailure describes the response or error with which a request failed.
+func describeFailure(res *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return res.Status
+}
+
 // GetWithRetry issues a GET request with the given client, and in the case of an error, retries the operation again
 // after a slight delay.
 func GetWithRetry(url string, client *http.Client) (*http.Response, error) {
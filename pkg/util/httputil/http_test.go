@@ -15,13 +15,17 @@
 package httputil
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/pem"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/net/http2"
 
@@ -92,3 +96,58 @@ func TestRetryPostHTTP2(t *testing.T) {
 	assert.Equal(t, 2, tries)
 	assert.Equal(t, 200, res.StatusCode)
 }
+
+// Test that DoWithRetryOpts retries throttled requests as many times as it is told to, and stops when the request's
+// context is canceled.
+func TestRetryOpts(t *testing.T) {
+	tries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tries++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	opts := RetryOpts{MaxRetryCount: 3, Delay: time.Millisecond, Backoff: 1, MaxDelay: time.Millisecond}
+	req, err := http.NewRequest("PUT", server.URL, strings.NewReader("hello, server"))
+	assert.NoError(t, err)
+	res, err := DoWithRetryOpts(req, server.Client(), opts)
+	assert.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+	assert.Equal(t, 3, tries)
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts.MaxRetryCount = 100
+	_, err = DoWithRetryOpts(req.WithContext(ctx), server.Client(), opts)
+	assert.Equal(t, context.Canceled, err)
+}
+
+// Test that a transport trusts the certificates that it is told to, in addition to the system's.
+func TestTrustExtraCACerts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	// The server's certificate is not trusted by default.
+	_, err := (&http.Client{Transport: &http.Transport{}}).Get(server.URL)
+	assert.Error(t, err)
+
+	file, err := ioutil.TempFile("", "pulumi-ca")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	assert.NoError(t, pem.Encode(file, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	assert.NoError(t, file.Close())
+
+	transport := &http.Transport{}
+	assert.NoError(t, trustExtraCACerts(transport, file.Name()))
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.NoError(t, resp.Body.Close())
+	}
+
+	// Files without certificates are rejected.
+	assert.Error(t, trustExtraCACerts(&http.Transport{}, os.DevNull))
+}
@@ -0,0 +1,69 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// ExtraCACertsEnvVar may be set to the path of a file of PEM-encoded certificates that are trusted, in addition to the
+// system's certificate authorities, by outbound HTTPS requests. This lets the CLI reach backends and download plugins
+// from networks that intercept TLS connections with their own certificate authority.
+const ExtraCACertsEnvVar = "PULUMI_EXTRA_CA_CERTS"
+
+// ConfigureDefaultTransport configures http.DefaultTransport, which is shared by http.DefaultClient and so by the
+// backend, plugin download, and template clients, to trust the certificates in the file named by ExtraCACertsEnvVar,
+// if any. Proxies are already taken from the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables by the
+// default transport.
+func ConfigureDefaultTransport() error {
+	file := os.Getenv(ExtraCACertsEnvVar)
+	if file == "" {
+		return nil
+	}
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return errors.New("cannot configure the default HTTP transport")
+	}
+	return trustExtraCACerts(transport, file)
+}
+
+// trustExtraCACerts configures the given transport to trust the certificates in the given file, in addition to the
+// system's certificate authorities.
+func trustExtraCACerts(transport *http.Transport, file string) error {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return errors.Wrapf(err, "reading the certificates named by %s", ExtraCACertsEnvVar)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return errors.Errorf("%s contains no PEM-encoded certificates", file)
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.RootCAs = pool
+	return nil
+}
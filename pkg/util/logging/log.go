@@ -26,9 +26,14 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 )
@@ -40,28 +45,123 @@ type Filter interface {
 var LogToStderr = false // true if logging is being redirected to stderr.
 var Verbose = 0         // >0 if verbose logging is enabled at a particular level.
 var LogFlow = false     // true to flow logging settings to child processes.
+var LogFile = ""        // the file that logging is being redirected to, if any.
+
+// The levels of verbose logging that `-v`, `-vv`, and `-vvv` enable, respectively.
+const (
+	CLIVerbosity    = 3 // the CLI's own decisions, e.g. which backend, stack, and plugins it uses.
+	EngineVerbosity = 7 // the engine's work, e.g. the steps it plans and executes for each resource.
+	PluginVerbosity = 9 // everything, including the details of each call made to plugins.
+)
 
 var rwLock sync.RWMutex
 var filters []Filter
 
-func V(level glog.Level) glog.Verbose {
-	return glog.V(level)
+var logFileLock sync.Mutex
+var logFile *os.File
+
+// VerboseLogger is a boolean that is true if verbose logging is enabled at the level it was requested for by V. As
+// with glog.Verbose, its methods log only if it is true.
+type VerboseLogger glog.Verbose
+
+func V(level glog.Level) VerboseLogger {
+	return VerboseLogger(glog.V(level))
+}
+
+func (v VerboseLogger) Info(args ...interface{}) {
+	if v {
+		output('I', fmt.Sprint(args...))
+	}
+}
+
+func (v VerboseLogger) Infoln(args ...interface{}) {
+	if v {
+		output('I', fmt.Sprintln(args...))
+	}
+}
+
+func (v VerboseLogger) Infof(format string, args ...interface{}) {
+	if v {
+		output('I', fmt.Sprintf(format, args...))
+	}
 }
 
 func Errorf(format string, args ...interface{}) {
-	glog.Errorf("%s", FilterString(fmt.Sprintf(format, args...)))
+	output('E', fmt.Sprintf(format, args...))
 }
 
 func Infof(format string, args ...interface{}) {
-	glog.Infof("%s", FilterString(fmt.Sprintf(format, args...)))
+	output('I', fmt.Sprintf(format, args...))
 }
 
 func Warningf(format string, args ...interface{}) {
-	glog.Warningf("%s", FilterString(fmt.Sprintf(format, args...)))
+	output('W', fmt.Sprintf(format, args...))
+}
+
+// output logs a message with the given severity on behalf of the caller of the function that called output. The message
+// goes to the log file, if there is one, and to glog otherwise.
+func output(severity byte, msg string) {
+	msg = FilterString(msg)
+
+	logFileLock.Lock()
+	defer logFileLock.Unlock()
+	if logFile == nil {
+		switch severity {
+		case 'E':
+			glog.ErrorDepth(2, msg)
+		case 'W':
+			glog.WarningDepth(2, msg)
+		default:
+			glog.InfoDepth(2, msg)
+		}
+		return
+	}
+
+	// Format the message as glog would: "Lmmdd hh:mm:ss.uuuuuu pid file:line] msg".
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		file, line = "???", 1
+	}
+	header := fmt.Sprintf("%c%s %7d %s:%d] ",
+		severity, time.Now().Format("0102 15:04:05.000000"), os.Getpid(), filepath.Base(file), line)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	_, _ = logFile.WriteString(header + msg) // there is nowhere to report a failure to log.
+}
+
+// logLinePattern matches lines logged by glog, or by this package, in another process.
+var logLinePattern = regexp.MustCompile(`^[IWEF]\d{4} \d{2}:\d{2}:\d{2}\.\d{6} `)
+
+// IsLogLine returns true if the given line of output from another process, e.g. a plugin, was logged by glog.
+func IsLogLine(line string) bool {
+	return logLinePattern.MatchString(line)
+}
+
+// Passthrough writes a line logged by another process, e.g. a plugin whose logging settings flowed from this one, to
+// this process's log file, if it has one, or to stderr otherwise.
+func Passthrough(line string) {
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+
+	logFileLock.Lock()
+	defer logFileLock.Unlock()
+	if logFile != nil {
+		_, _ = logFile.WriteString(line)
+	} else {
+		_, _ = os.Stderr.WriteString(line)
+	}
 }
 
 func Flush() {
 	glog.Flush()
+
+	logFileLock.Lock()
+	defer logFileLock.Unlock()
+	if logFile != nil {
+		_ = logFile.Sync()
+	}
 }
 
 // InitLogging ensures the logging library has been initialized with the given settings.
@@ -83,6 +183,30 @@ func InitLogging(logToStderr bool, verbose int, logFlow bool) {
 	}
 }
 
+// InitLogFile redirects logging, including that of any child processes to which the logging settings flow, to the file
+// at the given path, replacing its contents. An empty path ends any redirection.
+func InitLogFile(path string) error {
+	logFileLock.Lock()
+	defer logFileLock.Unlock()
+
+	if logFile != nil {
+		if err := logFile.Close(); err != nil {
+			return err
+		}
+		logFile, LogFile = nil, ""
+	}
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	logFile, LogFile = f, path
+	return nil
+}
+
 func assertNoError(err error) {
 	if err != nil {
 		failfast(err.Error())
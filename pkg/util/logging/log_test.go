@@ -15,6 +15,10 @@
 package logging
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,6 +36,38 @@ func TestInitLogging(t *testing.T) {
 	assert.Equal(t, prevFlow, LogFlow)
 }
 
+func TestLogFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logging-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "pulumi.log")
+	assert.NoError(t, InitLogFile(path))
+	assert.Equal(t, path, LogFile)
+	V(0).Infof("hello %s", "world")
+	V(100).Infof("too verbose to log")
+	Warningf("careful")
+	Passthrough("I0102 03:04:05.000006    1234 plugin.go:1] from a plugin")
+	Flush()
+	assert.NoError(t, InitLogFile(""))
+	assert.Equal(t, "", LogFile)
+
+	bytes, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(bytes), "\n"), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.True(t, IsLogLine(lines[0]), lines[0])
+		assert.True(t, strings.HasPrefix(lines[0], "I"))
+		assert.Contains(t, lines[0], " log_test.go:")
+		assert.True(t, strings.HasSuffix(lines[0], "] hello world"), lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "W"))
+		assert.True(t, strings.HasSuffix(lines[1], "] careful"), lines[1])
+		assert.Equal(t, "I0102 03:04:05.000006    1234 plugin.go:1] from a plugin", lines[2])
+	}
+
+	assert.False(t, IsLogLine("Updating (dev):"))
+}
+
 func TestFilter(t *testing.T) {
 	filter1 := CreateFilter([]string{"secret1", "secret2"}, "[secret]")
 	msg1 := filter1.Filter("These are my secrets: secret1, secret2, secret3, secret10")
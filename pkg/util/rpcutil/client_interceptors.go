@@ -0,0 +1,357 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+// ClientInterceptorsEnvVar is the environment variable that configures the interceptors that wrap every RPC the
+// engine makes to a plugin, when the --rpc-interceptors flag is not passed. See ConfigureClientInterceptors.
+const ClientInterceptorsEnvVar = "PULUMI_RPC_INTERCEPTORS"
+
+// ClientInterceptorFactory creates a client interceptor from the argument given to it in the interceptor
+// configuration, which is empty if there is none.
+type ClientInterceptorFactory func(arg string) (grpc.UnaryClientInterceptor, error)
+
+var (
+	clientInterceptorsLock     sync.Mutex
+	clientInterceptorFactories = map[string]ClientInterceptorFactory{
+		"chaos":   newChaosInterceptor,
+		"header":  newHeaderInterceptor,
+		"log":     newLogInterceptor,
+		"metrics": newMetricsInterceptor,
+	}
+	clientInterceptors []grpc.UnaryClientInterceptor
+)
+
+// RegisterClientInterceptor makes the interceptors that the given factory creates available to the interceptor
+// configuration under the given name.
+func RegisterClientInterceptor(name string, factory ClientInterceptorFactory) {
+	clientInterceptorsLock.Lock()
+	defer clientInterceptorsLock.Unlock()
+	clientInterceptorFactories[name] = factory
+}
+
+// ConfigureClientInterceptors sets the interceptors that wrap every RPC the engine makes to a plugin. The
+// configuration is a comma-separated list of interceptors, outermost first, each either a name or a name and an
+// argument separated by "=". The built-in interceptors are:
+//
+//     log[=FILE]        logs each RPC's method, duration, status, request, and response to FILE, or to the log
+//     metrics[=FILE]    counts the RPCs made to each method, and their total duration, and writes them to FILE, or to
+//                       the log, when FlushClientInterceptors is called
+//     header=KEY:VALUE  adds a header to each RPC's metadata; environment variables in VALUE are expanded
+//     chaos[=RATE]      fails the given fraction (by default, 0.1) of RPCs as though the plugin were unavailable
+func ConfigureClientInterceptors(config string) error {
+	clientInterceptorsLock.Lock()
+	defer clientInterceptorsLock.Unlock()
+
+	// Interceptors that accumulate output register functions to flush it as they are created.
+	oldFlushes := clientInterceptorFlushes
+	clientInterceptorFlushes = nil
+
+	var interceptors []grpc.UnaryClientInterceptor
+	for _, spec := range strings.Split(config, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		name, arg := spec, ""
+		if eq := strings.Index(spec, "="); eq != -1 {
+			name, arg = spec[:eq], spec[eq+1:]
+		}
+		factory, has := clientInterceptorFactories[name]
+		if !has {
+			var names []string
+			for n := range clientInterceptorFactories {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			clientInterceptorFlushes = oldFlushes
+			return errors.Errorf("unrecognized RPC interceptor '%s'; expected one of %s",
+				name, strings.Join(names, ", "))
+		}
+		interceptor, err := factory(arg)
+		if err != nil {
+			clientInterceptorFlushes = oldFlushes
+			return errors.Wrapf(err, "invalid RPC interceptor '%s'", spec)
+		}
+		interceptors = append(interceptors, interceptor)
+	}
+	clientInterceptors = interceptors
+	return nil
+}
+
+// ClientInterceptor returns the interceptor that wraps every RPC the engine makes to a plugin: OpenTracing, followed
+// by any interceptors set by ConfigureClientInterceptors.
+func ClientInterceptor() grpc.UnaryClientInterceptor {
+	clientInterceptorsLock.Lock()
+	defer clientInterceptorsLock.Unlock()
+	return ChainUnaryClientInterceptors(append([]grpc.UnaryClientInterceptor{OpenTracingClientInterceptor()},
+		clientInterceptors...)...)
+}
+
+// FlushClientInterceptors writes out anything that the configured interceptors have accumulated, such as metrics.
+func FlushClientInterceptors() {
+	clientInterceptorsLock.Lock()
+	defer clientInterceptorsLock.Unlock()
+	for _, flush := range clientInterceptorFlushes {
+		flush()
+	}
+}
+
+// clientInterceptorFlushes are run by FlushClientInterceptors.
+var clientInterceptorFlushes []func()
+
+// ChainUnaryClientInterceptors returns an interceptor that runs the given interceptors, the first outermost.
+func ChainUnaryClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], invoker
+			invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+				opts ...grpc.CallOption) error {
+				return interceptor(ctx, method, req, reply, cc, next, opts...)
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// isProbe returns true if an RPC is the empty call that the engine makes to check that a plugin is listening.
+func isProbe(method string) bool {
+	return method == ""
+}
+
+// openInterceptorOutput opens the file that an interceptor writes to, or returns nil if it should write to the log.
+func openInterceptorOutput(path string) (io.Writer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+}
+
+func newLogInterceptor(path string) (grpc.UnaryClientInterceptor, error) {
+	out, err := openInterceptorOutput(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock sync.Mutex
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		if isProbe(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		line := fmt.Sprintf("%s %s %s %v request={%s} response={%s}", start.UTC().Format(time.RFC3339Nano),
+			method, time.Since(start), status.Code(err), protoText(req), protoText(reply))
+		if err != nil {
+			line += " error=" + strconv.Quote(status.Convert(err).Message())
+		}
+
+		if out == nil {
+			logging.Infof("rpc: %s", line)
+		} else {
+			lock.Lock()
+			defer lock.Unlock()
+			if _, writeErr := fmt.Fprintln(out, logging.FilterString(line)); writeErr != nil {
+				logging.Warningf("could not log RPC: %v", writeErr)
+			}
+		}
+		return err
+	}, nil
+}
+
+// Property values are marked secret by wrapping them in an object with these keys and values. They mirror
+// resource.SigKey and resource.SecretSig, as the util packages do not depend on pkg/resource.
+const (
+	secretSigKey   = "4dabf18193072939515e22adb298388d"
+	secretSig      = "1b47061264138c4ac30d75fd1eb44270"
+	secretValueKey = "value"
+)
+
+// protoText renders an RPC's request or response as compact protobuf text, with the values of any secrets redacted.
+func protoText(msg interface{}) string {
+	m, ok := msg.(proto.Message)
+	if !ok || m == nil || reflect.ValueOf(m).IsNil() {
+		return ""
+	}
+	m = proto.Clone(m)
+	redactSecrets(reflect.ValueOf(m))
+	return proto.CompactTextString(m)
+}
+
+// redactSecrets replaces the values of any secrets within v, which is all or part of a protobuf message.
+func redactSecrets(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			redactSecrets(v.Elem())
+		}
+	case reflect.Struct:
+		if v.CanAddr() {
+			if s, ok := v.Addr().Interface().(*structpb.Struct); ok && isSecret(s) {
+				s.Fields[secretValueKey] = &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: "[secret]"}}
+				return
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				redactSecrets(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			redactSecrets(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			redactSecrets(v.MapIndex(key))
+		}
+	}
+}
+
+// isSecret returns true if the given object is a secret property value.
+func isSecret(s *structpb.Struct) bool {
+	sig, has := s.Fields[secretSigKey]
+	return has && sig.GetStringValue() == secretSig
+}
+
+func newMetricsInterceptor(path string) (grpc.UnaryClientInterceptor, error) {
+	out, err := openInterceptorOutput(path)
+	if err != nil {
+		return nil, err
+	}
+
+	type methodMetrics struct {
+		count, errors int
+		duration      time.Duration
+	}
+	var lock sync.Mutex
+	metrics := make(map[string]*methodMetrics)
+	clientInterceptorFlushes = append(clientInterceptorFlushes, func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		var methods []string
+		for method := range metrics {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			m := metrics[method]
+			line := fmt.Sprintf("%s calls=%d errors=%d duration=%s", method, m.count, m.errors, m.duration)
+			if out == nil {
+				logging.Infof("rpc metrics: %s", line)
+			} else if _, writeErr := fmt.Fprintln(out, line); writeErr != nil {
+				logging.Warningf("could not write RPC metrics: %v", writeErr)
+			}
+		}
+		metrics = make(map[string]*methodMetrics)
+	})
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		if isProbe(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		lock.Lock()
+		defer lock.Unlock()
+		m, has := metrics[method]
+		if !has {
+			m = &methodMetrics{}
+			metrics[method] = m
+		}
+		m.count++
+		m.duration += time.Since(start)
+		if err != nil {
+			m.errors++
+		}
+		return err
+	}, nil
+}
+
+func newHeaderInterceptor(arg string) (grpc.UnaryClientInterceptor, error) {
+	colon := strings.Index(arg, ":")
+	if colon <= 0 {
+		return nil, errors.New("expected a header of the form KEY:VALUE")
+	}
+	key, value := strings.ToLower(strings.TrimSpace(arg[:colon])), os.ExpandEnv(strings.TrimSpace(arg[colon+1:]))
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		return invoker(metadata.AppendToOutgoingContext(ctx, key, value), method, req, reply, cc, opts...)
+	}, nil
+}
+
+// chaosRand decides which RPCs the chaos interceptor fails; tests may replace it.
+var chaosRand = rand.New(rand.NewSource(time.Now().UnixNano())).Float64
+
+func newChaosInterceptor(arg string) (grpc.UnaryClientInterceptor, error) {
+	rate := 0.1
+	if arg != "" {
+		r, err := strconv.ParseFloat(arg, 64)
+		if err != nil || r < 0 || r > 1 {
+			return nil, errors.Errorf("expected a failure rate between 0 and 1, not '%s'", arg)
+		}
+		rate = r
+	}
+
+	var lock sync.Mutex
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		if !isProbe(method) {
+			lock.Lock()
+			fail := chaosRand() < rate
+			lock.Unlock()
+			if fail {
+				logging.V(5).Infof("rpc: chaos interceptor failing %s", method)
+				return status.Errorf(codes.Unavailable, "%s failed by the chaos RPC interceptor", method)
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcutil
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/logging"
+)
+
+const testMethod = "/pulumirpc.ResourceProvider/Create"
+
+// invoke runs an RPC through the given interceptor, with an invoker that records the RPC's outgoing metadata.
+func invoke(interceptor grpc.UnaryClientInterceptor, method string) (metadata.MD, error) {
+	var md metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		opts ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	err := interceptor(context.Background(), method, &pbempty.Empty{}, &pbempty.Empty{}, nil, invoker)
+	return md, err
+}
+
+func TestChainUnaryClientInterceptors(t *testing.T) {
+	var calls []string
+	record := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			calls = append(calls, name+" before")
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			calls = append(calls, name+" after")
+			return err
+		}
+	}
+
+	_, err := invoke(ChainUnaryClientInterceptors(record("a"), record("b")), testMethod)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a before", "b before", "b after", "a after"}, calls)
+}
+
+func TestConfigureClientInterceptors(t *testing.T) {
+	defer func() { contract.IgnoreError(ConfigureClientInterceptors("")) }()
+
+	assert.Error(t, ConfigureClientInterceptors("bogus"))
+	assert.Error(t, ConfigureClientInterceptors("chaos=2"))
+	assert.Error(t, ConfigureClientInterceptors("header=nocolon"))
+	assert.NoError(t, ConfigureClientInterceptors("header=x-team:infra, chaos=0"))
+	assert.Len(t, clientInterceptors, 2)
+
+	RegisterClientInterceptor("custom", func(arg string) (grpc.UnaryClientInterceptor, error) {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, "custom", arg), method, req, reply, cc, opts...)
+		}, nil
+	})
+	assert.NoError(t, ConfigureClientInterceptors("custom=value"))
+	md, err := invoke(ClientInterceptor(), testMethod)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"value"}, md["custom"])
+}
+
+func TestHeaderInterceptor(t *testing.T) {
+	assert.NoError(t, os.Setenv("PULUMI_TEST_RPC_TOKEN", "secret"))
+	defer func() { contract.IgnoreError(os.Unsetenv("PULUMI_TEST_RPC_TOKEN")) }()
+
+	interceptor, err := newHeaderInterceptor("Authorization: Bearer $PULUMI_TEST_RPC_TOKEN")
+	assert.NoError(t, err)
+	md, err := invoke(interceptor, testMethod)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bearer secret"}, md["authorization"])
+}
+
+func TestChaosInterceptor(t *testing.T) {
+	always, err := newChaosInterceptor("1")
+	assert.NoError(t, err)
+	_, err = invoke(always, testMethod)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// The probe that checks that a plugin is listening is never failed.
+	_, err = invoke(always, "")
+	assert.NoError(t, err)
+
+	never, err := newChaosInterceptor("0")
+	assert.NoError(t, err)
+	_, err = invoke(never, testMethod)
+	assert.NoError(t, err)
+}
+
+func TestLogAndMetricsInterceptors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-rpc-interceptors")
+	assert.NoError(t, err)
+	defer func() { contract.IgnoreError(os.RemoveAll(dir)) }()
+	defer func() { contract.IgnoreError(ConfigureClientInterceptors("")) }()
+
+	logFile, metricsFile := filepath.Join(dir, "rpc.log"), filepath.Join(dir, "rpc-metrics.log")
+	assert.NoError(t, ConfigureClientInterceptors("log="+logFile+",metrics="+metricsFile))
+	interceptor := ClientInterceptor()
+	for i := 0; i < 2; i++ {
+		_, err = invoke(interceptor, testMethod)
+		assert.NoError(t, err)
+	}
+	_, err = invoke(interceptor, "")
+	assert.NoError(t, err)
+	FlushClientInterceptors()
+
+	b, err := ioutil.ReadFile(logFile)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], testMethod)
+		assert.Contains(t, lines[0], "OK request={} response={}")
+	}
+
+	b, err = ioutil.ReadFile(metricsFile)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), testMethod+" calls=2 errors=0 duration="))
+}
+
+func TestLogInterceptorRedactsSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-rpc-interceptors")
+	assert.NoError(t, err)
+	defer func() { contract.IgnoreError(os.RemoveAll(dir)) }()
+
+	logging.AddGlobalFilter(logging.CreateFilter([]string{"hunter2"}, "[secret]"))
+
+	logFile := filepath.Join(dir, "rpc.log")
+	interceptor, err := newLogInterceptor(logFile)
+	assert.NoError(t, err)
+
+	str := func(s string) *structpb.Value {
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: s}}
+	}
+	secret := &structpb.Struct{Fields: map[string]*structpb.Value{
+		secretSigKey:   str(secretSig),
+		secretValueKey: str("s3cr3t"),
+	}}
+	req := &structpb.Struct{Fields: map[string]*structpb.Value{
+		"password": {Kind: &structpb.Value_StructValue{StructValue: secret}},
+		"config":   str("hunter2"),
+	}}
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		opts ...grpc.CallOption) error {
+		return nil
+	}
+	assert.NoError(t, interceptor(context.Background(), testMethod, req, &pbempty.Empty{}, nil, invoker))
+
+	b, err := ioutil.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), testMethod)
+	assert.Contains(t, string(b), "[secret]")
+	assert.NotContains(t, string(b), "s3cr3t")
+	assert.NotContains(t, string(b), "hunter2")
+
+	// The request itself must not have been modified.
+	assert.Equal(t, "s3cr3t", secret.Fields[secretValueKey].GetStringValue())
+}
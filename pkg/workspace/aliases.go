@@ -0,0 +1,78 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CommandAliasesFile is the name of the file in the bookkeeping directory that holds the user's command aliases.
+const CommandAliasesFile = "aliases.json"
+
+// CommandAliases maps the names of user-defined commands to the steps that each runs, in order. A step is either the
+// arguments of a pulumi command, such as "stack select prod", or, if it starts with "!", a shell command.
+type CommandAliases map[string][]string
+
+// Validate checks that a set of command aliases is well-formed.
+func (aliases CommandAliases) Validate() error {
+	for name, steps := range aliases {
+		if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\r\n") {
+			return errors.Errorf("'%s' is not a valid alias name", name)
+		}
+		if len(steps) == 0 {
+			return errors.Errorf("alias '%s' has no steps", name)
+		}
+		for _, step := range steps {
+			if strings.TrimSpace(strings.TrimPrefix(step, "!")) == "" {
+				return errors.Errorf("alias '%s' contains an empty step", name)
+			}
+		}
+	}
+	return nil
+}
+
+// GetCommandAliases returns the user's command aliases, which are stored in the bookkeeping directory. If there are
+// none, the result is empty.
+func GetCommandAliases() (CommandAliases, error) {
+	u, err := user.Current()
+	if u == nil || err != nil {
+		return nil, errors.Wrapf(err, "getting user home directory")
+	}
+	return loadCommandAliases(filepath.Join(u.HomeDir, BookkeepingDir, CommandAliasesFile))
+}
+
+func loadCommandAliases(path string) (CommandAliases, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return CommandAliases{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var aliases CommandAliases
+	if err = json.Unmarshal(b, &aliases); err != nil {
+		return nil, errors.Wrapf(err, "could not parse command aliases %s", path)
+	}
+	if err = aliases.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid command aliases %s", path)
+	}
+	return aliases, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestCommandAliasesValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: aliases
+runtime: nodejs
+aliases:
+  deploy:prod:
+    - stack select prod
+    - refresh --yes
+    - up --yes -m "Deploy to production"
+  lint:
+    - "!npm run lint"
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	assert.Equal(t, []string{"!npm run lint"}, proj.Aliases["lint"])
+
+	proj.Aliases["lint"] = []string{"!"}
+	assert.Error(t, proj.Validate())
+	proj.Aliases["lint"] = nil
+	assert.Error(t, proj.Validate())
+	delete(proj.Aliases, "lint")
+	proj.Aliases["deploy prod"] = []string{"up"}
+	assert.Error(t, proj.Validate())
+	delete(proj.Aliases, "deploy prod")
+	proj.Aliases["--up"] = []string{"up"}
+	assert.Error(t, proj.Validate())
+}
+
+func TestLoadCommandAliases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aliases")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, CommandAliasesFile)
+
+	// A missing file has no aliases.
+	aliases, err := loadCommandAliases(path)
+	assert.NoError(t, err)
+	assert.Empty(t, aliases)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"pv": ["preview --diff"]}`), 0600))
+	aliases, err = loadCommandAliases(path)
+	assert.NoError(t, err)
+	assert.Equal(t, CommandAliases{"pv": {"preview --diff"}}, aliases)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"pv": []}`), 0600))
+	_, err = loadCommandAliases(path)
+	assert.Error(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"pv": `), 0600))
+	_, err = loadCommandAliases(path)
+	assert.Error(t, err)
+}
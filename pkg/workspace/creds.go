@@ -20,6 +20,7 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -31,12 +32,28 @@ import (
 // credentials or tests interacting with one another
 const PulumiCredentialsPathEnvVar = "PULUMI_CREDENTIALS_PATH"
 
+// InsecureCredentialStorage may be set to true in order to store new access tokens in the credentials file, rather than
+// in the operating system's keychain. Access tokens are always stored in the file if there is no keychain available.
+var InsecureCredentialStorage bool
+
 // GetAccessToken returns an access token underneath a given key.
 func GetAccessToken(key string) (string, error) {
 	creds, err := GetStoredCredentials()
 	if err != nil && !os.IsNotExist(err) {
 		return "", err
 	}
+	if creds.KeychainTokens[key] {
+		k := osKeychain()
+		if k == nil {
+			return "", errors.Errorf("the access token for %s is stored in the OS keychain, which is unavailable", key)
+		}
+		token, err := k.Get(key)
+		if err != nil {
+			return "", errors.Wrapf(err, "reading the access token for %s from the OS keychain", key)
+		}
+		logging.AddGlobalFilter(logging.CreateFilter([]string{token}, "[credential]"))
+		return token, nil
+	}
 	if creds.AccessTokens == nil {
 		return "", nil
 	}
@@ -49,16 +66,70 @@ func DeleteAccessToken(key string) error {
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
+	deleteKeychainToken(&creds, key)
+	deleteSSOLogin(&creds, key)
 	if creds.AccessTokens != nil {
 		delete(creds.AccessTokens, key)
 	}
+	delete(creds.ReadOnly, key)
 	if creds.Current == key {
 		creds.Current = ""
 	}
 	return StoreCredentials(creds)
 }
 
-// StoreAccessToken saves the given access token underneath the given key.
+// DeleteAllAccessTokens deletes every stored access token, including those in the OS keychain.
+func DeleteAllAccessTokens() error {
+	creds, err := GetStoredCredentials()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for key := range creds.KeychainTokens {
+		deleteKeychainToken(&creds, key)
+	}
+	for key := range creds.SSOLogins {
+		deleteSSOLogin(&creds, key)
+	}
+	return StoreCredentials(Credentials{})
+}
+
+// deleteKeychainToken removes the access token for the given key from the OS keychain, if it is stored there.
+func deleteKeychainToken(creds *Credentials, key string) {
+	if !creds.KeychainTokens[key] {
+		return
+	}
+	if k := osKeychain(); k != nil {
+		if err := k.Delete(key); err != nil {
+			logging.V(3).Infof("error deleting the access token for %s from the OS keychain: %v", key, err)
+		}
+	}
+	delete(creds.KeychainTokens, key)
+}
+
+// ssoRefreshTokenAccount returns the OS keychain account under which the refresh token for the given key is stored.
+func ssoRefreshTokenAccount(key string) string {
+	return key + "#refresh"
+}
+
+// deleteSSOLogin forgets the single sign-on login for the given key, if any, including its refresh token.
+func deleteSSOLogin(creds *Credentials, key string) {
+	login, has := creds.SSOLogins[key]
+	if !has {
+		return
+	}
+	if login.KeychainRefreshToken {
+		if k := osKeychain(); k != nil {
+			if err := k.Delete(ssoRefreshTokenAccount(key)); err != nil {
+				logging.V(3).Infof("error deleting the refresh token for %s from the OS keychain: %v", key, err)
+			}
+		}
+	}
+	delete(creds.SSOLogins, key)
+}
+
+// StoreAccessToken saves the given access token underneath the given key. New access tokens are saved in the OS
+// keychain if possible, and otherwise (or if InsecureCredentialStorage is set) in the credentials file; storing an
+// access token again leaves it where it is.
 func StoreAccessToken(key string, token string, current bool) error {
 	creds, err := GetStoredCredentials()
 	if err != nil && !os.IsNotExist(err) {
@@ -67,18 +138,147 @@ func StoreAccessToken(key string, token string, current bool) error {
 	if creds.AccessTokens == nil {
 		creds.AccessTokens = make(map[string]string)
 	}
-	creds.AccessTokens[key] = token
+
+	existing, has := creds.AccessTokens[key]
+	if creds.KeychainTokens[key] {
+		if existing, err = GetAccessToken(key); err != nil {
+			existing = ""
+		}
+	}
+	if has && token != existing {
+		// The new token did not come from the identity provider that issued the old one, if any.
+		deleteSSOLogin(&creds, key)
+	}
+	if !has || token != existing || InsecureCredentialStorage {
+		deleteKeychainToken(&creds, key)
+		creds.AccessTokens[key] = token
+		if token != "" && !InsecureCredentialStorage {
+			if k := osKeychain(); k != nil {
+				if err = k.Set(key, token); err == nil {
+					if creds.KeychainTokens == nil {
+						creds.KeychainTokens = make(map[string]bool)
+					}
+					creds.KeychainTokens[key] = true
+					creds.AccessTokens[key] = ""
+				} else {
+					logging.V(3).Infof("error storing the access token for %s in the OS keychain: %v", key, err)
+				}
+			}
+		}
+	}
+
 	if current {
 		creds.Current = key
 	}
 	return StoreCredentials(creds)
 }
 
+// SSOLogin records that the access token stored underneath a key was issued by an OpenID Connect identity provider
+// through single sign-on, and how to refresh it before it expires.
+type SSOLogin struct {
+	Issuer   string    `json:"issuer"`   // the URL of the identity provider.
+	ClientID string    `json:"clientId"` // the OAuth client ID with which the CLI identifies itself to the provider.
+	TokenURL string    `json:"tokenUrl"` // the URL of the provider's token endpoint.
+	Expiry   time.Time `json:"expiry"`   // the time at which the access token expires; zero if it does not.
+	// RefreshToken is the token with which a new access token may be obtained, if the provider issued one and it is not
+	// stored in the OS keychain.
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// KeychainRefreshToken is true if the refresh token is stored in the OS keychain, rather than in RefreshToken.
+	KeychainRefreshToken bool `json:"keychainRefreshToken,omitempty"`
+}
+
+// GetSSOLogin returns the single sign-on login through which the access token underneath the given key was obtained,
+// with its refresh token filled in, or nil if the access token was not obtained through single sign-on.
+func GetSSOLogin(key string) (*SSOLogin, error) {
+	creds, err := GetStoredCredentials()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	login, has := creds.SSOLogins[key]
+	if !has {
+		return nil, nil
+	}
+	if login.KeychainRefreshToken {
+		k := osKeychain()
+		if k == nil {
+			return nil, errors.Errorf(
+				"the refresh token for %s is stored in the OS keychain, which is unavailable", key)
+		}
+		if login.RefreshToken, err = k.Get(ssoRefreshTokenAccount(key)); err != nil {
+			return nil, errors.Wrapf(err, "reading the refresh token for %s from the OS keychain", key)
+		}
+		logging.AddGlobalFilter(logging.CreateFilter([]string{login.RefreshToken}, "[credential]"))
+		login.KeychainRefreshToken = false
+	}
+	return &login, nil
+}
+
+// StoreSSOLogin saves the given access token underneath the given key, as StoreAccessToken does, along with the single
+// sign-on login through which it was obtained. Like access tokens, refresh tokens are saved in the OS keychain if
+// possible, and otherwise (or if InsecureCredentialStorage is set) in the credentials file.
+func StoreSSOLogin(key string, token string, login SSOLogin, current bool) error {
+	if err := StoreAccessToken(key, token, current); err != nil {
+		return err
+	}
+
+	creds, err := GetStoredCredentials()
+	if err != nil {
+		return err
+	}
+	deleteSSOLogin(&creds, key)
+	login.KeychainRefreshToken = false
+	if login.RefreshToken != "" && !InsecureCredentialStorage {
+		if k := osKeychain(); k != nil {
+			if err = k.Set(ssoRefreshTokenAccount(key), login.RefreshToken); err == nil {
+				login.RefreshToken, login.KeychainRefreshToken = "", true
+			} else {
+				logging.V(3).Infof("error storing the refresh token for %s in the OS keychain: %v", key, err)
+			}
+		}
+	}
+	if creds.SSOLogins == nil {
+		creds.SSOLogins = make(map[string]SSOLogin)
+	}
+	creds.SSOLogins[key] = login
+	return StoreCredentials(creds)
+}
+
+// IsReadOnlyBackend returns true if the backend whose access token is stored underneath the given key was logged into
+// in read-only mode.
+func IsReadOnlyBackend(key string) bool {
+	creds, err := GetStoredCredentials()
+	return err == nil && creds.ReadOnly[key]
+}
+
+// SetBackendReadOnly records whether the backend whose access token is stored underneath the given key was logged into
+// in read-only mode.
+func SetBackendReadOnly(key string, readOnly bool) error {
+	creds, err := GetStoredCredentials()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if readOnly {
+		if creds.ReadOnly == nil {
+			creds.ReadOnly = make(map[string]bool)
+		}
+		creds.ReadOnly[key] = true
+	} else {
+		delete(creds.ReadOnly, key)
+	}
+	return StoreCredentials(creds)
+}
+
 // Credentials hold the information necessary for authenticating Pulumi Cloud API requests.  It contains
 // a map from the cloud API URL to the associated access token.
 type Credentials struct {
 	Current      string            `json:"current,omitempty"`      // the currently selected key.
 	AccessTokens map[string]string `json:"accessTokens,omitempty"` // a map of arbitrary key strings to tokens.
+	// KeychainTokens is the set of keys whose tokens are stored in the OS keychain, rather than in AccessTokens.
+	KeychainTokens map[string]bool `json:"keychainTokens,omitempty"`
+	// ReadOnly is the set of keys whose backends were logged into in read-only mode.
+	ReadOnly map[string]bool `json:"readOnly,omitempty"`
+	// SSOLogins holds, for each key whose token was obtained through single sign-on, how to refresh the token.
+	SSOLogins map[string]SSOLogin `json:"ssoLogins,omitempty"`
 }
 
 // getCredsFilePath returns the path to the Pulumi credentials file on disk, regardless of
@@ -138,6 +338,11 @@ func GetStoredCredentials() (Credentials, error) {
 	for _, v := range creds.AccessTokens {
 		secrets = append(secrets, v)
 	}
+	for _, login := range creds.SSOLogins {
+		if login.RefreshToken != "" {
+			secrets = append(secrets, login.RefreshToken)
+		}
+	}
 
 	logging.AddGlobalFilter(logging.CreateFilter(secrets, "[credential]"))
 
@@ -164,5 +369,9 @@ func StoreCredentials(creds Credentials) error {
 	if err != nil {
 		return errors.Wrapf(err, "marshalling credentials object")
 	}
-	return ioutil.WriteFile(credsFile, raw, 0600)
+	if err = ioutil.WriteFile(credsFile, raw, 0600); err != nil {
+		return err
+	}
+	// WriteFile leaves the mode of an existing file alone, so make sure that older files are not readable by others.
+	return os.Chmod(credsFile, 0600)
 }
@@ -0,0 +1,143 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKeychain is an in-memory keychain.
+type fakeKeychain map[string]string
+
+func (k fakeKeychain) Get(account string) (string, error) {
+	secret, has := k[account]
+	if !has {
+		return "", errors.New("not found")
+	}
+	return secret, nil
+}
+
+func (k fakeKeychain) Set(account, secret string) error {
+	k[account] = secret
+	return nil
+}
+
+func (k fakeKeychain) Delete(account string) error {
+	delete(k, account)
+	return nil
+}
+
+func TestAccessTokenStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-creds-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	os.Setenv(PulumiCredentialsPathEnvVar, dir)
+	defer os.Unsetenv(PulumiCredentialsPathEnvVar)
+
+	chain := fakeKeychain{}
+	defer func(old func() keychain) { osKeychain = old }(osKeychain)
+	osKeychain = func() keychain { return chain }
+
+	// Tokens are stored in the keychain when there is one, and not in the credentials file.
+	assert.NoError(t, StoreAccessToken("https://a", "token-a", true))
+	assert.Equal(t, fakeKeychain{"https://a": "token-a"}, chain)
+	creds, err := GetStoredCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://a", creds.Current)
+	assert.Equal(t, "", creds.AccessTokens["https://a"])
+	assert.True(t, creds.KeychainTokens["https://a"])
+	token, err := GetAccessToken("https://a")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-a", token)
+
+	// With insecure storage, tokens are stored in the credentials file, even if they were in the keychain before.
+	InsecureCredentialStorage = true
+	assert.NoError(t, StoreAccessToken("https://a", "token-a", false))
+	assert.NoError(t, StoreAccessToken("https://b", "token-b", false))
+	InsecureCredentialStorage = false
+	assert.Empty(t, chain)
+	creds, err = GetStoredCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"https://a": "token-a", "https://b": "token-b"}, creds.AccessTokens)
+	assert.Empty(t, creds.KeychainTokens)
+
+	// Storing an unchanged token leaves it where it is, while a new token moves into the keychain.
+	assert.NoError(t, StoreAccessToken("https://a", "token-a", false))
+	assert.NoError(t, StoreAccessToken("https://b", "token-b2", false))
+	assert.Equal(t, fakeKeychain{"https://b": "token-b2"}, chain)
+	token, err = GetAccessToken("https://b")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-b2", token)
+
+	// Without a keychain, tokens fall back to the credentials file.
+	osKeychain = func() keychain { return nil }
+	assert.NoError(t, StoreAccessToken("https://c", "token-c", false))
+	token, err = GetAccessToken("https://c")
+	assert.NoError(t, err)
+	assert.Equal(t, "token-c", token)
+	_, err = GetAccessToken("https://b")
+	assert.Error(t, err)
+	osKeychain = func() keychain { return chain }
+
+	// Empty tokens, as used by backends that need none, are stored in the credentials file.
+	assert.NoError(t, StoreAccessToken("file://~", "", false))
+	creds, err = GetStoredCredentials()
+	assert.NoError(t, err)
+	token, has := creds.AccessTokens["file://~"]
+	assert.True(t, has)
+	assert.Equal(t, "", token)
+	assert.False(t, creds.KeychainTokens["file://~"])
+
+	// Backends can be marked read-only, until their tokens are deleted.
+	assert.False(t, IsReadOnlyBackend("https://b"))
+	assert.NoError(t, SetBackendReadOnly("https://b", true))
+	assert.True(t, IsReadOnlyBackend("https://b"))
+	assert.False(t, IsReadOnlyBackend("https://a"))
+
+	// Deleting tokens removes them from the keychain.
+	assert.NoError(t, DeleteAccessToken("https://b"))
+	assert.Empty(t, chain)
+	assert.False(t, IsReadOnlyBackend("https://b"))
+	assert.NoError(t, StoreAccessToken("https://d", "token-d", false))
+	assert.NoError(t, DeleteAllAccessTokens())
+	assert.Empty(t, chain)
+	_, err = os.Stat(filepath.Join(dir, "credentials.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	// The refresh tokens of single sign-on logins are stored in the keychain too, and forgotten along with the login.
+	login := SSOLogin{Issuer: "https://idp", ClientID: "cli", TokenURL: "https://idp/token", RefreshToken: "refresh-e"}
+	assert.NoError(t, StoreSSOLogin("https://e", "token-e", login, true))
+	assert.Equal(t, fakeKeychain{"https://e": "token-e", "https://e#refresh": "refresh-e"}, chain)
+	creds, err = GetStoredCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "", creds.SSOLogins["https://e"].RefreshToken)
+	stored, err := GetSSOLogin("https://e")
+	assert.NoError(t, err)
+	assert.Equal(t, login, *stored)
+	assert.NoError(t, StoreAccessToken("https://e", "token-e2", false))
+	assert.Equal(t, fakeKeychain{"https://e": "token-e2"}, chain)
+	stored, err = GetSSOLogin("https://e")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	assert.NoError(t, StoreSSOLogin("https://e", "token-e", login, true))
+	assert.NoError(t, DeleteAccessToken("https://e"))
+	assert.Empty(t, chain)
+}
@@ -0,0 +1,124 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// keychain stores secrets in the operating system's credential store, keyed by account name.
+type keychain interface {
+	// Get returns the secret stored for the given account.
+	Get(account string) (string, error)
+	// Set stores the secret for the given account, replacing any secret that is already stored for it.
+	Set(account, secret string) error
+	// Delete removes the secret stored for the given account.
+	Delete(account string) error
+}
+
+// osKeychain returns the keychain of the current operating system, or nil if there is none that we know how to use.
+// Access tokens are stored in the macOS keychain with the `security` tool, and in the Secret Service (e.g. GNOME
+// Keyring or KWallet) on Linux with the `secret-tool` tool.
+var osKeychain = func() keychain {
+	service := "pulumi"
+	if dir := os.Getenv(PulumiCredentialsPathEnvVar); dir != "" {
+		// Keep the secrets of credentials files that have been moved elsewhere, as they are in tests, apart.
+		service += ":" + dir
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return macKeychain{service: service}
+		}
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return secretServiceKeychain{service: service}
+		}
+	}
+	return nil
+}
+
+// runKeychainTool runs the given command with the given standard input, returning its standard output.
+func runKeychainTool(stdin string, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", errors.Wrapf(err, "%s %s: %s", name, args[0], message)
+		}
+		return "", errors.Wrapf(err, "%s %s", name, args[0])
+	}
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
+
+// macKeychain stores secrets as generic passwords in the user's default macOS keychain.
+type macKeychain struct {
+	service string
+}
+
+func (k macKeychain) Get(account string) (string, error) {
+	return runKeychainTool("", "security", "find-generic-password", "-s", k.service, "-a", account, "-w")
+}
+
+func (k macKeychain) Set(account, secret string) error {
+	// The secret is passed to an interactive session on standard input, rather than as an argument, so that it cannot
+	// be seen in the list of running processes.
+	for _, s := range []string{k.service, account, secret} {
+		if strings.ContainsAny(s, "\"\\\n") {
+			return errors.New("cannot store values with quotes, backslashes, or newlines in the macOS keychain")
+		}
+	}
+	command := `add-generic-password -U -s "` + k.service + `" -a "` + account + `" -w "` + secret + "\"\n"
+	_, err := runKeychainTool(command, "security", "-i")
+	return err
+}
+
+func (k macKeychain) Delete(account string) error {
+	_, err := runKeychainTool("", "security", "delete-generic-password", "-s", k.service, "-a", account)
+	return err
+}
+
+// secretServiceKeychain stores secrets in the freedesktop.org Secret Service, as provided by GNOME Keyring or KWallet.
+type secretServiceKeychain struct {
+	service string
+}
+
+func (k secretServiceKeychain) Get(account string) (string, error) {
+	secret, err := runKeychainTool("", "secret-tool", "lookup", "service", k.service, "account", account)
+	if err == nil && secret == "" {
+		err = errors.New("secret-tool lookup: no secret found")
+	}
+	return secret, err
+}
+
+func (k secretServiceKeychain) Set(account, secret string) error {
+	_, err := runKeychainTool(secret, "secret-tool", "store", "--label=Pulumi access token for "+account,
+		"service", k.service, "account", account)
+	return err
+}
+
+func (k secretServiceKeychain) Delete(account string) error {
+	_, err := runKeychainTool("", "secret-tool", "clear", "service", k.service, "account", account)
+	return err
+}
@@ -39,6 +39,8 @@ const (
 	GitDir = ".git"
 	// HistoryDir is the name of the directory that holds historical information for projects.
 	HistoryDir = "history"
+	// LockDir is the name of the directory that holds the locks of stacks that are being updated.
+	LockDir = "locks"
 	// PluginDir is the name of the directory containing plugins.
 	PluginDir = "plugins"
 	// StackDir is the name of the directory that holds stack information for projects.
@@ -50,6 +52,8 @@ const (
 
 	// ProjectFile is the base name of a project file.
 	ProjectFile = "Pulumi"
+	// PluginLockFile is the name of the file, next to a project file, that pins the versions of the project's plugins.
+	PluginLockFile = "Pulumi.lock.yaml"
 	// RepoFile is the name of the file that holds information specific to the entire repository.
 	RepoFile = "settings.json"
 	// WorkspaceFile is the name of the file that holds workspace information.
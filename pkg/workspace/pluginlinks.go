@@ -0,0 +1,170 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// PluginLinksFile is the name of the file in the plugin directory that records linked plugins.
+const PluginLinksFile = "links.json"
+
+// PluginLink points a plugin at a local build, which is then used in place of any installed version of the plugin.
+// This lets plugin authors test their changes without installing every build into the plugin cache.
+type PluginLink struct {
+	Kind PluginKind `json:"kind"`
+	Name string     `json:"name"`
+	// Path is the absolute path of the plugin's executable, or of a directory containing it.
+	Path string `json:"path"`
+}
+
+// Executable returns the path of the linked plugin's executable.
+func (link PluginLink) Executable() string {
+	stat, err := os.Stat(link.Path)
+	if err != nil || !stat.IsDir() {
+		return link.Path
+	}
+	filename := (&PluginInfo{Kind: link.Kind, Name: link.Name}).FilePrefix()
+	for _, ext := range getCandidateExtensions() {
+		candidate := filepath.Join(link.Path, filename+ext)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return filepath.Join(link.Path, filename)
+}
+
+// GetPluginLinks returns the linked plugins, sorted by kind and name.
+func GetPluginLinks() ([]PluginLink, error) {
+	dir, err := GetPluginDir()
+	if err != nil {
+		return nil, err
+	}
+	return loadPluginLinks(dir)
+}
+
+// LinkPlugin links the given plugin to the executable at the given path, or to the plugin's executable in the
+// directory at the given path, replacing any existing link for the plugin.
+func LinkPlugin(kind PluginKind, name, path string) (PluginLink, error) {
+	dir, err := GetPluginDir()
+	if err != nil {
+		return PluginLink{}, err
+	}
+	return linkPlugin(dir, kind, name, path)
+}
+
+// UnlinkPlugin removes the link for the given plugin, returning false if the plugin was not linked.
+func UnlinkPlugin(kind PluginKind, name string) (bool, error) {
+	dir, err := GetPluginDir()
+	if err != nil {
+		return false, err
+	}
+	return unlinkPlugin(dir, kind, name)
+}
+
+// getPluginLink returns the link for the given plugin, if there is one.
+func getPluginLink(kind PluginKind, name string) (PluginLink, bool) {
+	links, err := GetPluginLinks()
+	if err != nil {
+		return PluginLink{}, false
+	}
+	for _, link := range links {
+		if link.Kind == kind && link.Name == name {
+			return link, true
+		}
+	}
+	return PluginLink{}, false
+}
+
+func linkPlugin(dir string, kind PluginKind, name, path string) (PluginLink, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return PluginLink{}, err
+	}
+	link := PluginLink{Kind: kind, Name: name, Path: abs}
+	if _, err = os.Stat(link.Executable()); err != nil {
+		return PluginLink{}, errors.Wrapf(err, "linking %s plugin %s", kind, name)
+	}
+
+	links, err := loadPluginLinks(dir)
+	if err != nil {
+		return PluginLink{}, err
+	}
+	var replaced bool
+	for i, l := range links {
+		if l.Kind == kind && l.Name == name {
+			links[i], replaced = link, true
+		}
+	}
+	if !replaced {
+		links = append(links, link)
+	}
+	return link, savePluginLinks(dir, links)
+}
+
+func unlinkPlugin(dir string, kind PluginKind, name string) (bool, error) {
+	links, err := loadPluginLinks(dir)
+	if err != nil {
+		return false, err
+	}
+	var kept []PluginLink
+	for _, l := range links {
+		if l.Kind != kind || l.Name != name {
+			kept = append(kept, l)
+		}
+	}
+	if len(kept) == len(links) {
+		return false, nil
+	}
+	return true, savePluginLinks(dir, kept)
+}
+
+func loadPluginLinks(dir string) ([]PluginLink, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, PluginLinksFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var links []PluginLink
+	if err = json.Unmarshal(b, &links); err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", PluginLinksFile)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		li, lj := links[i], links[j]
+		return li.Kind < lj.Kind || (li.Kind == lj.Kind && li.Name < lj.Name)
+	})
+	return links, nil
+}
+
+func savePluginLinks(dir string, links []PluginLink) error {
+	if links == nil {
+		links = []PluginLink{}
+	}
+	b, err := json.MarshalIndent(links, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, PluginLinksFile), b, 0600)
+}
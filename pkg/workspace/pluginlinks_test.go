@@ -0,0 +1,63 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+func TestPluginLinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-plugin-links")
+	assert.NoError(t, err)
+	defer func() { contract.IgnoreError(os.RemoveAll(dir)) }()
+
+	pluginDir, buildDir := filepath.Join(dir, "plugins"), filepath.Join(dir, "bin")
+	assert.NoError(t, os.MkdirAll(buildDir, 0700))
+	exe := filepath.Join(buildDir, "pulumi-resource-aws"+getCandidateExtensions()[0])
+	assert.NoError(t, ioutil.WriteFile(exe, nil, 0700))
+
+	// A plugin can't be linked to a build that doesn't exist.
+	_, err = linkPlugin(pluginDir, ResourcePlugin, "aws", filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+
+	// A plugin may be linked to its executable, or to the directory containing it.
+	link, err := linkPlugin(pluginDir, ResourcePlugin, "aws", exe)
+	assert.NoError(t, err)
+	assert.Equal(t, exe, link.Executable())
+	link, err = linkPlugin(pluginDir, ResourcePlugin, "aws", buildDir)
+	assert.NoError(t, err)
+	assert.Equal(t, exe, link.Executable())
+
+	links, err := loadPluginLinks(pluginDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []PluginLink{{Kind: ResourcePlugin, Name: "aws", Path: buildDir}}, links)
+
+	unlinked, err := unlinkPlugin(pluginDir, ResourcePlugin, "aws")
+	assert.NoError(t, err)
+	assert.True(t, unlinked)
+	unlinked, err = unlinkPlugin(pluginDir, ResourcePlugin, "aws")
+	assert.NoError(t, err)
+	assert.False(t, unlinked)
+	links, err = loadPluginLinks(pluginDir)
+	assert.NoError(t, err)
+	assert.Empty(t, links)
+}
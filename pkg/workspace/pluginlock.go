@@ -0,0 +1,108 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/encoding"
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// PluginLock pins the exact versions of the resource plugins that a project uses, so that every machine that deploys
+// the same commit of the project uses the same providers. It is stored in the PluginLockFile next to the project file,
+// and is written by `pulumi plugin lock`.
+type PluginLock struct {
+	// Plugins lists the pinned plugins.
+	Plugins []ProjectPlugin `json:"plugins" yaml:"plugins"`
+}
+
+// Validate checks that the lock is well-formed.
+func (lock *PluginLock) Validate() error {
+	if err := validatePlugins(lock.Plugins); err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, p := range lock.Plugins {
+		key := string(p.Kind) + "/" + p.Name
+		if seen[key] {
+			return errors.Errorf("the %s plugin %s is pinned more than once", p.Kind, p.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Version returns the version at which the given plugin is pinned, or nil if it is not pinned.
+func (lock *PluginLock) Version(kind PluginKind, name string) *semver.Version {
+	for _, p := range lock.Plugins {
+		if p.Kind == kind && p.Name == name {
+			version, err := semver.ParseTolerant(p.Version)
+			contract.AssertNoErrorf(err, "plugin versions are validated when the lock is loaded")
+			return &version
+		}
+	}
+	return nil
+}
+
+// Pin pins the given plugin at the given version, replacing any existing pin.
+func (lock *PluginLock) Pin(kind PluginKind, name string, version semver.Version) {
+	for i, p := range lock.Plugins {
+		if p.Kind == kind && p.Name == name {
+			lock.Plugins[i].Version = version.String()
+			return
+		}
+	}
+	lock.Plugins = append(lock.Plugins, ProjectPlugin{Kind: kind, Name: name, Version: version.String()})
+	sort.Slice(lock.Plugins, func(i, j int) bool {
+		pi, pj := lock.Plugins[i], lock.Plugins[j]
+		return pi.Kind < pj.Kind || (pi.Kind == pj.Kind && pi.Name < pj.Name)
+	})
+}
+
+// LoadPluginLock loads the plugin lock of the project in the given directory, returning nil if it has none.
+func LoadPluginLock(root string) (*PluginLock, error) {
+	path := filepath.Join(root, PluginLockFile)
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var lock PluginLock
+	if err = encoding.YAML.Unmarshal(b, &lock); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	if err = lock.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	return &lock, nil
+}
+
+// Save writes the plugin lock for the project in the given directory.
+func (lock *PluginLock) Save(root string) error {
+	b, err := encoding.YAML.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(root, PluginLockFile), b, 0644)
+}
@@ -0,0 +1,68 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-plugin-lock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Projects without a lock file have no lock.
+	lock, err := LoadPluginLock(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+
+	// Pins are kept sorted, and replace earlier pins of the same plugin.
+	lock = &PluginLock{}
+	lock.Pin(ResourcePlugin, "kubernetes", semver.MustParse("0.21.0"))
+	lock.Pin(ResourcePlugin, "aws", semver.MustParse("0.17.0"))
+	lock.Pin(ResourcePlugin, "aws", semver.MustParse("0.17.1"))
+	assert.NoError(t, lock.Save(dir))
+
+	lock, err = LoadPluginLock(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []ProjectPlugin{
+		{Kind: ResourcePlugin, Name: "aws", Version: "0.17.1"},
+		{Kind: ResourcePlugin, Name: "kubernetes", Version: "0.21.0"},
+	}, lock.Plugins)
+	assert.Equal(t, "0.17.1", lock.Version(ResourcePlugin, "aws").String())
+	assert.Nil(t, lock.Version(ResourcePlugin, "gcp"))
+	assert.Nil(t, lock.Version(LanguagePlugin, "aws"))
+
+	// Malformed locks are rejected.
+	err = ioutil.WriteFile(filepath.Join(dir, PluginLockFile), []byte(`
+plugins:
+  - kind: resource
+    name: aws
+    version: 0.17.1
+  - kind: resource
+    name: aws
+    version: 0.17.2
+`), 0600)
+	assert.NoError(t, err)
+	_, err = LoadPluginLock(dir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "pinned more than once")
+}
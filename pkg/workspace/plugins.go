@@ -17,9 +17,14 @@ package workspace
 import (
 	"archive/tar"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/user"
@@ -27,18 +32,36 @@ import (
 	"regexp"
 	"runtime"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/blang/semver"
 	"github.com/djherbis/times"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
 
 	"github.com/pulumi/pulumi/pkg/util/contract"
+	"github.com/pulumi/pulumi/pkg/util/errutil"
+	"github.com/pulumi/pulumi/pkg/util/httputil"
 	"github.com/pulumi/pulumi/pkg/util/logging"
 )
 
 const (
 	windowsGOOS = "windows"
+
+	// DefaultPluginDownloadURL is the base URL of the server from which plugins are downloaded by backends that do
+	// not download plugins themselves.
+	DefaultPluginDownloadURL = "https://get.pulumi.com/releases/plugins"
+	// PluginDownloadURLEnvVar may be set to the URL of a mirror from which to download all plugins instead, such as an
+	// internal artifact server or a file share. The URL is either the base URL of a copy of the default server, or a
+	// template of the URL of each tarball in which ${kind}, ${name}, ${version}, ${os}, and ${arch} are replaced by
+	// the plugin's kind, name, and version and the current platform. Both http(s):// and file:// URLs are supported.
+	//
+	// Because a mirror is not the canonical source of plugins, each tarball downloaded from one must be accompanied by
+	// a file at the same URL plus ".sha256" holding the hex-encoded SHA-256 hash of the tarball (as written by e.g.
+	// `sha256sum`), which the tarball is verified against before it is installed. Tarballs from other sources are
+	// verified against their checksums if they are published.
+	PluginDownloadURLEnvVar = "PULUMI_PLUGIN_DOWNLOAD_URL"
 )
 
 var (
@@ -69,6 +92,18 @@ func (err *MissingError) Error() string {
 		err.Info.Kind, err.Info.String())
 }
 
+func (err *MissingError) Code() errutil.ErrorCode {
+	return errutil.ErrPluginNotFound
+}
+
+func (err *MissingError) Hint() string {
+	if err.Info.Version != nil {
+		return "" // the message already says how to install the plugin.
+	}
+	return fmt.Sprintf("run `pulumi plugin install %s %s <version>` to install the plugin",
+		err.Info.Kind, err.Info.Name)
+}
+
 // PluginInfo provides basic information about a plugin.  Each plugin gets installed into a system-wide
 // location, by default `~/.pulumi/plugins/<kind>-<name>-<version>/`.  A plugin may contain multiple files,
 // however the primary loadable executable must be named `pulumi-<kind>-<name>`.
@@ -159,12 +194,68 @@ func (info *PluginInfo) SetFileMetadata(path string) error {
 		info.InstallTime = tinfo.BirthTime()
 	}
 
-	info.LastUsedTime = tinfo.AccessTime()
+	// Prefer the time at which the plugin was last loaded, if it has been recorded, since merely listing a plugin's
+	// directory (as computing its size does) also updates the directory's access time.
+	if used, err := os.Stat(filepath.Join(path, pluginLastUsedFile)); err == nil {
+		info.LastUsedTime = used.ModTime()
+	} else {
+		info.LastUsedTime = tinfo.AccessTime()
+	}
 	return nil
 }
 
-// Install installs a plugin's tarball into the cache.  It validates that plugin names are in the expected format.
+// pluginLastUsedFile is the name of the file, within a plugin's directory, whose modification time records the last
+// time that the plugin was loaded.
+const pluginLastUsedFile = ".pulumi-last-used"
+
+// MarkUsed records that the plugin was used just now, so that the plugin cache can be pruned of unused plugins.
+func (info PluginInfo) MarkUsed() error {
+	dir, err := info.DirPath()
+	if err != nil {
+		return err
+	}
+	path, now := filepath.Join(dir, pluginLastUsedFile), time.Now()
+	if err = os.Chtimes(path, now, now); os.IsNotExist(err) {
+		return ioutil.WriteFile(path, nil, 0600)
+	}
+	return err
+}
+
+// Install installs a plugin's tarball into the cache.  It validates that plugin names are in the expected format.  If
+// the tarball was returned by Download or VerifyDownload, and its signature was verified, a copy of the tarball and
+// its signature are kept in the plugin's directory, so that the plugin may be verified each time it is executed, as
+// described by PluginTrustPolicy.VerifyExecutable.
 func (info PluginInfo) Install(tarball io.ReadCloser) error {
+	var signature []byte
+	if verified, ok := tarball.(*tempFile); ok {
+		signature = verified.signature
+	}
+	return info.install(tarball, signature)
+}
+
+// InstallFile installs a plugin from the tarball at the given path.  If the tarball has a signature, in the file at its
+// path plus PluginSignatureSuffix, the signature is kept along with a copy of the tarball, as described by Install.
+func (info PluginInfo) InstallFile(path string) error {
+	var signature []byte
+	if file, err := os.Open(path + PluginSignatureSuffix); err == nil {
+		signature, err = readPluginSignature(file)
+		contract.IgnoreClose(file)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", path+PluginSignatureSuffix)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tarball, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return info.install(tarball, signature)
+}
+
+// install installs a plugin's tarball, which has the given signature, if any, into the cache.
+func (info PluginInfo) install(tarball io.ReadCloser, signature []byte) error {
 	// Fetch the directory into which we will expand this tarball, and create it.
 	finalDir, err := info.DirPath()
 	if err != nil {
@@ -191,7 +282,20 @@ func (info PluginInfo) Install(tarball io.ReadCloser) error {
 	// before we later try to rename the directory. Otherwise, the open file handles cause issues on Windows.
 	err = (func() error {
 		defer contract.IgnoreClose(tarball)
-		gzr, err := gzip.NewReader(tarball)
+
+		// If the tarball is signed, keep a copy of it as it is expanded.
+		var source io.Reader = tarball
+		if signature != nil {
+			savedPath := filepath.Join(tempDir, pluginTarballFile)
+			saved, err := os.OpenFile(savedPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				return errors.Wrap(err, "copying signed tarball")
+			}
+			defer contract.IgnoreClose(saved)
+			source = io.TeeReader(tarball, saved)
+		}
+
+		gzr, err := gzip.NewReader(source)
 		if err != nil {
 			return errors.Wrapf(err, "unzipping")
 		}
@@ -229,6 +333,18 @@ func (info PluginInfo) Install(tarball io.ReadCloser) error {
 			}
 		}
 
+		if signature != nil {
+			// Copy any data that follows the archive, so that the copy's signature still matches.
+			if _, err = io.Copy(ioutil.Discard, source); err != nil {
+				return errors.Wrap(err, "copying signed tarball")
+			}
+			sigPath := filepath.Join(tempDir, pluginTarballFile+PluginSignatureSuffix)
+			encoded := base64.StdEncoding.EncodeToString(signature) + "\n"
+			if err = ioutil.WriteFile(sigPath, []byte(encoded), 0600); err != nil {
+				return errors.Wrap(err, "writing tarball signature")
+			}
+		}
+
 		return nil
 	})()
 	if err != nil {
@@ -245,6 +361,273 @@ func (info PluginInfo) Install(tarball io.ReadCloser) error {
 	return nil
 }
 
+// PluginPlatform returns the OS and architecture for which plugins are downloaded.
+func PluginPlatform() (string, string, error) {
+	var os string
+	switch runtime.GOOS {
+	case "darwin", "linux", "windows":
+		os = runtime.GOOS
+	default:
+		return "", "", errors.Errorf("unsupported plugin OS: %s", runtime.GOOS)
+	}
+	var arch string
+	switch runtime.GOARCH {
+	case "amd64":
+		arch = runtime.GOARCH
+	default:
+		return "", "", errors.Errorf("unsupported plugin architecture: %s", runtime.GOARCH)
+	}
+	return os, arch, nil
+}
+
+// DownloadURL returns the URL from which the plugin's tarball for the current platform is downloaded.
+func (info PluginInfo) DownloadURL() (string, error) {
+	if info.Version == nil {
+		return "", errors.Errorf("cannot download the %s plugin %s without a version", info.Kind, info.Name)
+	}
+	goos, goarch, err := PluginPlatform()
+	if err != nil {
+		return "", err
+	}
+
+	base := DefaultPluginDownloadURL
+	if mirror := os.Getenv(PluginDownloadURLEnvVar); mirror != "" {
+		base = mirror
+	}
+	if strings.Contains(base, "${") {
+		return strings.NewReplacer(
+			"${kind}", string(info.Kind),
+			"${name}", info.Name,
+			"${version}", info.Version.String(),
+			"${os}", goos,
+			"${arch}", goarch).Replace(base), nil
+	}
+	return fmt.Sprintf("%s/pulumi-%s-%s-v%s-%s-%s.tar.gz",
+		strings.TrimSuffix(base, "/"), info.Kind, info.Name, info.Version, goos, goarch), nil
+}
+
+// HasPluginMirror returns true if plugins are downloaded from a mirror, in which case all plugins should be downloaded
+// with Download rather than from the Pulumi service.
+func HasPluginMirror() bool {
+	return os.Getenv(PluginDownloadURLEnvVar) != ""
+}
+
+// PluginFileFetcher fetches the file with the given suffix (e.g. ".sha256") that is published alongside a plugin's
+// tarball. If no such file is published, it returns nil and no error.
+type PluginFileFetcher func(suffix string) (io.ReadCloser, error)
+
+// Download downloads the plugin's tarball for the current platform, returning a stream that reads the tar.gz file,
+// which should be expanded (e.g. by Install) and closed, and its size, which is -1 if it is unknown. The tarball is
+// verified first, as described by VerifyDownload.
+func (info PluginInfo) Download() (io.ReadCloser, int64, error) {
+	tarball, size, fetch, err := info.OpenDownload()
+	if err != nil {
+		return nil, 0, err
+	}
+	return info.VerifyDownload(tarball, size, fetch)
+}
+
+// OpenDownload starts downloading the plugin's tarball for the current platform, returning a stream that reads it
+// without verifying it, its size, which is -1 if it is unknown, and a fetcher for the files published alongside it.
+// Callers that want to observe the download (e.g. to show its progress) should pass the stream, once wrapped, to
+// VerifyDownload; others should use Download instead.
+func (info PluginInfo) OpenDownload() (io.ReadCloser, int64, PluginFileFetcher, error) {
+	tarballURL, err := info.DownloadURL()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	tarball, size, err := openPluginURL(tarballURL)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	fetch := func(suffix string) (io.ReadCloser, error) {
+		file, _, err := openPluginURL(tarballURL + suffix)
+		if _, notFound := err.(*pluginURLNotFoundError); notFound || os.IsNotExist(err) {
+			return nil, nil
+		}
+		return file, err
+	}
+	return tarball, size, fetch, nil
+}
+
+// VerifyDownload verifies the plugin's tarball, read from the given stream, against the checksum and, if the plugin
+// trust policy requires plugins to be signed, the signature that are published alongside it, which are fetched with
+// the given fetcher. The tarball is copied into a temporary file while it is verified; if it is valid, VerifyDownload
+// returns a stream that reads that file and removes it when it is closed, along with the file's size. The given stream
+// is always closed.
+//
+// Tarballs without published checksums are returned unverified, along with the given size, unless they were
+// downloaded from a mirror or signatures are required, in which case they are rejected.
+func (info PluginInfo) VerifyDownload(tarball io.ReadCloser, size int64,
+	fetch PluginFileFetcher) (io.ReadCloser, int64, error) {
+
+	policy, err := GetPluginTrustPolicy()
+	if err != nil {
+		contract.IgnoreClose(tarball)
+		return nil, 0, errors.Wrap(err, "loading plugin trust policy")
+	}
+	var keys []ed25519.PublicKey
+	if policy.RequireSignatures {
+		if keys = policy.trustedKeys(); len(keys) == 0 {
+			contract.IgnoreClose(tarball)
+			return nil, 0, errNoTrustedKeys
+		}
+	}
+
+	checksum, err := fetch(".sha256")
+	if err != nil {
+		contract.IgnoreClose(tarball)
+		return nil, 0, errors.Wrapf(err, "downloading the checksum of the %s plugin %s", info.Kind, info)
+	} else if checksum == nil {
+		if HasPluginMirror() {
+			contract.IgnoreClose(tarball)
+			return nil, 0, errors.Errorf("the %s plugin %s has no published checksum; "+
+				"plugins downloaded from a mirror must have checksums", info.Kind, info)
+		} else if len(keys) != 0 {
+			contract.IgnoreClose(tarball)
+			return nil, 0, errors.Errorf("the %s plugin %s has no published checksum; "+
+				"the plugin trust policy requires plugins to be signed", info.Kind, info)
+		}
+		logging.V(7).Infof("the %s plugin %s has no published checksum, and is not verified", info.Kind, info)
+		return tarball, size, nil
+	}
+	defer contract.IgnoreClose(checksum)
+
+	var signature []byte
+	if len(keys) != 0 {
+		file, err := fetch(PluginSignatureSuffix)
+		if err != nil {
+			contract.IgnoreClose(tarball)
+			return nil, 0, errors.Wrapf(err, "downloading the signature of the %s plugin %s", info.Kind, info)
+		} else if file == nil {
+			contract.IgnoreClose(tarball)
+			return nil, 0, errors.Errorf("the %s plugin %s has no published signature; "+
+				"the plugin trust policy requires plugins to be signed", info.Kind, info)
+		}
+		signature, err = readPluginSignature(file)
+		contract.IgnoreClose(file)
+		if err != nil {
+			contract.IgnoreClose(tarball)
+			return nil, 0, errors.Wrapf(err, "reading the signature of the %s plugin %s", info.Kind, info)
+		}
+	}
+
+	return info.verifyTarball(tarball, checksum, signature, keys)
+}
+
+// openPluginURL opens the file at the given http://, https://, or file:// URL, returning a stream that reads it and
+// its size, which is -1 if it is unknown.
+func openPluginURL(rawURL string) (io.ReadCloser, int64, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "invalid plugin URL %s", rawURL)
+	}
+
+	if u.Scheme == "file" {
+		path := u.Path
+		if runtime.GOOS == windowsGOOS {
+			// file:///C:/plugins/... has the path /C:/plugins/...
+			path = filepath.FromSlash(strings.TrimPrefix(path, "/"))
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		stat, err := f.Stat()
+		if err != nil {
+			contract.IgnoreClose(f)
+			return nil, 0, err
+		}
+		return f, stat.Size(), nil
+	}
+
+	resp, err := httputil.GetWithRetry(rawURL, http.DefaultClient)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "downloading %s", rawURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		contract.IgnoreClose(resp.Body)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, 0, &pluginURLNotFoundError{url: rawURL, status: resp.Status}
+		}
+		return nil, 0, errors.Errorf("downloading %s: the server responded with %s", rawURL, resp.Status)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// pluginURLNotFoundError is returned by openPluginURL if the server responds that there is no file at the URL.
+type pluginURLNotFoundError struct {
+	url    string
+	status string
+}
+
+func (err *pluginURLNotFoundError) Error() string {
+	return fmt.Sprintf("downloading %s: the server responded with %s", err.url, err.status)
+}
+
+// verifyTarball copies the given tarball into a temporary file while hashing it, and checks the hash against the given
+// checksum file and, if there are any keys, the given signature. If they match, it returns a stream that reads the
+// temporary file and removes it when it is closed, along with the file's size. The stream also carries the signature,
+// so that Install may keep it.
+func (info PluginInfo) verifyTarball(tarball io.ReadCloser, checksum io.Reader, signature []byte,
+	keys []ed25519.PublicKey) (io.ReadCloser, int64, error) {
+
+	defer contract.IgnoreClose(tarball)
+
+	// The checksum file holds the hash, optionally followed by the name of the file, as written by `sha256sum`.
+	contents, err := ioutil.ReadAll(io.LimitReader(checksum, 1024))
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "reading the checksum of the %s plugin %s", info.Kind, info)
+	}
+	fields := strings.Fields(string(contents))
+	if len(fields) == 0 {
+		return nil, 0, errors.Errorf("the checksum of the %s plugin %s is empty", info.Kind, info)
+	}
+	expected := strings.ToLower(fields[0])
+
+	f, err := ioutil.TempFile("", "pulumi-plugin")
+	if err != nil {
+		return nil, 0, err
+	}
+	temp := &tempFile{File: f}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, hash), tarball)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		contract.IgnoreClose(temp)
+		return nil, 0, errors.Wrapf(err, "downloading the %s plugin %s", info.Kind, info)
+	}
+	digest := hash.Sum(nil)
+	if actual := hex.EncodeToString(digest); actual != expected {
+		contract.IgnoreClose(temp)
+		return nil, 0, errors.Errorf("the SHA-256 hash of the %s plugin %s is %s, but its checksum is %s",
+			info.Kind, info, actual, expected)
+	}
+
+	if len(keys) != 0 {
+		if err = verifyPluginSignature(digest, signature, keys); err != nil {
+			contract.IgnoreClose(temp)
+			return nil, 0, errors.Wrapf(err, "verifying the signature of the %s plugin %s", info.Kind, info)
+		}
+		temp.signature = signature
+	}
+	return temp, size, nil
+}
+
+// tempFile is a temporary file that is removed when it is closed.
+type tempFile struct {
+	*os.File
+	signature []byte // the signature of the file, if it is a plugin tarball whose signature has been verified.
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	contract.IgnoreError(os.Remove(f.Name()))
+	return err
+}
+
 func (info PluginInfo) String() string {
 	var version string
 	if v := info.Version; v != nil {
@@ -364,8 +747,16 @@ func GetPlugins() ([]PluginInfo, error) {
 
 // GetPluginPath finds a plugin's path by its kind, name, and optional version.  It will match the latest version that
 // is >= the version specified.  If no version is supplied, the latest plugin for that given kind/name pair is loaded,
-// using standard semver sorting rules.  A plugin may be overridden entirely by placing it on your $PATH.
+// using standard semver sorting rules.  A plugin may be overridden entirely by linking it to a local build with
+// `pulumi plugin link`, or by placing it on your $PATH.
 func GetPluginPath(kind PluginKind, name string, version *semver.Version) (string, string, error) {
+	// If the plugin has been linked to a local build, use it regardless of the version requested.
+	if link, has := getPluginLink(kind, name); has {
+		path := link.Executable()
+		logging.V(6).Infof("GetPluginPath(%s, %s, %v): linked to %s", kind, name, version, path)
+		return "", path, nil
+	}
+
 	// If we have a version of the plugin on its $PATH, use it.  This supports development scenarios.
 	filename := (&PluginInfo{Kind: kind, Name: name, Version: version}).FilePrefix()
 	if path, err := exec.LookPath(filename); err == nil {
@@ -456,6 +847,9 @@ func GetPluginPath(kind PluginKind, name string, version *semver.Version) (strin
 		}
 
 		logging.V(6).Infof("GetPluginPath(%s, %s, %v): found in cache at %s", kind, name, version, matchPath)
+		if err = match.MarkUsed(); err != nil {
+			logging.V(6).Infof("GetPluginPath(%s, %s, %v): could not record use: %v", kind, name, version, err)
+		}
 		return matchDir, matchPath, nil
 	}
 
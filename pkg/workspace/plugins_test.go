@@ -15,10 +15,26 @@
 package workspace
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
 func TestPluginSelection_ExactMatch(t *testing.T) {
@@ -234,3 +250,203 @@ func TestPluginSelection_EmptyVersionWithAlternatives(t *testing.T) {
 	assert.Equal(t, "myplugin", result.Name)
 	assert.Equal(t, "0.2.0", result.Version.String())
 }
+
+func TestPluginDownload(t *testing.T) {
+	goos, goarch, err := PluginPlatform()
+	if err != nil {
+		t.Skip(err)
+	}
+	tarball := fmt.Sprintf("/plugins/pulumi-resource-aws-v0.17.1-%s-%s.tar.gz", goos, goarch)
+	files := map[string]string{
+		tarball:             "tarball",
+		tarball + ".sha256": sha256Hex("tarball") + "  pulumi-resource-aws.tar.gz\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		contents, has := files[req.URL.Path]
+		if !has {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(contents))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+	os.Setenv(PluginDownloadURLEnvVar, server.URL+"/plugins/")
+	defer os.Unsetenv(PluginDownloadURLEnvVar)
+
+	version := semver.MustParse("0.17.1")
+	info := PluginInfo{Kind: ResourcePlugin, Name: "aws", Version: &version}
+	url, err := info.DownloadURL()
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+tarball, url)
+
+	stream, size, err := info.Download()
+	assert.NoError(t, err)
+	byts, err := ioutil.ReadAll(stream)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Close())
+	assert.Equal(t, "tarball", string(byts))
+	assert.Equal(t, int64(7), size)
+
+	// Plugins that the server does not have, and plugins without versions, cannot be downloaded.
+	info.Name = "gcp"
+	_, _, err = info.Download()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "404 Not Found")
+
+	info.Version = nil
+	_, _, err = info.Download()
+	assert.Error(t, err)
+
+	// Tarballs from a mirror must match their checksums.
+	info.Name, info.Version = "aws", &version
+	files[tarball] = "tampered"
+	_, _, err = info.Download()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "but its checksum is")
+
+	delete(files, tarball+".sha256")
+	_, _, err = info.Download()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must have checksums")
+}
+
+func TestPluginMirrorLayout(t *testing.T) {
+	goos, goarch, err := PluginPlatform()
+	if err != nil {
+		t.Skip(err)
+	}
+	dir, err := ioutil.TempDir("", "pulumi-plugin-mirror")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Mirrors may lay plugins out however they like, and may be file shares rather than servers.
+	tarball := filepath.Join(dir, "resource", "aws", "0.17.1", goos+"-"+goarch+".tgz")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(tarball), 0700))
+	assert.NoError(t, ioutil.WriteFile(tarball, []byte("tarball"), 0600))
+	assert.NoError(t, ioutil.WriteFile(tarball+".sha256", []byte(sha256Hex("tarball")), 0600))
+	mirror := "file://" + filepath.ToSlash(dir) + "/${kind}/${name}/${version}/${os}-${arch}.tgz"
+	if runtime.GOOS == windowsGOOS {
+		mirror = "file:///" + filepath.ToSlash(dir) + "/${kind}/${name}/${version}/${os}-${arch}.tgz"
+	}
+	os.Setenv(PluginDownloadURLEnvVar, mirror)
+	defer os.Unsetenv(PluginDownloadURLEnvVar)
+	assert.True(t, HasPluginMirror())
+
+	version := semver.MustParse("0.17.1")
+	info := PluginInfo{Kind: ResourcePlugin, Name: "aws", Version: &version}
+	stream, size, err := info.Download()
+	assert.NoError(t, err)
+	byts, err := ioutil.ReadAll(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "tarball", string(byts))
+	assert.Equal(t, int64(7), size)
+
+	// The verified copy of the tarball is removed once it has been read.
+	temp := stream.(*tempFile).Name()
+	assert.NoError(t, stream.Close())
+	_, err = os.Stat(temp)
+	assert.True(t, os.IsNotExist(err))
+
+	info.Name = "gcp"
+	_, _, err = info.Download()
+	assert.Error(t, err)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPluginVerifyDownload(t *testing.T) {
+	version := semver.MustParse("0.17.1")
+	info := PluginInfo{Kind: ResourcePlugin, Name: "aws", Version: &version}
+	files := make(map[string]string)
+	fetch := func(suffix string) (io.ReadCloser, error) {
+		contents, has := files[suffix]
+		if !has {
+			return nil, nil
+		}
+		return ioutil.NopCloser(strings.NewReader(contents)), nil
+	}
+	verify := func(tarball string) (string, error) {
+		stream, _, err := info.VerifyDownload(ioutil.NopCloser(strings.NewReader(tarball)), int64(len(tarball)), fetch)
+		if err != nil {
+			return "", err
+		}
+		defer contract.IgnoreClose(stream)
+		byts, err := ioutil.ReadAll(stream)
+		return string(byts), err
+	}
+
+	// Tarballs without published checksums are not verified, unless they are downloaded from a mirror.
+	tarball, err := verify("tarball")
+	assert.NoError(t, err)
+	assert.Equal(t, "tarball", tarball)
+
+	// Published checksums are always verified.
+	files[".sha256"] = sha256Hex("tarball")
+	tarball, err = verify("tarball")
+	assert.NoError(t, err)
+	assert.Equal(t, "tarball", tarball)
+	_, err = verify("tampered")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "but its checksum is")
+
+	// If the plugin trust policy requires signatures, tarballs must be signed by one of its keys.
+	public, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	other, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	policy := &PluginTrustPolicy{RequireSignatures: true}
+	defer usePluginTrustPolicy(t, policy)()
+
+	_, err = verify("tarball")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no keys are trusted")
+
+	assert.NoError(t, policy.AddKey("other", base64.StdEncoding.EncodeToString(other)))
+	assert.NoError(t, policy.AddKey("acme", base64.StdEncoding.EncodeToString(public)))
+	assert.NoError(t, SavePluginTrustPolicy(policy))
+	_, err = verify("tarball")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "has no published signature")
+
+	digest := sha256.Sum256([]byte("tarball"))
+	files[".sig"] = base64.StdEncoding.EncodeToString(ed25519.Sign(private, digest[:])) + "\n"
+	tarball, err = verify("tarball")
+	assert.NoError(t, err)
+	assert.Equal(t, "tarball", tarball)
+
+	files[".sig"] = base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte("something else")))
+	_, err = verify("tarball")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match any of the trusted keys")
+
+	delete(files, ".sha256")
+	_, err = verify("tarball")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "has no published checksum")
+}
+
+// sha256Hex returns the hex-encoded SHA-256 hash of the given string.
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPluginLastUsedTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-plugin-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "pulumi-resource-aws"), []byte("plugin"), 0700))
+
+	// Without a record of its last use, a plugin's last use is approximated by its directory's access time.
+	var info PluginInfo
+	assert.NoError(t, info.SetFileMetadata(dir))
+	assert.False(t, info.LastUsedTime.IsZero())
+
+	// Otherwise the recorded time is used, regardless of when the directory was last accessed.
+	used := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, pluginLastUsedFile), nil, 0600))
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, pluginLastUsedFile), used, used))
+	assert.NoError(t, info.SetFileMetadata(dir))
+	assert.True(t, used.Equal(info.LastUsedTime))
+	assert.Equal(t, int64(len("plugin")), info.Size)
+}
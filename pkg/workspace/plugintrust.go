@@ -0,0 +1,323 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+const (
+	// PluginTrustFile is the name of the file that holds the installation-wide plugin trust policy.
+	PluginTrustFile = "plugin-trust.json"
+	// PluginSignatureSuffix is appended to the URL of a plugin's tarball to find its signature, which holds the
+	// base64-encoded Ed25519 signature of the tarball's SHA-256 hash (that is, of the 32 bytes of the hash itself,
+	// rather than of its hex encoding).
+	PluginSignatureSuffix = ".sig"
+
+	// pluginTarballFile is the name of the file in a plugin's directory that holds the signed tarball from which the
+	// plugin was installed, if any. Its signature is in the file of the same name plus PluginSignatureSuffix.
+	pluginTarballFile = ".pulumi-plugin.tar.gz"
+)
+
+// errNoTrustedKeys is returned when the plugin trust policy requires plugins to be signed, but trusts no keys.
+var errNoTrustedKeys = errors.New("the plugin trust policy requires plugins to be signed, but no keys are trusted; " +
+	"use `pulumi plugin trust add` to trust a key")
+
+// PluginTrustPolicy decides which plugins may be installed and executed. When signatures are required, plugins must be
+// downloaded in tarballs that are signed by one of the trusted keys, and each time a plugin is executed, the signature
+// of the tarball from which it was installed is checked again. Plugins that are installed alongside the pulumi
+// executable itself, such as the language hosts, are part of the installation, and are always trusted.
+type PluginTrustPolicy struct {
+	// RequireSignatures is true if plugins must be signed by a trusted key before they are executed.
+	RequireSignatures bool `json:"requireSignatures"`
+	// Keys are the trusted keys, sorted by name.
+	Keys []TrustedPluginKey `json:"keys"`
+}
+
+// TrustedPluginKey is a public key that is trusted to sign plugins.
+type TrustedPluginKey struct {
+	// Name identifies the key, e.g. the name of the organization that signs plugins with it.
+	Name string `json:"name"`
+	// Key is the base64-encoded Ed25519 public key.
+	Key string `json:"key"`
+}
+
+// GetPluginTrustFilePath returns the path of the installation-wide plugin trust policy, whether or not it exists. The
+// policy is kept outside of every user's home directory, so that only an administrator may change it: on Windows, it
+// is in %ProgramData%\Pulumi, and elsewhere, it is in /etc/pulumi.
+func GetPluginTrustFilePath() string {
+	return pluginTrustFilePath()
+}
+
+// pluginTrustFilePath returns the path of the plugin trust policy. It is a variable so that tests may replace it.
+var pluginTrustFilePath = func() string {
+	if runtime.GOOS == windowsGOOS {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return filepath.Join(programData, "Pulumi", PluginTrustFile)
+	}
+	return filepath.Join("/etc/pulumi", PluginTrustFile)
+}
+
+// GetPluginTrustPolicy returns the plugin trust policy. If there is none, the returned policy allows every plugin.
+func GetPluginTrustPolicy() (*PluginTrustPolicy, error) {
+	return loadPluginTrustPolicy(pluginTrustFilePath())
+}
+
+// SavePluginTrustPolicy replaces the plugin trust policy with the given one. This usually requires administrator
+// privileges.
+func SavePluginTrustPolicy(policy *PluginTrustPolicy) error {
+	return policy.save(pluginTrustFilePath())
+}
+
+func loadPluginTrustPolicy(path string) (*PluginTrustPolicy, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &PluginTrustPolicy{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var policy PluginTrustPolicy
+	if err = json.Unmarshal(b, &policy); err != nil {
+		return nil, errors.Wrapf(err, "could not parse plugin trust policy %s", path)
+	}
+	for _, k := range policy.Keys {
+		if _, err = decodePluginKey(k.Key); err != nil {
+			return nil, errors.Wrapf(err, "invalid key '%s' in plugin trust policy %s", k.Name, path)
+		}
+	}
+	return &policy, nil
+}
+
+func (policy *PluginTrustPolicy) save(path string) error {
+	if policy.Keys == nil {
+		policy.Keys = []TrustedPluginKey{}
+	}
+	b, err := json.MarshalIndent(policy, "", "    ")
+	if err != nil {
+		return err
+	}
+	// Every user must be able to read the policy, but only its owner may change it.
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// decodePluginKey decodes a base64-encoded Ed25519 public key.
+func decodePluginKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("keys must be base64-encoded Ed25519 public keys")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// trustedKeys returns the keys that the policy trusts.
+func (policy *PluginTrustPolicy) trustedKeys() []ed25519.PublicKey {
+	var keys []ed25519.PublicKey
+	for _, k := range policy.Keys {
+		key, err := decodePluginKey(k.Key)
+		contract.AssertNoErrorf(err, "trusted keys are validated when the policy is loaded")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// AddKey trusts the given base64-encoded Ed25519 public key under the given name, replacing any key with that name.
+func (policy *PluginTrustPolicy) AddKey(name, encoded string) error {
+	if name == "" {
+		return errors.New("trusted keys must have names")
+	}
+	if _, err := decodePluginKey(encoded); err != nil {
+		return err
+	}
+	policy.RemoveKey(name)
+	policy.Keys = append(policy.Keys, TrustedPluginKey{Name: name, Key: encoded})
+	sort.Slice(policy.Keys, func(i, j int) bool { return policy.Keys[i].Name < policy.Keys[j].Name })
+	return nil
+}
+
+// RemoveKey stops trusting the key with the given name, returning false if there is no such key.
+func (policy *PluginTrustPolicy) RemoveKey(name string) bool {
+	for i, k := range policy.Keys {
+		if k.Name == name {
+			policy.Keys = append(policy.Keys[:i], policy.Keys[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyExecutable checks that the plugin executable at the given path may be executed. It returns the path of the
+// file to execute in its place, along with a function that removes that file, which must be called once the plugin
+// has exited.
+//
+// If signatures are required, and the executable is not part of the Pulumi installation, it must belong to a plugin
+// that was installed from a signed tarball. The executable is extracted from the copy of that tarball in the
+// plugin's directory into a private temporary directory while the tarball is hashed, and the hash is checked against
+// the tarball's signature. The file that is executed is therefore the one that was verified, even if the plugin's
+// directory changes in the meantime. Plugins that expect other files to be next to their executable cannot be run
+// this way.
+func (policy *PluginTrustPolicy) VerifyExecutable(path string) (string, func(), error) {
+	if !policy.RequireSignatures || isPartOfInstallation(path) {
+		return path, func() {}, nil
+	}
+	keys := policy.trustedKeys()
+	if len(keys) == 0 {
+		return "", nil, errors.Wrapf(errNoTrustedKeys, "plugin %s cannot be executed", path)
+	}
+
+	tarball := filepath.Join(filepath.Dir(path), pluginTarballFile)
+	file, err := os.Open(tarball + PluginSignatureSuffix)
+	if os.IsNotExist(err) {
+		return "", nil, errors.Errorf("plugin %s cannot be executed: plugins must be installed from signed "+
+			"tarballs, but it was not; reinstall it with `pulumi plugin install --reinstall`", path)
+	} else if err != nil {
+		return "", nil, err
+	}
+	signature, err := readPluginSignature(file)
+	contract.IgnoreClose(file)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "plugin %s cannot be executed: reading its signature", path)
+	}
+
+	tempDir, err := ioutil.TempDir("", "pulumi-plugin")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { contract.IgnoreError(os.RemoveAll(tempDir)) }
+	exe := filepath.Join(tempDir, filepath.Base(path))
+	digest, err := extractPluginExecutable(tarball, filepath.Base(path), exe)
+	if err != nil {
+		cleanup()
+		return "", nil, errors.Wrapf(err, "plugin %s cannot be executed: extracting it from its tarball", path)
+	}
+	if err = verifyPluginSignature(digest, signature, keys); err != nil {
+		cleanup()
+		return "", nil, errors.Wrapf(err, "plugin %s cannot be executed: verifying its tarball's signature", path)
+	}
+	return exe, cleanup, nil
+}
+
+// isPartOfInstallation returns true if the given executable is in the same directory as the running executable.
+func isPartOfInstallation(path string) bool {
+	exe, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return false
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	return filepath.Dir(exe) == dir
+}
+
+// extractPluginExecutable copies the file with the given name from the given plugin tarball to the given path,
+// returning the SHA-256 hash of the whole tarball. The tarball is read only once, so the hash is of the same bytes
+// from which the file was extracted.
+func extractPluginExecutable(tarball, name, dest string) ([]byte, error) {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(f)
+
+	hash := sha256.New()
+	source := io.TeeReader(f, hash)
+	gzr, err := gzip.NewReader(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "unzipping")
+	}
+	r := tar.NewReader(gzr)
+	found := false
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "untarring")
+		}
+		if found || header.Typeflag != tar.TypeReg || filepath.Clean(filepath.FromSlash(header.Name)) != name {
+			continue
+		}
+
+		exe, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0700)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(exe, r)
+		if closeErr := exe.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, errors.Errorf("the tarball does not contain %s", name)
+	}
+
+	// Hash any data that follows the archive, since the signature covers the whole file.
+	if _, err = io.Copy(ioutil.Discard, source); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// readPluginSignature reads a base64-encoded Ed25519 signature from the given signature file.
+func readPluginSignature(signature io.Reader) ([]byte, error) {
+	contents, err := ioutil.ReadAll(io.LimitReader(signature, 1024))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(contents)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return nil, errors.New("the signature is not a base64-encoded Ed25519 signature")
+	}
+	return sig, nil
+}
+
+// verifyPluginSignature checks that the given signature is of the given digest by one of the given keys.
+func verifyPluginSignature(digest, signature []byte, keys []ed25519.PublicKey) error {
+	for _, key := range keys {
+		if ed25519.Verify(key, digest, signature) {
+			return nil
+		}
+	}
+	return errors.New("the signature does not match any of the trusted keys")
+}
@@ -0,0 +1,173 @@
+// Copyright 2016-2019, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
+)
+
+// usePluginTrustPolicy saves the given policy to a temporary file and uses it as the plugin trust policy, returning a
+// function that restores the previous policy.
+func usePluginTrustPolicy(t *testing.T, policy *PluginTrustPolicy) func() {
+	dir, err := ioutil.TempDir("", "pulumi-plugin-trust")
+	assert.NoError(t, err)
+	path := filepath.Join(dir, PluginTrustFile)
+	assert.NoError(t, policy.save(path))
+
+	old := pluginTrustFilePath
+	pluginTrustFilePath = func() string { return path }
+	return func() {
+		pluginTrustFilePath = old
+		contract.IgnoreError(os.RemoveAll(dir))
+	}
+}
+
+func TestPluginTrustPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-plugin-trust")
+	assert.NoError(t, err)
+	defer func() { contract.IgnoreError(os.RemoveAll(dir)) }()
+
+	public, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	other, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	encoded := base64.StdEncoding.EncodeToString(public)
+
+	// A missing policy allows every plugin, which is executed in place.
+	path := filepath.Join(dir, PluginTrustFile)
+	policy, err := loadPluginTrustPolicy(path)
+	assert.NoError(t, err)
+	assert.False(t, policy.RequireSignatures)
+	exe, cleanup, err := policy.VerifyExecutable(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "missing"), exe)
+	cleanup()
+
+	// Only Ed25519 public keys may be trusted, and adding a key under an existing name replaces it.
+	assert.Error(t, policy.AddKey("acme", "not a key"))
+	assert.Error(t, policy.AddKey("", encoded))
+	assert.NoError(t, policy.AddKey("acme", base64.StdEncoding.EncodeToString(other)))
+	assert.NoError(t, policy.AddKey("acme", encoded))
+	assert.NoError(t, policy.AddKey("other", base64.StdEncoding.EncodeToString(other)))
+	policy.RequireSignatures = true
+	assert.NoError(t, policy.save(path))
+
+	policy, err = loadPluginTrustPolicy(path)
+	assert.NoError(t, err)
+	assert.True(t, policy.RequireSignatures)
+	assert.Equal(t, []TrustedPluginKey{
+		{Name: "acme", Key: encoded},
+		{Name: "other", Key: base64.StdEncoding.EncodeToString(other)},
+	}, policy.Keys)
+	assert.True(t, policy.RemoveKey("acme"))
+	assert.False(t, policy.RemoveKey("acme"))
+
+	// The policy is stored outside of the user's home directory.
+	assert.NotContains(t, GetPluginTrustFilePath(), BookkeepingDir)
+}
+
+// pluginTarball returns a plugin tarball that holds the given files.
+func pluginTarball(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, contents := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0700,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(contents))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+func TestPluginVerifyExecutable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-plugin-trust")
+	assert.NoError(t, err)
+	defer func() { contract.IgnoreError(os.RemoveAll(dir)) }()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	policy := &PluginTrustPolicy{RequireSignatures: true}
+
+	// Without trusted keys, no plugin may be executed.
+	path := filepath.Join(dir, "pulumi-resource-acme")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"), 0700))
+	_, _, err = policy.VerifyExecutable(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no keys are trusted")
+
+	// Plugins that were not installed from signed tarballs may not be executed.
+	assert.NoError(t, policy.AddKey("acme", base64.StdEncoding.EncodeToString(public)))
+	_, _, err = policy.VerifyExecutable(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must be installed from signed tarballs")
+
+	tarball := pluginTarball(t, map[string]string{"pulumi-resource-acme": "#!/bin/sh\necho signed\n"})
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, pluginTarballFile), tarball, 0600))
+	sign := func(data []byte) {
+		digest := sha256.Sum256(data)
+		signature := base64.StdEncoding.EncodeToString(ed25519.Sign(private, digest[:]))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, pluginTarballFile+PluginSignatureSuffix),
+			[]byte(signature+"\n"), 0600))
+	}
+	sign([]byte("something else"))
+	_, _, err = policy.VerifyExecutable(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match any of the trusted keys")
+
+	// The executable of a plugin installed from a signed tarball is extracted from the tarball, so that the file that
+	// is executed is the one that was signed, rather than the installed one.
+	sign(tarball)
+	exe, cleanup, err := policy.VerifyExecutable(path)
+	assert.NoError(t, err)
+	assert.NotEqual(t, path, exe)
+	contents, err := ioutil.ReadFile(exe)
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho signed\n", string(contents))
+	cleanup()
+	_, err = os.Stat(exe)
+	assert.True(t, os.IsNotExist(err))
+
+	// Changing the tarball invalidates its signature.
+	tampered := pluginTarball(t, map[string]string{"pulumi-resource-acme": "#!/bin/sh\necho tampered\n"})
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, pluginTarballFile), tampered, 0600))
+	_, _, err = policy.VerifyExecutable(path)
+	assert.Error(t, err)
+
+	// Once the key is no longer trusted, the plugin may not be executed.
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, pluginTarballFile), tarball, 0600))
+	assert.True(t, policy.RemoveKey("acme"))
+	_, _, err = policy.VerifyExecutable(path)
+	assert.Error(t, err)
+}
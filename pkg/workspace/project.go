@@ -17,12 +17,17 @@ package workspace
 import (
 	"encoding/json"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/resource/config"
 	"github.com/pulumi/pulumi/pkg/util/contract"
 
+	"github.com/blang/semver"
 	"github.com/pkg/errors"
 
 	"github.com/pulumi/pulumi/pkg/encoding"
@@ -32,6 +37,379 @@ import (
 // Analyzers is a list of analyzers to run on this project.
 type Analyzers []tokens.QName
 
+// ProjectRetryPolicy configures how provider operations that fail with transient errors (such as rate limiting) are
+// retried. Unset fields inherit the engine's defaults or, for a provider package, the project's default policy, or, for
+// an operation, its provider package's policy.
+type ProjectRetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first. A value of 1 disables retries.
+	MaxAttempts int `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+	// InitialDelay is the delay before the first retry (e.g. "1s"); it doubles with each subsequent attempt.
+	InitialDelay string `json:"initialDelay,omitempty" yaml:"initialDelay,omitempty"`
+	// MaxDelay is the upper bound on the delay between attempts (e.g. "30s").
+	MaxDelay string `json:"maxDelay,omitempty" yaml:"maxDelay,omitempty"`
+	// Budget is the maximum number of times, over a whole update, that operations on a provider package's resources
+	// are retried. Zero means no limit beyond MaxAttempts. It may only be set for a provider package as a whole.
+	Budget int `json:"budget,omitempty" yaml:"budget,omitempty"`
+}
+
+// Validate checks that a retry policy's values are well-formed.
+func (p ProjectRetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return errors.Errorf("maxAttempts must not be negative")
+	}
+	if p.Budget < 0 {
+		return errors.Errorf("budget must not be negative")
+	}
+	for name, d := range map[string]string{"initialDelay": p.InitialDelay, "maxDelay": p.MaxDelay} {
+		if d == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(d); err != nil || parsed < 0 {
+			return errors.Errorf("%s must be a non-negative duration such as \"10s\", not %q", name, d)
+		}
+	}
+	return nil
+}
+
+// ProjectRetries configures automatic retries for provider operations.
+type ProjectRetries struct {
+	// Default is an optional policy that applies to all providers and operations.
+	Default *ProjectRetryPolicy `json:"default,omitempty" yaml:"default,omitempty"`
+	// Providers contains per-provider policies, keyed either by package name (e.g. "aws") or by package name and
+	// operation (e.g. "aws:create"). A package's policy may also be set by the retry section of its entry in the
+	// project's providers, but not in both places.
+	Providers map[string]ProjectRetryPolicy `json:"providers,omitempty" yaml:"providers,omitempty"`
+}
+
+// Validate checks that the retry policies are well-formed.
+func (r ProjectRetries) Validate() error {
+	if r.Default != nil {
+		if err := r.Default.Validate(); err != nil {
+			return errors.Wrap(err, "invalid default retry policy")
+		}
+		if r.Default.Budget != 0 {
+			return errors.New("invalid default retry policy: budget may only be set for a provider package")
+		}
+	}
+	for key, p := range r.Providers {
+		if err := p.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid retry policy for '%s'", key)
+		}
+		if strings.Contains(key, ":") && p.Budget != 0 {
+			return errors.Errorf("invalid retry policy for '%s': budget may only be set for a provider package", key)
+		}
+	}
+	return nil
+}
+
+// ProjectProviderSettings controls how the engine uses the resource providers of a provider package, for clouds that
+// reject requests made too quickly, or fail transiently, during large parallel deployments.
+type ProjectProviderSettings struct {
+	// Concurrency is the maximum number of simultaneous operations on the package's resources, independently of the
+	// overall parallelism of an update. Zero means no limit.
+	Concurrency int `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+	// QPS is the maximum sustained rate, per second, of calls to the package's providers. Zero means no limit.
+	QPS float64 `json:"qps,omitempty" yaml:"qps,omitempty"`
+	// Burst is the number of calls that may be made at once in excess of the sustained rate; it defaults to 1.
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+	// Retry optionally configures how operations on the package's resources are retried, overriding the project's
+	// default retry policy.
+	Retry *ProjectRetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// Validate checks that a provider package's settings are well-formed.
+func (s ProjectProviderSettings) Validate() error {
+	switch {
+	case s.Concurrency < 0:
+		return errors.Errorf("concurrency must not be negative")
+	case s.QPS < 0:
+		return errors.Errorf("qps must not be negative")
+	case s.Burst < 0:
+		return errors.Errorf("burst must not be negative")
+	}
+	if s.Retry != nil {
+		if err := s.Retry.Validate(); err != nil {
+			return errors.Wrap(err, "invalid retry policy")
+		}
+	}
+	return nil
+}
+
+// defaultProviderMaxRestarts is the number of times a provider may be restarted if a project does not say otherwise.
+// Restarts must be opted into, as a provider that is restarted loses any state that it held in memory.
+const defaultProviderMaxRestarts = 0
+
+// ProjectProviderHealth controls how the engine recovers from resource providers that crash or hang during an update,
+// so that one misbehaving provider process need not abort a long update.
+type ProjectProviderHealth struct {
+	// CallTimeout is the longest that a call which is safe to repeat, such as a check, diff, read, or invoke, may take
+	// before its provider is presumed hung (e.g. "5m"). If empty, such calls may take as long as they need.
+	CallTimeout string `json:"callTimeout,omitempty" yaml:"callTimeout,omitempty"`
+	// MaxRestarts is the number of times that each provider may be restarted after crashing or hanging. It defaults
+	// to zero, which disables restarts.
+	MaxRestarts *int `json:"maxRestarts,omitempty" yaml:"maxRestarts,omitempty"`
+}
+
+// Validate checks that the provider health settings are well-formed.
+func (h *ProjectProviderHealth) Validate() error {
+	if h.CallTimeout != "" {
+		if d, err := time.ParseDuration(h.CallTimeout); err != nil || d <= 0 {
+			return errors.Errorf("callTimeout must be a positive duration such as \"5m\", not %q", h.CallTimeout)
+		}
+	}
+	if h.MaxRestarts != nil && *h.MaxRestarts < 0 {
+		return errors.Errorf("maxRestarts must not be negative")
+	}
+	return nil
+}
+
+// Timeout returns the call timeout, or zero if there is none.
+func (h *ProjectProviderHealth) Timeout() time.Duration {
+	if h == nil || h.CallTimeout == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(h.CallTimeout)
+	contract.AssertNoErrorf(err, "provider health settings are validated when the project is loaded")
+	return d
+}
+
+// Restarts returns the number of times that each provider may be restarted.
+func (h *ProjectProviderHealth) Restarts() int {
+	if h == nil || h.MaxRestarts == nil {
+		return defaultProviderMaxRestarts
+	}
+	return *h.MaxRestarts
+}
+
+// ProjectProviderHook is a command that the engine runs before and after each create, update, and delete that a
+// resource provider performs, so that projects can enforce their own guardrails, such as change windows or naming
+// policies. The command reads a JSON description of the operation from stdin; before an operation, it may veto it.
+type ProjectProviderHook struct {
+	// Command is the shell command to run, from the project's directory.
+	Command string `json:"command" yaml:"command"`
+	// Operations optionally restricts the hook to some of the operations "create", "update", and "delete".
+	Operations []string `json:"operations,omitempty" yaml:"operations,omitempty"`
+}
+
+// Validate checks that a provider hook is well-formed.
+func (h ProjectProviderHook) Validate() error {
+	if strings.TrimSpace(h.Command) == "" {
+		return errors.New("command must not be empty")
+	}
+	for _, op := range h.Operations {
+		switch op {
+		case "create", "update", "delete":
+		default:
+			return errors.Errorf("unrecognized operation '%s'; expected create, update, or delete", op)
+		}
+	}
+	return nil
+}
+
+// Runs returns true if the hook runs for the given operation.
+func (h ProjectProviderHook) Runs(op string) bool {
+	if len(h.Operations) == 0 {
+		return true
+	}
+	for _, o := range h.Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectHooks are shell commands that the CLI runs at points in a stack's deployment lifecycle. Each hook is a list
+// of commands, run in order from the project's directory; if one fails, the remaining commands are skipped.
+type ProjectHooks struct {
+	// PrePreview commands run before `pulumi preview`.
+	PrePreview []string `json:"prePreview,omitempty" yaml:"prePreview,omitempty"`
+	// PreUpdate commands run before `pulumi up`, including before its preview.
+	PreUpdate []string `json:"preUpdate,omitempty" yaml:"preUpdate,omitempty"`
+	// PostUpdate commands run after `pulumi up` succeeds.
+	PostUpdate []string `json:"postUpdate,omitempty" yaml:"postUpdate,omitempty"`
+	// OnFailure commands run after `pulumi up` fails.
+	OnFailure []string `json:"onFailure,omitempty" yaml:"onFailure,omitempty"`
+}
+
+// Validate checks that a project's hooks are well-formed.
+func (h ProjectHooks) Validate() error {
+	hooks := map[string][]string{
+		"prePreview": h.PrePreview,
+		"preUpdate":  h.PreUpdate,
+		"postUpdate": h.PostUpdate,
+		"onFailure":  h.OnFailure,
+	}
+	for name, commands := range hooks {
+		for _, command := range commands {
+			if strings.TrimSpace(command) == "" {
+				return errors.Errorf("%s contains an empty command", name)
+			}
+		}
+	}
+	return nil
+}
+
+// ProjectDiffSuppression identifies a property whose changes are known to be noise, such as a server-computed
+// timestamp. Changes to it that a refresh observes are neither displayed nor counted as drift.
+type ProjectDiffSuppression struct {
+	// Type optionally restricts the suppression to resources of the given type (e.g. "aws:s3/bucket:Bucket").
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Property is a dotted path to the suppressed property (e.g. "metadata.generation").
+	Property string `json:"property" yaml:"property"`
+}
+
+// validateDiffSuppressions checks that a list of diff suppressions is well-formed.
+func validateDiffSuppressions(suppressions []ProjectDiffSuppression) error {
+	for _, s := range suppressions {
+		if s.Property == "" {
+			return errors.New("diff suppression is missing a 'property' attribute")
+		}
+	}
+	return nil
+}
+
+// ProjectRolloutPhase is one phase of a staged rollout. `pulumi up` updates a project with a rollout one phase at a
+// time: changes to existing resources that belong to later phases are held back until their phase runs.
+type ProjectRolloutPhase struct {
+	// Name is an optional name for the phase, used when reporting progress.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Resources lists glob patterns (e.g. "web-*") matched against resource names. A resource belongs to the first
+	// phase with a matching pattern; a phase without patterns, which must be the last, matches every resource.
+	// Resources that match no phase belong to the last phase.
+	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// Verify lists commands to run after the phase succeeds; if one fails, the rollout stops.
+	Verify []string `json:"verify,omitempty" yaml:"verify,omitempty"`
+	// Pause is an optional duration (e.g. "5m") to wait after the phase is verified, before the next phase begins.
+	Pause string `json:"pause,omitempty" yaml:"pause,omitempty"`
+}
+
+// validateRollout checks that the phases of a staged rollout are well-formed.
+func validateRollout(phases []ProjectRolloutPhase) error {
+	for i, phase := range phases {
+		if len(phase.Resources) == 0 && i != len(phases)-1 {
+			return errors.Errorf("rollout phase %d matches every resource, so it must be the last phase", i+1)
+		}
+		for _, pattern := range phase.Resources {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return errors.Errorf("rollout phase %d has an invalid resource pattern %q", i+1, pattern)
+			}
+		}
+		for _, command := range phase.Verify {
+			if strings.TrimSpace(command) == "" {
+				return errors.Errorf("rollout phase %d contains an empty verify command", i+1)
+			}
+		}
+		if phase.Pause != "" {
+			if d, err := time.ParseDuration(phase.Pause); err != nil || d < 0 {
+				return errors.Errorf("rollout phase %d must pause for a non-negative duration such as \"5m\", not %q",
+					i+1, phase.Pause)
+			}
+		}
+	}
+	return nil
+}
+
+// Names of the events that webhooks may subscribe to.
+const (
+	WebhookUpdateStarted   = "started"   // an update, refresh, or destroy has started.
+	WebhookUpdateSucceeded = "succeeded" // an update, refresh, or destroy has succeeded.
+	WebhookUpdateFailed    = "failed"    // an update, refresh, or destroy has failed.
+)
+
+// ProjectWebhook is a URL to which the CLI posts a JSON event when an update of one of the project's stacks starts and
+// when it finishes. The Pulumi service delivers webhooks of its own, which are configured in the service, so these are
+// only posted for stacks in other backends.
+type ProjectWebhook struct {
+	// URL is the http:// or https:// URL to which events are posted.
+	URL string `json:"url" yaml:"url"`
+	// SecretEnv optionally names an environment variable holding a secret with which each event is signed, so that
+	// the receiver can check that the event came from someone who knows the secret.
+	SecretEnv string `json:"secretEnv,omitempty" yaml:"secretEnv,omitempty"`
+	// Events optionally restricts the webhook to the named events ("started", "succeeded", and "failed"); by default,
+	// every event is posted.
+	Events []string `json:"events,omitempty" yaml:"events,omitempty"`
+}
+
+// Wants returns true if the webhook subscribes to the named event.
+func (w ProjectWebhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWebhooks checks that a project's webhooks are well-formed.
+func validateWebhooks(webhooks []ProjectWebhook) error {
+	for _, w := range webhooks {
+		if w.URL == "" {
+			return errors.New("webhook is missing a 'url' attribute")
+		}
+		if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Errorf("webhook URL %q must be an http:// or https:// URL", w.URL)
+		}
+		for _, e := range w.Events {
+			if e != WebhookUpdateStarted && e != WebhookUpdateSucceeded && e != WebhookUpdateFailed {
+				return errors.Errorf("webhook %s subscribes to unknown event %q; expected one of %q, %q, or %q",
+					w.URL, e, WebhookUpdateStarted, WebhookUpdateSucceeded, WebhookUpdateFailed)
+			}
+		}
+	}
+	return nil
+}
+
+// ProjectPlugin is a plugin that a project requires, which is downloaded before a preview or update if it is not
+// already installed. Plugins that the language host detects from the program's dependencies need not be listed.
+type ProjectPlugin struct {
+	// Kind is the kind of plugin: "resource", "analyzer", or "language".
+	Kind PluginKind `json:"kind" yaml:"kind"`
+	// Name is the plugin's name (e.g. "aws").
+	Name string `json:"name" yaml:"name"`
+	// Version is the exact version of the plugin that is required.
+	Version string `json:"version" yaml:"version"`
+}
+
+// validatePlugins checks that a project's required plugins are well-formed.
+func validatePlugins(plugins []ProjectPlugin) error {
+	for _, p := range plugins {
+		if !IsPluginKind(string(p.Kind)) {
+			return errors.Errorf("plugin %s has unrecognized kind %q", p.Name, p.Kind)
+		}
+		if p.Name == "" {
+			return errors.New("plugin is missing a 'name' attribute")
+		}
+		if _, err := semver.ParseTolerant(p.Version); err != nil {
+			return errors.Wrapf(err, "plugin %s has an invalid version", p.Name)
+		}
+	}
+	return nil
+}
+
+// RequiredPlugins returns the plugins that the project requires.
+func (proj *Project) RequiredPlugins() ([]PluginInfo, error) {
+	var plugins []PluginInfo
+	for _, p := range proj.Plugins {
+		version, err := semver.ParseTolerant(p.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "plugin %s has an invalid version", p.Name)
+		}
+		plugins = append(plugins, PluginInfo{Kind: p.Kind, Name: p.Name, Version: &version})
+	}
+	return plugins, nil
+}
+
+// ProjectBackend configures the backend in which a project's stacks are stored.
+type ProjectBackend struct {
+	// URL is the URL of the backend (e.g. "https://api.pulumi.com" or "file://~"), which is used for the project's
+	// stacks in place of the backend that was most recently logged into.
+	URL string `json:"url" yaml:"url"`
+}
+
 // ProjectTemplate is a Pulumi project template manifest.
 type ProjectTemplate struct {
 	// Description is an optional description of the template.
@@ -82,8 +460,49 @@ type Project struct {
 	// Config indicates where to store the Pulumi.<stack-name>.yaml files, combined with the folder Pulumi.yaml is in.
 	Config string `json:"config,omitempty" yaml:"config,omitempty"`
 
+	// Backend optionally names the backend in which the project's stacks are stored.
+	Backend *ProjectBackend `json:"backend,omitempty" yaml:"backend,omitempty"`
+
 	// Template is an optional template manifest, if this project is a template.
 	Template *ProjectTemplate `json:"template,omitempty" yaml:"template,omitempty"`
+
+	// Retries optionally configures automatic retries for provider operations that fail with transient errors.
+	Retries *ProjectRetries `json:"retries,omitempty" yaml:"retries,omitempty"`
+
+	// Hooks optionally configures commands to run before and after deployments.
+	Hooks *ProjectHooks `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+
+	// Concurrency optionally limits the number of simultaneous resource operations per provider package (e.g. "aws"),
+	// independently of the overall parallelism of an update. A package's limit may also be set by its entry in
+	// Providers, but not in both places.
+	Concurrency map[string]int `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+
+	// Providers optionally limits the concurrency of, the rate of calls to, and the retries of operations on, the
+	// resource providers for each provider package (e.g. "aws").
+	Providers map[string]ProjectProviderSettings `json:"providers,omitempty" yaml:"providers,omitempty"`
+
+	// ProviderHooks optionally lists commands that run before and after each resource operation, and that may veto
+	// those operations.
+	ProviderHooks []ProjectProviderHook `json:"providerHooks,omitempty" yaml:"providerHooks,omitempty"`
+
+	// ProviderHealth optionally controls how resource providers that crash or hang are restarted.
+	ProviderHealth *ProjectProviderHealth `json:"providerHealth,omitempty" yaml:"providerHealth,omitempty"`
+
+	// SuppressDiffs optionally lists properties whose changes a refresh should not report as drift.
+	SuppressDiffs []ProjectDiffSuppression `json:"suppressDiffs,omitempty" yaml:"suppressDiffs,omitempty"`
+
+	// Rollout optionally splits `pulumi up` into ordered phases, such as a single canary followed by the rest.
+	Rollout []ProjectRolloutPhase `json:"rollout,omitempty" yaml:"rollout,omitempty"`
+
+	// Webhooks optionally lists URLs to which events are posted as the project's stacks are updated.
+	Webhooks []ProjectWebhook `json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+
+	// Plugins optionally lists plugins, at specific versions, that the project requires.
+	Plugins []ProjectPlugin `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+
+	// Aliases optionally defines commands, such as `pulumi deploy:prod`, that run a sequence of other commands. They
+	// take precedence over the user's aliases of the same names.
+	Aliases CommandAliases `json:"aliases,omitempty" yaml:"aliases,omitempty"`
 }
 
 func (proj *Project) Validate() error {
@@ -93,6 +512,62 @@ func (proj *Project) Validate() error {
 	if proj.Runtime.Name() == "" {
 		return errors.New("project is missing a 'runtime' attribute")
 	}
+	if proj.Backend != nil && proj.Backend.URL == "" {
+		return errors.New("project backend is missing a 'url' attribute")
+	}
+	if proj.Retries != nil {
+		if err := proj.Retries.Validate(); err != nil {
+			return err
+		}
+	}
+	if proj.Hooks != nil {
+		if err := proj.Hooks.Validate(); err != nil {
+			return errors.Wrap(err, "invalid hooks")
+		}
+	}
+	for pkg, limit := range proj.Concurrency {
+		if limit <= 0 {
+			return errors.Errorf("concurrency limit for '%s' must be positive, not %d", pkg, limit)
+		}
+	}
+	for pkg, settings := range proj.Providers {
+		if err := settings.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid settings for provider '%s'", pkg)
+		}
+		if _, has := proj.Concurrency[pkg]; has && settings.Concurrency != 0 {
+			return errors.Errorf("the concurrency limit for '%s' is set both in concurrency and in providers", pkg)
+		}
+		if proj.Retries != nil && settings.Retry != nil {
+			if _, has := proj.Retries.Providers[pkg]; has {
+				return errors.Errorf("the retry policy for '%s' is set both in retries and in providers", pkg)
+			}
+		}
+	}
+	for i, hook := range proj.ProviderHooks {
+		if err := hook.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid provider hook #%d", i+1)
+		}
+	}
+	if proj.ProviderHealth != nil {
+		if err := proj.ProviderHealth.Validate(); err != nil {
+			return errors.Wrap(err, "invalid provider health settings")
+		}
+	}
+	if err := validateDiffSuppressions(proj.SuppressDiffs); err != nil {
+		return err
+	}
+	if err := validateRollout(proj.Rollout); err != nil {
+		return err
+	}
+	if err := validateWebhooks(proj.Webhooks); err != nil {
+		return err
+	}
+	if err := validatePlugins(proj.Plugins); err != nil {
+		return err
+	}
+	if err := proj.Aliases.Validate(); err != nil {
+		return errors.Wrap(err, "invalid aliases")
+	}
 
 	return nil
 }
@@ -129,6 +604,62 @@ type ProjectStack struct {
 	EncryptionSalt string `json:"encryptionsalt,omitempty" yaml:"encryptionsalt,omitempty"`
 	// Config is an optional config bag.
 	Config config.Map `json:"config,omitempty" yaml:"config,omitempty"`
+	// SuppressDiffs optionally lists properties whose changes a refresh should not report as drift, in addition to
+	// those listed by the project.
+	SuppressDiffs []ProjectDiffSuppression `json:"suppressDiffs,omitempty" yaml:"suppressDiffs,omitempty"`
+	// Credentials optionally maps package names to the credentials that the packages' providers are configured
+	// with when deploying this stack. Credentials are not recorded in the stack's checkpoint.
+	Credentials map[string]ProjectStackCredentials `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+}
+
+// ProjectStackCredentials tells a package's providers which credentials to use for a stack, so that stacks
+// deployed from the same machine can use different accounts. Each key is the name of a provider configuration
+// setting, such as "profile", and is only used if the setting is not otherwise configured.
+type ProjectStackCredentials struct {
+	// Values maps configuration settings to their values, such as the name of a profile.
+	Values map[string]string `json:"values,omitempty" yaml:"values,omitempty"`
+	// Files maps configuration settings to files, such as service account keys, whose contents are the settings'
+	// values. Relative paths are relative to the directory containing the stack settings file.
+	Files map[string]string `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// ResolveCredentials returns the credentials for each package's providers, reading any credential files
+// relative to the given directory.
+func (ps *ProjectStack) ResolveCredentials(dir string) (map[tokens.Package]map[string]string, error) {
+	if len(ps.Credentials) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[tokens.Package]map[string]string)
+	for pkg, creds := range ps.Credentials {
+		values := make(map[string]string)
+		for k, v := range creds.Values {
+			values[k] = v
+		}
+		for k, file := range creds.Files {
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(dir, file)
+			}
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, errors.Wrapf(err, "reading credentials for '%s'", pkg)
+			}
+			values[k] = string(b)
+		}
+		result[tokens.Package(pkg)] = values
+	}
+	return result, nil
+}
+
+func validateStackCredentials(credentials map[string]ProjectStackCredentials) error {
+	for pkg, creds := range credentials {
+		for k := range creds.Files {
+			if _, has := creds.Values[k]; has {
+				return errors.Errorf("credential '%s' for '%s' must not have both a value and a file", k, pkg)
+			}
+		}
+	}
+	return nil
 }
 
 // Save writes a project definition to a file.
@@ -288,6 +819,12 @@ func LoadProjectStack(path string) (*ProjectStack, error) {
 	if ps.Config == nil {
 		ps.Config = make(config.Map)
 	}
+	if err = validateDiffSuppressions(ps.SuppressDiffs); err != nil {
+		return nil, errors.Wrapf(err, "invalid stack settings in '%s'", path)
+	}
+	if err = validateStackCredentials(ps.Credentials); err != nil {
+		return nil, errors.Wrapf(err, "invalid stack settings in '%s'", path)
+	}
 
 	return &ps, err
 }
@@ -2,10 +2,16 @@ package workspace
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/pkg/util/contract"
 )
 
 func TestProjectRuntimeInfoRoundtripYAML(t *testing.T) {
@@ -36,3 +42,287 @@ func TestProjectRuntimeInfoRoundtripYAML(t *testing.T) {
 	doTest(yaml.Marshal, yaml.Unmarshal)
 	doTest(json.Marshal, json.Unmarshal)
 }
+
+func TestProjectHooksValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: hooks
+runtime: nodejs
+hooks:
+  preUpdate:
+    - npm run build
+  onFailure:
+    - ./notify.sh
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	assert.Equal(t, []string{"npm run build"}, proj.Hooks.PreUpdate)
+	assert.Equal(t, []string{"./notify.sh"}, proj.Hooks.OnFailure)
+
+	proj.Hooks.PostUpdate = []string{" "}
+	assert.Error(t, proj.Validate())
+}
+
+func TestProjectProvidersValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: providers
+runtime: nodejs
+retries:
+  default:
+    maxAttempts: 3
+  providers:
+    aws:create:
+      maxAttempts: 4
+concurrency:
+  gcp: 3
+providers:
+  aws:
+    concurrency: 5
+    qps: 2.5
+    burst: 5
+    retry:
+      maxAttempts: 5
+      maxDelay: 30s
+      budget: 20
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	assert.Equal(t, &ProjectRetries{
+		Default:   &ProjectRetryPolicy{MaxAttempts: 3},
+		Providers: map[string]ProjectRetryPolicy{"aws:create": {MaxAttempts: 4}},
+	}, proj.Retries)
+	assert.Equal(t, map[string]int{"gcp": 3}, proj.Concurrency)
+	assert.Equal(t, ProjectProviderSettings{
+		Concurrency: 5,
+		QPS:         2.5,
+		Burst:       5,
+		Retry:       &ProjectRetryPolicy{MaxAttempts: 5, MaxDelay: "30s", Budget: 20},
+	}, proj.Providers["aws"])
+
+	for _, settings := range []ProjectProviderSettings{
+		{Concurrency: -1},
+		{QPS: -1},
+		{Burst: -1},
+		{Retry: &ProjectRetryPolicy{Budget: -1}},
+		{Retry: &ProjectRetryPolicy{InitialDelay: "soon"}},
+	} {
+		proj.Providers["azure"] = settings
+		assert.Error(t, proj.Validate())
+	}
+	delete(proj.Providers, "azure")
+
+	// A package's concurrency limit and retry policy may each be set in only one place.
+	proj.Concurrency["aws"] = 2
+	assert.Error(t, proj.Validate())
+	delete(proj.Concurrency, "aws")
+	proj.Concurrency["gcp"] = 0
+	assert.Error(t, proj.Validate())
+	proj.Concurrency["gcp"] = 3
+	proj.Retries.Providers["aws"] = ProjectRetryPolicy{MaxAttempts: 2}
+	assert.Error(t, proj.Validate())
+	delete(proj.Retries.Providers, "aws")
+	assert.NoError(t, proj.Validate())
+
+	// Budgets only apply to provider packages as a whole.
+	proj.Retries.Providers["aws:create"] = ProjectRetryPolicy{Budget: 10}
+	assert.Error(t, proj.Validate())
+	proj.Retries.Providers["aws:create"] = ProjectRetryPolicy{}
+	proj.Retries.Default.Budget = 10
+	assert.Error(t, proj.Validate())
+}
+
+func TestProjectProviderHooksValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: hooks
+runtime: nodejs
+providerHooks:
+  - command: ./change-window.sh
+    operations: [update, delete]
+  - command: ./naming-policy.sh
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	assert.True(t, proj.ProviderHooks[0].Runs("delete"))
+	assert.False(t, proj.ProviderHooks[0].Runs("create"))
+	assert.True(t, proj.ProviderHooks[1].Runs("create"))
+
+	proj.ProviderHooks[1].Operations = []string{"read"}
+	assert.Error(t, proj.Validate())
+	proj.ProviderHooks[1] = ProjectProviderHook{Command: " "}
+	assert.Error(t, proj.Validate())
+}
+
+func TestProjectProviderHealthValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: health
+runtime: nodejs
+providerHealth:
+  callTimeout: 5m
+  maxRestarts: 3
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	assert.Equal(t, 5*time.Minute, proj.ProviderHealth.Timeout())
+	assert.Equal(t, 3, proj.ProviderHealth.Restarts())
+
+	proj.ProviderHealth.MaxRestarts = nil
+	assert.Equal(t, 0, proj.ProviderHealth.Restarts())
+	negative := -1
+	proj.ProviderHealth.MaxRestarts = &negative
+	assert.Error(t, proj.Validate())
+	proj.ProviderHealth = &ProjectProviderHealth{CallTimeout: "0s"}
+	assert.Error(t, proj.Validate())
+
+	// Without settings, calls have no timeout and providers are not restarted.
+	var defaults *ProjectProviderHealth
+	assert.Equal(t, time.Duration(0), defaults.Timeout())
+	assert.Equal(t, 0, defaults.Restarts())
+}
+
+func TestProjectStackCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pulumi-stack-credentials")
+	assert.NoError(t, err)
+	defer func() { contract.IgnoreError(os.RemoveAll(dir)) }()
+
+	err = ioutil.WriteFile(filepath.Join(dir, "prod-sa.json"), []byte(`{"type":"service_account"}`), 0600)
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "Pulumi.prod.yaml")
+	err = ioutil.WriteFile(path, []byte(`
+credentials:
+  aws:
+    values:
+      profile: prod
+  gcp:
+    files:
+      credentials: prod-sa.json
+`), 0600)
+	assert.NoError(t, err)
+
+	ps, err := LoadProjectStack(path)
+	assert.NoError(t, err)
+	creds, err := ps.ResolveCredentials(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"profile": "prod"}, creds["aws"])
+	assert.Equal(t, map[string]string{"credentials": `{"type":"service_account"}`}, creds["gcp"])
+
+	// A missing credential file is an error.
+	ps.Credentials["gcp"].Files["credentials"] = "missing.json"
+	_, err = ps.ResolveCredentials(dir)
+	assert.Error(t, err)
+
+	// A setting may not have both a value and a file.
+	err = ioutil.WriteFile(path, []byte(`
+credentials:
+  aws:
+    values:
+      profile: prod
+    files:
+      profile: profile.txt
+`), 0600)
+	assert.NoError(t, err)
+	_, err = LoadProjectStack(path)
+	assert.Error(t, err)
+}
+
+func TestProjectRolloutValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: rollout
+runtime: nodejs
+rollout:
+  - name: canary
+    resources: ["web-0"]
+    verify:
+      - ./smoke-test.sh
+    pause: 5m
+  - name: fleet
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	assert.Equal(t, []string{"web-0"}, proj.Rollout[0].Resources)
+	assert.Equal(t, "5m", proj.Rollout[0].Pause)
+
+	proj.Rollout[0].Pause = "soon"
+	assert.Error(t, proj.Validate())
+
+	// Only the last phase may match every resource.
+	proj.Rollout[0].Pause = ""
+	proj.Rollout = append(proj.Rollout, ProjectRolloutPhase{Resources: []string{"db-*"}})
+	assert.Error(t, proj.Validate())
+
+	proj.Rollout = []ProjectRolloutPhase{{Resources: []string{"web-["}}}
+	assert.Error(t, proj.Validate())
+}
+
+func TestProjectBackendValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: backend
+runtime: nodejs
+backend:
+  url: file://~
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	assert.Equal(t, "file://~", proj.Backend.URL)
+
+	proj.Backend.URL = ""
+	assert.Error(t, proj.Validate())
+}
+
+func TestProjectWebhooksValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: webhooks
+runtime: nodejs
+webhooks:
+  - url: https://chat.acmecorp.com/hooks/pulumi
+    secretEnv: CHAT_WEBHOOK_SECRET
+    events: [succeeded, failed]
+  - url: http://audit.internal/events
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	assert.Equal(t, "CHAT_WEBHOOK_SECRET", proj.Webhooks[0].SecretEnv)
+	assert.False(t, proj.Webhooks[0].Wants(WebhookUpdateStarted))
+	assert.True(t, proj.Webhooks[0].Wants(WebhookUpdateFailed))
+	assert.True(t, proj.Webhooks[1].Wants(WebhookUpdateStarted))
+
+	proj.Webhooks[1].Events = []string{"finished"}
+	assert.Error(t, proj.Validate())
+
+	proj.Webhooks[1] = ProjectWebhook{URL: "ftp://audit.internal/events"}
+	assert.Error(t, proj.Validate())
+}
+
+func TestProjectPluginsValidate(t *testing.T) {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: plugins
+runtime: nodejs
+plugins:
+  - kind: resource
+    name: aws
+    version: v0.17.1
+  - kind: analyzer
+    name: policy
+    version: 1.0.0
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	plugins, err := proj.RequiredPlugins()
+	assert.NoError(t, err)
+	assert.Len(t, plugins, 2)
+	assert.Equal(t, ResourcePlugin, plugins[0].Kind)
+	assert.Equal(t, "aws", plugins[0].Name)
+	assert.Equal(t, "0.17.1", plugins[0].Version.String())
+
+	proj.Plugins[1].Version = "latest"
+	assert.Error(t, proj.Validate())
+
+	proj.Plugins[1] = ProjectPlugin{Kind: "provider", Name: "aws", Version: "0.17.1"}
+	assert.Error(t, proj.Validate())
+}
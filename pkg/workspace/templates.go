@@ -15,6 +15,8 @@
 package workspace
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -45,6 +47,14 @@ const (
 	// pulumiLocalTemplatePathEnvVar is a path to the folder where templates are stored.
 	// It is used in sandboxed environments where the classic template folder may not be writable.
 	pulumiLocalTemplatePathEnvVar = "PULUMI_TEMPLATE_PATH"
+
+	// PulumiTemplateGalleryEnvVar is the URL of a Git repository, or the path of a local directory, containing the
+	// templates to offer instead of Pulumi's own, e.g. a gallery of starters published within an organization.
+	PulumiTemplateGalleryEnvVar = "PULUMI_TEMPLATE_GALLERY"
+
+	// templateGalleriesDir is the directory, next to the template directory, in which template galleries other than
+	// Pulumi's own are cloned.
+	templateGalleriesDir = "template-galleries"
 )
 
 // TemplateRepository represents a repository of templates.
@@ -150,14 +160,107 @@ func IsTemplateURL(templateNameOrURL string) bool {
 	return strings.HasPrefix(templateNameOrURL, "https://")
 }
 
-// RetrieveTemplates retrieves a "template repository" based on the specified name or URL.
+// IsTemplatePath returns true if templateNameOrURL is the path of a local directory rather than the name of a template
+// in the gallery, i.e. if it starts with "." or contains a path separator.
+func IsTemplatePath(templateNameOrURL string) bool {
+	return strings.HasPrefix(templateNameOrURL, ".") || strings.ContainsRune(templateNameOrURL, '/') ||
+		strings.ContainsRune(templateNameOrURL, filepath.Separator)
+}
+
+// TemplateGallery returns the URL or path of the gallery of templates from which templates are retrieved by name:
+// the value of PULUMI_TEMPLATE_GALLERY, if it is set, or Pulumi's own templates repository otherwise.
+func TemplateGallery() string {
+	if gallery := os.Getenv(PulumiTemplateGalleryEnvVar); gallery != "" {
+		return gallery
+	}
+	return pulumiTemplateGitRepository
+}
+
+// RetrieveTemplates retrieves a "template repository" based on the specified name, URL, or local path.
 func RetrieveTemplates(templateNameOrURL string, offline bool) (TemplateRepository, error) {
 	if IsTemplateURL(templateNameOrURL) {
 		return retrieveURLTemplates(templateNameOrURL, offline)
 	}
+	if IsTemplatePath(templateNameOrURL) {
+		return retrieveLocalTemplates(templateNameOrURL)
+	}
+	if gallery := TemplateGallery(); gallery != pulumiTemplateGitRepository {
+		return retrieveGalleryTemplates(gallery, templateNameOrURL, offline)
+	}
 	return retrievePulumiTemplates(templateNameOrURL, offline)
 }
 
+// retrieveLocalTemplates retrieves the "template repository" in the local directory at the specified path.
+func retrieveLocalTemplates(path string) (TemplateRepository, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return TemplateRepository{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return TemplateRepository{}, errors.Errorf("template directory %s does not exist", path)
+		}
+		return TemplateRepository{}, err
+	}
+	if !info.IsDir() {
+		return TemplateRepository{}, errors.Errorf("%s is not a directory", path)
+	}
+
+	return TemplateRepository{
+		Root:         path,
+		SubDirectory: path,
+		ShouldDelete: false,
+	}, nil
+}
+
+// retrieveGalleryTemplates retrieves the "template repository" for the gallery of templates at the specified Git URL
+// or local path. A gallery in a Git repository is cloned into a directory of its own next to the template directory,
+// and updated each time it is used unless offline is set.
+func retrieveGalleryTemplates(gallery string, templateName string, offline bool) (TemplateRepository, error) {
+	galleryDir := gallery
+	if IsTemplateURL(gallery) {
+		templateDir, err := GetTemplateDir()
+		if err != nil {
+			return TemplateRepository{}, err
+		}
+		hash := sha256.Sum256([]byte(gallery))
+		galleryDir = filepath.Join(filepath.Dir(templateDir), templateGalleriesDir, hex.EncodeToString(hash[:])[:16])
+
+		if offline {
+			if _, err = os.Stat(galleryDir); os.IsNotExist(err) {
+				return TemplateRepository{}, errors.Errorf(
+					"the template gallery %s has not been downloaded, and cannot be offline", gallery)
+			}
+		} else {
+			if err = os.MkdirAll(galleryDir, 0700); err != nil {
+				return TemplateRepository{}, err
+			}
+			if err = gitutil.GitCloneOrPull(gallery, plumbing.HEAD, galleryDir, false /*shallow*/); err != nil {
+				return TemplateRepository{}, errors.Wrapf(err, "retrieving the template gallery %s", gallery)
+			}
+		}
+	} else if info, err := os.Stat(galleryDir); err != nil || !info.IsDir() {
+		return TemplateRepository{}, errors.Errorf(
+			"the template gallery %s set by %s is neither an https:// URL nor a directory",
+			gallery, PulumiTemplateGalleryEnvVar)
+	}
+
+	subDir := galleryDir
+	if templateName != "" {
+		subDir = filepath.Join(subDir, strings.ToLower(templateName))
+		if _, err := os.Stat(subDir); os.IsNotExist(err) {
+			return TemplateRepository{}, newTemplateNotFoundError(galleryDir, templateName)
+		}
+	}
+
+	return TemplateRepository{
+		Root:         galleryDir,
+		SubDirectory: subDir,
+		ShouldDelete: false,
+	}, nil
+}
+
 // retrieveURLTemplates retrieves the "template repository" at the specified URL.
 func retrieveURLTemplates(rawurl string, offline bool) (TemplateRepository, error) {
 	if offline {
@@ -15,11 +15,92 @@
 package workspace
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func writeTestTemplate(t *testing.T, dir, name, description string) {
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name, "Pulumi.yaml"), []byte(
+		"name: ${PROJECT}\nruntime: nodejs\ntemplate:\n  description: "+description+"\n"+
+			"  config:\n    team:\n      description: The owning team\n"), 0600))
+}
+
+func TestIsTemplatePath(t *testing.T) {
+	assert.True(t, IsTemplatePath("."))
+	assert.True(t, IsTemplatePath("./starter"))
+	assert.True(t, IsTemplatePath("../starters/web"))
+	assert.True(t, IsTemplatePath("/opt/starters/web"))
+	assert.True(t, IsTemplatePath("starters/web"))
+	assert.False(t, IsTemplatePath("aws-typescript"))
+}
+
+func TestRetrieveLocalTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeTestTemplate(t, dir, "web", "A web service")
+
+	// A directory containing a template.
+	repo, err := RetrieveTemplates(filepath.Join(dir, "web"), true /*offline*/)
+	assert.NoError(t, err)
+	assert.False(t, repo.ShouldDelete)
+	templates, err := repo.Templates()
+	assert.NoError(t, err)
+	if assert.Len(t, templates, 1) {
+		assert.Equal(t, "web", templates[0].Name)
+		assert.Equal(t, "A web service", templates[0].Description)
+		assert.Equal(t, "The owning team", templates[0].Config["team"].Description)
+	}
+
+	_, err = RetrieveTemplates(filepath.Join(dir, "missing"), true /*offline*/)
+	assert.Error(t, err)
+}
+
+func TestRetrieveGalleryTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeTestTemplate(t, dir, "web", "A web service")
+	writeTestTemplate(t, dir, "worker", "A queue worker")
+
+	prev := os.Getenv(PulumiTemplateGalleryEnvVar)
+	defer func() { assert.NoError(t, os.Setenv(PulumiTemplateGalleryEnvVar, prev)) }()
+	assert.NoError(t, os.Setenv(PulumiTemplateGalleryEnvVar, dir))
+	assert.Equal(t, dir, TemplateGallery())
+
+	// The whole gallery.
+	repo, err := RetrieveTemplates("", true /*offline*/)
+	assert.NoError(t, err)
+	templates, err := repo.Templates()
+	assert.NoError(t, err)
+	assert.Len(t, templates, 2)
+
+	// A template in the gallery, by name.
+	repo, err = RetrieveTemplates("worker", true /*offline*/)
+	assert.NoError(t, err)
+	templates, err = repo.Templates()
+	assert.NoError(t, err)
+	if assert.Len(t, templates, 1) {
+		assert.Equal(t, "A queue worker", templates[0].Description)
+	}
+
+	_, err = RetrieveTemplates("wokrer", true /*offline*/)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "template 'wokrer' not found")
+		assert.Contains(t, err.Error(), "worker")
+	}
+
+	// A Git gallery that has never been retrieved cannot be used offline.
+	assert.NoError(t, os.Setenv(PulumiTemplateGalleryEnvVar, "https://git.example.com/starters.git"))
+	_, err = RetrieveTemplates("web", true /*offline*/)
+	assert.Error(t, err)
+}
+
 func TestGetValidDefaultProjectName(t *testing.T) {
 	// Valid names remain the same.
 	for _, name := range getValidProjectNamePrefixes() {
@@ -122,16 +122,18 @@ func (ctx *Context) Invoke(tok string, args map[string]interface{}, opts ...Invo
 		return nil, errors.New("invoke token must not be empty")
 	}
 
-	// Check for a provider option.
-	var provider string
+	// Check for provider and version options.
+	var provider, version string
 	for _, opt := range opts {
-		if opt.Provider != nil {
+		if provider == "" && opt.Provider != nil {
 			pr, err := ctx.resolveProviderReference(opt.Provider)
 			if err != nil {
 				return nil, err
 			}
 			provider = pr
-			break
+		}
+		if version == "" {
+			version = opt.Version
 		}
 	}
 
@@ -154,6 +156,7 @@ func (ctx *Context) Invoke(tok string, args map[string]interface{}, opts ...Invo
 		Tok:      tok,
 		Args:     rpcArgs,
 		Provider: provider,
+		Version:  version,
 	})
 	if err != nil {
 		glog.V(9).Infof("Invoke(%s, ...): error: %v", tok, err)
@@ -221,6 +224,7 @@ func (ctx *Context) ReadResource(
 			Parent:     inputs.parent,
 			Properties: inputs.rpcProps,
 			Provider:   inputs.provider,
+			Version:    inputs.version,
 		})
 		if err != nil {
 			glog.V(9).Infof("RegisterResource(%s, %s): error: %v", t, name, err)
@@ -293,6 +297,7 @@ func (ctx *Context) RegisterResource(
 			Provider:             inputs.provider,
 			PropertyDependencies: inputs.rpcPropertyDeps,
 			DeleteBeforeReplace:  inputs.deleteBeforeReplace,
+			Version:              inputs.version,
 		})
 		if err != nil {
 			glog.V(9).Infof("RegisterResource(%s, %s): error: %v", t, name, err)
@@ -412,6 +417,7 @@ type resourceInputs struct {
 	rpcProps            *structpb.Struct
 	rpcPropertyDeps     map[string]*pulumirpc.RegisterResourceRequest_PropertyDependencies
 	deleteBeforeReplace bool
+	version             string
 }
 
 // prepareResourceInputs prepares the inputs for a resource operation, shared between read and register.
@@ -458,6 +464,15 @@ func (ctx *Context) prepareResourceInputs(props map[string]interface{}, opts ...
 	}
 	sort.Strings(deps)
 
+	// Use the first version of the provider that the options request, if any.
+	var version string
+	for _, opt := range opts {
+		if opt.Version != "" {
+			version = opt.Version
+			break
+		}
+	}
+
 	return &resourceInputs{
 		parent:              string(parent),
 		deps:                deps,
@@ -466,6 +481,7 @@ func (ctx *Context) prepareResourceInputs(props map[string]interface{}, opts ...
 		rpcProps:            rpcProps,
 		rpcPropertyDeps:     rpcPropertyDeps,
 		deleteBeforeReplace: deleteBeforeReplace,
+		version:             version,
 	}, nil
 }
 
@@ -62,10 +62,15 @@ type ResourceOpt struct {
 	Provider ProviderResource
 	// DeleteBeforeReplace, when set to true, ensures that this resource is deleted prior to replacement.
 	DeleteBeforeReplace bool
+	// Version is an optional version of the provider to use for this resource's CRUD operations when no provider is
+	// supplied. Packages built against a particular version of their provider set this to keep that version's behavior.
+	Version string
 }
 
 // InvokeOpt contains optional settings that control an invoke's behavior.
 type InvokeOpt struct {
 	// Provider is an optional provider resource to use for this invoke.
 	Provider ProviderResource
+	// Version is an optional version of the provider to use for this invoke when no provider is supplied.
+	Version string
 }
@@ -138,6 +138,12 @@ async function diffRPC(call: any, callback: any): Promise<void> {
             if (result.replaces && result.replaces.length !== 0) {
                 resp.setReplacesList(result.replaces);
             }
+            if (result.replaceReasons) {
+                const reasons = resp.getReplacereasonsMap();
+                for (const k of Object.keys(result.replaceReasons)) {
+                    reasons.set(k, result.replaceReasons[k]);
+                }
+            }
             if (result.deleteBeforeReplace) {
                 resp.setDeletebeforereplace(result.deleteBeforeReplace);
             }
@@ -60,6 +60,12 @@ export interface DiffResult {
      */
     readonly replaces?: string[];
 
+    /**
+     * Optional explanations, keyed by property name, of why changes to the properties in `replaces` require a
+     * replacement.  These are shown to the user alongside the planned replacement.
+     */
+    readonly replaceReasons?: Record<string, string>;
+
     /**
      * An optional list of properties that will not ever change.
      */
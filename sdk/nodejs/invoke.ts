@@ -28,4 +28,10 @@ export interface InvokeOptions {
      * invoked function's package will be used.
      */
     provider?: ProviderResource;
+
+    /**
+     * An optional version of the provider to use for this invocation when no provider is supplied. See also
+     * CustomResourceOptions.version.
+     */
+    version?: string;
 }
@@ -11,6 +11,7 @@ var jspb = require('google-protobuf');
 var goog = jspb;
 var proto = { pulumirpc: {} }, global = proto;
 
+goog.exportSymbol('proto.pulumirpc.PluginAttach', null, global);
 goog.exportSymbol('proto.pulumirpc.PluginDependency', null, global);
 goog.exportSymbol('proto.pulumirpc.PluginInfo', null, global);
 
@@ -25,12 +26,18 @@ goog.exportSymbol('proto.pulumirpc.PluginInfo', null, global);
  * @constructor
  */
 proto.pulumirpc.PluginInfo = function(opt_data) {
-  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+  jspb.Message.initialize(this, opt_data, 0, -1, proto.pulumirpc.PluginInfo.repeatedFields_, null);
 };
 goog.inherits(proto.pulumirpc.PluginInfo, jspb.Message);
 if (goog.DEBUG && !COMPILED) {
   proto.pulumirpc.PluginInfo.displayName = 'proto.pulumirpc.PluginInfo';
 }
+/**
+ * List of repeated fields within this message type.
+ * @private {!Array<number>}
+ * @const
+ */
+proto.pulumirpc.PluginInfo.repeatedFields_ = [3];
 
 
 if (jspb.Message.GENERATE_TO_OBJECT) {
@@ -60,7 +67,9 @@ proto.pulumirpc.PluginInfo.prototype.toObject = function(opt_includeInstance) {
  */
 proto.pulumirpc.PluginInfo.toObject = function(includeInstance, msg) {
   var f, obj = {
-    version: jspb.Message.getFieldWithDefault(msg, 1, "")
+    version: jspb.Message.getFieldWithDefault(msg, 1, ""),
+    interfaceversion: jspb.Message.getFieldWithDefault(msg, 2, 0),
+    capabilitiesList: jspb.Message.getRepeatedField(msg, 3)
   };
 
   if (includeInstance) {
@@ -101,6 +110,14 @@ proto.pulumirpc.PluginInfo.deserializeBinaryFromReader = function(msg, reader) {
       var value = /** @type {string} */ (reader.readString());
       msg.setVersion(value);
       break;
+    case 2:
+      var value = /** @type {number} */ (reader.readInt32());
+      msg.setInterfaceversion(value);
+      break;
+    case 3:
+      var value = /** @type {string} */ (reader.readString());
+      msg.addCapabilities(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -137,6 +154,20 @@ proto.pulumirpc.PluginInfo.serializeBinaryToWriter = function(message, writer) {
       f
     );
   }
+  f = message.getInterfaceversion();
+  if (f !== 0) {
+    writer.writeInt32(
+      2,
+      f
+    );
+  }
+  f = message.getCapabilitiesList();
+  if (f.length > 0) {
+    writer.writeRepeatedString(
+      3,
+      f
+    );
+  }
 };
 
 
@@ -155,6 +186,192 @@ proto.pulumirpc.PluginInfo.prototype.setVersion = function(value) {
 };
 
 
+/**
+ * optional int32 interfaceVersion = 2;
+ * @return {number}
+ */
+proto.pulumirpc.PluginInfo.prototype.getInterfaceversion = function() {
+  return /** @type {number} */ (jspb.Message.getFieldWithDefault(this, 2, 0));
+};
+
+
+/** @param {number} value */
+proto.pulumirpc.PluginInfo.prototype.setInterfaceversion = function(value) {
+  jspb.Message.setProto3IntField(this, 2, value);
+};
+
+
+/**
+ * repeated string capabilities = 3;
+ * @return {!Array.<string>}
+ */
+proto.pulumirpc.PluginInfo.prototype.getCapabilitiesList = function() {
+  return /** @type {!Array.<string>} */ (jspb.Message.getRepeatedField(this, 3));
+};
+
+
+/** @param {!Array.<string>} value */
+proto.pulumirpc.PluginInfo.prototype.setCapabilitiesList = function(value) {
+  jspb.Message.setField(this, 3, value || []);
+};
+
+
+/**
+ * @param {!string} value
+ * @param {number=} opt_index
+ */
+proto.pulumirpc.PluginInfo.prototype.addCapabilities = function(value, opt_index) {
+  jspb.Message.addToRepeatedField(this, 3, value, opt_index);
+};
+
+
+proto.pulumirpc.PluginInfo.prototype.clearCapabilitiesList = function() {
+  this.setCapabilitiesList([]);
+};
+
+
+
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.pulumirpc.PluginAttach = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.pulumirpc.PluginAttach, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.pulumirpc.PluginAttach.displayName = 'proto.pulumirpc.PluginAttach';
+}
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.pulumirpc.PluginAttach.prototype.toObject = function(opt_includeInstance) {
+  return proto.pulumirpc.PluginAttach.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.pulumirpc.PluginAttach} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.pulumirpc.PluginAttach.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    address: jspb.Message.getFieldWithDefault(msg, 1, "")
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.pulumirpc.PluginAttach}
+ */
+proto.pulumirpc.PluginAttach.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.pulumirpc.PluginAttach;
+  return proto.pulumirpc.PluginAttach.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.pulumirpc.PluginAttach} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.pulumirpc.PluginAttach}
+ */
+proto.pulumirpc.PluginAttach.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setAddress(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.pulumirpc.PluginAttach.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.pulumirpc.PluginAttach.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.pulumirpc.PluginAttach} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.pulumirpc.PluginAttach.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getAddress();
+  if (f.length > 0) {
+    writer.writeString(
+      1,
+      f
+    );
+  }
+};
+
+
+/**
+ * optional string address = 1;
+ * @return {string}
+ */
+proto.pulumirpc.PluginAttach.prototype.getAddress = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 1, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.PluginAttach.prototype.setAddress = function(value) {
+  jspb.Message.setProto3StringField(this, 1, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
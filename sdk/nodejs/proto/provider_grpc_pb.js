@@ -121,6 +121,50 @@ function deserialize_pulumirpc_DiffResponse(buffer_arg) {
   return provider_pb.DiffResponse.deserializeBinary(new Uint8Array(buffer_arg));
 }
 
+function serialize_pulumirpc_GetLogsRequest(arg) {
+  if (!(arg instanceof provider_pb.GetLogsRequest)) {
+    throw new Error('Expected argument of type pulumirpc.GetLogsRequest');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_GetLogsRequest(buffer_arg) {
+  return provider_pb.GetLogsRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_GetLogsResponse(arg) {
+  if (!(arg instanceof provider_pb.GetLogsResponse)) {
+    throw new Error('Expected argument of type pulumirpc.GetLogsResponse');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_GetLogsResponse(buffer_arg) {
+  return provider_pb.GetLogsResponse.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_GetSchemaRequest(arg) {
+  if (!(arg instanceof provider_pb.GetSchemaRequest)) {
+    throw new Error('Expected argument of type pulumirpc.GetSchemaRequest');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_GetSchemaRequest(buffer_arg) {
+  return provider_pb.GetSchemaRequest.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
+function serialize_pulumirpc_GetSchemaResponse(arg) {
+  if (!(arg instanceof provider_pb.GetSchemaResponse)) {
+    throw new Error('Expected argument of type pulumirpc.GetSchemaResponse');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_GetSchemaResponse(buffer_arg) {
+  return provider_pb.GetSchemaResponse.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
 function serialize_pulumirpc_InvokeRequest(arg) {
   if (!(arg instanceof provider_pb.InvokeRequest)) {
     throw new Error('Expected argument of type pulumirpc.InvokeRequest');
@@ -143,6 +187,17 @@ function deserialize_pulumirpc_InvokeResponse(buffer_arg) {
   return provider_pb.InvokeResponse.deserializeBinary(new Uint8Array(buffer_arg));
 }
 
+function serialize_pulumirpc_PluginAttach(arg) {
+  if (!(arg instanceof plugin_pb.PluginAttach)) {
+    throw new Error('Expected argument of type pulumirpc.PluginAttach');
+  }
+  return Buffer.from(arg.serializeBinary());
+}
+
+function deserialize_pulumirpc_PluginAttach(buffer_arg) {
+  return plugin_pb.PluginAttach.deserializeBinary(new Uint8Array(buffer_arg));
+}
+
 function serialize_pulumirpc_PluginInfo(arg) {
   if (!(arg instanceof plugin_pb.PluginInfo)) {
     throw new Error('Expected argument of type pulumirpc.PluginInfo');
@@ -340,7 +395,11 @@ var ResourceProviderService = exports.ResourceProviderService = {
     responseSerialize: serialize_google_protobuf_Empty,
     responseDeserialize: deserialize_google_protobuf_Empty,
   },
-  // GetPluginInfo returns generic information about this plugin, like its version.
+  // GetPluginInfo returns generic information about this plugin, like its version. The engine also uses the interface
+  // version and capabilities that it returns to negotiate how it talks to the provider: it uses the older of its own
+  // and the provider's interface versions, and those capabilities that both support, and sends the results to the
+  // provider in its ConfigureRequest. Providers that do not report an interface version are assumed to support
+  // version 1, and providers must continue to support every interface version older than the one they report.
   getPluginInfo: {
     path: '/pulumirpc.ResourceProvider/GetPluginInfo',
     requestStream: false,
@@ -352,6 +411,46 @@ var ResourceProviderService = exports.ResourceProviderService = {
     responseSerialize: serialize_pulumirpc_PluginInfo,
     responseDeserialize: deserialize_pulumirpc_PluginInfo,
   },
+  // GetSchema fetches the schema for this resource provider, which describes its configuration, resources, and
+  // functions, for use by tools such as editors and documentation generators.
+  getSchema: {
+    path: '/pulumirpc.ResourceProvider/GetSchema',
+    requestStream: false,
+    responseStream: false,
+    requestType: provider_pb.GetSchemaRequest,
+    responseType: provider_pb.GetSchemaResponse,
+    requestSerialize: serialize_pulumirpc_GetSchemaRequest,
+    requestDeserialize: deserialize_pulumirpc_GetSchemaRequest,
+    responseSerialize: serialize_pulumirpc_GetSchemaResponse,
+    responseDeserialize: deserialize_pulumirpc_GetSchemaResponse,
+  },
+  // Attach tells a provider that was launched outside of the engine, rather than by it, the address of the engine's
+  // RPC server, which the engine would otherwise have passed to the provider on its command line. The engine calls
+  // it before any other method, and only on providers that it attaches to rather than launches.
+  attach: {
+    path: '/pulumirpc.ResourceProvider/Attach',
+    requestStream: false,
+    responseStream: false,
+    requestType: plugin_pb.PluginAttach,
+    responseType: google_protobuf_empty_pb.Empty,
+    requestSerialize: serialize_pulumirpc_PluginAttach,
+    requestDeserialize: deserialize_pulumirpc_PluginAttach,
+    responseSerialize: serialize_google_protobuf_Empty,
+    responseDeserialize: deserialize_google_protobuf_Empty,
+  },
+  // GetLogs fetches the runtime logs of a resource, such as those written by a serverless function, for `pulumi logs`.
+  // Providers that do not keep logs for resources of the given type return no entries.
+  getLogs: {
+    path: '/pulumirpc.ResourceProvider/GetLogs',
+    requestStream: false,
+    responseStream: false,
+    requestType: provider_pb.GetLogsRequest,
+    responseType: provider_pb.GetLogsResponse,
+    requestSerialize: serialize_pulumirpc_GetLogsRequest,
+    requestDeserialize: deserialize_pulumirpc_GetLogsRequest,
+    responseSerialize: serialize_pulumirpc_GetLogsResponse,
+    responseDeserialize: deserialize_pulumirpc_GetLogsResponse,
+  },
 };
 
 exports.ResourceProviderClient = grpc.makeGenericClientConstructor(ResourceProviderService);
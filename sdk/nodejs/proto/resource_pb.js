@@ -18,6 +18,7 @@ goog.exportSymbol('proto.pulumirpc.ReadResourceRequest', null, global);
 goog.exportSymbol('proto.pulumirpc.ReadResourceResponse', null, global);
 goog.exportSymbol('proto.pulumirpc.RegisterResourceOutputsRequest', null, global);
 goog.exportSymbol('proto.pulumirpc.RegisterResourceRequest', null, global);
+goog.exportSymbol('proto.pulumirpc.RegisterResourceRequest.CustomTimeouts', null, global);
 goog.exportSymbol('proto.pulumirpc.RegisterResourceRequest.PropertyDependencies', null, global);
 goog.exportSymbol('proto.pulumirpc.RegisterResourceResponse', null, global);
 
@@ -80,7 +81,8 @@ proto.pulumirpc.ReadResourceRequest.toObject = function(includeInstance, msg) {
     parent: jspb.Message.getFieldWithDefault(msg, 4, ""),
     properties: (f = msg.getProperties()) && google_protobuf_struct_pb.Struct.toObject(includeInstance, f),
     dependenciesList: jspb.Message.getRepeatedField(msg, 6),
-    provider: jspb.Message.getFieldWithDefault(msg, 7, "")
+    provider: jspb.Message.getFieldWithDefault(msg, 7, ""),
+    version: jspb.Message.getFieldWithDefault(msg, 8, "")
   };
 
   if (includeInstance) {
@@ -146,6 +148,10 @@ proto.pulumirpc.ReadResourceRequest.deserializeBinaryFromReader = function(msg,
       var value = /** @type {string} */ (reader.readString());
       msg.setProvider(value);
       break;
+    case 8:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setVersion(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -225,6 +231,13 @@ proto.pulumirpc.ReadResourceRequest.serializeBinaryToWriter = function(message,
       f
     );
   }
+  f = message.getVersion();
+  if (f.length > 0) {
+    writer.writeString(
+      8,
+      f
+    );
+  }
 };
 
 
@@ -362,6 +375,21 @@ proto.pulumirpc.ReadResourceRequest.prototype.setProvider = function(value) {
 };
 
 
+/**
+ * optional string version = 8;
+ * @return {string}
+ */
+proto.pulumirpc.ReadResourceRequest.prototype.getVersion = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 8, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.ReadResourceRequest.prototype.setVersion = function(value) {
+  jspb.Message.setProto3StringField(this, 8, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -571,7 +599,7 @@ if (goog.DEBUG && !COMPILED) {
  * @private {!Array<number>}
  * @const
  */
-proto.pulumirpc.RegisterResourceRequest.repeatedFields_ = [7];
+proto.pulumirpc.RegisterResourceRequest.repeatedFields_ = [7,11];
 
 
 
@@ -611,7 +639,11 @@ proto.pulumirpc.RegisterResourceRequest.toObject = function(includeInstance, msg
     dependenciesList: jspb.Message.getRepeatedField(msg, 7),
     provider: jspb.Message.getFieldWithDefault(msg, 8, ""),
     propertydependenciesMap: (f = msg.getPropertydependenciesMap()) ? f.toObject(includeInstance, proto.pulumirpc.RegisterResourceRequest.PropertyDependencies.toObject) : [],
-    deletebeforereplace: jspb.Message.getFieldWithDefault(msg, 10, false)
+    deletebeforereplace: jspb.Message.getFieldWithDefault(msg, 10, false),
+    ignorechangesList: jspb.Message.getRepeatedField(msg, 11),
+    customtimeouts: (f = msg.getCustomtimeouts()) && proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.toObject(includeInstance, f),
+    importid: jspb.Message.getFieldWithDefault(msg, 13, ""),
+    version: jspb.Message.getFieldWithDefault(msg, 14, "")
   };
 
   if (includeInstance) {
@@ -691,6 +723,23 @@ proto.pulumirpc.RegisterResourceRequest.deserializeBinaryFromReader = function(m
       var value = /** @type {boolean} */ (reader.readBool());
       msg.setDeletebeforereplace(value);
       break;
+    case 11:
+      var value = /** @type {string} */ (reader.readString());
+      msg.addIgnorechanges(value);
+      break;
+    case 12:
+      var value = new proto.pulumirpc.RegisterResourceRequest.CustomTimeouts;
+      reader.readMessage(value,proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.deserializeBinaryFromReader);
+      msg.setCustomtimeouts(value);
+      break;
+    case 13:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setImportid(value);
+      break;
+    case 14:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setVersion(value);
+      break;
     default:
       reader.skipField();
       break;
@@ -788,6 +837,35 @@ proto.pulumirpc.RegisterResourceRequest.serializeBinaryToWriter = function(messa
       f
     );
   }
+  f = message.getIgnorechangesList();
+  if (f.length > 0) {
+    writer.writeRepeatedString(
+      11,
+      f
+    );
+  }
+  f = message.getCustomtimeouts();
+  if (f != null) {
+    writer.writeMessage(
+      12,
+      f,
+      proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.serializeBinaryToWriter
+    );
+  }
+  f = message.getImportid();
+  if (f.length > 0) {
+    writer.writeString(
+      13,
+      f
+    );
+  }
+  f = message.getVersion();
+  if (f.length > 0) {
+    writer.writeString(
+      14,
+      f
+    );
+  }
 };
 
 
@@ -954,6 +1032,201 @@ proto.pulumirpc.RegisterResourceRequest.PropertyDependencies.prototype.clearUrns
 };
 
 
+/**
+ * Generated by JsPbCodeGenerator.
+ * @param {Array=} opt_data Optional initial data array, typically from a
+ * server response, or constructed directly in Javascript. The array is used
+ * in place and becomes part of the constructed object. It is not cloned.
+ * If no data is provided, the constructed object will be empty, but still
+ * valid.
+ * @extends {jspb.Message}
+ * @constructor
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts = function(opt_data) {
+  jspb.Message.initialize(this, opt_data, 0, -1, null, null);
+};
+goog.inherits(proto.pulumirpc.RegisterResourceRequest.CustomTimeouts, jspb.Message);
+if (goog.DEBUG && !COMPILED) {
+  proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.displayName = 'proto.pulumirpc.RegisterResourceRequest.CustomTimeouts';
+}
+
+
+if (jspb.Message.GENERATE_TO_OBJECT) {
+/**
+ * Creates an object representation of this proto suitable for use in Soy templates.
+ * Field names that are reserved in JavaScript and will be renamed to pb_name.
+ * To access a reserved field use, foo.pb_<name>, eg, foo.pb_default.
+ * For the list of reserved names please see:
+ *     com.google.apps.jspb.JsClassTemplate.JS_RESERVED_WORDS.
+ * @param {boolean=} opt_includeInstance Whether to include the JSPB instance
+ *     for transitional soy proto support: http://goto/soy-param-migration
+ * @return {!Object}
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.prototype.toObject = function(opt_includeInstance) {
+  return proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.toObject(opt_includeInstance, this);
+};
+
+
+/**
+ * Static version of the {@see toObject} method.
+ * @param {boolean|undefined} includeInstance Whether to include the JSPB
+ *     instance for transitional soy proto support:
+ *     http://goto/soy-param-migration
+ * @param {!proto.pulumirpc.RegisterResourceRequest.CustomTimeouts} msg The msg instance to transform.
+ * @return {!Object}
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.toObject = function(includeInstance, msg) {
+  var f, obj = {
+    create: jspb.Message.getFieldWithDefault(msg, 1, ""),
+    update: jspb.Message.getFieldWithDefault(msg, 2, ""),
+    pb_delete: jspb.Message.getFieldWithDefault(msg, 3, "")
+  };
+
+  if (includeInstance) {
+    obj.$jspbMessageInstance = msg;
+  }
+  return obj;
+};
+}
+
+
+/**
+ * Deserializes binary data (in protobuf wire format).
+ * @param {jspb.ByteSource} bytes The bytes to deserialize.
+ * @return {!proto.pulumirpc.RegisterResourceRequest.CustomTimeouts}
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.deserializeBinary = function(bytes) {
+  var reader = new jspb.BinaryReader(bytes);
+  var msg = new proto.pulumirpc.RegisterResourceRequest.CustomTimeouts;
+  return proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.deserializeBinaryFromReader(msg, reader);
+};
+
+
+/**
+ * Deserializes binary data (in protobuf wire format) from the
+ * given reader into the given message object.
+ * @param {!proto.pulumirpc.RegisterResourceRequest.CustomTimeouts} msg The message object to deserialize into.
+ * @param {!jspb.BinaryReader} reader The BinaryReader to use.
+ * @return {!proto.pulumirpc.RegisterResourceRequest.CustomTimeouts}
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.deserializeBinaryFromReader = function(msg, reader) {
+  while (reader.nextField()) {
+    if (reader.isEndGroup()) {
+      break;
+    }
+    var field = reader.getFieldNumber();
+    switch (field) {
+    case 1:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setCreate(value);
+      break;
+    case 2:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setUpdate(value);
+      break;
+    case 3:
+      var value = /** @type {string} */ (reader.readString());
+      msg.setDelete(value);
+      break;
+    default:
+      reader.skipField();
+      break;
+    }
+  }
+  return msg;
+};
+
+
+/**
+ * Serializes the message to binary data (in protobuf wire format).
+ * @return {!Uint8Array}
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.prototype.serializeBinary = function() {
+  var writer = new jspb.BinaryWriter();
+  proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.serializeBinaryToWriter(this, writer);
+  return writer.getResultBuffer();
+};
+
+
+/**
+ * Serializes the given message to binary data (in protobuf wire
+ * format), writing to the given BinaryWriter.
+ * @param {!proto.pulumirpc.RegisterResourceRequest.CustomTimeouts} message
+ * @param {!jspb.BinaryWriter} writer
+ * @suppress {unusedLocalVariables} f is only used for nested messages
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.serializeBinaryToWriter = function(message, writer) {
+  var f = undefined;
+  f = message.getCreate();
+  if (f.length > 0) {
+    writer.writeString(
+      1,
+      f
+    );
+  }
+  f = message.getUpdate();
+  if (f.length > 0) {
+    writer.writeString(
+      2,
+      f
+    );
+  }
+  f = message.getDelete();
+  if (f.length > 0) {
+    writer.writeString(
+      3,
+      f
+    );
+  }
+};
+
+
+/**
+ * optional string create = 1;
+ * @return {string}
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.prototype.getCreate = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 1, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.prototype.setCreate = function(value) {
+  jspb.Message.setProto3StringField(this, 1, value);
+};
+
+
+/**
+ * optional string update = 2;
+ * @return {string}
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.prototype.getUpdate = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 2, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.prototype.setUpdate = function(value) {
+  jspb.Message.setProto3StringField(this, 2, value);
+};
+
+
+/**
+ * optional string delete = 3;
+ * @return {string}
+ */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.prototype.getDelete = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 3, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.RegisterResourceRequest.CustomTimeouts.prototype.setDelete = function(value) {
+  jspb.Message.setProto3StringField(this, 3, value);
+};
+
+
 /**
  * optional string type = 1;
  * @return {string}
@@ -1142,6 +1415,95 @@ proto.pulumirpc.RegisterResourceRequest.prototype.setDeletebeforereplace = funct
 };
 
 
+/**
+ * repeated string ignoreChanges = 11;
+ * @return {!Array.<string>}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getIgnorechangesList = function() {
+  return /** @type {!Array.<string>} */ (jspb.Message.getRepeatedField(this, 11));
+};
+
+
+/** @param {!Array.<string>} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setIgnorechangesList = function(value) {
+  jspb.Message.setField(this, 11, value || []);
+};
+
+
+/**
+ * @param {!string} value
+ * @param {number=} opt_index
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.addIgnorechanges = function(value, opt_index) {
+  jspb.Message.addToRepeatedField(this, 11, value, opt_index);
+};
+
+
+proto.pulumirpc.RegisterResourceRequest.prototype.clearIgnorechangesList = function() {
+  this.setIgnorechangesList([]);
+};
+
+
+/**
+ * optional CustomTimeouts customTimeouts = 12;
+ * @return {?proto.pulumirpc.RegisterResourceRequest.CustomTimeouts}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getCustomtimeouts = function() {
+  return /** @type{?proto.pulumirpc.RegisterResourceRequest.CustomTimeouts} */ (
+    jspb.Message.getWrapperField(this, proto.pulumirpc.RegisterResourceRequest.CustomTimeouts, 12));
+};
+
+
+/** @param {?proto.pulumirpc.RegisterResourceRequest.CustomTimeouts|undefined} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setCustomtimeouts = function(value) {
+  jspb.Message.setWrapperField(this, 12, value);
+};
+
+
+proto.pulumirpc.RegisterResourceRequest.prototype.clearCustomtimeouts = function() {
+  this.setCustomtimeouts(undefined);
+};
+
+
+/**
+ * Returns whether this field is set.
+ * @return {!boolean}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.hasCustomtimeouts = function() {
+  return jspb.Message.getField(this, 12) != null;
+};
+
+
+/**
+ * optional string importId = 13;
+ * @return {string}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getImportid = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 13, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setImportid = function(value) {
+  jspb.Message.setProto3StringField(this, 13, value);
+};
+
+
+/**
+ * optional string version = 14;
+ * @return {string}
+ */
+proto.pulumirpc.RegisterResourceRequest.prototype.getVersion = function() {
+  return /** @type {string} */ (jspb.Message.getFieldWithDefault(this, 14, ""));
+};
+
+
+/** @param {string} value */
+proto.pulumirpc.RegisterResourceRequest.prototype.setVersion = function(value) {
+  jspb.Message.setProto3StringField(this, 14, value);
+};
+
+
 
 /**
  * Generated by JsPbCodeGenerator.
@@ -15,6 +15,7 @@
 import { ResourceError, RunError } from "./errors";
 import { Input, Inputs, Output } from "./output";
 import { readResource, registerResource, registerResourceOutputs } from "./runtime/resource";
+import { getStackResource } from "./runtime/settings";
 import * as utils from "./utils";
 
 export type ID = string;  // a provider-assigned ID.
@@ -94,6 +95,12 @@ export abstract class Resource {
      // tslint:disable-next-line:variable-name
     /* @internal */ private readonly __providers: Record<string, ProviderResource>;
 
+    /**
+     * The transformations to apply to this resource and to its children, in the order in which they are applied.
+     */
+    // tslint:disable-next-line:variable-name
+    /* @internal */ public readonly __transformations: ResourceTransformation[];
+
     public static isInstance(obj: any): obj is Resource {
         return utils.isInstance<Resource>(obj, "__pulumiResource");
     }
@@ -133,6 +140,24 @@ export abstract class Resource {
             throw new ResourceError("Missing resource name argument (for URN creation)", opts.parent);
         }
 
+        // Before anything else, apply any transformations registered for this resource, its ancestors, or the
+        // stack. The resource's own transformations run first so that those of its ancestors have the final say.
+        const inheritFrom = opts.parent || getStackResource();
+        this.__transformations = [
+            ...(opts.transformations || []),
+            ...(inheritFrom ? inheritFrom.__transformations : []),
+        ];
+        for (const transformation of this.__transformations) {
+            const tres = transformation({ resource: this, type: t, name, props, opts });
+            if (tres) {
+                if (tres.opts.parent !== opts.parent) {
+                    throw new ResourceError("Transformations cannot change the parent of a resource", opts.parent);
+                }
+                props = tres.props;
+                opts = tres.opts;
+            }
+        }
+
         // Check the parent type if one exists and fill in any default options.
         this.__providers = {};
         if (opts.parent) {
@@ -208,6 +233,87 @@ export interface ResourceOptions {
      * When set to true, protect ensures this resource cannot be deleted.
      */
     protect?: boolean;
+    /**
+     * Ignore changes to any of the specified properties when diffing this resource. Each property is named by a
+     * dotted path, e.g. "tags.owner" refers to the "owner" property of the "tags" object.
+     */
+    ignoreChanges?: string[];
+    /**
+     * An optional customTimeouts configuration block.
+     */
+    customTimeouts?: CustomTimeouts;
+    /**
+     * Optional transformations to apply to this resource and to all of its children. Transformations run before the
+     * resource is registered and may modify its properties and options, or veto its creation by throwing.
+     */
+    transformations?: ResourceTransformation[];
+}
+
+/**
+ * CustomTimeouts overrides the default timeouts of a resource's create, update and delete operations. Each timeout is
+ * a duration string such as "5m", "40s" or "1h30m"; operations without a custom timeout use the default.
+ */
+export interface CustomTimeouts {
+    /**
+     * The optional create timeout.
+     */
+    create?: string;
+    /**
+     * The optional update timeout.
+     */
+    update?: string;
+    /**
+     * The optional delete timeout.
+     */
+    delete?: string;
+}
+
+/**
+ * ResourceTransformation is a callback that is invoked with the type, name, properties and options of a resource
+ * before it is registered. It may return new properties and options to use in place of the originals, or undefined
+ * to leave the resource unchanged. A transformation may prevent a resource from being created by throwing an error.
+ */
+export type ResourceTransformation = (args: ResourceTransformationArgs) => ResourceTransformationResult | undefined;
+
+/**
+ * ResourceTransformationArgs is the argument bag passed to a resource transformation.
+ */
+export interface ResourceTransformationArgs {
+    /**
+     * The resource being transformed. Its outputs are not yet available.
+     */
+    resource: Resource;
+    /**
+     * The type of the resource.
+     */
+    type: string;
+    /**
+     * The name of the resource.
+     */
+    name: string;
+    /**
+     * The original properties passed to the resource constructor.
+     */
+    props: Inputs;
+    /**
+     * The original options passed to the resource constructor.
+     */
+    opts: ResourceOptions;
+}
+
+/**
+ * ResourceTransformationResult is the result that must be returned by a resource transformation that modifies a
+ * resource.
+ */
+export interface ResourceTransformationResult {
+    /**
+     * The new properties to use in place of the original ones.
+     */
+    props: Inputs;
+    /**
+     * The new options to use in place of the original ones.
+     */
+    opts: ResourceOptions;
 }
 
 /**
@@ -226,6 +332,20 @@ export interface CustomResourceOptions extends ResourceOptions {
      * is created when replacement is necessary.
      */
     deleteBeforeReplace?: boolean;
+
+    /**
+     * When provided with a resource ID, import indicates that this resource's provider should import its state from
+     * the cloud resource with the given ID instead of creating it. The inputs to the resource's constructor must
+     * match the existing resource's state, or the import will fail.
+     */
+    import?: ID;
+
+    /**
+     * An optional version of the provider to use for this resource's CRUD operations when no provider is supplied.
+     * Packages that are built against a particular version of their provider set this so that their resources keep
+     * that version's behavior even when the program uses a different version of the same provider elsewhere.
+     */
+    version?: string;
 }
 
 /**
@@ -85,6 +85,7 @@ export function readResource(res: Resource, t: string, name: string, props: Inpu
         req.setId(resolvedID);
         req.setParent(resop.parentURN);
         req.setProvider(resop.providerRef);
+        req.setVersion((<any>opts).version || "");
         req.setProperties(gstruct.Struct.fromJavaScript(resop.serializedProps));
         req.setDependenciesList(Array.from(resop.allDirectDependencyURNs));
 
@@ -144,6 +145,17 @@ export function registerResource(res: Resource, t: string, name: string, custom:
         req.setProvider(resop.providerRef);
         req.setDependenciesList(Array.from(resop.allDirectDependencyURNs));
         req.setDeletebeforereplace((<any>opts).deleteBeforeReplace || false);
+        req.setIgnorechangesList(opts.ignoreChanges || []);
+        req.setImportid((<any>opts).import || "");
+        req.setVersion((<any>opts).version || "");
+
+        if (opts.customTimeouts) {
+            const customTimeouts = new resproto.RegisterResourceRequest.CustomTimeouts();
+            customTimeouts.setCreate(opts.customTimeouts.create || "");
+            customTimeouts.setUpdate(opts.customTimeouts.update || "");
+            customTimeouts.setDelete(opts.customTimeouts.delete || "");
+            req.setCustomtimeouts(customTimeouts);
+        }
 
         const propertyDependencies = req.getPropertydependenciesMap();
         for (const [key, resourceURNs] of resop.propertyToDirectDependencyURNs) {
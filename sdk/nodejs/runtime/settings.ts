@@ -14,7 +14,7 @@
 
 import * as grpc from "grpc";
 import { RunError } from "../errors";
-import { ComponentResource, URN } from "../resource";
+import { ComponentResource, Resource, URN } from "../resource";
 import { debuggablePromise } from "./debuggable";
 
 const engrpc = require("../proto/engine_grpc_pb.js");
@@ -215,6 +215,22 @@ export function rpcKeepAlive(): () => void {
     return done!;
 }
 
+let stackResource: Resource | undefined;
+
+/**
+ * getStackResource returns the root stack resource of the running program, if it has been constructed.
+ */
+export function getStackResource(): Resource | undefined {
+    return stackResource;
+}
+
+/**
+ * setStackResource records the root stack resource of the running program.
+ */
+export function setStackResource(res: Resource): void {
+    stackResource = res;
+}
+
 let rootResource: Promise<URN> | undefined;
 
 /**
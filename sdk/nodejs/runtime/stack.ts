@@ -15,8 +15,8 @@
 import * as asset from "../asset";
 import { getProject, getStack } from "../metadata";
 import { Inputs, Output, output } from "../output";
-import { ComponentResource, Resource } from "../resource";
-import { getRootResource, setRootResource } from "./settings";
+import { ComponentResource, Resource, ResourceTransformation } from "../resource";
+import { getRootResource, getStackResource, setRootResource, setStackResource } from "./settings";
 
 /**
  * rootPulumiStackTypeName is the type name that should be used to construct the root component in the tree of Pulumi
@@ -34,6 +34,19 @@ export function runInPulumiStack(init: () => any): Promise<Inputs | undefined> {
     return stack.outputs.promise();
 }
 
+/**
+ * registerStackTransformation registers a transformation that will be applied to every resource subsequently
+ * constructed in this stack. This allows policies, such as mandatory tags or protection of critical resources, to be
+ * enforced centrally rather than on each individual resource.
+ */
+export function registerStackTransformation(t: ResourceTransformation) {
+    const stackResource = getStackResource();
+    if (!stackResource) {
+        throw new Error("The root stack resource was referenced before it was initialized.");
+    }
+    stackResource.__transformations.push(t);
+}
+
 /**
  * Stack is the root resource for a Pulumi stack. Before invoking the `init` callback, it registers itself as the root
  * resource with the Pulumi engine.
@@ -46,6 +59,7 @@ class Stack extends ComponentResource {
 
     constructor(init: () => Inputs) {
         super(rootPulumiStackTypeName, `${getProject()}-${getStack()}`);
+        setStackResource(this);
         this.outputs = output(this.runInit(init));
     }
 
@@ -0,0 +1,44 @@
+let pulumi = require("../../../../../");
+
+class MyCustomResource extends pulumi.CustomResource {
+	constructor(name, args, opts) {
+		super("test:index:MyCustomResource", name, args, opts);
+	}
+}
+
+class MyDatabase extends pulumi.CustomResource {
+	constructor(name, args, opts) {
+		super("test:index:MyDatabase", name, args, opts);
+	}
+}
+
+class MyComponentResource extends pulumi.ComponentResource {
+	constructor(name, opts) {
+		super("test:index:MyComponentResource", name, {}, opts);
+		new MyCustomResource(`${name}-child`, { tags: {} }, { parent: this });
+	}
+}
+
+// Every resource gets an "owner" tag, and every database is protected.
+pulumi.runtime.registerStackTransformation(({ type, props, opts }) => {
+	if (type === "test:index:MyComponentResource") {
+		return undefined;
+	}
+	props = Object.assign({}, props, { tags: Object.assign({}, props.tags, { owner: "platform" }) });
+	if (type === "test:index:MyDatabase") {
+		opts = Object.assign({}, opts, { protect: true });
+	}
+	return { props, opts };
+});
+
+// A resource's own transformations run before the stack's.
+new MyCustomResource("res1", { tags: { owner: "me" } }, {
+	transformations: [ ({ props, opts }) => ({ props: Object.assign({}, props, { size: 2 }), opts }) ],
+});
+
+new MyDatabase("db1", {});
+
+// A component's transformations also apply to its children.
+new MyComponentResource("comp1", {
+	transformations: [ ({ props, opts }) => ({ props: Object.assign({}, props, { fromParent: true }), opts }) ],
+});
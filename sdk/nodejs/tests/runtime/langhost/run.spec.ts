@@ -727,6 +727,34 @@ describe("rpc", () => {
                 return { urn: makeUrn(t, name), id: undefined, props: undefined };
             },
         },
+        // Test that resource, component and stack transformations are applied.
+        "transformations": {
+            program: path.join(base, "030.transformations"),
+            expectResourceCount: 4,
+            registerResource: (ctx: any, dryrun: boolean, t: string, name: string, res: any, dependencies?: string[],
+                               custom?: boolean, protect?: boolean) => {
+                switch (name) {
+                    case "res1":
+                        assert.deepStrictEqual(res, { tags: { owner: "platform" }, size: 2 });
+                        assert.strictEqual(protect, false);
+                        break;
+                    case "db1":
+                        assert.deepStrictEqual(res, { tags: { owner: "platform" } });
+                        assert.strictEqual(protect, true);
+                        break;
+                    case "comp1":
+                        assert.deepStrictEqual(res, { fromParent: true });
+                        break;
+                    case "comp1-child":
+                        assert.deepStrictEqual(res, { tags: { owner: "platform" }, fromParent: true });
+                        assert.strictEqual(protect, false);
+                        break;
+                    default:
+                        throw new Error("Didn't check: " + name);
+                }
+                return { urn: makeUrn(t, name), id: undefined, props: undefined };
+            },
+        },
     };
 
     for (const casename of Object.keys(cases)) {
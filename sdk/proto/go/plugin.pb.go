@@ -21,6 +21,8 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 // PluginInfo is meta-information about a plugin that is used by the system.
 type PluginInfo struct {
 	Version              string   `protobuf:"bytes,1,opt,name=version" json:"version,omitempty"`
+	InterfaceVersion     int32    `protobuf:"varint,2,opt,name=interfaceVersion" json:"interfaceVersion,omitempty"`
+	Capabilities         []string `protobuf:"bytes,3,rep,name=capabilities" json:"capabilities,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -30,7 +32,7 @@ func (m *PluginInfo) Reset()         { *m = PluginInfo{} }
 func (m *PluginInfo) String() string { return proto.CompactTextString(m) }
 func (*PluginInfo) ProtoMessage()    {}
 func (*PluginInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_plugin_6374fd953f733c72, []int{0}
+	return fileDescriptor_plugin_7eeea84f1e477210, []int{0}
 }
 func (m *PluginInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PluginInfo.Unmarshal(m, b)
@@ -57,6 +59,60 @@ func (m *PluginInfo) GetVersion() string {
 	return ""
 }
 
+func (m *PluginInfo) GetInterfaceVersion() int32 {
+	if m != nil {
+		return m.InterfaceVersion
+	}
+	return 0
+}
+
+func (m *PluginInfo) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
+// PluginAttach is sent to a plugin that was launched outside of the engine (e.g. under a debugger), rather than by the
+// engine, in order to tell it how to connect back to the engine.
+type PluginAttach struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PluginAttach) Reset()         { *m = PluginAttach{} }
+func (m *PluginAttach) String() string { return proto.CompactTextString(m) }
+func (*PluginAttach) ProtoMessage()    {}
+func (*PluginAttach) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_7eeea84f1e477210, []int{1}
+}
+func (m *PluginAttach) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PluginAttach.Unmarshal(m, b)
+}
+func (m *PluginAttach) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PluginAttach.Marshal(b, m, deterministic)
+}
+func (dst *PluginAttach) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PluginAttach.Merge(dst, src)
+}
+func (m *PluginAttach) XXX_Size() int {
+	return xxx_messageInfo_PluginAttach.Size(m)
+}
+func (m *PluginAttach) XXX_DiscardUnknown() {
+	xxx_messageInfo_PluginAttach.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PluginAttach proto.InternalMessageInfo
+
+func (m *PluginAttach) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
 // PluginDependency is information about a plugin that a program may depend upon.
 type PluginDependency struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
@@ -71,7 +127,7 @@ func (m *PluginDependency) Reset()         { *m = PluginDependency{} }
 func (m *PluginDependency) String() string { return proto.CompactTextString(m) }
 func (*PluginDependency) ProtoMessage()    {}
 func (*PluginDependency) Descriptor() ([]byte, []int) {
-	return fileDescriptor_plugin_6374fd953f733c72, []int{1}
+	return fileDescriptor_plugin_7eeea84f1e477210, []int{2}
 }
 func (m *PluginDependency) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PluginDependency.Unmarshal(m, b)
@@ -114,20 +170,25 @@ func (m *PluginDependency) GetVersion() string {
 
 func init() {
 	proto.RegisterType((*PluginInfo)(nil), "pulumirpc.PluginInfo")
+	proto.RegisterType((*PluginAttach)(nil), "pulumirpc.PluginAttach")
 	proto.RegisterType((*PluginDependency)(nil), "pulumirpc.PluginDependency")
 }
 
-func init() { proto.RegisterFile("plugin.proto", fileDescriptor_plugin_6374fd953f733c72) }
-
-var fileDescriptor_plugin_6374fd953f733c72 = []byte{
-	// 130 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0x29, 0xc8, 0x29, 0x4d,
-	0xcf, 0xcc, 0xd3, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x2c, 0x28, 0xcd, 0x29, 0xcd, 0xcd,
-	0x2c, 0x2a, 0x48, 0x56, 0x52, 0xe3, 0xe2, 0x0a, 0x00, 0x4b, 0x79, 0xe6, 0xa5, 0xe5, 0x0b, 0x49,
-	0x70, 0xb1, 0x97, 0xa5, 0x16, 0x15, 0x67, 0xe6, 0xe7, 0x49, 0x30, 0x2a, 0x30, 0x6a, 0x70, 0x06,
-	0xc1, 0xb8, 0x4a, 0x21, 0x5c, 0x02, 0x10, 0x75, 0x2e, 0xa9, 0x05, 0xa9, 0x79, 0x29, 0xa9, 0x79,
-	0xc9, 0x95, 0x42, 0x42, 0x5c, 0x2c, 0x79, 0x89, 0xb9, 0xa9, 0x50, 0xa5, 0x60, 0x36, 0x48, 0x2c,
-	0x3b, 0x33, 0x2f, 0x45, 0x82, 0x09, 0x22, 0x06, 0x62, 0x23, 0x9b, 0xca, 0x8c, 0x62, 0x6a, 0x12,
-	0x1b, 0xd8, 0x3d, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x27, 0x6c, 0x8e, 0x80, 0x9f, 0x00,
-	0x00, 0x00,
+func init() { proto.RegisterFile("plugin.proto", fileDescriptor_plugin_7eeea84f1e477210) }
+
+var fileDescriptor_plugin_7eeea84f1e477210 = []byte{
+	// 199 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x90, 0xbd, 0x6a, 0xc4, 0x30,
+	0x10, 0x84, 0x71, 0x9c, 0x1f, 0xb4, 0xb8, 0x38, 0x54, 0xb9, 0x34, 0xaa, 0x44, 0x8a, 0x34, 0x79,
+	0x82, 0x40, 0x9a, 0x74, 0x41, 0x84, 0xf4, 0x3a, 0x69, 0x2f, 0x59, 0xe2, 0x5b, 0x09, 0x49, 0x3e,
+	0xc8, 0xdb, 0x07, 0xcb, 0x36, 0xc4, 0x5c, 0x37, 0x33, 0x1a, 0xf8, 0x34, 0x0b, 0x5d, 0x1c, 0xa7,
+	0x2f, 0xe2, 0xa7, 0x98, 0x42, 0x09, 0x52, 0xc4, 0x69, 0x9c, 0xce, 0x94, 0xa2, 0x53, 0x17, 0x80,
+	0xf7, 0xfa, 0xf4, 0xc6, 0xa7, 0x20, 0x7b, 0x78, 0xb8, 0x60, 0xca, 0x14, 0xb8, 0x6f, 0x86, 0x46,
+	0x0b, 0xb3, 0x59, 0xf9, 0x08, 0x07, 0xe2, 0x82, 0xe9, 0x64, 0x1d, 0x7e, 0xae, 0x95, 0x9b, 0xa1,
+	0xd1, 0x77, 0xe6, 0x2a, 0x97, 0x0a, 0x3a, 0x67, 0xa3, 0x3d, 0xd2, 0x48, 0x85, 0x30, 0xf7, 0xed,
+	0xd0, 0x6a, 0x61, 0x76, 0x99, 0xd2, 0xd0, 0x2d, 0xdc, 0x97, 0x52, 0xac, 0xfb, 0x9e, 0xc9, 0xd6,
+	0xfb, 0x84, 0x39, 0x6f, 0xe4, 0xd5, 0xaa, 0x0f, 0x38, 0x2c, 0xcd, 0x57, 0x8c, 0xc8, 0x1e, 0xd9,
+	0xfd, 0x4a, 0x09, 0xb7, 0x6c, 0xcf, 0xb8, 0x56, 0xab, 0x9e, 0xb3, 0x1f, 0x62, 0x5f, 0x7f, 0x25,
+	0x4c, 0xd5, 0xff, 0xf7, 0xb4, 0xbb, 0x3d, 0xc7, 0xfb, 0x7a, 0x89, 0xe7, 0xbf, 0x01, 0x00, 0x4c,
+	0x32, 0x9a, 0xfa, 0x19, 0x01, 0x00, 0x00,
 }
@@ -48,12 +48,14 @@ func (x DiffResponse_DiffChanges) String() string {
 	return proto.EnumName(DiffResponse_DiffChanges_name, int32(x))
 }
 func (DiffResponse_DiffChanges) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{8, 0}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{8, 0}
 }
 
 type ConfigureRequest struct {
 	Variables            map[string]string `protobuf:"bytes,1,rep,name=variables" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	Args                 *_struct.Struct   `protobuf:"bytes,2,opt,name=args" json:"args,omitempty"`
+	InterfaceVersion     int32             `protobuf:"varint,3,opt,name=interfaceVersion" json:"interfaceVersion,omitempty"`
+	Capabilities         []string          `protobuf:"bytes,4,rep,name=capabilities" json:"capabilities,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -63,7 +65,7 @@ func (m *ConfigureRequest) Reset()         { *m = ConfigureRequest{} }
 func (m *ConfigureRequest) String() string { return proto.CompactTextString(m) }
 func (*ConfigureRequest) ProtoMessage()    {}
 func (*ConfigureRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{0}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{0}
 }
 func (m *ConfigureRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureRequest.Unmarshal(m, b)
@@ -97,6 +99,20 @@ func (m *ConfigureRequest) GetArgs() *_struct.Struct {
 	return nil
 }
 
+func (m *ConfigureRequest) GetInterfaceVersion() int32 {
+	if m != nil {
+		return m.InterfaceVersion
+	}
+	return 0
+}
+
+func (m *ConfigureRequest) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
 // ConfigureErrorMissingKeys is sent as a Detail on an error returned from `ResourceProvider.Configure`.
 type ConfigureErrorMissingKeys struct {
 	MissingKeys          []*ConfigureErrorMissingKeys_MissingKey `protobuf:"bytes,1,rep,name=missingKeys" json:"missingKeys,omitempty"`
@@ -109,7 +125,7 @@ func (m *ConfigureErrorMissingKeys) Reset()         { *m = ConfigureErrorMissing
 func (m *ConfigureErrorMissingKeys) String() string { return proto.CompactTextString(m) }
 func (*ConfigureErrorMissingKeys) ProtoMessage()    {}
 func (*ConfigureErrorMissingKeys) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{1}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{1}
 }
 func (m *ConfigureErrorMissingKeys) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureErrorMissingKeys.Unmarshal(m, b)
@@ -148,7 +164,7 @@ func (m *ConfigureErrorMissingKeys_MissingKey) Reset()         { *m = ConfigureE
 func (m *ConfigureErrorMissingKeys_MissingKey) String() string { return proto.CompactTextString(m) }
 func (*ConfigureErrorMissingKeys_MissingKey) ProtoMessage()    {}
 func (*ConfigureErrorMissingKeys_MissingKey) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{1, 0}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{1, 0}
 }
 func (m *ConfigureErrorMissingKeys_MissingKey) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigureErrorMissingKeys_MissingKey.Unmarshal(m, b)
@@ -186,6 +202,7 @@ type InvokeRequest struct {
 	Tok                  string          `protobuf:"bytes,1,opt,name=tok" json:"tok,omitempty"`
 	Args                 *_struct.Struct `protobuf:"bytes,2,opt,name=args" json:"args,omitempty"`
 	Provider             string          `protobuf:"bytes,3,opt,name=provider" json:"provider,omitempty"`
+	Version              string          `protobuf:"bytes,4,opt,name=version" json:"version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -195,7 +212,7 @@ func (m *InvokeRequest) Reset()         { *m = InvokeRequest{} }
 func (m *InvokeRequest) String() string { return proto.CompactTextString(m) }
 func (*InvokeRequest) ProtoMessage()    {}
 func (*InvokeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{2}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{2}
 }
 func (m *InvokeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_InvokeRequest.Unmarshal(m, b)
@@ -236,6 +253,13 @@ func (m *InvokeRequest) GetProvider() string {
 	return ""
 }
 
+func (m *InvokeRequest) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
 type InvokeResponse struct {
 	Return               *_struct.Struct `protobuf:"bytes,1,opt,name=return" json:"return,omitempty"`
 	Failures             []*CheckFailure `protobuf:"bytes,2,rep,name=failures" json:"failures,omitempty"`
@@ -248,7 +272,7 @@ func (m *InvokeResponse) Reset()         { *m = InvokeResponse{} }
 func (m *InvokeResponse) String() string { return proto.CompactTextString(m) }
 func (*InvokeResponse) ProtoMessage()    {}
 func (*InvokeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{3}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{3}
 }
 func (m *InvokeResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_InvokeResponse.Unmarshal(m, b)
@@ -295,7 +319,7 @@ func (m *CheckRequest) Reset()         { *m = CheckRequest{} }
 func (m *CheckRequest) String() string { return proto.CompactTextString(m) }
 func (*CheckRequest) ProtoMessage()    {}
 func (*CheckRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{4}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{4}
 }
 func (m *CheckRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckRequest.Unmarshal(m, b)
@@ -348,7 +372,7 @@ func (m *CheckResponse) Reset()         { *m = CheckResponse{} }
 func (m *CheckResponse) String() string { return proto.CompactTextString(m) }
 func (*CheckResponse) ProtoMessage()    {}
 func (*CheckResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{5}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{5}
 }
 func (m *CheckResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckResponse.Unmarshal(m, b)
@@ -394,7 +418,7 @@ func (m *CheckFailure) Reset()         { *m = CheckFailure{} }
 func (m *CheckFailure) String() string { return proto.CompactTextString(m) }
 func (*CheckFailure) ProtoMessage()    {}
 func (*CheckFailure) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{6}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{6}
 }
 func (m *CheckFailure) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CheckFailure.Unmarshal(m, b)
@@ -442,7 +466,7 @@ func (m *DiffRequest) Reset()         { *m = DiffRequest{} }
 func (m *DiffRequest) String() string { return proto.CompactTextString(m) }
 func (*DiffRequest) ProtoMessage()    {}
 func (*DiffRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{7}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{7}
 }
 func (m *DiffRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DiffRequest.Unmarshal(m, b)
@@ -496,6 +520,7 @@ type DiffResponse struct {
 	DeleteBeforeReplace  bool                     `protobuf:"varint,3,opt,name=deleteBeforeReplace" json:"deleteBeforeReplace,omitempty"`
 	Changes              DiffResponse_DiffChanges `protobuf:"varint,4,opt,name=changes,enum=pulumirpc.DiffResponse_DiffChanges" json:"changes,omitempty"`
 	Diffs                []string                 `protobuf:"bytes,5,rep,name=diffs" json:"diffs,omitempty"`
+	ReplaceReasons       map[string]string        `protobuf:"bytes,6,rep,name=replaceReasons" json:"replaceReasons,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
 	XXX_unrecognized     []byte                   `json:"-"`
 	XXX_sizecache        int32                    `json:"-"`
@@ -505,7 +530,7 @@ func (m *DiffResponse) Reset()         { *m = DiffResponse{} }
 func (m *DiffResponse) String() string { return proto.CompactTextString(m) }
 func (*DiffResponse) ProtoMessage()    {}
 func (*DiffResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{8}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{8}
 }
 func (m *DiffResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DiffResponse.Unmarshal(m, b)
@@ -560,9 +585,17 @@ func (m *DiffResponse) GetDiffs() []string {
 	return nil
 }
 
+func (m *DiffResponse) GetReplaceReasons() map[string]string {
+	if m != nil {
+		return m.ReplaceReasons
+	}
+	return nil
+}
+
 type CreateRequest struct {
 	Urn                  string          `protobuf:"bytes,1,opt,name=urn" json:"urn,omitempty"`
 	Properties           *_struct.Struct `protobuf:"bytes,2,opt,name=properties" json:"properties,omitempty"`
+	Timeout              float64         `protobuf:"fixed64,3,opt,name=timeout" json:"timeout,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -572,7 +605,7 @@ func (m *CreateRequest) Reset()         { *m = CreateRequest{} }
 func (m *CreateRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateRequest) ProtoMessage()    {}
 func (*CreateRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{9}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{9}
 }
 func (m *CreateRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateRequest.Unmarshal(m, b)
@@ -606,6 +639,13 @@ func (m *CreateRequest) GetProperties() *_struct.Struct {
 	return nil
 }
 
+func (m *CreateRequest) GetTimeout() float64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
 type CreateResponse struct {
 	Id                   string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Properties           *_struct.Struct `protobuf:"bytes,2,opt,name=properties" json:"properties,omitempty"`
@@ -618,7 +658,7 @@ func (m *CreateResponse) Reset()         { *m = CreateResponse{} }
 func (m *CreateResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateResponse) ProtoMessage()    {}
 func (*CreateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{10}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{10}
 }
 func (m *CreateResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CreateResponse.Unmarshal(m, b)
@@ -666,7 +706,7 @@ func (m *ReadRequest) Reset()         { *m = ReadRequest{} }
 func (m *ReadRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadRequest) ProtoMessage()    {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{11}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{11}
 }
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadRequest.Unmarshal(m, b)
@@ -727,7 +767,7 @@ func (m *ReadResponse) Reset()         { *m = ReadResponse{} }
 func (m *ReadResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse) ProtoMessage()    {}
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{12}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{12}
 }
 func (m *ReadResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResponse.Unmarshal(m, b)
@@ -773,6 +813,7 @@ type UpdateRequest struct {
 	Urn                  string          `protobuf:"bytes,2,opt,name=urn" json:"urn,omitempty"`
 	Olds                 *_struct.Struct `protobuf:"bytes,3,opt,name=olds" json:"olds,omitempty"`
 	News                 *_struct.Struct `protobuf:"bytes,4,opt,name=news" json:"news,omitempty"`
+	Timeout              float64         `protobuf:"fixed64,5,opt,name=timeout" json:"timeout,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -782,7 +823,7 @@ func (m *UpdateRequest) Reset()         { *m = UpdateRequest{} }
 func (m *UpdateRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdateRequest) ProtoMessage()    {}
 func (*UpdateRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{13}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{13}
 }
 func (m *UpdateRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateRequest.Unmarshal(m, b)
@@ -830,6 +871,13 @@ func (m *UpdateRequest) GetNews() *_struct.Struct {
 	return nil
 }
 
+func (m *UpdateRequest) GetTimeout() float64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
 type UpdateResponse struct {
 	Properties           *_struct.Struct `protobuf:"bytes,1,opt,name=properties" json:"properties,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
@@ -841,7 +889,7 @@ func (m *UpdateResponse) Reset()         { *m = UpdateResponse{} }
 func (m *UpdateResponse) String() string { return proto.CompactTextString(m) }
 func (*UpdateResponse) ProtoMessage()    {}
 func (*UpdateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{14}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{14}
 }
 func (m *UpdateResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UpdateResponse.Unmarshal(m, b)
@@ -872,6 +920,7 @@ type DeleteRequest struct {
 	Id                   string          `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Urn                  string          `protobuf:"bytes,2,opt,name=urn" json:"urn,omitempty"`
 	Properties           *_struct.Struct `protobuf:"bytes,3,opt,name=properties" json:"properties,omitempty"`
+	Timeout              float64         `protobuf:"fixed64,4,opt,name=timeout" json:"timeout,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -881,7 +930,7 @@ func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
 func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
 func (*DeleteRequest) ProtoMessage()    {}
 func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{15}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{15}
 }
 func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
@@ -922,6 +971,13 @@ func (m *DeleteRequest) GetProperties() *_struct.Struct {
 	return nil
 }
 
+func (m *DeleteRequest) GetTimeout() float64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
 // ErrorResourceInitFailed is sent as a Detail `ResourceProvider.{Create, Update}` fail because a
 // resource was created successfully, but failed to initialize.
 type ErrorResourceInitFailed struct {
@@ -938,7 +994,7 @@ func (m *ErrorResourceInitFailed) Reset()         { *m = ErrorResourceInitFailed
 func (m *ErrorResourceInitFailed) String() string { return proto.CompactTextString(m) }
 func (*ErrorResourceInitFailed) ProtoMessage()    {}
 func (*ErrorResourceInitFailed) Descriptor() ([]byte, []int) {
-	return fileDescriptor_provider_dc3f0238d29a44c1, []int{16}
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{16}
 }
 func (m *ErrorResourceInitFailed) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ErrorResourceInitFailed.Unmarshal(m, b)
@@ -986,6 +1042,244 @@ func (m *ErrorResourceInitFailed) GetInputs() *_struct.Struct {
 	return nil
 }
 
+type GetSchemaRequest struct {
+	Version              int32    `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSchemaRequest) Reset()         { *m = GetSchemaRequest{} }
+func (m *GetSchemaRequest) String() string { return proto.CompactTextString(m) }
+func (*GetSchemaRequest) ProtoMessage()    {}
+func (*GetSchemaRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{17}
+}
+func (m *GetSchemaRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSchemaRequest.Unmarshal(m, b)
+}
+func (m *GetSchemaRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSchemaRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetSchemaRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSchemaRequest.Merge(dst, src)
+}
+func (m *GetSchemaRequest) XXX_Size() int {
+	return xxx_messageInfo_GetSchemaRequest.Size(m)
+}
+func (m *GetSchemaRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSchemaRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSchemaRequest proto.InternalMessageInfo
+
+func (m *GetSchemaRequest) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type GetSchemaResponse struct {
+	Schema               string   `protobuf:"bytes,1,opt,name=schema" json:"schema,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSchemaResponse) Reset()         { *m = GetSchemaResponse{} }
+func (m *GetSchemaResponse) String() string { return proto.CompactTextString(m) }
+func (*GetSchemaResponse) ProtoMessage()    {}
+func (*GetSchemaResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{18}
+}
+func (m *GetSchemaResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSchemaResponse.Unmarshal(m, b)
+}
+func (m *GetSchemaResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSchemaResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetSchemaResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSchemaResponse.Merge(dst, src)
+}
+func (m *GetSchemaResponse) XXX_Size() int {
+	return xxx_messageInfo_GetSchemaResponse.Size(m)
+}
+func (m *GetSchemaResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSchemaResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSchemaResponse proto.InternalMessageInfo
+
+func (m *GetSchemaResponse) GetSchema() string {
+	if m != nil {
+		return m.Schema
+	}
+	return ""
+}
+
+type GetLogsRequest struct {
+	Urn                  string          `protobuf:"bytes,1,opt,name=urn" json:"urn,omitempty"`
+	Id                   string          `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	Properties           *_struct.Struct `protobuf:"bytes,3,opt,name=properties" json:"properties,omitempty"`
+	StartTime            int64           `protobuf:"varint,4,opt,name=startTime" json:"startTime,omitempty"`
+	EndTime              int64           `protobuf:"varint,5,opt,name=endTime" json:"endTime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *GetLogsRequest) Reset()         { *m = GetLogsRequest{} }
+func (m *GetLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLogsRequest) ProtoMessage()    {}
+func (*GetLogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{19}
+}
+func (m *GetLogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLogsRequest.Unmarshal(m, b)
+}
+func (m *GetLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLogsRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetLogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogsRequest.Merge(dst, src)
+}
+func (m *GetLogsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetLogsRequest.Size(m)
+}
+func (m *GetLogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogsRequest proto.InternalMessageInfo
+
+func (m *GetLogsRequest) GetUrn() string {
+	if m != nil {
+		return m.Urn
+	}
+	return ""
+}
+
+func (m *GetLogsRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *GetLogsRequest) GetProperties() *_struct.Struct {
+	if m != nil {
+		return m.Properties
+	}
+	return nil
+}
+
+func (m *GetLogsRequest) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *GetLogsRequest) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+type GetLogsResponse struct {
+	Entries              []*LogEntry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetLogsResponse) Reset()         { *m = GetLogsResponse{} }
+func (m *GetLogsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetLogsResponse) ProtoMessage()    {}
+func (*GetLogsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{20}
+}
+func (m *GetLogsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLogsResponse.Unmarshal(m, b)
+}
+func (m *GetLogsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLogsResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetLogsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLogsResponse.Merge(dst, src)
+}
+func (m *GetLogsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetLogsResponse.Size(m)
+}
+func (m *GetLogsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLogsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLogsResponse proto.InternalMessageInfo
+
+func (m *GetLogsResponse) GetEntries() []*LogEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type LogEntry struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Timestamp            int64    `protobuf:"varint,2,opt,name=timestamp" json:"timestamp,omitempty"`
+	Message              string   `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LogEntry) Reset()         { *m = LogEntry{} }
+func (m *LogEntry) String() string { return proto.CompactTextString(m) }
+func (*LogEntry) ProtoMessage()    {}
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_provider_27132d7185bd0f1b, []int{21}
+}
+func (m *LogEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LogEntry.Unmarshal(m, b)
+}
+func (m *LogEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LogEntry.Marshal(b, m, deterministic)
+}
+func (dst *LogEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LogEntry.Merge(dst, src)
+}
+func (m *LogEntry) XXX_Size() int {
+	return xxx_messageInfo_LogEntry.Size(m)
+}
+func (m *LogEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_LogEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LogEntry proto.InternalMessageInfo
+
+func (m *LogEntry) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *LogEntry) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *LogEntry) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ConfigureRequest)(nil), "pulumirpc.ConfigureRequest")
 	proto.RegisterMapType((map[string]string)(nil), "pulumirpc.ConfigureRequest.VariablesEntry")
@@ -998,6 +1292,7 @@ func init() {
 	proto.RegisterType((*CheckFailure)(nil), "pulumirpc.CheckFailure")
 	proto.RegisterType((*DiffRequest)(nil), "pulumirpc.DiffRequest")
 	proto.RegisterType((*DiffResponse)(nil), "pulumirpc.DiffResponse")
+	proto.RegisterMapType((map[string]string)(nil), "pulumirpc.DiffResponse.ReplaceReasonsEntry")
 	proto.RegisterType((*CreateRequest)(nil), "pulumirpc.CreateRequest")
 	proto.RegisterType((*CreateResponse)(nil), "pulumirpc.CreateResponse")
 	proto.RegisterType((*ReadRequest)(nil), "pulumirpc.ReadRequest")
@@ -1006,6 +1301,11 @@ func init() {
 	proto.RegisterType((*UpdateResponse)(nil), "pulumirpc.UpdateResponse")
 	proto.RegisterType((*DeleteRequest)(nil), "pulumirpc.DeleteRequest")
 	proto.RegisterType((*ErrorResourceInitFailed)(nil), "pulumirpc.ErrorResourceInitFailed")
+	proto.RegisterType((*GetSchemaRequest)(nil), "pulumirpc.GetSchemaRequest")
+	proto.RegisterType((*GetSchemaResponse)(nil), "pulumirpc.GetSchemaResponse")
+	proto.RegisterType((*GetLogsRequest)(nil), "pulumirpc.GetLogsRequest")
+	proto.RegisterType((*GetLogsResponse)(nil), "pulumirpc.GetLogsResponse")
+	proto.RegisterType((*LogEntry)(nil), "pulumirpc.LogEntry")
 	proto.RegisterEnum("pulumirpc.DiffResponse_DiffChanges", DiffResponse_DiffChanges_name, DiffResponse_DiffChanges_value)
 }
 
@@ -1048,8 +1348,22 @@ type ResourceProviderClient interface {
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	// Cancel signals the provider to abort all outstanding resource operations.
 	Cancel(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
-	// GetPluginInfo returns generic information about this plugin, like its version.
+	// GetPluginInfo returns generic information about this plugin, like its version. The engine also uses the interface
+	// version and capabilities that it returns to negotiate how it talks to the provider: it uses the older of its own
+	// and the provider's interface versions, and those capabilities that both support, and sends the results to the
+	// provider in its ConfigureRequest. Providers that do not report an interface version are assumed to support
+	// version 1, and providers must continue to support every interface version older than the one they report.
 	GetPluginInfo(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*PluginInfo, error)
+	// GetSchema fetches the schema for this resource provider, which describes its configuration, resources, and
+	// functions, for use by tools such as editors and documentation generators.
+	GetSchema(ctx context.Context, in *GetSchemaRequest, opts ...grpc.CallOption) (*GetSchemaResponse, error)
+	// Attach tells a provider that was launched outside of the engine, rather than by it, the address of the engine's
+	// RPC server, which the engine would otherwise have passed to the provider on its command line. The engine calls
+	// it before any other method, and only on providers that it attaches to rather than launches.
+	Attach(ctx context.Context, in *PluginAttach, opts ...grpc.CallOption) (*empty.Empty, error)
+	// GetLogs fetches the runtime logs of a resource, such as those written by a serverless function, for `pulumi logs`.
+	// Providers that do not keep logs for resources of the given type return no entries.
+	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error)
 }
 
 type resourceProviderClient struct {
@@ -1168,6 +1482,33 @@ func (c *resourceProviderClient) GetPluginInfo(ctx context.Context, in *empty.Em
 	return out, nil
 }
 
+func (c *resourceProviderClient) GetSchema(ctx context.Context, in *GetSchemaRequest, opts ...grpc.CallOption) (*GetSchemaResponse, error) {
+	out := new(GetSchemaResponse)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/GetSchema", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceProviderClient) Attach(ctx context.Context, in *PluginAttach, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/Attach", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceProviderClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error) {
+	out := new(GetLogsResponse)
+	err := grpc.Invoke(ctx, "/pulumirpc.ResourceProvider/GetLogs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for ResourceProvider service
 
 type ResourceProviderServer interface {
@@ -1199,8 +1540,22 @@ type ResourceProviderServer interface {
 	Delete(context.Context, *DeleteRequest) (*empty.Empty, error)
 	// Cancel signals the provider to abort all outstanding resource operations.
 	Cancel(context.Context, *empty.Empty) (*empty.Empty, error)
-	// GetPluginInfo returns generic information about this plugin, like its version.
+	// GetPluginInfo returns generic information about this plugin, like its version. The engine also uses the interface
+	// version and capabilities that it returns to negotiate how it talks to the provider: it uses the older of its own
+	// and the provider's interface versions, and those capabilities that both support, and sends the results to the
+	// provider in its ConfigureRequest. Providers that do not report an interface version are assumed to support
+	// version 1, and providers must continue to support every interface version older than the one they report.
 	GetPluginInfo(context.Context, *empty.Empty) (*PluginInfo, error)
+	// GetSchema fetches the schema for this resource provider, which describes its configuration, resources, and
+	// functions, for use by tools such as editors and documentation generators.
+	GetSchema(context.Context, *GetSchemaRequest) (*GetSchemaResponse, error)
+	// Attach tells a provider that was launched outside of the engine, rather than by it, the address of the engine's
+	// RPC server, which the engine would otherwise have passed to the provider on its command line. The engine calls
+	// it before any other method, and only on providers that it attaches to rather than launches.
+	Attach(context.Context, *PluginAttach) (*empty.Empty, error)
+	// GetLogs fetches the runtime logs of a resource, such as those written by a serverless function, for `pulumi logs`.
+	// Providers that do not keep logs for resources of the given type return no entries.
+	GetLogs(context.Context, *GetLogsRequest) (*GetLogsResponse, error)
 }
 
 func RegisterResourceProviderServer(s *grpc.Server, srv ResourceProviderServer) {
@@ -1423,6 +1778,60 @@ func _ResourceProvider_GetPluginInfo_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceProvider_GetSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).GetSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/GetSchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).GetSchema(ctx, req.(*GetSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceProvider_Attach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PluginAttach)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).Attach(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/Attach",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).Attach(ctx, req.(*PluginAttach))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceProvider_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceProviderServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pulumirpc.ResourceProvider/GetLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceProviderServer).GetLogs(ctx, req.(*GetLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ResourceProvider_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pulumirpc.ResourceProvider",
 	HandlerType: (*ResourceProviderServer)(nil),
@@ -1475,72 +1884,103 @@ var _ResourceProvider_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetPluginInfo",
 			Handler:    _ResourceProvider_GetPluginInfo_Handler,
 		},
+		{
+			MethodName: "GetSchema",
+			Handler:    _ResourceProvider_GetSchema_Handler,
+		},
+		{
+			MethodName: "Attach",
+			Handler:    _ResourceProvider_Attach_Handler,
+		},
+		{
+			MethodName: "GetLogs",
+			Handler:    _ResourceProvider_GetLogs_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "provider.proto",
 }
 
-func init() { proto.RegisterFile("provider.proto", fileDescriptor_provider_dc3f0238d29a44c1) }
-
-var fileDescriptor_provider_dc3f0238d29a44c1 = []byte{
-	// 944 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xc4, 0x57, 0xdd, 0x6e, 0xe3, 0x44,
-	0x14, 0xae, 0x93, 0x34, 0xdb, 0x9c, 0xfc, 0x28, 0x1a, 0xa0, 0xcd, 0x7a, 0xb9, 0xa8, 0xcc, 0xcd,
-	0x0a, 0x24, 0x07, 0x75, 0x2f, 0x80, 0xd5, 0xae, 0x40, 0xd9, 0xa6, 0x10, 0xad, 0x36, 0x2d, 0xae,
-	0x0a, 0x82, 0x1b, 0xe4, 0xda, 0x93, 0xd4, 0xc4, 0xb1, 0xcd, 0x78, 0x1c, 0x14, 0x9e, 0x80, 0x1f,
-	0x89, 0x7b, 0x1e, 0x82, 0x1b, 0x5e, 0x82, 0xd7, 0x62, 0x3c, 0x33, 0x76, 0x3c, 0x49, 0x93, 0xa6,
-	0x51, 0xd1, 0xde, 0xcd, 0xc9, 0x39, 0x73, 0xce, 0xf7, 0x9d, 0x39, 0xf3, 0x79, 0x02, 0xad, 0x88,
-	0x84, 0x33, 0xcf, 0xc5, 0xc4, 0x64, 0x0b, 0x1a, 0xa2, 0x5a, 0x94, 0xf8, 0xc9, 0xd4, 0x23, 0x91,
-	0xa3, 0x37, 0x22, 0x3f, 0x19, 0x7b, 0x81, 0x70, 0xe8, 0x4f, 0xc6, 0x61, 0x38, 0xf6, 0x71, 0x97,
-	0x5b, 0xd7, 0xc9, 0xa8, 0x8b, 0xa7, 0x11, 0x9d, 0x4b, 0xe7, 0xfb, 0xcb, 0xce, 0x98, 0x92, 0xc4,
-	0xa1, 0xc2, 0x6b, 0xfc, 0xab, 0x41, 0xfb, 0x55, 0x18, 0x8c, 0xbc, 0x71, 0x42, 0xb0, 0x85, 0x7f,
-	0x4a, 0x70, 0x4c, 0xd1, 0x57, 0x50, 0x9b, 0xd9, 0xc4, 0xb3, 0xaf, 0x7d, 0x1c, 0x77, 0xb4, 0xe3,
-	0xf2, 0xd3, 0xfa, 0xc9, 0x87, 0x66, 0x5e, 0xdc, 0x5c, 0x8e, 0x37, 0xbf, 0xc9, 0x82, 0xfb, 0x01,
-	0x25, 0x73, 0x6b, 0xb1, 0x19, 0x7d, 0x04, 0x15, 0x9b, 0x8c, 0xe3, 0x4e, 0xe9, 0x58, 0x63, 0x49,
-	0x8e, 0x4c, 0x81, 0xc5, 0xcc, 0xb0, 0x98, 0x97, 0x1c, 0x8b, 0xc5, 0x83, 0xf4, 0x17, 0xd0, 0x52,
-	0x33, 0xa1, 0x36, 0x94, 0x27, 0x78, 0xce, 0x20, 0x68, 0x4f, 0x6b, 0x56, 0xba, 0x44, 0xef, 0xc2,
-	0xfe, 0xcc, 0xf6, 0x13, 0xcc, 0x33, 0xd6, 0x2c, 0x61, 0x3c, 0x2f, 0x7d, 0xaa, 0x19, 0xff, 0x68,
-	0xf0, 0x38, 0x47, 0xd6, 0x27, 0x24, 0x24, 0x6f, 0xbc, 0x38, 0xf6, 0x82, 0xf1, 0x6b, 0x3c, 0x8f,
-	0xd1, 0xd7, 0x50, 0x9f, 0x2e, 0x4c, 0x49, 0xaa, 0x7b, 0x1b, 0xa9, 0xe5, 0xad, 0xe6, 0x62, 0x6d,
-	0x15, 0x73, 0xe8, 0x3d, 0x80, 0x85, 0x0b, 0x21, 0xa8, 0x04, 0xf6, 0x14, 0x4b, 0xac, 0x7c, 0x8d,
-	0x8e, 0xa1, 0xee, 0xe2, 0xd8, 0x21, 0x5e, 0x44, 0xbd, 0x30, 0x90, 0x90, 0x8b, 0x3f, 0x19, 0x3f,
-	0x42, 0x73, 0x10, 0xcc, 0xc2, 0x49, 0xde, 0x7a, 0xc6, 0x98, 0x86, 0x93, 0x8c, 0x31, 0x5b, 0xde,
-	0xab, 0x85, 0x48, 0x87, 0x83, 0x6c, 0x68, 0x3a, 0x65, 0x9e, 0x23, 0xb7, 0x8d, 0x19, 0xb4, 0xb2,
-	0x5a, 0x71, 0x14, 0x06, 0x31, 0x46, 0x5d, 0xa8, 0x12, 0x4c, 0x13, 0x12, 0xf0, 0x7a, 0x1b, 0x92,
-	0xcb, 0x30, 0xf4, 0x0c, 0x0e, 0x46, 0xb6, 0xe7, 0xb3, 0x2e, 0xa5, 0x78, 0xca, 0x7c, 0x4b, 0xa1,
-	0x85, 0x37, 0xd8, 0x99, 0x9c, 0x09, 0xbf, 0x95, 0x07, 0x1a, 0xbf, 0x40, 0x83, 0x7b, 0x0a, 0x14,
-	0xb3, 0x92, 0x8c, 0x62, 0x9a, 0x96, 0x51, 0x0c, 0x7d, 0xf7, 0x6e, 0x8a, 0x69, 0x50, 0x1a, 0x1c,
-	0xe0, 0x9f, 0x63, 0x4e, 0x6f, 0x53, 0x70, 0x1a, 0x64, 0x24, 0xd0, 0x94, 0xb5, 0x17, 0x94, 0xbd,
-	0x20, 0x4a, 0x68, 0x7c, 0x27, 0x65, 0x11, 0xb6, 0x1b, 0xe5, 0x9e, 0xa4, 0x2c, 0x3d, 0xf2, 0x58,
-	0x22, 0x4c, 0x68, 0x36, 0xcc, 0xb9, 0x8d, 0x0e, 0xd3, 0x43, 0xb0, 0xe3, 0x7c, 0x3e, 0xa4, 0x65,
-	0xfc, 0xa6, 0x41, 0xfd, 0xd4, 0x1b, 0x8d, 0xb2, 0xb6, 0xb5, 0xa0, 0xe4, 0xb9, 0x72, 0x37, 0x5b,
-	0x65, 0x6d, 0x2c, 0xad, 0xb6, 0xb1, 0x7c, 0x9f, 0x36, 0x56, 0xb6, 0x69, 0xe3, 0xef, 0x25, 0x68,
-	0x08, 0x2c, 0xb2, 0x8d, 0x8c, 0x10, 0xc1, 0x91, 0x6f, 0x3b, 0x52, 0x20, 0x18, 0xa1, 0xcc, 0x46,
-	0x1d, 0x78, 0x14, 0x53, 0xa1, 0x1d, 0x25, 0xee, 0xca, 0x4c, 0xf4, 0x31, 0xbc, 0xe3, 0x62, 0x1f,
-	0x53, 0xdc, 0xc3, 0xa3, 0x30, 0x95, 0x0f, 0xbe, 0x83, 0xe3, 0x3d, 0xb0, 0x6e, 0x73, 0xa1, 0x97,
-	0xf0, 0xc8, 0xb9, 0xb1, 0x83, 0x31, 0x16, 0x40, 0x5b, 0x27, 0x1f, 0x14, 0x9a, 0x5f, 0x44, 0xc4,
-	0x8d, 0x57, 0x22, 0xd4, 0xca, 0xf6, 0xa4, 0x6a, 0xe1, 0xb2, 0xdf, 0xe3, 0xce, 0x3e, 0x07, 0x22,
-	0x0c, 0xe3, 0xa5, 0x68, 0xac, 0x8c, 0x66, 0x8d, 0x6c, 0x9c, 0x0e, 0xce, 0xce, 0x7e, 0xb8, 0x1a,
-	0xbe, 0x1e, 0x9e, 0x7f, 0x3b, 0x6c, 0xef, 0xa1, 0x26, 0xd4, 0xf8, 0x2f, 0xc3, 0xf3, 0x61, 0xbf,
-	0xad, 0xe5, 0xe6, 0xe5, 0xf9, 0x9b, 0x7e, 0xbb, 0x64, 0x7c, 0xcf, 0x66, 0x8a, 0x9d, 0x11, 0xc5,
-	0xeb, 0x07, 0xfa, 0x13, 0x00, 0x79, 0xbe, 0x1e, 0xbe, 0x73, 0xac, 0x0b, 0xa1, 0xc6, 0x77, 0xd0,
-	0xca, 0x72, 0xcb, 0x4e, 0x2f, 0x1f, 0xfb, 0xce, 0xa9, 0xff, 0x62, 0xf3, 0x64, 0x61, 0xdb, 0xdd,
-	0x7e, 0x9e, 0xd4, 0x52, 0xe5, 0xad, 0x4b, 0x15, 0x2e, 0x59, 0x65, 0xab, 0x4b, 0x66, 0xfc, 0xaa,
-	0x41, 0x43, 0x60, 0x7b, 0x60, 0xd6, 0x05, 0x28, 0xe5, 0xed, 0xa0, 0xfc, 0xa1, 0x41, 0xf3, 0x2a,
-	0x72, 0x0b, 0xc7, 0xfb, 0x36, 0x2f, 0xde, 0x00, 0x5a, 0x19, 0x18, 0xd9, 0x19, 0xb5, 0x13, 0xda,
-	0xf6, 0xe7, 0xcf, 0x3e, 0x35, 0xa7, 0xfc, 0x86, 0xfd, 0xff, 0x03, 0x60, 0xfc, 0xad, 0xc1, 0x11,
-	0xff, 0x8e, 0x32, 0xd8, 0x61, 0x42, 0x1c, 0x3c, 0x08, 0x3c, 0x9a, 0x8a, 0x21, 0x76, 0x1f, 0xee,
-	0x68, 0x99, 0xce, 0x08, 0xa9, 0x4c, 0xa1, 0x71, 0x9d, 0x91, 0xe6, 0xbd, 0xe7, 0xef, 0xe4, 0xcf,
-	0x2a, 0xb4, 0x33, 0xa8, 0x17, 0xf2, 0x7b, 0x89, 0x7a, 0x50, 0xe7, 0x22, 0x2e, 0x5e, 0x06, 0x68,
-	0x45, 0xf6, 0x65, 0x1f, 0xf5, 0xce, 0xaa, 0x43, 0x9c, 0x95, 0xb1, 0x87, 0x3e, 0x07, 0xe0, 0x52,
-	0x23, 0x52, 0x1c, 0xae, 0x88, 0x97, 0xc8, 0x70, 0xb4, 0x46, 0xd4, 0x58, 0x82, 0x1e, 0xd4, 0xf2,
-	0x97, 0x09, 0x7a, 0xb2, 0xe1, 0x11, 0xa6, 0x1f, 0xae, 0x90, 0xec, 0xa7, 0xaf, 0x40, 0x0e, 0xa2,
-	0x2a, 0x3e, 0xfc, 0xa8, 0x08, 0x55, 0x79, 0x77, 0xe8, 0x8f, 0x6f, 0xf1, 0xe4, 0x20, 0x5e, 0xc0,
-	0x3e, 0x27, 0xb6, 0x5b, 0x0f, 0x3e, 0x83, 0x4a, 0x4a, 0x6a, 0x17, 0xf6, 0x0c, 0xb9, 0x90, 0x43,
-	0x05, 0xb9, 0xa2, 0xbe, 0x0a, 0x72, 0x55, 0x3b, 0x45, 0xed, 0x54, 0x57, 0x94, 0xda, 0x05, 0x11,
-	0x54, 0x6a, 0x17, 0x05, 0x48, 0xd4, 0x16, 0x57, 0x4f, 0xa9, 0xad, 0x48, 0x83, 0x52, 0x5b, 0xbd,
-	0xa7, 0xbc, 0x6b, 0x55, 0x71, 0xe1, 0x94, 0x04, 0xca, 0x1d, 0xdc, 0x70, 0x68, 0xcf, 0x19, 0x75,
-	0x3b, 0x70, 0xb0, 0x8f, 0xd6, 0xc4, 0x6c, 0xd8, 0xfb, 0x05, 0x34, 0xbf, 0xc4, 0xf4, 0x82, 0xff,
-	0x45, 0x18, 0x04, 0xa3, 0x70, 0x6d, 0x8a, 0xf7, 0x0a, 0xc0, 0x16, 0xe1, 0xc6, 0xde, 0x75, 0x95,
-	0x07, 0x3e, 0xfb, 0x2f, 0x00, 0x00, 0xff, 0xff, 0x37, 0xce, 0x6f, 0x2c, 0x83, 0x0c, 0x00, 0x00,
+func init() { proto.RegisterFile("provider.proto", fileDescriptor_provider_27132d7185bd0f1b) }
+
+var fileDescriptor_provider_27132d7185bd0f1b = []byte{
+	// 1236 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0x5d, 0x73, 0xdb, 0x44,
+	0x17, 0x8e, 0x2c, 0xdb, 0x89, 0x8e, 0x3f, 0x5e, 0xbf, 0x5b, 0x48, 0x14, 0x35, 0x17, 0x1e, 0x71,
+	0x93, 0x69, 0xc1, 0x61, 0xd2, 0x0b, 0xa0, 0xb4, 0xd3, 0xd6, 0x69, 0x12, 0x32, 0x6d, 0x93, 0xa2,
+	0xd0, 0x32, 0x5c, 0x31, 0x8a, 0xbc, 0x76, 0x76, 0x62, 0x4b, 0x62, 0x77, 0x65, 0x26, 0x5c, 0x73,
+	0x01, 0xbf, 0x80, 0xe1, 0x16, 0x86, 0x4b, 0x6e, 0xf8, 0x37, 0xfc, 0x1b, 0x46, 0xfb, 0x21, 0x4b,
+	0xfe, 0x48, 0x9c, 0x4c, 0x07, 0xee, 0x7c, 0xf6, 0x9c, 0xdd, 0xf3, 0x3c, 0xe7, 0x1c, 0x3f, 0xbb,
+	0x82, 0x66, 0x4c, 0xa3, 0x31, 0xe9, 0x61, 0xda, 0x89, 0x69, 0xc4, 0x23, 0x64, 0xc5, 0xc9, 0x30,
+	0x19, 0x11, 0x1a, 0x07, 0x4e, 0x3d, 0x1e, 0x26, 0x03, 0x12, 0x4a, 0x87, 0x73, 0x77, 0x10, 0x45,
+	0x83, 0x21, 0xde, 0x11, 0xd6, 0x59, 0xd2, 0xdf, 0xc1, 0xa3, 0x98, 0x5f, 0x2a, 0xe7, 0xd6, 0xb4,
+	0x93, 0x71, 0x9a, 0x04, 0x5c, 0x7a, 0xdd, 0x5f, 0x4a, 0xd0, 0xda, 0x8b, 0xc2, 0x3e, 0x19, 0x24,
+	0x14, 0x7b, 0xf8, 0xbb, 0x04, 0x33, 0x8e, 0xbe, 0x00, 0x6b, 0xec, 0x53, 0xe2, 0x9f, 0x0d, 0x31,
+	0xb3, 0x8d, 0xb6, 0xb9, 0x5d, 0xdb, 0xbd, 0xd7, 0xc9, 0x92, 0x77, 0xa6, 0xe3, 0x3b, 0x6f, 0x75,
+	0xf0, 0x7e, 0xc8, 0xe9, 0xa5, 0x37, 0xd9, 0x8c, 0xee, 0x43, 0xd9, 0xa7, 0x03, 0x66, 0x97, 0xda,
+	0xc6, 0x76, 0x6d, 0x77, 0xa3, 0x23, 0xb1, 0x74, 0x34, 0x96, 0xce, 0xa9, 0xc0, 0xe2, 0x89, 0x20,
+	0x74, 0x0f, 0x5a, 0x24, 0xe4, 0x98, 0xf6, 0xfd, 0x00, 0xbf, 0xc5, 0x94, 0x91, 0x28, 0xb4, 0xcd,
+	0xb6, 0xb1, 0x5d, 0xf1, 0x66, 0xd6, 0x91, 0x0b, 0xf5, 0xc0, 0x8f, 0xfd, 0x33, 0x32, 0x24, 0x9c,
+	0x60, 0x66, 0x97, 0xdb, 0xe6, 0xb6, 0xe5, 0x15, 0xd6, 0x9c, 0x47, 0xd0, 0x2c, 0x22, 0x43, 0x2d,
+	0x30, 0x2f, 0xf0, 0xa5, 0x6d, 0xb4, 0x8d, 0x6d, 0xcb, 0x4b, 0x7f, 0xa2, 0xf7, 0xa0, 0x32, 0xf6,
+	0x87, 0x09, 0x16, 0x08, 0x2d, 0x4f, 0x1a, 0x0f, 0x4b, 0x9f, 0x1a, 0xee, 0x5f, 0x06, 0x6c, 0x66,
+	0x4c, 0xf7, 0x29, 0x8d, 0xe8, 0x2b, 0xc2, 0x18, 0x09, 0x07, 0x2f, 0xf0, 0x25, 0x43, 0x5f, 0x42,
+	0x6d, 0x34, 0x31, 0x55, 0x91, 0x76, 0xe6, 0x15, 0x69, 0x7a, 0x6b, 0x67, 0xf2, 0xdb, 0xcb, 0x9f,
+	0xe1, 0x74, 0x01, 0x26, 0x2e, 0x84, 0xa0, 0x1c, 0xfa, 0x23, 0xac, 0xb0, 0x8a, 0xdf, 0xa8, 0x0d,
+	0xb5, 0x1e, 0x66, 0x01, 0x25, 0x31, 0x4f, 0x6b, 0x23, 0x21, 0xe7, 0x97, 0xdc, 0x1f, 0x0d, 0x68,
+	0x1c, 0x85, 0xe3, 0xe8, 0x22, 0xeb, 0x65, 0x0b, 0x4c, 0x1e, 0x5d, 0x68, 0xca, 0x3c, 0xba, 0xb8,
+	0x59, 0x4f, 0x1c, 0x58, 0xd3, 0x53, 0x28, 0x7a, 0x61, 0x79, 0x99, 0x8d, 0x6c, 0x58, 0x1d, 0xab,
+	0x36, 0x95, 0x85, 0x4b, 0x9b, 0xee, 0x18, 0x9a, 0x1a, 0x05, 0x8b, 0xa3, 0x90, 0x61, 0xb4, 0x03,
+	0x55, 0x8a, 0x79, 0x42, 0x43, 0xdb, 0xb8, 0x3a, 0xad, 0x0a, 0x43, 0x0f, 0x60, 0xad, 0xef, 0x93,
+	0x61, 0x42, 0x71, 0x8a, 0xd4, 0x14, 0x5b, 0x72, 0xd5, 0x3d, 0xc7, 0xc1, 0xc5, 0x81, 0xf4, 0x7b,
+	0x59, 0xa0, 0xfb, 0x03, 0xd4, 0x85, 0x27, 0x47, 0x5e, 0xa7, 0xb4, 0xbc, 0xf4, 0x67, 0x4a, 0x3e,
+	0x1a, 0xf6, 0xae, 0x27, 0x9f, 0x06, 0xa5, 0xc1, 0x21, 0xfe, 0x9e, 0xd9, 0xe6, 0x35, 0xc1, 0x69,
+	0x90, 0x9b, 0x40, 0x43, 0xe5, 0x9e, 0x50, 0x26, 0x61, 0x9c, 0x70, 0x76, 0x2d, 0x65, 0x19, 0x76,
+	0x3b, 0xca, 0x5d, 0xa8, 0xe7, 0x3d, 0xaa, 0x61, 0x31, 0xa6, 0x5c, 0xcf, 0x79, 0x66, 0xa3, 0xf5,
+	0xb4, 0x09, 0x3e, 0xcb, 0x46, 0x47, 0x59, 0xee, 0xcf, 0x06, 0xd4, 0x9e, 0x93, 0x7e, 0x5f, 0x97,
+	0xad, 0x09, 0x25, 0xd2, 0x53, 0xbb, 0x4b, 0xa4, 0xa7, 0xcb, 0x58, 0x9a, 0x2d, 0xa3, 0x79, 0x93,
+	0x32, 0x96, 0x97, 0x29, 0xe3, 0x6f, 0x26, 0xd4, 0x25, 0x16, 0x55, 0x46, 0x07, 0xd6, 0x28, 0x8e,
+	0x87, 0x7e, 0xa0, 0xb4, 0xc8, 0xf2, 0x32, 0x3b, 0x9d, 0x40, 0xc6, 0xa5, 0x4c, 0x95, 0x84, 0x4b,
+	0x9b, 0xe8, 0x63, 0xb8, 0xd3, 0xc3, 0x43, 0xcc, 0x71, 0x17, 0xf7, 0xa3, 0x54, 0xa9, 0xc4, 0x0e,
+	0x81, 0x77, 0xcd, 0x9b, 0xe7, 0x42, 0x8f, 0x61, 0x35, 0x38, 0xf7, 0xc3, 0x01, 0x96, 0x40, 0x9b,
+	0xbb, 0x1f, 0xe4, 0x8a, 0x9f, 0x47, 0x24, 0x8c, 0x3d, 0x19, 0xea, 0xe9, 0x3d, 0xa9, 0x90, 0xf4,
+	0x48, 0xbf, 0xcf, 0xec, 0x8a, 0x00, 0x22, 0x0d, 0x74, 0x0a, 0x4d, 0x05, 0xd6, 0x13, 0xa5, 0x66,
+	0x76, 0x55, 0x34, 0xf6, 0xfe, 0xa2, 0xb3, 0xbd, 0x42, 0xb4, 0xd4, 0xd3, 0xa9, 0x23, 0x9c, 0x67,
+	0x70, 0x67, 0x4e, 0xd8, 0x8d, 0xc4, 0xed, 0x31, 0xd4, 0x72, 0x2c, 0x50, 0x0b, 0xea, 0xcf, 0x8f,
+	0x0e, 0x0e, 0xbe, 0x7d, 0x73, 0xfc, 0xe2, 0xf8, 0xe4, 0xeb, 0xe3, 0xd6, 0x0a, 0x6a, 0x80, 0x25,
+	0x56, 0x8e, 0x4f, 0x8e, 0xf7, 0x5b, 0x46, 0x66, 0x9e, 0x9e, 0xbc, 0xda, 0x6f, 0x95, 0x5c, 0x0e,
+	0x8d, 0x3d, 0x8a, 0x7d, 0x8e, 0x17, 0xff, 0xd1, 0x3e, 0x01, 0x50, 0x73, 0x47, 0xf0, 0xb5, 0x7f,
+	0xb7, 0x5c, 0x68, 0xda, 0x53, 0x4e, 0x46, 0x38, 0x4a, 0xb8, 0xe8, 0x96, 0xe1, 0x69, 0xd3, 0xfd,
+	0x06, 0x9a, 0x3a, 0xab, 0x9a, 0x8d, 0xe9, 0x41, 0xbd, 0x6d, 0x52, 0xf7, 0x57, 0x03, 0x6a, 0x1e,
+	0xf6, 0x7b, 0xcb, 0xff, 0x03, 0x8a, 0xa9, 0xcc, 0xe5, 0xf9, 0x4d, 0x64, 0xa1, 0xbc, 0x94, 0x2c,
+	0xb8, 0x3f, 0x19, 0x50, 0x97, 0xd8, 0xde, 0x31, 0xeb, 0x1c, 0x14, 0x73, 0x39, 0x28, 0x7f, 0x18,
+	0xd0, 0x78, 0x13, 0xf7, 0x72, 0x8d, 0xff, 0x0f, 0xa5, 0x22, 0x3f, 0x29, 0x95, 0xe2, 0xa4, 0x1c,
+	0x41, 0x53, 0xc3, 0x54, 0x35, 0x2b, 0xd6, 0xc8, 0x58, 0x7e, 0x32, 0xd2, 0x1b, 0xf5, 0xb9, 0x90,
+	0x8b, 0x7f, 0x61, 0x36, 0x72, 0x8c, 0xca, 0x45, 0x46, 0x7f, 0x1a, 0xb0, 0x21, 0x5e, 0x12, 0x1e,
+	0x66, 0x51, 0x42, 0x03, 0x7c, 0x14, 0x12, 0x9e, 0x6a, 0x3e, 0xee, 0xbd, 0xbb, 0x79, 0xb0, 0x61,
+	0x95, 0x2a, 0x99, 0x32, 0xa5, 0x9c, 0x2a, 0xf3, 0xe6, 0x43, 0xfb, 0x21, 0xb4, 0x0e, 0x31, 0x3f,
+	0x0d, 0xce, 0xf1, 0xc8, 0xd7, 0x85, 0xcb, 0xbd, 0x17, 0x0c, 0xf1, 0xac, 0xd3, 0xa6, 0x7b, 0x1f,
+	0xfe, 0x9f, 0x8b, 0x56, 0x2d, 0x5b, 0x87, 0x2a, 0x13, 0x2b, 0x8a, 0x9a, 0xb2, 0xdc, 0xdf, 0x0d,
+	0x68, 0x1e, 0x62, 0xfe, 0x32, 0x1a, 0xb0, 0xc5, 0xf2, 0x23, 0x6b, 0x52, 0x5a, 0x50, 0x93, 0x1b,
+	0xb4, 0x64, 0x0b, 0x2c, 0xc6, 0x7d, 0xca, 0xbf, 0x22, 0x23, 0x2c, 0xc8, 0x9b, 0xde, 0x64, 0x21,
+	0xa5, 0x84, 0xc3, 0x9e, 0xf0, 0x55, 0x84, 0x4f, 0x9b, 0xee, 0x53, 0xf8, 0x5f, 0x06, 0x52, 0x11,
+	0xfa, 0x28, 0x0d, 0xe6, 0x94, 0x64, 0x8f, 0xea, 0x3b, 0xb9, 0x5b, 0xe0, 0x65, 0x34, 0x90, 0x6a,
+	0xaf, 0x63, 0x5c, 0x0f, 0xd6, 0xf4, 0xe2, 0x4c, 0x8b, 0xb7, 0xc0, 0x4a, 0x27, 0x83, 0x71, 0x7f,
+	0x14, 0x0b, 0x96, 0xa6, 0x37, 0x59, 0x48, 0x51, 0x8d, 0x30, 0x63, 0xfe, 0x00, 0xab, 0x37, 0x9b,
+	0x36, 0x77, 0xff, 0x5e, 0x85, 0x96, 0x9e, 0xa0, 0xd7, 0xfa, 0x1d, 0xd7, 0x85, 0x9a, 0x78, 0x42,
+	0xc8, 0x27, 0x2b, 0x9a, 0x79, 0x74, 0xa8, 0x2a, 0x3b, 0xf6, 0xac, 0x43, 0x32, 0x73, 0x57, 0xd0,
+	0x13, 0x00, 0x71, 0xa1, 0xc8, 0x23, 0xd6, 0x67, 0xae, 0x37, 0x79, 0xc2, 0xc6, 0x82, 0x6b, 0xcf,
+	0x5d, 0x41, 0x5d, 0xb0, 0xb2, 0x27, 0x33, 0xba, 0x7b, 0xc5, 0xd7, 0x86, 0xb3, 0x3e, 0xd3, 0xb6,
+	0xfd, 0xf4, 0x73, 0x47, 0x80, 0xa8, 0xca, 0x67, 0x27, 0xca, 0x43, 0x2d, 0xbc, 0x87, 0x9d, 0xcd,
+	0x39, 0x9e, 0x0c, 0xc4, 0x23, 0xa8, 0x08, 0x62, 0xb7, 0xab, 0xc1, 0x67, 0x50, 0x4e, 0x49, 0xdd,
+	0x86, 0xfd, 0x13, 0xa8, 0xca, 0xab, 0xad, 0x80, 0xbc, 0x70, 0xc7, 0x3a, 0x9b, 0x73, 0x3c, 0xf9,
+	0xdc, 0xe9, 0x1d, 0x51, 0xc8, 0x9d, 0xbb, 0xd0, 0x9c, 0x8d, 0x99, 0xf5, 0x7c, 0x6e, 0x29, 0x96,
+	0x85, 0xdc, 0x05, 0x99, 0x77, 0x36, 0xe7, 0x78, 0x72, 0x55, 0xab, 0x4a, 0x85, 0x2c, 0x1c, 0x50,
+	0x10, 0xcd, 0x2b, 0x9a, 0xf6, 0x10, 0xaa, 0x7b, 0x7e, 0x18, 0xe0, 0x21, 0x5a, 0x10, 0x73, 0xc5,
+	0xde, 0xa7, 0xd0, 0x38, 0xc4, 0xfc, 0xb5, 0xf8, 0x16, 0x3e, 0x0a, 0xfb, 0xd1, 0xc2, 0x23, 0xde,
+	0xcf, 0x01, 0x9b, 0x84, 0xbb, 0x2b, 0xe9, 0xa7, 0x6e, 0xa6, 0x3c, 0x85, 0xb1, 0x9b, 0x56, 0x2f,
+	0x67, 0x6b, 0xbe, 0x33, 0xab, 0xc2, 0xe7, 0x50, 0x7d, 0xc6, 0xb9, 0x1f, 0x9c, 0xa3, 0x8d, 0x99,
+	0x64, 0xd2, 0x71, 0x05, 0x91, 0x2e, 0xac, 0x2a, 0xb5, 0x40, 0x9b, 0xc5, 0x3c, 0x39, 0x99, 0x73,
+	0x9c, 0x79, 0x2e, 0x0d, 0xe0, 0xac, 0x2a, 0x4e, 0x7d, 0xf0, 0xcf, 0x00, 0x05, 0x23, 0x22, 0x27,
+	0x37, 0x10, 0x00, 0x00,
 }
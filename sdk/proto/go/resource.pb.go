@@ -34,6 +34,7 @@ type ReadResourceRequest struct {
 	Properties           *_struct.Struct `protobuf:"bytes,5,opt,name=properties" json:"properties,omitempty"`
 	Dependencies         []string        `protobuf:"bytes,6,rep,name=dependencies" json:"dependencies,omitempty"`
 	Provider             string          `protobuf:"bytes,7,opt,name=provider" json:"provider,omitempty"`
+	Version              string          `protobuf:"bytes,8,opt,name=version" json:"version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
 	XXX_unrecognized     []byte          `json:"-"`
 	XXX_sizecache        int32           `json:"-"`
@@ -43,7 +44,7 @@ func (m *ReadResourceRequest) Reset()         { *m = ReadResourceRequest{} }
 func (m *ReadResourceRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadResourceRequest) ProtoMessage()    {}
 func (*ReadResourceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_03e51d5764cd9ae8, []int{0}
+	return fileDescriptor_resource_e462e6b41ab42113, []int{0}
 }
 func (m *ReadResourceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResourceRequest.Unmarshal(m, b)
@@ -112,6 +113,13 @@ func (m *ReadResourceRequest) GetProvider() string {
 	return ""
 }
 
+func (m *ReadResourceRequest) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
 // ReadResourceResponse contains the result of reading a resource's state.
 type ReadResourceResponse struct {
 	Urn                  string          `protobuf:"bytes,1,opt,name=urn" json:"urn,omitempty"`
@@ -125,7 +133,7 @@ func (m *ReadResourceResponse) Reset()         { *m = ReadResourceResponse{} }
 func (m *ReadResourceResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResourceResponse) ProtoMessage()    {}
 func (*ReadResourceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_03e51d5764cd9ae8, []int{1}
+	return fileDescriptor_resource_e462e6b41ab42113, []int{1}
 }
 func (m *ReadResourceResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResourceResponse.Unmarshal(m, b)
@@ -171,6 +179,10 @@ type RegisterResourceRequest struct {
 	Provider             string                                                   `protobuf:"bytes,8,opt,name=provider" json:"provider,omitempty"`
 	PropertyDependencies map[string]*RegisterResourceRequest_PropertyDependencies `protobuf:"bytes,9,rep,name=propertyDependencies" json:"propertyDependencies,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	DeleteBeforeReplace  bool                                                     `protobuf:"varint,10,opt,name=deleteBeforeReplace" json:"deleteBeforeReplace,omitempty"`
+	IgnoreChanges        []string                                                 `protobuf:"bytes,11,rep,name=ignoreChanges" json:"ignoreChanges,omitempty"`
+	CustomTimeouts       *RegisterResourceRequest_CustomTimeouts                  `protobuf:"bytes,12,opt,name=customTimeouts" json:"customTimeouts,omitempty"`
+	ImportId             string                                                   `protobuf:"bytes,13,opt,name=importId" json:"importId,omitempty"`
+	Version              string                                                   `protobuf:"bytes,14,opt,name=version" json:"version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                                                 `json:"-"`
 	XXX_unrecognized     []byte                                                   `json:"-"`
 	XXX_sizecache        int32                                                    `json:"-"`
@@ -180,7 +192,7 @@ func (m *RegisterResourceRequest) Reset()         { *m = RegisterResourceRequest
 func (m *RegisterResourceRequest) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceRequest) ProtoMessage()    {}
 func (*RegisterResourceRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_03e51d5764cd9ae8, []int{2}
+	return fileDescriptor_resource_e462e6b41ab42113, []int{2}
 }
 func (m *RegisterResourceRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceRequest.Unmarshal(m, b)
@@ -270,6 +282,34 @@ func (m *RegisterResourceRequest) GetDeleteBeforeReplace() bool {
 	return false
 }
 
+func (m *RegisterResourceRequest) GetIgnoreChanges() []string {
+	if m != nil {
+		return m.IgnoreChanges
+	}
+	return nil
+}
+
+func (m *RegisterResourceRequest) GetCustomTimeouts() *RegisterResourceRequest_CustomTimeouts {
+	if m != nil {
+		return m.CustomTimeouts
+	}
+	return nil
+}
+
+func (m *RegisterResourceRequest) GetImportId() string {
+	if m != nil {
+		return m.ImportId
+	}
+	return ""
+}
+
+func (m *RegisterResourceRequest) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
 // PropertyDependencies describes the resources that a particular property depends on.
 type RegisterResourceRequest_PropertyDependencies struct {
 	Urns                 []string `protobuf:"bytes,1,rep,name=urns" json:"urns,omitempty"`
@@ -286,7 +326,7 @@ func (m *RegisterResourceRequest_PropertyDependencies) String() string {
 }
 func (*RegisterResourceRequest_PropertyDependencies) ProtoMessage() {}
 func (*RegisterResourceRequest_PropertyDependencies) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_03e51d5764cd9ae8, []int{2, 0}
+	return fileDescriptor_resource_e462e6b41ab42113, []int{2, 0}
 }
 func (m *RegisterResourceRequest_PropertyDependencies) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceRequest_PropertyDependencies.Unmarshal(m, b)
@@ -313,6 +353,64 @@ func (m *RegisterResourceRequest_PropertyDependencies) GetUrns() []string {
 	return nil
 }
 
+// CustomTimeouts allows a user to be able to create a set of custom timeout parameters. Each timeout is a duration
+// string such as "5m" or "1h30m"; an empty string means that the default timeout should be used.
+type RegisterResourceRequest_CustomTimeouts struct {
+	Create               string   `protobuf:"bytes,1,opt,name=create" json:"create,omitempty"`
+	Update               string   `protobuf:"bytes,2,opt,name=update" json:"update,omitempty"`
+	Delete               string   `protobuf:"bytes,3,opt,name=delete" json:"delete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RegisterResourceRequest_CustomTimeouts) Reset() {
+	*m = RegisterResourceRequest_CustomTimeouts{}
+}
+func (m *RegisterResourceRequest_CustomTimeouts) String() string { return proto.CompactTextString(m) }
+func (*RegisterResourceRequest_CustomTimeouts) ProtoMessage()    {}
+func (*RegisterResourceRequest_CustomTimeouts) Descriptor() ([]byte, []int) {
+	return fileDescriptor_resource_e462e6b41ab42113, []int{2, 1}
+}
+func (m *RegisterResourceRequest_CustomTimeouts) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegisterResourceRequest_CustomTimeouts.Unmarshal(m, b)
+}
+func (m *RegisterResourceRequest_CustomTimeouts) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegisterResourceRequest_CustomTimeouts.Marshal(b, m, deterministic)
+}
+func (dst *RegisterResourceRequest_CustomTimeouts) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegisterResourceRequest_CustomTimeouts.Merge(dst, src)
+}
+func (m *RegisterResourceRequest_CustomTimeouts) XXX_Size() int {
+	return xxx_messageInfo_RegisterResourceRequest_CustomTimeouts.Size(m)
+}
+func (m *RegisterResourceRequest_CustomTimeouts) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegisterResourceRequest_CustomTimeouts.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegisterResourceRequest_CustomTimeouts proto.InternalMessageInfo
+
+func (m *RegisterResourceRequest_CustomTimeouts) GetCreate() string {
+	if m != nil {
+		return m.Create
+	}
+	return ""
+}
+
+func (m *RegisterResourceRequest_CustomTimeouts) GetUpdate() string {
+	if m != nil {
+		return m.Update
+	}
+	return ""
+}
+
+func (m *RegisterResourceRequest_CustomTimeouts) GetDelete() string {
+	if m != nil {
+		return m.Delete
+	}
+	return ""
+}
+
 // RegisterResourceResponse is returned by the engine after a resource has finished being initialized.  It includes the
 // auto-assigned URN, the provider-assigned ID, and any other properties initialized by the engine.
 type RegisterResourceResponse struct {
@@ -330,7 +428,7 @@ func (m *RegisterResourceResponse) Reset()         { *m = RegisterResourceRespon
 func (m *RegisterResourceResponse) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceResponse) ProtoMessage()    {}
 func (*RegisterResourceResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_03e51d5764cd9ae8, []int{3}
+	return fileDescriptor_resource_e462e6b41ab42113, []int{3}
 }
 func (m *RegisterResourceResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceResponse.Unmarshal(m, b)
@@ -398,7 +496,7 @@ func (m *RegisterResourceOutputsRequest) Reset()         { *m = RegisterResource
 func (m *RegisterResourceOutputsRequest) String() string { return proto.CompactTextString(m) }
 func (*RegisterResourceOutputsRequest) ProtoMessage()    {}
 func (*RegisterResourceOutputsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_resource_03e51d5764cd9ae8, []int{4}
+	return fileDescriptor_resource_e462e6b41ab42113, []int{4}
 }
 func (m *RegisterResourceOutputsRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_RegisterResourceOutputsRequest.Unmarshal(m, b)
@@ -438,6 +536,7 @@ func init() {
 	proto.RegisterType((*RegisterResourceRequest)(nil), "pulumirpc.RegisterResourceRequest")
 	proto.RegisterMapType((map[string]*RegisterResourceRequest_PropertyDependencies)(nil), "pulumirpc.RegisterResourceRequest.PropertyDependenciesEntry")
 	proto.RegisterType((*RegisterResourceRequest_PropertyDependencies)(nil), "pulumirpc.RegisterResourceRequest.PropertyDependencies")
+	proto.RegisterType((*RegisterResourceRequest_CustomTimeouts)(nil), "pulumirpc.RegisterResourceRequest.CustomTimeouts")
 	proto.RegisterType((*RegisterResourceResponse)(nil), "pulumirpc.RegisterResourceResponse")
 	proto.RegisterType((*RegisterResourceOutputsRequest)(nil), "pulumirpc.RegisterResourceOutputsRequest")
 }
@@ -613,46 +712,53 @@ var _ResourceMonitor_serviceDesc = grpc.ServiceDesc{
 	Metadata: "resource.proto",
 }
 
-func init() { proto.RegisterFile("resource.proto", fileDescriptor_resource_03e51d5764cd9ae8) }
-
-var fileDescriptor_resource_03e51d5764cd9ae8 = []byte{
-	// 601 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xae, 0x9d, 0xd6, 0x49, 0xa6, 0x55, 0xa8, 0xb6, 0x51, 0xeb, 0x1a, 0x54, 0x2a, 0x73, 0x01,
-	0x0e, 0x0e, 0x84, 0x43, 0x11, 0x42, 0x42, 0x42, 0xf4, 0xc0, 0xa1, 0x02, 0xcc, 0x19, 0x24, 0xc7,
-	0x9e, 0x46, 0xa6, 0x8e, 0x77, 0x59, 0xaf, 0x23, 0xe5, 0xc6, 0x9b, 0xf0, 0x48, 0xbc, 0x01, 0x27,
-	0x1e, 0x84, 0x5d, 0xaf, 0x1d, 0xe2, 0xd8, 0x69, 0x2a, 0x4e, 0x99, 0xbf, 0xfd, 0x76, 0xe6, 0x9b,
-	0x6f, 0x1d, 0x18, 0x70, 0xcc, 0x68, 0xce, 0x43, 0xf4, 0x18, 0xa7, 0x82, 0x92, 0x3e, 0xcb, 0x93,
-	0x7c, 0x16, 0x73, 0x16, 0x3a, 0xf7, 0xa7, 0x94, 0x4e, 0x13, 0x1c, 0x15, 0x89, 0x49, 0x7e, 0x3d,
-	0xc2, 0x19, 0x13, 0x0b, 0x5d, 0xe7, 0x3c, 0x58, 0x4f, 0x66, 0x82, 0xe7, 0xa1, 0x28, 0xb3, 0x03,
-	0xf9, 0x33, 0x8f, 0x23, 0xe4, 0xda, 0x77, 0x7f, 0x1b, 0x70, 0xe4, 0x63, 0x10, 0xf9, 0xe5, 0x65,
-	0x3e, 0x7e, 0xcf, 0x31, 0x13, 0x64, 0x00, 0x66, 0x1c, 0xd9, 0xc6, 0xb9, 0xf1, 0xb8, 0xef, 0x4b,
-	0x8b, 0x10, 0xd8, 0x15, 0x0b, 0x86, 0xb6, 0x59, 0x44, 0x0a, 0x5b, 0xc5, 0xd2, 0x60, 0x86, 0x76,
-	0x47, 0xc7, 0x94, 0x4d, 0x8e, 0xc1, 0x62, 0x01, 0xc7, 0x54, 0xd8, 0xbb, 0x45, 0xb4, 0xf4, 0xc8,
-	0x05, 0x80, 0xbc, 0x90, 0x21, 0x17, 0x31, 0x66, 0xf6, 0x9e, 0xcc, 0xed, 0x8f, 0x4f, 0x3c, 0xdd,
-	0xaa, 0x57, 0xb5, 0xea, 0x7d, 0x2e, 0x5a, 0xf5, 0x57, 0x4a, 0x89, 0x0b, 0x07, 0x11, 0x32, 0x4c,
-	0x23, 0x4c, 0x43, 0x75, 0xd4, 0x3a, 0xef, 0x48, 0xd8, 0x5a, 0x8c, 0x38, 0xd0, 0xab, 0xc6, 0xb2,
-	0xbb, 0xc5, 0xb5, 0x4b, 0xdf, 0x0d, 0x60, 0x58, 0x9f, 0x2f, 0x63, 0x34, 0xcd, 0x90, 0x1c, 0x42,
-	0x27, 0xe7, 0x69, 0x39, 0xa1, 0x32, 0xd7, 0x5a, 0x34, 0xef, 0xdc, 0xa2, 0xfb, 0x6b, 0x17, 0x4e,
-	0x7c, 0x9c, 0xc6, 0x99, 0x40, 0xbe, 0xce, 0x63, 0xc5, 0x9b, 0xd1, 0xc2, 0x9b, 0xd9, 0xca, 0x5b,
-	0xa7, 0xc6, 0x9b, 0x8c, 0x87, 0x79, 0x26, 0xe8, 0xac, 0xe0, 0xb3, 0xe7, 0x97, 0x1e, 0x19, 0x81,
-	0x45, 0x27, 0xdf, 0x30, 0x14, 0xdb, 0xb8, 0x2c, 0xcb, 0x88, 0x0d, 0x5d, 0x95, 0x52, 0x27, 0xac,
-	0x02, 0xa9, 0x72, 0x1b, 0x0c, 0x77, 0xb7, 0x30, 0xdc, 0xab, 0x33, 0x4c, 0x18, 0x0c, 0x4b, 0x32,
-	0x16, 0xef, 0x56, 0x71, 0xfa, 0x12, 0x67, 0x7f, 0xfc, 0xda, 0x5b, 0xea, 0xd6, 0xdb, 0x40, 0x92,
-	0xf7, 0xb1, 0xe5, 0xf8, 0x65, 0x2a, 0xf8, 0xc2, 0x6f, 0x45, 0x26, 0xcf, 0xe0, 0x28, 0xc2, 0x04,
-	0x05, 0xbe, 0xc5, 0x6b, 0xca, 0x25, 0x0c, 0x4b, 0x82, 0x10, 0x6d, 0x28, 0xe6, 0x6a, 0x4b, 0x39,
-	0x4f, 0x61, 0xd8, 0x76, 0x89, 0x5a, 0x85, 0x5c, 0x7d, 0x26, 0xd7, 0xa3, 0x66, 0x2e, 0x6c, 0xe7,
-	0x87, 0x01, 0xa7, 0x1b, 0x3b, 0x52, 0xba, 0xb9, 0xc1, 0x45, 0xa5, 0x1b, 0x69, 0x92, 0x2b, 0xd8,
-	0x9b, 0x07, 0x49, 0x8e, 0xa5, 0x64, 0x2e, 0xfe, 0x73, 0x60, 0x5f, 0xa3, 0xbc, 0x32, 0x5f, 0x1a,
-	0xee, 0x4f, 0x03, 0xec, 0xe6, 0xd9, 0x8d, 0xca, 0xd5, 0x8f, 0xd5, 0x5c, 0x3e, 0xd6, 0x7f, 0xe2,
-	0xe8, 0xdc, 0x4d, 0x1c, 0x52, 0x65, 0x99, 0x08, 0x26, 0x09, 0x56, 0x2a, 0xd3, 0x9e, 0x12, 0x8d,
-	0xb6, 0xd4, 0x93, 0x55, 0x0c, 0x55, 0xae, 0x8b, 0x70, 0xb6, 0xde, 0xe0, 0x87, 0x5c, 0xb0, 0x5c,
-	0x64, 0x95, 0xf2, 0x9b, 0x6d, 0x3e, 0x87, 0x2e, 0xd5, 0x35, 0xdb, 0x5e, 0x57, 0x55, 0x37, 0xfe,
-	0x63, 0xc2, 0xbd, 0x0a, 0xff, 0x8a, 0xa6, 0xb1, 0xa0, 0x9c, 0xbc, 0x01, 0xeb, 0x7d, 0x3a, 0xa7,
-	0x37, 0xb2, 0xbd, 0x15, 0xaa, 0x75, 0xa8, 0xbc, 0xdc, 0x39, 0x6d, 0xc9, 0x68, 0xfa, 0xdc, 0x1d,
-	0xf2, 0x09, 0x0e, 0x56, 0x3f, 0x09, 0xe4, 0xac, 0xb6, 0xb1, 0xc6, 0xb7, 0xd0, 0x79, 0xb8, 0x31,
-	0xbf, 0x84, 0xfc, 0x02, 0x87, 0xeb, 0x74, 0x10, 0x77, 0xbb, 0x10, 0x9c, 0x47, 0xb7, 0xd6, 0x2c,
-	0xe1, 0xbf, 0x36, 0x3f, 0x30, 0x25, 0xdb, 0xe4, 0xc9, 0x2d, 0x08, 0xf5, 0x8d, 0x38, 0xc7, 0x0d,
-	0xba, 0x2f, 0xd5, 0xff, 0x86, 0xbb, 0x33, 0xb1, 0x8a, 0xc8, 0x8b, 0xbf, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x00, 0xdf, 0xec, 0x9d, 0x74, 0x06, 0x00, 0x00,
+func init() { proto.RegisterFile("resource.proto", fileDescriptor_resource_e462e6b41ab42113) }
+
+var fileDescriptor_resource_e462e6b41ab42113 = []byte{
+	// 707 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x95, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xc7, 0x6b, 0xa7, 0x75, 0x92, 0x69, 0x1b, 0xaa, 0x6d, 0x95, 0x6e, 0x0d, 0x2a, 0x91, 0xe1,
+	0x10, 0x38, 0xa4, 0xb4, 0x1c, 0x8a, 0x10, 0x12, 0x12, 0xa5, 0x87, 0x1e, 0x2a, 0xc0, 0x70, 0x80,
+	0x03, 0x48, 0x4e, 0x3c, 0x0d, 0xa6, 0x89, 0x77, 0x59, 0xaf, 0x23, 0xe5, 0xc6, 0x9b, 0xf0, 0x6a,
+	0x1c, 0x78, 0x08, 0x8e, 0x68, 0x77, 0xbd, 0x21, 0xce, 0x47, 0x53, 0x71, 0xdb, 0xff, 0xcc, 0xee,
+	0x78, 0xe6, 0xb7, 0x33, 0x6b, 0x68, 0x08, 0xcc, 0x58, 0x2e, 0x7a, 0xd8, 0xe1, 0x82, 0x49, 0x46,
+	0xea, 0x3c, 0x1f, 0xe4, 0xc3, 0x44, 0xf0, 0x9e, 0x7f, 0xb7, 0xcf, 0x58, 0x7f, 0x80, 0x47, 0xda,
+	0xd1, 0xcd, 0xaf, 0x8e, 0x70, 0xc8, 0xe5, 0xd8, 0xec, 0xf3, 0xef, 0xcd, 0x3a, 0x33, 0x29, 0xf2,
+	0x9e, 0x2c, 0xbc, 0x0d, 0x2e, 0xd8, 0x28, 0x89, 0x51, 0x18, 0x1d, 0xfc, 0x71, 0x60, 0x37, 0xc4,
+	0x28, 0x0e, 0x8b, 0x8f, 0x85, 0xf8, 0x3d, 0xc7, 0x4c, 0x92, 0x06, 0xb8, 0x49, 0x4c, 0x9d, 0x96,
+	0xd3, 0xae, 0x87, 0x6e, 0x12, 0x13, 0x02, 0xeb, 0x72, 0xcc, 0x91, 0xba, 0xda, 0xa2, 0xd7, 0xca,
+	0x96, 0x46, 0x43, 0xa4, 0x15, 0x63, 0x53, 0x6b, 0xd2, 0x04, 0x8f, 0x47, 0x02, 0x53, 0x49, 0xd7,
+	0xb5, 0xb5, 0x50, 0xe4, 0x14, 0x80, 0x0b, 0xc6, 0x51, 0xc8, 0x04, 0x33, 0xba, 0xd1, 0x72, 0xda,
+	0x9b, 0x27, 0xfb, 0x1d, 0x93, 0x6a, 0xc7, 0xa6, 0xda, 0x79, 0xaf, 0x53, 0x0d, 0xa7, 0xb6, 0x92,
+	0x00, 0xb6, 0x62, 0xe4, 0x98, 0xc6, 0x98, 0xf6, 0xd4, 0x51, 0xaf, 0x55, 0x69, 0xd7, 0xc3, 0x92,
+	0x8d, 0xf8, 0x50, 0xb3, 0x65, 0xd1, 0xaa, 0xfe, 0xec, 0x44, 0x13, 0x0a, 0xd5, 0x11, 0x8a, 0x2c,
+	0x61, 0x29, 0xad, 0x69, 0x97, 0x95, 0x41, 0x04, 0x7b, 0xe5, 0xca, 0x33, 0xce, 0xd2, 0x0c, 0xc9,
+	0x0e, 0x54, 0x72, 0x91, 0x16, 0xb5, 0xab, 0xe5, 0x4c, 0xf2, 0xee, 0xad, 0x93, 0x0f, 0x7e, 0x79,
+	0xb0, 0x1f, 0x62, 0x3f, 0xc9, 0x24, 0x8a, 0x59, 0xc2, 0x96, 0xa8, 0xb3, 0x80, 0xa8, 0xbb, 0x90,
+	0x68, 0xa5, 0x44, 0xb4, 0x09, 0x5e, 0x2f, 0xcf, 0x24, 0x1b, 0x6a, 0xd2, 0xb5, 0xb0, 0x50, 0xe4,
+	0x08, 0x3c, 0xd6, 0xfd, 0x86, 0x3d, 0xb9, 0x8a, 0x72, 0xb1, 0x4d, 0x11, 0x52, 0x2e, 0x75, 0xc2,
+	0xd3, 0x91, 0xac, 0x9c, 0x63, 0x5f, 0x5d, 0xc1, 0xbe, 0x36, 0xc3, 0x9e, 0xc3, 0x5e, 0x01, 0x63,
+	0xfc, 0x7a, 0x3a, 0x4e, 0xbd, 0x55, 0x69, 0x6f, 0x9e, 0xbc, 0xe8, 0x4c, 0x3a, 0xba, 0xb3, 0x04,
+	0x52, 0xe7, 0xed, 0x82, 0xe3, 0xe7, 0xa9, 0x14, 0xe3, 0x70, 0x61, 0x64, 0xf2, 0x04, 0x76, 0x63,
+	0x1c, 0xa0, 0xc4, 0x57, 0x78, 0xc5, 0x04, 0x86, 0xc8, 0x07, 0x51, 0x0f, 0x29, 0xe8, 0xba, 0x16,
+	0xb9, 0xc8, 0x43, 0xd8, 0x4e, 0xfa, 0x29, 0x13, 0x78, 0xf6, 0x35, 0x4a, 0xfb, 0x98, 0xd1, 0x4d,
+	0x5d, 0x64, 0xd9, 0x48, 0x3e, 0x41, 0xc3, 0xe0, 0xfd, 0x90, 0x0c, 0x91, 0xe5, 0x32, 0xa3, 0x5b,
+	0x1a, 0xee, 0xf1, 0x2d, 0x6a, 0x38, 0x2b, 0x1d, 0x0c, 0x67, 0x02, 0x29, 0x80, 0xc9, 0x90, 0x33,
+	0x21, 0x2f, 0x62, 0xba, 0x6d, 0x00, 0x5a, 0x3d, 0xdd, 0xbc, 0x8d, 0x52, 0xf3, 0xfa, 0x8f, 0x61,
+	0x6f, 0x11, 0x1b, 0xd5, 0x41, 0xb9, 0x48, 0x33, 0xea, 0xe8, 0x2a, 0xf4, 0xda, 0xff, 0x08, 0x8d,
+	0x72, 0x0e, 0xba, 0x77, 0x04, 0x46, 0xd2, 0x76, 0x5f, 0xa1, 0x94, 0x3d, 0xe7, 0x71, 0x24, 0x6d,
+	0x07, 0x16, 0x4a, 0xd9, 0x0d, 0x3b, 0xdb, 0x83, 0x46, 0xf9, 0x3f, 0x1c, 0x38, 0x58, 0x7a, 0x45,
+	0x6a, 0x90, 0xae, 0x71, 0x6c, 0x07, 0xe9, 0x1a, 0xc7, 0xe4, 0x12, 0x36, 0x46, 0xd1, 0x20, 0xc7,
+	0x62, 0x86, 0x4e, 0xff, 0xb3, 0x03, 0x42, 0x13, 0xe5, 0xb9, 0xfb, 0xcc, 0x09, 0x7e, 0x3a, 0x40,
+	0xe7, 0xcf, 0x2e, 0x1d, 0x65, 0xf3, 0xae, 0xb9, 0x93, 0x77, 0xed, 0xdf, 0xb4, 0x54, 0x6e, 0x37,
+	0x2d, 0x4d, 0xf0, 0x32, 0x19, 0x75, 0x07, 0x68, 0xc7, 0xce, 0x28, 0x75, 0x55, 0x66, 0xa5, 0x5e,
+	0x37, 0xc5, 0xde, 0xca, 0x00, 0xe1, 0x70, 0x36, 0xc1, 0x37, 0xb9, 0xe4, 0xaa, 0x17, 0x8a, 0xa7,
+	0x60, 0x3e, 0xcd, 0x63, 0xa8, 0x32, 0xb3, 0x67, 0xd5, 0x73, 0x63, 0xf7, 0x9d, 0xfc, 0x76, 0xe1,
+	0x8e, 0x8d, 0x7f, 0xc9, 0xd2, 0x44, 0x32, 0x41, 0x5e, 0x82, 0x77, 0x91, 0x8e, 0xd8, 0x35, 0x12,
+	0x3a, 0x85, 0xda, 0x98, 0x8a, 0x8f, 0xfb, 0x07, 0x0b, 0x3c, 0x06, 0x5f, 0xb0, 0x46, 0xde, 0xc1,
+	0xd6, 0xf4, 0x1b, 0x49, 0x0e, 0x4b, 0x37, 0x36, 0xf7, 0xdb, 0xf0, 0xef, 0x2f, 0xf5, 0x4f, 0x42,
+	0x7e, 0x86, 0x9d, 0x59, 0x1c, 0x24, 0x58, 0xdd, 0x08, 0xfe, 0x83, 0x1b, 0xf7, 0x4c, 0xc2, 0x7f,
+	0x81, 0xfd, 0x25, 0xb4, 0xc9, 0xa3, 0x1b, 0x22, 0x94, 0x6f, 0xc4, 0x6f, 0xce, 0xe1, 0x3e, 0x57,
+	0xbf, 0xd8, 0x60, 0xad, 0xeb, 0x69, 0xcb, 0xd3, 0xbf, 0x03, 0x00, 0xc2, 0xcc, 0xa9, 0x59, 0x9f,
+	0x07, 0x00, 0x00,
 }
@@ -0,0 +1,3 @@
+@echo off
+setlocal
+@python -u -m pulumi.dynamic %*
@@ -58,6 +58,7 @@ from .resource import (
     ComponentResource,
     ProviderResource,
     ResourceOptions,
+    CustomTimeouts,
     export,
 )
 
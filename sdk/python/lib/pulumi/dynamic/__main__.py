@@ -0,0 +1,186 @@
+# Copyright 2016-2019, Pulumi Corporation.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""
+The resource provider plugin for dynamic resources, which is run as `python -m pulumi.dynamic <engine>` by the
+pulumi-resource-pulumi-python plugin. Each operation deserializes the provider that is recorded in the resource's
+properties and delegates to it.
+"""
+
+import sys
+import time
+import traceback
+from concurrent import futures
+from typing import Any, Dict
+
+import grpc
+from google.protobuf import empty_pb2, struct_pb2
+
+from ..runtime import rpc
+from ..runtime.proto import plugin_pb2, provider_pb2, provider_pb2_grpc
+from .dynamic import PROVIDER_KEY, ResourceProvider, deserialize_provider
+
+
+def get_provider(props: Dict[str, Any]) -> ResourceProvider:
+    return deserialize_provider(props[PROVIDER_KEY])
+
+
+def to_struct(props: Dict[str, Any]) -> struct_pb2.Struct:
+    struct = struct_pb2.Struct()
+    struct.update(props)
+    return struct
+
+
+def result_including_provider(result: Any, props: Dict[str, Any]) -> Dict[str, Any]:
+    # The provider is carried along in the resource's outputs, so that later operations on the resource can use it.
+    outs = dict(result or {})
+    outs[PROVIDER_KEY] = props[PROVIDER_KEY]
+    return outs
+
+
+class DynamicResourceProviderServicer(provider_pb2_grpc.ResourceProviderServicer):
+    """
+    DynamicResourceProviderServicer implements the resource provider RPC interface for dynamic resources.
+
+    Note that diff takes no special action if the provider itself has changed. This allows a user to iterate on a
+    dynamic provider's implementation, so long as each iteration can handle all of the state produced by earlier ones.
+    """
+
+    def fail(self, context, exn: Exception, response):
+        traceback.print_exc(file=sys.stderr)
+        context.set_code(grpc.StatusCode.UNKNOWN)
+        context.set_details(str(exn))
+        return response
+
+    def CheckConfig(self, request, context):
+        return provider_pb2.CheckResponse(inputs=request.news)
+
+    def DiffConfig(self, request, context):
+        return provider_pb2.DiffResponse()
+
+    def Configure(self, request, context):
+        return empty_pb2.Empty()
+
+    def Invoke(self, request, context):
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details("unknown function %s" % request.tok)
+        return provider_pb2.InvokeResponse()
+
+    def Check(self, request, context):
+        try:
+            olds = rpc.deserialize_properties(request.olds)
+            news = rpc.deserialize_properties(request.news)
+            # If the provider is not known yet, e.g. because the program's provider depends on an output that is not
+            # known during a preview, check the inputs with the resource's previous provider.
+            provider = get_provider(news if PROVIDER_KEY in news else olds)
+
+            result = provider.check(olds, news)
+            inputs = dict(result.inputs if result.inputs is not None else news)
+            if PROVIDER_KEY in news:
+                inputs[PROVIDER_KEY] = news[PROVIDER_KEY]
+            else:
+                inputs[PROVIDER_KEY] = rpc.UNKNOWN
+
+            failures = [provider_pb2.CheckFailure(property=f.property, reason=f.reason) for f in result.failures]
+            return provider_pb2.CheckResponse(inputs=to_struct(inputs), failures=failures)
+        except Exception as exn: # pylint: disable=broad-except
+            return self.fail(context, exn, provider_pb2.CheckResponse())
+
+    def Diff(self, request, context):
+        try:
+            olds = rpc.deserialize_properties(request.olds)
+            news = rpc.deserialize_properties(request.news)
+            provider = get_provider(news if PROVIDER_KEY in news else olds)
+
+            result = provider.diff(request.id, olds, news)
+            if result.changes is True:
+                changes = provider_pb2.DiffResponse.DIFF_SOME
+            elif result.changes is False:
+                changes = provider_pb2.DiffResponse.DIFF_NONE
+            else:
+                changes = provider_pb2.DiffResponse.DIFF_UNKNOWN
+            return provider_pb2.DiffResponse(
+                changes=changes,
+                replaces=result.replaces,
+                replaceReasons=result.replace_reasons,
+                stables=result.stables,
+                deleteBeforeReplace=result.delete_before_replace)
+        except Exception as exn: # pylint: disable=broad-except
+            return self.fail(context, exn, provider_pb2.DiffResponse())
+
+    def Create(self, request, context):
+        try:
+            props = rpc.deserialize_properties(request.properties)
+            result = get_provider(props).create(props)
+            outs = result_including_provider(result.outs, props)
+            return provider_pb2.CreateResponse(id=result.id, properties=to_struct(outs))
+        except Exception as exn: # pylint: disable=broad-except
+            return self.fail(context, exn, provider_pb2.CreateResponse())
+
+    def Read(self, request, context):
+        try:
+            props = rpc.deserialize_properties(request.properties)
+            result = get_provider(props).read(request.id, props)
+            if not result.id:
+                # The resource no longer exists.
+                return provider_pb2.ReadResponse()
+            outs = result_including_provider(result.outs, props)
+            return provider_pb2.ReadResponse(id=result.id, properties=to_struct(outs))
+        except Exception as exn: # pylint: disable=broad-except
+            return self.fail(context, exn, provider_pb2.ReadResponse())
+
+    def Update(self, request, context):
+        try:
+            olds = rpc.deserialize_properties(request.olds)
+            news = rpc.deserialize_properties(request.news)
+            result = get_provider(news).update(request.id, olds, news)
+            outs = result_including_provider(result.outs if result is not None else None, news)
+            return provider_pb2.UpdateResponse(properties=to_struct(outs))
+        except Exception as exn: # pylint: disable=broad-except
+            return self.fail(context, exn, provider_pb2.UpdateResponse())
+
+    def Delete(self, request, context):
+        try:
+            props = rpc.deserialize_properties(request.properties)
+            get_provider(props).delete(request.id, props)
+            return empty_pb2.Empty()
+        except Exception as exn: # pylint: disable=broad-except
+            return self.fail(context, exn, empty_pb2.Empty())
+
+    def Cancel(self, request, context):
+        return empty_pb2.Empty()
+
+    def GetPluginInfo(self, request, context):
+        return plugin_pb2.PluginInfo()
+
+
+def main(args):
+    # The plugin requires a single argument, the address of the engine, which dynamic providers do not use.
+    if not args:
+        print("fatal: Missing <engine> address", file=sys.stderr)
+        sys.exit(1)
+
+    server = grpc.server(futures.ThreadPoolExecutor(max_workers=4))
+    provider_pb2_grpc.add_ResourceProviderServicer_to_server(DynamicResourceProviderServicer(), server)
+    port = server.add_insecure_port("0.0.0.0:0")
+    server.start()
+
+    # Emit the port so that the engine can connect to the provider, and then serve until we are killed.
+    print(port, flush=True)
+    while True:
+        time.sleep(60 * 60)
+
+
+if __name__ == "__main__":
+    main(sys.argv[1:])
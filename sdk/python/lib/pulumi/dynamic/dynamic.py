@@ -0,0 +1,272 @@
+# Copyright 2016-2019, Pulumi Corporation.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import base64
+from typing import Any, Dict, List, Optional
+
+import dill
+
+from .. import CustomResource, ResourceOptions
+
+PROVIDER_KEY = "__provider"
+"""
+The reserved property of a dynamic resource that holds its serialized provider.
+"""
+
+
+class CheckFailure:
+    """
+    CheckFailure represents a single failure in the results of a call to `ResourceProvider.check`.
+    """
+
+    property: str
+    """
+    The property that failed validation.
+    """
+
+    reason: str
+    """
+    The reason that the property failed validation.
+    """
+
+    def __init__(self, property_: str, reason: str) -> None:
+        self.property = property_
+        self.reason = reason
+
+
+class CheckResult:
+    """
+    CheckResult represents the results of a call to `ResourceProvider.check`.
+    """
+
+    inputs: Optional[Dict[str, Any]]
+    """
+    The inputs to use, if any.
+    """
+
+    failures: List[CheckFailure]
+    """
+    Any validation failures that occurred.
+    """
+
+    def __init__(self,
+                 inputs: Optional[Dict[str, Any]] = None,
+                 failures: Optional[List[CheckFailure]] = None) -> None:
+        self.inputs = inputs
+        self.failures = failures or []
+
+
+class DiffResult:
+    """
+    DiffResult represents the results of a call to `ResourceProvider.diff`.
+    """
+
+    changes: Optional[bool]
+    """
+    If true, this diff detected changes and suggests an update. If None, it is unknown whether there are changes.
+    """
+
+    replaces: List[str]
+    """
+    If this update requires a replacement, the set of properties triggering it.
+    """
+
+    replace_reasons: Dict[str, str]
+    """
+    Optional explanations, keyed by property name, of why changes to the properties in `replaces` require a
+    replacement.
+    """
+
+    stables: List[str]
+    """
+    An optional list of properties that will not ever change.
+    """
+
+    delete_before_replace: bool
+    """
+    If true, and a replacement occurs, the resource will first be deleted before being recreated.
+    """
+
+    def __init__(self,
+                 changes: Optional[bool] = None,
+                 replaces: Optional[List[str]] = None,
+                 replace_reasons: Optional[Dict[str, str]] = None,
+                 stables: Optional[List[str]] = None,
+                 delete_before_replace: bool = False) -> None:
+        self.changes = changes
+        self.replaces = replaces or []
+        self.replace_reasons = replace_reasons or {}
+        self.stables = stables or []
+        self.delete_before_replace = delete_before_replace
+
+
+class CreateResult:
+    """
+    CreateResult represents the results of a call to `ResourceProvider.create`.
+    """
+
+    id: str
+    """
+    The ID of the created resource.
+    """
+
+    outs: Optional[Dict[str, Any]]
+    """
+    Any properties that were computed during creation.
+    """
+
+    def __init__(self, id_: str, outs: Optional[Dict[str, Any]] = None) -> None:
+        self.id = id_
+        self.outs = outs
+
+
+class ReadResult:
+    """
+    ReadResult represents the results of a call to `ResourceProvider.read`.
+    """
+
+    id: Optional[str]
+    """
+    The ID of the resource read back, or None if it is missing.
+    """
+
+    outs: Optional[Dict[str, Any]]
+    """
+    The current property state read from the live environment.
+    """
+
+    def __init__(self, id_: Optional[str] = None, outs: Optional[Dict[str, Any]] = None) -> None:
+        self.id = id_
+        self.outs = outs
+
+
+class UpdateResult:
+    """
+    UpdateResult represents the results of a call to `ResourceProvider.update`.
+    """
+
+    outs: Optional[Dict[str, Any]]
+    """
+    Any properties that were computed during updating.
+    """
+
+    def __init__(self, outs: Optional[Dict[str, Any]] = None) -> None:
+        self.outs = outs
+
+
+class ResourceProvider:
+    """
+    ResourceProvider provides the CRUD operations for a dynamic resource. Subclasses must implement `create`, and may
+    override the other operations, whose default implementations accept the inputs as they are, report no diff, read
+    back the recorded state, and do nothing on update or delete.
+
+    The provider is serialized, along with any objects that it refers to, into the state of each resource that uses
+    it, so it should not capture large objects or objects that cannot be serialized, such as open clients; create
+    those within its operations instead.
+    """
+    # pylint: disable=unused-argument,no-self-use
+
+    def check(self, olds: Dict[str, Any], news: Dict[str, Any]) -> CheckResult:
+        """
+        Check validates that the given property bag is valid for a resource of the given type.
+
+        :param olds: The old input properties to use for validation.
+        :param news: The new input properties to use for validation.
+        """
+        return CheckResult(news)
+
+    def diff(self, id_: str, olds: Dict[str, Any], news: Dict[str, Any]) -> DiffResult:
+        """
+        Diff checks what impacts a hypothetical update will have on the resource's properties.
+
+        :param id_: The ID of the resource to diff.
+        :param olds: The old values of properties to diff.
+        :param news: The new values of properties to diff.
+        """
+        return DiffResult()
+
+    def create(self, inputs: Dict[str, Any]) -> CreateResult:
+        """
+        Create allocates a new instance of the provided resource and returns its unique ID afterwards. If this call
+        fails, the resource must not have been created (i.e., it is "transactional").
+
+        :param inputs: The properties to set during creation.
+        """
+        raise NotImplementedError("dynamic providers must implement create")
+
+    def read(self, id_: str, props: Dict[str, Any]) -> ReadResult:
+        """
+        Reads the current live state associated with a resource. Enough state must be included in the inputs to
+        uniquely identify the resource; this is typically just the resource ID, but it may also include some
+        properties.
+        """
+        return ReadResult(id_, props)
+
+    def update(self, id_: str, olds: Dict[str, Any], news: Dict[str, Any]) -> UpdateResult:
+        """
+        Update updates an existing resource with new values.
+
+        :param id_: The ID of the resource to update.
+        :param olds: The old values of properties to update.
+        :param news: The new values of properties to update.
+        """
+        return UpdateResult()
+
+    def delete(self, id_: str, props: Dict[str, Any]) -> None:
+        """
+        Delete tears down an existing resource with the given ID. If it fails, the resource is assumed to still exist.
+
+        :param id_: The ID of the resource to delete.
+        :param props: The current properties on the resource.
+        """
+
+
+def serialize_provider(provider: ResourceProvider) -> str:
+    """
+    Serializes the given provider, along with everything that it refers to, into a string.
+    """
+    return base64.b64encode(dill.dumps(provider, recurse=True)).decode("utf-8")
+
+
+def deserialize_provider(serialized: str) -> ResourceProvider:
+    """
+    Deserializes a provider that was serialized by `serialize_provider`.
+    """
+    return dill.loads(base64.b64decode(serialized.encode("utf-8")))
+
+
+class Resource(CustomResource):
+    """
+    Resource represents a Pulumi resource that incorporates an inline implementation of its CRUD operations.
+    """
+
+    def __init__(self,
+                 provider: ResourceProvider,
+                 name: str,
+                 props: Dict[str, Any],
+                 opts: Optional[ResourceOptions] = None) -> None:
+        """
+        :param ResourceProvider provider: The implementation of the resource's CRUD operations.
+        :param str name: The name of this resource.
+        :param dict props: The arguments to use to populate the new resource. Must not define the reserved property
+               "__provider".
+        :param Optional[ResourceOptions] opts: Optional set of :class:`pulumi.ResourceOptions` to use for this
+               resource.
+        """
+        if PROVIDER_KEY in props:
+            raise Exception("A dynamic resource must not define the __provider key")
+        props = dict(props)
+        props[PROVIDER_KEY] = serialize_provider(provider)
+
+        CustomResource.__init__(self, "pulumi-python:dynamic:Resource", name, props, opts)
@@ -16,11 +16,46 @@
 from typing import Optional, List, Any, Mapping, TYPE_CHECKING
 
 from .runtime import known_types
-from .runtime.resource import register_resource, register_resource_outputs
+from .runtime.resource import register_resource, register_resource_outputs, read_resource
 from .runtime.settings import get_root_resource
 
 if TYPE_CHECKING:
-    from .output import Output, Inputs
+    from .output import Output, Input, Inputs
+
+
+class CustomTimeouts:
+    """
+    CustomTimeouts overrides the default timeouts of a resource's create, update and delete operations. Each timeout
+    is a duration string such as "5m", "40s" or "1h30m"; operations without a custom timeout use the default.
+    """
+
+    create: Optional[str]
+    """
+    The optional create timeout.
+    """
+
+    update: Optional[str]
+    """
+    The optional update timeout.
+    """
+
+    delete: Optional[str]
+    """
+    The optional delete timeout.
+    """
+
+    def __init__(self,
+                 create: Optional[str] = None,
+                 update: Optional[str] = None,
+                 delete: Optional[str] = None) -> None:
+        """
+        :param Optional[str] create: The optional create timeout.
+        :param Optional[str] update: The optional update timeout.
+        :param Optional[str] delete: The optional delete timeout.
+        """
+        self.create = create
+        self.update = update
+        self.delete = delete
 
 
 class ResourceOptions:
@@ -48,6 +83,17 @@ class ResourceOptions:
     If provided and True, this resource must be deleted before it is replaced.
     """
 
+    ignore_changes: Optional[List[str]]
+    """
+    If provided, ignore changes to any of the specified properties when diffing this resource. Each property is named
+    by a dotted path, e.g. "tags.owner" refers to the "owner" property of the "tags" object.
+    """
+
+    custom_timeouts: Optional[CustomTimeouts]
+    """
+    If provided, overrides the default timeouts of this resource's create, update and delete operations.
+    """
+
     provider: Optional['ProviderResource']
     """
     An optional provider to use for this resource's CRUD operations. If no provider is supplied, the default
@@ -60,6 +106,25 @@ class ResourceOptions:
     An optional set of providers to use for child resources. Keyed by package name (e.g. "aws")
     """
 
+    id: Optional['Input[str]']
+    """
+    An optional existing ID to load, rather than create. A resource with an ID is read from its provider and
+    recorded in the stack's state, but is never created, updated, or deleted by Pulumi.
+    """
+
+    import_: Optional[str]
+    """
+    When provided with a resource ID, indicates that this resource's provider should import its state from the cloud
+    resource with the given ID instead of creating it. The inputs to the resource's constructor must match the
+    existing resource's state, or the import will fail.
+    """
+
+    version: Optional[str]
+    """
+    An optional version of the provider to use for this resource's CRUD operations when no provider is supplied.
+    Packages that are built against a particular version of their provider set this so that their resources keep that
+    version's behavior even when the program uses a different version of the same provider elsewhere.
+    """
 
     def __init__(self,
                  parent: Optional['Resource'] = None,
@@ -67,7 +132,12 @@ class ResourceOptions:
                  protect: Optional[bool] = None,
                  provider: Optional['ProviderResource'] = None,
                  providers: Optional[Mapping[str, 'ProviderResource']] = None,
-                 delete_before_replace: Optional[bool] = None) -> None:
+                 delete_before_replace: Optional[bool] = None,
+                 ignore_changes: Optional[List[str]] = None,
+                 id: Optional['Input[str]'] = None, # pylint: disable=redefined-builtin
+                 custom_timeouts: Optional[CustomTimeouts] = None,
+                 import_: Optional[str] = None,
+                 version: Optional[str] = None) -> None:
         """
         :param Optional[Resource] parent: If provided, the currently-constructing resource should be the child of
                the provided parent resource.
@@ -80,6 +150,14 @@ class ResourceOptions:
         :param Optional[Mapping[str,ProviderResource]] providers: An optional set of providers to use for child resources. Keyed
                by package name (e.g. "aws")
         :param Optional[bool] delete_before_replace: If provided and True, this resource must be deleted before it is replaced.
+        :param Optional[List[str]] ignore_changes: If provided, a list of property paths to ignore when diffing this resource.
+        :param Optional[Input[str]] id: An optional existing ID to load, rather than create.
+        :param Optional[CustomTimeouts] custom_timeouts: If provided, overrides the default timeouts of this resource's
+               create, update and delete operations.
+        :param Optional[str] import_: When provided with a resource ID, indicates that this resource's provider should
+               import its state from the cloud resource with the given ID instead of creating it.
+        :param Optional[str] version: An optional version of the provider to use for this resource's CRUD operations
+               when no provider is supplied.
         """
         self.parent = parent
         self.depends_on = depends_on
@@ -87,6 +165,11 @@ class ResourceOptions:
         self.provider = provider
         self.providers = providers
         self.delete_before_replace = delete_before_replace
+        self.ignore_changes = ignore_changes
+        self.id = id
+        self.custom_timeouts = custom_timeouts
+        self.import_ = import_
+        self.version = version
 
 class Resource:
     """
@@ -165,7 +248,14 @@ class Resource:
                 self._providers = {**self._providers, **providers}
 
         self._protect = bool(opts.protect)
-        register_resource(self, t, name, custom, props, opts)
+
+        if opts.id is not None:
+            # If this resource already exists, read its state rather than registering it anew.
+            if not custom:
+                raise Exception("Cannot read an existing resource unless it has a custom provider")
+            read_resource(self, t, name, props, opts)
+        else:
+            register_resource(self, t, name, custom, props, opts)
 
     def translate_output_property(self, prop: str) -> str:
         """
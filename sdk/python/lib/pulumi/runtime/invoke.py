@@ -51,7 +51,7 @@ def invoke(tok: str, props: Inputs, opts: InvokeOptions = None) -> Awaitable[Any
         monitor = get_monitor()
         inputs = await rpc.serialize_properties(props, {})
         log.debug(f"Invoking function prepared: tok={tok}")
-        req = provider_pb2.InvokeRequest(tok=tok, args=inputs, provider=provider_ref)
+        req = provider_pb2.InvokeRequest(tok=tok, args=inputs, provider=provider_ref, version=opts.version or "")
 
         def do_invoke():
             try:
@@ -19,7 +19,7 @@ DESCRIPTOR = _descriptor.FileDescriptor(
   package='pulumirpc',
   syntax='proto3',
   serialized_options=None,
-  serialized_pb=_b('\n\x0cplugin.proto\x12\tpulumirpc\"\x1d\n\nPluginInfo\x12\x0f\n\x07version\x18\x01 \x01(\t\"?\n\x10PluginDependency\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0c\n\x04kind\x18\x02 \x01(\t\x12\x0f\n\x07version\x18\x03 \x01(\tb\x06proto3')
+  serialized_pb=_b('\n\x0cplugin.proto\x12\tpulumirpc\"M\n\nPluginInfo\x12\x0f\n\x07version\x18\x01 \x01(\t\x12\x18\n\x10interfaceVersion\x18\x02 \x01(\x05\x12\x14\n\x0c\x63\x61pabilities\x18\x03 \x03(\t\"\x1f\n\x0cPluginAttach\x12\x0f\n\x07\x61\x64\x64ress\x18\x01 \x01(\t\"?\n\x10PluginDependency\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0c\n\x04kind\x18\x02 \x01(\t\x12\x0f\n\x07version\x18\x03 \x01(\tb\x06proto3')
 )
 
 
@@ -39,6 +39,20 @@ _PLUGININFO = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='interfaceVersion', full_name='pulumirpc.PluginInfo.interfaceVersion', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='capabilities', full_name='pulumirpc.PluginInfo.capabilities', index=2,
+      number=3, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -52,7 +66,38 @@ _PLUGININFO = _descriptor.Descriptor(
   oneofs=[
   ],
   serialized_start=27,
-  serialized_end=56,
+  serialized_end=104,
+)
+
+
+_PLUGINATTACH = _descriptor.Descriptor(
+  name='PluginAttach',
+  full_name='pulumirpc.PluginAttach',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='address', full_name='pulumirpc.PluginAttach.address', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=106,
+  serialized_end=137,
 )
 
 
@@ -96,11 +141,12 @@ _PLUGINDEPENDENCY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=58,
-  serialized_end=121,
+  serialized_start=139,
+  serialized_end=202,
 )
 
 DESCRIPTOR.message_types_by_name['PluginInfo'] = _PLUGININFO
+DESCRIPTOR.message_types_by_name['PluginAttach'] = _PLUGINATTACH
 DESCRIPTOR.message_types_by_name['PluginDependency'] = _PLUGINDEPENDENCY
 _sym_db.RegisterFileDescriptor(DESCRIPTOR)
 
@@ -111,6 +157,13 @@ PluginInfo = _reflection.GeneratedProtocolMessageType('PluginInfo', (_message.Me
   ))
 _sym_db.RegisterMessage(PluginInfo)
 
+PluginAttach = _reflection.GeneratedProtocolMessageType('PluginAttach', (_message.Message,), dict(
+  DESCRIPTOR = _PLUGINATTACH,
+  __module__ = 'plugin_pb2'
+  # @@protoc_insertion_point(class_scope:pulumirpc.PluginAttach)
+  ))
+_sym_db.RegisterMessage(PluginAttach)
+
 PluginDependency = _reflection.GeneratedProtocolMessageType('PluginDependency', (_message.Message,), dict(
   DESCRIPTOR = _PLUGINDEPENDENCY,
   __module__ = 'plugin_pb2'
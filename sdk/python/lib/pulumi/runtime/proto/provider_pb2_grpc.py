@@ -77,6 +77,21 @@ class ResourceProviderStub(object):
         request_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
         response_deserializer=plugin__pb2.PluginInfo.FromString,
         )
+    self.GetSchema = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/GetSchema',
+        request_serializer=provider__pb2.GetSchemaRequest.SerializeToString,
+        response_deserializer=provider__pb2.GetSchemaResponse.FromString,
+        )
+    self.Attach = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/Attach',
+        request_serializer=plugin__pb2.PluginAttach.SerializeToString,
+        response_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+        )
+    self.GetLogs = channel.unary_unary(
+        '/pulumirpc.ResourceProvider/GetLogs',
+        request_serializer=provider__pb2.GetLogsRequest.SerializeToString,
+        response_deserializer=provider__pb2.GetLogsResponse.FromString,
+        )
 
 
 class ResourceProviderServicer(object):
@@ -168,7 +183,36 @@ class ResourceProviderServicer(object):
     raise NotImplementedError('Method not implemented!')
 
   def GetPluginInfo(self, request, context):
-    """GetPluginInfo returns generic information about this plugin, like its version.
+    """GetPluginInfo returns generic information about this plugin, like its version. The engine also uses the interface
+    version and capabilities that it returns to negotiate how it talks to the provider: it uses the older of its own
+    and the provider's interface versions, and those capabilities that both support, and sends the results to the
+    provider in its ConfigureRequest. Providers that do not report an interface version are assumed to support
+    version 1, and providers must continue to support every interface version older than the one they report.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def GetSchema(self, request, context):
+    """GetSchema fetches the schema for this resource provider, which describes its configuration, resources, and
+    functions, for use by tools such as editors and documentation generators.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def Attach(self, request, context):
+    """Attach tells a provider that was launched outside of the engine, rather than by it, the address of the engine's
+    RPC server, which the engine would otherwise have passed to the provider on its command line. The engine calls
+    it before any other method, and only on providers that it attaches to rather than launches.
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def GetLogs(self, request, context):
+    """GetLogs fetches the runtime logs of a resource, such as those written by a serverless function, for `pulumi logs`.
+    Providers that do not keep logs for resources of the given type return no entries.
     """
     context.set_code(grpc.StatusCode.UNIMPLEMENTED)
     context.set_details('Method not implemented!')
@@ -237,6 +281,21 @@ def add_ResourceProviderServicer_to_server(servicer, server):
           request_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
           response_serializer=plugin__pb2.PluginInfo.SerializeToString,
       ),
+      'GetSchema': grpc.unary_unary_rpc_method_handler(
+          servicer.GetSchema,
+          request_deserializer=provider__pb2.GetSchemaRequest.FromString,
+          response_serializer=provider__pb2.GetSchemaResponse.SerializeToString,
+      ),
+      'Attach': grpc.unary_unary_rpc_method_handler(
+          servicer.Attach,
+          request_deserializer=plugin__pb2.PluginAttach.FromString,
+          response_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
+      ),
+      'GetLogs': grpc.unary_unary_rpc_method_handler(
+          servicer.GetLogs,
+          request_deserializer=provider__pb2.GetLogsRequest.FromString,
+          response_serializer=provider__pb2.GetLogsResponse.SerializeToString,
+      ),
   }
   generic_handler = grpc.method_handlers_generic_handler(
       'pulumirpc.ResourceProvider', rpc_method_handlers)
@@ -118,6 +118,81 @@ async def prepare_resource(res: 'Resource',
     )
 
 
+def read_resource(res: 'Resource', ty: str, name: str, props: 'Inputs', opts: Optional['ResourceOptions']):
+    """
+    read_resource reads an existing custom resource's state from the resource monitor. The resource is recorded in
+    the stack's state, but its lifecycle is not managed by Pulumi: it is never created, updated, or deleted.
+    """
+    if opts.id is None:
+        raise Exception("Cannot read resource whose options are lacking an ID value")
+
+    log.debug(f"reading resource: ty={ty}, name={name}")
+    monitor = settings.get_monitor()
+
+    # Prepare the resource, similar to a RegisterResource. Reads are deliberately similar to RegisterResource except
+    # that we are populating the Resource object with properties associated with an already-live resource.
+    urn_future = asyncio.Future()
+    urn_known = asyncio.Future()
+    urn_known.set_result(True)
+    resolve_urn = urn_future.set_result
+    resolve_urn_exn = urn_future.set_exception
+    res.urn = known_types.new_output({res}, urn_future, urn_known)
+
+    # The ID of a read resource is supplied by the program, but it may not be known until its inputs resolve.
+    resolve_value = asyncio.Future()
+    resolve_perform_apply = asyncio.Future()
+    res.id = known_types.new_output({res}, resolve_value, resolve_perform_apply)
+
+    resolvers = rpc.transfer_properties(res, props)
+
+    async def do_read():
+        try:
+            log.debug(f"preparing read: ty={ty}, name={name}")
+            resolver = await prepare_resource(res, ty, True, props, opts)
+            resolved_id = await rpc.serialize_property(opts.id, [])
+            log.debug(f"read prepared: ty={ty}, name={name}, id={resolved_id}")
+
+            req = resource_pb2.ReadResourceRequest(
+                type=ty,
+                name=name,
+                id=resolved_id,
+                parent=resolver.parent_urn,
+                provider=resolver.provider_ref,
+                properties=resolver.serialized_props,
+                dependencies=resolver.dependencies,
+                version=opts.version or "",
+            )
+
+            def do_rpc_call():
+                try:
+                    return monitor.ReadResource(req)
+                except grpc.RpcError as exn:
+                    # See the comment on invoke for the justification for disabling
+                    # this warning
+                    # pylint: disable=no-member
+                    if exn.code() == grpc.StatusCode.UNAVAILABLE:
+                        sys.exit(0)
+
+                    details = exn.details()
+                raise Exception(details)
+            resp = await asyncio.get_event_loop().run_in_executor(None, do_rpc_call)
+        except Exception as exn:
+            log.debug(f"exception when preparing or executing rpc: {traceback.format_exc()}")
+            rpc.resolve_outputs_due_to_exception(resolvers, exn)
+            resolve_urn_exn(exn)
+            resolve_value.set_exception(exn)
+            resolve_perform_apply.set_exception(exn)
+            raise
+
+        log.debug(f"resource read successful: ty={ty}, urn={resp.urn}")
+        resolve_urn(resp.urn)
+        resolve_value.set_result(resolved_id)
+        resolve_perform_apply.set_result(bool(resolved_id) and resolved_id != rpc.UNKNOWN)
+        await rpc.resolve_outputs(res, props, resp.properties, resolvers)
+
+    asyncio.ensure_future(RPC_MANAGER.do_rpc("read resource", do_read)())
+
+
 # pylint: disable=too-many-locals,too-many-statements
 def register_resource(res: 'Resource', ty: str, name: str, custom: bool, props: 'Inputs', opts: Optional['ResourceOptions']):
     """
@@ -173,6 +248,20 @@ def register_resource(res: 'Resource', ty: str, name: str, custom: bool, props:
             for key, deps in resolver.property_dependencies.items():
                 property_dependencies[key] = resource_pb2.RegisterResourceRequest.PropertyDependencies(urns=deps)
 
+            # The first element of each ignored property path names one of this resource's inputs, so translate it
+            # in the same way as the input property names themselves.
+            ignore_changes = []
+            for path in opts.ignore_changes or []:
+                head, sep, rest = path.partition(".")
+                ignore_changes.append(res.translate_input_property(head) + sep + rest)
+
+            custom_timeouts = None
+            if opts.custom_timeouts is not None:
+                custom_timeouts = resource_pb2.RegisterResourceRequest.CustomTimeouts(
+                    create=opts.custom_timeouts.create or "",
+                    update=opts.custom_timeouts.update or "",
+                    delete=opts.custom_timeouts.delete or "")
+
             req = resource_pb2.RegisterResourceRequest(
                 type=ty,
                 name=name,
@@ -183,7 +272,11 @@ def register_resource(res: 'Resource', ty: str, name: str, custom: bool, props:
                 provider=resolver.provider_ref,
                 dependencies=resolver.dependencies,
                 propertyDependencies=property_dependencies,
-                deleteBeforeReplace=opts.delete_before_replace
+                deleteBeforeReplace=opts.delete_before_replace,
+                ignoreChanges=ignore_changes,
+                customTimeouts=custom_timeouts,
+                importId=opts.import_,
+                version=opts.version or ""
             )
 
             def do_rpc_call():
@@ -28,7 +28,7 @@ class ConfigTest(LanghostTest):
             expected_resource_count=1)
 
     def register_resource(self, ctx, dry_run, ty, name, _resource,
-                          _dependencies, _parent, _custom, _protect, _provider, _property_deps, _delete_before_replace):
+                          _dependencies, _parent, _custom, _protect, _provider, _property_deps, _delete_before_replace, _ignore_changes):
         self.assertEqual("test:index:MyResource", ty)
         self.assertEqual("myname", name)
         return {
@@ -25,7 +25,7 @@ class DeleteBeforeReplaceTest(LanghostTest):
             expected_resource_count=1)
 
     def register_resource(self, _ctx, _dry_run, ty, name, _resource,
-                          _dependencies, _parent, _custom, _protect, _provider, _property_deps, delete_before_replace):
+                          _dependencies, _parent, _custom, _protect, _provider, _property_deps, delete_before_replace, _ignore_changes):
         self.assertEqual("foo", name)
         self.assertTrue(delete_before_replace)
         return {
@@ -53,7 +53,7 @@ class TestFirstClassProviderInvoke(LanghostTest):
         }
 
     def register_resource(self, _ctx, _dry_run, ty, name, resource, _deps,
-                          _parent, _custom, _protect, _provider, _property_deps, _delete_before_replace):
+                          _parent, _custom, _protect, _provider, _property_deps, _delete_before_replace, _ignore_changes):
         if name == "testprov":
             self.assertEqual("pulumi:providers:test", ty)
             self.prov_urn = self.make_urn(ty, name)
@@ -0,0 +1,34 @@
+# Copyright 2016-2018, Pulumi Corporation.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+from os import path
+from ..util import LanghostTest
+
+
+class IgnoreChangesTest(LanghostTest):
+    """
+    Tests that resources correctly pass the list of properties whose changes should be ignored to the engine, and that
+    the names of top-level properties are translated.
+    """
+    def test_ignore_changes(self):
+        self.run_test(
+            program=path.join(self.base_path(), "ignore_changes"),
+            expected_resource_count=1)
+
+    def register_resource(self, _ctx, _dry_run, ty, name, _resource, _dependencies, _parent, _custom, _protect,
+                          _provider, _property_deps, _delete_before_replace, ignore_changes):
+        self.assertEqual("foo", name)
+        self.assertListEqual(["desiredCount", "tags.owner"], ignore_changes)
+        return {
+            "urn": self.make_urn(ty, name)
+        }
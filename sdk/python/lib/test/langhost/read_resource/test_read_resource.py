@@ -0,0 +1,50 @@
+# Copyright 2016-2018, Pulumi Corporation.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+from pulumi import CustomResource, ResourceOptions
+from os import path
+from ..util import LanghostTest
+
+
+class ReadResourceTest(LanghostTest):
+    """
+    Tests that resources with an ID are read from the engine rather than registered, and that the properties
+    returned by the read are available to the program.
+    """
+    def test_read_resource(self):
+        self.run_test(
+            program=path.join(self.base_path(), "read_resource"),
+            expected_resource_count=1)
+
+    def read_resource(self, _ctx, ty, name, id_, _parent, state):
+        self.assertEqual("test:index:MyResource", ty)
+        self.assertEqual("existing", name)
+        self.assertEqual("existing-id", id_)
+        self.assertEqual("bar", state["filter"])
+        return {
+            "urn": self.make_urn(ty, name),
+            "properties": {
+                "filter": "bar",
+                "value": "looked-up",
+            },
+        }
+
+    def register_resource(self, _ctx, dry_run, ty, name, resource,
+                          _dependencies, _parent, _custom, _protect, _provider, _property_deps, _delete_before_replace, _ignore_changes):
+        self.assertEqual("dependent", name)
+        if not dry_run:
+            self.assertDictEqual({"input": "looked-up"}, resource)
+        return {
+            "urn": self.make_urn(ty, name),
+            "id": name,
+        }
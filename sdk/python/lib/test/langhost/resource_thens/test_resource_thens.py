@@ -30,7 +30,7 @@ class ResourceThensTest(LanghostTest):
             expected_resource_count=2)
 
     def register_resource(self, _ctx, dry_run, ty, name, res, deps,
-                          _parent, custom, _protect, _provider, _property_deps, _delete_before_replace):
+                          _parent, custom, _protect, _provider, _property_deps, _delete_before_replace, _ignore_changes):
         if ty == "test:index:ResourceA":
             self.assertEqual(name, "resourceA")
             self.assertDictEqual(res, {"inprop": 777})
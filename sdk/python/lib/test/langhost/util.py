@@ -89,6 +89,7 @@ class LanghostMockResourceMonitor(proto.ResourceMonitorServicer):
         protect = request.protect
         provider = request.provider
         delete_before_replace = request.deleteBeforeReplace
+        ignore_changes = sorted(list(request.ignoreChanges))
 
         property_dependencies = {}
         for key, value in request.propertyDependencies.items():
@@ -97,7 +98,8 @@ class LanghostMockResourceMonitor(proto.ResourceMonitorServicer):
         outs = {}
         if type_ != "pulumi:pulumi:Stack":
             outs = self.langhost_test.register_resource(
-                context, self.dryrun, type_, name, props, deps, parent, custom, protect, provider, property_dependencies, delete_before_replace)
+                context, self.dryrun, type_, name, props, deps, parent, custom, protect, provider, property_dependencies, delete_before_replace,
+                ignore_changes)
             if outs.get("urn"):
                 urn = outs["urn"]
                 self.registrations[urn] = {
@@ -0,0 +1,80 @@
+# Copyright 2016-2019, Pulumi Corporation.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+import unittest
+
+from google.protobuf import struct_pb2
+from pulumi.dynamic import CheckFailure, CheckResult, CreateResult, ResourceProvider
+from pulumi.dynamic.dynamic import PROVIDER_KEY, deserialize_provider, serialize_provider
+from pulumi.dynamic.__main__ import DynamicResourceProviderServicer
+from pulumi.runtime.proto import provider_pb2
+
+
+class CounterProvider(ResourceProvider):
+    def __init__(self, prefix):
+        self.prefix = prefix
+
+    def check(self, olds, news):
+        if "count" not in news:
+            return CheckResult(news, [CheckFailure("count", "count is required")])
+        return CheckResult(news)
+
+    def create(self, inputs):
+        return CreateResult(self.prefix + str(inputs["count"]), {"doubled": inputs["count"] * 2})
+
+
+class FakeContext:
+    def __init__(self):
+        self.code = None
+        self.details = None
+
+    def set_code(self, code):
+        self.code = code
+
+    def set_details(self, details):
+        self.details = details
+
+
+def to_struct(props):
+    struct = struct_pb2.Struct()
+    struct.update(props)
+    return struct
+
+
+class DynamicProviderTests(unittest.TestCase):
+    def test_serialize_provider(self):
+        provider = deserialize_provider(serialize_provider(CounterProvider("id-")))
+        self.assertEqual("id-1", provider.create({"count": 1}).id)
+
+    def test_servicer(self):
+        servicer = DynamicResourceProviderServicer()
+        serialized = serialize_provider(CounterProvider("id-"))
+
+        # Check delegates to the provider, and keeps the provider in the inputs.
+        resp = servicer.Check(provider_pb2.CheckRequest(
+            olds=to_struct({}), news=to_struct({PROVIDER_KEY: serialized})), FakeContext())
+        self.assertEqual(serialized, resp.inputs[PROVIDER_KEY])
+        self.assertEqual(["count"], [f.property for f in resp.failures])
+
+        # Create returns the provider's ID and outputs, along with the provider.
+        resp = servicer.Create(provider_pb2.CreateRequest(
+            properties=to_struct({PROVIDER_KEY: serialized, "count": 2})), FakeContext())
+        self.assertEqual("id-2", resp.id)
+        self.assertEqual(4, resp.properties["doubled"])
+        self.assertEqual(serialized, resp.properties[PROVIDER_KEY])
+
+        # Failures are reported as errors.
+        context = FakeContext()
+        servicer.Create(provider_pb2.CreateRequest(properties=to_struct({PROVIDER_KEY: serialized})), context)
+        self.assertIsNotNone(context.code)
+        self.assertIn("count", context.details)